@@ -23,6 +23,7 @@ import (
 
 	"github.com/onflow/flow-cli/internal/command"
 	"github.com/onflow/flow-cli/pkg/flowkit"
+	"github.com/onflow/flow-cli/pkg/flowkit/output"
 	"github.com/onflow/flow-cli/pkg/flowkit/services"
 )
 
@@ -47,8 +48,8 @@ var RemoveCommand = &command.Command{
 func removeContract(
 	args []string,
 	_ flowkit.ReaderWriter,
-	_ command.GlobalFlags,
-	services *services.Services,
+	globalFlags command.GlobalFlags,
+	srv *services.Services,
 	state *flowkit.State,
 ) (command.Result, error) {
 	contractName := args[0]
@@ -58,12 +59,21 @@ func removeContract(
 		return nil, err
 	}
 
-	_, err = services.Accounts.RemoveContract(from, contractName)
+	srv.Accounts.SetConfirmation(services.ConfirmationOptions{
+		RequireConfirmation:    !globalFlags.Yes,
+		RemoveContractCallback: confirmRemoveContract,
+	})
+
+	_, err = srv.Accounts.RemoveContractWithOptions(
+		from,
+		contractName,
+		services.RemoveContractOptions{Network: globalFlags.Network},
+	)
 	if err != nil {
 		return nil, err
 	}
 
-	account, err := services.Accounts.Get(from.Address())
+	account, err := srv.Accounts.Get(from.Address())
 	if err != nil {
 		return nil, err
 	}
@@ -72,3 +82,12 @@ func removeContract(
 		include: flagsRemove.Include,
 	}, nil
 }
+
+// confirmRemoveContract asks for confirmation only if stored values or capabilities reference the contract.
+func confirmRemoveContract(report *services.ImpactReport) bool {
+	if !report.HasReferences() {
+		return true
+	}
+
+	return output.ConfirmRemoveContractPrompt(report.Contract, report.String())
+}
@@ -20,7 +20,6 @@ package accounts
 
 import (
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/onflow/flow-go-sdk"
@@ -61,8 +60,8 @@ var CreateCommand = &command.Command{
 func create(
 	_ []string,
 	loader flowkit.ReaderWriter,
-	_ command.GlobalFlags,
-	services *services.Services,
+	globalFlags command.GlobalFlags,
+	srv *services.Services,
 	state *flowkit.State,
 ) (command.Result, error) {
 	// if user doesn't provide any flags go into interactive mode
@@ -116,24 +115,33 @@ func create(
 
 	keyWeights := createFlags.Weights
 
-	// decode public keys
+	// decode public keys on the curves of the flags, if the key encoding contains algorithms they take precedence
 	pubKeys := make([]crypto.PublicKey, 0, len(createFlags.Keys))
 	for i, k := range createFlags.Keys {
-		k = strings.TrimPrefix(k, "0x") // clear possible prefix
-		key, err := crypto.DecodePublicKeyHex(sigAlgos[i], k)
+		key, sigAlgo, hashAlgo, err := srv.Keys.ParsePublicKey(k, sigAlgos[i])
 		if err != nil {
-			return nil, fmt.Errorf("failed decoding public key: %s with error: %w", key, err)
+			return nil, fmt.Errorf("failed decoding public key: %s with error: %w", k, err)
+		}
+		sigAlgos[i] = sigAlgo
+		if hashAlgo != crypto.UnknownHashAlgorithm {
+			hashAlgos[i] = hashAlgo
 		}
 		pubKeys = append(pubKeys, key)
 	}
 
-	account, err := services.Accounts.Create(
+	srv.Accounts.SetConfirmation(services.ConfirmationOptions{
+		RequireConfirmation:  !globalFlags.Yes,
+		ConfirmationCallback: output.ConfirmNetworkOperationPrompt,
+	})
+
+	account, err := srv.Accounts.CreateForNetwork(
 		signer,
 		pubKeys,
 		keyWeights,
 		sigAlgos,
 		hashAlgos,
 		createFlags.Contracts,
+		globalFlags.Network,
 	)
 
 	if err != nil {
@@ -157,6 +165,7 @@ func createInteractive(state *flowkit.State, loader flowkit.ReaderWriter) (*flow
 	if err != nil {
 		return nil, err
 	}
+	defer gw.Close()
 
 	privateFile := output.Bold(fmt.Sprintf("%s.private.json", name))
 
@@ -196,13 +205,14 @@ func createInteractive(state *flowkit.State, loader flowkit.ReaderWriter) (*flow
 		if err != nil {
 			return nil, err
 		}
-		account, err := service.Accounts.Create(
+		account, err := service.Accounts.CreateForNetwork(
 			signer,
 			[]crypto.PublicKey{key.PublicKey()},
 			[]int{flow.AccountKeyWeightThreshold},
 			[]crypto.SignatureAlgorithm{crypto.ECDSA_P256},
 			[]crypto.HashAlgorithm{crypto.SHA3_256},
 			nil,
+			selectedNetwork.Name,
 		)
 		if err != nil {
 			return nil, err
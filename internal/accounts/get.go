@@ -35,9 +35,9 @@ var getFlags = flagsGet{}
 
 var GetCommand = &command.Command{
 	Cmd: &cobra.Command{
-		Use:     "get <address>",
-		Short:   "Gets an account by address",
-		Example: "flow accounts get f8d6e0586b0a20c7",
+		Use:     "get <address|name>",
+		Short:   "Gets an account by address or by a name registered on-chain",
+		Example: "flow accounts get f8d6e0586b0a20c7\nflow accounts get alice.find --network mainnet",
 		Args:    cobra.ExactArgs(1),
 	},
 	Flags: &getFlags,
@@ -48,11 +48,23 @@ func get(
 	args []string,
 	_ flowkit.ReaderWriter,
 	_ command.GlobalFlags,
-	services *services.Services,
+	srv *services.Services,
 ) (command.Result, error) {
+	if services.IsRegistryName(args[0]) {
+		accounts, err := srv.Accounts.GetMultiple(args[:1])
+		if err != nil {
+			return nil, err
+		}
+
+		return &AccountResult{
+			Account: accounts[0],
+			include: getFlags.Include,
+		}, nil
+	}
+
 	address := flow.HexToAddress(args[0])
 
-	account, err := services.Accounts.Get(address)
+	account, err := srv.Accounts.Get(address)
 	if err != nil {
 		return nil, err
 	}
@@ -54,23 +54,33 @@ func stakingInfo(
 ) (command.Result, error) {
 	address := flow.HexToAddress(args[0])
 
-	staking, delegation, err := services.Accounts.StakingInfo(address)
+	result, err := services.Accounts.StakingInfo(address)
 	if err != nil {
 		return nil, err
 	}
 
-	return &StakingResult{staking, delegation}, nil
+	warnings := make([]string, len(result.Warnings))
+	for i, warning := range result.Warnings {
+		warnings[i] = warning.String()
+	}
+
+	return &StakingResult{result.Staking, result.Delegation, warnings}, nil
 }
 
 type StakingResult struct {
 	staking    []map[string]interface{} // stake as FlowIDTableStaking.NodeInfo
 	delegation []map[string]interface{} // delegation as FlowIDTableStaking.DelegatorInfo
+	warnings   []string                 // parts of the info that couldn't be fetched
 }
 
 func (r *StakingResult) JSON() interface{} {
 	result := make(map[string]interface{})
 	result["staking"] = r.staking
 	result["delegation"] = r.delegation
+	if len(r.warnings) > 0 {
+		result["partial"] = true
+		result["warnings"] = r.warnings
+	}
 
 	return result
 }
@@ -120,6 +130,13 @@ func (r *StakingResult) String() string {
 		_, _ = fmt.Fprintf(writer, "Account has no delegations.\n")
 	}
 
+	if len(r.warnings) != 0 {
+		_, _ = fmt.Fprintf(writer, "\nInfo is incomplete:\n")
+		for _, warning := range r.warnings {
+			_, _ = fmt.Fprintf(writer, "\t%s\n", warning)
+		}
+	}
+
 	writer.Flush()
 	return b.String()
 }
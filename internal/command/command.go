@@ -110,12 +110,21 @@ func (c Command) AddToParent(parent *cobra.Command) {
 
 		clientGateway, err := createGateway(host, hostNetworkKey)
 		handleError("Gateway Error", err)
+		defer clientGateway.Close()
 
 		logger := createLogger(Flags.Log, Flags.Format)
 
 		// initialize services
 		service := services.NewServices(clientGateway, state, logger)
 
+		// resolve names registered on-chain, like alice.find, with the registries of the network,
+		// the network is unknown if the host is provided
+		if Flags.Host == "" {
+			network, err := resolveNetwork(state, Flags.Network)
+			handleError("Host Error", err)
+			service.Accounts.SetNameResolver(services.NewRegistryNameResolver(clientGateway, *network))
+		}
+
 		// skip version check if flag is set
 		if !Flags.SkipVersionCheck {
 			checkVersion(logger)
@@ -135,6 +144,9 @@ func (c Command) AddToParent(parent *cobra.Command) {
 			panic("command implementation needs to provide run functionality")
 		}
 
+		if err != nil {
+			printCadenceRuntimeError(err, state)
+		}
 		handleError("Command Error", err)
 
 		// Do not print a result if none is provided.
@@ -196,24 +208,35 @@ func resolveHost(state *flowkit.State, hostFlag, networkKeyFlag, networkFlag str
 		return hostFlag, networkKeyFlag, nil
 	}
 
+	network, err := resolveNetwork(state, networkFlag)
+	if err != nil {
+		return "", "", err
+	}
+
+	return network.Host, network.Key, nil
+}
+
+// resolveNetwork returns the network selected by the network flag, from the configuration if
+// initialized and from the default networks otherwise.
+func resolveNetwork(state *flowkit.State, networkFlag string) (*config.Network, error) {
 	// network flag with project initialized is next
 	if state != nil {
 		stateNetwork, err := state.Networks().ByName(networkFlag)
 		if err != nil {
-			return "", "", fmt.Errorf("network with name %s does not exist in configuration", networkFlag)
+			return nil, fmt.Errorf("network with name %s does not exist in configuration", networkFlag)
 		}
 
-		return stateNetwork.Host, stateNetwork.Key, nil
+		return stateNetwork, nil
 	}
 
 	networks := config.DefaultNetworks()
 	network, err := networks.ByName(networkFlag)
 
 	if err != nil {
-		return "", "", fmt.Errorf("invalid network with name %s", networkFlag)
+		return nil, fmt.Errorf("invalid network with name %s", networkFlag)
 	}
 
-	return network.Host, network.Key, nil
+	return network, nil
 }
 
 // create logger utility.
@@ -28,6 +28,7 @@ import (
 	"github.com/onflow/flow-go-sdk/access/grpc"
 	"github.com/spf13/afero"
 
+	"github.com/onflow/flow-cli/pkg/flowkit"
 	"github.com/onflow/flow-cli/pkg/flowkit/config"
 	"github.com/onflow/flow-cli/pkg/flowkit/output"
 )
@@ -128,6 +129,54 @@ func filterResultValue(result Result, filter string) (interface{}, error) {
 	return value, nil
 }
 
+// printCadenceRuntimeError prints the stack of a Cadence runtime error,
+// showing the local source line for locations of contracts found in the state.
+func printCadenceRuntimeError(err error, state *flowkit.State) {
+	var runtimeErr *flowkit.CadenceRuntimeError
+	if !errors.As(err, &runtimeErr) || !runtimeErr.Parsed() {
+		return
+	}
+
+	_, _ = fmt.Fprintf(os.Stderr, "%s Cadence %s: %s\n", output.ErrorEmoji(), runtimeErr.Kind, runtimeErr.Message)
+	for _, entry := range runtimeErr.Stack {
+		if entry.Location == nil {
+			continue
+		}
+
+		_, _ = fmt.Fprintf(os.Stderr, "  at %s\n", entry.Location)
+		if line := localSourceLine(entry.Location, state); line != "" {
+			_, _ = fmt.Fprintf(os.Stderr, "    %d | %s\n", entry.Location.Line, line)
+		} else if entry.Source != "" {
+			_, _ = fmt.Fprintf(os.Stderr, "    %d | %s\n", entry.Location.Line, entry.Source)
+		}
+	}
+}
+
+// localSourceLine returns the line of the location from the local contract file, if the contract is in the state.
+func localSourceLine(location *flowkit.CadenceErrorLocation, state *flowkit.State) string {
+	_, name, ok := location.Contract()
+	if !ok || state == nil {
+		return ""
+	}
+
+	contract, err := state.Contracts().ByName(name)
+	if err != nil {
+		return ""
+	}
+
+	code, err := state.ReadFile(contract.Location)
+	if err != nil {
+		return ""
+	}
+
+	lines := strings.Split(string(code), "\n")
+	if location.Line < 1 || location.Line > len(lines) {
+		return ""
+	}
+
+	return strings.TrimSpace(lines[location.Line-1])
+}
+
 // handleError handle errors returned from command execution, try to understand why error happens and offer help to the user.
 func handleError(description string, err error) {
 	if err == nil {
@@ -149,6 +198,9 @@ func handleError(description string, err error) {
 		} else if strings.Contains(err.Error(), "transport:") {
 			_, _ = fmt.Fprintf(os.Stderr, "%s %s \n", output.ErrorEmoji(), strings.Split(err.Error(), "transport:")[1])
 			_, _ = fmt.Fprintf(os.Stderr, "%s Make sure your emulator is running or connection address is correct.", output.TryEmoji())
+		} else if errors.Is(err, flowkit.ErrAccountNotFound) {
+			_, _ = fmt.Fprintf(os.Stderr, "%s Not Found: %s \n", output.ErrorEmoji(), err.Error())
+			_, _ = fmt.Fprintf(os.Stderr, "%s Check you are connecting to the correct network or account name or address you use is correct.", output.TryEmoji())
 		} else if strings.Contains(err.Error(), "NotFound desc =") {
 			_, _ = fmt.Fprintf(os.Stderr, "%s Not Found:%s \n", output.ErrorEmoji(), strings.Split(err.Error(), "NotFound desc =")[1])
 		} else if strings.Contains(err.Error(), "code = InvalidArgument desc = ") {
@@ -27,7 +27,9 @@ import (
 	"github.com/onflow/flow-cli/pkg/flowkit/services"
 )
 
-type flagsRemoveAccount struct{}
+type flagsRemoveAccount struct {
+	RemoveDeployments bool `default:"false" flag:"remove-deployments" info:"Remove the deployments to the account"`
+}
 
 var removeAccountFlags = flagsRemoveAccount{}
 
@@ -56,7 +58,9 @@ func removeAccount(
 		name = output.RemoveAccountPrompt(state.Config().Accounts)
 	}
 
-	err := state.Accounts().Remove(name)
+	err := state.RemoveAccount(name, flowkit.RemoveAccountOptions{
+		RemoveDeployments: removeAccountFlags.RemoveDeployments,
+	})
 	if err != nil {
 		return nil, err
 	}
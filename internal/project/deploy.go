@@ -21,6 +21,8 @@ package project
 import (
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -33,7 +35,13 @@ import (
 )
 
 type flagsDeploy struct {
-	Update bool `flag:"update" default:"false" info:"use update flag to update existing contracts"`
+	Update        bool     `flag:"update" default:"false" info:"use update flag to update existing contracts"`
+	Batch         bool     `flag:"batch" default:"false" info:"deploy contracts for the same account in a single transaction"`
+	Fallback      bool     `flag:"batch-fallback" default:"false" info:"deploy contracts individually if a batch transaction fails"`
+	Metadata      []string `flag:"metadata" default:"" info:"metadata recorded in the lock file for deployed contracts as comma-separated key=value pairs, e.g. commit=3f2a1b"`
+	Create        bool     `flag:"create-missing" default:"false" info:"create deployment accounts that don't exist on the network with their configured key"`
+	Creator       string   `flag:"creator" default:"" info:"account name paying for creating missing accounts, defaults to the service account on the emulator"`
+	SkipPreflight bool     `flag:"skip-preflight" default:"false" info:"skip checking the keys of the deployment accounts before deploying"`
 }
 
 var deployFlags = flagsDeploy{}
@@ -53,7 +61,7 @@ func deploy(
 	_ flowkit.ReaderWriter,
 	globalFlags command.GlobalFlags,
 	srv *services.Services,
-	_ *flowkit.State,
+	state *flowkit.State,
 ) (command.Result, error) {
 
 	//precheck for standard contract on Mainnet
@@ -65,7 +73,40 @@ func deploy(
 
 	}
 
-	c, err := srv.Project.Deploy(globalFlags.Network, deployFlags.Update)
+	metadata, err := parseMetadata(deployFlags.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	var creator *flowkit.Account
+	if deployFlags.Creator != "" {
+		creator, err = state.Accounts().ByName(deployFlags.Creator)
+		if err != nil {
+			return nil, fmt.Errorf("creator account %s not found in configuration", deployFlags.Creator)
+		}
+	}
+
+	start := time.Now()
+	c, err := srv.Project.DeployWithOptions(
+		globalFlags.Network,
+		deployFlags.Update,
+		services.DeployOptions{
+			Batch:                deployFlags.Batch,
+			FallbackToIndividual: deployFlags.Fallback,
+			Metadata:             metadata,
+			CreateMissing:        deployFlags.Create,
+			Creator:              creator,
+			SkipPreflight:        deployFlags.SkipPreflight,
+		},
+	)
+
+	// created accounts may be assigned different addresses than configured
+	if deployFlags.Create {
+		if saveErr := state.SaveDefault(); saveErr != nil {
+			return nil, saveErr
+		}
+	}
+
 	if err != nil {
 		var projectErr *services.ProjectDeploymentError
 		if errors.As(err, &projectErr) {
@@ -83,18 +124,53 @@ func deploy(
 		return nil, err
 	}
 
-	return &DeployResult{c}, nil
+	return &DeployResult{
+		contracts:     c,
+		totalDuration: time.Since(start),
+	}, nil
+}
+
+// parseMetadata parses the deployment metadata provided as key=value pairs.
+func parseMetadata(pairs []string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+
+	metadata := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, found := strings.Cut(pair, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid metadata %s, use key=value format", pair)
+		}
+		metadata[key] = value
+	}
+
+	return metadata, nil
 }
 
 type DeployResult struct {
-	contracts []*project.Contract
+	contracts     []*project.Contract
+	totalDuration time.Duration
 }
 
+// deploymentKey is the key of the deployment durations in the JSON result, it can't collide with a contract name
+// as it isn't a Cadence identifier.
+const deploymentKey = "$deployment"
+
+// JSON maps the name of each contract to its address, the deployment durations in milliseconds are under the
+// deploymentKey.
 func (r *DeployResult) JSON() interface{} {
-	result := make(map[string]string)
+	result := make(map[string]interface{})
+	durations := make(map[string]int64)
 
 	for _, contract := range r.contracts {
 		result[contract.Name] = contract.AccountAddress.String()
+		durations[contract.Name] = contract.DeploymentDuration.Milliseconds()
+	}
+
+	result[deploymentKey] = map[string]interface{}{
+		"durationsMs":     durations,
+		"totalDurationMs": r.totalDuration.Milliseconds(),
 	}
 
 	return result
@@ -0,0 +1,54 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package project
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/onflow/flow-cli/pkg/flowkit/project"
+)
+
+func Test_DeployResultJSON(t *testing.T) {
+	// contracts named like the duration keys don't collide with them
+	result := &DeployResult{
+		contracts: []*project.Contract{{
+			Name:               "durationsMs",
+			AccountAddress:     flow.HexToAddress("01"),
+			DeploymentDuration: 20 * time.Millisecond,
+		}, {
+			Name:               "totalDurationMs",
+			AccountAddress:     flow.HexToAddress("02"),
+			DeploymentDuration: 30 * time.Millisecond,
+		}},
+		totalDuration: 60 * time.Millisecond,
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"durationsMs":     "0000000000000001",
+		"totalDurationMs": "0000000000000002",
+		"$deployment": map[string]interface{}{
+			"durationsMs":     map[string]int64{"durationsMs": 20, "totalDurationMs": 30},
+			"totalDurationMs": int64(60),
+		},
+	}, result.JSON())
+}
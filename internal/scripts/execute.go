@@ -58,6 +58,10 @@ func execute(
 	if err != nil {
 		return nil, fmt.Errorf("error loading script file: %w", err)
 	}
+	code, err = flowkit.SanitizeSource(filename, code, flowkit.SourceLimits{})
+	if err != nil {
+		return nil, err
+	}
 
 	var scriptArgs []cadence.Value
 	if scriptFlags.ArgsJSON != "" {
@@ -184,13 +184,14 @@ func (p *project) addAccount(name string) error {
 	}
 
 	// create the account on the network and set the address
-	flowAcc, err := p.services.Accounts.Create(
+	flowAcc, err := p.services.Accounts.CreateForNetwork(
 		p.service,
 		[]crypto.PublicKey{pkey.PublicKey()},
 		[]int{flow.AccountKeyWeightThreshold},
 		[]crypto.SignatureAlgorithm{crypto.ECDSA_P256},
 		[]crypto.HashAlgorithm{crypto.SHA3_256},
 		nil,
+		network,
 	)
 	if err != nil {
 		return err
@@ -39,6 +39,8 @@ type flagsBuild struct {
 	Payer            string   `default:"emulator-account" flag:"payer" info:"transaction payer"`
 	Authorizer       []string `default:"emulator-account" flag:"authorizer" info:"transaction authorizer"`
 	GasLimit         uint64   `default:"1000" flag:"gas-limit" info:"transaction gas limit"`
+	Offline          bool     `default:"false" flag:"offline" info:"transaction will be signed offline, warn if it expires too soon"`
+	MinValidity      uint64   `default:"300" flag:"min-validity" info:"minimum number of blocks the transaction must remain valid when signing offline"`
 }
 
 var buildFlags = flagsBuild{}
@@ -86,6 +88,10 @@ func build(
 	if err != nil {
 		return nil, fmt.Errorf("error loading transaction file: %w", err)
 	}
+	code, err = flowkit.SanitizeSource(filename, code, flowkit.SourceLimits{})
+	if err != nil {
+		return nil, err
+	}
 
 	var transactionArgs []cadence.Value
 	if buildFlags.ArgsJSON != "" {
@@ -97,24 +103,30 @@ func build(
 		return nil, fmt.Errorf("error parsing transaction arguments: %w", err)
 	}
 
-	tx, err := srv.Transactions.Build(
+	result, err := srv.Transactions.BuildWithOptions(
 		services.NewTransactionAddresses(proposer, payer, authorizers),
 		buildFlags.ProposerKeyIndex,
 		flowkit.NewScript(code, transactionArgs, filename),
 		buildFlags.GasLimit,
 		globalFlags.Network,
+		services.BuildOptions{
+			OfflineSigning:        buildFlags.Offline,
+			MinimumValidityBlocks: buildFlags.MinValidity,
+		},
 	)
 	if err != nil {
 		return nil, err
 	}
+	tx := result.Transaction
 
-	if !globalFlags.Yes && !output.ApproveTransactionForBuildingPrompt(tx) {
+	if !globalFlags.Yes && !output.ApproveTransactionForBuildingPrompt(tx, srv.Redactor) {
 		return nil, fmt.Errorf("transaction was not approved")
 	}
 
 	return &TransactionResult{
-		tx:      tx.FlowTransaction(),
-		include: []string{"code", "payload", "signatures"},
+		tx:       tx.FlowTransaction(),
+		include:  []string{"code", "payload", "signatures"},
+		redactor: srv.Redactor,
 	}, nil
 }
 
@@ -64,7 +64,8 @@ func decode(
 	}
 
 	return &TransactionResult{
-		tx:      tx.FlowTransaction(),
-		include: decodeFlags.Include,
+		tx:       tx.FlowTransaction(),
+		include:  decodeFlags.Include,
+		redactor: services.Redactor,
 	}, nil
 }
@@ -63,9 +63,10 @@ func get(
 	}
 
 	return &TransactionResult{
-		result:  result,
-		tx:      tx,
-		include: getFlags.Include,
-		exclude: getFlags.Exclude,
+		result:   result,
+		tx:       tx,
+		include:  getFlags.Include,
+		exclude:  getFlags.Exclude,
+		redactor: services.Redactor,
 	}, nil
 }
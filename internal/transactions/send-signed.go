@@ -66,7 +66,7 @@ func sendSigned(
 		return nil, err
 	}
 
-	if !globalFlags.Yes && !output.ApproveTransactionForSendingPrompt(tx) {
+	if !globalFlags.Yes && !output.ApproveTransactionForSendingPrompt(tx, services.Redactor) {
 		return nil, fmt.Errorf("transaction was not approved for sending")
 	}
 
@@ -76,9 +76,10 @@ func sendSigned(
 	}
 
 	return &TransactionResult{
-		result:  result,
-		tx:      sentTx,
-		include: sendSignedFlags.Include,
-		exclude: sendSignedFlags.Exclude,
+		result:   result,
+		tx:       sentTx,
+		include:  sendSignedFlags.Include,
+		exclude:  sendSignedFlags.Exclude,
+		redactor: services.Redactor,
 	}, nil
 }
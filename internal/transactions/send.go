@@ -112,6 +112,10 @@ func send(
 	if err != nil {
 		return nil, fmt.Errorf("error loading transaction file: %w", err)
 	}
+	code, err = flowkit.SanitizeSource(codeFilename, code, flowkit.SourceLimits{})
+	if err != nil {
+		return nil, err
+	}
 
 	var transactionArgs []cadence.Value
 	if sendFlags.ArgsJSON != "" {
@@ -139,9 +143,10 @@ func send(
 	}
 
 	return &TransactionResult{
-		result:  txResult,
-		tx:      tx,
-		include: sendFlags.Include,
-		exclude: sendFlags.Exclude,
+		result:   txResult,
+		tx:       tx,
+		include:  sendFlags.Include,
+		exclude:  sendFlags.Exclude,
+		redactor: srv.Redactor,
 	}, nil
 }
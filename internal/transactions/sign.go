@@ -105,7 +105,7 @@ func sign(
 	})
 
 	for _, signer := range signers {
-		if !globalFlags.Yes && !output.ApproveTransactionForSigningPrompt(tx) {
+		if !globalFlags.Yes && !output.ApproveTransactionForSigningPrompt(tx, services.Redactor) {
 			return nil, fmt.Errorf("transaction was not approved for signing")
 		}
 
@@ -128,7 +128,8 @@ func sign(
 	}
 
 	return &TransactionResult{
-		tx:      signed.FlowTransaction(),
-		include: signFlags.Include,
+		tx:       signed.FlowTransaction(),
+		include:  signFlags.Include,
+		redactor: services.Redactor,
 	}, nil
 }
@@ -28,6 +28,7 @@ import (
 
 	"github.com/onflow/flow-cli/internal/command"
 	"github.com/onflow/flow-cli/internal/events"
+	"github.com/onflow/flow-cli/pkg/flowkit"
 	"github.com/onflow/flow-cli/pkg/flowkit/output"
 	"github.com/onflow/flow-cli/pkg/flowkit/util"
 )
@@ -49,10 +50,11 @@ func init() {
 }
 
 type TransactionResult struct {
-	result  *flow.TransactionResult
-	tx      *flow.Transaction
-	include []string
-	exclude []string
+	result   *flow.TransactionResult
+	tx       *flow.Transaction
+	include  []string
+	exclude  []string
+	redactor *flowkit.Redactor
 }
 
 func (r *TransactionResult) JSON() interface{} {
@@ -163,7 +165,7 @@ func (r *TransactionResult) String() string {
 				_, _ = fmt.Fprintf(writer, "\n\nArguments\tNo arguments\n")
 			} else {
 				_, _ = fmt.Fprintf(writer, "\n\nArguments (%d):\n", len(r.tx.Arguments))
-				for i, argument := range r.tx.Arguments {
+				for i, argument := range r.redactor.Arguments(r.tx) {
 					_, _ = fmt.Fprintf(writer, "    - Argument %d: %s\n", i, argument)
 				}
 			}
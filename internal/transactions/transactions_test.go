@@ -0,0 +1,54 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package transactions
+
+import (
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-cli/pkg/flowkit"
+)
+
+func TestTransactionResultRedaction(t *testing.T) {
+	script := []byte(`transaction(apiKey: String, user: String) {}`)
+	tx := flow.NewTransaction().SetScript(script)
+	require.NoError(t, tx.AddArgument(cadence.String("secret-api-key")))
+	require.NoError(t, tx.AddArgument(cadence.String("alice")))
+
+	result := &TransactionResult{
+		tx:      tx,
+		include: []string{"code"},
+		redactor: flowkit.NewRedactor(flowkit.RedactionRule{
+			ScriptHash:    flowkit.ScriptHash(script),
+			ArgumentIndex: 0,
+		}),
+	}
+
+	output := result.String()
+	assert.NotContains(t, output, "secret-api-key")
+	assert.Contains(t, output, "Argument 0: [redacted]")
+	assert.Contains(t, output, "alice")
+
+	result.redactor = nil
+	assert.Contains(t, result.String(), "secret-api-key")
+}
@@ -19,6 +19,8 @@
 package flowkit
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -29,11 +31,19 @@ import (
 	"github.com/onflow/flow-cli/pkg/flowkit/util"
 )
 
+// serviceAccountAddresses contains the canonical service account address for each known chain.
+var serviceAccountAddresses = map[flow.ChainID]flow.Address{
+	flow.Emulator: flow.HexToAddress("f8d6e0586b0a20c7"),
+	flow.Testnet:  flow.HexToAddress("8c5303eaa26202d6"),
+	flow.Mainnet:  flow.HexToAddress("e467b9dd11fa00df"),
+}
+
 // Account is a flowkit-specific account implementation.
 type Account struct {
 	name    string
 	address flow.Address
 	key     AccountKey
+	tags    []string
 }
 
 // NewAccount creates an empty account with the provided name.
@@ -95,6 +105,54 @@ func NewAccountFromOnChainAccount(
 	return account, nil
 }
 
+// CheckKeySigAlgo compares the account key with the key at the same index of the on-chain account and
+// returns a config.SigAlgoMismatchError if the key is registered with a different signature algorithm
+// than the configured one.
+//
+// Keys that don't expose the private key, like KMS keys, and keys not found on-chain are not checked.
+func (a *Account) CheckKeySigAlgo(onChainAccount *flow.Account) error {
+	if a.key == nil || onChainAccount == nil {
+		return nil
+	}
+
+	var onChainKey *flow.AccountKey
+	for _, key := range onChainAccount.Keys {
+		if key != nil && key.Index == a.key.Index() {
+			onChainKey = key
+			break
+		}
+	}
+	if onChainKey == nil || onChainKey.PublicKey == nil {
+		return nil
+	}
+
+	privateKey, err := a.key.PrivateKey()
+	if err != nil || privateKey == nil || *privateKey == nil {
+		return nil
+	}
+
+	if (*privateKey).PublicKey().Equals(onChainKey.PublicKey) {
+		return nil
+	}
+
+	// the same scalar is a valid key on both curves, so the key matches the on-chain key if the algorithm is swapped
+	alternative := config.AlternativeSigAlgo(a.key.SigAlgo())
+	if alternative == crypto.UnknownSignatureAlgorithm {
+		return nil
+	}
+
+	alternativeKey, err := crypto.DecodePrivateKey(alternative, (*privateKey).Encode())
+	if err != nil || !alternativeKey.PublicKey().Equals(onChainKey.PublicKey) {
+		return nil
+	}
+
+	return &config.SigAlgoMismatchError{
+		Account:  a.name,
+		Declared: a.key.SigAlgo(),
+		Actual:   alternative,
+	}
+}
+
 // Address get account address.
 func (a *Account) Address() flow.Address {
 	return a.address
@@ -110,6 +168,16 @@ func (a *Account) Key() AccountKey {
 	return a.key
 }
 
+// IsServiceAccount returns true if the account address is the service account address of the provided chain.
+func (a *Account) IsServiceAccount(network flow.ChainID) bool {
+	address, ok := serviceAccountAddresses[network]
+	if !ok {
+		return false
+	}
+
+	return a.address == address
+}
+
 // SetAddress sets the account address.
 func (a *Account) SetAddress(address flow.Address) *Account {
 	a.address = address
@@ -128,6 +196,109 @@ func (a *Account) SetKey(key AccountKey) *Account {
 	return a
 }
 
+// Tags get account tags.
+func (a *Account) Tags() []string {
+	return a.tags
+}
+
+// HasTag returns true if the account is tagged with the tag.
+func (a *Account) HasTag(tag string) bool {
+	for _, t := range a.tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// SetTags sets the account tags.
+func (a *Account) SetTags(tags []string) *Account {
+	a.tags = tags
+	return a
+}
+
+// Sign signs the message with the account key, the message is hashed with the hash algorithm of the key.
+func (a *Account) Sign(msg []byte) ([]byte, error) {
+	signer, err := a.key.Signer(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return signer.Sign(msg)
+}
+
+// SignRaw signs the message without hashing it, the message is signed as if it was the digest.
+//
+// The message should be as long as the order of the signature curve, longer messages are truncated
+// to the curve order by ECDSA. Only keys with a private key available locally can sign raw messages.
+func (a *Account) SignRaw(msg []byte) ([]byte, error) {
+	if len(msg) == 0 {
+		return nil, fmt.Errorf("message to sign is empty")
+	}
+	return a.signDigest(msg)
+}
+
+// SignHashed signs the hash of a message computed with the hash algorithm of the account key.
+//
+// The signature verifies against the original message with the account key hash algorithm, like
+// a signature made by Sign. An error is returned if the hash length doesn't match the hash algorithm.
+func (a *Account) SignHashed(hash []byte) ([]byte, error) {
+	hasher, err := crypto.NewHasher(a.key.HashAlgo())
+	if err != nil {
+		return nil, err
+	}
+	if len(hash) != hasher.Size() {
+		return nil, fmt.Errorf(
+			"invalid %s hash length %d, expected %d bytes",
+			a.key.HashAlgo(),
+			len(hash),
+			hasher.Size(),
+		)
+	}
+	return a.signDigest(hash)
+}
+
+// signDigest signs the digest with the private key of the account key without hashing it again.
+func (a *Account) signDigest(digest []byte) ([]byte, error) {
+	privateKey, err := a.key.PrivateKey()
+	if err != nil {
+		return nil, fmt.Errorf("signing without hashing is not supported for %s keys: %w", a.key.Type(), err)
+	}
+	return (*privateKey).Sign(digest, &digestHasher{algo: a.key.HashAlgo()})
+}
+
+// digestHasher is a hasher returning the data as its hash, used to sign data that is already a digest.
+type digestHasher struct {
+	algo crypto.HashAlgorithm
+	data []byte
+}
+
+var _ crypto.Hasher = &digestHasher{}
+
+func (d *digestHasher) Algorithm() crypto.HashAlgorithm {
+	return d.algo
+}
+
+func (d *digestHasher) Size() int {
+	return len(d.data)
+}
+
+func (d *digestHasher) ComputeHash(data []byte) crypto.Hash {
+	return append(crypto.Hash{}, data...)
+}
+
+func (d *digestHasher) Write(p []byte) (int, error) {
+	d.data = append(d.data, p...)
+	return len(p), nil
+}
+
+func (d *digestHasher) SumHash() crypto.Hash {
+	return append(crypto.Hash{}, d.data...)
+}
+
+func (d *digestHasher) Reset() {
+	d.data = nil
+}
+
 func accountsFromConfig(conf *config.Config) (Accounts, error) {
 	var accounts Accounts
 	for _, accountConf := range conf.Accounts {
@@ -161,6 +332,7 @@ func fromConfig(account config.Account) (*Account, error) {
 		name:    account.Name,
 		address: account.Address,
 		key:     key,
+		tags:    account.Tags,
 	}, nil
 }
 
@@ -182,6 +354,7 @@ func toConfig(account Account, accountLocations map[string]string) config.Accoun
 		Name:    account.name,
 		Address: account.address,
 		Key:     key,
+		Tags:    account.tags,
 	}
 }
 
@@ -203,27 +376,24 @@ func generateEmulatorServiceAccount(sigAlgo crypto.SignatureAlgorithm, hashAlgo
 	}, nil
 }
 
+// ErrAccountNotFound is returned when no account with the name exists in the configuration.
+var ErrAccountNotFound = errors.New("account not found")
+
 // Accounts is a collection of account.
 type Accounts []Account
 
-// Remove an account.
+// Remove an account, ErrAccountNotFound is returned if no account has the name.
+//
+// Use State.RemoveAccount to also remove the references to the account from the rest of the state.
 func (a *Accounts) Remove(name string) error {
-	account, err := a.ByName(name)
-	if err != nil {
-		return err
-	}
-
-	if account == nil {
-		return fmt.Errorf("account named %s does not exist in configuration", name)
-	}
-
 	for i, acc := range *a {
 		if acc.name == name {
 			*a = append((*a)[0:i], (*a)[i+1:]...) // remove item
+			return nil
 		}
 	}
 
-	return nil
+	return fmt.Errorf("account named %s does not exist in configuration: %w", name, ErrAccountNotFound)
 }
 
 func (a Accounts) String() string {
@@ -0,0 +1,219 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package flowkit
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-cli/pkg/flowkit/config"
+)
+
+func TestAccount_IsServiceAccount(t *testing.T) {
+	tests := []struct {
+		network flow.ChainID
+		address string
+	}{
+		{flow.Emulator, "f8d6e0586b0a20c7"},
+		{flow.Testnet, "8c5303eaa26202d6"},
+		{flow.Mainnet, "e467b9dd11fa00df"},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.network), func(t *testing.T) {
+			account := NewAccount("service").SetAddress(flow.HexToAddress(test.address))
+			assert.True(t, account.IsServiceAccount(test.network))
+
+			for _, other := range tests {
+				if other.network == test.network {
+					continue
+				}
+				assert.False(t, account.IsServiceAccount(other.network))
+			}
+		})
+	}
+
+	t.Run("Not service account", func(t *testing.T) {
+		account := NewAccount("alice").SetAddress(flow.HexToAddress("01"))
+		assert.False(t, account.IsServiceAccount(flow.Emulator))
+		assert.False(t, account.IsServiceAccount(flow.Testnet))
+		assert.False(t, account.IsServiceAccount(flow.Mainnet))
+	})
+
+	t.Run("Unknown network", func(t *testing.T) {
+		account := NewAccount("service").SetAddress(flow.HexToAddress("f8d6e0586b0a20c7"))
+		assert.False(t, account.IsServiceAccount(flow.ChainID("flow-unknown")))
+	})
+}
+
+func TestAccount_CheckKeySigAlgo(t *testing.T) {
+	const keyHex = "dd72967fd2bd75234ae9037dd4694c1f00baad63a10c35172bf65fbb8ad74b47"
+
+	onChainAccount := func(sigAlgo crypto.SignatureAlgorithm) *flow.Account {
+		key, err := crypto.DecodePrivateKeyHex(sigAlgo, keyHex)
+		require.NoError(t, err)
+		return &flow.Account{
+			Address: flow.HexToAddress("01"),
+			Keys: []*flow.AccountKey{{
+				Index:     0,
+				PublicKey: key.PublicKey(),
+				SigAlgo:   sigAlgo,
+				HashAlgo:  crypto.SHA3_256,
+				Weight:    flow.AccountKeyWeightThreshold,
+			}},
+		}
+	}
+
+	configuredAccount := func(sigAlgo crypto.SignatureAlgorithm) *Account {
+		key, err := crypto.DecodePrivateKeyHex(sigAlgo, keyHex)
+		require.NoError(t, err)
+		return NewAccount("admin").
+			SetAddress(flow.HexToAddress("01")).
+			SetKey(NewHexAccountKeyFromPrivateKey(0, crypto.SHA3_256, key))
+	}
+
+	tests := []struct {
+		onChain    crypto.SignatureAlgorithm
+		configured crypto.SignatureAlgorithm
+		err        string
+	}{
+		{onChain: crypto.ECDSA_P256, configured: crypto.ECDSA_P256},
+		{onChain: crypto.ECDSA_secp256k1, configured: crypto.ECDSA_secp256k1},
+		{
+			onChain:    crypto.ECDSA_secp256k1,
+			configured: crypto.ECDSA_P256,
+			err:        "key for account 'admin' parses as ECDSA_secp256k1 but config declares ECDSA_P256, fix the sigAlgorithm field",
+		},
+		{
+			onChain:    crypto.ECDSA_P256,
+			configured: crypto.ECDSA_secp256k1,
+			err:        "key for account 'admin' parses as ECDSA_P256 but config declares ECDSA_secp256k1, fix the sigAlgorithm field",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s key configured as %s", test.onChain, test.configured), func(t *testing.T) {
+			err := configuredAccount(test.configured).CheckKeySigAlgo(onChainAccount(test.onChain))
+			if test.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			var mismatchErr *config.SigAlgoMismatchError
+			require.ErrorAs(t, err, &mismatchErr)
+			assert.Equal(t, test.configured, mismatchErr.Declared)
+			assert.Equal(t, test.onChain, mismatchErr.Actual)
+			assert.EqualError(t, err, test.err)
+		})
+	}
+
+	t.Run("Different Key", func(t *testing.T) {
+		other, err := crypto.DecodePrivateKeyHex(crypto.ECDSA_P256, "388e3fbdc654b765942610679bb3a66b74212149ab9482187067ee116d9a8118")
+		require.NoError(t, err)
+		account := NewAccount("admin").SetKey(NewHexAccountKeyFromPrivateKey(0, crypto.SHA3_256, other))
+
+		assert.NoError(t, account.CheckKeySigAlgo(onChainAccount(crypto.ECDSA_secp256k1)))
+	})
+
+	t.Run("Key Not On-Chain", func(t *testing.T) {
+		account := configuredAccount(crypto.ECDSA_P256)
+		onChain := onChainAccount(crypto.ECDSA_secp256k1)
+		onChain.Keys[0].Index = 1
+
+		assert.NoError(t, account.CheckKeySigAlgo(onChain))
+	})
+}
+
+func TestAccount_Sign(t *testing.T) {
+	privateKey, err := crypto.DecodePrivateKeyHex(
+		crypto.ECDSA_P256,
+		"dd72967fd2bd75234ae9037dd4694c1f00baad63a10c35172bf65fbb8ad74b47",
+	)
+	require.NoError(t, err)
+	publicKey := privateKey.PublicKey()
+	account := NewAccount("alice").
+		SetAddress(flow.HexToAddress("01")).
+		SetKey(NewHexAccountKeyFromPrivateKey(0, crypto.SHA3_256, privateKey))
+
+	msg := []byte("message to sign")
+	hasher, err := crypto.NewHasher(crypto.SHA3_256)
+	require.NoError(t, err)
+	hash := hasher.ComputeHash(msg)
+
+	t.Run("Hashing", func(t *testing.T) {
+		sig, err := account.Sign(msg)
+		require.NoError(t, err)
+
+		valid, err := publicKey.Verify(sig, msg, hasher)
+		require.NoError(t, err)
+		assert.True(t, valid)
+	})
+
+	t.Run("Pre-hashed", func(t *testing.T) {
+		sig, err := account.SignHashed(hash)
+		require.NoError(t, err)
+
+		valid, err := publicKey.Verify(sig, msg, hasher)
+		require.NoError(t, err)
+		assert.True(t, valid)
+
+		_, err = account.SignHashed(hash[:16])
+		assert.EqualError(t, err, "invalid SHA3_256 hash length 16, expected 32 bytes")
+	})
+
+	t.Run("Raw", func(t *testing.T) {
+		// the raw message is signed as is, so signing the hash verifies against the message
+		sig, err := account.SignRaw(hash)
+		require.NoError(t, err)
+
+		valid, err := publicKey.Verify(sig, msg, hasher)
+		require.NoError(t, err)
+		assert.True(t, valid)
+
+		// signing the message itself doesn't hash it
+		sig, err = account.SignRaw(msg)
+		require.NoError(t, err)
+		valid, err = publicKey.Verify(sig, msg, hasher)
+		require.NoError(t, err)
+		assert.False(t, valid)
+
+		_, err = account.SignRaw(nil)
+		assert.EqualError(t, err, "message to sign is empty")
+	})
+
+	t.Run("KMS key", func(t *testing.T) {
+		kmsKey, err := NewAccountKey(config.AccountKey{
+			Type:       config.KeyTypeGoogleKMS,
+			SigAlgo:    crypto.ECDSA_P256,
+			HashAlgo:   crypto.SHA3_256,
+			ResourceID: "projects/flow/locations/global/keyRings/flow/cryptoKeys/signer/cryptoKeyVersions/1",
+		})
+		require.NoError(t, err)
+		kmsAccount := NewAccount("kms").SetKey(kmsKey)
+
+		_, err = kmsAccount.SignRaw(hash)
+		assert.ErrorContains(t, err, "signing without hashing is not supported for google-kms keys")
+		_, err = kmsAccount.SignHashed(hash)
+		assert.ErrorContains(t, err, "signing without hashing is not supported for google-kms keys")
+	})
+}
@@ -21,6 +21,7 @@ package flowkit
 import (
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -31,6 +32,7 @@ import (
 	"github.com/onflow/cadence/runtime/cmd"
 	"github.com/onflow/cadence/runtime/common"
 	"github.com/onflow/cadence/runtime/interpreter"
+	"github.com/onflow/cadence/runtime/parser"
 	"github.com/onflow/cadence/runtime/sema"
 )
 
@@ -144,6 +146,8 @@ func ParseArgumentsWithoutType(fileName string, code []byte, args []string) (scr
 		return nil, err
 	}
 
+	imports := addressImports(program)
+
 	for index, argumentString := range args {
 		astType := parameterList[index].TypeAnnotation.Type
 		semaType := checker.ConvertType(astType)
@@ -152,6 +156,9 @@ func ParseArgumentsWithoutType(fileName string, code []byte, args []string) (scr
 			switch v := semaType.(type) {
 			case *sema.OptionalType:
 				semaType = v.Type
+				if optional, ok := astType.(*ast.OptionalType); ok {
+					astType = optional.Type
+				}
 				continue
 
 			case *sema.SimpleType:
@@ -169,15 +176,292 @@ func ParseArgumentsWithoutType(fileName string, code []byte, args []string) (scr
 			break
 		}
 
-		var value, err = runtime.ParseLiteral(argumentString, semaType, inter)
+		var value cadence.Value
+		simpleType, _ := semaType.(*sema.SimpleType)
+		domains, isPath := pathTypeDomains[simpleType]
+		_, isCapability := semaType.(*sema.CapabilityType)
+
+		switch {
+		case isPath:
+			value, err = parsePathArgument(argumentString, domains)
+		case simpleType == sema.MetaType:
+			value, err = parseTypeArgument(argumentString, imports)
+		case isCapability:
+			value, err = parseCapabilityArgument(argumentString, astType, imports)
+		default:
+			value, err = runtime.ParseLiteral(argumentString, semaType, inter)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"argument `%s` is not expected type `%s`",
+					parameterList[index].Identifier,
+					semaType.QualifiedString(),
+				)
+			}
+		}
 		if err != nil {
 			return nil, fmt.Errorf(
-				"argument `%s` is not expected type `%s`",
+				"argument `%s` is not a valid `%s`: %w",
 				parameterList[index].Identifier,
-				semaType.QualifiedString(),
+				astType,
+				err,
 			)
 		}
 		resultArgs = append(resultArgs, value)
 	}
 	return resultArgs, nil
 }
+
+// ParseArgumentWithType converts the value of the named argument to the declared Cadence type.
+//
+// The value is written the same way as the values parsed by ParseArgumentsWithoutType,
+// nil is accepted for optional types.
+func ParseArgumentWithType(name string, argType string, value string) (cadence.Value, error) {
+	if !identifierRegex.MatchString(name) {
+		return nil, fmt.Errorf("invalid argument name `%s`", name)
+	}
+
+	ty, errs := parser.ParseType(nil, []byte(argType), parser.Config{})
+	if len(errs) > 0 || ty == nil {
+		return nil, fmt.Errorf("argument `%s` has invalid type `%s`", name, argType)
+	}
+	if _, ok := ty.(*ast.OptionalType); ok && value == "nil" {
+		return cadence.NewOptional(nil), nil
+	}
+
+	code := fmt.Sprintf("pub fun main(%s: %s) {}", name, argType)
+	values, err := ParseArgumentsWithoutType("", []byte(code), []string{value})
+	if err != nil {
+		return nil, err
+	}
+
+	return values[0], nil
+}
+
+// pathTypeDomains are the path domains each path type can have.
+var pathTypeDomains = map[*sema.SimpleType][]common.PathDomain{
+	sema.PathType:           {common.PathDomainStorage, common.PathDomainPublic, common.PathDomainPrivate},
+	sema.StoragePathType:    {common.PathDomainStorage},
+	sema.CapabilityPathType: {common.PathDomainPublic, common.PathDomainPrivate},
+	sema.PublicPathType:     {common.PathDomainPublic},
+	sema.PrivatePathType:    {common.PathDomainPrivate},
+}
+
+var identifierRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// addressImports returns the locations of the contracts the program imports from addresses by contract name.
+func addressImports(program *ast.Program) map[string]common.AddressLocation {
+	imports := make(map[string]common.AddressLocation)
+	for _, declaration := range program.ImportDeclarations() {
+		location, ok := declaration.Location.(common.AddressLocation)
+		if !ok {
+			continue
+		}
+		for _, identifier := range declaration.Identifiers {
+			imports[identifier.Identifier] = common.AddressLocation{Address: location.Address, Name: identifier.Identifier}
+		}
+	}
+	return imports
+}
+
+// parsePathArgument parses a path literal such as /storage/flowTokenVault with one of the domains.
+func parsePathArgument(value string, domains []common.PathDomain) (cadence.Value, error) {
+	parts := strings.Split(value, "/")
+	if len(parts) != 3 || parts[0] != "" || parts[1] == "" || parts[2] == "" {
+		return nil, fmt.Errorf("malformed path `%s`, expected /<domain>/<identifier>", value)
+	}
+
+	domain := common.PathDomainFromIdentifier(parts[1])
+	allowed := make([]string, len(domains))
+	valid := false
+	for i, d := range domains {
+		allowed[i] = d.Identifier()
+		valid = valid || d == domain
+	}
+	if !valid {
+		return nil, fmt.Errorf("invalid path domain `%s`, expected %s", parts[1], strings.Join(allowed, " or "))
+	}
+
+	if !identifierRegex.MatchString(parts[2]) {
+		return nil, fmt.Errorf("invalid path identifier `%s`", parts[2])
+	}
+
+	return cadence.NewPath(parts[1], parts[2]), nil
+}
+
+// parseCapabilityArgument parses a capability given as <address><path> such as 0x01/public/flowTokenReceiver.
+//
+// The borrow type is taken from the declared capability type, so it must be declared and be a reference.
+func parseCapabilityArgument(
+	value string,
+	declared ast.Type,
+	imports map[string]common.AddressLocation,
+) (cadence.Value, error) {
+	instantiation, ok := declared.(*ast.InstantiationType)
+	if !ok || len(instantiation.TypeArguments) != 1 {
+		return nil, fmt.Errorf("the capability borrow type must be declared, e.g. Capability<&Vault>")
+	}
+
+	borrowType, err := astArgumentType(instantiation.TypeArguments[0].Type, imports)
+	if err != nil {
+		return nil, err
+	}
+	if _, isReference := borrowType.(cadence.ReferenceType); !isReference {
+		return nil, fmt.Errorf("the capability borrow type must be a reference")
+	}
+
+	separator := strings.Index(value, "/")
+	if separator <= 0 {
+		return nil, fmt.Errorf("malformed capability `%s`, expected <address>/<domain>/<identifier>", value)
+	}
+
+	address, err := hexToAddress(value[:separator])
+	if err != nil {
+		return nil, fmt.Errorf("invalid capability address `%s`", value[:separator])
+	}
+
+	path, err := parsePathArgument(value[separator:], pathTypeDomains[sema.CapabilityPathType])
+	if err != nil {
+		return nil, err
+	}
+
+	return cadence.NewStorageCapability(path.(cadence.Path), cadence.Address(address), borrowType), nil
+}
+
+// parseTypeArgument parses a type given as a fully qualified identifier such as A.0x1.FlowToken.Vault,
+// the name of a built-in type such as UFix64 or a type of an imported contract such as FlowToken.Vault.
+//
+// Optional types are supported with the ? suffix. The kind of composite types is unknown without their contract,
+// they are represented as structs since the network only resolves them by their identifier.
+func parseTypeArgument(value string, imports map[string]common.AddressLocation) (cadence.Value, error) {
+	staticType, err := parseTypeIdentifier(value, imports)
+	if err != nil {
+		return nil, err
+	}
+	return cadence.NewTypeValue(staticType), nil
+}
+
+func parseTypeIdentifier(value string, imports map[string]common.AddressLocation) (cadence.Type, error) {
+	if strings.HasSuffix(value, "?") {
+		inner, err := parseTypeIdentifier(strings.TrimSuffix(value, "?"), imports)
+		if err != nil {
+			return nil, err
+		}
+		return cadence.NewOptionalType(inner), nil
+	}
+
+	parts := strings.Split(value, ".")
+	if parts[0] == "A" && len(parts) > 1 {
+		if len(parts) < 3 {
+			return nil, fmt.Errorf("malformed type identifier `%s`, expected A.<address>.<contract>[.<type>]", value)
+		}
+		address, err := hexToAddress(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("malformed type identifier `%s`, invalid address `%s`", value, parts[1])
+		}
+		if err := validateIdentifiers(value, parts[2:]); err != nil {
+			return nil, err
+		}
+		return compositeArgumentType(common.AddressLocation{Address: address, Name: parts[2]}, parts[2:]), nil
+	}
+
+	if err := validateIdentifiers(value, parts); err != nil {
+		return nil, err
+	}
+	return nominalArgumentType(parts, imports)
+}
+
+// hexToAddress converts the hex address with an optional 0x prefix, unlike common.HexToAddress it rejects empty addresses.
+func hexToAddress(value string) (common.Address, error) {
+	if strings.TrimPrefix(value, "0x") == "" {
+		return common.Address{}, fmt.Errorf("empty address")
+	}
+	return common.HexToAddress(value)
+}
+
+func validateIdentifiers(value string, identifiers []string) error {
+	for _, identifier := range identifiers {
+		if !identifierRegex.MatchString(identifier) {
+			return fmt.Errorf("malformed type identifier `%s`, invalid identifier `%s`", value, identifier)
+		}
+	}
+	return nil
+}
+
+// nominalArgumentType resolves the type named by the identifiers to a built-in type or a type of an imported contract.
+func nominalArgumentType(identifiers []string, imports map[string]common.AddressLocation) (cadence.Type, error) {
+	if location, imported := imports[identifiers[0]]; imported {
+		return compositeArgumentType(location, identifiers), nil
+	}
+
+	if len(identifiers) == 1 {
+		if builtin := sema.BaseTypeActivation.Find(identifiers[0]); builtin != nil {
+			return runtime.ExportType(builtin.Type, map[sema.TypeID]cadence.Type{}), nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown type `%s`, types of contracts must be imported or fully qualified", strings.Join(identifiers, "."))
+}
+
+func compositeArgumentType(location common.AddressLocation, identifiers []string) cadence.Type {
+	return &cadence.StructType{
+		Location:            location,
+		QualifiedIdentifier: strings.Join(identifiers, "."),
+	}
+}
+
+// astArgumentType converts the declared type to a cadence type, resolving contract types through the imports.
+func astArgumentType(declared ast.Type, imports map[string]common.AddressLocation) (cadence.Type, error) {
+	switch t := declared.(type) {
+	case *ast.NominalType:
+		identifiers := []string{t.Identifier.Identifier}
+		for _, nested := range t.NestedIdentifiers {
+			identifiers = append(identifiers, nested.Identifier)
+		}
+		return nominalArgumentType(identifiers, imports)
+
+	case *ast.OptionalType:
+		inner, err := astArgumentType(t.Type, imports)
+		if err != nil {
+			return nil, err
+		}
+		return cadence.NewOptionalType(inner), nil
+
+	case *ast.ReferenceType:
+		inner, err := astArgumentType(t.Type, imports)
+		if err != nil {
+			return nil, err
+		}
+		return cadence.NewReferenceType(t.Authorized, inner), nil
+
+	case *ast.RestrictedType:
+		// restricted types without a type are almost always resources, e.g. &{FungibleToken.Receiver}
+		var restricted cadence.Type = cadence.AnyResourceType{}
+		if t.Type != nil {
+			var err error
+			restricted, err = astArgumentType(t.Type, imports)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		restrictions := make([]cadence.Type, len(t.Restrictions))
+		for i, restriction := range t.Restrictions {
+			identifiers := []string{restriction.Identifier.Identifier}
+			for _, nested := range restriction.NestedIdentifiers {
+				identifiers = append(identifiers, nested.Identifier)
+			}
+			location, imported := imports[identifiers[0]]
+			if !imported {
+				return nil, fmt.Errorf("restriction `%s` must be an interface of an imported contract", restriction)
+			}
+			restrictions[i] = &cadence.ResourceInterfaceType{
+				Location:            location,
+				QualifiedIdentifier: strings.Join(identifiers, "."),
+			}
+		}
+
+		return cadence.NewRestrictedType("", restricted, restrictions), nil
+	}
+
+	return nil, fmt.Errorf("type `%s` is not supported as a capability borrow type", declared)
+}
@@ -23,7 +23,10 @@ import (
 	"testing"
 
 	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/cadence/runtime/common"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/onflow/flow-cli/pkg/flowkit"
 )
@@ -58,3 +61,170 @@ func TestArguments(t *testing.T) {
 		assert.Equal(t, []cadence.Value{sample}, args)
 	}
 }
+
+func TestArgumentsPathsCapabilitiesAndTypes(t *testing.T) {
+	flowTokenVault := &cadence.StructType{
+		Location:            common.AddressLocation{Address: common.MustBytesToAddress([]byte{0x1}), Name: "FlowToken"},
+		QualifiedIdentifier: "FlowToken.Vault",
+	}
+	receiver := &cadence.ResourceInterfaceType{
+		Location:            common.AddressLocation{Address: common.MustBytesToAddress([]byte{0x2}), Name: "FungibleToken"},
+		QualifiedIdentifier: "FungibleToken.Receiver",
+	}
+	imports := "import FlowToken from 0x01\nimport FungibleToken from 0x02\n"
+
+	values := []struct {
+		name     string
+		typ      string
+		arg      string
+		expected cadence.Value
+		err      string
+	}{
+		{name: "storage path", typ: "StoragePath", arg: "/storage/flowTokenVault", expected: cadence.NewPath("storage", "flowTokenVault")},
+		{name: "public path", typ: "PublicPath", arg: "/public/flowTokenReceiver", expected: cadence.NewPath("public", "flowTokenReceiver")},
+		{name: "private path", typ: "PrivatePath", arg: "/private/vault", expected: cadence.NewPath("private", "vault")},
+		{name: "capability path", typ: "CapabilityPath", arg: "/private/vault", expected: cadence.NewPath("private", "vault")},
+		{name: "any path", typ: "Path", arg: "/storage/vault", expected: cadence.NewPath("storage", "vault")},
+		{name: "optional path", typ: "StoragePath?", arg: "/storage/vault", expected: cadence.NewPath("storage", "vault")},
+		{
+			name: "wrong path domain",
+			typ:  "StoragePath",
+			arg:  "/public/vault",
+			err:  "argument `test` is not a valid `StoragePath`: invalid path domain `public`, expected storage",
+		},
+		{
+			name: "unknown path domain",
+			typ:  "CapabilityPath",
+			arg:  "/stroage/vault",
+			err:  "argument `test` is not a valid `CapabilityPath`: invalid path domain `stroage`, expected public or private",
+		},
+		{
+			name: "malformed path",
+			typ:  "Path",
+			arg:  "storage/vault",
+			err:  "argument `test` is not a valid `Path`: malformed path `storage/vault`, expected /<domain>/<identifier>",
+		},
+		{
+			name: "invalid path identifier",
+			typ:  "Path",
+			arg:  "/storage/flow-vault",
+			err:  "argument `test` is not a valid `Path`: invalid path identifier `flow-vault`",
+		},
+		{name: "builtin type", typ: "Type", arg: "UFix64", expected: cadence.NewTypeValue(cadence.UFix64Type{})},
+		{name: "optional type", typ: "Type", arg: "String?", expected: cadence.NewTypeValue(cadence.NewOptionalType(cadence.StringType{}))},
+		{name: "qualified type", typ: "Type", arg: "A.0x1.FlowToken.Vault", expected: cadence.NewTypeValue(flowTokenVault)},
+		{
+			name:     "qualified type without prefix",
+			typ:      "Type",
+			arg:      "A.0000000000000001.FlowToken.Vault",
+			expected: cadence.NewTypeValue(flowTokenVault),
+		},
+		{name: "imported type", typ: "Type", arg: "FlowToken.Vault", expected: cadence.NewTypeValue(flowTokenVault)},
+		{
+			name: "malformed type address",
+			typ:  "Type",
+			arg:  "A.0xzz.FlowToken.Vault",
+			err:  "argument `test` is not a valid `Type`: malformed type identifier `A.0xzz.FlowToken.Vault`, invalid address `0xzz`",
+		},
+		{
+			name: "missing contract",
+			typ:  "Type",
+			arg:  "A.0x1",
+			err:  "argument `test` is not a valid `Type`: malformed type identifier `A.0x1`, expected A.<address>.<contract>[.<type>]",
+		},
+		{
+			name: "malformed type identifier",
+			typ:  "Type",
+			arg:  "A.0x1.FlowToken.",
+			err:  "argument `test` is not a valid `Type`: malformed type identifier `A.0x1.FlowToken.`, invalid identifier ``",
+		},
+		{
+			name: "unknown type",
+			typ:  "Type",
+			arg:  "Token.Vault",
+			err:  "argument `test` is not a valid `Type`: unknown type `Token.Vault`, types of contracts must be imported or fully qualified",
+		},
+		{
+			name: "capability",
+			typ:  "Capability<&FlowToken.Vault{FungibleToken.Receiver}>",
+			arg:  "0x01/public/flowTokenReceiver",
+			expected: cadence.NewStorageCapability(
+				cadence.NewPath("public", "flowTokenReceiver"),
+				cadence.NewAddress([8]byte{0, 0, 0, 0, 0, 0, 0, 1}),
+				cadence.NewReferenceType(false, cadence.NewRestrictedType("", flowTokenVault, []cadence.Type{receiver})),
+			),
+		},
+		{
+			name: "capability of restricted type",
+			typ:  "Capability<&{FungibleToken.Receiver}>",
+			arg:  "0x01/public/flowTokenReceiver",
+			expected: cadence.NewStorageCapability(
+				cadence.NewPath("public", "flowTokenReceiver"),
+				cadence.NewAddress([8]byte{0, 0, 0, 0, 0, 0, 0, 1}),
+				cadence.NewReferenceType(false, cadence.NewRestrictedType("", cadence.AnyResourceType{}, []cadence.Type{receiver})),
+			),
+		},
+		{
+			name: "capability without borrow type",
+			typ:  "Capability",
+			arg:  "0x01/public/flowTokenReceiver",
+			err:  "argument `test` is not a valid `Capability`: the capability borrow type must be declared, e.g. Capability<&Vault>",
+		},
+		{
+			name: "capability with storage path",
+			typ:  "Capability<&FlowToken.Vault>",
+			arg:  "0x01/storage/flowTokenVault",
+			err:  "argument `test` is not a valid `Capability<&FlowToken.Vault>`: invalid path domain `storage`, expected public or private",
+		},
+		{
+			name: "malformed capability",
+			typ:  "Capability<&FlowToken.Vault>",
+			arg:  "/public/flowTokenReceiver",
+			err:  "argument `test` is not a valid `Capability<&FlowToken.Vault>`: malformed capability `/public/flowTokenReceiver`, expected <address>/<domain>/<identifier>",
+		},
+	}
+
+	for _, v := range values {
+		t.Run(v.name, func(t *testing.T) {
+			args, err := flowkit.ParseArgumentsWithoutType(
+				"",
+				[]byte(fmt.Sprintf("%spub fun main(test: %s): Void {}", imports, v.typ)),
+				[]string{v.arg},
+			)
+			if v.err != "" {
+				assert.EqualError(t, err, v.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, args, 1)
+			assert.Equal(t, v.expected, args[0])
+
+			// the values are sent encoded as JSON-Cadence
+			encoded, err := jsoncdc.Encode(args[0])
+			require.NoError(t, err)
+			decoded, err := flowkit.ParseArgumentsJSON(fmt.Sprintf("[%s]", encoded))
+			require.NoError(t, err)
+			assert.Equal(t, args[0].Type().ID(), decoded[0].Type().ID())
+			assert.Equal(t, args[0].String(), decoded[0].String())
+		})
+	}
+}
+
+func TestParseArgumentWithType(t *testing.T) {
+	value, err := flowkit.ParseArgumentWithType("amount", "UFix64", "10.5")
+	require.NoError(t, err)
+	assert.Equal(t, cadence.UFix64(1_050_000_000), value)
+
+	value, err = flowkit.ParseArgumentWithType("recipient", "Address?", "nil")
+	require.NoError(t, err)
+	assert.Equal(t, cadence.NewOptional(nil), value)
+
+	_, err = flowkit.ParseArgumentWithType("amount", "UFix64", "ten")
+	assert.EqualError(t, err, "argument `amount` is not expected type `UFix64`")
+
+	_, err = flowkit.ParseArgumentWithType("amount", "UFix64)", "10.5")
+	assert.EqualError(t, err, "argument `amount` has invalid type `UFix64)`")
+
+	_, err = flowkit.ParseArgumentWithType("the amount", "UFix64", "10.5")
+	assert.EqualError(t, err, "invalid argument name `the amount`")
+}
@@ -0,0 +1,206 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package flowkit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DefaultBatchErrorLimit is the number of failures listed by BatchError.Error when no limit is set.
+const DefaultBatchErrorLimit = 10
+
+// BatchError aggregates the failures of a batch operation where some of the items may have succeeded.
+//
+// Items are identified by their index in the batch and an optional label, such as a contract name.
+// Use errors.As to get the per-item detail, errors.Is matches any error wrapped by a failed item.
+type BatchError struct {
+	// Limit is the number of failures listed by Error, DefaultBatchErrorLimit is used if zero.
+	Limit  int
+	labels []string
+	errs   []error
+}
+
+var _ error = &BatchError{}
+
+// NewBatchError returns a batch error for a batch of items with the labels, no item failed initially.
+func NewBatchError(labels ...string) *BatchError {
+	return &BatchError{
+		labels: labels,
+		errs:   make([]error, len(labels)),
+	}
+}
+
+// Fail records the error of the item at the index, a nil error marks the item as succeeded.
+func (b *BatchError) Fail(index int, err error) {
+	b.errs[index] = err
+}
+
+// ErrorAt returns the error of the item at the index, nil if the item succeeded or is out of range.
+func (b *BatchError) ErrorAt(index int) error {
+	if b == nil || index < 0 || index >= len(b.errs) {
+		return nil
+	}
+	return b.errs[index]
+}
+
+// Label returns the label of the item at the index.
+func (b *BatchError) Label(index int) string {
+	if b == nil || index < 0 || index >= len(b.labels) {
+		return ""
+	}
+	return b.labels[index]
+}
+
+// Len returns the number of items in the batch.
+func (b *BatchError) Len() int {
+	if b == nil {
+		return 0
+	}
+	return len(b.errs)
+}
+
+// Failed returns the number of failed items.
+func (b *BatchError) Failed() int {
+	return len(b.Unwrap())
+}
+
+// Succeeded returns the number of items that didn't fail.
+func (b *BatchError) Succeeded() int {
+	return b.Len() - b.Failed()
+}
+
+// Err returns the batch error if any item failed, otherwise nil.
+//
+// Returning Err instead of the batch error itself avoids returning a non-nil error for a successful batch.
+func (b *BatchError) Err() error {
+	if b.Failed() == 0 {
+		return nil
+	}
+	return b
+}
+
+// Unwrap returns the errors of the failed items, in the order of the items.
+func (b *BatchError) Unwrap() []error {
+	if b == nil {
+		return nil
+	}
+
+	errs := make([]error, 0)
+	for _, err := range b.errs {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// Is reports whether any of the failed items matches the target.
+//
+// Errors with multiple wrapped errors are only unwrapped by the errors package from Go 1.20 on.
+func (b *BatchError) Is(target error) bool {
+	for _, err := range b.Unwrap() {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first failed item error matching the target.
+func (b *BatchError) As(target any) bool {
+	for _, err := range b.Unwrap() {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *BatchError) Error() string {
+	failed := b.Failed()
+	if failed == 0 {
+		return "no items failed"
+	}
+
+	limit := b.Limit
+	if limit <= 0 {
+		limit = DefaultBatchErrorLimit
+	}
+
+	var out strings.Builder
+	out.WriteString(fmt.Sprintf("%d of %d items failed:", failed, b.Len()))
+
+	listed := 0
+	for i, err := range b.errs {
+		if err == nil {
+			continue
+		}
+		if listed == limit {
+			out.WriteString(fmt.Sprintf("\n  and %d more", failed-listed))
+			break
+		}
+		out.WriteString(fmt.Sprintf("\n  %s: %s", b.itemName(i), err.Error()))
+		listed++
+	}
+
+	return out.String()
+}
+
+func (b *BatchError) itemName(index int) string {
+	if label := b.Label(index); label != "" {
+		return label
+	}
+	return fmt.Sprintf("item %d", index)
+}
+
+type batchItemErrorJSON struct {
+	Index int    `json:"index"`
+	Label string `json:"label,omitempty"`
+	Error string `json:"error"`
+}
+
+type batchErrorJSON struct {
+	Failed    int                  `json:"failed"`
+	Succeeded int                  `json:"succeeded"`
+	Errors    []batchItemErrorJSON `json:"errors"`
+}
+
+// MarshalJSON encodes all the failed items, the limit only applies to Error.
+func (b *BatchError) MarshalJSON() ([]byte, error) {
+	result := batchErrorJSON{
+		Failed:    b.Failed(),
+		Succeeded: b.Succeeded(),
+		Errors:    make([]batchItemErrorJSON, 0),
+	}
+
+	for i := 0; i < b.Len(); i++ {
+		if err := b.errs[i]; err != nil {
+			result.Errors = append(result.Errors, batchItemErrorJSON{
+				Index: i,
+				Label: b.Label(i),
+				Error: err.Error(),
+			})
+		}
+	}
+
+	return json.Marshal(result)
+}
@@ -0,0 +1,137 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package flowkit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testItemError struct {
+	item string
+}
+
+func (t *testItemError) Error() string {
+	return fmt.Sprintf("item %s is broken", t.item)
+}
+
+func TestBatchError(t *testing.T) {
+	errNotFound := errors.New("not found")
+
+	t.Run("Failures", func(t *testing.T) {
+		batch := NewBatchError("A", "B", "C")
+		batch.Fail(0, errNotFound)
+		batch.Fail(2, &testItemError{item: "C"})
+
+		assert.Equal(t, 3, batch.Len())
+		assert.Equal(t, 2, batch.Failed())
+		assert.Equal(t, 1, batch.Succeeded())
+		assert.Equal(t, errNotFound, batch.ErrorAt(0))
+		assert.NoError(t, batch.ErrorAt(1))
+		assert.NoError(t, batch.ErrorAt(5))
+		assert.Equal(t, "C", batch.Label(2))
+		assert.Equal(t, []error{errNotFound, &testItemError{item: "C"}}, batch.Unwrap())
+		assert.EqualError(t, batch, "2 of 3 items failed:\n  A: not found\n  C: item C is broken")
+	})
+
+	t.Run("Sentinel Matching", func(t *testing.T) {
+		batch := NewBatchError("A", "B")
+		batch.Fail(1, fmt.Errorf("failed to get account: %w", errNotFound))
+
+		var err error = fmt.Errorf("batch failed: %w", batch.Err())
+		assert.ErrorIs(t, err, errNotFound)
+		assert.NotErrorIs(t, err, errors.New("not found"))
+
+		var batchErr *BatchError
+		require.ErrorAs(t, err, &batchErr)
+		assert.Equal(t, 1, batchErr.Failed())
+
+		batch.Fail(0, &testItemError{item: "A"})
+		var itemErr *testItemError
+		require.ErrorAs(t, err, &itemErr)
+		assert.Equal(t, "A", itemErr.item)
+	})
+
+	t.Run("Formatting Cap", func(t *testing.T) {
+		labels := make([]string, 25)
+		for i := range labels {
+			labels[i] = fmt.Sprintf("item-%d", i)
+		}
+		batch := NewBatchError(labels...)
+		for i := 0; i < 22; i++ {
+			batch.Fail(i, errNotFound)
+		}
+
+		assert.Contains(t, batch.Error(), "22 of 25 items failed:\n  item-0: not found\n")
+		assert.Contains(t, batch.Error(), "item-9: not found\n  and 12 more")
+		assert.NotContains(t, batch.Error(), "item-10")
+
+		batch.Limit = 2
+		assert.EqualError(t, batch, "22 of 25 items failed:\n  item-0: not found\n  item-1: not found\n  and 20 more")
+	})
+
+	t.Run("Unlabeled Items", func(t *testing.T) {
+		batch := &BatchError{errs: make([]error, 2)}
+		batch.Fail(1, errNotFound)
+
+		assert.EqualError(t, batch, "1 of 2 items failed:\n  item 1: not found")
+	})
+
+	t.Run("Empty and Nil", func(t *testing.T) {
+		batch := NewBatchError("A", "B")
+		assert.Nil(t, batch.Err())
+		assert.Equal(t, 0, batch.Failed())
+		assert.Equal(t, 2, batch.Succeeded())
+		assert.Len(t, batch.Unwrap(), 0)
+		assert.False(t, errors.Is(batch, errNotFound))
+
+		empty := NewBatchError()
+		assert.Nil(t, empty.Err())
+		assert.Equal(t, 0, empty.Len())
+
+		var nilBatch *BatchError
+		assert.Nil(t, nilBatch.Err())
+		assert.Nil(t, nilBatch.Unwrap())
+		assert.NoError(t, nilBatch.ErrorAt(0))
+		assert.Equal(t, 0, nilBatch.Failed())
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		batch := NewBatchError("A", "B", "C")
+		batch.Limit = 1
+		batch.Fail(1, errNotFound)
+		batch.Fail(2, &testItemError{item: "C"})
+
+		out, err := json.Marshal(batch)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"failed": 2,
+			"succeeded": 1,
+			"errors": [
+				{"index": 1, "label": "B", "error": "not found"},
+				{"index": 2, "label": "C", "error": "item C is broken"}
+			]
+		}`, string(out))
+	})
+}
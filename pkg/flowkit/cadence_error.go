@@ -0,0 +1,211 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package flowkit
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/onflow/flow-go-sdk"
+)
+
+// CadenceErrorKind is the kind of failure reported by the Cadence runtime.
+type CadenceErrorKind string
+
+const (
+	CadenceErrorPanic         CadenceErrorKind = "panic"
+	CadenceErrorAssertion     CadenceErrorKind = "assertion failed"
+	CadenceErrorPreCondition  CadenceErrorKind = "pre-condition failed"
+	CadenceErrorPostCondition CadenceErrorKind = "post-condition failed"
+	CadenceErrorForceNil      CadenceErrorKind = "force unwrap of nil"
+	CadenceErrorOther         CadenceErrorKind = "error"
+)
+
+// cadenceErrorKinds maps the message prefixes printed by the Cadence runtime to the error kinds.
+var cadenceErrorKinds = []struct {
+	prefix string
+	kind   CadenceErrorKind
+}{
+	{"panic: ", CadenceErrorPanic},
+	{"assertion failed: ", CadenceErrorAssertion},
+	{"assertion failed", CadenceErrorAssertion},
+	{"pre-condition failed: ", CadenceErrorPreCondition},
+	{"post-condition failed: ", CadenceErrorPostCondition},
+	{"unexpectedly found nil while forcing an Optional value", CadenceErrorForceNil},
+}
+
+var (
+	cadenceErrorCode     = regexp.MustCompile(`\[Error Code: (\d+)\]`)
+	cadenceErrorLocation = regexp.MustCompile(`^\s*--> (\S+):(\d+):(\d+)\s*$`)
+	cadenceErrorSource   = regexp.MustCompile(`^\s*(\d+) \| (.*)$`)
+)
+
+// CadenceErrorLocation is a position in a Cadence program.
+type CadenceErrorLocation struct {
+	// Location identifies the program, an address qualified contract like f8d6e0586b0a20c7.Foo or a transaction ID.
+	Location string
+	Line     int
+	Column   int
+}
+
+// Contract returns the address and the name of the contract if the location is in a contract.
+func (l *CadenceErrorLocation) Contract() (flow.Address, string, bool) {
+	address, name, found := strings.Cut(strings.TrimPrefix(l.Location, "0x"), ".")
+	if !found || name == "" || len(address) > 2*flow.AddressLength {
+		return flow.EmptyAddress, "", false
+	}
+	if _, err := strconv.ParseUint(address, 16, 64); err != nil {
+		return flow.EmptyAddress, "", false
+	}
+
+	return flow.HexToAddress(address), name, true
+}
+
+func (l *CadenceErrorLocation) String() string {
+	return l.Location + ":" + strconv.Itoa(l.Line) + ":" + strconv.Itoa(l.Column)
+}
+
+// CadenceStackEntry is one of the errors printed by the Cadence runtime, from the entry point to the failure.
+type CadenceStackEntry struct {
+	Kind    CadenceErrorKind
+	Message string
+	// Location is nil if no location was printed for the error.
+	Location *CadenceErrorLocation
+	// Source is the source line at the location, empty if the source was not printed.
+	Source string
+}
+
+// CadenceRuntimeError is the structured form of the Cadence error of a failed transaction.
+//
+// The error message is kept unchanged. If the error can't be parsed the stack is empty
+// and the message is the raw error message.
+type CadenceRuntimeError struct {
+	// Code is the execution error code, zero if it was not printed.
+	Code int
+	// Kind and Message describe the failure, they are taken from the last error of the stack.
+	Kind    CadenceErrorKind
+	Message string
+	Stack   []CadenceStackEntry
+	err     error
+}
+
+// NewCadenceRuntimeError parses the error of a failed transaction, nil is returned for a nil error.
+func NewCadenceRuntimeError(err error) *CadenceRuntimeError {
+	if err == nil {
+		return nil
+	}
+
+	raw := err.Error()
+	runtimeErr := &CadenceRuntimeError{
+		Kind:    CadenceErrorOther,
+		Message: raw,
+		Stack:   make([]CadenceStackEntry, 0),
+		err:     err,
+	}
+
+	if match := cadenceErrorCode.FindStringSubmatch(raw); match != nil {
+		runtimeErr.Code, _ = strconv.Atoi(match[1])
+	}
+
+	// some gateways return the message with escaped new lines
+	if !strings.Contains(raw, "\n") {
+		raw = strings.NewReplacer(`\n`, "\n", `\t`, "\t").Replace(raw)
+	}
+
+	var entry *CadenceStackEntry
+	for _, line := range strings.Split(raw, "\n") {
+		if strings.HasPrefix(line, "error: ") {
+			runtimeErr.Stack = append(runtimeErr.Stack, newCadenceStackEntry(strings.TrimPrefix(line, "error: ")))
+			entry = &runtimeErr.Stack[len(runtimeErr.Stack)-1]
+			continue
+		}
+		if entry == nil {
+			continue
+		}
+
+		if match := cadenceErrorLocation.FindStringSubmatch(line); match != nil && entry.Location == nil {
+			lineNumber, _ := strconv.Atoi(match[2])
+			column, _ := strconv.Atoi(match[3])
+			entry.Location = &CadenceErrorLocation{Location: match[1], Line: lineNumber, Column: column}
+			continue
+		}
+
+		if match := cadenceErrorSource.FindStringSubmatch(line); match != nil && entry.Source == "" &&
+			entry.Location != nil && match[1] == strconv.Itoa(entry.Location.Line) {
+			entry.Source = match[2]
+		}
+	}
+
+	if len(runtimeErr.Stack) > 0 {
+		failure := runtimeErr.Stack[len(runtimeErr.Stack)-1]
+		runtimeErr.Kind = failure.Kind
+		runtimeErr.Message = failure.Message
+	}
+
+	return runtimeErr
+}
+
+func newCadenceStackEntry(message string) CadenceStackEntry {
+	message = strings.TrimSpace(message)
+	for _, k := range cadenceErrorKinds {
+		if strings.HasPrefix(message, k.prefix) {
+			trimmed := strings.TrimSpace(strings.TrimPrefix(message, k.prefix))
+			if trimmed == "" {
+				trimmed = message
+			}
+			return CadenceStackEntry{Kind: k.kind, Message: trimmed}
+		}
+	}
+
+	return CadenceStackEntry{Kind: CadenceErrorOther, Message: message}
+}
+
+// Parsed returns true if the Cadence errors could be extracted from the error message.
+func (c *CadenceRuntimeError) Parsed() bool {
+	return len(c.Stack) > 0
+}
+
+// EntryPoint returns the location of the first error of the stack with a location, nil if there is none.
+func (c *CadenceRuntimeError) EntryPoint() *CadenceErrorLocation {
+	for _, entry := range c.Stack {
+		if entry.Location != nil {
+			return entry.Location
+		}
+	}
+	return nil
+}
+
+// Location returns the location of the failure, the last error of the stack with a location, nil if there is none.
+func (c *CadenceRuntimeError) Location() *CadenceErrorLocation {
+	for i := len(c.Stack) - 1; i >= 0; i-- {
+		if c.Stack[i].Location != nil {
+			return c.Stack[i].Location
+		}
+	}
+	return nil
+}
+
+func (c *CadenceRuntimeError) Error() string {
+	return c.err.Error()
+}
+
+func (c *CadenceRuntimeError) Unwrap() error {
+	return c.err
+}
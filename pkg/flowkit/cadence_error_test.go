@@ -0,0 +1,149 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package flowkit
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// errors captured from failed deployments on the emulator and the access API
+const (
+	capturedPanicErr     = "execution error code 1101: [Error Code: 1101] error caused by: 1 error occurred:\n\t* transaction invocation failed when executing transaction: [Error Code: 1101] cadence runtime error: Execution failed:\nerror: panic: init failed\n --> f8d6e0586b0a20c7.Panic:3:2\n\n\n"
+	capturedAssertionErr = "execution error code 1101: [Error Code: 1101] error caused by: 1 error occurred:\n\t* transaction invocation failed when executing transaction: [Error Code: 1101] cadence runtime error: Execution failed:\nerror: assertion failed: x must be two\n --> f8d6e0586b0a20c7.Assert:4:2\n\n\n"
+	capturedPreErr       = "execution error code 1101: [Error Code: 1101] error caused by: 1 error occurred:\n\t* transaction invocation failed when executing transaction: [Error Code: 1101] cadence runtime error: Execution failed:\nerror: pre-condition failed: amount must be positive\n --> f8d6e0586b0a20c7.Pre:4:3\n\n\n"
+	capturedForceNilErr  = "execution error code 1101: [Error Code: 1101] error caused by: 1 error occurred:\n\t* transaction invocation failed when executing transaction: [Error Code: 1101] cadence runtime error: Execution failed:\nerror: unexpectedly found nil while forcing an Optional value\n --> f8d6e0586b0a20c7.Deref:6:14\n\n\n"
+	capturedWithdrawErr  = "[Error Code: 1101] error caused by: 1 error occurred:\n\t* transaction execute failed: [Error Code: 1101] cadence runtime error: Execution failed:\nerror: pre-condition failed: Amount withdrawn must be less than or equal than the balance of the Vault\n   --> 1654653399040a61.FlowToken:106:12\n    |\n106 |             pre {\n    |             ^^^^^\n\n"
+	capturedUpdateErr    = "[Error Code: 1101] error caused by: 1 error occurred:\n\t* transaction invocation failed when executing transaction: [Error Code: 1101] cadence runtime error: Execution failed:\nerror: cannot deploy invalid contract\n --> 27cb78893c20b28e0b13317f7afaf5040ac3336244925061bf8708f69157a230:3:2\n  |\n3 | \t\tsigner.contracts.update__experimental(name: name, code: code.decodeHex())\n  | \t\t^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^^\n\nerror: mismatching field `count` in `Counter`\n --> f8d6e0586b0a20c7.Counter:3:20\n  |\n3 | \t\t\t\t\tpub var count: UInt64\n  | \t\t\t\t\t               ^^^^^^ incompatible type annotations. expected `Int`, found `UInt64`\n\n"
+)
+
+func TestCadenceRuntimeError(t *testing.T) {
+
+	t.Run("Failures", func(t *testing.T) {
+		tests := []struct {
+			raw      string
+			kind     CadenceErrorKind
+			message  string
+			location string
+			contract string
+		}{
+			{capturedPanicErr, CadenceErrorPanic, "init failed", "f8d6e0586b0a20c7.Panic:3:2", "Panic"},
+			{capturedAssertionErr, CadenceErrorAssertion, "x must be two", "f8d6e0586b0a20c7.Assert:4:2", "Assert"},
+			{capturedPreErr, CadenceErrorPreCondition, "amount must be positive", "f8d6e0586b0a20c7.Pre:4:3", "Pre"},
+			{
+				capturedForceNilErr,
+				CadenceErrorForceNil,
+				"unexpectedly found nil while forcing an Optional value",
+				"f8d6e0586b0a20c7.Deref:6:14",
+				"Deref",
+			},
+			{
+				capturedWithdrawErr,
+				CadenceErrorPreCondition,
+				"Amount withdrawn must be less than or equal than the balance of the Vault",
+				"1654653399040a61.FlowToken:106:12",
+				"FlowToken",
+			},
+		}
+
+		for _, test := range tests {
+			t.Run(string(test.kind), func(t *testing.T) {
+				err := NewCadenceRuntimeError(errors.New(test.raw))
+
+				require.True(t, err.Parsed())
+				assert.Equal(t, 1101, err.Code)
+				assert.Equal(t, test.kind, err.Kind)
+				assert.Equal(t, test.message, err.Message)
+				assert.Equal(t, test.raw, err.Error())
+				require.Len(t, err.Stack, 1)
+				require.NotNil(t, err.Location())
+				assert.Equal(t, test.location, err.Location().String())
+				assert.Equal(t, err.Location(), err.EntryPoint())
+
+				_, name, ok := err.Location().Contract()
+				assert.True(t, ok)
+				assert.Equal(t, test.contract, name)
+			})
+		}
+	})
+
+	t.Run("Source Line", func(t *testing.T) {
+		err := NewCadenceRuntimeError(errors.New(capturedWithdrawErr))
+		assert.Equal(t, "            pre {", err.Stack[0].Source)
+	})
+
+	t.Run("Stack", func(t *testing.T) {
+		err := NewCadenceRuntimeError(errors.New(capturedUpdateErr))
+
+		require.Len(t, err.Stack, 2)
+		assert.Equal(t, CadenceStackEntry{
+			Kind:    CadenceErrorOther,
+			Message: "cannot deploy invalid contract",
+			Location: &CadenceErrorLocation{
+				Location: "27cb78893c20b28e0b13317f7afaf5040ac3336244925061bf8708f69157a230",
+				Line:     3,
+				Column:   2,
+			},
+			Source: "\t\tsigner.contracts.update__experimental(name: name, code: code.decodeHex())",
+		}, err.Stack[0])
+
+		assert.Equal(t, "mismatching field `count` in `Counter`", err.Message)
+		assert.Equal(t, CadenceErrorOther, err.Kind)
+		assert.Equal(t, "f8d6e0586b0a20c7.Counter:3:20", err.Location().String())
+		assert.Equal(t, "\t\t\t\t\tpub var count: UInt64", err.Stack[1].Source)
+
+		_, _, ok := err.EntryPoint().Contract()
+		assert.False(t, ok, "transaction location is not a contract")
+		address, _, ok := err.Location().Contract()
+		assert.True(t, ok)
+		assert.Equal(t, flow.HexToAddress("f8d6e0586b0a20c7"), address)
+	})
+
+	t.Run("Escaped New Lines", func(t *testing.T) {
+		escaped := fmt.Sprintf("%q", capturedPanicErr)
+		err := NewCadenceRuntimeError(errors.New(escaped[1 : len(escaped)-1]))
+
+		require.True(t, err.Parsed())
+		assert.Equal(t, CadenceErrorPanic, err.Kind)
+		assert.Equal(t, "init failed", err.Message)
+		assert.Equal(t, "f8d6e0586b0a20c7.Panic:3:2", err.Location().String())
+	})
+
+	t.Run("Unparseable", func(t *testing.T) {
+		cause := errors.New("failed to submit transaction: connection refused")
+		err := NewCadenceRuntimeError(cause)
+
+		assert.False(t, err.Parsed())
+		assert.Equal(t, CadenceErrorOther, err.Kind)
+		assert.Equal(t, cause.Error(), err.Message)
+		assert.Equal(t, cause.Error(), err.Error())
+		assert.Nil(t, err.Location())
+		assert.Nil(t, err.EntryPoint())
+		assert.ErrorIs(t, err, cause)
+	})
+
+	t.Run("Nil", func(t *testing.T) {
+		assert.Nil(t, NewCadenceRuntimeError(nil))
+	})
+}
@@ -19,7 +19,9 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"regexp"
 
 	"github.com/onflow/flow-go-sdk"
 	"github.com/onflow/flow-go-sdk/crypto"
@@ -39,10 +41,26 @@ type Account struct {
 	// Ref: https://docs.onflow.org/flow-cli/security/#private-account-configuration-file
 	Location         string
 	UseAdvanceFormat bool
+	// Tags group the accounts, e.g. by role, and can select them with a "tag:" selector.
+	Tags []string
 }
 
 type Accounts []Account
 
+var accountTagRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_\-]{0,31}$`)
+
+// ValidateAccountTag checks the tag starts with a letter or a digit, contains only letters, digits,
+// '_' and '-' and is at most 32 characters long.
+func ValidateAccountTag(tag string) error {
+	if !accountTagRegex.MatchString(tag) {
+		return fmt.Errorf(
+			"invalid account tag %q, tags can only contain letters, digits, '_' and '-' and have at most 32 characters",
+			tag,
+		)
+	}
+	return nil
+}
+
 // AccountKey represents account key and all their possible configuration formats.
 type AccountKey struct {
 	Type           KeyType
@@ -53,6 +71,69 @@ type AccountKey struct {
 	Mnemonic       string
 	DerivationPath string
 	PrivateKey     crypto.PrivateKey
+	// Location is the key file the key is loaded from, relative to the configuration file.
+	//
+	// The key file holds the key and optionally the address of the account, changes to the key
+	// are saved back to the key file. This field is empty if the key is part of the configuration.
+	Location string
+}
+
+// ErrNestedKeyFile is returned when a key file loads the key from another file.
+var ErrNestedKeyFile = errors.New("key files can't load the key from another file")
+
+// SigAlgoMismatchError is returned when the private key of an account belongs to a different
+// signature algorithm than the one declared in the configuration.
+type SigAlgoMismatchError struct {
+	Account  string
+	Declared crypto.SignatureAlgorithm
+	Actual   crypto.SignatureAlgorithm
+}
+
+func (s *SigAlgoMismatchError) Error() string {
+	return fmt.Sprintf(
+		"key for account '%s' parses as %s but config declares %s, fix the sigAlgorithm field",
+		s.Account,
+		s.Actual,
+		s.Declared,
+	)
+}
+
+// AlternativeSigAlgo returns the other supported ECDSA signature algorithm, used to diagnose keys
+// configured with the wrong algorithm.
+func AlternativeSigAlgo(sigAlgo crypto.SignatureAlgorithm) crypto.SignatureAlgorithm {
+	switch sigAlgo {
+	case crypto.ECDSA_P256:
+		return crypto.ECDSA_secp256k1
+	case crypto.ECDSA_secp256k1:
+		return crypto.ECDSA_P256
+	default:
+		return crypto.UnknownSignatureAlgorithm
+	}
+}
+
+// DecodeAccountPrivateKey decodes the hex encoded private key of the account with the declared signature algorithm.
+//
+// If the key can't be decoded but is a valid key of the other supported algorithm a SigAlgoMismatchError is returned.
+// Both curves use 32 byte keys so most keys decode under either algorithm, a mismatch of those keys is only
+// detected once the derived public key is compared to the on-chain key.
+func DecodeAccountPrivateKey(accountName string, sigAlgo crypto.SignatureAlgorithm, key string) (crypto.PrivateKey, error) {
+	privateKey, err := crypto.DecodePrivateKeyHex(sigAlgo, key)
+	if err == nil {
+		return privateKey, nil
+	}
+
+	alternative := AlternativeSigAlgo(sigAlgo)
+	if alternative != crypto.UnknownSignatureAlgorithm {
+		if _, altErr := crypto.DecodePrivateKeyHex(alternative, key); altErr == nil {
+			return nil, &SigAlgoMismatchError{
+				Account:  accountName,
+				Declared: sigAlgo,
+				Actual:   alternative,
+			}
+		}
+	}
+
+	return nil, err
 }
 
 // ByName get account by name.
@@ -32,12 +32,14 @@ import (
 // Networks defines all the Flow networks addresses
 // Accounts defines Flow accounts and their addresses, private key and more properties
 // Deployments describes which contracts should be deployed to which accounts
+// Scripts registers frequently used scripts by name
 type Config struct {
 	Emulators   Emulators
 	Contracts   Contracts
 	Networks    Networks
 	Accounts    Accounts
 	Deployments Deployments
+	Scripts     Scripts
 }
 
 type KeyType string
@@ -46,6 +48,8 @@ const (
 	KeyTypeHex                        KeyType = "hex"
 	KeyTypeGoogleKMS                  KeyType = "google-kms"
 	KeyTypeBip44                      KeyType = "bip44"
+	KeyTypeHDWallet                   KeyType = "hd-wallet"
+	DefaultDerivationPath                     = "m/44'/539'/0'/0/0"
 	DefaultEmulatorConfigName                 = "default"
 	DefaultEmulatorServiceAccountName         = "emulator-account"
 	DefaultEmulatorPort                       = 3569
@@ -60,6 +64,16 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	for _, n := range c.Networks {
+		if n.DefaultSigner == "" {
+			continue
+		}
+		_, err := c.Accounts.ByName(n.DefaultSigner)
+		if err != nil {
+			return fmt.Errorf("network %s contains nonexisting default signer %s", n.Name, n.DefaultSigner)
+		}
+	}
+
 	for _, em := range c.Emulators {
 		_, err := c.Accounts.ByName(em.ServiceAccount)
 		if err != nil {
@@ -67,6 +81,7 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	contracts := c.Contracts.Index()
 	for _, d := range c.Deployments {
 		_, err := c.Networks.ByName(d.Network)
 		if err != nil {
@@ -74,7 +89,7 @@ func (c *Config) Validate() error {
 		}
 
 		for _, con := range d.Contracts {
-			_, err := c.Contracts.ByName(con.Name)
+			_, err := contracts.ByName(con.Name)
 			if err != nil {
 				return fmt.Errorf("deployment contains nonexisting contract %s", con.Name)
 			}
@@ -86,6 +101,12 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	for _, s := range c.Scripts {
+		if err := s.Validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -18,7 +18,10 @@
 
 package config
 
-import "fmt"
+import (
+	"fmt"
+	"path/filepath"
+)
 
 // Contract defines the configuration for a Cadence contract.
 type Contract struct {
@@ -26,10 +29,18 @@ type Contract struct {
 	Location string
 	Network  string
 	Alias    string
+	// GeneratedBy names the code generator the contract is generated with, such as "cadence-gen v1.2.3",
+	// generated contracts shouldn't be edited by hand.
+	GeneratedBy string
 }
 
 type Contracts []Contract
 
+// IsGenerated checks if contract is generated by a code generator.
+func (c *Contract) IsGenerated() bool {
+	return c.GeneratedBy != ""
+}
+
 // IsAlias checks if contract has an alias.
 func (c *Contract) IsAlias() bool {
 	return c.Alias != ""
@@ -51,9 +62,10 @@ func (c *Contracts) ByNameAndNetwork(name string, network string) (*Contract, er
 	}
 
 	return &Contract{
-		Name:     cName.Name,
-		Network:  network,
-		Location: cName.Location,
+		Name:        cName.Name,
+		Network:     network,
+		Location:    cName.Location,
+		GeneratedBy: cName.GeneratedBy,
 	}, nil
 }
 
@@ -68,6 +80,80 @@ func (c *Contracts) ByName(name string) (*Contract, error) {
 	return nil, fmt.Errorf("contract named %s does not exist in configuration", name)
 }
 
+// ByLocation get contract by its source location, locations are compared after they are cleaned.
+func (c *Contracts) ByLocation(location string) (*Contract, error) {
+	location = filepath.Clean(location)
+	for _, contract := range *c {
+		if filepath.Clean(contract.Location) == location {
+			return &contract, nil
+		}
+	}
+
+	return nil, fmt.Errorf("contract with location %s does not exist in configuration", location)
+}
+
+// Index returns an index of the contracts for repeated lookups by name or location.
+func (c *Contracts) Index() *ContractIndex {
+	return NewContractIndex(*c)
+}
+
+// ContractIndex looks up contracts by name or location in constant time.
+//
+// The index is a snapshot of the contracts it was created from, changes to the contracts
+// are not reflected and require a new index. Like the Contracts lookups, the first contract
+// with a name or location is returned.
+type ContractIndex struct {
+	contracts  Contracts
+	byName     map[string]int
+	byLocation map[string]int
+}
+
+// NewContractIndex returns a new index of the contracts.
+func NewContractIndex(contracts Contracts) *ContractIndex {
+	index := &ContractIndex{
+		contracts:  make(Contracts, len(contracts)),
+		byName:     make(map[string]int, len(contracts)),
+		byLocation: make(map[string]int, len(contracts)),
+	}
+	copy(index.contracts, contracts)
+
+	for i, contract := range index.contracts {
+		if _, ok := index.byName[contract.Name]; !ok {
+			index.byName[contract.Name] = i
+		}
+
+		location := filepath.Clean(contract.Location)
+		if _, ok := index.byLocation[location]; !ok {
+			index.byLocation[location] = i
+		}
+	}
+
+	return index
+}
+
+// ByName get contract by name.
+func (i *ContractIndex) ByName(name string) (*Contract, error) {
+	n, ok := i.byName[name]
+	if !ok {
+		return nil, fmt.Errorf("contract named %s does not exist in configuration", name)
+	}
+
+	contract := i.contracts[n]
+	return &contract, nil
+}
+
+// ByLocation get contract by its source location, locations are compared after they are cleaned.
+func (i *ContractIndex) ByLocation(location string) (*Contract, error) {
+	location = filepath.Clean(location)
+	n, ok := i.byLocation[location]
+	if !ok {
+		return nil, fmt.Errorf("contract with location %s does not exist in configuration", location)
+	}
+
+	contract := i.contracts[n]
+	return &contract, nil
+}
+
 // ByNetwork returns all contracts for specific network.
 func (c *Contracts) ByNetwork(network string) Contracts {
 	var contracts []Contract
@@ -81,6 +167,22 @@ func (c *Contracts) ByNetwork(network string) Contracts {
 	return contracts
 }
 
+// Generated returns the contracts generated by a code generator, once for each contract name.
+func (c *Contracts) Generated() []*Contract {
+	contracts := make([]*Contract, 0)
+	seen := make(map[string]bool)
+
+	for i, contract := range *c {
+		if !contract.IsGenerated() || seen[contract.Name] {
+			continue
+		}
+		seen[contract.Name] = true
+		contracts = append(contracts, &(*c)[i])
+	}
+
+	return contracts
+}
+
 // AddOrUpdate add new or update if already present.
 func (c *Contracts) AddOrUpdate(name string, contract Contract) {
 	for i, existingContract := range *c {
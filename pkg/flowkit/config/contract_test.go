@@ -0,0 +1,175 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testContracts() Contracts {
+	return Contracts{{
+		Name:     "NonFungibleToken",
+		Location: "./contracts/NonFungibleToken.cdc",
+	}, {
+		Name:     "KittyItems",
+		Location: "contracts/KittyItems.cdc",
+	}, {
+		Name:     "KittyItems",
+		Location: "contracts/KittyItems.cdc",
+		Network:  "testnet",
+		Alias:    "0x1",
+	}, {
+		Name:     "KittyItemsMarket",
+		Location: "contracts/market/../KittyItemsMarket.cdc",
+	}}
+}
+
+func TestContracts_Lookup(t *testing.T) {
+	contracts := testContracts()
+
+	lookups := map[string]interface {
+		ByName(string) (*Contract, error)
+		ByLocation(string) (*Contract, error)
+	}{
+		"Contracts": &contracts,
+		"Index":     contracts.Index(),
+	}
+
+	for name, lookup := range lookups {
+		t.Run(name, func(t *testing.T) {
+			contract, err := lookup.ByName("KittyItems")
+			require.NoError(t, err)
+			assert.Equal(t, contracts[1], *contract)
+
+			_, err = lookup.ByName("Unknown")
+			assert.EqualError(t, err, "contract named Unknown does not exist in configuration")
+
+			contract, err = lookup.ByLocation("contracts/NonFungibleToken.cdc")
+			require.NoError(t, err)
+			assert.Equal(t, "NonFungibleToken", contract.Name)
+
+			contract, err = lookup.ByLocation("./contracts/KittyItemsMarket.cdc")
+			require.NoError(t, err)
+			assert.Equal(t, "KittyItemsMarket", contract.Name)
+
+			contract, err = lookup.ByLocation("contracts/KittyItems.cdc")
+			require.NoError(t, err)
+			assert.Equal(t, contracts[1], *contract)
+
+			_, err = lookup.ByLocation("./contracts/Unknown.cdc")
+			assert.EqualError(t, err, "contract with location contracts/Unknown.cdc does not exist in configuration")
+		})
+	}
+
+	t.Run("Index Snapshot", func(t *testing.T) {
+		contracts := testContracts()
+		index := contracts.Index()
+
+		contracts.AddOrUpdate("Added", Contract{Name: "Added", Location: "contracts/Added.cdc"})
+		contracts[0].Location = "contracts/Moved.cdc"
+
+		_, err := index.ByName("Added")
+		assert.Error(t, err)
+
+		contract, err := index.ByName("NonFungibleToken")
+		require.NoError(t, err)
+		assert.Equal(t, "./contracts/NonFungibleToken.cdc", contract.Location)
+
+		contract.Location = "changed"
+		contract, _ = index.ByName("NonFungibleToken")
+		assert.Equal(t, "./contracts/NonFungibleToken.cdc", contract.Location)
+	})
+}
+
+func TestContracts_Generated(t *testing.T) {
+	contracts := testContracts()
+	assert.Empty(t, contracts.Generated())
+
+	contracts[1].GeneratedBy = "cadence-gen v1.2.3"
+	contracts[2].GeneratedBy = "cadence-gen v1.2.3"
+	contracts[3].GeneratedBy = "cadence-gen v1.0.0"
+
+	generated := contracts.Generated()
+	require.Len(t, generated, 2)
+	assert.Equal(t, "KittyItems", generated[0].Name)
+	assert.Equal(t, "KittyItemsMarket", generated[1].Name)
+	assert.True(t, generated[1].IsGenerated())
+	assert.False(t, contracts[0].IsGenerated())
+
+	contract, err := contracts.ByNameAndNetwork("KittyItemsMarket", "testnet")
+	require.NoError(t, err)
+	assert.Equal(t, "cadence-gen v1.0.0", contract.GeneratedBy)
+}
+
+func benchmarkContracts(n int) Contracts {
+	contracts := make(Contracts, n)
+	for i := range contracts {
+		contracts[i] = Contract{
+			Name:     fmt.Sprintf("Contract%d", i),
+			Location: fmt.Sprintf("./contracts/Contract%d.cdc", i),
+		}
+	}
+	return contracts
+}
+
+func BenchmarkContracts_ByLocation(b *testing.B) {
+	for _, n := range []int{10, 100, 1000} {
+		contracts := benchmarkContracts(n)
+		location := contracts[n-1].Location
+
+		b.Run(fmt.Sprintf("Scan/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, _ = contracts.ByLocation(location)
+			}
+		})
+
+		b.Run(fmt.Sprintf("Index/%d", n), func(b *testing.B) {
+			index := contracts.Index()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, _ = index.ByLocation(location)
+			}
+		})
+	}
+}
+
+func BenchmarkContracts_ByName(b *testing.B) {
+	for _, n := range []int{10, 100, 1000} {
+		contracts := benchmarkContracts(n)
+		name := contracts[n-1].Name
+
+		b.Run(fmt.Sprintf("Scan/%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_, _ = contracts.ByName(name)
+			}
+		})
+
+		b.Run(fmt.Sprintf("Index/%d", n), func(b *testing.B) {
+			index := contracts.Index()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				_, _ = index.ByName(name)
+			}
+		})
+	}
+}
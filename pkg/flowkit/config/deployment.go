@@ -52,6 +52,24 @@ func (d *Deployments) ByNetwork(network string) Deployments {
 	return deployments
 }
 
+// ForNetwork returns all deployments on the network.
+func (d *Deployments) ForNetwork(network string) []Deployment {
+	return d.ByNetwork(network)
+}
+
+// ForAccount returns all deployments to the account, on any network.
+func (d *Deployments) ForAccount(account string) []Deployment {
+	var deployments []Deployment
+
+	for _, deploy := range *d {
+		if deploy.Account == account {
+			deployments = append(deployments, deploy)
+		}
+	}
+
+	return deployments
+}
+
 // ByAccountAndNetwork get deploy by account and network.
 func (d *Deployments) ByAccountAndNetwork(account string, network string) Deployments {
 	var deployments Deployments
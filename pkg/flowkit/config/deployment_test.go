@@ -116,4 +116,34 @@ func Test_Deployment(t *testing.T) {
 		assert.Equal(t, (*deployments)[0].Contracts[1], contracts[1])
 	})
 
+	t.Run("Deployments for account and network", func(t *testing.T) {
+		deployments := &Deployments{}
+		for _, d := range []Deployment{
+			{Network: "emulator", Account: "alice"},
+			{Network: "emulator", Account: "bob"},
+			{Network: "testnet", Account: "alice"},
+		} {
+			deployments.AddOrUpdate(d)
+		}
+
+		alice := deployments.ForAccount("alice")
+		assert.Len(t, alice, 2)
+		assert.Equal(t, "emulator", alice[0].Network)
+		assert.Equal(t, "testnet", alice[1].Network)
+
+		emulator := deployments.ForNetwork("emulator")
+		assert.Len(t, emulator, 2)
+		assert.Equal(t, "alice", emulator[0].Account)
+		assert.Equal(t, "bob", emulator[1].Account)
+
+		// changes to the deployments are reflected in the results
+		deployments.AddOrUpdate(Deployment{Network: "emulator", Account: "charlie"})
+		assert.Len(t, deployments.ForNetwork("emulator"), 3)
+		assert.NoError(t, deployments.Remove("alice", "testnet"))
+		assert.Len(t, deployments.ForAccount("alice"), 1)
+
+		assert.Len(t, deployments.ForAccount("dave"), 0)
+		assert.Len(t, deployments.ForNetwork("mainnet"), 0)
+	})
+
 }
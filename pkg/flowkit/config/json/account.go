@@ -20,6 +20,7 @@ package json
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -47,10 +48,15 @@ func transformAddress(address string) (flow.Address, error) {
 
 // transformSimpleToConfig transforms simple internal account to config account.
 func transformSimpleToConfig(accountName string, a simpleAccount) (*config.Account, error) {
-	pkey, err := crypto.DecodePrivateKeyHex(
+	pkey, err := config.DecodeAccountPrivateKey(
+		accountName,
 		crypto.ECDSA_P256,
 		strings.TrimPrefix(a.Key, "0x"),
 	)
+	var mismatchErr *config.SigAlgoMismatchError
+	if errors.As(err, &mismatchErr) {
+		return nil, fmt.Errorf("%w, the simple account format only supports %s keys", err, crypto.ECDSA_P256)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("invalid private key for account: %s", accountName)
 	}
@@ -75,73 +81,122 @@ func transformSimpleToConfig(accountName string, a simpleAccount) (*config.Accou
 
 // transformAdvancedToConfig transforms advanced internal account to config account.
 func transformAdvancedToConfig(accountName string, a advancedAccount) (*config.Account, error) {
-	sigAlgo := crypto.StringToSignatureAlgorithm(a.Key.SigAlgo)
-	hashAlgo := crypto.StringToHashAlgorithm(a.Key.HashAlgo)
+	if a.Key.FromFile != "" {
+		return transformKeyFromFileToConfig(accountName, a)
+	}
 
-	if a.Key.Type != config.KeyTypeHex && a.Key.Type != config.KeyTypeGoogleKMS && a.Key.Type != config.KeyTypeBip44 {
-		return nil, fmt.Errorf("invalid key type for account %s", accountName)
+	key, err := transformAdvancedKeyToConfig(accountName, a.Key)
+	if err != nil {
+		return nil, err
 	}
 
-	if a.Key.ResourceID != "" && a.Key.PrivateKey != "" {
-		return nil, fmt.Errorf("only provide value for private key or resource ID on account %s", accountName)
+	address, err := transformAddress(a.Address)
+	if err != nil {
+		return nil, err
 	}
 
-	if sigAlgo == crypto.UnknownSignatureAlgorithm {
-		return nil, fmt.Errorf("invalid signature algorithm for account %s", accountName)
+	return &config.Account{
+		Name:    accountName,
+		Address: address,
+		Key:     key,
+	}, nil
+}
+
+// transformKeyFromFileToConfig transforms advanced internal account with the key loaded from a key file to config account.
+//
+// The key is only loaded later by the loader, the address can be provided by the key file instead.
+func transformKeyFromFileToConfig(accountName string, a advancedAccount) (*config.Account, error) {
+	if a.Key.Type != "" || a.Key.PrivateKey != "" || a.Key.Mnemonic != "" || a.Key.ResourceID != "" {
+		return nil, fmt.Errorf("key of account %s is loaded from file %s and can't contain key values", accountName, a.Key.FromFile)
 	}
 
-	if hashAlgo == crypto.UnknownHashAlgorithm {
-		return nil, fmt.Errorf("invalid hash algorithm for account %s", accountName)
+	address := flow.EmptyAddress
+	if a.Address != "" {
+		var err error
+		address, err = transformAddress(a.Address)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	address, err := transformAddress(a.Address)
-	if err != nil {
-		return nil, err
+	return &config.Account{
+		Name:    accountName,
+		Address: address,
+		Key:     config.AccountKey{Location: a.Key.FromFile},
+	}, nil
+}
+
+// transformAdvancedKeyToConfig transforms advanced internal key to config account key.
+func transformAdvancedKeyToConfig(accountName string, k advanceKey) (config.AccountKey, error) {
+	sigAlgo := crypto.StringToSignatureAlgorithm(k.SigAlgo)
+	hashAlgo := crypto.StringToHashAlgorithm(k.HashAlgo)
+
+	if k.Type != config.KeyTypeHex &&
+		k.Type != config.KeyTypeGoogleKMS &&
+		k.Type != config.KeyTypeBip44 &&
+		k.Type != config.KeyTypeHDWallet {
+		return config.AccountKey{}, fmt.Errorf("invalid key type for account %s", accountName)
+	}
+
+	if k.ResourceID != "" && k.PrivateKey != "" {
+		return config.AccountKey{}, fmt.Errorf("only provide value for private key or resource ID on account %s", accountName)
+	}
+
+	if sigAlgo == crypto.UnknownSignatureAlgorithm {
+		return config.AccountKey{}, fmt.Errorf("invalid signature algorithm for account %s", accountName)
+	}
+
+	if hashAlgo == crypto.UnknownHashAlgorithm {
+		return config.AccountKey{}, fmt.Errorf("invalid hash algorithm for account %s", accountName)
 	}
 
 	key := config.AccountKey{
-		Type:     a.Key.Type,
-		Index:    a.Key.Index,
+		Type:     k.Type,
+		Index:    k.Index,
 		SigAlgo:  sigAlgo,
 		HashAlgo: hashAlgo,
 	}
 
-	switch a.Key.Type {
+	switch k.Type {
 	case config.KeyTypeHex:
-		if a.Key.PrivateKey == "" {
-			return nil, fmt.Errorf("missing private key value for hex key type on account %s", accountName)
+		if k.PrivateKey == "" {
+			return config.AccountKey{}, fmt.Errorf("missing private key value for hex key type on account %s", accountName)
 		}
-		pKey, err := crypto.DecodePrivateKeyHex(
+		pKey, err := config.DecodeAccountPrivateKey(
+			accountName,
 			sigAlgo,
-			strings.TrimPrefix(a.Key.PrivateKey, "0x"),
+			strings.TrimPrefix(k.PrivateKey, "0x"),
 		)
 		if err != nil {
-			return nil, err
+			return config.AccountKey{}, err
 		}
 
 		key.PrivateKey = pKey
 	case config.KeyTypeBip44:
-		if a.Key.Mnemonic == "" {
-			return nil, fmt.Errorf("missing mnemonic value for bip44 key type on account %s", accountName)
+		if k.Mnemonic == "" {
+			return config.AccountKey{}, fmt.Errorf("missing mnemonic value for bip44 key type on account %s", accountName)
+		}
+		key.Mnemonic = k.Mnemonic
+		key.DerivationPath = k.DerivationPath
+		if key.DerivationPath == "" {
+			key.DerivationPath = config.DefaultDerivationPath
 		}
-		key.Mnemonic = a.Key.Mnemonic
-		key.DerivationPath = a.Key.DerivationPath
+	case config.KeyTypeHDWallet:
+		// the mnemonic can also be provided with the FLOW_MNEMONIC environment variable
+		key.Mnemonic = k.Mnemonic
+		key.DerivationPath = k.DerivationPath
 		if key.DerivationPath == "" {
-			key.DerivationPath = "m/44'/539'/0'/0/0"
+			key.DerivationPath = config.DefaultDerivationPath
 		}
 
 	case config.KeyTypeGoogleKMS:
-		if a.Key.ResourceID == "" {
-			return nil, fmt.Errorf("missing resource ID value for key on account %s", accountName)
+		if k.ResourceID == "" {
+			return config.AccountKey{}, fmt.Errorf("missing resource ID value for key on account %s", accountName)
 		}
-		key.ResourceID = a.Key.ResourceID
+		key.ResourceID = k.ResourceID
 	}
 
-	return &config.Account{
-		Name:    accountName,
-		Address: address,
-		Key:     key,
-	}, nil
+	return key, nil
 }
 
 // transformToConfig transforms json structures to config structure.
@@ -163,6 +218,13 @@ func (j jsonAccounts) transformToConfig() (config.Accounts, error) {
 			}
 		}
 
+		account.Tags = append(a.Simple.Tags, a.Advanced.Tags...)
+		for _, tag := range account.Tags {
+			if err := config.ValidateAccountTag(tag); err != nil {
+				return nil, fmt.Errorf("invalid tags of account %s: %w", accountName, err)
+			}
+		}
+
 		accounts = append(accounts, *account)
 	}
 
@@ -176,6 +238,8 @@ func transformAccountsToJSON(accounts config.Accounts) jsonAccounts {
 	for _, a := range accounts {
 		if a.Location != "" {
 			jsonAccounts[a.Name] = transformFromFileAccountToJSON(a)
+		} else if a.Key.Location != "" {
+			jsonAccounts[a.Name] = transformKeyFromFileAccountToJSON(a)
 		} else if isDefaultKeyFormat(a.Key) && !a.UseAdvanceFormat {
 			jsonAccounts[a.Name] = transformSimpleAccountToJSON(a)
 		} else {
@@ -194,11 +258,22 @@ func transformFromFileAccountToJSON(a config.Account) account {
 	}
 }
 
+func transformKeyFromFileAccountToJSON(a config.Account) account {
+	return account{
+		KeyFromFile: keyFromFileAccount{
+			Address: a.Address.String(),
+			Key:     fromFileKey{FromFile: a.Key.Location},
+			Tags:    a.Tags,
+		},
+	}
+}
+
 func transformSimpleAccountToJSON(a config.Account) account {
 	return account{
 		Simple: simpleAccount{
 			Address: a.Address.String(),
 			Key:     strings.TrimPrefix(a.Key.PrivateKey.String(), "0x"),
+			Tags:    a.Tags,
 		},
 	}
 }
@@ -208,6 +283,7 @@ func transformAdvancedAccountToJSON(a config.Account) account {
 		Advanced: advancedAccount{
 			Address: a.Address.String(),
 			Key:     transformAdvancedKeyToJSON(a.Key),
+			Tags:    a.Tags,
 		},
 	}
 }
@@ -223,7 +299,7 @@ func transformAdvancedKeyToJSON(key config.AccountKey) advanceKey {
 	switch key.Type {
 	case config.KeyTypeHex:
 		advancedKey.PrivateKey = strings.TrimPrefix(key.PrivateKey.String(), "0x")
-	case config.KeyTypeBip44:
+	case config.KeyTypeBip44, config.KeyTypeHDWallet:
 		advancedKey.Mnemonic = key.Mnemonic
 		advancedKey.DerivationPath = key.DerivationPath
 	case config.KeyTypeGoogleKMS:
@@ -241,23 +317,36 @@ func isDefaultKeyFormat(key config.AccountKey) bool {
 }
 
 type account struct {
-	FromFile fromFileAccount
-	Simple   simpleAccount
-	Advanced advancedAccount
+	FromFile    fromFileAccount
+	KeyFromFile keyFromFileAccount
+	Simple      simpleAccount
+	Advanced    advancedAccount
 }
 
 type fromFileAccount struct {
 	FromFile string `json:"fromFile"`
 }
 
+type keyFromFileAccount struct {
+	Address string      `json:"address,omitempty"`
+	Key     fromFileKey `json:"key"`
+	Tags    []string    `json:"tags,omitempty"`
+}
+
+type fromFileKey struct {
+	FromFile string `json:"fromFile"`
+}
+
 type simpleAccount struct {
-	Address string `json:"address"`
-	Key     string `json:"key"`
+	Address string   `json:"address"`
+	Key     string   `json:"key"`
+	Tags    []string `json:"tags,omitempty"`
 }
 
 type advancedAccount struct {
 	Address string     `json:"address"`
 	Key     advanceKey `json:"key"`
+	Tags    []string   `json:"tags,omitempty"`
 }
 
 type advanceKey struct {
@@ -267,13 +356,15 @@ type advanceKey struct {
 	HashAlgo string         `json:"hashAlgorithm"`
 	// hex key type
 	PrivateKey string `json:"privateKey,omitempty"`
-	// bip44 key type
+	// bip44 and hd-wallet key types
 	Mnemonic       string `json:"mnemonic,omitempty"`
 	DerivationPath string `json:"derivationPath,omitempty"`
 	// kms key type
 	ResourceID string `json:"resourceID,omitempty"`
 	// old key format
 	Context map[string]string `json:"context,omitempty"`
+	// key loaded from a key file
+	FromFile string `json:"fromFile,omitempty"`
 }
 
 // support for pre v0.22 formats
@@ -366,7 +457,11 @@ func (j account) MarshalJSON() ([]byte, error) {
 		return json.Marshal(j.FromFile)
 	}
 
-	if j.Simple != (simpleAccount{}) {
+	if j.KeyFromFile.Key.FromFile != "" {
+		return json.Marshal(j.KeyFromFile)
+	}
+
+	if j.Simple.Address != "" {
 		return json.Marshal(j.Simple)
 	}
 
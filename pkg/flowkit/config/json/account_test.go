@@ -120,6 +120,52 @@ func Test_ConfigAccountKeysAdvancedKMS(t *testing.T) {
 	assert.Nil(t, key.PrivateKey)
 }
 
+func Test_ConfigAccountKeysAdvancedHDWallet(t *testing.T) {
+	b := []byte(`{
+		"test": {
+			"address": "service",
+			"key": {
+				"type": "hd-wallet",
+				"index": 0,
+				"signatureAlgorithm": "ECDSA_secp256k1",
+				"hashAlgorithm": "SHA3_256",
+				"derivationPath": "m/44'/539'/513'/0/0"
+			}
+		},
+		"default-path": {
+			"address": "service",
+			"key": {
+				"type": "hd-wallet",
+				"signatureAlgorithm": "ECDSA_P256",
+				"hashAlgorithm": "SHA3_256",
+				"mnemonic": "normal dune pole key case cradle unfold require tornado mercy hospital buyer"
+			}
+		}
+	}`)
+
+	var jsonAccounts jsonAccounts
+	err := json.Unmarshal(b, &jsonAccounts)
+	assert.NoError(t, err)
+
+	accounts, err := jsonAccounts.transformToConfig()
+	assert.NoError(t, err)
+
+	account, err := accounts.ByName("test")
+	assert.NoError(t, err)
+	assert.Equal(t, config.KeyTypeHDWallet, account.Key.Type)
+	assert.Equal(t, "m/44'/539'/513'/0/0", account.Key.DerivationPath)
+	assert.Equal(t, "", account.Key.Mnemonic)
+
+	account, err = accounts.ByName("default-path")
+	assert.NoError(t, err)
+	assert.Equal(t, config.DefaultDerivationPath, account.Key.DerivationPath)
+	assert.Equal(t, "normal dune pole key case cradle unfold require tornado mercy hospital buyer", account.Key.Mnemonic)
+
+	transformed := transformAdvancedKeyToJSON(account.Key)
+	assert.Equal(t, config.DefaultDerivationPath, transformed.DerivationPath)
+	assert.Equal(t, account.Key.Mnemonic, transformed.Mnemonic)
+}
+
 func Test_ConfigAccountOldFormats(t *testing.T) {
 	b := []byte(`{
 		"old-format-1": {
@@ -471,3 +517,42 @@ func Test_ConfigInvalidAddress(t *testing.T) {
 	_, err = jsonAccounts.transformToConfig()
 	assert.Equal(t, err.Error(), "could not parse address: zz")
 }
+
+func Test_ConfigAccountTags(t *testing.T) {
+	b := []byte(`{"admin":{"address":"f8d6e0586b0a20c7","key":"1272967fd2bd75234ae9037dd4694c1f00baad63a10c35172bf65fbb8ad74b47","tags":["admin","testnet-only"]},"ops":{"address":"3c1162386b0a245f","key":{"type":"hex","index":1,"signatureAlgorithm":"ECDSA_P256","hashAlgorithm":"SHA3_256","privateKey":"2272967fd2bd75234ae9037dd4694c1f00baad63a10c35172bf65fbb8ad74b47"},"tags":["ops"]}}`)
+
+	var jsonAccounts jsonAccounts
+	err := json.Unmarshal(b, &jsonAccounts)
+	assert.NoError(t, err)
+
+	accounts, err := jsonAccounts.transformToConfig()
+	assert.NoError(t, err)
+
+	admin, err := accounts.ByName("admin")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"admin", "testnet-only"}, admin.Tags)
+	ops, err := accounts.ByName("ops")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ops"}, ops.Tags)
+
+	x, err := json.Marshal(transformAccountsToJSON(accounts))
+	assert.NoError(t, err)
+	assert.Equal(t, string(b), string(x))
+}
+
+func Test_ConfigAccountInvalidTag(t *testing.T) {
+	b := []byte(`{
+		"test": {
+			"address": "f8d6e0586b0a20c7",
+			"key": "2332967fd2bd75234ae9037dd4694c1f00baad63a10c35172bf65fbb8ad74b44",
+			"tags": ["admin", "not a tag"]
+		}
+	}`)
+
+	var jsonAccounts jsonAccounts
+	err := json.Unmarshal(b, &jsonAccounts)
+	assert.NoError(t, err)
+
+	_, err = jsonAccounts.transformToConfig()
+	assert.EqualError(t, err, `invalid tags of account test: invalid account tag "not a tag", tags can only contain letters, digits, '_' and '-' and have at most 32 characters`)
+}
@@ -32,6 +32,7 @@ type jsonConfig struct {
 	Networks    jsonNetworks    `json:"networks,omitempty"`
 	Accounts    jsonAccounts    `json:"accounts,omitempty"`
 	Deployments jsonDeployments `json:"deployments,omitempty"`
+	Scripts     jsonScripts     `json:"scripts,omitempty"`
 }
 
 func (j *jsonConfig) transformToConfig() (*config.Config, error) {
@@ -60,25 +61,37 @@ func (j *jsonConfig) transformToConfig() (*config.Config, error) {
 		return nil, err
 	}
 
+	scripts, err := j.Scripts.transformToConfig()
+	if err != nil {
+		return nil, err
+	}
+
 	conf := &config.Config{
 		Emulators:   emulators,
 		Contracts:   contracts,
 		Networks:    networks,
 		Accounts:    accounts,
 		Deployments: deployments,
+		Scripts:     scripts,
 	}
 
 	return conf, nil
 }
 
-func transformConfigToJSON(config *config.Config) jsonConfig {
+func transformConfigToJSON(config *config.Config) (jsonConfig, error) {
+	deployments, err := transformDeploymentsToJSON(config.Deployments)
+	if err != nil {
+		return jsonConfig{}, err
+	}
+
 	return jsonConfig{
 		Emulators:   transformEmulatorsToJSON(config.Emulators),
 		Contracts:   transformContractsToJSON(config.Contracts),
 		Networks:    transformNetworksToJSON(config.Networks),
 		Accounts:    transformAccountsToJSON(config.Accounts),
-		Deployments: transformDeploymentsToJSON(config.Deployments),
-	}
+		Deployments: deployments,
+		Scripts:     transformScriptsToJSON(config.Scripts),
+	}, nil
 }
 
 type oldFormat struct {
@@ -111,7 +124,11 @@ func NewParser() *Parser {
 
 // Serialize configuration to raw.
 func (p *Parser) Serialize(conf *config.Config) ([]byte, error) {
-	jsonConf := transformConfigToJSON(conf)
+	jsonConf, err := transformConfigToJSON(conf)
+	if err != nil {
+		return nil, err
+	}
+
 	data, err := json.MarshalIndent(jsonConf, "", "\t")
 	if err != nil {
 		return nil, err
@@ -168,6 +168,24 @@ func Test_NonExistingAccountForEmulator(t *testing.T) {
 	assert.Equal(t, "emulator default contains nonexisting service account emulator-account", err.Error())
 }
 
+func Test_NonExistingDefaultSignerForNetwork(t *testing.T) {
+	b := []byte(`{
+		"networks": {
+			"testnet": {
+				"host": "access.devnet.nodes.onflow.org:9000",
+				"defaultSigner": "testnet-account"
+			}
+		}
+	}`)
+
+	parser := NewParser()
+	conf, err := parser.Deserialize(b)
+	assert.NoError(t, err)
+
+	err = conf.Validate()
+	assert.Equal(t, "network testnet contains nonexisting default signer testnet-account", err.Error())
+}
+
 // If config has default emulator values, it will not show up in flow.json
 func Test_SerializeConfigToJsonEmulatorDefault(t *testing.T) {
 	configJson := []byte(`{
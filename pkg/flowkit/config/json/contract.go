@@ -38,6 +38,14 @@ func (j jsonContracts) transformToConfig() (config.Contracts, error) {
 				Location: c.Simple,
 			}
 
+			contracts = append(contracts, contract)
+		} else if len(c.Advanced.Aliases) == 0 {
+			contract := config.Contract{
+				Name:        contractName,
+				Location:    c.Advanced.Source,
+				GeneratedBy: c.Advanced.GeneratedBy,
+			}
+
 			contracts = append(contracts, contract)
 		} else {
 			for network, alias := range c.Advanced.Aliases {
@@ -47,10 +55,11 @@ func (j jsonContracts) transformToConfig() (config.Contracts, error) {
 				}
 
 				contract := config.Contract{
-					Name:     contractName,
-					Location: c.Advanced.Source,
-					Network:  network,
-					Alias:    alias,
+					Name:        contractName,
+					Location:    c.Advanced.Source,
+					Network:     network,
+					Alias:       alias,
+					GeneratedBy: c.Advanced.GeneratedBy,
 				}
 
 				contracts = append(contracts, contract)
@@ -67,10 +76,17 @@ func transformContractsToJSON(contracts config.Contracts) jsonContracts {
 
 	for _, c := range contracts {
 		// if simple case
-		if c.Network == "" {
+		if c.Network == "" && !c.IsGenerated() {
 			jsonContracts[c.Name] = jsonContract{
 				Simple: c.Location,
 			}
+		} else if c.Network == "" { // if generated without aliases
+			jsonContracts[c.Name] = jsonContract{
+				Advanced: jsonContractAdvanced{
+					Source:      c.Location,
+					GeneratedBy: c.GeneratedBy,
+				},
+			}
 		} else { // if advanced config
 			// check if we already created for this name then add or create
 			if _, exists := jsonContracts[c.Name]; exists && jsonContracts[c.Name].Advanced.Aliases != nil {
@@ -78,8 +94,9 @@ func transformContractsToJSON(contracts config.Contracts) jsonContracts {
 			} else {
 				jsonContracts[c.Name] = jsonContract{
 					Advanced: jsonContractAdvanced{
-						Source:  c.Location,
-						Aliases: map[string]string{c.Network: c.Alias},
+						Source:      c.Location,
+						Aliases:     map[string]string{c.Network: c.Alias},
+						GeneratedBy: c.GeneratedBy,
 					},
 				}
 			}
@@ -91,8 +108,9 @@ func transformContractsToJSON(contracts config.Contracts) jsonContracts {
 
 // jsonContractAdvanced for json parsing advanced config.
 type jsonContractAdvanced struct {
-	Source  string            `json:"source"`
-	Aliases map[string]string `json:"aliases"`
+	Source      string            `json:"source"`
+	Aliases     map[string]string `json:"aliases,omitempty"`
+	GeneratedBy string            `json:"generatedBy,omitempty"`
 }
 
 // jsonContract structure for json parsing.
@@ -206,3 +206,41 @@ func Test_TransformComplexContractToJSON(t *testing.T) {
 
 	assert.JSONEq(t, string(b), string(x))
 }
+
+func Test_TransformGeneratedContractToJSON(t *testing.T) {
+	b := []byte(`{
+		"KittyItems": {
+			"source": "./cadence/kittyItems/contracts/KittyItems.cdc",
+			"generatedBy": "cadence-gen v1.2.3"
+		},
+		"KittyItemsMarket": {
+			"source": "./cadence/kittyItemsMarket/contracts/KittyItemsMarket.cdc",
+			"aliases": {
+				"testnet": "e5a8b7f23e8b548f"
+			},
+			"generatedBy": "cadence-gen v1.2.3"
+		}
+	}`)
+
+	var jsonContracts jsonContracts
+	err := json.Unmarshal(b, &jsonContracts)
+	assert.NoError(t, err)
+
+	contracts, err := jsonContracts.transformToConfig()
+	assert.NoError(t, err)
+	assert.Len(t, contracts, 2)
+
+	kittyItems, err := contracts.ByName("KittyItems")
+	assert.NoError(t, err)
+	assert.Equal(t, "cadence-gen v1.2.3", kittyItems.GeneratedBy)
+	assert.Equal(t, "", kittyItems.Network)
+
+	market, err := contracts.ByNameAndNetwork("KittyItemsMarket", "emulator")
+	assert.NoError(t, err)
+	assert.Equal(t, "cadence-gen v1.2.3", market.GeneratedBy)
+
+	j := transformContractsToJSON(contracts)
+	x, _ := json.Marshal(j)
+
+	assert.JSONEq(t, string(b), string(x))
+}
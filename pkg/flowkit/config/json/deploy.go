@@ -55,15 +55,13 @@ func (j jsonDeployments) transformToConfig() (config.Deployments, error) {
 					)
 				} else {
 					args := make([]cadence.Value, 0)
-					for _, arg := range contract.advanced.Args {
-						b, err := json.Marshal(arg)
+					for i, arg := range contract.advanced.Args {
+						cadenceArg, err := decodeArgument(arg)
 						if err != nil {
-							return nil, err
-						}
-
-						cadenceArg, err := jsoncdc.Decode(nil, b)
-						if err != nil {
-							return nil, err
+							return nil, fmt.Errorf(
+								"invalid argument %d of contract %s in deployment for account %s on network %s: %w",
+								i, contract.advanced.Name, accountName, networkName, err,
+							)
 						}
 
 						args = append(args, cadenceArg)
@@ -88,7 +86,7 @@ func (j jsonDeployments) transformToConfig() (config.Deployments, error) {
 }
 
 // transformToJSON transforms config structure to json structures for saving.
-func transformDeploymentsToJSON(configDeployments config.Deployments) jsonDeployments {
+func transformDeploymentsToJSON(configDeployments config.Deployments) (jsonDeployments, error) {
 	jsonDeploys := jsonDeployments{}
 
 	for _, d := range configDeployments {
@@ -101,19 +99,16 @@ func transformDeploymentsToJSON(configDeployments config.Deployments) jsonDeploy
 				})
 			} else {
 				args := make([]map[string]interface{}, 0)
-				for _, arg := range c.Args {
-					switch arg.Type().ID() {
-					case "Bool":
-						args = append(args, map[string]interface{}{
-							"type":  arg.Type().ID(),
-							"value": arg.ToGoValue(),
-						})
-					default:
-						args = append(args, map[string]interface{}{
-							"type":  arg.Type().ID(),
-							"value": fmt.Sprintf("%v", arg.ToGoValue()),
-						})
+				for i, arg := range c.Args {
+					jsonArg, err := encodeArgument(arg)
+					if err != nil {
+						return nil, fmt.Errorf(
+							"invalid argument %d of contract %s in deployment for account %s on network %s: %w",
+							i, c.Name, d.Account, d.Network, err,
+						)
 					}
+
+					args = append(args, jsonArg)
 				}
 
 				deployments = append(deployments, deployment{
@@ -135,7 +130,108 @@ func transformDeploymentsToJSON(configDeployments config.Deployments) jsonDeploy
 
 	}
 
-	return jsonDeploys
+	return jsonDeploys, nil
+}
+
+// encodeArgument encodes the cadence value in the JSON-Cadence interchange format.
+func encodeArgument(arg cadence.Value) (map[string]interface{}, error) {
+	b, err := jsoncdc.Encode(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	var jsonArg map[string]interface{}
+	err = json.Unmarshal(b, &jsonArg)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsonArg, nil
+}
+
+// decodeArgument decodes the argument from the JSON-Cadence interchange format.
+//
+// If the argument is invalid the returned error includes the JSON path of the innermost invalid value.
+func decodeArgument(arg map[string]interface{}) (cadence.Value, error) {
+	value, err := decodeValue(arg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value at %s: %w", invalidValuePath(arg, "$"), err)
+	}
+
+	return value, nil
+}
+
+func decodeValue(node interface{}) (cadence.Value, error) {
+	b, err := json.Marshal(node)
+	if err != nil {
+		return nil, err
+	}
+
+	return jsoncdc.Decode(nil, b)
+}
+
+// invalidValuePath returns the path of the innermost value that fails to decode, starting at the provided node.
+func invalidValuePath(node interface{}, path string) string {
+	for _, child := range childValues(node, path) {
+		if _, err := decodeValue(child.value); err != nil {
+			return invalidValuePath(child.value, child.path)
+		}
+	}
+
+	return path
+}
+
+type jsonValue struct {
+	path  string
+	value interface{}
+}
+
+// childValues returns the nested values of a composite JSON-Cadence value together with their paths.
+func childValues(node interface{}, path string) []jsonValue {
+	object, ok := node.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	kind, _ := object["type"].(string)
+	children := make([]jsonValue, 0)
+
+	switch kind {
+	case "Optional":
+		if object["value"] != nil {
+			children = append(children, jsonValue{path + ".value", object["value"]})
+		}
+	case "Array":
+		elements, _ := object["value"].([]interface{})
+		for i, element := range elements {
+			children = append(children, jsonValue{fmt.Sprintf("%s.value[%d]", path, i), element})
+		}
+	case "Dictionary":
+		entries, _ := object["value"].([]interface{})
+		for i, entry := range entries {
+			pair, ok := entry.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			children = append(
+				children,
+				jsonValue{fmt.Sprintf("%s.value[%d].key", path, i), pair["key"]},
+				jsonValue{fmt.Sprintf("%s.value[%d].value", path, i), pair["value"]},
+			)
+		}
+	case "Struct", "Resource", "Event", "Contract", "Enum":
+		composite, _ := object["value"].(map[string]interface{})
+		fields, _ := composite["fields"].([]interface{})
+		for i, field := range fields {
+			f, ok := field.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			children = append(children, jsonValue{fmt.Sprintf("%s.value.fields[%d].value", path, i), f["value"]})
+		}
+	}
+
+	return children
 }
 
 type contractDeployment struct {
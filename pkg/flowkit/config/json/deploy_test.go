@@ -19,12 +19,17 @@ package json
 
 import (
 	"encoding/json"
+	"fmt"
 	"regexp"
 	"strings"
 	"testing"
 
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-cli/pkg/flowkit/config"
 )
 
 func cleanSpecialChars(code []byte) string {
@@ -109,7 +114,8 @@ func Test_TransformDeployToJSON(t *testing.T) {
 	deployments, err := jsonDeployments.transformToConfig()
 	assert.NoError(t, err)
 
-	j := transformDeploymentsToJSON(deployments)
+	j, err := transformDeploymentsToJSON(deployments)
+	assert.NoError(t, err)
 	x, _ := json.Marshal(j)
 
 	assert.Equal(t, cleanSpecialChars(b), cleanSpecialChars(x))
@@ -151,3 +157,180 @@ func Test_DeploymentAdvanced(t *testing.T) {
 	assert.Equal(t, "KittyItemsMarket", alice[0].Contracts[1].Name)
 	assert.Len(t, alice[0].Contracts[1].Args, 0)
 }
+
+func Test_DeploymentComplexArgs(t *testing.T) {
+	// deploymentWithArg returns a deployment of the Rates contract with the provided JSON-Cadence argument
+	deploymentWithArg := func(arg string) []byte {
+		return []byte(fmt.Sprintf(`{
+			"emulator": {
+				"alice": [{ "name": "Rates", "args": [%s] }]
+			}
+		}`, arg))
+	}
+
+	// roundTrip loads the deployments, serializes them and loads them again
+	roundTrip := func(t *testing.T, b []byte) (config.Deployments, config.Deployments) {
+		var loaded jsonDeployments
+		require.NoError(t, json.Unmarshal(b, &loaded))
+
+		deployments, err := loaded.transformToConfig()
+		require.NoError(t, err)
+
+		serialized, err := transformDeploymentsToJSON(deployments)
+		require.NoError(t, err)
+		x, err := json.Marshal(serialized)
+		require.NoError(t, err)
+
+		var reloadedJSON jsonDeployments
+		require.NoError(t, json.Unmarshal(x, &reloadedJSON))
+		reloaded, err := reloadedJSON.transformToConfig()
+		require.NoError(t, err)
+
+		return deployments, reloaded
+	}
+
+	tests := []struct {
+		name     string
+		arg      string
+		expected cadence.Value
+	}{{
+		name: "Dictionary",
+		arg: `{
+			"type": "Dictionary",
+			"value": [
+				{ "key": { "type": "String", "value": "USD" }, "value": { "type": "UFix64", "value": "1.00000000" } },
+				{ "key": { "type": "String", "value": "EUR" }, "value": { "type": "UFix64", "value": "0.92000000" } }
+			]
+		}`,
+		expected: cadence.NewDictionary([]cadence.KeyValuePair{{
+			Key:   cadence.String("USD"),
+			Value: cadence.UFix64(100000000),
+		}, {
+			Key:   cadence.String("EUR"),
+			Value: cadence.UFix64(92000000),
+		}}),
+	}, {
+		name: "Array",
+		arg: `{
+			"type": "Array",
+			"value": [{ "type": "Int", "value": "1" }, { "type": "Int", "value": "-2" }]
+		}`,
+		expected: cadence.NewArray([]cadence.Value{cadence.NewInt(1), cadence.NewInt(-2)}),
+	}, {
+		name:     "Optional",
+		arg:      `{ "type": "Optional", "value": { "type": "UInt64", "value": "42" } }`,
+		expected: cadence.NewOptional(cadence.NewUInt64(42)),
+	}, {
+		name:     "Optional Nil",
+		arg:      `{ "type": "Optional", "value": null }`,
+		expected: cadence.NewOptional(nil),
+	}, {
+		name:     "Address",
+		arg:      `{ "type": "Address", "value": "0xf8d6e0586b0a20c7" }`,
+		expected: cadence.NewAddress(flow.HexToAddress("f8d6e0586b0a20c7")),
+	}, {
+		name: "Path",
+		arg:  `{ "type": "Path", "value": { "domain": "storage", "identifier": "rates" } }`,
+		expected: cadence.Path{
+			Domain:     "storage",
+			Identifier: "rates",
+		},
+	}}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			deployments, reloaded := roundTrip(t, deploymentWithArg(test.arg))
+
+			args := deployments.ByAccountAndNetwork("alice", "emulator")[0].Contracts[0].Args
+			require.Len(t, args, 1)
+			assert.Equal(t, test.expected, args[0])
+			assert.Equal(t, deployments, reloaded)
+		})
+	}
+
+	t.Run("Array of Structs", func(t *testing.T) {
+		deployments, reloaded := roundTrip(t, deploymentWithArg(`{
+			"type": "Array",
+			"value": [{
+				"type": "Struct",
+				"value": {
+					"id": "A.f8d6e0586b0a20c7.Rates.Rate",
+					"fields": [
+						{ "name": "currency", "value": { "type": "String", "value": "USD" } },
+						{ "name": "receiver", "value": { "type": "Address", "value": "0xf8d6e0586b0a20c7" } },
+						{ "name": "vault", "value": { "type": "Path", "value": { "domain": "public", "identifier": "usdVault" } } }
+					]
+				}
+			}]
+		}`))
+
+		args := deployments.ByAccountAndNetwork("alice", "emulator")[0].Contracts[0].Args
+		require.Len(t, args, 1)
+		rates := args[0].(cadence.Array)
+		require.Len(t, rates.Values, 1)
+		rate := rates.Values[0].(cadence.Struct)
+		assert.Equal(t, "A.f8d6e0586b0a20c7.Rates.Rate", rate.StructType.ID())
+		assert.Equal(t, []cadence.Value{
+			cadence.String("USD"),
+			cadence.NewAddress(flow.HexToAddress("f8d6e0586b0a20c7")),
+			cadence.Path{Domain: "public", Identifier: "usdVault"},
+		}, rate.Fields)
+		assert.Equal(t, deployments, reloaded)
+	})
+
+	t.Run("Invalid Values", func(t *testing.T) {
+		invalid := []struct {
+			arg  string
+			path string
+		}{{
+			arg:  `{ "type": "UFix64", "value": "one" }`,
+			path: "$",
+		}, {
+			arg: `{
+				"type": "Dictionary",
+				"value": [
+					{ "key": { "type": "String", "value": "USD" }, "value": { "type": "UFix64", "value": "1.0" } },
+					{ "key": { "type": "String", "value": "EUR" }, "value": { "type": "UFix64", "value": "0.92%" } }
+				]
+			}`,
+			path: "$.value[1].value",
+		}, {
+			arg: `{
+				"type": "Dictionary",
+				"value": [{ "key": { "type": "Strin", "value": "USD" }, "value": { "type": "UFix64", "value": "1.0" } }]
+			}`,
+			path: "$.value[0].key",
+		}, {
+			arg:  `{ "type": "Optional", "value": { "type": "Address", "value": "0xnotanaddress" } }`,
+			path: "$.value",
+		}, {
+			arg: `{
+				"type": "Array",
+				"value": [{
+					"type": "Struct",
+					"value": {
+						"id": "A.f8d6e0586b0a20c7.Rates.Rate",
+						"fields": [
+							{ "name": "currency", "value": { "type": "String", "value": "USD" } },
+							{ "name": "vault", "value": { "type": "Path", "value": { "domain": "public" } } }
+						]
+					}
+				}]
+			}`,
+			path: "$.value[0].value.fields[1].value",
+		}}
+
+		for _, test := range invalid {
+			var loaded jsonDeployments
+			require.NoError(t, json.Unmarshal(deploymentWithArg(test.arg), &loaded))
+
+			_, err := loaded.transformToConfig()
+			require.Error(t, err, test.arg)
+			assert.Contains(
+				t,
+				err.Error(),
+				fmt.Sprintf("invalid argument 0 of contract Rates in deployment for account alice on network emulator: invalid value at %s:", test.path),
+			)
+		}
+	})
+}
@@ -0,0 +1,117 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package json
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+
+	"github.com/onflow/flow-cli/pkg/flowkit/config"
+)
+
+var _ config.KeyFileParser = &Parser{}
+
+// jsonKeyFile is the key file of an account, the key has the simple or the advanced account key format.
+type jsonKeyFile struct {
+	Address string          `json:"address,omitempty"`
+	Key     json.RawMessage `json:"key"`
+}
+
+// DeserializeKeyFile returns the key and the optional address of the account from the key file.
+func (p *Parser) DeserializeKeyFile(accountName string, raw []byte) (flow.Address, config.AccountKey, error) {
+	var keyFile jsonKeyFile
+	err := json.Unmarshal(raw, &keyFile)
+	if err != nil {
+		return flow.EmptyAddress, config.AccountKey{}, fmt.Errorf("key file syntax error: %w", err)
+	}
+	if len(keyFile.Key) == 0 {
+		return flow.EmptyAddress, config.AccountKey{}, fmt.Errorf("missing key")
+	}
+
+	address := flow.EmptyAddress
+	if keyFile.Address != "" {
+		address, err = transformAddress(keyFile.Address)
+		if err != nil {
+			return flow.EmptyAddress, config.AccountKey{}, err
+		}
+	}
+
+	key, err := transformKeyFileKeyToConfig(accountName, keyFile.Key)
+	if err != nil {
+		return flow.EmptyAddress, config.AccountKey{}, err
+	}
+
+	return address, key, nil
+}
+
+func transformKeyFileKeyToConfig(accountName string, raw json.RawMessage) (config.AccountKey, error) {
+	var simple string
+	if json.Unmarshal(raw, &simple) == nil {
+		pkey, err := config.DecodeAccountPrivateKey(accountName, crypto.ECDSA_P256, strings.TrimPrefix(simple, "0x"))
+		var mismatchErr *config.SigAlgoMismatchError
+		if errors.As(err, &mismatchErr) {
+			return config.AccountKey{}, fmt.Errorf("%w, the simple key format only supports %s keys", err, crypto.ECDSA_P256)
+		}
+		if err != nil {
+			return config.AccountKey{}, fmt.Errorf("invalid private key for account: %s", accountName)
+		}
+
+		return config.AccountKey{
+			Type:       config.KeyTypeHex,
+			SigAlgo:    crypto.ECDSA_P256,
+			HashAlgo:   crypto.SHA3_256,
+			PrivateKey: pkey,
+		}, nil
+	}
+
+	var advanced advanceKey
+	if err := json.Unmarshal(raw, &advanced); err != nil {
+		return config.AccountKey{}, fmt.Errorf("key file syntax error: %w", err)
+	}
+	if advanced.FromFile != "" {
+		return config.AccountKey{}, config.ErrNestedKeyFile
+	}
+
+	return transformAdvancedKeyToConfig(accountName, advanced)
+}
+
+// SerializeKeyFile returns the key file containing the key and the address of the account.
+func (p *Parser) SerializeKeyFile(address flow.Address, key config.AccountKey) ([]byte, error) {
+	var jsonKey any = transformAdvancedKeyToJSON(key)
+	if isDefaultKeyFormat(key) {
+		jsonKey = strings.TrimPrefix(key.PrivateKey.String(), "0x")
+	}
+
+	rawKey, err := json.Marshal(jsonKey)
+	if err != nil {
+		return nil, err
+	}
+
+	keyFile := jsonKeyFile{Key: rawKey}
+	if address != flow.EmptyAddress {
+		keyFile.Address = address.String()
+	}
+
+	return json.MarshalIndent(keyFile, "", "\t")
+}
@@ -33,16 +33,47 @@ func (j jsonNetworks) transformToConfig() (config.Networks, error) {
 	networks := make(config.Networks, 0)
 
 	for networkName, n := range j {
-		if n.Advanced.Key != "" && n.Advanced.Host != "" {
-			err := util.ValidateECDSAP256Pub(n.Advanced.Key)
-			if err != nil {
-				return nil, fmt.Errorf("invalid key %s for network with name %s", n.Advanced.Key, networkName)
+		// the advanced format requires a key, a default signer, a registry, strict imports or a compute limit
+		advanced := n.Advanced.Key != "" || n.Advanced.DefaultSigner != "" || n.Advanced.ContractRegistry != "" ||
+			n.Advanced.StrictImports || n.Advanced.ComputeLimit != 0 ||
+			n.Advanced.FindRegistry != "" || n.Advanced.FlownsRegistry != ""
+		if n.Advanced.Host != "" && advanced {
+			if n.Advanced.Key != "" {
+				err := util.ValidateECDSAP256Pub(n.Advanced.Key)
+				if err != nil {
+					return nil, fmt.Errorf("invalid key %s for network with name %s", n.Advanced.Key, networkName)
+				}
+			}
+
+			registries := []struct{ kind, address string }{
+				{"contract", n.Advanced.ContractRegistry},
+				{"find", n.Advanced.FindRegistry},
+				{"flowns", n.Advanced.FlownsRegistry},
+			}
+			for _, registry := range registries {
+				if registry.address == "" {
+					continue
+				}
+				if _, valid := util.ParseAddress(registry.address); !valid {
+					return nil, fmt.Errorf(
+						"invalid %s registry address %s for network with name %s",
+						registry.kind,
+						registry.address,
+						networkName,
+					)
+				}
 			}
 
 			networks = append(networks, config.Network{
-				Name: networkName,
-				Host: n.Advanced.Host,
-				Key:  n.Advanced.Key,
+				Name:             networkName,
+				Host:             n.Advanced.Host,
+				Key:              n.Advanced.Key,
+				DefaultSigner:    n.Advanced.DefaultSigner,
+				ContractRegistry: n.Advanced.ContractRegistry,
+				StrictImports:    n.Advanced.StrictImports,
+				ComputeLimit:     n.Advanced.ComputeLimit,
+				FindRegistry:     n.Advanced.FindRegistry,
+				FlownsRegistry:   n.Advanced.FlownsRegistry,
 			})
 		} else if n.Simple.Host != "" {
 			networks = append(networks, config.Network{
@@ -62,7 +93,8 @@ func transformNetworksToJSON(networks config.Networks) jsonNetworks {
 	jsonNetworks := jsonNetworks{}
 
 	for _, n := range networks {
-		if n.Key != "" {
+		if n.Key != "" || n.DefaultSigner != "" || n.ContractRegistry != "" || n.StrictImports || n.ComputeLimit != 0 ||
+			n.FindRegistry != "" || n.FlownsRegistry != "" {
 			jsonNetworks[n.Name] = transformAdvancedNetworkToJSON(n)
 		} else {
 			jsonNetworks[n.Name] = transformSimpleNetworkToJSON(n)
@@ -83,8 +115,14 @@ func transformSimpleNetworkToJSON(n config.Network) jsonNetwork {
 func transformAdvancedNetworkToJSON(n config.Network) jsonNetwork {
 	return jsonNetwork{
 		Advanced: advancedNetwork{
-			Host: n.Host,
-			Key:  n.Key,
+			Host:             n.Host,
+			Key:              n.Key,
+			DefaultSigner:    n.DefaultSigner,
+			ContractRegistry: n.ContractRegistry,
+			StrictImports:    n.StrictImports,
+			ComputeLimit:     n.ComputeLimit,
+			FindRegistry:     n.FindRegistry,
+			FlownsRegistry:   n.FlownsRegistry,
 		},
 	}
 }
@@ -99,8 +137,14 @@ type simpleNetwork struct {
 }
 
 type advancedNetwork struct {
-	Host string `json:"host"`
-	Key  string `json:"key"`
+	Host             string `json:"host"`
+	Key              string `json:"key,omitempty"`
+	DefaultSigner    string `json:"defaultSigner,omitempty"`
+	ContractRegistry string `json:"contractRegistry,omitempty"`
+	StrictImports    bool   `json:"strictImports,omitempty"`
+	ComputeLimit     uint64 `json:"computeLimit,omitempty"`
+	FindRegistry     string `json:"findRegistry,omitempty"`
+	FlownsRegistry   string `json:"flownsRegistry,omitempty"`
 }
 
 func (j *jsonNetwork) UnmarshalJSON(b []byte) error {
@@ -115,8 +159,7 @@ func (j *jsonNetwork) UnmarshalJSON(b []byte) error {
 	var advanced advancedNetwork
 	err = json.Unmarshal(b, &advanced)
 	if err == nil {
-		j.Advanced.Host = advanced.Host
-		j.Advanced.Key = advanced.Key
+		j.Advanced = advanced
 	}
 
 	return err
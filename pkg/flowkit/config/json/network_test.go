@@ -153,3 +153,114 @@ func Test_TransformConfigAdvanced(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func Test_ConfigNetworkDefaultSigner(t *testing.T) {
+	b := []byte(`{"emulator":"127.0.0.1:3569","testnet":{"host":"access.testnet.nodes.onflow.org:9000","defaultSigner":"testnet-account"}}`)
+
+	var jsonNetworks jsonNetworks
+	err := json.Unmarshal(b, &jsonNetworks)
+	assert.NoError(t, err)
+
+	networks, err := jsonNetworks.transformToConfig()
+	assert.NoError(t, err)
+
+	testnet, err := networks.ByName("testnet")
+	assert.NoError(t, err)
+	assert.Equal(t, "access.testnet.nodes.onflow.org:9000", testnet.Host)
+	assert.Equal(t, "testnet-account", testnet.DefaultSigner)
+	assert.Empty(t, testnet.Key)
+
+	x, _ := json.Marshal(transformNetworksToJSON(networks))
+	assert.Equal(t, string(b), string(x))
+}
+
+func Test_ConfigNetworkContractRegistry(t *testing.T) {
+	b := []byte(`{"testnet":{"host":"access.testnet.nodes.onflow.org:9000","contractRegistry":"0x9a0766d93b6608b7"}}`)
+
+	var jsonNetworks jsonNetworks
+	err := json.Unmarshal(b, &jsonNetworks)
+	assert.NoError(t, err)
+
+	networks, err := jsonNetworks.transformToConfig()
+	assert.NoError(t, err)
+
+	testnet, err := networks.ByName("testnet")
+	assert.NoError(t, err)
+	assert.Equal(t, "0x9a0766d93b6608b7", testnet.ContractRegistry)
+
+	x, _ := json.Marshal(transformNetworksToJSON(networks))
+	assert.Equal(t, string(b), string(x))
+}
+
+func Test_ConfigNetworkInvalidContractRegistry(t *testing.T) {
+	b := []byte(`{"testnet":{"host":"access.testnet.nodes.onflow.org:9000","contractRegistry":"0xzz"}}`)
+
+	var jsonNetworks jsonNetworks
+	err := json.Unmarshal(b, &jsonNetworks)
+	assert.NoError(t, err)
+
+	_, err = jsonNetworks.transformToConfig()
+	assert.EqualError(t, err, "invalid contract registry address 0xzz for network with name testnet")
+}
+
+func Test_ConfigNetworkStrictImports(t *testing.T) {
+	b := []byte(`{"testnet":{"host":"access.testnet.nodes.onflow.org:9000","strictImports":true}}`)
+
+	var jsonNetworks jsonNetworks
+	err := json.Unmarshal(b, &jsonNetworks)
+	assert.NoError(t, err)
+
+	networks, err := jsonNetworks.transformToConfig()
+	assert.NoError(t, err)
+
+	testnet, err := networks.ByName("testnet")
+	assert.NoError(t, err)
+	assert.True(t, testnet.StrictImports)
+
+	x, _ := json.Marshal(transformNetworksToJSON(networks))
+	assert.Equal(t, string(b), string(x))
+}
+
+func Test_ConfigNetworkComputeLimit(t *testing.T) {
+	b := []byte(`{"testnet":{"host":"access.testnet.nodes.onflow.org:9000","computeLimit":1000}}`)
+
+	var jsonNetworks jsonNetworks
+	err := json.Unmarshal(b, &jsonNetworks)
+	assert.NoError(t, err)
+
+	networks, err := jsonNetworks.transformToConfig()
+	assert.NoError(t, err)
+
+	testnet, err := networks.ByName("testnet")
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1000), testnet.ComputeLimit)
+
+	x, _ := json.Marshal(transformNetworksToJSON(networks))
+	assert.Equal(t, string(b), string(x))
+}
+
+func Test_ConfigNetworkNameRegistries(t *testing.T) {
+	b := []byte(`{"testnet":{"host":"access.testnet.nodes.onflow.org:9000","findRegistry":"0x35717efbbce11c74","flownsRegistry":"0xb05b2abb42335e88"}}`)
+
+	var jsonNetworks jsonNetworks
+	err := json.Unmarshal(b, &jsonNetworks)
+	assert.NoError(t, err)
+
+	networks, err := jsonNetworks.transformToConfig()
+	assert.NoError(t, err)
+
+	testnet, err := networks.ByName("testnet")
+	assert.NoError(t, err)
+	assert.Equal(t, "0x35717efbbce11c74", testnet.FindRegistry)
+	assert.Equal(t, "0xb05b2abb42335e88", testnet.FlownsRegistry)
+
+	x, _ := json.Marshal(transformNetworksToJSON(networks))
+	assert.Equal(t, string(b), string(x))
+
+	b = []byte(`{"testnet":{"host":"access.testnet.nodes.onflow.org:9000","flownsRegistry":"0xzz"}}`)
+	err = json.Unmarshal(b, &jsonNetworks)
+	assert.NoError(t, err)
+
+	_, err = jsonNetworks.transformToConfig()
+	assert.EqualError(t, err, "invalid flowns registry address 0xzz for network with name testnet")
+}
@@ -0,0 +1,134 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/onflow/flow-cli/pkg/flowkit/config"
+)
+
+type jsonScripts map[string]jsonScript
+
+// transformToConfig transforms json structures to config structure.
+func (j jsonScripts) transformToConfig() (config.Scripts, error) {
+	var scripts config.Scripts
+
+	for name, s := range j {
+		script := config.Script{
+			Name:     name,
+			Location: s.Simple,
+		}
+
+		if s.Simple == "" {
+			script.Location = s.Advanced.Source
+			for _, arg := range s.Advanced.Arguments {
+				if arg.Name == "" || arg.Type == "" {
+					return nil, fmt.Errorf("argument of script %s is missing a name or a type", name)
+				}
+				script.Arguments = append(script.Arguments, config.ScriptArgument{
+					Name: arg.Name,
+					Type: arg.Type,
+				})
+			}
+		}
+
+		scripts = append(scripts, script)
+	}
+
+	return scripts, nil
+}
+
+// transformScriptsToJSON transforms config structure to json structures for saving.
+func transformScriptsToJSON(scripts config.Scripts) jsonScripts {
+	jsonScripts := jsonScripts{}
+
+	for _, s := range scripts {
+		if len(s.Arguments) == 0 {
+			jsonScripts[s.Name] = jsonScript{
+				Simple: s.Location,
+			}
+			continue
+		}
+
+		args := make([]jsonScriptArgument, len(s.Arguments))
+		for i, arg := range s.Arguments {
+			args[i] = jsonScriptArgument{
+				Name: arg.Name,
+				Type: arg.Type,
+			}
+		}
+
+		jsonScripts[s.Name] = jsonScript{
+			Advanced: jsonScriptAdvanced{
+				Source:    s.Location,
+				Arguments: args,
+			},
+		}
+	}
+
+	return jsonScripts
+}
+
+// jsonScriptArgument for json parsing of the declared script arguments.
+type jsonScriptArgument struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// jsonScriptAdvanced for json parsing advanced config.
+type jsonScriptAdvanced struct {
+	Source    string               `json:"source"`
+	Arguments []jsonScriptArgument `json:"arguments,omitempty"`
+}
+
+// jsonScript structure for json parsing.
+type jsonScript struct {
+	Simple   string
+	Advanced jsonScriptAdvanced
+}
+
+func (j *jsonScript) UnmarshalJSON(b []byte) error {
+	var source string
+	var advancedFormat jsonScriptAdvanced
+
+	// simple
+	err := json.Unmarshal(b, &source)
+	if err == nil {
+		j.Simple = source
+		return nil
+	}
+
+	// advanced
+	err = json.Unmarshal(b, &advancedFormat)
+	if err != nil {
+		return err
+	}
+
+	j.Advanced = advancedFormat
+	return nil
+}
+
+func (j jsonScript) MarshalJSON() ([]byte, error) {
+	if j.Simple != "" {
+		return json.Marshal(j.Simple)
+	}
+	return json.Marshal(j.Advanced)
+}
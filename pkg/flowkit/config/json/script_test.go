@@ -0,0 +1,141 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package json
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-cli/pkg/flowkit/config"
+)
+
+func Test_ConfigScripts(t *testing.T) {
+	b := []byte(`{
+		"balance": {
+			"source": "./cadence/scripts/balance.cdc",
+			"arguments": [
+				{ "name": "address", "type": "Address" },
+				{ "name": "path", "type": "PublicPath?" }
+			]
+		},
+		"admins": "./cadence/scripts/admins.cdc"
+	}`)
+
+	var jsonScripts jsonScripts
+	err := json.Unmarshal(b, &jsonScripts)
+	require.NoError(t, err)
+
+	scripts, err := jsonScripts.transformToConfig()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"admins", "balance"}, scripts.Names())
+
+	balance, err := scripts.ByName("balance")
+	require.NoError(t, err)
+	assert.Equal(t, "./cadence/scripts/balance.cdc", balance.Location)
+	assert.Equal(t, []config.ScriptArgument{
+		{Name: "address", Type: "Address"},
+		{Name: "path", Type: "PublicPath?"},
+	}, balance.Arguments)
+	assert.False(t, balance.Arguments[0].Optional())
+	assert.True(t, balance.Arguments[1].Optional())
+
+	admins, err := scripts.ByName("admins")
+	require.NoError(t, err)
+	assert.Equal(t, "./cadence/scripts/admins.cdc", admins.Location)
+	assert.Empty(t, admins.Arguments)
+
+	serialized, err := json.Marshal(transformScriptsToJSON(scripts))
+	require.NoError(t, err)
+	assert.JSONEq(t, string(b), string(serialized))
+}
+
+func Test_ConfigScriptsMissingArgumentType(t *testing.T) {
+	b := []byte(`{
+		"balance": {
+			"source": "./cadence/scripts/balance.cdc",
+			"arguments": [{ "name": "address" }]
+		}
+	}`)
+
+	var jsonScripts jsonScripts
+	err := json.Unmarshal(b, &jsonScripts)
+	require.NoError(t, err)
+
+	_, err = jsonScripts.transformToConfig()
+	assert.EqualError(t, err, "argument of script balance is missing a name or a type")
+}
+
+func Test_ConfigScriptsValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments string
+		err       string
+	}{
+		{
+			name:      "valid",
+			arguments: `[{ "name": "amounts", "type": "[UFix64]" }, { "name": "owners", "type": "{String: Address}" }]`,
+		},
+		{
+			name:      "unknown type",
+			arguments: `[{ "name": "vault", "type": "FlowToken.Vault" }]`,
+			err:       `script balance argument vault: unknown type "FlowToken.Vault"`,
+		},
+		{
+			name:      "invalid type",
+			arguments: `[{ "name": "amount", "type": "UFix64(" }]`,
+			err:       `script balance argument amount: invalid type "UFix64("`,
+		},
+		{
+			name:      "duplicate argument",
+			arguments: `[{ "name": "amount", "type": "UFix64" }, { "name": "amount", "type": "UFix64" }]`,
+			err:       "script balance contains duplicate argument amount",
+		},
+		{
+			name:      "invalid argument name",
+			arguments: `[{ "name": "the amount", "type": "UFix64" }]`,
+			err:       `script balance contains invalid argument name "the amount"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b := []byte(`{
+				"scripts": {
+					"balance": {
+						"source": "./cadence/scripts/balance.cdc",
+						"arguments": ` + test.arguments + `
+					}
+				}
+			}`)
+
+			conf, err := NewParser().Deserialize(b)
+			require.NoError(t, err)
+
+			err = conf.Validate()
+			if test.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, test.err)
+		})
+	}
+}
@@ -0,0 +1,267 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/onflow/flow-cli/pkg/flowkit/config"
+)
+
+// section converts a top-level section of the configuration.
+type section struct {
+	key      string
+	toJSON   func(conf *config.Config) (any, int, error)
+	fromJSON func(dec *json.Decoder, conf *config.Config) error
+}
+
+// sections lists the top-level sections in the order they are serialized.
+var sections = []section{{
+	key: "emulators",
+	toJSON: func(conf *config.Config) (any, int, error) {
+		emulators := transformEmulatorsToJSON(conf.Emulators)
+		return emulators, len(emulators), nil
+	},
+	fromJSON: func(dec *json.Decoder, conf *config.Config) (err error) {
+		var emulators jsonEmulators
+		if err := dec.Decode(&emulators); err != nil {
+			return syntaxError(err)
+		}
+		conf.Emulators, err = emulators.transformToConfig()
+		return err
+	},
+}, {
+	key: "contracts",
+	toJSON: func(conf *config.Config) (any, int, error) {
+		contracts := transformContractsToJSON(conf.Contracts)
+		return contracts, len(contracts), nil
+	},
+	fromJSON: func(dec *json.Decoder, conf *config.Config) (err error) {
+		var contracts jsonContracts
+		if err := dec.Decode(&contracts); err != nil {
+			return syntaxError(err)
+		}
+		conf.Contracts, err = contracts.transformToConfig()
+		return err
+	},
+}, {
+	key: "networks",
+	toJSON: func(conf *config.Config) (any, int, error) {
+		networks := transformNetworksToJSON(conf.Networks)
+		return networks, len(networks), nil
+	},
+	fromJSON: func(dec *json.Decoder, conf *config.Config) (err error) {
+		var networks jsonNetworks
+		if err := dec.Decode(&networks); err != nil {
+			return syntaxError(err)
+		}
+		conf.Networks, err = networks.transformToConfig()
+		return err
+	},
+}, {
+	key: "accounts",
+	toJSON: func(conf *config.Config) (any, int, error) {
+		accounts := transformAccountsToJSON(conf.Accounts)
+		return accounts, len(accounts), nil
+	},
+	fromJSON: func(dec *json.Decoder, conf *config.Config) (err error) {
+		var accounts jsonAccounts
+		if err := dec.Decode(&accounts); err != nil {
+			return syntaxError(err)
+		}
+		conf.Accounts, err = accounts.transformToConfig()
+		return err
+	},
+}, {
+	key: "deployments",
+	toJSON: func(conf *config.Config) (any, int, error) {
+		deployments, err := transformDeploymentsToJSON(conf.Deployments)
+		return deployments, len(deployments), err
+	},
+	fromJSON: func(dec *json.Decoder, conf *config.Config) (err error) {
+		var deployments jsonDeployments
+		if err := dec.Decode(&deployments); err != nil {
+			return syntaxError(err)
+		}
+		conf.Deployments, err = deployments.transformToConfig()
+		return err
+	},
+}, {
+	key: "scripts",
+	toJSON: func(conf *config.Config) (any, int, error) {
+		scripts := transformScriptsToJSON(conf.Scripts)
+		return scripts, len(scripts), nil
+	},
+	fromJSON: func(dec *json.Decoder, conf *config.Config) (err error) {
+		var scripts jsonScripts
+		if err := dec.Decode(&scripts); err != nil {
+			return syntaxError(err)
+		}
+		conf.Scripts, err = scripts.transformToConfig()
+		return err
+	},
+}}
+
+// SerializeTo writes the configuration to the writer one top-level section at a time.
+//
+// Only a single section is held in its JSON form at once, the written document is the same as the one
+// returned by Serialize.
+func (p *Parser) SerializeTo(w io.Writer, conf *config.Config) error {
+	enc := json.NewEncoder(trimNewlineWriter{w})
+	enc.SetIndent("\t", "\t")
+
+	if _, err := io.WriteString(w, "{"); err != nil {
+		return err
+	}
+
+	written := 0
+	for _, s := range sections {
+		value, size, err := s.toJSON(conf)
+		if err != nil {
+			return err
+		}
+		if size == 0 { // same as omitempty of the buffered format
+			continue
+		}
+
+		separator := "\n\t"
+		if written > 0 {
+			separator = ",\n\t"
+		}
+		if _, err := fmt.Fprintf(w, "%s%q: ", separator, s.key); err != nil {
+			return err
+		}
+		if err := enc.Encode(value); err != nil {
+			return err
+		}
+		written++
+	}
+
+	end := "}"
+	if written > 0 {
+		end = "\n}"
+	}
+	_, err := io.WriteString(w, end)
+	return err
+}
+
+// DeserializeFrom reads the configuration from the reader one top-level section at a time.
+//
+// Each section is converted to the configuration as soon as it's decoded, unknown sections are skipped.
+func (p *Parser) DeserializeFrom(r io.Reader) (*config.Config, error) {
+	conf := config.Empty()
+	dec := json.NewDecoder(r)
+
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, syntaxError(err)
+	}
+
+	decoded := make(map[string]bool)
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return nil, syntaxError(err)
+		}
+		key, _ := token.(string) // object keys are always strings
+
+		if key == "host" { // key of the old configuration format
+			var host any
+			if err := dec.Decode(&host); err != nil {
+				return nil, syntaxError(err)
+			}
+			if host != nil {
+				return nil, config.ErrOutdatedFormat
+			}
+			continue
+		}
+
+		if err := decodeSection(dec, key, conf); err != nil {
+			return nil, err
+		}
+		decoded[key] = true
+	}
+
+	if err := expectDelim(dec, '}'); err != nil {
+		return nil, syntaxError(err)
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, syntaxError(fmt.Errorf("unexpected data after the configuration"))
+	}
+
+	// missing sections are converted the same as by Deserialize, as if they were null
+	for _, s := range sections {
+		if decoded[s.key] {
+			continue
+		}
+		if err := s.fromJSON(json.NewDecoder(strings.NewReader("null")), conf); err != nil {
+			return nil, err
+		}
+	}
+
+	return conf, nil
+}
+
+func decodeSection(dec *json.Decoder, key string, conf *config.Config) error {
+	for _, s := range sections {
+		if s.key != key {
+			continue
+		}
+
+		return s.fromJSON(dec, conf)
+	}
+
+	var skipped json.RawMessage
+	if err := dec.Decode(&skipped); err != nil {
+		return syntaxError(err)
+	}
+	return nil
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("expected %s but found %v", delim, token)
+	}
+	return nil
+}
+
+func syntaxError(err error) error {
+	return fmt.Errorf("configuration syntax error: %w", err)
+}
+
+// trimNewlineWriter drops the newline json.Encoder terminates each encoded value with,
+// so the sections can be followed by the separators of the enclosing object.
+type trimNewlineWriter struct {
+	w io.Writer
+}
+
+func (t trimNewlineWriter) Write(p []byte) (int, error) {
+	if _, err := t.w.Write(bytes.TrimSuffix(p, []byte("\n"))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,132 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package json
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-cli/pkg/flowkit/config"
+)
+
+func Test_StreamingConfig(t *testing.T) {
+	b := []byte(`{
+		"emulators": {
+			"default": {
+				"port": 3569,
+				"serviceAccount": "emulator-account"
+			}
+		},
+		"contracts": {
+			"Foo": "./Foo.cdc",
+			"Bar": {
+				"source": "./Bar.cdc",
+				"aliases": {
+					"testnet": "9a0766d93b6608b7"
+				}
+			}
+		},
+		"networks": {
+			"emulator": "127.0.0.1:3569",
+			"testnet": "access.devnet.nodes.onflow.org:9000"
+		},
+		"accounts": {
+			"emulator-account": {
+				"address": "f8d6e0586b0a20c7",
+				"key": "11c5dfdeb0ff03a7a73ef39788563b62c89adea67bbb21ab95e5f710bd1d40b7"
+			}
+		},
+		"deployments": {
+			"emulator": {
+				"emulator-account": ["Foo", "Bar"]
+			}
+		}
+	}`)
+
+	parser := NewParser()
+	conf, err := parser.Deserialize(b)
+	require.NoError(t, err)
+
+	t.Run("Serialize", func(t *testing.T) {
+		buffered, err := parser.Serialize(conf)
+		require.NoError(t, err)
+
+		var streamed bytes.Buffer
+		err = parser.SerializeTo(&streamed, conf)
+		require.NoError(t, err)
+
+		assert.Equal(t, string(buffered), streamed.String())
+	})
+
+	t.Run("Serialize Empty", func(t *testing.T) {
+		var streamed bytes.Buffer
+		err := parser.SerializeTo(&streamed, config.Empty())
+		require.NoError(t, err)
+
+		assert.Equal(t, "{}", streamed.String())
+	})
+
+	t.Run("Deserialize", func(t *testing.T) {
+		streamed, err := parser.DeserializeFrom(bytes.NewReader(b))
+		require.NoError(t, err)
+
+		// sections are decoded as maps, so the order of the converted items isn't stable
+		assert.ElementsMatch(t, conf.Emulators, streamed.Emulators)
+		assert.ElementsMatch(t, conf.Contracts, streamed.Contracts)
+		assert.ElementsMatch(t, conf.Networks, streamed.Networks)
+		assert.ElementsMatch(t, conf.Accounts, streamed.Accounts)
+		assert.ElementsMatch(t, conf.Deployments, streamed.Deployments)
+		assert.ElementsMatch(t, conf.Scripts, streamed.Scripts)
+	})
+
+	t.Run("Deserialize Missing and Unknown Sections", func(t *testing.T) {
+		raw := `{
+			"unknown": { "foo": ["bar"] },
+			"networks": { "emulator": "127.0.0.1:3569" }
+		}`
+
+		buffered, err := parser.Deserialize([]byte(raw))
+		require.NoError(t, err)
+
+		streamed, err := parser.DeserializeFrom(strings.NewReader(raw))
+		require.NoError(t, err)
+
+		assert.Equal(t, buffered, streamed)
+	})
+
+	t.Run("Deserialize Old Format", func(t *testing.T) {
+		_, err := parser.DeserializeFrom(strings.NewReader(`{ "host": "127.0.0.1:3569", "accounts": {} }`))
+		assert.ErrorIs(t, err, config.ErrOutdatedFormat)
+	})
+
+	t.Run("Deserialize Syntax Error", func(t *testing.T) {
+		_, err := parser.DeserializeFrom(strings.NewReader(`{ "networks": { "emulator": 1 } }`))
+		assert.ErrorContains(t, err, "configuration syntax error")
+
+		_, err = parser.DeserializeFrom(strings.NewReader(`{ "networks": {}`))
+		assert.ErrorContains(t, err, "configuration syntax error")
+
+		_, err = parser.DeserializeFrom(strings.NewReader(`{} {}`))
+		assert.EqualError(t, err, "configuration syntax error: unexpected data after the configuration")
+	})
+}
@@ -19,10 +19,13 @@
 package config
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+
+	"github.com/onflow/flow-go-sdk"
 )
 
 // ErrDoesNotExist is error to be returned when config file does not exists.
@@ -44,6 +47,14 @@ type Parser interface {
 	SupportsFormat(string) bool
 }
 
+// KeyFileParser is implemented by the parsers supporting account keys loaded from a separate key file.
+type KeyFileParser interface {
+	// DeserializeKeyFile returns the key and the address of the account from the key file, the address is
+	// empty if the key file doesn't contain it.
+	DeserializeKeyFile(accountName string, raw []byte) (flow.Address, AccountKey, error)
+	SerializeKeyFile(address flow.Address, key AccountKey) ([]byte, error)
+}
+
 type ReaderWriter interface {
 	ReadFile(source string) ([]byte, error)
 	WriteFile(filename string, data []byte, perm os.FileMode) error
@@ -68,6 +79,12 @@ type Loader struct {
 	readerWriter     ReaderWriter
 	configParsers    Parsers
 	accountsFromFile map[string]string
+	keysFromFile     map[string]string
+	configPaths      []string
+	root             string
+	migrate          bool
+	persistMigration bool
+	migrations       map[string][]MigrationChange
 }
 
 // NewLoader returns a new loader.
@@ -75,9 +92,25 @@ func NewLoader(readerWriter ReaderWriter) *Loader {
 	return &Loader{
 		readerWriter:     readerWriter,
 		accountsFromFile: map[string]string{},
+		keysFromFile:     map[string]string{},
+		migrations:       map[string][]MigrationChange{},
 	}
 }
 
+// SetMigration enables migrating configurations written in older schema versions when loading.
+//
+// If persist is set the migrated configuration is saved to its path and the original is backed up
+// to the same path with a ".bak" suffix, otherwise the configuration is only migrated in memory.
+func (l *Loader) SetMigration(enabled bool, persist bool) {
+	l.migrate = enabled
+	l.persistMigration = persist
+}
+
+// Migrations returns the changes made to each configuration path migrated while loading.
+func (l *Loader) Migrations() map[string][]MigrationChange {
+	return l.migrations
+}
+
 // AddConfigParser adds a new configuration parser.
 func (l *Loader) AddConfigParser(format Parser) {
 	l.configParsers = append(l.configParsers, format)
@@ -91,6 +124,18 @@ func (l *Loader) SetAccountFromFile(name string, location string) {
 	l.accountsFromFile[name] = location
 }
 
+// RemoveAccount removes the account from the accounts and keys loaded from separate files,
+// so they are not saved to the files anymore. The files are left untouched.
+func (l *Loader) RemoveAccount(name string) {
+	delete(l.accountsFromFile, name)
+	delete(l.keysFromFile, name)
+}
+
+// KeysFromFile returns the key file locations of the loaded accounts with keys loaded from a key file.
+func (l *Loader) KeysFromFile() map[string]string {
+	return l.keysFromFile
+}
+
 // Save saves a configuration to a path with correct serializer.
 func (l *Loader) Save(conf *Config, path string) error {
 	configFormat := l.configParsers.FindForFormat(
@@ -101,6 +146,15 @@ func (l *Loader) Save(conf *Config, path string) error {
 		return fmt.Errorf("parser not found for format")
 	}
 
+	for _, account := range conf.Accounts {
+		if account.Key.Location == "" {
+			continue
+		}
+		if err := l.saveKeyFile(path, account); err != nil {
+			return err
+		}
+	}
+
 	data, err := configFormat.Serialize(conf)
 	if err != nil {
 		return err
@@ -115,19 +169,37 @@ func (l *Loader) Save(conf *Config, path string) error {
 }
 
 func (l *Loader) loadConfig(confPath string) (*Config, error) {
+	l.configPaths = append(l.configPaths, confPath)
 	raw, err := l.loadFile(confPath)
 
 	if err != nil {
 		return nil, err
 	}
 
+	if l.migrate {
+		raw, err = l.migrateFile(confPath, raw)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	preProcessed := l.preprocess(raw)
 	configParser := l.configParsers.FindForFormat(filepath.Ext(confPath))
 	if configParser == nil {
 		return nil, fmt.Errorf("parser not found for config: %s", confPath)
 	}
 
-	return configParser.Deserialize(preProcessed)
+	conf, err := configParser.Deserialize(preProcessed)
+	if err != nil {
+		return nil, err
+	}
+
+	err = l.resolveKeyFiles(conf, confPath, []string{confPath})
+	if err != nil {
+		return nil, err
+	}
+
+	return conf, nil
 }
 
 // Load loads configuration from one or more file paths.
@@ -135,11 +207,15 @@ func (l *Loader) loadConfig(confPath string) (*Config, error) {
 // If more than one path is specified, their contents are merged
 // together into on configuration object.
 func (l *Loader) Load(paths []string) (*Config, error) {
+	l.configPaths = nil
+	l.root = ""
+
 	// special case for default configs
 	// try to load local config and only if not found try to load global config
 	if IsDefaultPath(paths) {
 		conf, err := l.loadConfig(DefaultPath)
 		if err == nil { // if we could load it then process it
+			l.root = filepath.ToSlash(filepath.Dir(DefaultPath))
 			return l.postprocess(conf)
 		}
 		if !errors.Is(err, ErrDoesNotExist) {
@@ -163,6 +239,7 @@ func (l *Loader) Load(paths []string) (*Config, error) {
 		// if first conf just assign as baseConf
 		if baseConf == nil {
 			baseConf = conf
+			l.root = filepath.ToSlash(filepath.Dir(confPath))
 			continue
 		}
 
@@ -177,6 +254,12 @@ func (l *Loader) Load(paths []string) (*Config, error) {
 	return l.postprocess(baseConf)
 }
 
+// Root returns the directory of the base configuration loaded, empty if the global configuration was loaded
+// or no configuration was loaded.
+func (l *Loader) Root() string {
+	return l.root
+}
+
 // preprocess does all manipulations to the raw configuration format happens here.
 func (l *Loader) preprocess(raw []byte) []byte {
 	raw, accountsFromFile := ProcessorRun(raw)
@@ -205,6 +288,11 @@ func (l *Loader) postprocess(baseConf *Config) (*Config, error) {
 			return nil, err
 		}
 
+		err = l.resolveKeyFiles(conf, path, append(append([]string{}, l.configPaths...), path))
+		if err != nil {
+			return nil, err
+		}
+
 		account, err := conf.Accounts.ByName(name)
 		if err != nil {
 			return nil, err
@@ -247,6 +335,150 @@ func (l *Loader) composeConfig(baseConf *Config, conf *Config) {
 	for _, deployment := range conf.Deployments {
 		baseConf.Deployments.AddOrUpdate(deployment)
 	}
+	for _, script := range conf.Scripts {
+		baseConf.Scripts.AddOrUpdate(script.Name, script)
+	}
+}
+
+// migrateFile migrates the raw configuration loaded from the path and persists it if enabled.
+func (l *Loader) migrateFile(path string, raw []byte) ([]byte, error) {
+	migrated, changes, err := Migrate(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to migrate configuration %s: %w", path, err)
+	}
+	if len(changes) == 0 {
+		return raw, nil
+	}
+
+	l.migrations[path] = changes
+
+	if l.persistMigration {
+		err = l.readerWriter.WriteFile(fmt.Sprintf("%s.bak", path), raw, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to back up configuration %s: %w", path, err)
+		}
+
+		err = l.readerWriter.WriteFile(path, migrated, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to save migrated configuration %s: %w", path, err)
+		}
+	}
+
+	return migrated, nil
+}
+
+// resolveKeyFiles loads the keys of the accounts in the configuration loaded from confPath from their key files.
+//
+// The chain contains the files loaded to get to this configuration, a key file can't be any of them.
+func (l *Loader) resolveKeyFiles(conf *Config, confPath string, chain []string) error {
+	for i, account := range conf.Accounts {
+		location := account.Key.Location
+		if location == "" {
+			delete(l.keysFromFile, account.Name) // overridden by an account with the key in the configuration
+			continue
+		}
+
+		keyPath := keyFilePath(confPath, location)
+		for _, loaded := range chain {
+			if filepath.Clean(loaded) == keyPath {
+				return fmt.Errorf(
+					"key file %s of account %s is the configuration file %s, loading it would be a cycle",
+					keyPath,
+					account.Name,
+					loaded,
+				)
+			}
+		}
+
+		keyParser, ok := l.configParsers.FindForFormat(filepath.Ext(keyPath)).(KeyFileParser)
+		if !ok {
+			return fmt.Errorf("key file %s of account %s has an unsupported format", keyPath, account.Name)
+		}
+
+		raw, err := l.loadFile(keyPath)
+		if errors.Is(err, ErrDoesNotExist) {
+			return fmt.Errorf("key file %s of account %s does not exist", keyPath, account.Name)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to load key file %s of account %s: %w", keyPath, account.Name, err)
+		}
+
+		address, key, err := keyParser.DeserializeKeyFile(account.Name, raw)
+		if err != nil {
+			return fmt.Errorf("invalid key file %s of account %s: %w", keyPath, account.Name, err)
+		}
+
+		if account.Address == flow.EmptyAddress {
+			account.Address = address
+		} else if address != flow.EmptyAddress && address != account.Address {
+			return fmt.Errorf(
+				"key file %s of account %s contains address 0x%s, but the account has address 0x%s",
+				keyPath,
+				account.Name,
+				address,
+				account.Address,
+			)
+		}
+		if account.Address == flow.EmptyAddress {
+			return fmt.Errorf("missing address of account %s, neither the account nor the key file %s contain it", account.Name, keyPath)
+		}
+
+		key.Location = location
+		account.Key = key
+		conf.Accounts[i] = account
+		l.keysFromFile[account.Name] = location
+	}
+
+	return nil
+}
+
+// saveKeyFile saves the key of the account to its key file, relative to the configuration saved to confPath.
+//
+// The key file is only written if its key or address changed, and only readable by the owner as it holds a private key.
+func (l *Loader) saveKeyFile(confPath string, account Account) error {
+	keyPath := keyFilePath(confPath, account.Key.Location)
+
+	keyParser, ok := l.configParsers.FindForFormat(filepath.Ext(keyPath)).(KeyFileParser)
+	if !ok {
+		return fmt.Errorf("key file %s of account %s has an unsupported format", keyPath, account.Name)
+	}
+
+	key := account.Key
+	key.Location = ""
+	address := account.Address
+
+	var current []byte
+	if raw, err := l.loadFile(keyPath); err == nil {
+		if fileAddress, fileKey, err := keyParser.DeserializeKeyFile(account.Name, raw); err == nil {
+			if fileAddress == flow.EmptyAddress {
+				address = flow.EmptyAddress // the address is in the configuration, not in the key file
+			}
+			current, _ = keyParser.SerializeKeyFile(fileAddress, fileKey)
+		}
+	}
+
+	data, err := keyParser.SerializeKeyFile(address, key)
+	if err != nil {
+		return err
+	}
+	if current != nil && bytes.Equal(current, data) {
+		return nil
+	}
+
+	err = l.readerWriter.WriteFile(keyPath, data, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to save key file %s of account %s: %w", keyPath, account.Name, err)
+	}
+
+	return nil
+}
+
+// keyFilePath returns the path of the key file at the location relative to the configuration file.
+func keyFilePath(confPath string, location string) string {
+	if filepath.IsAbs(location) {
+		return filepath.Clean(location)
+	}
+	return filepath.Join(filepath.Dir(confPath), location)
 }
 
 // loadFile simple file loader.
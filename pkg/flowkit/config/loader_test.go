@@ -21,8 +21,10 @@ import (
 	"os"
 	"testing"
 
+	"github.com/onflow/flow-go-sdk/crypto"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/onflow/flow-cli/pkg/flowkit/config"
 	"github.com/onflow/flow-cli/pkg/flowkit/config/json"
@@ -461,3 +463,197 @@ func Test_JSONEnv(t *testing.T) {
 	assert.Equal(t, 1, len(conf.Accounts))
 	assert.Equal(t, "0x21c5dfdeb0ff03a7a73ef39788563b62c89adea67bbb21ab95e5f710bd1d40b7", conf.Accounts[0].Key.PrivateKey.String())
 }
+
+func Test_KeyFromFile(t *testing.T) {
+	b := []byte(`{
+		"accounts": {
+			"testnet-account": {
+				"key": { "fromFile": "keys/testnet.json" }
+			},
+			"mainnet-account": {
+				"address": "f8d6e0586b0a20c7",
+				"key": { "fromFile": "keys/mainnet.json" },
+				"tags": ["admin"]
+			}
+		}
+	}`)
+
+	testnetKey := []byte(`{
+		"address": "f1d6e0586b0a20c7",
+		"key": "3335dfdeb0ff03a7a73ef39788563b62c89adea67bbb21ab95e5f710bd1d40b7"
+	}`)
+
+	mainnetKey := []byte(`{
+		"key": {
+			"type": "hex",
+			"index": 1,
+			"signatureAlgorithm": "ECDSA_secp256k1",
+			"hashAlgorithm": "SHA2_256",
+			"privateKey": "21c5dfdeb0ff03a7a73ef39788563b62c89adea67bbb21ab95e5f710bd1d40b7"
+		}
+	}`)
+
+	newFS := func(t *testing.T, files map[string][]byte) afero.Afero {
+		af := afero.Afero{Fs: afero.NewMemMapFs()}
+		for name, content := range files {
+			require.NoError(t, af.WriteFile(name, content, 0644))
+		}
+		return af
+	}
+
+	newLoader := func(af afero.Afero) *config.Loader {
+		composer := config.NewLoader(af)
+		composer.AddConfigParser(json.NewParser())
+		return composer
+	}
+
+	t.Run("Load", func(t *testing.T) {
+		af := newFS(t, map[string][]byte{
+			"project/flow.json":         b,
+			"project/keys/testnet.json": testnetKey,
+			"project/keys/mainnet.json": mainnetKey,
+		})
+		composer := newLoader(af)
+
+		conf, err := composer.Load([]string{"project/flow.json"})
+		require.NoError(t, err)
+
+		testnet, err := conf.Accounts.ByName("testnet-account")
+		require.NoError(t, err)
+		assert.Equal(t, "f1d6e0586b0a20c7", testnet.Address.String())
+		assert.Equal(t, config.KeyTypeHex, testnet.Key.Type)
+		assert.Equal(t, "0x3335dfdeb0ff03a7a73ef39788563b62c89adea67bbb21ab95e5f710bd1d40b7", testnet.Key.PrivateKey.String())
+		assert.Equal(t, "keys/testnet.json", testnet.Key.Location)
+
+		mainnet, err := conf.Accounts.ByName("mainnet-account")
+		require.NoError(t, err)
+		assert.Equal(t, "f8d6e0586b0a20c7", mainnet.Address.String())
+		assert.Equal(t, 1, mainnet.Key.Index)
+		assert.Equal(t, crypto.ECDSA_secp256k1, mainnet.Key.SigAlgo)
+		assert.Equal(t, crypto.SHA2_256, mainnet.Key.HashAlgo)
+		assert.Equal(t, []string{"admin"}, mainnet.Tags)
+
+		assert.Equal(t, map[string]string{
+			"testnet-account": "keys/testnet.json",
+			"mainnet-account": "keys/mainnet.json",
+		}, composer.KeysFromFile())
+	})
+
+	t.Run("Save", func(t *testing.T) {
+		af := newFS(t, map[string][]byte{
+			"project/flow.json":         b,
+			"project/keys/testnet.json": testnetKey,
+			"project/keys/mainnet.json": mainnetKey,
+		})
+		composer := newLoader(af)
+
+		conf, err := composer.Load([]string{"project/flow.json"})
+		require.NoError(t, err)
+
+		newKey, err := crypto.DecodePrivateKeyHex(crypto.ECDSA_P256, "dd72967fd2bd75234ae9037dd4694c1f00baad63a10c35172bf65fbb8ad74b47")
+		require.NoError(t, err)
+		testnet, _ := conf.Accounts.ByName("testnet-account")
+		testnet.Key.PrivateKey = newKey
+		conf.Accounts.AddOrUpdate(testnet.Name, *testnet)
+
+		require.NoError(t, composer.Save(conf, "project/flow.json"))
+
+		saved, err := af.ReadFile("project/flow.json")
+		require.NoError(t, err)
+		assert.NotContains(t, string(saved), "dd72967fd2bd75234ae9037dd4694c1f00baad63a10c35172bf65fbb8ad74b47")
+		assert.NotContains(t, string(saved), "21c5dfdeb0ff03a7a73ef39788563b62c89adea67bbb21ab95e5f710bd1d40b7")
+		assert.Contains(t, string(saved), `"fromFile": "keys/testnet.json"`)
+
+		savedKey, err := af.ReadFile("project/keys/testnet.json")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"address": "f1d6e0586b0a20c7",
+			"key": "dd72967fd2bd75234ae9037dd4694c1f00baad63a10c35172bf65fbb8ad74b47"
+		}`, string(savedKey))
+
+		// the unchanged key file is left as the user wrote it, without the address of the configuration
+		unchanged, err := af.ReadFile("project/keys/mainnet.json")
+		require.NoError(t, err)
+		assert.Equal(t, mainnetKey, unchanged)
+
+		reloaded, err := newLoader(af).Load([]string{"project/flow.json"})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, conf.Accounts, reloaded.Accounts)
+	})
+
+	t.Run("Save New File", func(t *testing.T) {
+		af := newFS(t, map[string][]byte{
+			"project/flow.json":         b,
+			"project/keys/testnet.json": testnetKey,
+			"project/keys/mainnet.json": mainnetKey,
+		})
+		composer := newLoader(af)
+
+		conf, err := composer.Load([]string{"project/flow.json"})
+		require.NoError(t, err)
+
+		testnet, _ := conf.Accounts.ByName("testnet-account")
+		testnet.Key.Location = "keys/new.json"
+		conf.Accounts.AddOrUpdate(testnet.Name, *testnet)
+
+		require.NoError(t, composer.Save(conf, "project/flow.json"))
+
+		info, err := af.Stat("project/keys/new.json")
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+
+	t.Run("Missing File", func(t *testing.T) {
+		af := newFS(t, map[string][]byte{
+			"project/flow.json":         b,
+			"project/keys/testnet.json": testnetKey,
+		})
+
+		_, err := newLoader(af).Load([]string{"project/flow.json"})
+		assert.EqualError(t, err, "key file project/keys/mainnet.json of account mainnet-account does not exist")
+	})
+
+	t.Run("Invalid File", func(t *testing.T) {
+		af := newFS(t, map[string][]byte{
+			"project/flow.json":         b,
+			"project/keys/testnet.json": []byte(`{ "address": "f1d6e0586b0a20c7", "key": "invalid" }`),
+			"project/keys/mainnet.json": mainnetKey,
+		})
+
+		_, err := newLoader(af).Load([]string{"project/flow.json"})
+		assert.EqualError(t, err, "invalid key file project/keys/testnet.json of account testnet-account: invalid private key for account: testnet-account")
+	})
+
+	t.Run("Nested File", func(t *testing.T) {
+		af := newFS(t, map[string][]byte{
+			"project/flow.json":         b,
+			"project/keys/testnet.json": []byte(`{ "key": { "fromFile": "other.json" } }`),
+			"project/keys/mainnet.json": mainnetKey,
+		})
+
+		_, err := newLoader(af).Load([]string{"project/flow.json"})
+		assert.ErrorIs(t, err, config.ErrNestedKeyFile)
+		assert.EqualError(t, err, "invalid key file project/keys/testnet.json of account testnet-account: key files can't load the key from another file")
+	})
+
+	t.Run("Cycle", func(t *testing.T) {
+		af := newFS(t, map[string][]byte{
+			"project/flow.json": []byte(`{
+				"accounts": {
+					"testnet-account": { "fromFile": "project/private.json" }
+				}
+			}`),
+			"project/private.json": []byte(`{
+				"accounts": {
+					"testnet-account": {
+						"address": "f1d6e0586b0a20c7",
+						"key": { "fromFile": "flow.json" }
+					}
+				}
+			}`),
+		})
+
+		_, err := newLoader(af).Load([]string{"project/flow.json"})
+		assert.EqualError(t, err, "key file project/flow.json of account testnet-account is the configuration file project/flow.json, loading it would be a cycle")
+	})
+}
@@ -0,0 +1,560 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// SchemaVersion is the vintage of the configuration schema a raw configuration is written in.
+type SchemaVersion int
+
+const (
+	// SchemaCurrent is the schema supported by the current parser.
+	SchemaCurrent SchemaVersion = iota
+	// SchemaPre022 is the schema used before v0.22, with "keys" on accounts and "chain" fields.
+	SchemaPre022
+	// SchemaLegacy is the first schema, with a top level "host" and inline account private keys.
+	SchemaLegacy
+)
+
+func (s SchemaVersion) String() string {
+	switch s {
+	case SchemaLegacy:
+		return "legacy"
+	case SchemaPre022:
+		return "pre-v0.22"
+	default:
+		return "current"
+	}
+}
+
+// MigrationChange describes a single change made while migrating the configuration.
+type MigrationChange struct {
+	// Path is the dot separated location of the changed field.
+	Path        string
+	Description string
+}
+
+func (m MigrationChange) String() string {
+	return fmt.Sprintf("%s: %s", m.Path, m.Description)
+}
+
+// Migrate migrates the raw JSON configuration from older schema versions to the current schema.
+//
+// Every change made is reported, fields that are not recognized are preserved and the order
+// of the fields is kept. If the configuration is already in the current schema it's returned unchanged.
+func Migrate(raw []byte) ([]byte, []MigrationChange, error) {
+	root, err := parseObject(raw)
+	if err != nil {
+		return nil, nil, fmt.Errorf("configuration syntax error: %w", err)
+	}
+
+	m := &migration{}
+	if err := m.migrate(root); err != nil {
+		return nil, nil, err
+	}
+
+	if len(m.changes) == 0 {
+		return raw, nil, nil
+	}
+
+	migrated, err := json.MarshalIndent(root, "", "\t")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return migrated, m.changes, nil
+}
+
+// DetectSchema detects the schema version the raw configuration is written in.
+func DetectSchema(raw []byte) (SchemaVersion, error) {
+	root, err := parseObject(raw)
+	if err != nil {
+		return SchemaCurrent, fmt.Errorf("configuration syntax error: %w", err)
+	}
+
+	return detectSchema(root), nil
+}
+
+func detectSchema(root *object) SchemaVersion {
+	if root.has("host") {
+		return SchemaLegacy
+	}
+
+	for _, section := range []string{"accounts", "networks", "emulators"} {
+		entries, _ := root.object(section)
+		for _, name := range entries.fields() {
+			entry, _ := entries.object(name)
+			if entry.has("keys") || entry.has("chain") {
+				return SchemaPre022
+			}
+		}
+	}
+
+	contracts, _ := root.object("contracts")
+	for _, name := range contracts.fields() {
+		contract, _ := contracts.object(name)
+		if contract != nil && !contract.has("source") {
+			return SchemaPre022
+		}
+	}
+
+	return SchemaCurrent
+}
+
+type migration struct {
+	changes []MigrationChange
+}
+
+func (m *migration) change(path string, format string, args ...interface{}) {
+	m.changes = append(m.changes, MigrationChange{
+		Path:        path,
+		Description: fmt.Sprintf(format, args...),
+	})
+}
+
+func (m *migration) migrate(root *object) error {
+	legacy := detectSchema(root) == SchemaLegacy
+	if legacy {
+		if err := m.migrateLegacyHost(root); err != nil {
+			return err
+		}
+	}
+
+	if err := m.migrateAccounts(root, legacy); err != nil {
+		return err
+	}
+	if err := m.migrateNetworks(root); err != nil {
+		return err
+	}
+	if err := m.migrateEmulators(root); err != nil {
+		return err
+	}
+
+	return m.migrateContracts(root)
+}
+
+// migrateLegacyHost moves the top level host to the emulator network.
+func (m *migration) migrateLegacyHost(root *object) error {
+	var host string
+	if err := root.decode("host", &host); err != nil {
+		return fmt.Errorf("invalid host in legacy configuration: %w", err)
+	}
+
+	networks, err := root.object("networks")
+	if err != nil {
+		return err
+	}
+	if networks == nil {
+		networks = newObject()
+	}
+
+	root.remove("host")
+	if !networks.has(DefaultEmulatorNetwork().Name) {
+		networks.set(DefaultEmulatorNetwork().Name, host)
+		root.set("networks", networks)
+		m.change("host", "moved to networks.%s", DefaultEmulatorNetwork().Name)
+	} else {
+		m.change("host", "removed, networks.%s is already defined", DefaultEmulatorNetwork().Name)
+	}
+
+	return nil
+}
+
+func (m *migration) migrateAccounts(root *object, legacy bool) error {
+	accounts, err := root.object("accounts")
+	if err != nil || accounts == nil {
+		return err
+	}
+
+	for _, name := range accounts.fields() {
+		account, err := accounts.object(name)
+		if err != nil {
+			return fmt.Errorf("invalid account %s: %w", name, err)
+		}
+		if account == nil {
+			continue
+		}
+		path := fmt.Sprintf("accounts.%s", name)
+
+		if err := m.migrateAccountKeys(account, path); err != nil {
+			return err
+		}
+
+		if account.has("chain") {
+			account.remove("chain")
+			m.change(path+".chain", "removed deprecated field, the network is derived from the address")
+		}
+
+		accounts.set(name, account)
+
+		// legacy service account is named emulator account in the current schema
+		if legacy && name == "service" && !accounts.has(DefaultEmulatorServiceAccountName) {
+			accounts.rename(name, DefaultEmulatorServiceAccountName)
+			m.change(path, "renamed to accounts.%s", DefaultEmulatorServiceAccountName)
+		}
+	}
+
+	root.set("accounts", accounts)
+	return nil
+}
+
+func (m *migration) migrateAccountKeys(account *object, path string) error {
+	// legacy inline private key
+	if account.has("privateKey") {
+		var privateKey, sigAlgo, hashAlgo string
+		_ = account.decode("privateKey", &privateKey)
+		_ = account.decode("sigAlgorithm", &sigAlgo)
+		_ = account.decode("hashAlgorithm", &hashAlgo)
+
+		key := newObject()
+		key.set("type", KeyTypeHex)
+		key.set("index", 0)
+		key.set("signatureAlgorithm", defaultString(sigAlgo, "ECDSA_P256"))
+		key.set("hashAlgorithm", defaultString(hashAlgo, "SHA3_256"))
+		key.set("privateKey", strings.TrimPrefix(privateKey, "0x"))
+
+		account.replace("privateKey", "key", key)
+		account.remove("sigAlgorithm")
+		account.remove("hashAlgorithm")
+		m.change(path+".privateKey", "moved to %s.key with hex key type", path)
+		return nil
+	}
+
+	if !account.has("keys") {
+		return nil
+	}
+
+	var simple string
+	if err := account.decode("keys", &simple); err == nil {
+		account.replace("keys", "key", strings.TrimPrefix(simple, "0x"))
+		m.change(path+".keys", "renamed to key")
+		return nil
+	}
+
+	var keys []*object
+	if err := account.decode("keys", &keys); err != nil {
+		return fmt.Errorf("invalid keys for account at %s: %w", path, err)
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("no keys for account at %s", path)
+	}
+
+	key := keys[0]
+	if ctx, _ := key.object("context"); ctx != nil {
+		var privateKey string
+		if err := ctx.decode("privateKey", &privateKey); err == nil {
+			key.replace("context", "privateKey", strings.TrimPrefix(privateKey, "0x"))
+			m.change(path+".keys.context", "moved privateKey to %s.key.privateKey", path)
+		}
+	}
+
+	account.replace("keys", "key", key)
+	m.change(path+".keys", "renamed to key using the first key")
+	if len(keys) > 1 {
+		m.change(path+".keys", "dropped %d additional keys, only a single key is supported", len(keys)-1)
+	}
+
+	return nil
+}
+
+func (m *migration) migrateNetworks(root *object) error {
+	networks, err := root.object("networks")
+	if err != nil || networks == nil {
+		return err
+	}
+
+	for _, name := range networks.fields() {
+		network, err := networks.object(name)
+		if err != nil {
+			return fmt.Errorf("invalid network %s: %w", name, err)
+		}
+		if network == nil || !network.has("chain") {
+			continue
+		}
+		path := fmt.Sprintf("networks.%s", name)
+
+		network.remove("chain")
+		m.change(path+".chain", "removed deprecated field")
+
+		if fields := network.fields(); len(fields) == 1 && fields[0] == "host" {
+			var host string
+			if err := network.decode("host", &host); err == nil {
+				networks.set(name, host)
+				m.change(path, "converted to host string")
+				continue
+			}
+		}
+
+		networks.set(name, network)
+	}
+
+	root.set("networks", networks)
+	return nil
+}
+
+func (m *migration) migrateEmulators(root *object) error {
+	emulators, err := root.object("emulators")
+	if err != nil || emulators == nil {
+		return err
+	}
+
+	for _, name := range emulators.fields() {
+		emulator, err := emulators.object(name)
+		if err != nil {
+			return fmt.Errorf("invalid emulator %s: %w", name, err)
+		}
+		if emulator == nil {
+			continue
+		}
+		path := fmt.Sprintf("emulators.%s", name)
+
+		if emulator.has("chain") {
+			emulator.remove("chain")
+			m.change(path+".chain", "removed deprecated field, the emulator always uses the emulator chain")
+		}
+
+		emulators.set(name, emulator)
+	}
+
+	root.set("emulators", emulators)
+	return nil
+}
+
+// migrateContracts migrates contracts defined per network, where each network maps to either
+// the source location or an alias address, to the source and aliases layout.
+func (m *migration) migrateContracts(root *object) error {
+	contracts, err := root.object("contracts")
+	if err != nil || contracts == nil {
+		return err
+	}
+
+	for _, name := range contracts.fields() {
+		contract, err := contracts.object(name)
+		if err != nil {
+			return fmt.Errorf("invalid contract %s: %w", name, err)
+		}
+		if contract == nil || contract.has("source") {
+			continue
+		}
+		path := fmt.Sprintf("contracts.%s", name)
+
+		source := ""
+		aliases := newObject()
+		for _, network := range contract.fields() {
+			var value string
+			if err := contract.decode(network, &value); err != nil {
+				return fmt.Errorf("invalid value for network %s on contract at %s", network, path)
+			}
+
+			if _, err := StringToAddress(value); err == nil && value != "" {
+				aliases.set(network, value)
+				continue
+			}
+
+			if source != "" && source != value {
+				return fmt.Errorf("contract at %s has different sources for networks, which is not supported", path)
+			}
+			source = value
+		}
+
+		if source == "" {
+			return fmt.Errorf("contract at %s is missing a source", path)
+		}
+
+		if len(aliases.fields()) == 0 {
+			contracts.set(name, source)
+			m.change(path, "converted network sources to a single source")
+			continue
+		}
+
+		migrated := newObject()
+		migrated.set("source", source)
+		migrated.set("aliases", aliases)
+		contracts.set(name, migrated)
+		m.change(path, "converted network sources and addresses to source and aliases")
+	}
+
+	root.set("contracts", contracts)
+	return nil
+}
+
+func defaultString(value string, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
+
+// object is a JSON object which keeps the order of its fields.
+type object struct {
+	keys   []string
+	values map[string]json.RawMessage
+}
+
+func newObject() *object {
+	return &object{values: make(map[string]json.RawMessage)}
+}
+
+// parseObject parses the raw JSON object, returning an error if it's not an object.
+func parseObject(raw []byte) (*object, error) {
+	o := newObject()
+	if err := json.Unmarshal(raw, o); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func (o *object) UnmarshalJSON(raw []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("expected a JSON object")
+	}
+
+	o.keys = nil
+	o.values = make(map[string]json.RawMessage)
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key := token.(string)
+
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return err
+		}
+
+		if _, exists := o.values[key]; !exists {
+			o.keys = append(o.keys, key)
+		}
+		o.values[key] = value
+	}
+
+	_, err = dec.Token()
+	return err
+}
+
+func (o *object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(o.values[key])
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// fields returns the field names in order, it's safe to call on a nil object.
+func (o *object) fields() []string {
+	if o == nil {
+		return nil
+	}
+	return append([]string(nil), o.keys...)
+}
+
+func (o *object) has(key string) bool {
+	if o == nil {
+		return false
+	}
+	_, ok := o.values[key]
+	return ok
+}
+
+func (o *object) decode(key string, value interface{}) error {
+	raw, ok := o.values[key]
+	if !ok {
+		return fmt.Errorf("missing field %s", key)
+	}
+	return json.Unmarshal(raw, value)
+}
+
+// object returns the field as an object, or nil if it doesn't exist or isn't an object.
+func (o *object) object(key string) (*object, error) {
+	if !o.has(key) {
+		return nil, nil
+	}
+
+	raw := bytes.TrimSpace(o.values[key])
+	if len(raw) == 0 || raw[0] != '{' {
+		return nil, nil
+	}
+
+	return parseObject(raw)
+}
+
+// set sets the field value, keeping the position of an existing field.
+func (o *object) set(key string, value interface{}) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		panic(err) // values are always built from JSON compatible types
+	}
+
+	if _, exists := o.values[key]; !exists {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = raw
+}
+
+// replace replaces the field with a new field at the same position.
+func (o *object) replace(key string, newKey string, value interface{}) {
+	o.rename(key, newKey)
+	o.set(newKey, value)
+}
+
+func (o *object) rename(key string, newKey string) {
+	for i, k := range o.keys {
+		if k == key {
+			o.keys[i] = newKey
+		}
+	}
+	o.values[newKey] = o.values[key]
+	delete(o.values, key)
+}
+
+func (o *object) remove(key string) {
+	if !o.has(key) {
+		return
+	}
+	delete(o.values, key)
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i], o.keys[i+1:]...)
+			return
+		}
+	}
+}
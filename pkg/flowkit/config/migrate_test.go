@@ -0,0 +1,324 @@
+/*
+* Flow CLI
+*
+* Copyright 2019 Dapper Labs, Inc.
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*   http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+
+package config_test
+
+import (
+	"testing"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-cli/pkg/flowkit/config"
+	"github.com/onflow/flow-cli/pkg/flowkit/config/json"
+)
+
+const migrationKey = "21c5dfdeb0ff03a7a73ef39788563b62c89adea67bbb21ab95e5f710bd1d40b7"
+
+// legacy configuration used by the first CLI versions
+var legacyConfig = []byte(`{
+	"host": "127.0.0.1:3569",
+	"accounts": {
+		"service": {
+			"address": "f8d6e0586b0a20c7",
+			"privateKey": "0x21c5dfdeb0ff03a7a73ef39788563b62c89adea67bbb21ab95e5f710bd1d40b7",
+			"sigAlgorithm": "ECDSA_P256",
+			"hashAlgorithm": "SHA3_256"
+		}
+	}
+}`)
+
+// configuration with simple keys used before v0.22
+var pre022SimpleConfig = []byte(`{
+	"emulators": {
+		"default": {
+			"port": 3569,
+			"serviceAccount": "emulator-account",
+			"chain": "flow-emulator"
+		}
+	},
+	"contracts": {},
+	"networks": {
+		"emulator": {
+			"host": "127.0.0.1:3569",
+			"chain": "flow-emulator"
+		}
+	},
+	"accounts": {
+		"emulator-account": {
+			"address": "service",
+			"keys": "21c5dfdeb0ff03a7a73ef39788563b62c89adea67bbb21ab95e5f710bd1d40b7",
+			"chain": "flow-emulator"
+		}
+	},
+	"deployments": {}
+}`)
+
+// configuration with advanced keys and per network contracts used before v0.22
+var pre022AdvancedConfig = []byte(`{
+	"emulators": {
+		"default": {
+			"port": 3569,
+			"serviceAccount": "emulator-account"
+		}
+	},
+	"contracts": {
+		"Kibble": "./cadence/contracts/Kibble.cdc",
+		"FungibleToken": {
+			"emulator": "./cadence/contracts/FungibleToken.cdc",
+			"testnet": "0x9a0766d93b6608b7"
+		}
+	},
+	"networks": {
+		"emulator": {
+			"host": "127.0.0.1:3569",
+			"chain": "flow-emulator"
+		},
+		"testnet": {
+			"host": "access.devnet.nodes.onflow.org:9000",
+			"chain": "flow-testnet"
+		}
+	},
+	"accounts": {
+		"emulator-account": {
+			"address": "f8d6e0586b0a20c7",
+			"keys": [{
+				"type": "hex",
+				"index": 0,
+				"signatureAlgorithm": "ECDSA_P256",
+				"hashAlgorithm": "SHA3_256",
+				"context": {
+					"privateKey": "21c5dfdeb0ff03a7a73ef39788563b62c89adea67bbb21ab95e5f710bd1d40b7"
+				}
+			}],
+			"chain": "flow-emulator"
+		}
+	},
+	"deployments": {
+		"emulator": {
+			"emulator-account": ["Kibble", "FungibleToken"]
+		}
+	},
+	"custom": {
+		"keep": true
+	}
+}`)
+
+func Test_Migrate(t *testing.T) {
+	t.Run("Legacy", func(t *testing.T) {
+		schema, err := config.DetectSchema(legacyConfig)
+		require.NoError(t, err)
+		assert.Equal(t, config.SchemaLegacy, schema)
+
+		migrated, changes, err := config.Migrate(legacyConfig)
+		require.NoError(t, err)
+
+		assert.Equal(t, `{
+	"accounts": {
+		"emulator-account": {
+			"address": "f8d6e0586b0a20c7",
+			"key": {
+				"type": "hex",
+				"index": 0,
+				"signatureAlgorithm": "ECDSA_P256",
+				"hashAlgorithm": "SHA3_256",
+				"privateKey": "21c5dfdeb0ff03a7a73ef39788563b62c89adea67bbb21ab95e5f710bd1d40b7"
+			}
+		}
+	},
+	"networks": {
+		"emulator": "127.0.0.1:3569"
+	}
+}`, string(migrated))
+
+		assert.Equal(t, []config.MigrationChange{
+			{Path: "host", Description: "moved to networks.emulator"},
+			{Path: "accounts.service.privateKey", Description: "moved to accounts.service.key with hex key type"},
+			{Path: "accounts.service", Description: "renamed to accounts.emulator-account"},
+		}, changes)
+	})
+
+	t.Run("Pre v0.22 simple", func(t *testing.T) {
+		schema, err := config.DetectSchema(pre022SimpleConfig)
+		require.NoError(t, err)
+		assert.Equal(t, config.SchemaPre022, schema)
+
+		migrated, changes, err := config.Migrate(pre022SimpleConfig)
+		require.NoError(t, err)
+
+		assert.Equal(t, `{
+	"emulators": {
+		"default": {
+			"port": 3569,
+			"serviceAccount": "emulator-account"
+		}
+	},
+	"contracts": {},
+	"networks": {
+		"emulator": "127.0.0.1:3569"
+	},
+	"accounts": {
+		"emulator-account": {
+			"address": "service",
+			"key": "21c5dfdeb0ff03a7a73ef39788563b62c89adea67bbb21ab95e5f710bd1d40b7"
+		}
+	},
+	"deployments": {}
+}`, string(migrated))
+
+		assert.Equal(t, []config.MigrationChange{
+			{Path: "accounts.emulator-account.keys", Description: "renamed to key"},
+			{Path: "accounts.emulator-account.chain", Description: "removed deprecated field, the network is derived from the address"},
+			{Path: "networks.emulator.chain", Description: "removed deprecated field"},
+			{Path: "networks.emulator", Description: "converted to host string"},
+			{Path: "emulators.default.chain", Description: "removed deprecated field, the emulator always uses the emulator chain"},
+		}, changes)
+	})
+
+	t.Run("Pre v0.22 advanced", func(t *testing.T) {
+		migrated, changes, err := config.Migrate(pre022AdvancedConfig)
+		require.NoError(t, err)
+
+		assert.Equal(t, `{
+	"emulators": {
+		"default": {
+			"port": 3569,
+			"serviceAccount": "emulator-account"
+		}
+	},
+	"contracts": {
+		"Kibble": "./cadence/contracts/Kibble.cdc",
+		"FungibleToken": {
+			"source": "./cadence/contracts/FungibleToken.cdc",
+			"aliases": {
+				"testnet": "0x9a0766d93b6608b7"
+			}
+		}
+	},
+	"networks": {
+		"emulator": "127.0.0.1:3569",
+		"testnet": "access.devnet.nodes.onflow.org:9000"
+	},
+	"accounts": {
+		"emulator-account": {
+			"address": "f8d6e0586b0a20c7",
+			"key": {
+				"type": "hex",
+				"index": 0,
+				"signatureAlgorithm": "ECDSA_P256",
+				"hashAlgorithm": "SHA3_256",
+				"privateKey": "21c5dfdeb0ff03a7a73ef39788563b62c89adea67bbb21ab95e5f710bd1d40b7"
+			}
+		}
+	},
+	"deployments": {
+		"emulator": {
+			"emulator-account": [
+				"Kibble",
+				"FungibleToken"
+			]
+		}
+	},
+	"custom": {
+		"keep": true
+	}
+}`, string(migrated))
+
+		assert.Equal(t, []config.MigrationChange{
+			{Path: "accounts.emulator-account.keys.context", Description: "moved privateKey to accounts.emulator-account.key.privateKey"},
+			{Path: "accounts.emulator-account.keys", Description: "renamed to key using the first key"},
+			{Path: "accounts.emulator-account.chain", Description: "removed deprecated field, the network is derived from the address"},
+			{Path: "networks.emulator.chain", Description: "removed deprecated field"},
+			{Path: "networks.emulator", Description: "converted to host string"},
+			{Path: "networks.testnet.chain", Description: "removed deprecated field"},
+			{Path: "networks.testnet", Description: "converted to host string"},
+			{Path: "contracts.FungibleToken", Description: "converted network sources and addresses to source and aliases"},
+		}, changes)
+	})
+
+	t.Run("Current schema unchanged", func(t *testing.T) {
+		current := []byte(`{
+			"networks": { "emulator": "127.0.0.1:3569" },
+			"accounts": {
+				"emulator-account": {
+					"address": "f8d6e0586b0a20c7",
+					"key": "21c5dfdeb0ff03a7a73ef39788563b62c89adea67bbb21ab95e5f710bd1d40b7"
+				}
+			}
+		}`)
+
+		schema, err := config.DetectSchema(current)
+		require.NoError(t, err)
+		assert.Equal(t, config.SchemaCurrent, schema)
+
+		migrated, changes, err := config.Migrate(current)
+		require.NoError(t, err)
+		assert.Equal(t, current, migrated)
+		assert.Len(t, changes, 0)
+	})
+
+	t.Run("Different contract sources", func(t *testing.T) {
+		_, _, err := config.Migrate([]byte(`{
+			"contracts": {
+				"Foo": { "emulator": "./Foo.cdc", "testnet": "./FooTestnet.cdc" }
+			}
+		}`))
+		assert.EqualError(t, err, "contract at contracts.Foo has different sources for networks, which is not supported")
+	})
+}
+
+func Test_LoaderMigration(t *testing.T) {
+	for _, persist := range []bool{false, true} {
+		fs := afero.Afero{Fs: afero.NewMemMapFs()}
+		require.NoError(t, fs.WriteFile("flow.json", pre022AdvancedConfig, 0644))
+
+		loader := config.NewLoader(fs)
+		loader.AddConfigParser(json.NewParser())
+
+		_, err := loader.Load([]string{"flow.json"})
+		assert.Error(t, err) // fails without migration
+
+		loader.SetMigration(true, persist)
+		conf, err := loader.Load([]string{"flow.json"})
+		require.NoError(t, err)
+
+		account, err := conf.Accounts.ByName("emulator-account")
+		require.NoError(t, err)
+		assert.Equal(t, flow.HexToAddress("f8d6e0586b0a20c7"), account.Address)
+		assert.Equal(t, "0x"+migrationKey, account.Key.PrivateKey.String())
+
+		contract, err := conf.Contracts.ByNameAndNetwork("FungibleToken", "testnet")
+		require.NoError(t, err)
+		assert.Equal(t, "9a0766d93b6608b7", flow.HexToAddress(contract.Alias).String())
+		assert.Len(t, loader.Migrations()["flow.json"], 8)
+
+		saved, err := fs.ReadFile("flow.json")
+		require.NoError(t, err)
+		backup, backupErr := fs.ReadFile("flow.json.bak")
+		if persist {
+			require.NoError(t, backupErr)
+			assert.Equal(t, pre022AdvancedConfig, backup)
+			assert.NotEqual(t, pre022AdvancedConfig, saved)
+		} else {
+			assert.Error(t, backupErr)
+			assert.Equal(t, pre022AdvancedConfig, saved)
+		}
+	}
+}
@@ -29,6 +29,18 @@ type Network struct {
 	Name string
 	Host string
 	Key  string
+	// DefaultSigner is the name of the account signing for service calls that don't provide an account.
+	DefaultSigner string
+	// ContractRegistry is the address of the contract registry the deployment metadata of added contracts is registered in.
+	ContractRegistry string
+	// StrictImports requires every import resolved to an address to match the import pins in the lock file.
+	StrictImports bool
+	// ComputeLimit caps the compute limit of the transactions sent to the network, zero doesn't cap it.
+	ComputeLimit uint64
+	// FindRegistry is the address of the .find name registry, overriding the built-in address of the network.
+	FindRegistry string
+	// FlownsRegistry is the address of the flowns name registry, overriding the built-in address of the network.
+	FlownsRegistry string
 }
 
 // ByName get network by name.
@@ -41,7 +41,7 @@ func StringToAccount(
 		return nil, err
 	}
 
-	parsedKey, err := StringToHexKey(key, sigAlgo)
+	parsedKey, err := DecodeAccountPrivateKey(name, crypto.StringToSignatureAlgorithm(sigAlgo), key)
 	if err != nil {
 		return nil, err
 	}
@@ -19,9 +19,11 @@
 package config
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
+	"github.com/onflow/flow-go-sdk/crypto"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -41,3 +43,42 @@ func Test_ParseAddress(t *testing.T) {
 	}
 
 }
+
+func Test_DecodeAccountPrivateKey(t *testing.T) {
+	// valid key on both curves
+	const sharedKey = "dd72967fd2bd75234ae9037dd4694c1f00baad63a10c35172bf65fbb8ad74b47"
+	// larger than the P-256 group order but smaller than the secp256k1 group order
+	const secp256k1Key = "ffffffff00000000ffffffffffffffffbce6faada7179e84f3b9cac2fc632552"
+
+	t.Run("Matching Algorithm", func(t *testing.T) {
+		for _, sigAlgo := range []crypto.SignatureAlgorithm{crypto.ECDSA_P256, crypto.ECDSA_secp256k1} {
+			key, err := DecodeAccountPrivateKey("admin", sigAlgo, sharedKey)
+			require.NoError(t, err)
+			assert.Equal(t, sigAlgo, key.Algorithm())
+		}
+
+		key, err := DecodeAccountPrivateKey("admin", crypto.ECDSA_secp256k1, secp256k1Key)
+		require.NoError(t, err)
+		assert.Equal(t, crypto.ECDSA_secp256k1, key.Algorithm())
+	})
+
+	t.Run("Mismatched Algorithm", func(t *testing.T) {
+		_, err := DecodeAccountPrivateKey("admin", crypto.ECDSA_P256, secp256k1Key)
+		var mismatchErr *SigAlgoMismatchError
+		require.ErrorAs(t, err, &mismatchErr)
+		assert.Equal(t, crypto.ECDSA_P256, mismatchErr.Declared)
+		assert.Equal(t, crypto.ECDSA_secp256k1, mismatchErr.Actual)
+		assert.EqualError(
+			t,
+			err,
+			"key for account 'admin' parses as ECDSA_secp256k1 but config declares ECDSA_P256, fix the sigAlgorithm field",
+		)
+	})
+
+	t.Run("Invalid Key", func(t *testing.T) {
+		_, err := DecodeAccountPrivateKey("admin", crypto.ECDSA_P256, "0011")
+		require.Error(t, err)
+		var mismatchErr *SigAlgoMismatchError
+		assert.False(t, errors.As(err, &mismatchErr))
+	})
+}
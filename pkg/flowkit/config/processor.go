@@ -58,6 +58,11 @@ func processFile(raw string) (string, map[string]string) {
 			continue
 		}
 
+		// keys loaded from a file use the same syntax inside the account, they are resolved after parsing
+		if match[1] == "key" {
+			continue
+		}
+
 		// match 1 is the account name, match 2 is the file location
 		accountFromFiles[match[1]] = match[2]
 
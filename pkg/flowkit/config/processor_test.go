@@ -98,3 +98,28 @@ func Test_PrivateConfigFileAccounts(t *testing.T) {
 			}
 		}`, string(preprocessor))
 }
+
+func Test_PrivateConfigFileKeys(t *testing.T) {
+	b := []byte(`{
+		"accounts": {
+			"emulator-account": {
+				"address": "f8d6e0586b0a20c7",
+				"key": { "fromFile": "keys/emulator.json" }
+			},
+			"admin-account": { "fromFile": "test.json" }
+		}
+	}`)
+
+	preprocessor, accFromFile := ProcessorRun(b)
+
+	assert.Equal(t, map[string]string{"admin-account": "test.json"}, accFromFile)
+
+	assert.JSONEq(t, `{
+			"accounts": {
+				"emulator-account": {
+					"address": "f8d6e0586b0a20c7",
+					"key": { "fromFile": "keys/emulator.json" }
+				}
+			}
+		}`, string(preprocessor))
+}
@@ -0,0 +1,155 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/cadence/runtime/parser"
+	"github.com/onflow/cadence/runtime/sema"
+)
+
+// Script defines the configuration for a Cadence script registered under a name.
+type Script struct {
+	Name     string
+	Location string
+	// Arguments declare the names and the Cadence types of the script arguments in the order of the script parameters.
+	Arguments []ScriptArgument
+}
+
+// ScriptArgument declares an argument of a registered script.
+type ScriptArgument struct {
+	Name string
+	Type string
+}
+
+// Optional checks if the argument has an optional type, optional arguments can be omitted.
+func (s ScriptArgument) Optional() bool {
+	ty, errs := parser.ParseType(nil, []byte(s.Type), parser.Config{})
+	if len(errs) > 0 {
+		return false
+	}
+	_, ok := ty.(*ast.OptionalType)
+	return ok
+}
+
+type Scripts []Script
+
+// ByName get script by name.
+func (s *Scripts) ByName(name string) (*Script, error) {
+	for _, script := range *s {
+		if script.Name == name {
+			return &script, nil
+		}
+	}
+
+	return nil, fmt.Errorf("script named %s does not exist in configuration", name)
+}
+
+// Names returns the sorted names of the scripts.
+func (s *Scripts) Names() []string {
+	names := make([]string, len(*s))
+	for i, script := range *s {
+		names[i] = script.Name
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AddOrUpdate add new script or update if already present.
+func (s *Scripts) AddOrUpdate(name string, script Script) {
+	for i, existingScript := range *s {
+		if existingScript.Name == name {
+			(*s)[i] = script
+			return
+		}
+	}
+
+	*s = append(*s, script)
+}
+
+// Remove script by the name.
+func (s *Scripts) Remove(name string) error {
+	_, err := s.ByName(name)
+	if err != nil {
+		return err
+	}
+
+	for i, script := range *s {
+		if script.Name == name {
+			*s = append((*s)[0:i], (*s)[i+1:]...) // remove item
+		}
+	}
+
+	return nil
+}
+
+var argumentNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Validate checks the script has a location and its arguments have unique names and valid types.
+func (s *Script) Validate() error {
+	if s.Location == "" {
+		return fmt.Errorf("script %s is missing a location", s.Name)
+	}
+
+	names := make(map[string]bool)
+	for _, arg := range s.Arguments {
+		if !argumentNameRegex.MatchString(arg.Name) {
+			return fmt.Errorf("script %s contains invalid argument name %q", s.Name, arg.Name)
+		}
+		if names[arg.Name] {
+			return fmt.Errorf("script %s contains duplicate argument %s", s.Name, arg.Name)
+		}
+		names[arg.Name] = true
+
+		if err := ValidateArgumentType(arg.Type); err != nil {
+			return fmt.Errorf("script %s argument %s: %w", s.Name, arg.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// ValidateArgumentType checks the type is a valid Cadence type that doesn't need imports.
+func ValidateArgumentType(argType string) error {
+	ty, errs := parser.ParseType(nil, []byte(argType), parser.Config{})
+	if len(errs) > 0 || ty == nil {
+		return fmt.Errorf("invalid type %q", argType)
+	}
+
+	checker, err := sema.NewChecker(
+		ast.NewProgram(nil, nil),
+		common.StringLocation("script-arguments"),
+		nil,
+		&sema.Config{AccessCheckMode: sema.AccessCheckModeStrict},
+	)
+	if err != nil {
+		return err
+	}
+
+	if converted := checker.ConvertType(ty); converted.IsInvalidType() || checker.CheckerError() != nil {
+		return fmt.Errorf("unknown type %q", argType)
+	}
+
+	return nil
+}
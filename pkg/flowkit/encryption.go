@@ -0,0 +1,322 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package flowkit
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+
+	"github.com/onflow/flow-cli/pkg/flowkit/config"
+	configjson "github.com/onflow/flow-cli/pkg/flowkit/config/json"
+)
+
+// EncryptedStatePath is the path the encrypted state is written to.
+const EncryptedStatePath = "flow.encrypted.json"
+
+// ErrInvalidPassphrase is returned when the encrypted state can't be decrypted with the passphrase.
+var ErrInvalidPassphrase = errors.New("failed to decrypt the state, the passphrase is invalid or the file was modified")
+
+const (
+	encryptedValuePrefix = "encrypted:"
+	encryptionAlgorithm  = "AES-256-GCM"
+	encryptionKDF        = "argon2id"
+	encryptionSaltLength = 16
+	encryptionKeyLength  = 32
+	encryptionTime       = 1
+	encryptionMemory     = 64 * 1024
+	encryptionThreads    = 4
+	// the key derivation params are read from the file, they are bounded so a modified file can't exhaust the machine
+	encryptionMaxTime   = 16
+	encryptionMaxMemory = 1024 * 1024
+)
+
+// secretKeyFields are the fields of an advanced account key that are encrypted.
+var secretKeyFields = []string{"privateKey", "mnemonic"}
+
+// encryptionParams are stored in the encrypted state so it can be decrypted even if the defaults change.
+type encryptionParams struct {
+	Algorithm string `json:"algorithm"`
+	KDF       string `json:"kdf"`
+	Salt      string `json:"salt"`
+	Time      uint32 `json:"time"`
+	Memory    uint32 `json:"memory"`
+	Threads   uint8  `json:"threads"`
+}
+
+func newEncryptionParams(salt []byte) encryptionParams {
+	return encryptionParams{
+		Algorithm: encryptionAlgorithm,
+		KDF:       encryptionKDF,
+		Salt:      base64.StdEncoding.EncodeToString(salt),
+		Time:      encryptionTime,
+		Memory:    encryptionMemory,
+		Threads:   encryptionThreads,
+	}
+}
+
+// aead derives the key from the passphrase and returns the AEAD used to encrypt the values.
+func (e encryptionParams) aead(passphrase string) (cipher.AEAD, error) {
+	if e.Algorithm != encryptionAlgorithm || e.KDF != encryptionKDF {
+		return nil, fmt.Errorf("unsupported encryption %s with key derivation %s", e.Algorithm, e.KDF)
+	}
+	if e.Time < 1 || e.Time > encryptionMaxTime ||
+		e.Memory < 8*uint32(e.Threads) || e.Memory > encryptionMaxMemory ||
+		e.Threads < 1 {
+		return nil, fmt.Errorf(
+			"invalid key derivation params time %d, memory %d and threads %d", e.Time, e.Memory, e.Threads,
+		)
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(e.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(passphrase), salt, e.Time, e.Memory, e.Threads, encryptionKeyLength)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// WriteEncrypted saves the state to EncryptedStatePath with the account secrets encrypted.
+//
+// The configuration is serialized the same as flow.json, but the private keys and mnemonics are
+// replaced by values encrypted with AES-256-GCM, using a key derived from the passphrase with Argon2id.
+// Accounts saved in separate files are included in the encrypted state.
+func (p *State) WriteEncrypted(passphrase string) error {
+	if passphrase == "" {
+		return fmt.Errorf("passphrase must not be empty")
+	}
+
+	salt := make([]byte, encryptionSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+
+	data, err := p.encrypt(passphrase, salt, rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	return p.readerWriter.WriteFile(EncryptedStatePath, data, 0644)
+}
+
+// encrypt serializes the state encrypting the secrets with nonces read from the reader.
+func (p *State) encrypt(passphrase string, salt []byte, nonces io.Reader) ([]byte, error) {
+	conf := *p.conf
+	conf.Accounts = accountsToConfig(*p.accounts, nil)
+
+	raw, err := configjson.NewParser().Serialize(&conf)
+	if err != nil {
+		return nil, err
+	}
+
+	params := newEncryptionParams(salt)
+	aead, err := params.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	return transformSecrets(raw, &params, func(value string, path string) (string, error) {
+		nonce := make([]byte, aead.NonceSize())
+		if _, err := io.ReadFull(nonces, nonce); err != nil {
+			return "", err
+		}
+
+		sealed := aead.Seal(nonce, nonce, []byte(value), []byte(path))
+		return encryptedValuePrefix + base64.StdEncoding.EncodeToString(sealed), nil
+	})
+}
+
+// LoadEncrypted loads the state from an encrypted state file written by WriteEncrypted.
+//
+// Files are read from and written to the local filesystem.
+func LoadEncrypted(path string, passphrase string) (*State, error) {
+	return loadEncrypted(path, passphrase, osReaderWriter{})
+}
+
+func loadEncrypted(path string, passphrase string, readerWriter ReaderWriter) (*State, error) {
+	raw, err := readerWriter.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	decrypted, err := decrypt(raw, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	confLoader := config.NewLoader(readerWriter)
+	confLoader.AddConfigParser(configjson.NewParser())
+
+	conf, err := configjson.NewParser().Deserialize(decrypted)
+	if err != nil {
+		return nil, err
+	}
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+	addDefaultEmulator(conf)
+
+	state, err := newProject(conf, confLoader, readerWriter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project configuration: %s", err)
+	}
+
+	return state, nil
+}
+
+// decrypt returns the configuration with the secrets decrypted.
+func decrypt(raw []byte, passphrase string) ([]byte, error) {
+	var doc struct {
+		Encryption *encryptionParams `json:"encryption"`
+	}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("configuration syntax error: %w", err)
+	}
+	if doc.Encryption == nil {
+		return nil, fmt.Errorf("the file is not an encrypted state")
+	}
+
+	aead, err := doc.Encryption.aead(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	return transformSecrets(raw, nil, func(value string, path string) (string, error) {
+		if !strings.HasPrefix(value, encryptedValuePrefix) {
+			return value, nil
+		}
+
+		sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedValuePrefix))
+		if err != nil || len(sealed) < aead.NonceSize() {
+			return "", fmt.Errorf("invalid encrypted value in %s", path)
+		}
+
+		nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+		plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(path))
+		if err != nil {
+			return "", ErrInvalidPassphrase
+		}
+
+		return string(plaintext), nil
+	})
+}
+
+// transformSecrets replaces the account secrets of the serialized configuration and sets the encryption params.
+//
+// Accounts are visited by name so the output is deterministic, the path of the secret
+// (e.g. accounts.alice.key.privateKey) is passed along so the values can't be swapped between accounts.
+func transformSecrets(
+	raw []byte,
+	params *encryptionParams,
+	transform func(value string, path string) (string, error),
+) ([]byte, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+
+	accounts := make(map[string]map[string]any)
+	if rawAccounts, ok := doc["accounts"]; ok {
+		if err := json.Unmarshal(rawAccounts, &accounts); err != nil {
+			return nil, err
+		}
+	}
+
+	names := make([]string, 0, len(accounts))
+	for name := range accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		path := fmt.Sprintf("accounts.%s.key", name)
+
+		switch key := accounts[name]["key"].(type) {
+		case string:
+			value, err := transform(key, path)
+			if err != nil {
+				return nil, err
+			}
+			accounts[name]["key"] = value
+		case map[string]any:
+			for _, field := range secretKeyFields {
+				secret, ok := key[field].(string)
+				if !ok || secret == "" {
+					continue
+				}
+
+				value, err := transform(secret, fmt.Sprintf("%s.%s", path, field))
+				if err != nil {
+					return nil, err
+				}
+				key[field] = value
+			}
+		}
+	}
+
+	if len(accounts) > 0 {
+		rawAccounts, err := json.Marshal(accounts)
+		if err != nil {
+			return nil, err
+		}
+		doc["accounts"] = rawAccounts
+	}
+
+	delete(doc, "encryption")
+	if params != nil {
+		rawParams, err := json.Marshal(params)
+		if err != nil {
+			return nil, err
+		}
+		doc["encryption"] = rawParams
+	}
+
+	return json.MarshalIndent(doc, "", "\t")
+}
+
+// osReaderWriter reads and writes files on the local filesystem.
+type osReaderWriter struct{}
+
+func (osReaderWriter) ReadFile(source string) ([]byte, error) {
+	return os.ReadFile(source)
+}
+
+func (osReaderWriter) Stat(name string) (os.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (osReaderWriter) WriteFile(filename string, data []byte, perm os.FileMode) error {
+	return os.WriteFile(filename, data, perm)
+}
@@ -0,0 +1,218 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package flowkit
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const encryptionTestConfig = `{
+	"accounts": {
+		"emulator-account": {
+			"address": "f8d6e0586b0a20c7",
+			"key": "dd72967fd2bd75234ae9037dd4694c1f00baad63a10c35172bf65fbb8ad74b47"
+		},
+		"alice": {
+			"address": "179b6b1cb6755e31",
+			"key": {
+				"type": "hex",
+				"index": 1,
+				"signatureAlgorithm": "ECDSA_P256",
+				"hashAlgorithm": "SHA3_256",
+				"privateKey": "388e3fbdc654b765942610679bb3a66b74212149ab9482187067ee116d9a8118"
+			}
+		}
+	}
+}`
+
+func encryptionTestState(t *testing.T) (*State, afero.Afero) {
+	rw := afero.Afero{Fs: afero.NewMemMapFs()}
+	require.NoError(t, rw.WriteFile("flow.json", []byte(encryptionTestConfig), 0644))
+
+	state, err := Load([]string{"flow.json"}, rw)
+	require.NoError(t, err)
+
+	return state, rw
+}
+
+// testNonces returns the nonces 000000000000000000000000 and 0c0d0e0f1011121314151617, assigned to the accounts by name.
+func testNonces() *bytes.Reader {
+	nonces := make([]byte, 24)
+	for i := range nonces {
+		nonces[i] = byte(i)
+	}
+	return bytes.NewReader(nonces)
+}
+
+func Test_EncryptState(t *testing.T) {
+	t.Run("Known Vectors", func(t *testing.T) {
+		state, _ := encryptionTestState(t)
+
+		salt := bytes.Repeat([]byte{0x01}, encryptionSaltLength)
+		data, err := state.encrypt("correct horse battery staple", salt, testNonces())
+		require.NoError(t, err)
+
+		var encrypted struct {
+			Accounts map[string]struct {
+				Key json.RawMessage `json:"key"`
+			} `json:"accounts"`
+			Encryption encryptionParams `json:"encryption"`
+		}
+		require.NoError(t, json.Unmarshal(data, &encrypted))
+
+		assert.Equal(t, encryptionParams{
+			Algorithm: "AES-256-GCM",
+			KDF:       "argon2id",
+			Salt:      "AQEBAQEBAQEBAQEBAQEBAQ==",
+			Time:      1,
+			Memory:    65536,
+			Threads:   4,
+		}, encrypted.Encryption)
+
+		var aliceKey map[string]any
+		require.NoError(t, json.Unmarshal(encrypted.Accounts["alice"].Key, &aliceKey))
+		assert.Equal(t,
+			"encrypted:AAECAwQFBgcICQoLc4PDy3hY4uENGwKs3HkDlbBPMNpiVo7GhFN+Uj5RlNxBJbhVXGDHSmqT5FvWmzwXiubrqaemvqKNf3XfqoEXmahuSTIbWvuS31fW2wkz++Q=",
+			aliceKey["privateKey"],
+		)
+		assert.Equal(t, "hex", aliceKey["type"])
+
+		var emulatorKey string
+		require.NoError(t, json.Unmarshal(encrypted.Accounts["emulator-account"].Key, &emulatorKey))
+		assert.Equal(t,
+			"encrypted:DA0ODxAREhMUFRYXIpXST4Syc+ebIfV0PW1ayNkt48cGSG9GsD9FzKdUUqQIxIEmxM9ZMByH8iSq5rhhwTYCrqZ95Gy1Oj3h6JbihW+xd6E544DvVC12HX+7xeA=",
+			emulatorKey,
+		)
+	})
+
+	t.Run("Decrypt Known Vectors", func(t *testing.T) {
+		state, _ := encryptionTestState(t)
+
+		salt := bytes.Repeat([]byte{0x01}, encryptionSaltLength)
+		data, err := state.encrypt("correct horse battery staple", salt, testNonces())
+		require.NoError(t, err)
+
+		decrypted, err := decrypt(data, "correct horse battery staple")
+		require.NoError(t, err)
+		assert.Contains(t, string(decrypted), `"privateKey": "388e3fbdc654b765942610679bb3a66b74212149ab9482187067ee116d9a8118"`)
+		assert.Contains(t, string(decrypted), `"key": "dd72967fd2bd75234ae9037dd4694c1f00baad63a10c35172bf65fbb8ad74b47"`)
+		assert.NotContains(t, string(decrypted), `"encryption"`)
+	})
+}
+
+func Test_WriteEncrypted(t *testing.T) {
+	state, rw := encryptionTestState(t)
+	require.NoError(t, state.WriteEncrypted("passphrase"))
+
+	data, err := rw.ReadFile(EncryptedStatePath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "dd72967fd2bd75234ae9037dd4694c1f00baad63a10c35172bf65fbb8ad74b47")
+	assert.NotContains(t, string(data), "388e3fbdc654b765942610679bb3a66b74212149ab9482187067ee116d9a8118")
+	assert.Contains(t, string(data), `"address": "179b6b1cb6755e31"`)
+
+	t.Run("Load", func(t *testing.T) {
+		loaded, err := loadEncrypted(EncryptedStatePath, "passphrase", rw)
+		require.NoError(t, err)
+
+		alice, err := loaded.Accounts().ByName("alice")
+		require.NoError(t, err)
+		assert.Equal(t, "179b6b1cb6755e31", alice.Address().String())
+		assert.Equal(t, 1, alice.Key().Index())
+		assert.Equal(t,
+			"388e3fbdc654b765942610679bb3a66b74212149ab9482187067ee116d9a8118",
+			alice.Key().(*HexAccountKey).PrivateKeyHex(),
+		)
+		assert.Len(t, loaded.Config().Emulators, 1)
+	})
+
+	t.Run("Invalid Passphrase", func(t *testing.T) {
+		_, err := loadEncrypted(EncryptedStatePath, "wrong", rw)
+		assert.ErrorIs(t, err, ErrInvalidPassphrase)
+	})
+
+	t.Run("Swapped Values", func(t *testing.T) {
+		var doc map[string]any
+		require.NoError(t, json.Unmarshal(data, &doc))
+		accounts := doc["accounts"].(map[string]any)
+		alice := accounts["alice"].(map[string]any)
+		emulator := accounts["emulator-account"].(map[string]any)
+		alice["key"].(map[string]any)["privateKey"] = emulator["key"]
+
+		swapped, err := json.Marshal(doc)
+		require.NoError(t, err)
+
+		_, err = decrypt(swapped, "passphrase")
+		assert.ErrorIs(t, err, ErrInvalidPassphrase)
+	})
+
+	t.Run("Invalid Params", func(t *testing.T) {
+		for _, params := range []map[string]any{
+			{"threads": 0},
+			{"time": 0},
+			{"memory": 1 << 31},
+		} {
+			var doc map[string]any
+			require.NoError(t, json.Unmarshal(data, &doc))
+			for name, value := range params {
+				doc["encryption"].(map[string]any)[name] = value
+			}
+
+			tampered, err := json.Marshal(doc)
+			require.NoError(t, err)
+
+			_, err = decrypt(tampered, "passphrase")
+			assert.ErrorContains(t, err, "invalid key derivation params")
+		}
+	})
+
+	t.Run("Not Encrypted", func(t *testing.T) {
+		_, err := loadEncrypted("flow.json", "passphrase", rw)
+		assert.EqualError(t, err, "the file is not an encrypted state")
+	})
+
+	t.Run("Empty Passphrase", func(t *testing.T) {
+		assert.EqualError(t, state.WriteEncrypted(""), "passphrase must not be empty")
+	})
+}
+
+func Test_LoadEncrypted(t *testing.T) {
+	state, rw := encryptionTestState(t)
+	require.NoError(t, state.WriteEncrypted("passphrase"))
+
+	data, err := rw.ReadFile(EncryptedStatePath)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), EncryptedStatePath)
+	require.NoError(t, os.WriteFile(path, data, 0644))
+
+	loaded, err := LoadEncrypted(path, "passphrase")
+	require.NoError(t, err)
+
+	account, err := loaded.EmulatorServiceAccount()
+	require.NoError(t, err)
+	assert.Equal(t, "f8d6e0586b0a20c7", account.Address().String())
+}
@@ -106,6 +106,21 @@ func (e *Events) GetCreatedAddresses() []*flow.Address {
 	return addresses
 }
 
+// GetUpdatedContracts returns the names of all the contracts updated by the events.
+func (e *Events) GetUpdatedContracts() []string {
+	names := make([]string, 0)
+	for _, event := range *e {
+		if event.Type != flow.EventAccountContractUpdated {
+			continue
+		}
+		if name, ok := event.Values["contract"].(cadence.String); ok {
+			names = append(names, string(name))
+		}
+	}
+
+	return names
+}
+
 func handleCadenceArrayValues(keyArray cadence.Array) []byte {
 	parsedKey := make([]byte, len(keyArray.Values))
 	for i, val := range keyArray.Values {
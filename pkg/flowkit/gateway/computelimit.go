@@ -0,0 +1,28 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gateway
+
+import "github.com/onflow/flow-go-sdk"
+
+// DefaultComputeLimit is the compute limit of transactions that neither set a limit nor have one configured
+// for their network.
+//
+// Querying the maximum compute limit of a network is not supported, the Access API of the Flow SDK in use
+// doesn't provide the node version info the limit could be read from.
+const DefaultComputeLimit = flow.DefaultTransactionGasLimit
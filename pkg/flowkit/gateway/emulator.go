@@ -21,16 +21,20 @@ package gateway
 import (
 	"context"
 	"fmt"
+	"io"
 
 	"github.com/onflow/cadence"
 	jsoncdc "github.com/onflow/cadence/encoding/json"
 	emulator "github.com/onflow/flow-emulator"
 	"github.com/onflow/flow-emulator/convert/sdk"
 	"github.com/onflow/flow-emulator/server/backend"
+	"github.com/onflow/flow-emulator/storage"
+	"github.com/onflow/flow-emulator/storage/badger"
 	"github.com/onflow/flow-go-sdk"
 	flowGo "github.com/onflow/flow-go/model/flow"
 	"github.com/rs/zerolog"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
 	"github.com/onflow/flow-cli/pkg/flowkit"
@@ -39,6 +43,7 @@ import (
 
 type EmulatorGateway struct {
 	emulator        *emulator.Blockchain
+	store           storage.Store
 	backend         *backend.Backend
 	ctx             context.Context
 	logger          *logrus.Logger
@@ -64,7 +69,13 @@ func NewEmulatorGatewayWithOpts(serviceAccount *flowkit.Account, opts ...func(*E
 		opt(gateway)
 	}
 
-	gateway.emulator = newEmulator(serviceAccount, gateway.emulatorOptions...)
+	store, err := badger.New(badger.WithPersist(false))
+	if err != nil {
+		panic(err)
+	}
+	gateway.store = store
+
+	gateway.emulator = newEmulator(serviceAccount, store, gateway.emulatorOptions...)
 	gateway.backend = backend.New(gateway.logger, gateway.emulator)
 	gateway.backend.EnableAutoMine()
 
@@ -99,8 +110,9 @@ func (g *EmulatorGateway) SetContext(ctx context.Context) {
 	g.ctx = ctx
 }
 
-func newEmulator(serviceAccount *flowkit.Account, emulatorOptions ...emulator.Option) *emulator.Blockchain {
-	var opts []emulator.Option
+// newEmulator creates the emulator with the store, the store is kept by the gateway to profile scripts against the emulator state.
+func newEmulator(serviceAccount *flowkit.Account, store storage.Store, emulatorOptions ...emulator.Option) *emulator.Blockchain {
+	opts := []emulator.Option{emulator.WithStore(store)}
 	if serviceAccount != nil && serviceAccount.Key().Type() == config.KeyTypeHex {
 		privKey, _ := serviceAccount.Key().PrivateKey()
 
@@ -109,9 +121,8 @@ func newEmulator(serviceAccount *flowkit.Account, emulatorOptions ...emulator.Op
 			serviceAccount.Key().SigAlgo(),
 			serviceAccount.Key().HashAlgo(),
 		))
-		opts = append(opts, emulatorOptions...)
-
 	}
+	opts = append(opts, emulatorOptions...)
 
 	b, err := emulator.NewBlockchain(opts...)
 	if err != nil {
@@ -122,7 +133,30 @@ func newEmulator(serviceAccount *flowkit.Account, emulatorOptions ...emulator.Op
 }
 
 func (g *EmulatorGateway) GetAccount(address flow.Address) (*flow.Account, error) {
+	if fork, ok := g.store.(*forkStore); ok {
+		defer fork.readAccount()()
+	}
+
 	account, err := g.backend.GetAccount(g.ctx, address)
+	if status.Code(err) == codes.NotFound {
+		return nil, fmt.Errorf("failed to get account with address %s: %w", address, ErrAccountNotFound)
+	}
+	if err != nil {
+		return nil, UnwrapStatusError(err)
+	}
+	return account, nil
+}
+
+// GetAccountAtBlockHeight gets the account at the block height.
+func (g *EmulatorGateway) GetAccountAtBlockHeight(address flow.Address, height uint64) (*flow.Account, error) {
+	if fork, ok := g.store.(*forkStore); ok {
+		defer fork.readAccount()()
+	}
+
+	account, err := g.backend.GetAccountAtBlockHeight(g.ctx, address, height)
+	if status.Code(err) == codes.NotFound {
+		return nil, fmt.Errorf("failed to get account with address %s at height %d: %w", address, height, ErrAccountNotFound)
+	}
 	if err != nil {
 		return nil, UnwrapStatusError(err)
 	}
@@ -134,9 +168,18 @@ func (g *EmulatorGateway) SendSignedTransaction(tx *flowkit.Transaction) (*flow.
 	if err != nil {
 		return nil, UnwrapStatusError(err)
 	}
+	if err := g.forkedStorageError(); err != nil {
+		return nil, fmt.Errorf("transaction %s failed on the fork: %w", tx.FlowTransaction().ID(), err)
+	}
 	return tx.FlowTransaction(), nil
 }
 
+// DefaultWaitPolicy returns WaitExecuted, transactions are executed as soon as they are sent to the emulator
+// so waiting for the seal only adds latency.
+func (g *EmulatorGateway) DefaultWaitPolicy() WaitPolicy {
+	return WaitExecuted
+}
+
 func (g *EmulatorGateway) GetTransactionResult(ID flow.Identifier, waitSeal bool) (*flow.TransactionResult, error) {
 	result, err := g.backend.GetTransactionResult(g.ctx, ID)
 	if err != nil {
@@ -182,6 +225,9 @@ func (g *EmulatorGateway) ExecuteScript(script []byte, arguments []cadence.Value
 	if err != nil {
 		return nil, UnwrapStatusError(err)
 	}
+	if err := g.forkedStorageError(); err != nil {
+		return nil, err
+	}
 
 	value, err := messageToCadenceValue(result)
 	if err != nil {
@@ -303,6 +349,28 @@ func (g *EmulatorGateway) GetLatestProtocolStateSnapshot() ([]byte, error) {
 	return snapshot, nil
 }
 
+// HealthCheck always reports a ready connection, the emulator runs in the same process.
+func (g *EmulatorGateway) HealthCheck() ConnectionState {
+	return ConnectionReady
+}
+
+// forkedStorageError returns ErrForkedStorage if the emulator forks a remote network and the storage of the forked
+// accounts was read since the last call.
+func (g *EmulatorGateway) forkedStorageError() error {
+	if fork, ok := g.store.(*forkStore); ok {
+		return fork.checkStorageReads()
+	}
+	return nil
+}
+
+// Close closes the emulator store, the emulator can't be used afterwards.
+func (g *EmulatorGateway) Close() error {
+	if closer, ok := g.store.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
 // SecureConnection placeholder func to complete gateway interface implementation
 func (g *EmulatorGateway) SecureConnection() bool {
 	return false
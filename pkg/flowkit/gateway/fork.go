@@ -0,0 +1,297 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/onflow/cadence/runtime"
+	emulator "github.com/onflow/flow-emulator"
+	"github.com/onflow/flow-emulator/convert/sdk"
+	"github.com/onflow/flow-emulator/server/backend"
+	"github.com/onflow/flow-emulator/storage"
+	"github.com/onflow/flow-emulator/storage/badger"
+	"github.com/onflow/flow-emulator/types"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go/engine/execution/state/delta"
+	"github.com/onflow/flow-go/fvm/environment"
+	"github.com/onflow/flow-go/fvm/state"
+	flowGo "github.com/onflow/flow-go/model/flow"
+	"github.com/sirupsen/logrus"
+
+	"github.com/onflow/flow-cli/pkg/flowkit"
+)
+
+// ErrForkedStorage is returned when a transaction or a script on a fork reads the storage of an account forked
+// from the remote network, which the fork doesn't have.
+var ErrForkedStorage = errors.New("the storage of forked accounts is not available on the fork")
+
+// NewForkedEmulatorGateway returns an emulator gateway forking the state of a remote network at the block height.
+//
+// The emulator is bootstrapped as usual, after that the accounts it doesn't know are read from the remote
+// network at the height the first time they are accessed and cached, while all the writes stay local.
+// Only the keys and the contracts of the remote accounts are forked, the Access API doesn't provide their storage,
+// so sending transactions and executing scripts reading it fail with ErrForkedStorage instead of silently seeing
+// empty storage, and the storage limits are disabled. The accounts returned by the gateway have no balance,
+// as it's read from their storage. The system accounts are the ones bootstrapped by the emulator
+// and the events of the remote network aren't replayed.
+func NewForkedEmulatorGateway(
+	serviceAccount *flowkit.Account,
+	remote Gateway,
+	height uint64,
+	opts ...func(*EmulatorGateway),
+) *EmulatorGateway {
+	gateway := &EmulatorGateway{
+		ctx:             context.Background(),
+		logger:          logrus.New(),
+		emulatorOptions: []emulator.Option{},
+	}
+	for _, opt := range opts {
+		opt(gateway)
+	}
+
+	local, err := badger.New(badger.WithPersist(false))
+	if err != nil {
+		panic(err)
+	}
+	store := newForkStore(local, remote, height)
+	gateway.store = store
+
+	options := append([]emulator.Option{emulator.WithStorageLimitEnabled(false)}, gateway.emulatorOptions...)
+	gateway.emulator = newEmulator(serviceAccount, store, options...)
+	gateway.backend = backend.New(gateway.logger, gateway.emulator)
+	gateway.backend.EnableAutoMine()
+
+	// remote accounts are only read after bootstrapping, otherwise they would collide with the accounts the emulator creates
+	store.fork()
+
+	return gateway
+}
+
+// forkStore is an emulator store falling back to the remote network for registers of accounts that don't exist locally.
+type forkStore struct {
+	storage.Store
+	remote Gateway
+	height uint64
+
+	mu      sync.Mutex
+	forking bool
+	// accountReads counts the accounts being read by the gateway, they see empty storage to compute their balance.
+	accountReads int
+	// storageReads contains the forked accounts whose storage was read since it was last checked.
+	storageReads map[flowGo.Address]struct{}
+	// written contains the registers committed locally since forking, they are never read from the remote network.
+	written map[flowGo.RegisterID]struct{}
+	// accounts contains the registers of the remote accounts by owner, nil if the account doesn't exist remotely.
+	// Registers missing from a remote account are storage registers unless they are keys or contract code.
+	accounts map[string]map[string]flowGo.RegisterValue
+}
+
+func newForkStore(local storage.Store, remote Gateway, height uint64) *forkStore {
+	return &forkStore{
+		Store:        local,
+		remote:       remote,
+		height:       height,
+		written:      make(map[flowGo.RegisterID]struct{}),
+		accounts:     make(map[string]map[string]flowGo.RegisterValue),
+		storageReads: make(map[flowGo.Address]struct{}),
+	}
+}
+
+// Close closes the local store, the remote network is read through a gateway the fork doesn't own.
+func (f *forkStore) Close() error {
+	if closer, ok := f.Store.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func (f *forkStore) fork() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.forking = true
+}
+
+// readAccount lets the storage of the forked accounts read as empty until the returned function is called.
+func (f *forkStore) readAccount() func() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.accountReads++
+
+	return func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.accountReads--
+	}
+}
+
+// checkStorageReads returns ErrForkedStorage if the storage of forked accounts was read since the last check.
+func (f *forkStore) checkStorageReads() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.storageReads) == 0 {
+		return nil
+	}
+
+	addresses := make([]string, 0, len(f.storageReads))
+	for address := range f.storageReads {
+		addresses = append(addresses, address.HexWithPrefix())
+	}
+	sort.Strings(addresses)
+	f.storageReads = make(map[flowGo.Address]struct{})
+
+	return fmt.Errorf("%w, read from %s", ErrForkedStorage, strings.Join(addresses, ", "))
+}
+
+func (f *forkStore) CommitBlock(
+	ctx context.Context,
+	block flowGo.Block,
+	collections []*flowGo.LightCollection,
+	transactions map[flowGo.Identifier]*flowGo.TransactionBody,
+	transactionResults map[flowGo.Identifier]*types.StorableTransactionResult,
+	delta delta.Delta,
+	events []flowGo.Event,
+) error {
+	f.mu.Lock()
+	if f.forking {
+		for id := range delta.Data {
+			f.written[id] = struct{}{}
+		}
+	}
+	f.mu.Unlock()
+
+	return f.Store.CommitBlock(ctx, block, collections, transactions, transactionResults, delta, events)
+}
+
+func (f *forkStore) LedgerViewByHeight(ctx context.Context, blockHeight uint64) *delta.View {
+	local := f.Store.LedgerViewByHeight(ctx, blockHeight)
+
+	return delta.NewView(func(owner, key string) (flowGo.RegisterValue, error) {
+		value, err := local.Peek(owner, key)
+		if err != nil || len(value) > 0 || owner == "" {
+			return value, err
+		}
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		if !f.forking {
+			return value, nil
+		}
+		if _, ok := f.written[flowGo.NewRegisterID(owner, key)]; ok {
+			return value, nil
+		}
+
+		registers, ok := f.accounts[owner]
+		if !ok {
+			status, err := local.Peek(owner, state.AccountStatusKey)
+			if err != nil {
+				return nil, err
+			}
+			if len(status) > 0 {
+				return value, nil // the account exists locally
+			}
+
+			registers, err = f.remoteRegisters(flowGo.BytesToAddress([]byte(owner)))
+			if err != nil {
+				return nil, err
+			}
+			f.accounts[owner] = registers
+		}
+
+		if value, ok := registers[key]; ok || registers == nil {
+			return value, nil
+		}
+
+		// a missing register is a storage register unless it's a key or contract code the account doesn't have,
+		// a read error would be fatal to the emulator so the read is recorded and reported by the gateway instead
+		if !strings.HasPrefix(key, state.PublicKeyKeyPrefix) && !strings.HasPrefix(key, state.CodeKeyPrefix) &&
+			f.accountReads == 0 {
+			f.storageReads[flowGo.BytesToAddress([]byte(owner))] = struct{}{}
+		}
+		return nil, nil
+	})
+}
+
+// remoteRegisters reads the account from the remote network and encodes it to the registers storing it.
+func (f *forkStore) remoteRegisters(address flowGo.Address) (map[string]flowGo.RegisterValue, error) {
+	account, err := f.remote.GetAccountAtBlockHeight(flow.Address(address), f.height)
+	if errors.Is(err, ErrAccountNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fork account %s: %w", address, err)
+	}
+
+	keys, err := sdk.SDKAccountKeysToFlow(account.Keys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fork keys of account %s: %w", address, err)
+	}
+
+	registers := make(map[string]flowGo.RegisterValue)
+	for i, key := range keys {
+		encoded, err := flowGo.EncodeAccountPublicKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fork keys of account %s: %w", address, err)
+		}
+		registers[environment.KeyPublicKey(uint64(i))] = encoded
+	}
+
+	names := make([]string, 0, len(account.Contracts))
+	for name, code := range account.Contracts {
+		names = append(names, name)
+		registers[environment.ContractKey(name)] = code
+	}
+	if len(names) > 0 {
+		sort.Strings(names)
+		var buf bytes.Buffer
+		if err := cbor.NewEncoder(&buf).Encode(names); err != nil {
+			return nil, fmt.Errorf("failed to fork contracts of account %s: %w", address, err)
+		}
+		registers[state.ContractNamesKey] = buf.Bytes()
+	} else {
+		// without contracts the names and the domain storing the contract values are known to be empty
+		registers[state.ContractNamesKey] = nil
+		registers[runtime.StorageDomainContract] = nil
+	}
+
+	status := environment.NewAccountStatus()
+	status.SetPublicKeyCount(uint64(len(keys)))
+
+	// the storage used must cover the forked registers, so it doesn't turn negative when they are updated
+	used := environment.RegisterSize(address, state.AccountStatusKey, status.ToBytes())
+	for key, value := range registers {
+		if len(value) > 0 {
+			used += environment.RegisterSize(address, key, value)
+		}
+	}
+	status.SetStorageUsed(uint64(used))
+	registers[state.AccountStatusKey] = status.ToBytes()
+
+	return registers, nil
+}
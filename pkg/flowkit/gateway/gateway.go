@@ -19,15 +19,26 @@
 package gateway
 
 import (
+	"io"
+
 	"github.com/onflow/cadence"
 	"github.com/onflow/flow-go-sdk"
 
 	"github.com/onflow/flow-cli/pkg/flowkit"
 )
 
+// ErrAccountNotFound is returned by GetAccount when no account exists at the address.
+//
+// It's flowkit.ErrAccountNotFound, so errors.Is matches missing accounts on the network and in the configuration alike.
+var ErrAccountNotFound = flowkit.ErrAccountNotFound
+
 // Gateway describes blockchain access interface
+//
+// Gateways hold resources like network connections, they are released by Close.
 type Gateway interface {
+	io.Closer
 	GetAccount(flow.Address) (*flow.Account, error)
+	GetAccountAtBlockHeight(flow.Address, uint64) (*flow.Account, error)
 	SendSignedTransaction(*flowkit.Transaction) (*flow.Transaction, error)
 	GetTransaction(flow.Identifier) (*flow.Transaction, error)
 	GetTransactionResultsByBlockID(blockID flow.Identifier) ([]*flow.TransactionResult, error)
@@ -41,5 +52,7 @@ type Gateway interface {
 	GetCollection(flow.Identifier) (*flow.Collection, error)
 	GetLatestProtocolStateSnapshot() ([]byte, error)
 	Ping() error
+	// HealthCheck returns the current state of the connection to the network, e.g. for liveness probes.
+	HealthCheck() ConnectionState
 	SecureConnection() bool
 }
@@ -22,14 +22,20 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/encoding/json"
 	"github.com/onflow/flow-go-sdk"
 	grpcAccess "github.com/onflow/flow-go-sdk/access/grpc"
 	"github.com/onflow/flow-go/utils/grpcutils"
+	"github.com/onflow/flow/protobuf/go/flow/access"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
 
 	"github.com/onflow/flow-cli/pkg/flowkit"
 )
@@ -38,62 +44,116 @@ import (
 // https://github.com/onflow/flow-go/blob/master/utils/grpc/grpc.go#L5
 const maxGRPCMessageSize = 1024 * 1024 * 20
 
+// jsonOptions are the options used to decode values, same as a client dialed by the SDK.
+var jsonOptions = []json.Option{json.WithAllowUnstructuredStaticTypes(true)}
+
 // GrpcGateway is a gateway implementation that uses the Flow Access gRPC API.
+//
+// Calls failing because the connection broke, e.g. after the access node restarted or closed an idle
+// connection, are retried after reconnecting, see WithRedial.
 type GrpcGateway struct {
-	client       *grpcAccess.Client
-	ctx          context.Context
+	client *grpcAccess.BaseClient
+	// rpc is the Access API client used by client, for the calls decoding responses themselves.
+	rpc grpcAccess.RPCClient
+	// conn is nil if the gateway doesn't own the connection of the client.
+	conn *grpc.ClientConn
+	ctx  context.Context
+	// cancel cancels ctx when the gateway is closed, stopping the calls in progress and their reconnects.
+	cancel       context.CancelFunc
+	closeOnce    sync.Once
+	closeErr     error
 	secureClient bool
+	keepalive    keepalive.ClientParameters
+	redial       RedialOptions
+	// GRPCOptions are included in all the calls made to the Access API.
+	GRPCOptions []grpc.CallOption
 }
 
 // NewGrpcGateway returns a new gRPC gateway.
-func NewGrpcGateway(host string) (*GrpcGateway, error) {
-
-	gClient, err := grpcAccess.NewClient(
-		host,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxGRPCMessageSize)),
-	)
-	ctx := context.Background()
-
-	if err != nil || gClient == nil {
-		return nil, fmt.Errorf("failed to connect to host %s", host)
-	}
-
-	return &GrpcGateway{
-		client:       gClient,
-		ctx:          ctx,
-		secureClient: false,
-	}, nil
+func NewGrpcGateway(host string, opts ...func(*GrpcGateway)) (*GrpcGateway, error) {
+	return dialGrpcGateway(host, grpc.WithTransportCredentials(insecure.NewCredentials()), false, opts)
 }
 
 // NewSecureGrpcGateway returns a new gRPC gateway with a secure client connection.
-func NewSecureGrpcGateway(host, hostNetworkKey string) (*GrpcGateway, error) {
+func NewSecureGrpcGateway(host, hostNetworkKey string, opts ...func(*GrpcGateway)) (*GrpcGateway, error) {
 	secureDialOpts, err := grpcutils.SecureGRPCDialOpt(strings.TrimPrefix(hostNetworkKey, "0x"))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create secure GRPC dial options with network key \"%s\": %w", hostNetworkKey, err)
 	}
 
-	gClient, err := grpcAccess.NewClient(
+	return dialGrpcGateway(host, secureDialOpts, true, opts)
+}
+
+// dialGrpcGateway connects to the host with the keepalive and reconnect configured by the options.
+func dialGrpcGateway(
+	host string,
+	credentials grpc.DialOption,
+	secureClient bool,
+	opts []func(*GrpcGateway),
+) (*GrpcGateway, error) {
+	gateway := newGrpcGateway(nil, context.Background(), secureClient, opts)
+
+	conn, err := grpc.Dial(
 		host,
-		secureDialOpts,
+		credentials,
 		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxGRPCMessageSize)),
+		grpc.WithKeepaliveParams(gateway.keepalive),
+		grpc.WithChainUnaryInterceptor(gateway.reconnectInterceptor),
 	)
-	ctx := context.Background()
-
-	if err != nil || gClient == nil {
+	if err != nil || conn == nil {
+		gateway.cancel()
 		return nil, fmt.Errorf("failed to connect to host %s", host)
 	}
 
-	return &GrpcGateway{
-		client:       gClient,
+	gateway.setRPCClient(access.NewAccessAPIClient(conn))
+	gateway.conn = conn
+
+	return gateway, nil
+}
+
+func newGrpcGateway(
+	rpc grpcAccess.RPCClient,
+	ctx context.Context,
+	secureClient bool,
+	opts []func(*GrpcGateway),
+) *GrpcGateway {
+	ctx, cancel := context.WithCancel(ctx)
+	gateway := &GrpcGateway{
 		ctx:          ctx,
-		secureClient: true,
-	}, nil
+		cancel:       cancel,
+		secureClient: secureClient,
+		keepalive:    DefaultKeepalive,
+		redial:       DefaultRedialOptions,
+	}
+	for _, opt := range opts {
+		opt(gateway)
+	}
+	if rpc != nil {
+		gateway.setRPCClient(rpc)
+	}
+
+	return gateway
+}
+
+func (g *GrpcGateway) setRPCClient(rpc grpcAccess.RPCClient) {
+	g.rpc = rpc
+	g.client = grpcAccess.NewFromRPCClient(rpc)
+	g.client.SetJSONOptions(jsonOptions)
+}
+
+// WithCallOptions adds the gRPC call options, like headers or message size limits, to all the calls made by the gateway.
+func WithCallOptions(opts ...grpc.CallOption) func(g *GrpcGateway) {
+	return func(g *GrpcGateway) {
+		g.GRPCOptions = append(g.GRPCOptions, opts...)
+	}
 }
 
 // GetAccount gets an account by address from the Flow Access API.
 func (g *GrpcGateway) GetAccount(address flow.Address) (*flow.Account, error) {
-	account, err := g.client.GetAccountAtLatestBlock(g.ctx, address)
+	account, err := g.client.GetAccountAtLatestBlock(g.ctx, address, g.GRPCOptions...)
+	if status.Code(err) == codes.NotFound {
+		return nil, fmt.Errorf("failed to get account with address %s: %w", address, ErrAccountNotFound)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get account with address %s: %w", address, err)
 	}
@@ -101,11 +161,24 @@ func (g *GrpcGateway) GetAccount(address flow.Address) (*flow.Account, error) {
 	return account, nil
 }
 
+// GetAccountAtBlockHeight gets an account by address at the block height from Flow Access API.
+func (g *GrpcGateway) GetAccountAtBlockHeight(address flow.Address, height uint64) (*flow.Account, error) {
+	account, err := g.client.GetAccountAtBlockHeight(g.ctx, address, height, g.GRPCOptions...)
+	if status.Code(err) == codes.NotFound {
+		return nil, fmt.Errorf("failed to get account with address %s at height %d: %w", address, height, ErrAccountNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account with address %s at height %d: %w", address, height, err)
+	}
+
+	return account, nil
+}
+
 // SendSignedTransaction sends a transaction to flow that is already prepared and signed.
 func (g *GrpcGateway) SendSignedTransaction(transaction *flowkit.Transaction) (*flow.Transaction, error) {
 	tx := transaction.FlowTransaction()
 
-	err := g.client.SendTransaction(g.ctx, *tx)
+	err := g.client.SendTransaction(g.ctx, *tx, g.GRPCOptions...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to submit transaction: %w", err)
 	}
@@ -115,20 +188,20 @@ func (g *GrpcGateway) SendSignedTransaction(transaction *flowkit.Transaction) (*
 
 // GetTransaction gets a transaction by ID from the Flow Access API.
 func (g *GrpcGateway) GetTransaction(ID flow.Identifier) (*flow.Transaction, error) {
-	return g.client.GetTransaction(g.ctx, ID)
+	return g.client.GetTransaction(g.ctx, ID, g.GRPCOptions...)
 }
 
 func (g *GrpcGateway) GetTransactionResultsByBlockID(blockID flow.Identifier) ([]*flow.TransactionResult, error) {
-	return g.client.GetTransactionResultsByBlockID(g.ctx, blockID)
+	return g.client.GetTransactionResultsByBlockID(g.ctx, blockID, g.GRPCOptions...)
 }
 
 func (g *GrpcGateway) GetTransactionsByBlockID(blockID flow.Identifier) ([]*flow.Transaction, error) {
-	return g.client.GetTransactionsByBlockID(g.ctx, blockID)
+	return g.client.GetTransactionsByBlockID(g.ctx, blockID, g.GRPCOptions...)
 }
 
 // GetTransactionResult gets a transaction result by ID from the Flow Access API.
 func (g *GrpcGateway) GetTransactionResult(ID flow.Identifier, waitSeal bool) (*flow.TransactionResult, error) {
-	result, err := g.client.GetTransactionResult(g.ctx, ID)
+	result, err := g.client.GetTransactionResult(g.ctx, ID, g.GRPCOptions...)
 	if err != nil {
 		return nil, err
 	}
@@ -144,7 +217,7 @@ func (g *GrpcGateway) GetTransactionResult(ID flow.Identifier, waitSeal bool) (*
 // ExecuteScript execute a scripts on Flow through the Access API.
 func (g *GrpcGateway) ExecuteScript(script []byte, arguments []cadence.Value) (cadence.Value, error) {
 
-	value, err := g.client.ExecuteScriptAtLatestBlock(g.ctx, script, arguments)
+	value, err := g.client.ExecuteScriptAtLatestBlock(g.ctx, script, arguments, g.GRPCOptions...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to submit executable script: %w", err)
 	}
@@ -154,17 +227,17 @@ func (g *GrpcGateway) ExecuteScript(script []byte, arguments []cadence.Value) (c
 
 // GetLatestBlock gets the latest block on Flow through the Access API.
 func (g *GrpcGateway) GetLatestBlock() (*flow.Block, error) {
-	return g.client.GetLatestBlock(g.ctx, true)
+	return g.client.GetLatestBlock(g.ctx, true, g.GRPCOptions...)
 }
 
 // GetBlockByID get block by ID from the Flow Access API.
 func (g *GrpcGateway) GetBlockByID(id flow.Identifier) (*flow.Block, error) {
-	return g.client.GetBlockByID(g.ctx, id)
+	return g.client.GetBlockByID(g.ctx, id, g.GRPCOptions...)
 }
 
 // GetBlockByHeight get block by height from the Flow Access API.
 func (g *GrpcGateway) GetBlockByHeight(height uint64) (*flow.Block, error) {
-	return g.client.GetBlockByHeight(g.ctx, height)
+	return g.client.GetBlockByHeight(g.ctx, height, g.GRPCOptions...)
 }
 
 // GetEvents gets events by name and block range from the Flow Access API.
@@ -176,9 +249,12 @@ func (g *GrpcGateway) GetEvents(
 
 	events, err := g.client.GetEventsForHeightRange(
 		g.ctx,
-		eventType,
-		startHeight,
-		endHeight,
+		grpcAccess.EventRangeQuery{
+			Type:        eventType,
+			StartHeight: startHeight,
+			EndHeight:   endHeight,
+		},
+		g.GRPCOptions...,
 	)
 
 	return events, err
@@ -186,17 +262,39 @@ func (g *GrpcGateway) GetEvents(
 
 // GetCollection gets a collection by ID from the Flow Access API.
 func (g *GrpcGateway) GetCollection(id flow.Identifier) (*flow.Collection, error) {
-	return g.client.GetCollection(g.ctx, id)
+	return g.client.GetCollection(g.ctx, id, g.GRPCOptions...)
 }
 
 // GetLatestProtocolStateSnapshot gets the latest finalized protocol state snapshot
 func (g *GrpcGateway) GetLatestProtocolStateSnapshot() ([]byte, error) {
-	return g.client.GetLatestProtocolStateSnapshot(g.ctx)
+	return g.client.GetLatestProtocolStateSnapshot(g.ctx, g.GRPCOptions...)
 }
 
 // Ping is used to check if the access node is alive and healthy.
 func (g *GrpcGateway) Ping() error {
-	return g.client.Ping(g.ctx)
+	return g.client.Ping(g.ctx, g.GRPCOptions...)
+}
+
+// HealthCheck returns the state of the connection to the access node.
+//
+// A gateway created over a client it doesn't own the connection of always reports a ready connection.
+func (g *GrpcGateway) HealthCheck() ConnectionState {
+	if g.conn == nil {
+		return ConnectionReady
+	}
+	return newConnectionState(g.conn.GetState())
+}
+
+// Close stops the calls in progress, including the ones waiting to reconnect, and closes the connection
+// to the access node if the gateway owns it. Closing the gateway more than once has no effect.
+func (g *GrpcGateway) Close() error {
+	g.closeOnce.Do(func() {
+		g.cancel()
+		if g.conn != nil {
+			g.closeErr = g.conn.Close()
+		}
+	})
+	return g.closeErr
 }
 
 // SecureConnection is used to log warning if a service should be using a secure client but is not
@@ -0,0 +1,362 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/onflow/flow/protobuf/go/flow/entities"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/onflow/flow-cli/pkg/flowkit"
+)
+
+// recordingAccessClient records the call options passed to the Access API.
+type recordingAccessClient struct {
+	access.AccessAPIClient
+	calls map[string][]grpc.CallOption
+	// accountErr is returned when getting an account, defaults to an unavailable error.
+	accountErr error
+	// heights are the block heights accounts were requested at.
+	heights []uint64
+	// result is returned when getting a transaction result.
+	result *access.TransactionResultResponse
+}
+
+func (r *recordingAccessClient) Ping(
+	_ context.Context,
+	_ *access.PingRequest,
+	opts ...grpc.CallOption,
+) (*access.PingResponse, error) {
+	r.calls["Ping"] = opts
+	return &access.PingResponse{}, nil
+}
+
+func (r *recordingAccessClient) GetAccountAtLatestBlock(
+	_ context.Context,
+	_ *access.GetAccountAtLatestBlockRequest,
+	opts ...grpc.CallOption,
+) (*access.AccountResponse, error) {
+	r.calls["GetAccountAtLatestBlock"] = opts
+	if r.accountErr != nil {
+		return nil, r.accountErr
+	}
+	return nil, status.Error(codes.Unavailable, "access node unavailable")
+}
+
+func (r *recordingAccessClient) GetAccountAtBlockHeight(
+	_ context.Context,
+	req *access.GetAccountAtBlockHeightRequest,
+	opts ...grpc.CallOption,
+) (*access.AccountResponse, error) {
+	r.calls["GetAccountAtBlockHeight"] = opts
+	r.heights = append(r.heights, req.GetBlockHeight())
+	if r.accountErr != nil {
+		return nil, r.accountErr
+	}
+	return &access.AccountResponse{
+		Account: &entities.Account{Address: req.GetAddress(), Balance: 10},
+	}, nil
+}
+
+func (r *recordingAccessClient) SendTransaction(
+	_ context.Context,
+	_ *access.SendTransactionRequest,
+	opts ...grpc.CallOption,
+) (*access.SendTransactionResponse, error) {
+	r.calls["SendTransaction"] = opts
+	return &access.SendTransactionResponse{Id: flow.EmptyID.Bytes()}, nil
+}
+
+func (r *recordingAccessClient) GetTransactionResult(
+	_ context.Context,
+	_ *access.GetTransactionRequest,
+	opts ...grpc.CallOption,
+) (*access.TransactionResultResponse, error) {
+	r.calls["GetTransactionResult"] = opts
+	return r.result, nil
+}
+
+func (r *recordingAccessClient) ExecuteScriptAtLatestBlock(
+	_ context.Context,
+	_ *access.ExecuteScriptAtLatestBlockRequest,
+	opts ...grpc.CallOption,
+) (*access.ExecuteScriptResponse, error) {
+	r.calls["ExecuteScriptAtLatestBlock"] = opts
+	return nil, fmt.Errorf("script failed")
+}
+
+func TestGrpcGateway_CallOptions(t *testing.T) {
+
+	t.Run("Forward Options", func(t *testing.T) {
+		rpc := &recordingAccessClient{calls: make(map[string][]grpc.CallOption)}
+		header := metadata.New(nil)
+		options := []grpc.CallOption{
+			grpc.MaxCallRecvMsgSize(1024),
+			grpc.WaitForReady(true),
+			grpc.Header(&header),
+		}
+
+		gw := newGrpcGateway(
+			rpc,
+			context.Background(),
+			false,
+			[]func(*GrpcGateway){WithCallOptions(options[0]), WithCallOptions(options[1:]...)},
+		)
+		assert.Equal(t, options, gw.GRPCOptions)
+
+		assert.NoError(t, gw.Ping())
+		_, err := gw.GetAccount(flow.HexToAddress("0x01"))
+		assert.Error(t, err)
+		_, err = gw.SendSignedTransaction(flowkit.NewTransaction())
+		assert.NoError(t, err)
+		_, err = gw.ExecuteScript([]byte(`pub fun main() {}`), nil)
+		assert.Error(t, err)
+
+		assert.Len(t, rpc.calls, 4)
+		for name, opts := range rpc.calls {
+			assert.Equal(t, options, opts, name)
+		}
+	})
+
+	t.Run("No Options", func(t *testing.T) {
+		rpc := &recordingAccessClient{calls: make(map[string][]grpc.CallOption)}
+		gw := newGrpcGateway(rpc, context.Background(), false, nil)
+
+		assert.NoError(t, gw.Ping())
+		assert.Len(t, rpc.calls["Ping"], 0)
+	})
+}
+
+func TestGrpcGateway_GetAccount(t *testing.T) {
+	address := flow.HexToAddress("0x01")
+
+	t.Run("Account Not Found", func(t *testing.T) {
+		rpc := &recordingAccessClient{
+			calls:      make(map[string][]grpc.CallOption),
+			accountErr: status.Error(codes.NotFound, "could not find account with address 0000000000000001"),
+		}
+		gw := newGrpcGateway(rpc, context.Background(), false, nil)
+
+		_, err := gw.GetAccount(address)
+		assert.ErrorIs(t, err, ErrAccountNotFound)
+		assert.ErrorIs(t, err, flowkit.ErrAccountNotFound)
+		assert.EqualError(t, err, "failed to get account with address 0000000000000001: account not found")
+	})
+
+	t.Run("Other Errors", func(t *testing.T) {
+		rpc := &recordingAccessClient{calls: make(map[string][]grpc.CallOption)}
+		gw := newGrpcGateway(rpc, context.Background(), false, nil)
+
+		_, err := gw.GetAccount(address)
+		assert.Error(t, err)
+		assert.False(t, errors.Is(err, ErrAccountNotFound))
+		assert.Equal(t, codes.Unavailable, status.Code(errors.Unwrap(err)))
+	})
+}
+
+func TestGrpcGateway_GetAccountAtBlockHeight(t *testing.T) {
+	address := flow.HexToAddress("0x01")
+
+	t.Run("Block Height", func(t *testing.T) {
+		rpc := &recordingAccessClient{calls: make(map[string][]grpc.CallOption)}
+		gw := newGrpcGateway(rpc, context.Background(), false, nil)
+
+		account, err := gw.GetAccountAtBlockHeight(address, 42)
+		assert.NoError(t, err)
+		assert.Equal(t, address, account.Address)
+		assert.Equal(t, uint64(10), account.Balance)
+
+		_, err = gw.GetAccountAtBlockHeight(address, 0)
+		assert.NoError(t, err)
+
+		assert.Equal(t, []uint64{42, 0}, rpc.heights)
+	})
+
+	t.Run("Account Not Found", func(t *testing.T) {
+		rpc := &recordingAccessClient{
+			calls:      make(map[string][]grpc.CallOption),
+			accountErr: status.Error(codes.NotFound, "could not find account with address 0000000000000001"),
+		}
+		gw := newGrpcGateway(rpc, context.Background(), false, nil)
+
+		_, err := gw.GetAccountAtBlockHeight(address, 42)
+		assert.ErrorIs(t, err, ErrAccountNotFound)
+		assert.EqualError(t, err, "failed to get account with address 0000000000000001 at height 42: account not found")
+		assert.Equal(t, []uint64{42}, rpc.heights)
+	})
+}
+
+func TestGrpcGateway_GetTransactionResultWithOptions(t *testing.T) {
+	created := cadence.NewEvent([]cadence.Value{cadence.NewAddress(flow.HexToAddress("0x01"))}).
+		WithType(cadence.NewEventType(nil, flow.EventAccountCreated, []cadence.Field{{
+			Identifier: "address",
+			Type:       cadence.AddressType{},
+		}}, nil))
+	payload, err := json.Encode(created)
+	require.NoError(t, err)
+
+	// payloads of the other events aren't valid, decoding them fails
+	newRPC := func() *recordingAccessClient {
+		return &recordingAccessClient{
+			calls: make(map[string][]grpc.CallOption),
+			result: &access.TransactionResultResponse{
+				Status: entities.TransactionStatus_SEALED,
+				Events: []*entities.Event{
+					{Type: "A.01.Token.Deposited", Payload: []byte("invalid")},
+					{Type: flow.EventAccountCreated, Payload: payload, EventIndex: 1},
+					{Type: "A.01.Token.Withdrawn", Payload: []byte("invalid"), EventIndex: 2},
+				},
+				BlockHeight: 42,
+			},
+		}
+	}
+
+	t.Run("Filter Events", func(t *testing.T) {
+		gw := newGrpcGateway(newRPC(), context.Background(), false, nil)
+
+		result, err := gw.GetTransactionResultWithOptions(flow.EmptyID, true, ResultOptions{
+			EventTypePrefixes: []string{"flow."},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, flow.TransactionStatusSealed, result.Status)
+		assert.Equal(t, uint64(42), result.BlockHeight)
+		require.Len(t, result.Events, 1)
+		assert.Equal(t, flow.EventAccountCreated, result.Events[0].Type)
+		assert.Equal(t, 1, result.Events[0].EventIndex)
+		assert.Equal(t, created.Fields, result.Events[0].Value.Fields)
+	})
+
+	t.Run("Skip Events", func(t *testing.T) {
+		rpc := newRPC()
+		rpc.result.StatusCode = 1
+		rpc.result.ErrorMessage = "execution failed"
+		gw := newGrpcGateway(rpc, context.Background(), false, nil)
+
+		result, err := gw.GetTransactionResultWithOptions(flow.EmptyID, true, ResultOptions{SkipEvents: true})
+		require.NoError(t, err)
+		assert.Empty(t, result.Events)
+		assert.EqualError(t, result.Error, "execution failed")
+	})
+
+	t.Run("All Events Decoded", func(t *testing.T) {
+		gw := newGrpcGateway(newRPC(), context.Background(), false, nil)
+
+		_, err := gw.GetTransactionResultWithOptions(flow.EmptyID, true, ResultOptions{})
+		assert.Error(t, err)
+	})
+}
+
+func TestResultOptions_Require(t *testing.T) {
+	assert.True(t, ResultOptions{}.Require(flow.EventAccountCreated).RetainsAll())
+
+	skip := ResultOptions{SkipEvents: true}.Require(flow.EventAccountCreated)
+	assert.True(t, skip.Retains(flow.EventAccountCreated))
+	assert.False(t, skip.Retains(flow.EventAccountKeyAdded))
+
+	filter := ResultOptions{EventTypePrefixes: []string{"A."}}.Require(flow.EventAccountCreated)
+	assert.True(t, filter.Retains(flow.EventAccountCreated))
+	assert.True(t, filter.Retains("A.01.Token.Deposited"))
+	assert.False(t, filter.Retains(flow.EventAccountKeyAdded))
+}
+
+func TestGrpcGateway_Close(t *testing.T) {
+
+	t.Run("Connection closed without leaks", func(t *testing.T) {
+		ignore := goleak.IgnoreCurrent()
+		server := startServer(t)
+
+		gw, err := NewGrpcGateway(server.address)
+		require.NoError(t, err)
+		require.NoError(t, gw.Ping())
+
+		require.NoError(t, gw.Close())
+		assert.Equal(t, ConnectionShutdown, gw.HealthCheck())
+		assert.Error(t, gw.Ping())
+
+		server.stop()
+		goleak.VerifyNone(t, ignore)
+	})
+
+	t.Run("Close stops reconnecting", func(t *testing.T) {
+		ignore := goleak.IgnoreCurrent()
+		server := startServer(t)
+
+		gw, err := NewGrpcGateway(server.address, WithRedial(RedialOptions{
+			MaxAttempts:    10,
+			MinBackoff:     10 * time.Second,
+			MaxBackoff:     10 * time.Second,
+			AttemptTimeout: 100 * time.Millisecond,
+		}))
+		require.NoError(t, err)
+		require.NoError(t, gw.Ping())
+		server.stop()
+
+		pingErr := make(chan error)
+		go func() { pingErr <- gw.Ping() }()
+
+		// wait for the first reconnect to fail, so the ping is waiting for the backoff
+		time.Sleep(300 * time.Millisecond)
+		require.NoError(t, gw.Close())
+
+		select {
+		case err := <-pingErr:
+			assert.ErrorIs(t, err, context.Canceled)
+		case <-time.After(5 * time.Second):
+			t.Fatal("ping is still reconnecting after the gateway was closed")
+		}
+
+		goleak.VerifyNone(t, ignore)
+	})
+
+	t.Run("Close twice", func(t *testing.T) {
+		server := startServer(t)
+
+		gw, err := NewGrpcGateway(server.address)
+		require.NoError(t, err)
+
+		assert.NoError(t, gw.Close())
+		assert.NoError(t, gw.Close())
+	})
+}
+
+func TestEmulatorGateway_Close(t *testing.T) {
+	ignore := goleak.IgnoreCurrent()
+
+	gw := NewEmulatorGateway(nil)
+	_, err := gw.GetLatestBlock()
+	require.NoError(t, err)
+
+	require.NoError(t, gw.Close())
+	goleak.VerifyNone(t, ignore)
+}
@@ -0,0 +1,32 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gateway
+
+// IsolatedGateway is implemented by gateways to a network that only executes the transactions sent through
+// the gateway, so state read before and after sending a transaction only differs by the transaction changes.
+type IsolatedGateway interface {
+	Isolated() bool
+}
+
+var _ IsolatedGateway = &EmulatorGateway{}
+
+// Isolated returns true, the emulator is created by the gateway and isn't reachable otherwise.
+func (g *EmulatorGateway) Isolated() bool {
+	return true
+}
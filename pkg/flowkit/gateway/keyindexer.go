@@ -0,0 +1,93 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gateway
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+)
+
+// KeyIndexer finds the accounts having a public key, it is the reverse of reading the keys of an account.
+type KeyIndexer interface {
+	AccountsByPublicKey(publicKey crypto.PublicKey) ([]flow.Address, error)
+}
+
+// HTTPKeyIndexer finds accounts with the key indexer API run for the Flow networks.
+type HTTPKeyIndexer struct {
+	url    string
+	client *http.Client
+}
+
+var _ KeyIndexer = &HTTPKeyIndexer{}
+
+// NewHTTPKeyIndexer returns a key indexer using the API at the url, e.g. https://key-indexer.production.flow.com.
+func NewHTTPKeyIndexer(url string) *HTTPKeyIndexer {
+	return &HTTPKeyIndexer{
+		url:    strings.TrimSuffix(url, "/"),
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+type keyIndexerResponse struct {
+	Accounts []struct {
+		Address string `json:"address"`
+	} `json:"accounts"`
+}
+
+// AccountsByPublicKey returns the addresses of the accounts having the public key, revoked keys included.
+func (k *HTTPKeyIndexer) AccountsByPublicKey(publicKey crypto.PublicKey) ([]flow.Address, error) {
+	url := fmt.Sprintf("%s/key/%s", k.url, hex.EncodeToString(publicKey.Encode()))
+	res, err := k.client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up accounts by public key: %w", err)
+	}
+	defer res.Body.Close()
+
+	// the indexer doesn't know keys that no account has
+	if res.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to look up accounts by public key: %s", res.Status)
+	}
+
+	var body keyIndexerResponse
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode key indexer response: %w", err)
+	}
+
+	addresses := make([]flow.Address, 0, len(body.Accounts))
+	seen := make(map[flow.Address]bool)
+	for _, account := range body.Accounts {
+		address := flow.HexToAddress(account.Address)
+		if !seen[address] {
+			seen[address] = true
+			addresses = append(addresses, address)
+		}
+	}
+
+	return addresses, nil
+}
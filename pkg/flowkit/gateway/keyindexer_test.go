@@ -0,0 +1,62 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gateway
+
+import (
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPKeyIndexer(t *testing.T) {
+	privateKey, err := crypto.GeneratePrivateKey(crypto.ECDSA_P256, make([]byte, crypto.MinSeedLength))
+	require.NoError(t, err)
+	publicKey := privateKey.PublicKey()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/key/"+hex.EncodeToString(publicKey.Encode()) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"publicKey":"","accounts":[
+			{"address":"0x01","keyId":0,"weight":1000},
+			{"address":"0x01","keyId":1,"weight":1000},
+			{"address":"0x02","keyId":0,"weight":500}
+		]}`))
+	}))
+	defer server.Close()
+
+	indexer := NewHTTPKeyIndexer(server.URL + "/")
+
+	addresses, err := indexer.AccountsByPublicKey(publicKey)
+	require.NoError(t, err)
+	assert.Equal(t, []flow.Address{flow.HexToAddress("0x01"), flow.HexToAddress("0x02")}, addresses)
+
+	other, err := crypto.GeneratePrivateKey(crypto.ECDSA_secp256k1, make([]byte, crypto.MinSeedLength))
+	require.NoError(t, err)
+	addresses, err = indexer.AccountsByPublicKey(other.PublicKey())
+	require.NoError(t, err)
+	assert.Empty(t, addresses)
+}
@@ -0,0 +1,179 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gateway
+
+import (
+	"fmt"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime/common"
+	emulator "github.com/onflow/flow-emulator"
+	"github.com/onflow/flow-go/fvm"
+	"github.com/onflow/flow-go/fvm/derived"
+	"github.com/onflow/flow-go/fvm/environment"
+	"github.com/onflow/flow-go/fvm/state"
+	flowGo "github.com/onflow/flow-go/model/flow"
+)
+
+// profileScriptGasLimit is the computation limit of profiled scripts, same as the default emulator script limit.
+const profileScriptGasLimit = 100000
+
+// ScriptProfile contains the script result with the computation and memory metered while executing it.
+type ScriptProfile struct {
+	Value           cadence.Value
+	ComputationUsed uint64
+	// ComputationIntensities is the metered intensity by computation kind, such as loops or storage reads.
+	ComputationIntensities map[string]uint
+	MemoryEstimate         uint64
+}
+
+// ScriptProfiler is implemented by gateways that can profile script executions.
+type ScriptProfiler interface {
+	ProfileScript(script []byte, arguments []cadence.Value) (*ScriptProfile, error)
+}
+
+var _ ScriptProfiler = &EmulatorGateway{}
+
+// ProfileScript executes the script at the latest block same as ExecuteScript and reports the metered computation.
+//
+// The script is executed with a separate virtual machine on the emulator state, so profiling
+// doesn't work if the emulator was configured with a different store than the gateway's.
+func (g *EmulatorGateway) ProfileScript(script []byte, arguments []cadence.Value) (*ScriptProfile, error) {
+	args, err := cadenceValuesToMessages(arguments)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := g.emulator.GetLatestBlock()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := fvm.NewContext(
+		fvm.WithChain(g.emulator.GetChain()),
+		fvm.WithBlocks(&emulatorBlocks{emulator: g.emulator}),
+		fvm.WithBlockHeader(block.Header),
+		fvm.WithGasLimit(profileScriptGasLimit),
+		fvm.WithCadenceLogging(true),
+	)
+
+	proc := &profiledScript{ScriptProcedure: fvm.Script(script).WithArguments(args...)}
+	view := g.store.LedgerViewByHeight(g.ctx, block.Header.Height)
+
+	err = fvm.NewVirtualMachine().Run(ctx, proc, view)
+	if err != nil {
+		return nil, err
+	}
+	if proc.Err != nil {
+		return nil, proc.Err
+	}
+	if err := g.forkedStorageError(); err != nil {
+		return nil, err
+	}
+
+	intensities := make(map[string]uint)
+	for kind, intensity := range proc.txnState.ComputationIntensities() {
+		intensities[computationKindName(kind)] = intensity
+	}
+
+	return &ScriptProfile{
+		Value:                  proc.Value,
+		ComputationUsed:        proc.GasUsed,
+		ComputationIntensities: intensities,
+		MemoryEstimate:         proc.MemoryEstimate,
+	}, nil
+}
+
+// profiledScript keeps the state of the script execution so the metered intensities can be read after it finishes.
+type profiledScript struct {
+	*fvm.ScriptProcedure
+	txnState *state.TransactionState
+}
+
+func (p *profiledScript) NewExecutor(
+	ctx fvm.Context,
+	txnState *state.TransactionState,
+	derivedTxnData *derived.DerivedTransactionData,
+) fvm.ProcedureExecutor {
+	p.txnState = txnState
+	return p.ScriptProcedure.NewExecutor(ctx, txnState, derivedTxnData)
+}
+
+// emulatorBlocks looks up blocks referenced by scripts in the emulator.
+type emulatorBlocks struct {
+	emulator *emulator.Blockchain
+}
+
+func (b *emulatorBlocks) ByHeightFrom(height uint64, header *flowGo.Header) (*flowGo.Header, error) {
+	if header != nil && height > header.Height {
+		return nil, fmt.Errorf("block at height %d is after the referenced block at height %d", height, header.Height)
+	}
+
+	block, err := b.emulator.GetBlockByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+
+	return block.Header, nil
+}
+
+// fvmComputationKinds names the computation kinds metered by the FVM, the kinds metered
+// by Cadence are named by the Cadence runtime.
+var fvmComputationKinds = map[common.ComputationKind]string{
+	environment.ComputationKindHash:                       "Hash",
+	environment.ComputationKindVerifySignature:            "VerifySignature",
+	environment.ComputationKindAddAccountKey:              "AddAccountKey",
+	environment.ComputationKindAddEncodedAccountKey:       "AddEncodedAccountKey",
+	environment.ComputationKindAllocateStorageIndex:       "AllocateStorageIndex",
+	environment.ComputationKindCreateAccount:              "CreateAccount",
+	environment.ComputationKindEmitEvent:                  "EmitEvent",
+	environment.ComputationKindGenerateUUID:               "GenerateUUID",
+	environment.ComputationKindGetAccountAvailableBalance: "GetAccountAvailableBalance",
+	environment.ComputationKindGetAccountBalance:          "GetAccountBalance",
+	environment.ComputationKindGetAccountContractCode:     "GetAccountContractCode",
+	environment.ComputationKindGetAccountContractNames:    "GetAccountContractNames",
+	environment.ComputationKindGetAccountKey:              "GetAccountKey",
+	environment.ComputationKindGetBlockAtHeight:           "GetBlockAtHeight",
+	environment.ComputationKindGetCode:                    "GetCode",
+	environment.ComputationKindGetCurrentBlockHeight:      "GetCurrentBlockHeight",
+	environment.ComputationKindGetProgram:                 "GetProgram",
+	environment.ComputationKindGetStorageCapacity:         "GetStorageCapacity",
+	environment.ComputationKindGetStorageUsed:             "GetStorageUsed",
+	environment.ComputationKindGetValue:                   "GetValue",
+	environment.ComputationKindRemoveAccountContractCode:  "RemoveAccountContractCode",
+	environment.ComputationKindResolveLocation:            "ResolveLocation",
+	environment.ComputationKindRevokeAccountKey:           "RevokeAccountKey",
+	environment.ComputationKindRevokeEncodedAccountKey:    "RevokeEncodedAccountKey",
+	environment.ComputationKindSetProgram:                 "SetProgram",
+	environment.ComputationKindSetValue:                   "SetValue",
+	environment.ComputationKindUpdateAccountContractCode:  "UpdateAccountContractCode",
+	environment.ComputationKindValidatePublicKey:          "ValidatePublicKey",
+	environment.ComputationKindValueExists:                "ValueExists",
+	environment.ComputationKindAccountKeysCount:           "AccountKeysCount",
+	environment.ComputationKindBLSVerifyPOP:               "BLSVerifyPOP",
+	environment.ComputationKindBLSAggregateSignatures:     "BLSAggregateSignatures",
+	environment.ComputationKindBLSAggregatePublicKeys:     "BLSAggregatePublicKeys",
+}
+
+func computationKindName(kind common.ComputationKind) string {
+	if name, ok := fvmComputationKinds[kind]; ok {
+		return name
+	}
+	return kind.String()
+}
@@ -0,0 +1,195 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultKeepalive pings the access node after five minutes without activity while a call is in progress,
+// so long calls are not silently dropped by the network in between.
+//
+// Access nodes use the gRPC default enforcement policy, closing connections pinged more often than every five
+// minutes or pinged without calls in progress, so the pings don't go below that. Broken idle connections are
+// redialed by the next call instead.
+var DefaultKeepalive = keepalive.ClientParameters{
+	Time:                5 * time.Minute,
+	Timeout:             20 * time.Second,
+	PermitWithoutStream: false,
+}
+
+// RedialOptions configures how a call failing on a broken connection reconnects before it's retried.
+type RedialOptions struct {
+	// MaxAttempts is the number of reconnects made by a single call before a ReconnectError is returned.
+	MaxAttempts int
+	// MinBackoff is the wait after the first failed reconnect, doubled after each following one.
+	MinBackoff time.Duration
+	// MaxBackoff caps the wait between reconnects.
+	MaxBackoff time.Duration
+	// AttemptTimeout bounds the wait for the connection to become ready in a single reconnect.
+	AttemptTimeout time.Duration
+}
+
+// DefaultRedialOptions are used by the gateway unless changed with WithRedial.
+var DefaultRedialOptions = RedialOptions{
+	MaxAttempts:    3,
+	MinBackoff:     200 * time.Millisecond,
+	MaxBackoff:     2 * time.Second,
+	AttemptTimeout: 5 * time.Second,
+}
+
+// ReconnectError is returned when a call failed because the connection to the access node was broken
+// and it couldn't be re-established in the allowed redial attempts.
+type ReconnectError struct {
+	Host     string
+	Attempts int
+	Err      error
+}
+
+func (r *ReconnectError) Error() string {
+	return fmt.Sprintf("failed to reconnect to %s after %d attempts: %v", r.Host, r.Attempts, r.Err)
+}
+
+func (r *ReconnectError) Unwrap() error {
+	return r.Err
+}
+
+// ConnectionState is the state of the gateway connection to the network.
+type ConnectionState string
+
+const (
+	ConnectionIdle             ConnectionState = "idle"
+	ConnectionConnecting       ConnectionState = "connecting"
+	ConnectionReady            ConnectionState = "ready"
+	ConnectionTransientFailure ConnectionState = "transient-failure"
+	ConnectionShutdown         ConnectionState = "shutdown"
+)
+
+// Healthy reports whether calls can be made over the connection, idle connections reconnect on the next call.
+func (c ConnectionState) Healthy() bool {
+	return c == ConnectionIdle || c == ConnectionConnecting || c == ConnectionReady
+}
+
+func newConnectionState(state connectivity.State) ConnectionState {
+	switch state {
+	case connectivity.Idle:
+		return ConnectionIdle
+	case connectivity.Connecting:
+		return ConnectionConnecting
+	case connectivity.Ready:
+		return ConnectionReady
+	case connectivity.TransientFailure:
+		return ConnectionTransientFailure
+	}
+	return ConnectionShutdown
+}
+
+// WithKeepalive changes the keepalive pings sent on the gateway connection.
+//
+// Access nodes may close connections pinging more often than they allow, gRPC doesn't ping more often than every 10 seconds.
+func WithKeepalive(params keepalive.ClientParameters) func(g *GrpcGateway) {
+	return func(g *GrpcGateway) {
+		g.keepalive = params
+	}
+}
+
+// WithRedial changes how calls failing on a broken connection reconnect, zero attempts disable reconnecting.
+func WithRedial(options RedialOptions) func(g *GrpcGateway) {
+	return func(g *GrpcGateway) {
+		g.redial = options
+	}
+}
+
+// isConnectionError checks whether the call failed because the connection to the access node is not usable.
+func isConnectionError(err error) bool {
+	return status.Code(err) == codes.Unavailable
+}
+
+// reconnectInterceptor retries calls failing on a broken connection after reconnecting, instead of
+// surfacing the error of the stale connection.
+//
+// gRPC redials broken connections on its own, but only after its connection backoff, which can
+// be much longer than the call is willing to wait, so the backoff is reset before each reconnect.
+func (g *GrpcGateway) reconnectInterceptor(
+	ctx context.Context,
+	method string,
+	req, reply any,
+	cc *grpc.ClientConn,
+	invoker grpc.UnaryInvoker,
+	opts ...grpc.CallOption,
+) error {
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	if !isConnectionError(err) || g.redial.MaxAttempts <= 0 {
+		return err
+	}
+
+	backoff := g.redial.MinBackoff
+	for attempt := 1; attempt <= g.redial.MaxAttempts; attempt++ {
+		cc.ResetConnectBackoff()
+		cc.Connect()
+
+		if g.waitForReady(ctx, cc) {
+			err = invoker(ctx, method, req, reply, cc, opts...)
+			if !isConnectionError(err) {
+				return err
+			}
+		}
+
+		if attempt == g.redial.MaxAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > g.redial.MaxBackoff {
+			backoff = g.redial.MaxBackoff
+		}
+	}
+
+	return &ReconnectError{Host: cc.Target(), Attempts: g.redial.MaxAttempts, Err: err}
+}
+
+// waitForReady waits until the connection is ready or the attempt timeout passes.
+func (g *GrpcGateway) waitForReady(ctx context.Context, cc *grpc.ClientConn) bool {
+	ctx, cancel := context.WithTimeout(ctx, g.redial.AttemptTimeout)
+	defer cancel()
+
+	for {
+		state := cc.GetState()
+		if state == connectivity.Ready {
+			return true
+		}
+		if state == connectivity.Shutdown || !cc.WaitForStateChange(ctx, state) {
+			return false
+		}
+	}
+}
@@ -0,0 +1,145 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gateway
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type pingServer struct {
+	access.UnimplementedAccessAPIServer
+}
+
+func (p *pingServer) Ping(context.Context, *access.PingRequest) (*access.PingResponse, error) {
+	return &access.PingResponse{}, nil
+}
+
+// restartableServer serves the Access API on the same address every time it's started.
+type restartableServer struct {
+	t       *testing.T
+	address string
+	server  *grpc.Server
+}
+
+func startServer(t *testing.T) *restartableServer {
+	r := &restartableServer{t: t, address: "127.0.0.1:0"}
+	r.start()
+	t.Cleanup(r.stop)
+	return r
+}
+
+func (r *restartableServer) start() {
+	listener, err := net.Listen("tcp", r.address)
+	require.NoError(r.t, err)
+	r.address = listener.Addr().String()
+
+	r.server = grpc.NewServer()
+	access.RegisterAccessAPIServer(r.server, &pingServer{})
+	go func() { _ = r.server.Serve(listener) }()
+}
+
+func (r *restartableServer) stop() {
+	r.server.Stop()
+}
+
+func TestGrpcGateway_Reconnect(t *testing.T) {
+
+	t.Run("Request succeeds after server restart", func(t *testing.T) {
+		server := startServer(t)
+
+		gw, err := NewGrpcGateway(server.address, WithRedial(RedialOptions{
+			MaxAttempts:    10,
+			MinBackoff:     50 * time.Millisecond,
+			MaxBackoff:     200 * time.Millisecond,
+			AttemptTimeout: time.Second,
+		}))
+		require.NoError(t, err)
+		defer gw.Close()
+
+		require.NoError(t, gw.Ping())
+		assert.Equal(t, ConnectionReady, gw.HealthCheck())
+
+		server.stop()
+		require.Eventually(t, func() bool {
+			return gw.HealthCheck() != ConnectionReady
+		}, 5*time.Second, 10*time.Millisecond)
+
+		restarted := make(chan struct{})
+		go func() {
+			time.Sleep(300 * time.Millisecond)
+			server.start()
+			close(restarted)
+		}()
+
+		assert.NoError(t, gw.Ping())
+		<-restarted
+		assert.Equal(t, ConnectionReady, gw.HealthCheck())
+	})
+
+	t.Run("Reconnect attempts exhausted", func(t *testing.T) {
+		server := startServer(t)
+
+		gw, err := NewGrpcGateway(server.address, WithRedial(RedialOptions{
+			MaxAttempts:    2,
+			MinBackoff:     10 * time.Millisecond,
+			MaxBackoff:     10 * time.Millisecond,
+			AttemptTimeout: 100 * time.Millisecond,
+		}))
+		require.NoError(t, err)
+		defer gw.Close()
+		require.NoError(t, gw.Ping())
+
+		server.stop()
+
+		err = gw.Ping()
+		var reconnectErr *ReconnectError
+		require.ErrorAs(t, err, &reconnectErr)
+		assert.Equal(t, 2, reconnectErr.Attempts)
+		assert.Equal(t, server.address, reconnectErr.Host)
+		assert.Equal(t, codes.Unavailable, status.Code(reconnectErr.Err))
+		assert.NotEqual(t, ConnectionReady, gw.HealthCheck())
+	})
+
+	t.Run("Reconnect disabled", func(t *testing.T) {
+		server := startServer(t)
+
+		gw, err := NewGrpcGateway(server.address, WithRedial(RedialOptions{}))
+		require.NoError(t, err)
+		defer gw.Close()
+		require.NoError(t, gw.Ping())
+
+		server.stop()
+
+		err = gw.Ping()
+		var reconnectErr *ReconnectError
+		assert.False(t, errors.As(err, &reconnectErr))
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	})
+}
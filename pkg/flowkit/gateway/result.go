@@ -0,0 +1,208 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow-go-sdk"
+	grpcAccess "github.com/onflow/flow-go-sdk/access/grpc"
+	"github.com/onflow/flow/protobuf/go/flow/access"
+	"github.com/onflow/flow/protobuf/go/flow/entities"
+)
+
+// ResultOptions configures which events are retained in a transaction result.
+//
+// The zero value retains all the events. Results of large transactions can have thousands of events,
+// leaving them out saves decoding and holding them when only the status or a few events are needed.
+type ResultOptions struct {
+	// SkipEvents leaves all the events out, the result only contains the status and the error.
+	SkipEvents bool
+	// EventTypePrefixes retains only the events with a type starting with one of the prefixes,
+	// e.g. "flow.AccountCreated" or "A.f8d6e0586b0a20c7.", all the events are retained if empty.
+	EventTypePrefixes []string
+}
+
+// RetainsAll returns true if no event is left out of the results.
+func (o ResultOptions) RetainsAll() bool {
+	return !o.SkipEvents && len(o.EventTypePrefixes) == 0
+}
+
+// Retains returns true if events of the type are kept in the results.
+func (o ResultOptions) Retains(eventType string) bool {
+	if o.SkipEvents {
+		return false
+	}
+	if len(o.EventTypePrefixes) == 0 {
+		return true
+	}
+
+	for _, prefix := range o.EventTypePrefixes {
+		if strings.HasPrefix(eventType, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Require returns options that also retain the events of the types, in addition to the events retained by o.
+func (o ResultOptions) Require(eventTypes ...string) ResultOptions {
+	if o.RetainsAll() || len(eventTypes) == 0 {
+		return o
+	}
+
+	required := ResultOptions{}
+	if !o.SkipEvents {
+		required.EventTypePrefixes = append(required.EventTypePrefixes, o.EventTypePrefixes...)
+	}
+	required.EventTypePrefixes = append(required.EventTypePrefixes, eventTypes...)
+
+	return required
+}
+
+// Filter removes the events not retained by the options from the result.
+func (o ResultOptions) Filter(result *flow.TransactionResult) {
+	if result == nil || o.RetainsAll() {
+		return
+	}
+
+	events := make([]flow.Event, 0)
+	for _, event := range result.Events {
+		if o.Retains(event.Type) {
+			events = append(events, event)
+		}
+	}
+	result.Events = events
+}
+
+// TransactionResultReader is implemented by gateways that can leave events out of a transaction result
+// before decoding them.
+type TransactionResultReader interface {
+	GetTransactionResultWithOptions(ID flow.Identifier, waitSeal bool, options ResultOptions) (*flow.TransactionResult, error)
+}
+
+var _ TransactionResultReader = &GrpcGateway{}
+
+// GetTransactionResultWithOptions gets the transaction result from the gateway with the events retained by the options.
+//
+// Gateways that aren't a TransactionResultReader return all the events, which are filtered after receiving them.
+func GetTransactionResultWithOptions(
+	gateway Gateway,
+	ID flow.Identifier,
+	waitSeal bool,
+	options ResultOptions,
+) (*flow.TransactionResult, error) {
+	if options.RetainsAll() {
+		return gateway.GetTransactionResult(ID, waitSeal)
+	}
+	if reader, ok := gateway.(TransactionResultReader); ok {
+		return reader.GetTransactionResultWithOptions(ID, waitSeal, options)
+	}
+
+	result, err := gateway.GetTransactionResult(ID, waitSeal)
+	if err != nil {
+		return nil, err
+	}
+
+	options.Filter(result)
+	return result, nil
+}
+
+// GetTransactionResultWithOptions gets a transaction result by ID from the Flow Access API with the events
+// retained by the options.
+//
+// The Access API always sends all the events of the result, but only the payloads of the retained events
+// are decoded and the others are released as soon as they are skipped.
+func (g *GrpcGateway) GetTransactionResultWithOptions(
+	ID flow.Identifier,
+	waitSeal bool,
+	options ResultOptions,
+) (*flow.TransactionResult, error) {
+	if options.RetainsAll() {
+		return g.GetTransactionResult(ID, waitSeal)
+	}
+
+	res, err := g.rpc.GetTransactionResult(g.ctx, &access.GetTransactionRequest{Id: ID.Bytes()}, g.GRPCOptions...)
+	if err != nil {
+		return nil, grpcAccess.RPCError{GRPCErr: err}
+	}
+
+	if flow.TransactionStatus(res.GetStatus()) != flow.TransactionStatusSealed && waitSeal {
+		time.Sleep(time.Second)
+		return g.GetTransactionResultWithOptions(ID, waitSeal, options)
+	}
+
+	events := make([]flow.Event, 0)
+	for i, message := range res.GetEvents() {
+		res.Events[i] = nil
+		if !options.Retains(message.GetType()) {
+			continue
+		}
+
+		event, err := eventFromMessage(message)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode event %d of transaction %s: %w", i, ID, err)
+		}
+		events = append(events, event)
+	}
+
+	// same conversion as the SDK, an error without message is still a failed execution
+	var resultErr error
+	if res.GetStatusCode() != 0 {
+		resultErr = errors.New("transaction execution failed")
+		if res.GetErrorMessage() != "" {
+			resultErr = errors.New(res.GetErrorMessage())
+		}
+	}
+
+	return &flow.TransactionResult{
+		Status:        flow.TransactionStatus(res.GetStatus()),
+		Error:         resultErr,
+		Events:        events,
+		BlockID:       flow.BytesToID(res.GetBlockId()),
+		BlockHeight:   res.GetBlockHeight(),
+		TransactionID: flow.BytesToID(res.GetTransactionId()),
+	}, nil
+}
+
+func eventFromMessage(message *entities.Event) (flow.Event, error) {
+	value, err := json.Decode(nil, message.GetPayload(), jsonOptions...)
+	if err != nil {
+		return flow.Event{}, err
+	}
+
+	eventValue, ok := value.(cadence.Event)
+	if !ok {
+		return flow.Event{}, fmt.Errorf("expected event value, got %s", value.Type().ID())
+	}
+
+	return flow.Event{
+		Type:             message.GetType(),
+		TransactionID:    flow.BytesToID(message.GetTransactionId()),
+		TransactionIndex: int(message.GetTransactionIndex()),
+		EventIndex:       int(message.GetEventIndex()),
+		Payload:          message.GetPayload(),
+		Value:            eventValue,
+	}, nil
+}
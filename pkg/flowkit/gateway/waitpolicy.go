@@ -0,0 +1,61 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gateway
+
+// WaitPolicy is the status a transaction result is waited for before it's returned.
+type WaitPolicy int
+
+const (
+	// WaitDefault waits for the status of the default wait policy of the gateway.
+	WaitDefault WaitPolicy = iota
+	// WaitSealed waits until the transaction is sealed, the result is final.
+	WaitSealed
+	// WaitExecuted returns the result as soon as the transaction is executed, the events and the error
+	// are available but the result isn't final, its status is flow.TransactionStatusExecuted until sealed.
+	WaitExecuted
+)
+
+func (w WaitPolicy) String() string {
+	switch w {
+	case WaitSealed:
+		return "sealed"
+	case WaitExecuted:
+		return "executed"
+	default:
+		return "default"
+	}
+}
+
+// WaitPolicyProvider is implemented by gateways that wait for transaction results with their own default policy.
+type WaitPolicyProvider interface {
+	DefaultWaitPolicy() WaitPolicy
+}
+
+var _ WaitPolicyProvider = &EmulatorGateway{}
+
+// DefaultWaitPolicy returns the default wait policy of the gateway, WaitSealed if the gateway isn't a WaitPolicyProvider.
+func DefaultWaitPolicy(gateway Gateway) WaitPolicy {
+	if provider, ok := gateway.(WaitPolicyProvider); ok {
+		if policy := provider.DefaultWaitPolicy(); policy != WaitDefault {
+			return policy
+		}
+	}
+
+	return WaitSealed
+}
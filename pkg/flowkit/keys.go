@@ -58,7 +58,7 @@ func NewAccountKey(accountKeyConf config.AccountKey) (AccountKey, error) {
 	switch accountKeyConf.Type {
 	case config.KeyTypeHex:
 		return newHexAccountKey(accountKeyConf)
-	case config.KeyTypeBip44:
+	case config.KeyTypeBip44, config.KeyTypeHDWallet:
 		return newBip44AccountKey(accountKeyConf)
 	case config.KeyTypeGoogleKMS:
 		return newKmsAccountKey(accountKeyConf)
@@ -268,24 +268,48 @@ type Bip44AccountKey struct {
 	derivationPath string
 }
 
+// MnemonicEnv is the environment variable the mnemonic of a hd-wallet key is read from when it's not configured.
+const MnemonicEnv = "FLOW_MNEMONIC"
+
+// newBip44AccountKey returns the key for the bip44 key type and its hd-wallet alias.
+//
+// A hd-wallet key derives the private key the same way, but if its mnemonic isn't configured it's read from
+// the FLOW_MNEMONIC environment variable and it's never written back to the configuration.
 func newBip44AccountKey(key config.AccountKey) (AccountKey, error) {
+	derivationPath := key.DerivationPath
+	if derivationPath == "" && key.Type == config.KeyTypeHDWallet {
+		derivationPath = config.DefaultDerivationPath
+	}
+
 	return &Bip44AccountKey{
 		baseAccountKey: &baseAccountKey{
-			keyType:  config.KeyTypeBip44,
+			keyType:  key.Type,
 			index:    key.Index,
 			sigAlgo:  key.SigAlgo,
 			hashAlgo: key.HashAlgo,
 		},
-		derivationPath: key.DerivationPath,
+		derivationPath: derivationPath,
 		mnemonic:       key.Mnemonic,
 	}, nil
 }
 
 func (a *Bip44AccountKey) Signer(ctx context.Context) (crypto.Signer, error) {
-	return crypto.NewInMemorySigner(a.privateKey, a.HashAlgo())
+	privateKey, err := a.PrivateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return crypto.NewInMemorySigner(*privateKey, a.HashAlgo())
 }
 
+// PrivateKey returns the derived private key, deriving it on first use.
 func (a *Bip44AccountKey) PrivateKey() (*crypto.PrivateKey, error) {
+	if a.privateKey == nil {
+		if err := a.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
 	return &a.privateKey, nil
 }
 
@@ -302,8 +326,15 @@ func (a *Bip44AccountKey) ToConfig() config.AccountKey {
 }
 
 func (a *Bip44AccountKey) Validate() error {
+	mnemonic := a.mnemonic
+	if mnemonic == "" && a.keyType == config.KeyTypeHDWallet {
+		mnemonic = os.Getenv(MnemonicEnv)
+		if mnemonic == "" {
+			return fmt.Errorf("missing mnemonic for hd-wallet key, set it in flow.json or in the %s environment variable", MnemonicEnv)
+		}
+	}
 
-	if !bip39.IsMnemonicValid(a.mnemonic) {
+	if !bip39.IsMnemonicValid(mnemonic) {
 		return fmt.Errorf("invalid mnemonic defined for account in flow.json")
 	}
 
@@ -312,30 +343,39 @@ func (a *Bip44AccountKey) Validate() error {
 		return fmt.Errorf("invalid derivation path defined for account in flow.json")
 	}
 
-	seed := bip39.NewSeed(a.mnemonic, "")
+	a.privateKey, err = deriveHDPrivateKey(mnemonic, derivationPath, a.SigAlgo())
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (a *Bip44AccountKey) PrivateKeyHex() string {
+	return hex.EncodeToString(a.privateKey.Encode())
+}
+
+// deriveHDPrivateKey derives the private key from the mnemonic seed following SLIP-10 for the curve of the signature algorithm.
+func deriveHDPrivateKey(
+	mnemonic string,
+	derivationPath goeth.DerivationPath,
+	sigAlgo crypto.SignatureAlgorithm,
+) (crypto.PrivateKey, error) {
+	seed := bip39.NewSeed(mnemonic, "")
 	curve := slip10.CurveBitcoin
-	if a.sigAlgo == crypto.ECDSA_P256 {
+	if sigAlgo == crypto.ECDSA_P256 {
 		curve = slip10.CurveP256
 	}
 	accountKey, err := slip10.NewMasterKeyWithCurve(seed, curve)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	for _, n := range derivationPath {
 		accountKey, err = accountKey.NewChildKey(n)
-
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
-	a.privateKey, err = crypto.DecodePrivateKey(a.SigAlgo(), accountKey.Key)
-	if err != nil {
-		return err
-	}
-	return nil
-}
 
-func (a *Bip44AccountKey) PrivateKeyHex() string {
-	return hex.EncodeToString(a.privateKey.Encode())
+	return crypto.DecodePrivateKey(sigAlgo, accountKey.Key)
 }
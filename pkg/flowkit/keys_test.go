@@ -0,0 +1,120 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package flowkit
+
+import (
+	"context"
+	"encoding/hex"
+	"testing"
+
+	"github.com/onflow/flow-go-sdk/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-cli/pkg/flowkit/config"
+)
+
+func TestHDWalletKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		mnemonic   string
+		path       string
+		sigAlgo    crypto.SignatureAlgorithm
+		privateKey string
+		publicKey  string
+	}{
+		{
+			name:       "default path",
+			mnemonic:   "normal dune pole key case cradle unfold require tornado mercy hospital buyer",
+			sigAlgo:    crypto.ECDSA_P256,
+			privateKey: "0x638dc9ad0eee91d09249f0fd7c5323a11600e20d5b9105b66b782a96236e74cf",
+			publicKey:  "dbe5b4b4416ad9158339dd692002ceddab895e11bd87d90ce7e3e745efef28d2ad6e736fe3d57d52213f397a7ba9f0bc8c65620a872aefedbc1ddd74c605cf58",
+		},
+		{
+			// https://github.com/onflow/ledger-app-flow#using-a-real-device-for-integration-tests-nano-s-and-nano-s-plus
+			name:       "ledger path",
+			mnemonic:   "equip will roof matter pink blind book anxiety banner elbow sun young",
+			path:       "m/44'/539'/513'/0/0",
+			sigAlgo:    crypto.ECDSA_secp256k1,
+			privateKey: "0xd18d051afca7150781fef111f3387d132d31c4a6250268db0f61f836a205e0b8",
+			publicKey:  "d7482bbaff7827035d5b238df318b10604673dc613808723efbd23fbc4b9fad34a415828d924ec7b83ac0eddf22ef115b7c203ee39fb080572d7e51775ee54be",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			key, err := NewAccountKey(config.AccountKey{
+				Type:           config.KeyTypeHDWallet,
+				SigAlgo:        test.sigAlgo,
+				HashAlgo:       crypto.SHA3_256,
+				Mnemonic:       test.mnemonic,
+				DerivationPath: test.path,
+			})
+			require.NoError(t, err)
+			require.NoError(t, key.Validate())
+
+			privateKey, err := key.PrivateKey()
+			require.NoError(t, err)
+			assert.Equal(t, test.privateKey, (*privateKey).String())
+			assert.Equal(t, test.publicKey, hex.EncodeToString((*privateKey).PublicKey().Encode()))
+		})
+	}
+
+	t.Run("Mnemonic from environment", func(t *testing.T) {
+		t.Setenv(MnemonicEnv, "equip will roof matter pink blind book anxiety banner elbow sun young")
+
+		key, err := NewAccountKey(config.AccountKey{
+			Type:           config.KeyTypeHDWallet,
+			SigAlgo:        crypto.ECDSA_secp256k1,
+			HashAlgo:       crypto.SHA3_256,
+			DerivationPath: "m/44'/539'/513'/0/0",
+		})
+		require.NoError(t, err)
+
+		signer, err := key.Signer(context.Background())
+		require.NoError(t, err)
+		assert.Equal(
+			t,
+			"d7482bbaff7827035d5b238df318b10604673dc613808723efbd23fbc4b9fad34a415828d924ec7b83ac0eddf22ef115b7c203ee39fb080572d7e51775ee54be",
+			hex.EncodeToString(signer.PublicKey().Encode()),
+		)
+
+		// the mnemonic from the environment is never written to the configuration
+		assert.Equal(t, "", key.ToConfig().Mnemonic)
+	})
+
+	t.Run("Missing Mnemonic", func(t *testing.T) {
+		t.Setenv(MnemonicEnv, "")
+
+		key, err := NewAccountKey(config.AccountKey{Type: config.KeyTypeHDWallet, SigAlgo: crypto.ECDSA_P256})
+		require.NoError(t, err)
+		assert.ErrorContains(t, key.Validate(), "missing mnemonic for hd-wallet key")
+	})
+
+	t.Run("Invalid Derivation Path", func(t *testing.T) {
+		key, err := NewAccountKey(config.AccountKey{
+			Type:           config.KeyTypeHDWallet,
+			SigAlgo:        crypto.ECDSA_P256,
+			Mnemonic:       "normal dune pole key case cradle unfold require tornado mercy hospital buyer",
+			DerivationPath: "m/invalid",
+		})
+		require.NoError(t, err)
+		assert.ErrorContains(t, key.Validate(), "invalid derivation path defined for account in flow.json")
+	})
+}
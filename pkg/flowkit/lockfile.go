@@ -0,0 +1,194 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package flowkit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+)
+
+// DefaultLockFilePath is the path of the lock file recording the contract deployments.
+const DefaultLockFilePath = "flow.lock"
+
+const (
+	// MaxDeploymentMetadataEntries is the maximum number of metadata entries recorded for a deployment.
+	MaxDeploymentMetadataEntries = 32
+	// MaxDeploymentMetadataValueSize is the maximum size of a single metadata value in bytes.
+	MaxDeploymentMetadataValueSize = 1024
+	// MaxDeploymentMetadataSize is the maximum size of all metadata keys and values in bytes.
+	MaxDeploymentMetadataSize = 4096
+)
+
+var metadataKeyRegex = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.\-]{0,63}$`)
+
+// DeploymentRecord is a contract deployment recorded in the lock file.
+type DeploymentRecord struct {
+	Network  string `json:"network"`
+	Contract string `json:"contract"`
+	Address  string `json:"address"`
+	// CodeHash is the hex encoded SHA256 hash of the deployed code.
+	CodeHash string `json:"codeHash"`
+	TxID     string `json:"txId"`
+	// Metadata is supplied by the caller deploying the contract, e.g. the commit the code was built from.
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+func (d DeploymentRecord) String() string {
+	keys := make([]string, 0, len(d.Metadata))
+	for key := range d.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	out := fmt.Sprintf("%s -> 0x%s on %s (tx %s, code hash %s)", d.Contract, d.Address, d.Network, d.TxID, d.CodeHash)
+	for _, key := range keys {
+		out = fmt.Sprintf("%s\n  %s: %s", out, key, d.Metadata[key])
+	}
+	return out
+}
+
+// LockFile contains the latest deployment of each contract on each account and network, in the order they were made.
+type LockFile struct {
+	Deployments []DeploymentRecord `json:"deployments"`
+	// Pins maps the network names to the addresses the imports of each contract are allowed to resolve to
+	// on the network when strict imports are enabled.
+	Pins map[string]map[string]string `json:"pins,omitempty"`
+}
+
+// Add records a deployment, replacing the previous deployment of the contract on the same account and network.
+func (l *LockFile) Add(record DeploymentRecord) {
+	deployments := make([]DeploymentRecord, 0, len(l.Deployments)+1)
+	for _, existing := range l.Deployments {
+		if existing.Network != record.Network || existing.Address != record.Address || existing.Contract != record.Contract {
+			deployments = append(deployments, existing)
+		}
+	}
+	l.Deployments = append(deployments, record)
+}
+
+// ByContract returns the recorded deployments of the contract on the network, the latest first.
+func (l *LockFile) ByContract(network string, contract string) []DeploymentRecord {
+	records := make([]DeploymentRecord, 0)
+	for i := len(l.Deployments) - 1; i >= 0; i-- {
+		record := l.Deployments[i]
+		if record.Network == network && record.Contract == contract {
+			records = append(records, record)
+		}
+	}
+	return records
+}
+
+// PinsForNetwork returns the import pins of the network, empty if none are set.
+func (l *LockFile) PinsForNetwork(network string) map[string]string {
+	pins, ok := l.Pins[network]
+	if !ok {
+		return map[string]string{}
+	}
+	return pins
+}
+
+// SetPins replaces the import pins of the network.
+func (l *LockFile) SetPins(network string, pins map[string]string) {
+	if l.Pins == nil {
+		l.Pins = make(map[string]map[string]string)
+	}
+	l.Pins[network] = pins
+}
+
+// RemoveNetwork removes all the recorded deployments on the network.
+func (l *LockFile) RemoveNetwork(network string) {
+	deployments := make([]DeploymentRecord, 0, len(l.Deployments))
+	for _, record := range l.Deployments {
+		if record.Network != network {
+			deployments = append(deployments, record)
+		}
+	}
+	l.Deployments = deployments
+}
+
+// ValidateDeploymentMetadata checks the metadata keys are valid identifiers and the metadata doesn't exceed the size limits.
+func ValidateDeploymentMetadata(metadata map[string]string) error {
+	if len(metadata) > MaxDeploymentMetadataEntries {
+		return fmt.Errorf("deployment metadata can have at most %d entries", MaxDeploymentMetadataEntries)
+	}
+
+	size := 0
+	for key, value := range metadata {
+		if !metadataKeyRegex.MatchString(key) {
+			return fmt.Errorf("invalid deployment metadata key %q, keys can only contain letters, digits, '_', '.' and '-'", key)
+		}
+		if len(value) > MaxDeploymentMetadataValueSize {
+			return fmt.Errorf("deployment metadata value for %s exceeds %d bytes", key, MaxDeploymentMetadataValueSize)
+		}
+		size += len(key) + len(value)
+	}
+
+	if size > MaxDeploymentMetadataSize {
+		return fmt.Errorf("deployment metadata exceeds %d bytes", MaxDeploymentMetadataSize)
+	}
+
+	return nil
+}
+
+// LockFile loads the lock file, if it doesn't exist yet an empty lock file is returned.
+func (p *State) LockFile() (*LockFile, error) {
+	data, err := p.readerWriter.ReadFile(DefaultLockFilePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return &LockFile{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lock file: %w", err)
+	}
+
+	var lock LockFile
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse lock file %s: %w", DefaultLockFilePath, err)
+	}
+
+	return &lock, nil
+}
+
+// SaveLockFile saves the lock file.
+func (p *State) SaveLockFile(lock *LockFile) error {
+	data, err := json.MarshalIndent(lock, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return p.readerWriter.WriteFile(DefaultLockFilePath, data, 0644)
+}
+
+// RecordDeployment adds the deployment to the lock file.
+func (p *State) RecordDeployment(record DeploymentRecord) error {
+	if err := ValidateDeploymentMetadata(record.Metadata); err != nil {
+		return err
+	}
+
+	lock, err := p.LockFile()
+	if err != nil {
+		return err
+	}
+
+	lock.Add(record)
+	return p.SaveLockFile(lock)
+}
@@ -0,0 +1,146 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package flowkit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/onflow/flow-go-sdk/crypto"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LockFile(t *testing.T) {
+	af := afero.Afero{Fs: afero.NewMemMapFs()}
+	state, err := Init(af, crypto.ECDSA_P256, crypto.SHA3_256)
+	require.NoError(t, err)
+
+	t.Run("Missing lock file", func(t *testing.T) {
+		lock, err := state.LockFile()
+		require.NoError(t, err)
+		assert.Len(t, lock.Deployments, 0)
+	})
+
+	t.Run("Round trip", func(t *testing.T) {
+		first := DeploymentRecord{
+			Network:  "testnet",
+			Contract: "Foo",
+			Address:  "01cf0e2f2f715450",
+			CodeHash: "aa",
+			TxID:     "01",
+			Metadata: map[string]string{"commit": "3f2a1b", "ci.run-url": "https://ci.example.com/runs/1"},
+		}
+		second := DeploymentRecord{
+			Network:  "testnet",
+			Contract: "Foo",
+			Address:  "01cf0e2f2f715450",
+			CodeHash: "bb",
+			TxID:     "02",
+		}
+		other := DeploymentRecord{Network: "mainnet", Contract: "Foo", CodeHash: "cc"}
+		otherAccount := DeploymentRecord{Network: "testnet", Contract: "Foo", Address: "179b6b1cb6755e31", CodeHash: "dd"}
+
+		require.NoError(t, state.RecordDeployment(first))
+		require.NoError(t, state.RecordDeployment(other))
+		require.NoError(t, state.RecordDeployment(otherAccount))
+		require.NoError(t, state.RecordDeployment(second))
+
+		exists, err := af.Exists(DefaultLockFilePath)
+		require.NoError(t, err)
+		assert.True(t, exists)
+
+		// only the latest deployment of the contract on the account is kept
+		lock, err := state.LockFile()
+		require.NoError(t, err)
+		assert.Equal(t, []DeploymentRecord{other, otherAccount, second}, lock.Deployments)
+		assert.Equal(t, []DeploymentRecord{second, otherAccount}, lock.ByContract("testnet", "Foo"))
+		assert.Len(t, lock.ByContract("testnet", "Bar"), 0)
+
+		lock.RemoveNetwork("testnet")
+		assert.Equal(t, []DeploymentRecord{other}, lock.Deployments)
+	})
+
+	t.Run("Pins", func(t *testing.T) {
+		lock, err := state.LockFile()
+		require.NoError(t, err)
+		assert.Empty(t, lock.PinsForNetwork("testnet"))
+
+		lock.SetPins("testnet", map[string]string{"Foo": "01cf0e2f2f715450"})
+		require.NoError(t, state.SaveLockFile(lock))
+
+		lock, err = state.LockFile()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"Foo": "01cf0e2f2f715450"}, lock.PinsForNetwork("testnet"))
+		assert.Empty(t, lock.PinsForNetwork("mainnet"))
+	})
+
+	t.Run("Invalid metadata not recorded", func(t *testing.T) {
+		err := state.RecordDeployment(DeploymentRecord{
+			Network:  "testnet",
+			Contract: "Bar",
+			Metadata: map[string]string{"commit sha": "3f2a1b"},
+		})
+		assert.Error(t, err)
+
+		lock, err := state.LockFile()
+		require.NoError(t, err)
+		assert.Len(t, lock.ByContract("testnet", "Bar"), 0)
+	})
+}
+
+func Test_ValidateDeploymentMetadata(t *testing.T) {
+	tooMany := make(map[string]string)
+	for i := 0; i <= MaxDeploymentMetadataEntries; i++ {
+		tooMany[strings.Repeat("k", i+1)] = "v"
+	}
+
+	tooLarge := make(map[string]string)
+	for i := 0; i < 5; i++ {
+		tooLarge[strings.Repeat("k", i+1)] = strings.Repeat("v", MaxDeploymentMetadataValueSize)
+	}
+
+	tests := []struct {
+		name     string
+		metadata map[string]string
+		err      string
+	}{
+		{name: "empty", metadata: nil},
+		{name: "valid", metadata: map[string]string{"commit": "3f2a1b", "ci.run_url": "https://ci", "build-id": "7"}},
+		{name: "invalid key", metadata: map[string]string{"commit/sha": "3f2a1b"}, err: `invalid deployment metadata key "commit/sha"`},
+		{name: "empty key", metadata: map[string]string{"": "3f2a1b"}, err: `invalid deployment metadata key ""`},
+		{name: "too long key", metadata: map[string]string{strings.Repeat("k", 65): "v"}, err: "invalid deployment metadata key"},
+		{name: "too many entries", metadata: tooMany, err: "deployment metadata can have at most 32 entries"},
+		{name: "value too large", metadata: map[string]string{"notes": strings.Repeat("v", MaxDeploymentMetadataValueSize+1)}, err: "deployment metadata value for notes exceeds 1024 bytes"},
+		{name: "too large", metadata: tooLarge, err: "deployment metadata exceeds 4096 bytes"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateDeploymentMetadata(test.metadata)
+			if test.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.err)
+		})
+	}
+}
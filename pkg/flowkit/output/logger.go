@@ -27,14 +27,40 @@ const (
 	ErrorLog = 1
 	DebugLog = 2
 	InfoLog  = 3
+	WarnLog  = 4
 )
 
+// logVerbosity orders the log levels by the messages they log, warnings are logged by all levels logging more than errors.
+var logVerbosity = map[int]int{
+	NoneLog:  0,
+	ErrorLog: 1,
+	WarnLog:  2,
+	DebugLog: 3,
+	InfoLog:  4,
+}
+
 type Logger interface {
 	Debug(string)
 	Info(string)
 	Error(string)
 	StartProgress(string)
 	StopProgress()
+	StartProgressSteps(label string, total int) ProgressSteps
+}
+
+// WarnLogger is implemented by loggers reporting warnings separately from infos.
+type WarnLogger interface {
+	Warn(string)
+}
+
+// Warn logs the message as a warning if the logger reports warnings, as an info otherwise.
+func Warn(logger Logger, msg string) {
+	if warnLogger, ok := logger.(WarnLogger); ok {
+		warnLogger.Warn(msg)
+		return
+	}
+
+	logger.Info(msg)
 }
 
 // NewStdoutLogger returns a new stdout logger.
@@ -45,15 +71,17 @@ func NewStdoutLogger(level int) *StdoutLogger {
 }
 
 var _ Logger = &StdoutLogger{}
+var _ WarnLogger = &StdoutLogger{}
 
 // StdoutLogger is a stdout logging implementation.
 type StdoutLogger struct {
 	level   int
 	spinner *Spinner
+	steps   *stdoutProgressSteps
 }
 
 func (s *StdoutLogger) log(msg string, level int) {
-	if s.level < level {
+	if logVerbosity[s.level] < logVerbosity[level] {
 		return
 	}
 
@@ -72,11 +100,19 @@ func (s *StdoutLogger) Error(msg string) {
 	s.log(fmt.Sprintf("%s %s", ErrorEmoji(), Red(msg)), ErrorLog)
 }
 
+func (s *StdoutLogger) Warn(msg string) {
+	s.log(fmt.Sprintf("%s%s", WarningEmoji(), msg), WarnLog)
+}
+
 func (s *StdoutLogger) StartProgress(msg string) {
 	if s.level == NoneLog {
 		return
 	}
 
+	s.startSpinner(msg)
+}
+
+func (s *StdoutLogger) startSpinner(msg string) {
 	if s.spinner != nil {
 		s.spinner.Stop()
 	}
@@ -95,3 +131,25 @@ func (s *StdoutLogger) StopProgress() {
 		s.spinner = nil
 	}
 }
+
+// StartProgressSteps starts reporting progress of an operation consisting of total steps.
+//
+// In a terminal the current step is shown as "[7/20] Deploying Marketplace..." with a spinner,
+// otherwise a structured line is printed for each step. Starting steps while another
+// steps progress is active nests the new steps under the current step of the active one.
+func (s *StdoutLogger) StartProgressSteps(label string, total int) ProgressSteps {
+	if s.level == NoneLog {
+		return NoopProgressSteps{}
+	}
+
+	steps := &stdoutProgressSteps{
+		logger:      s,
+		parent:      s.steps,
+		title:       label,
+		total:       total,
+		interactive: isInteractive(),
+	}
+	s.steps = steps
+
+	return steps
+}
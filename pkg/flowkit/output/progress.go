@@ -0,0 +1,124 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package output
+
+import (
+	"fmt"
+	"os"
+)
+
+// ProgressSteps reports the progress of an operation made of a known number of steps.
+type ProgressSteps interface {
+	// Increment starts the next step described by the step label.
+	Increment(stepLabel string)
+	// Complete finishes the operation and releases the progress output.
+	Complete()
+}
+
+// NoopProgressSteps is a progress steps implementation that doesn't report anything.
+type NoopProgressSteps struct{}
+
+func (NoopProgressSteps) Increment(string) {}
+
+func (NoopProgressSteps) Complete() {}
+
+// isInteractive returns true if stdout is attached to a terminal.
+func isInteractive() bool {
+	info, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// stdoutProgressSteps renders step progress with a spinner when running in a terminal
+// and as one structured line per step otherwise.
+//
+// Steps started while another steps progress is active are rendered as part of
+// the parent progress instead of replacing it.
+type stdoutProgressSteps struct {
+	logger      *StdoutLogger
+	parent      *stdoutProgressSteps
+	title       string
+	step        string
+	total       int
+	current     int
+	interactive bool
+}
+
+// message returns the current step description, prefixed with the parent step if nested.
+func (p *stdoutProgressSteps) message() string {
+	msg := fmt.Sprintf("[%d/%d] %s", p.current, p.total, p.step)
+	if p.parent != nil && p.parent.current > 0 {
+		msg = fmt.Sprintf("%s > %s", p.parent.message(), msg)
+	}
+	return msg
+}
+
+func (p *stdoutProgressSteps) rootTitle() string {
+	if p.parent != nil {
+		return p.parent.rootTitle()
+	}
+	return p.title
+}
+
+func (p *stdoutProgressSteps) percent() int {
+	if p.total == 0 {
+		return 100
+	}
+	return p.current * 100 / p.total
+}
+
+func (p *stdoutProgressSteps) Increment(stepLabel string) {
+	if p.current < p.total {
+		p.current++
+	}
+	p.step = stepLabel
+
+	if !p.interactive {
+		p.logger.log(fmt.Sprintf(
+			"progress=%q step=%d total=%d percent=%d label=%q",
+			p.rootTitle(),
+			p.current,
+			p.total,
+			p.percent(),
+			p.message(),
+		), InfoLog)
+		return
+	}
+
+	p.logger.startSpinner(fmt.Sprintf("%s... ", p.message()))
+}
+
+func (p *stdoutProgressSteps) Complete() {
+	if p.logger.steps != p {
+		return // already completed or not the innermost progress
+	}
+	p.logger.steps = p.parent
+
+	if !p.interactive {
+		return
+	}
+
+	if p.parent != nil && p.parent.current > 0 {
+		p.logger.startSpinner(fmt.Sprintf("%s... ", p.parent.message()))
+		return
+	}
+	p.logger.StopProgress()
+}
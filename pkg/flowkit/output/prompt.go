@@ -33,19 +33,20 @@ import (
 	"github.com/onflow/flow-cli/pkg/flowkit/util"
 )
 
-func ApproveTransactionForSigningPrompt(transaction *flowkit.Transaction) bool {
-	return ApproveTransactionPrompt(transaction, "⚠️  Do you want to SIGN this transaction?")
+func ApproveTransactionForSigningPrompt(transaction *flowkit.Transaction, redactor *flowkit.Redactor) bool {
+	return ApproveTransactionPrompt(transaction, redactor, "⚠️  Do you want to SIGN this transaction?")
 }
 
-func ApproveTransactionForBuildingPrompt(transaction *flowkit.Transaction) bool {
-	return ApproveTransactionPrompt(transaction, "⚠️  Do you want to BUILD this transaction?")
+func ApproveTransactionForBuildingPrompt(transaction *flowkit.Transaction, redactor *flowkit.Redactor) bool {
+	return ApproveTransactionPrompt(transaction, redactor, "⚠️  Do you want to BUILD this transaction?")
 }
 
-func ApproveTransactionForSendingPrompt(transaction *flowkit.Transaction) bool {
-	return ApproveTransactionPrompt(transaction, "⚠️  Do you want to SEND this transaction?")
+func ApproveTransactionForSendingPrompt(transaction *flowkit.Transaction, redactor *flowkit.Redactor) bool {
+	return ApproveTransactionPrompt(transaction, redactor, "⚠️  Do you want to SEND this transaction?")
 }
 
-func ApproveTransactionPrompt(transaction *flowkit.Transaction, promptMsg string) bool {
+// ApproveTransactionPrompt displays the transaction with the arguments redacted by the redactor and asks for approval.
+func ApproveTransactionPrompt(transaction *flowkit.Transaction, redactor *flowkit.Redactor, promptMsg string) bool {
 	writer := uilive.New()
 	tx := transaction.FlowTransaction()
 
@@ -86,7 +87,7 @@ func ApproveTransactionPrompt(transaction *flowkit.Transaction, promptMsg string
 			_, _ = fmt.Fprintf(writer, "\n\nArguments\tNo arguments\n")
 		} else {
 			_, _ = fmt.Fprintf(writer, "\n\nArguments (%d):\n", len(tx.Arguments))
-			for i, argument := range tx.Arguments {
+			for i, argument := range redactor.Arguments(tx) {
 				_, _ = fmt.Fprintf(writer, "    - Argument %d: %s\n", i, argument)
 			}
 		}
@@ -575,6 +576,35 @@ func WantToContinue() bool {
 	return strings.ToLower(selected) == "y"
 }
 
+// ConfirmNetworkOperationPrompt asks the user to confirm an operation with irreversible costs on the network.
+func ConfirmNetworkOperationPrompt(network string) bool {
+	prompt := promptui.Prompt{
+		Label:     fmt.Sprintf("This operation on %s has irreversible costs, do you want to continue", network),
+		IsConfirm: true,
+	}
+	selected, err := prompt.Run()
+	if err == promptui.ErrInterrupt {
+		os.Exit(-1)
+	}
+
+	return strings.ToLower(selected) == "y"
+}
+
+// ConfirmRemoveContractPrompt shows the values referencing the contract and asks the user to confirm its removal.
+func ConfirmRemoveContractPrompt(contract string, references string) bool {
+	fmt.Println(references)
+	prompt := promptui.Prompt{
+		Label:     fmt.Sprintf("Removing %s leaves the values above orphaned, do you want to continue", contract),
+		IsConfirm: true,
+	}
+	selected, err := prompt.Run()
+	if err == promptui.ErrInterrupt {
+		os.Exit(-1)
+	}
+
+	return strings.ToLower(selected) == "y"
+}
+
 func ConfirmOpenBrowser() {
 	prompt := promptui.Prompt{
 		Label:       "Press <ENTER> to open in your browser...",
@@ -0,0 +1,370 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package project
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/onflow/cadence/runtime/ast"
+)
+
+// bindingKind is the kind of program bindings are generated for.
+type bindingKind string
+
+const (
+	bindingTransaction bindingKind = "transaction"
+	bindingScript      bindingKind = "script"
+)
+
+// bindingImports are the packages the generated code can import, by their name.
+var bindingImports = map[string]string{
+	"fmt":     "fmt",
+	"cadence": "github.com/onflow/cadence",
+	"flow":    "github.com/onflow/flow-go-sdk",
+	"flowkit": "github.com/onflow/flow-cli/pkg/flowkit",
+}
+
+// reservedBindingNames can't be used for parameters of the generated constructors, since they would shadow the
+// imported packages or the variables of the generated code.
+var reservedBindingNames = map[string]bool{
+	"b": true, "args": true, "err": true, "fmt": true, "cadence": true, "flow": true, "flowkit": true,
+}
+
+// GenerateBindings generates the Go source of a package with typed bindings for the transactions and scripts.
+//
+// For each program a struct is generated with a field per Cadence parameter, named after the program file.
+// Address, String, UFix64, Int and Bool parameters, as well as arrays and optionals of those, are mapped
+// to Go types, other parameters are kept as cadence.Value fields. The generated constructor takes the
+// required, non-optional, parameters and validates them, and the Script method returns the flowkit.Script
+// with the encoded arguments, to be sent with the Transactions service or executed with the Scripts service.
+func GenerateBindings(programs []*Program, packageName string) ([]byte, error) {
+	if !token.IsIdentifier(packageName) {
+		return nil, fmt.Errorf("invalid package name %s", packageName)
+	}
+
+	g := &bindingsGenerator{imports: map[string]bool{"cadence": true, "flowkit": true}}
+	names := make(map[string]string)
+
+	for _, program := range programs {
+		binding, err := newProgramBinding(program)
+		if err != nil {
+			return nil, err
+		}
+		if location, exists := names[binding.name]; exists {
+			return nil, fmt.Errorf(
+				"programs %s and %s both generate bindings named %s",
+				location,
+				program.Location(),
+				binding.name,
+			)
+		}
+		names[binding.name] = program.Location()
+
+		g.generate(binding)
+	}
+
+	var out bytes.Buffer
+	out.WriteString("// Code generated by flowkit. DO NOT EDIT.\n\n")
+	out.WriteString(fmt.Sprintf("package %s\n\nimport (\n", packageName))
+	if g.imports["fmt"] {
+		out.WriteString("\t\"fmt\"\n\n")
+	}
+	for _, name := range []string{"cadence", "flow", "flowkit"} {
+		if g.imports[name] {
+			out.WriteString(fmt.Sprintf("\t%q\n", bindingImports[name]))
+		}
+	}
+	out.WriteString(")\n")
+	out.Write(g.body.Bytes())
+
+	source, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated bindings: %w", err)
+	}
+
+	return source, nil
+}
+
+// programBinding describes the bindings generated for a program.
+type programBinding struct {
+	name       string
+	kind       bindingKind
+	program    *Program
+	parameters []bindingParameter
+}
+
+type bindingParameter struct {
+	name      string
+	field     string
+	argument  string
+	typ       ast.Type
+	goType    string
+	supported bool
+}
+
+func newProgramBinding(program *Program) (*programBinding, error) {
+	var kind bindingKind
+	var parameterList *ast.ParameterList
+
+	transactions := program.astProgram.TransactionDeclarations()
+	switch {
+	case len(transactions) == 1:
+		kind = bindingTransaction
+		parameterList = transactions[0].ParameterList
+	case len(transactions) > 1:
+		return nil, fmt.Errorf("program %s declares more than one transaction", program.Location())
+	default:
+		for _, function := range program.astProgram.FunctionDeclarations() {
+			if function.Identifier.Identifier == "main" {
+				kind = bindingScript
+				parameterList = function.ParameterList
+			}
+		}
+	}
+	if kind == "" {
+		return nil, fmt.Errorf("program %s is neither a transaction nor a script", program.Location())
+	}
+
+	name := exportedIdentifier(strings.TrimSuffix(path.Base(program.Location()), path.Ext(program.Location())))
+	if name == "" {
+		return nil, fmt.Errorf("can't derive a bindings name from the location %s", program.Location())
+	}
+
+	binding := &programBinding{
+		name:       name,
+		kind:       kind,
+		program:    program,
+		parameters: make([]bindingParameter, 0),
+	}
+	if parameterList == nil {
+		return binding, nil
+	}
+
+	for _, param := range parameterList.Parameters {
+		name := param.Identifier.Identifier
+		field := exportedIdentifier(name)
+		if field == "Arguments" || field == "Script" {
+			field += "Param"
+		}
+		argument := name
+		if token.IsKeyword(argument) || reservedBindingNames[argument] {
+			argument += "Arg"
+		}
+
+		goType, supported := bindingGoType(param.TypeAnnotation.Type)
+		binding.parameters = append(binding.parameters, bindingParameter{
+			name:      name,
+			field:     field,
+			argument:  argument,
+			typ:       param.TypeAnnotation.Type,
+			goType:    goType,
+			supported: supported,
+		})
+	}
+
+	return binding, nil
+}
+
+// bindingGoType returns the Go type a Cadence parameter type is bound to and whether the type is supported.
+func bindingGoType(typ ast.Type) (string, bool) {
+	switch t := typ.(type) {
+	case *ast.NominalType:
+		if len(t.NestedIdentifiers) > 0 {
+			return "cadence.Value", false
+		}
+		switch t.Identifier.Identifier {
+		case "Address":
+			return "flow.Address", true
+		case "String", "UFix64":
+			return "string", true
+		case "Int":
+			return "int", true
+		case "Bool":
+			return "bool", true
+		}
+	case *ast.OptionalType:
+		if inner, ok := bindingGoType(t.Type); ok {
+			return "*" + inner, true
+		}
+	case *ast.VariableSizedType:
+		if inner, ok := bindingGoType(t.Type); ok {
+			return "[]" + inner, true
+		}
+	}
+
+	return "cadence.Value", false
+}
+
+// exportedIdentifier converts a file or parameter name to an exported Go identifier,
+// e.g. transfer_tokens becomes TransferTokens.
+func exportedIdentifier(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var out strings.Builder
+	for _, part := range parts {
+		runes := []rune(part)
+		runes[0] = unicode.ToUpper(runes[0])
+		out.WriteString(string(runes))
+	}
+
+	identifier := out.String()
+	if identifier != "" && unicode.IsDigit([]rune(identifier)[0]) {
+		identifier = "Program" + identifier
+	}
+	return identifier
+}
+
+type bindingsGenerator struct {
+	body    bytes.Buffer
+	imports map[string]bool
+}
+
+func (g *bindingsGenerator) printf(format string, args ...any) {
+	g.body.WriteString(fmt.Sprintf(format, args...))
+}
+
+func (g *bindingsGenerator) generate(b *programBinding) {
+	name := b.name
+	location := b.program.Location()
+
+	g.printf("\n// %sLocation is the location of the %s the %s bindings are generated from.\n", name, b.kind, name)
+	g.printf("const %sLocation = %s\n", name, strconv.Quote(location))
+	g.printf("\n// %sCode is the code of the %s %s.\n", name, b.kind, location)
+	g.printf("const %sCode = %s\n", name, strconv.Quote(string(b.program.Code())))
+
+	g.printf("\n// %s binds the parameters of the %s %s.\n", name, b.kind, location)
+	if len(b.parameters) == 0 {
+		g.printf("type %s struct{}\n", name)
+	} else {
+		g.generateFields(b)
+	}
+
+	g.generateConstructor(b)
+
+	g.printf("\n// Arguments encodes the parameters as the Cadence arguments of the %s.\n", b.kind)
+	g.printf("func (b *%s) Arguments() ([]cadence.Value, error) {\n", name)
+	g.printf("\targs := make([]cadence.Value, %d)\n", len(b.parameters))
+	for i, param := range b.parameters {
+		g.encode(fmt.Sprintf("args[%d]", i), "b."+param.field, param.typ, param.name, 0)
+	}
+	g.printf("\treturn args, nil\n}\n")
+
+	g.printf("\n// Script returns the %s with the encoded arguments.\n", b.kind)
+	g.printf("func (b *%s) Script() (*flowkit.Script, error) {\n", name)
+	g.printf("\targs, err := b.Arguments()\n\tif err != nil {\n\t\treturn nil, err\n\t}\n")
+	g.printf("\treturn flowkit.NewScript([]byte(%sCode), args, %sLocation), nil\n}\n", name, name)
+}
+
+func (g *bindingsGenerator) generateFields(b *programBinding) {
+	g.printf("type %s struct {\n", b.name)
+	for _, param := range b.parameters {
+		if !param.supported {
+			g.printf("\t// %s has the Cadence type %s which has no Go binding, the value is passed as is.\n", param.field, param.typ.String())
+		}
+		g.printf("\t%s %s\n", param.field, param.goType)
+	}
+	g.printf("}\n")
+}
+
+func (g *bindingsGenerator) generateConstructor(b *programBinding) {
+	required := make([]bindingParameter, 0)
+	for _, param := range b.parameters {
+		// optionals of unsupported types aren't bound to pointers, so they are required as Cadence optionals
+		if _, optional := param.typ.(*ast.OptionalType); !optional || !param.supported {
+			required = append(required, param)
+		}
+	}
+
+	arguments := make([]string, len(required))
+	for i, param := range required {
+		arguments[i] = fmt.Sprintf("%s %s", param.argument, param.goType)
+	}
+
+	g.printf("\n// New%s returns the %s bindings with the required parameters, optional parameters can be set on the returned bindings.\n", b.name, b.kind)
+	g.printf("func New%s(%s) (*%s, error) {\n", b.name, strings.Join(arguments, ", "), b.name)
+	g.printf("\tb := &%s{\n", b.name)
+	for _, param := range required {
+		g.printf("\t\t%s: %s,\n", param.field, param.argument)
+	}
+	g.printf("\t}\n")
+
+	for _, param := range required {
+		if param.goType == "flow.Address" {
+			g.imports["fmt"] = true
+			g.printf("\tif %s == flow.EmptyAddress {\n", param.argument)
+			g.printf("\t\treturn nil, fmt.Errorf(\"parameter %s is required\")\n\t}\n", param.name)
+		}
+	}
+
+	g.printf("\tif _, err := b.Arguments(); err != nil {\n\t\treturn nil, err\n\t}\n")
+	g.printf("\treturn b, nil\n}\n")
+}
+
+// encode generates the statements assigning the Cadence value of the Go expression with the Cadence type to the target.
+func (g *bindingsGenerator) encode(target string, expr string, typ ast.Type, param string, depth int) {
+	if _, supported := bindingGoType(typ); !supported {
+		g.imports["fmt"] = true
+		g.printf("\tif %s == nil {\n\t\treturn nil, fmt.Errorf(\"parameter %s is required\")\n\t}\n", expr, param)
+		g.printf("\t%s = %s\n", target, expr)
+		return
+	}
+
+	switch t := typ.(type) {
+	case *ast.NominalType:
+		switch t.Identifier.Identifier {
+		case "Address":
+			g.imports["flow"] = true
+			g.printf("\t%s = cadence.NewAddress(%s)\n", target, expr)
+		case "String", "UFix64":
+			g.imports["fmt"] = true
+			constructor := map[string]string{"String": "cadence.NewString", "UFix64": "cadence.NewUFix64"}[t.Identifier.Identifier]
+			value := fmt.Sprintf("value%d", depth)
+			g.printf("\t{\n\t\t%s, err := %s(%s)\n", value, constructor, expr)
+			g.printf("\t\tif err != nil {\n\t\t\treturn nil, fmt.Errorf(\"invalid parameter %s: %%w\", err)\n\t\t}\n", param)
+			g.printf("\t\t%s = %s\n\t}\n", target, value)
+		case "Int":
+			g.printf("\t%s = cadence.NewInt(%s)\n", target, expr)
+		case "Bool":
+			g.printf("\t%s = cadence.NewBool(%s)\n", target, expr)
+		}
+	case *ast.OptionalType:
+		inner := fmt.Sprintf("inner%d", depth)
+		g.printf("\tif %s == nil {\n\t\t%s = cadence.NewOptional(nil)\n\t} else {\n", expr, target)
+		g.printf("\tvar %s cadence.Value\n", inner)
+		g.encode(inner, "*"+expr, t.Type, param, depth+1)
+		g.printf("\t%s = cadence.NewOptional(%s)\n\t}\n", target, inner)
+	case *ast.VariableSizedType:
+		values := fmt.Sprintf("values%d", depth)
+		index := fmt.Sprintf("i%d", depth)
+		element := fmt.Sprintf("element%d", depth)
+		g.printf("\t{\n\t%s := make([]cadence.Value, len(%s))\n", values, expr)
+		g.printf("\tfor %s, %s := range %s {\n", index, element, expr)
+		g.encode(fmt.Sprintf("%s[%s]", values, index), element, t.Type, param, depth+1)
+		g.printf("\t}\n\t%s = cadence.NewArray(%s)\n\t}\n", target, values)
+	}
+}
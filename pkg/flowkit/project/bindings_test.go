@@ -0,0 +1,96 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestProgram(t *testing.T, location string, code string) *Program {
+	program, err := NewProgram(&testScript{code: []byte(code), location: location})
+	require.NoError(t, err)
+	return program
+}
+
+func TestGenerateBindings(t *testing.T) {
+
+	// the generated bindings of the fixtures are compiled and tested in the bindingstest package
+	t.Run("Golden", func(t *testing.T) {
+		dir := filepath.Join("internal", "bindingstest")
+		programs := make([]*Program, 0)
+		for _, file := range []string{"get_balances.cdc", "setup.cdc", "transfer_tokens.cdc"} {
+			code, err := os.ReadFile(filepath.Join(dir, file))
+			require.NoError(t, err)
+			programs = append(programs, newTestProgram(t, file, string(code)))
+		}
+
+		generated, err := GenerateBindings(programs, "bindingstest")
+		require.NoError(t, err)
+
+		golden, err := os.ReadFile(filepath.Join(dir, "bindings.go"))
+		require.NoError(t, err)
+		assert.Equal(t, string(golden), string(generated))
+	})
+
+	t.Run("Reserved Parameter Names", func(t *testing.T) {
+		program := newTestProgram(t, "./scripts/get-range.cdc", `
+			pub fun main(range: Int, args: String, script: Bool, flow: Address): Int {
+				return range
+			}
+		`)
+
+		generated, err := GenerateBindings([]*Program{program}, "scripts")
+		require.NoError(t, err)
+		assert.Contains(t, string(generated), "func NewGetRange(rangeArg int, argsArg string, script bool, flowArg flow.Address) (*GetRange, error) {")
+		assert.Contains(t, string(generated), "\tScriptParam bool\n")
+	})
+
+	t.Run("Unsupported Types", func(t *testing.T) {
+		program := newTestProgram(t, "tx.cdc", `
+			transaction(amounts: {String: UFix64}, path: StoragePath?, count: UInt8) {}
+		`)
+
+		generated, err := GenerateBindings([]*Program{program}, "transactions")
+		require.NoError(t, err)
+		assert.Contains(t, string(generated), "// Amounts has the Cadence type {String: UFix64} which has no Go binding, the value is passed as is.\n\tAmounts cadence.Value")
+		assert.Contains(t, string(generated), "\tPath cadence.Value\n")
+		assert.Contains(t, string(generated), "\tCount cadence.Value\n")
+		assert.Contains(t, string(generated), "func NewTx(amounts cadence.Value, path cadence.Value, count cadence.Value) (*Tx, error) {")
+	})
+
+	t.Run("Invalid Programs", func(t *testing.T) {
+		script := newTestProgram(t, "./a/run.cdc", `pub fun main() {}`)
+		duplicate := newTestProgram(t, "./b/run.cdc", `transaction {}`)
+		contract := newTestProgram(t, "./Foo.cdc", `pub contract Foo {}`)
+
+		_, err := GenerateBindings([]*Program{script, duplicate}, "bindings")
+		assert.EqualError(t, err, "programs ./a/run.cdc and ./b/run.cdc both generate bindings named Run")
+
+		_, err = GenerateBindings([]*Program{contract}, "bindings")
+		assert.EqualError(t, err, "program ./Foo.cdc is neither a transaction nor a script")
+
+		_, err = GenerateBindings([]*Program{script}, "invalid-name")
+		assert.EqualError(t, err, "invalid package name invalid-name")
+	})
+}
@@ -19,6 +19,8 @@
 package project
 
 import (
+	"time"
+
 	"github.com/onflow/cadence"
 	"github.com/onflow/flow-go-sdk"
 )
@@ -31,6 +33,8 @@ type Contract struct {
 	AccountAddress flow.Address
 	AccountName    string
 	Args           []cadence.Value
+	// DeploymentDuration is the wall-clock time it took to deploy the contract, set after deployment.
+	DeploymentDuration time.Duration
 }
 
 func NewContract(
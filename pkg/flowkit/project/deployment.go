@@ -20,7 +20,9 @@ package project
 
 import (
 	"fmt"
+	"sort"
 
+	"github.com/onflow/flow-go-sdk"
 	"gonum.org/v1/gonum/graph"
 	"gonum.org/v1/gonum/graph/simple"
 	"gonum.org/v1/gonum/graph/topo"
@@ -51,6 +53,7 @@ type Deployment struct {
 	// map of contracts by their location specified in state
 	contractsByLocation map[string]*deployContract
 	contractsByName     map[string]*deployContract
+	root                string
 }
 
 // NewDeployment from the flowkit Contracts and loaded from the contract location using a loader.
@@ -70,6 +73,12 @@ func NewDeployment(contracts []*Contract) (*Deployment, error) {
 	return deployment, nil
 }
 
+// SetRoot bounds the path imports of the contracts to the project root, sorting fails if an import
+// resolves to a location outside of it. Imports are not bounded if the root is empty.
+func (d *Deployment) SetRoot(root string) {
+	d.root = root
+}
+
 func (d *Deployment) add(contract *Contract) error {
 	program, err := NewProgram(contract)
 	if err != nil {
@@ -100,12 +109,7 @@ func (d *Deployment) Sort() ([]*Contract, error) {
 		return nil, fmt.Errorf("the same contract cannot be deployed to multiple accounts on the same network")
 	}
 
-	err := d.buildDependencies()
-	if err != nil {
-		return nil, err
-	}
-
-	sorted, err := sortByDeploymentOrder(d.contracts)
+	sorted, err := d.sort()
 	if err != nil {
 		return nil, err
 	}
@@ -118,6 +122,77 @@ func (d *Deployment) Sort() ([]*Contract, error) {
 	return contracts, nil
 }
 
+// Batches returns contracts in deployment order grouped into batches that can each be deployed in a single transaction.
+//
+// All contracts in a batch are deployed to the same account. A contract is only added to the latest batch
+// of its account if all of its dependencies are deployed in that same batch or in any of the previous batches,
+// otherwise a new batch is started. Batches must be deployed in the order returned.
+func (d *Deployment) Batches() ([][]*Contract, error) {
+	if d.conflictExists() {
+		return nil, fmt.Errorf("the same contract cannot be deployed to multiple accounts on the same network")
+	}
+
+	sorted, err := d.sort()
+	if err != nil {
+		return nil, err
+	}
+
+	batches := make([][]*Contract, 0)
+	contractBatch := make(map[*deployContract]int)
+	accountBatch := make(map[flow.Address]int)
+
+	for _, contract := range sorted {
+		index, exists := accountBatch[contract.AccountAddress]
+		if exists {
+			for _, dep := range contract.dependencies {
+				if contractBatch[dep] > index {
+					exists = false
+					break
+				}
+			}
+		}
+
+		if !exists {
+			batches = append(batches, make([]*Contract, 0))
+			index = len(batches) - 1
+			accountBatch[contract.AccountAddress] = index
+		}
+
+		batches[index] = append(batches[index], contract.Contract)
+		contractBatch[contract] = index
+	}
+
+	return batches, nil
+}
+
+// Dependencies returns the contracts imported by the contract, only available after the contracts are sorted.
+func (d *Deployment) Dependencies(contract *Contract) []*Contract {
+	c, ok := d.contractsByName[contract.Name]
+	if !ok {
+		return nil
+	}
+
+	dependencies := make([]*Contract, 0, len(c.dependencies))
+	for _, dep := range c.dependencies {
+		dependencies = append(dependencies, dep.Contract)
+	}
+	sort.Slice(dependencies, func(i, j int) bool {
+		return dependencies[i].Name < dependencies[j].Name
+	})
+
+	return dependencies
+}
+
+// sort builds the dependencies of the contracts and sorts them by deployment order.
+func (d *Deployment) sort() ([]*deployContract, error) {
+	err := d.buildDependencies()
+	if err != nil {
+		return nil, err
+	}
+
+	return sortByDeploymentOrder(d.contracts)
+}
+
 // conflictExists returns true if the same contract is configured to deploy to more than one account for the same network.
 func (d *Deployment) conflictExists() bool {
 	uniq := make(map[string]bool)
@@ -136,7 +211,10 @@ func (d *Deployment) buildDependencies() error {
 	for _, contract := range d.contracts {
 		for _, location := range contract.program.imports() {
 			// find contract by the path import
-			importPath := absolutePath(contract.location, location)
+			importPath, err := absolutePathWithRoot(d.root, contract.location, location)
+			if err != nil {
+				return err
+			}
 			importContract, isPath := d.contractsByLocation[importPath]
 			if isPath {
 				contract.addDependency(location, importContract)
@@ -243,3 +243,131 @@ func TestContractDeploymentOrder(t *testing.T) {
 		})
 	}
 }
+
+func TestContractDeploymentBatches(t *testing.T) {
+	account1 := flow.HexToAddress("0x01")
+	account2 := flow.HexToAddress("0x02")
+
+	newContracts := func(contracts []testContract, accounts []flow.Address) []*Contract {
+		result := make([]*Contract, len(contracts))
+		for i, contract := range contracts {
+			result[i] = NewContract(
+				strings.Split(contract.location, ".")[0],
+				contract.location,
+				contract.code,
+				accounts[i],
+				"",
+				nil,
+			)
+		}
+		return result
+	}
+
+	testCases := []struct {
+		name      string
+		contracts []testContract
+		accounts  []flow.Address
+		expected  [][]string
+	}{
+		{
+			name:      "Same account with imports",
+			contracts: []testContract{testContractA, testContractC, testContractD},
+			accounts:  []flow.Address{account1, account1, account1},
+			expected:  [][]string{{"ContractA", "ContractC", "ContractD"}},
+		},
+		{
+			name:      "Two accounts no imports",
+			contracts: []testContract{testContractA, testContractB},
+			accounts:  []flow.Address{account1, account2},
+			expected:  [][]string{{"ContractA"}, {"ContractB"}},
+		},
+		{
+			name:      "Dependency deployed to other account in between",
+			contracts: []testContract{testContractA, testContractC, testContractD},
+			accounts:  []flow.Address{account1, account2, account1},
+			expected:  [][]string{{"ContractA"}, {"ContractC"}, {"ContractD"}},
+		},
+		{
+			name:      "Dependency deployed to other account before",
+			contracts: []testContract{testContractB, testContractA, testContractG},
+			accounts:  []flow.Address{account2, account1, account1},
+			expected:  [][]string{{"ContractB"}, {"ContractA", "ContractG"}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			deployment, err := NewDeployment(newContracts(testCase.contracts, testCase.accounts))
+			require.NoError(t, err)
+
+			batches, err := deployment.Batches()
+			require.NoError(t, err)
+			require.Len(t, batches, len(testCase.expected))
+
+			for i, batch := range batches {
+				names := make([]string, len(batch))
+				for j, contract := range batch {
+					names[j] = contract.Name
+				}
+				assert.Equal(t, testCase.expected[i], names)
+			}
+		})
+	}
+
+	t.Run("Import cycle", func(t *testing.T) {
+		deployment, err := NewDeployment(newContracts(
+			[]testContract{testContractE, testContractF},
+			[]flow.Address{account1, account1},
+		))
+		require.NoError(t, err)
+
+		_, err = deployment.Batches()
+		assert.IsType(t, &CyclicImportError{}, err)
+	})
+
+	t.Run("Import outside root", func(t *testing.T) {
+		escaping := testContract{
+			location: "ContractEscape.cdc",
+			code: []byte(`
+				import ContractA from "../ContractA.cdc"
+
+				pub contract ContractEscape {}
+			`),
+		}
+
+		deployment, err := NewDeployment(newContracts(
+			[]testContract{testContractA, escaping},
+			[]flow.Address{account1, account1},
+		))
+		require.NoError(t, err)
+		deployment.SetRoot(".")
+
+		_, err = deployment.Batches()
+		assert.ErrorContains(t, err, "../ContractA.cdc which is outside of the project root")
+	})
+}
+
+func TestContractDeploymentDependencies(t *testing.T) {
+	account := flow.HexToAddress("0x01")
+	contracts := make([]*Contract, 0)
+	for _, contract := range []testContract{testContractA, testContractC, testContractD} {
+		contracts = append(contracts, NewContract(
+			strings.Split(contract.location, ".")[0],
+			contract.location,
+			contract.code,
+			account,
+			"",
+			nil,
+		))
+	}
+
+	deployment, err := NewDeployment(contracts)
+	require.NoError(t, err)
+
+	_, err = deployment.Sort()
+	require.NoError(t, err)
+
+	assert.Len(t, deployment.Dependencies(contracts[0]), 0)
+	assert.Equal(t, []*Contract{contracts[0]}, deployment.Dependencies(contracts[1]))
+	assert.Equal(t, []*Contract{contracts[1]}, deployment.Dependencies(contracts[2]))
+}
@@ -0,0 +1,55 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package project
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/onflow/cadence/runtime/parser"
+	"github.com/turbolent/prettier"
+)
+
+const (
+	formatLineWidth = 100
+	formatIndent    = "    "
+)
+
+// FormatCadence returns the code pretty-printed by the Cadence formatter, so codes differing only in formatting
+// are formatted the same.
+//
+// The formatter prints the parsed program, so comments are dropped and the formatted code is meant for
+// comparisons rather than for replacing the source. An error is returned if the code can't be parsed or printed.
+func FormatCadence(code []byte) (formatted []byte, err error) {
+	program, err := parser.ParseProgram(nil, code, parser.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	// the printer doesn't support every declaration yet and panics on the ones it doesn't
+	defer func() {
+		if r := recover(); r != nil {
+			formatted, err = nil, fmt.Errorf("failed to format code: %v", r)
+		}
+	}()
+
+	var b strings.Builder
+	prettier.Prettier(&b, program.Doc(), formatLineWidth, formatIndent)
+	return []byte(b.String()), nil
+}
@@ -0,0 +1,61 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package project
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatCadence(t *testing.T) {
+
+	t.Run("Differently Formatted", func(t *testing.T) {
+		compact, err := FormatCadence([]byte(`pub contract Foo { pub fun bar(a: Int): Int { return a+1 } }`))
+		require.NoError(t, err)
+
+		spread, err := FormatCadence([]byte(`
+			pub contract Foo {
+
+				pub fun bar(a: Int): Int {
+					return a + 1
+				}
+			}
+		`))
+		require.NoError(t, err)
+
+		assert.Equal(t, string(compact), string(spread))
+	})
+
+	t.Run("Different Code", func(t *testing.T) {
+		foo, err := FormatCadence([]byte(`pub contract Foo { pub fun bar(): Int { return 1 } }`))
+		require.NoError(t, err)
+
+		bar, err := FormatCadence([]byte(`pub contract Foo { pub fun bar(): Int { return 2 } }`))
+		require.NoError(t, err)
+
+		assert.NotEqual(t, string(foo), string(bar))
+	})
+
+	t.Run("Invalid Code", func(t *testing.T) {
+		_, err := FormatCadence([]byte(`pub contract Foo {`))
+		assert.Error(t, err)
+	})
+}
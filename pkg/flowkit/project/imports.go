@@ -21,6 +21,8 @@ package project
 import (
 	"fmt"
 	"path"
+	"sort"
+	"strings"
 
 	"github.com/onflow/flow-go-sdk"
 )
@@ -34,31 +36,109 @@ type Account interface {
 type ImportReplacer struct {
 	contracts []*Contract
 	aliases   Aliases
+	warnings  []DuplicateContractLocationWarning
+	// substitutions are the imports resolved to addresses during the last replace
+	substitutions []ImportSubstitution
+	root          string
+	importLogger  func(imp, resolvedAddress, contractName string)
 }
 
-func NewImportReplacer(contracts []*Contract, aliases Aliases) *ImportReplacer {
-	return &ImportReplacer{
+// ImportSubstitution describes an import the replacer resolved to an address.
+type ImportSubstitution struct {
+	// Program is the location of the program containing the import.
+	Program string
+	// Contract is the imported name, e.g. X for import X from "./X.cdc".
+	Contract string
+	// Location is the import location as written in the program.
+	Location string
+	Address  string
+}
+
+// DuplicateContractLocationWarning is reported when a location is claimed by more than one contract or alias
+// and the later one overrides the address the location was previously resolved to.
+type DuplicateContractLocationWarning struct {
+	Location          string
+	Address           string
+	OverriddenAddress string
+}
+
+func (d DuplicateContractLocationWarning) String() string {
+	return fmt.Sprintf(
+		"location %s is used by multiple contracts, imports will resolve to 0x%s instead of 0x%s",
+		d.Location,
+		d.Address,
+		d.OverriddenAddress,
+	)
+}
+
+// ImportReplacerOption configures the import replacer.
+type ImportReplacerOption func(*ImportReplacer)
+
+// WithImportLogger calls the log function for each import resolved to an address with the import
+// as written in the program, the address it resolved to and the imported contract name.
+//
+// An import of multiple contracts, e.g. import A, B from "./AB.cdc", is logged once for each contract.
+func WithImportLogger(log func(imp, resolvedAddress, contractName string)) ImportReplacerOption {
+	return func(i *ImportReplacer) {
+		i.importLogger = log
+	}
+}
+
+func NewImportReplacer(contracts []*Contract, aliases Aliases, options ...ImportReplacerOption) *ImportReplacer {
+	replacer := &ImportReplacer{
 		contracts: contracts,
 		aliases:   aliases,
 	}
+	for _, option := range options {
+		option(replacer)
+	}
+
+	return replacer
+}
+
+// SetRoot bounds the path imports to the project root, the directory containing the configuration.
+//
+// Replacing an import resolving to a location outside the root fails. Imports are not bounded if the root is empty.
+func (i *ImportReplacer) SetRoot(root string) {
+	i.root = root
 }
 
 func (i *ImportReplacer) Replace(program *Program) (*Program, error) {
 	imports := program.imports()
-	contractsLocations := i.getContractsLocations()
+	contractsLocations, warnings := i.getContractsLocations()
+	i.warnings = warnings
+	i.substitutions = make([]ImportSubstitution, 0)
+
+	substitute := func(imp string, address string) {
+		for _, name := range program.importIdentifiers(imp) {
+			i.substitutions = append(i.substitutions, ImportSubstitution{
+				Program:  program.Location(),
+				Contract: name,
+				Location: imp,
+				Address:  address,
+			})
+			if i.importLogger != nil {
+				i.importLogger(imp, address, name)
+			}
+		}
+		program.replaceImport(imp, address)
+	}
 
 	for _, imp := range imports {
 		// check if import by path exists (e.g. import X from ["./X.cdc"])
-		importLocation := path.Clean(absolutePath(program.Location(), imp))
+		importLocation, err := absolutePathWithRoot(i.root, program.Location(), imp)
+		if err != nil {
+			return nil, err
+		}
 		address, isPath := contractsLocations[importLocation]
 		if isPath {
-			program.replaceImport(imp, address)
+			substitute(imp, address)
 			continue
 		}
 		// check if import by identifier exists (e.g. import ["X"])
 		address, isIdentifier := contractsLocations[imp]
 		if isIdentifier {
-			program.replaceImport(imp, address)
+			substitute(imp, address)
 			continue
 		}
 
@@ -68,22 +148,204 @@ func (i *ImportReplacer) Replace(program *Program) (*Program, error) {
 	return program, nil
 }
 
+// ImportChange describes an import line changed by the import replacer.
+type ImportChange struct {
+	Original    string
+	Replacement string
+	// LineNumber is the 1-based line of the import in the program code.
+	LineNumber int
+}
+
+// ReplaceWithDiff replaces the program imports same as Replace and also returns the changed import lines.
+func (i *ImportReplacer) ReplaceWithDiff(program *Program) (*Program, []ImportChange, error) {
+	original := strings.Split(string(program.Code()), "\n")
+
+	program, err := i.Replace(program)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// replacing imports never adds or removes lines, so changed lines can be compared one by one
+	replaced := strings.Split(string(program.Code()), "\n")
+	changes := make([]ImportChange, 0)
+	for n, line := range original {
+		if n >= len(replaced) || line == replaced[n] {
+			continue
+		}
+
+		changes = append(changes, ImportChange{
+			Original:    strings.TrimSpace(line),
+			Replacement: strings.TrimSpace(replaced[n]),
+			LineNumber:  n + 1,
+		})
+	}
+
+	return program, changes, nil
+}
+
+// Warnings returns duplicate location warnings found during the last replace.
+func (i *ImportReplacer) Warnings() []DuplicateContractLocationWarning {
+	return i.warnings
+}
+
+// Substitutions returns the imports resolved to addresses during the last replace.
+func (i *ImportReplacer) Substitutions() []ImportSubstitution {
+	return i.substitutions
+}
+
+// ImportAllowlist maps the contract names to the addresses their imports are expected to resolve to.
+type ImportAllowlist map[string]string
+
+// ImportViolation is an import substitution not covered by the import allowlist.
+type ImportViolation struct {
+	ImportSubstitution
+	// Expected is the allowed address of the contract, empty if the contract is missing from the allowlist.
+	Expected string
+}
+
+func (v ImportViolation) String() string {
+	if v.Expected == "" {
+		return fmt.Sprintf(
+			"import %s in %s resolves to 0x%s but %s is not in the import allowlist",
+			v.Location,
+			v.Program,
+			v.Address,
+			v.Contract,
+		)
+	}
+
+	return fmt.Sprintf(
+		"import %s in %s resolves to 0x%s but %s is allowed only at 0x%s",
+		v.Location,
+		v.Program,
+		v.Address,
+		v.Contract,
+		v.Expected,
+	)
+}
+
+// Check returns the substitutions of contracts missing from the allowlist or resolved to a different address.
+func (a ImportAllowlist) Check(substitutions []ImportSubstitution) []ImportViolation {
+	violations := make([]ImportViolation, 0)
+	for _, substitution := range substitutions {
+		expected, ok := a[substitution.Contract]
+		if !ok {
+			violations = append(violations, ImportViolation{ImportSubstitution: substitution})
+			continue
+		}
+
+		expectedAddress := flow.HexToAddress(expected)
+		if expectedAddress != flow.HexToAddress(substitution.Address) {
+			violations = append(violations, ImportViolation{
+				ImportSubstitution: substitution,
+				Expected:           expectedAddress.String(),
+			})
+		}
+	}
+
+	return violations
+}
+
+// NewImportAllowlist builds the allowlist allowing exactly the provided substitutions.
+//
+// An error is returned if the same contract is resolved to different addresses.
+func NewImportAllowlist(substitutions []ImportSubstitution) (ImportAllowlist, error) {
+	allowlist := make(ImportAllowlist)
+	for _, substitution := range substitutions {
+		address := flow.HexToAddress(substitution.Address).String()
+		if existing, ok := allowlist[substitution.Contract]; ok && existing != address {
+			return nil, fmt.Errorf(
+				"contract %s is imported from both 0x%s and 0x%s",
+				substitution.Contract,
+				existing,
+				address,
+			)
+		}
+		allowlist[substitution.Contract] = address
+	}
+
+	return allowlist, nil
+}
+
 // getContractsLocations return a map with contract locations as keys and addresses where they are deployed as values.
-func (i *ImportReplacer) getContractsLocations() map[string]string {
+//
+// Any location that gets overridden with a different address is reported as a warning.
+func (i *ImportReplacer) getContractsLocations() (map[string]string, []DuplicateContractLocationWarning) {
 	locationAddress := make(map[string]string)
+	warnings := make([]DuplicateContractLocationWarning, 0)
+
+	set := func(location string, address string) {
+		if previous, ok := locationAddress[location]; ok && previous != address {
+			warnings = append(warnings, DuplicateContractLocationWarning{
+				Location:          location,
+				Address:           address,
+				OverriddenAddress: previous,
+			})
+		}
+		locationAddress[location] = address
+	}
+
 	for _, contract := range i.contracts {
-		locationAddress[path.Clean(contract.Location())] = contract.AccountAddress.String()
+		set(path.Clean(contract.Location()), contract.AccountAddress.String())
 		// add also by name since we might use the new import schema
-		locationAddress[contract.Name] = contract.AccountAddress.String()
+		set(contract.Name, contract.AccountAddress.String())
 	}
 
-	for source, target := range i.aliases {
-		locationAddress[path.Clean(source)] = flow.HexToAddress(target).String()
+	// sort aliases so the overriding order and reported warnings are deterministic
+	sources := make([]string, 0, len(i.aliases))
+	for source := range i.aliases {
+		sources = append(sources, source)
 	}
+	sort.Strings(sources)
 
-	return locationAddress
+	for _, source := range sources {
+		set(path.Clean(source), flow.HexToAddress(i.aliases[source]).String())
+	}
+
+	return locationAddress, warnings
 }
 
 func absolutePath(basePath, relativePath string) string {
 	return path.Join(path.Dir(basePath), relativePath)
 }
+
+// absolutePathWithRoot resolves the relative path against the directory of the base path and returns
+// an error if the resolved path escapes the root, e.g. with an import of "../../../etc/passwd".
+//
+// An empty root doesn't bound the resolved path.
+func absolutePathWithRoot(root, basePath, relativePath string) (string, error) {
+	resolved := absolutePath(basePath, relativePath)
+	if root == "" {
+		return resolved, nil
+	}
+
+	root = path.Clean(root)
+	if !withinRoot(root, resolved) {
+		return "", fmt.Errorf(
+			"import %s from %s resolves to %s which is outside of the project root %s",
+			relativePath,
+			basePath,
+			resolved,
+			root,
+		)
+	}
+
+	return resolved, nil
+}
+
+// withinRoot checks whether the cleaned path is the root or is located inside it.
+func withinRoot(root, cleaned string) bool {
+	if root == "." {
+		return cleaned != ".." && !strings.HasPrefix(cleaned, "../") && !path.IsAbs(cleaned)
+	}
+
+	if root == "/" {
+		return path.IsAbs(cleaned)
+	}
+
+	if path.IsAbs(root) != path.IsAbs(cleaned) {
+		return false
+	}
+
+	return cleaned == root || strings.HasPrefix(cleaned, root+"/")
+}
@@ -151,4 +151,274 @@ func TestResolver(t *testing.T) {
 		assert.Equal(t, cleanCode(expected), cleanCode(replaced.Code()))
 	})
 
+	t.Run("Replace with diff", func(t *testing.T) {
+		contracts := []*Contract{
+			NewContract("Bar", "./Bar.cdc", nil, flow.HexToAddress("0x2"), "", nil),
+			NewContract("Foo", "./Foo.cdc", nil, flow.HexToAddress("0x1"), "", nil),
+		}
+
+		code := []byte(`
+			import Foo from "./Foo.cdc"
+			import Crypto
+
+			import "Bar"
+			pub fun main() {}
+		`)
+		program, err := NewProgram(&testScript{code: code, location: "./script.cdc"})
+		require.NoError(t, err)
+
+		_, changes, err := NewImportReplacer(contracts, nil).ReplaceWithDiff(program)
+		require.NoError(t, err)
+
+		assert.Equal(t, []ImportChange{{
+			Original:    `import Foo from "./Foo.cdc"`,
+			Replacement: "import Foo from 0x0000000000000001",
+			LineNumber:  2,
+		}, {
+			Original:    `import "Bar"`,
+			Replacement: "import Bar from 0x0000000000000002",
+			LineNumber:  5,
+		}}, changes)
+	})
+
+	t.Run("Replace with diff no imports", func(t *testing.T) {
+		program, err := NewProgram(&testScript{code: []byte(`pub fun main() {}`), location: "./script.cdc"})
+		require.NoError(t, err)
+
+		_, changes, err := NewImportReplacer(nil, nil).ReplaceWithDiff(program)
+		require.NoError(t, err)
+		assert.Len(t, changes, 0)
+	})
+
+	t.Run("Replace with diff unresolved import", func(t *testing.T) {
+		program, err := NewProgram(&testScript{code: []byte(`import "Foo"`), location: "./script.cdc"})
+		require.NoError(t, err)
+
+		_, _, err = NewImportReplacer(nil, nil).ReplaceWithDiff(program)
+		assert.EqualError(t, err, "import Foo could not be resolved from provided contracts")
+	})
+
+	t.Run("Warn on duplicate locations", func(t *testing.T) {
+		contracts := []*Contract{
+			NewContract("Foo", "./Foo.cdc", nil, flow.HexToAddress("0x1"), "", nil),
+			NewContract("Bar", "./Foo.cdc", nil, flow.HexToAddress("0x2"), "", nil),
+		}
+
+		replacer := NewImportReplacer(contracts, nil)
+
+		locations, warnings := replacer.getContractsLocations()
+		assert.Equal(t, flow.HexToAddress("0x2").String(), locations["Foo.cdc"])
+		require.Len(t, warnings, 1)
+		assert.Equal(t, DuplicateContractLocationWarning{
+			Location:          "Foo.cdc",
+			Address:           flow.HexToAddress("0x2").String(),
+			OverriddenAddress: flow.HexToAddress("0x1").String(),
+		}, warnings[0])
+
+		program, err := NewProgram(&testScript{
+			code:     []byte(`import Foo from "./Foo.cdc"`),
+			location: "./script.cdc",
+		})
+		require.NoError(t, err)
+
+		_, err = replacer.Replace(program)
+		require.NoError(t, err)
+		assert.Equal(t, warnings, replacer.Warnings())
+	})
+
+	t.Run("No warning on same address", func(t *testing.T) {
+		contracts := []*Contract{
+			NewContract("Foo", "./Foo.cdc", nil, flow.HexToAddress("0x1"), "", nil),
+		}
+		aliases := map[string]string{
+			"./Foo.cdc": flow.HexToAddress("0x1").String(),
+		}
+
+		_, warnings := NewImportReplacer(contracts, aliases).getContractsLocations()
+		assert.Len(t, warnings, 0)
+	})
+
+	t.Run("Warn on alias override", func(t *testing.T) {
+		contracts := []*Contract{
+			NewContract("Foo", "./Foo.cdc", nil, flow.HexToAddress("0x1"), "", nil),
+		}
+		aliases := map[string]string{
+			"./Foo.cdc": flow.HexToAddress("0x3").String(),
+		}
+
+		locations, warnings := NewImportReplacer(contracts, aliases).getContractsLocations()
+		assert.Equal(t, flow.HexToAddress("0x3").String(), locations["Foo.cdc"])
+		require.Len(t, warnings, 1)
+		assert.Equal(t, "Foo.cdc", warnings[0].Location)
+		assert.Equal(t, flow.HexToAddress("0x1").String(), warnings[0].OverriddenAddress)
+	})
+
+	t.Run("Reject import outside root", func(t *testing.T) {
+		contracts := []*Contract{
+			NewContract("Foo", "./Foo.cdc", nil, flow.HexToAddress("0x1"), "", nil),
+		}
+
+		code := []byte(`import Foo from "../../../etc/passwd"`)
+		program, err := NewProgram(&testScript{code: code, location: "./contracts/script.cdc"})
+		require.NoError(t, err)
+
+		replacer := NewImportReplacer(contracts, nil)
+		replacer.SetRoot(".")
+		_, err = replacer.Replace(program)
+		assert.EqualError(
+			t,
+			err,
+			"import ../../../etc/passwd from ./contracts/script.cdc resolves to ../../etc/passwd which is outside of the project root .",
+		)
+	})
+
+	t.Run("Resolve import inside root", func(t *testing.T) {
+		contracts := []*Contract{
+			NewContract("Foo", "./Foo.cdc", nil, flow.HexToAddress("0x1"), "", nil),
+		}
+
+		code := []byte(`import Foo from "../Foo.cdc"`)
+		program, err := NewProgram(&testScript{code: code, location: "./contracts/script.cdc"})
+		require.NoError(t, err)
+
+		replacer := NewImportReplacer(contracts, nil)
+		replacer.SetRoot(".")
+		replaced, err := replacer.Replace(program)
+		require.NoError(t, err)
+		assert.Equal(t, "import Foo from 0x0000000000000001", cleanCode(replaced.Code()))
+	})
+
+	t.Run("Substitutions checked against allowlist", func(t *testing.T) {
+		contracts := []*Contract{
+			NewContract("Foo", "./Foo.cdc", nil, flow.HexToAddress("0x1"), "", nil),
+			NewContract("Bar", "./Bar.cdc", nil, flow.HexToAddress("0x2"), "", nil),
+		}
+		aliases := map[string]string{"./Baz.cdc": "0x3"}
+
+		code := []byte(`
+			import Foo from "./Foo.cdc"
+			import "Bar"
+			import Baz from "./Baz.cdc"
+			pub fun main() {}
+		`)
+		program, err := NewProgram(&testScript{code: code, location: "./script.cdc"})
+		require.NoError(t, err)
+
+		replacer := NewImportReplacer(contracts, aliases)
+		_, err = replacer.Replace(program)
+		require.NoError(t, err)
+
+		substitutions := replacer.Substitutions()
+		assert.Equal(t, []ImportSubstitution{
+			{Program: "./script.cdc", Contract: "Foo", Location: "./Foo.cdc", Address: "0000000000000001"},
+			{Program: "./script.cdc", Contract: "Bar", Location: "Bar", Address: "0000000000000002"},
+			{Program: "./script.cdc", Contract: "Baz", Location: "./Baz.cdc", Address: "0000000000000003"},
+		}, substitutions)
+
+		allowlist, err := NewImportAllowlist(substitutions)
+		require.NoError(t, err)
+		assert.Equal(t, ImportAllowlist{
+			"Foo": "0000000000000001",
+			"Bar": "0000000000000002",
+			"Baz": "0000000000000003",
+		}, allowlist)
+		assert.Empty(t, allowlist.Check(substitutions))
+
+		violations := ImportAllowlist{"Foo": "0x01", "Bar": "0x04"}.Check(substitutions)
+		require.Len(t, violations, 2)
+		assert.Equal(t,
+			"import Bar in ./script.cdc resolves to 0x0000000000000002 but Bar is allowed only at 0x0000000000000004",
+			violations[0].String(),
+		)
+		assert.Equal(t,
+			"import ./Baz.cdc in ./script.cdc resolves to 0x0000000000000003 but Baz is not in the import allowlist",
+			violations[1].String(),
+		)
+
+		_, err = NewImportAllowlist(append(substitutions, ImportSubstitution{Contract: "Foo", Address: "0x5"}))
+		assert.EqualError(t, err, "contract Foo is imported from both 0x0000000000000001 and 0x0000000000000005")
+	})
+
+	t.Run("Import logger", func(t *testing.T) {
+		contracts := []*Contract{
+			NewContract("Foo", "./Foo.cdc", nil, flow.HexToAddress("0x1"), "", nil),
+			NewContract("Bar", "./Bar.cdc", nil, flow.HexToAddress("0x2"), "", nil),
+		}
+		aliases := map[string]string{"./Baz.cdc": "0x3"}
+
+		code := []byte(`
+			import Foo from "./Foo.cdc"
+			import "Bar"
+			import Baz, Qux from "./Baz.cdc"
+			import Crypto
+			pub fun main() {}
+		`)
+		program, err := NewProgram(&testScript{code: code, location: "./script.cdc"})
+		require.NoError(t, err)
+
+		logged := make([][3]string, 0)
+		replacer := NewImportReplacer(contracts, aliases, WithImportLogger(func(imp, resolvedAddress, contractName string) {
+			logged = append(logged, [3]string{imp, resolvedAddress, contractName})
+		}))
+		_, err = replacer.Replace(program)
+		require.NoError(t, err)
+
+		assert.Equal(t, [][3]string{
+			{"./Foo.cdc", "0000000000000001", "Foo"},
+			{"Bar", "0000000000000002", "Bar"},
+			{"./Baz.cdc", "0000000000000003", "Baz"},
+			{"./Baz.cdc", "0000000000000003", "Qux"},
+		}, logged)
+	})
+
+	t.Run("Import logger not called for unresolved imports", func(t *testing.T) {
+		program, err := NewProgram(&testScript{code: []byte(`import Foo from "./Foo.cdc"`), location: "./script.cdc"})
+		require.NoError(t, err)
+
+		called := false
+		replacer := NewImportReplacer(nil, nil, WithImportLogger(func(string, string, string) {
+			called = true
+		}))
+		_, err = replacer.Replace(program)
+		require.Error(t, err)
+		assert.False(t, called)
+	})
+}
+
+func TestAbsolutePathWithRoot(t *testing.T) {
+	tests := []struct {
+		name     string
+		root     string
+		base     string
+		rel      string
+		resolved string
+		valid    bool
+	}{
+		{name: "same directory", root: ".", base: "./Foo.cdc", rel: "./Bar.cdc", resolved: "Bar.cdc", valid: true},
+		{name: "nested directory", root: ".", base: "./contracts/Foo.cdc", rel: "../Bar.cdc", resolved: "Bar.cdc", valid: true},
+		{name: "escape root", root: ".", base: "./Foo.cdc", rel: "../Bar.cdc"},
+		{name: "escape root deeply", root: ".", base: "./contracts/Foo.cdc", rel: "../../../etc/passwd"},
+		{name: "escape and return", root: ".", base: "./Foo.cdc", rel: "../module/Bar.cdc"},
+		{name: "parent of root", root: ".", base: "./contracts/Foo.cdc", rel: "../.."},
+		{name: "absolute import joined to base", root: ".", base: "./Foo.cdc", rel: "/etc/passwd", resolved: "etc/passwd", valid: true},
+		{name: "absolute root", root: "/project", base: "/project/contracts/Foo.cdc", rel: "../Bar.cdc", resolved: "/project/Bar.cdc", valid: true},
+		{name: "absolute root escape", root: "/project", base: "/project/Foo.cdc", rel: "../etc/passwd"},
+		{name: "absolute root sibling prefix", root: "/project", base: "/project/Foo.cdc", rel: "../project-other/Bar.cdc"},
+		{name: "relative root", root: "./app", base: "app/Foo.cdc", rel: "./Bar.cdc", resolved: "app/Bar.cdc", valid: true},
+		{name: "relative root escape", root: "./app", base: "app/Foo.cdc", rel: "../Bar.cdc"},
+		{name: "no root", root: "", base: "./Foo.cdc", rel: "../../Bar.cdc", resolved: "../../Bar.cdc", valid: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resolved, err := absolutePathWithRoot(test.root, test.base, test.rel)
+			if !test.valid {
+				assert.ErrorContains(t, err, "outside of the project root")
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.resolved, resolved)
+		})
+	}
 }
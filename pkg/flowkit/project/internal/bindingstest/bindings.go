@@ -0,0 +1,215 @@
+// Code generated by flowkit. DO NOT EDIT.
+
+package bindingstest
+
+import (
+	"fmt"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-cli/pkg/flowkit"
+	"github.com/onflow/flow-go-sdk"
+)
+
+// GetBalancesLocation is the location of the script the GetBalances bindings are generated from.
+const GetBalancesLocation = "get_balances.cdc"
+
+// GetBalancesCode is the code of the script get_balances.cdc.
+const GetBalancesCode = "pub fun main(addresses: [Address], minimum: UFix64?, verbose: Bool, limit: Int, ranges: [[Int]?], path: PublicPath): [UFix64] {\n\treturn []\n}\n"
+
+// GetBalances binds the parameters of the script get_balances.cdc.
+type GetBalances struct {
+	Addresses []flow.Address
+	Minimum   *string
+	Verbose   bool
+	Limit     int
+	Ranges    []*[]int
+	// Path has the Cadence type PublicPath which has no Go binding, the value is passed as is.
+	Path cadence.Value
+}
+
+// NewGetBalances returns the script bindings with the required parameters, optional parameters can be set on the returned bindings.
+func NewGetBalances(addresses []flow.Address, verbose bool, limit int, ranges []*[]int, path cadence.Value) (*GetBalances, error) {
+	b := &GetBalances{
+		Addresses: addresses,
+		Verbose:   verbose,
+		Limit:     limit,
+		Ranges:    ranges,
+		Path:      path,
+	}
+	if _, err := b.Arguments(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Arguments encodes the parameters as the Cadence arguments of the script.
+func (b *GetBalances) Arguments() ([]cadence.Value, error) {
+	args := make([]cadence.Value, 6)
+	{
+		values0 := make([]cadence.Value, len(b.Addresses))
+		for i0, element0 := range b.Addresses {
+			values0[i0] = cadence.NewAddress(element0)
+		}
+		args[0] = cadence.NewArray(values0)
+	}
+	if b.Minimum == nil {
+		args[1] = cadence.NewOptional(nil)
+	} else {
+		var inner0 cadence.Value
+		{
+			value1, err := cadence.NewUFix64(*b.Minimum)
+			if err != nil {
+				return nil, fmt.Errorf("invalid parameter minimum: %w", err)
+			}
+			inner0 = value1
+		}
+		args[1] = cadence.NewOptional(inner0)
+	}
+	args[2] = cadence.NewBool(b.Verbose)
+	args[3] = cadence.NewInt(b.Limit)
+	{
+		values0 := make([]cadence.Value, len(b.Ranges))
+		for i0, element0 := range b.Ranges {
+			if element0 == nil {
+				values0[i0] = cadence.NewOptional(nil)
+			} else {
+				var inner1 cadence.Value
+				{
+					values2 := make([]cadence.Value, len(*element0))
+					for i2, element2 := range *element0 {
+						values2[i2] = cadence.NewInt(element2)
+					}
+					inner1 = cadence.NewArray(values2)
+				}
+				values0[i0] = cadence.NewOptional(inner1)
+			}
+		}
+		args[4] = cadence.NewArray(values0)
+	}
+	if b.Path == nil {
+		return nil, fmt.Errorf("parameter path is required")
+	}
+	args[5] = b.Path
+	return args, nil
+}
+
+// Script returns the script with the encoded arguments.
+func (b *GetBalances) Script() (*flowkit.Script, error) {
+	args, err := b.Arguments()
+	if err != nil {
+		return nil, err
+	}
+	return flowkit.NewScript([]byte(GetBalancesCode), args, GetBalancesLocation), nil
+}
+
+// SetupLocation is the location of the transaction the Setup bindings are generated from.
+const SetupLocation = "setup.cdc"
+
+// SetupCode is the code of the transaction setup.cdc.
+const SetupCode = "transaction {\n\tprepare(signer: AuthAccount) {}\n}\n"
+
+// Setup binds the parameters of the transaction setup.cdc.
+type Setup struct{}
+
+// NewSetup returns the transaction bindings with the required parameters, optional parameters can be set on the returned bindings.
+func NewSetup() (*Setup, error) {
+	b := &Setup{}
+	if _, err := b.Arguments(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Arguments encodes the parameters as the Cadence arguments of the transaction.
+func (b *Setup) Arguments() ([]cadence.Value, error) {
+	args := make([]cadence.Value, 0)
+	return args, nil
+}
+
+// Script returns the transaction with the encoded arguments.
+func (b *Setup) Script() (*flowkit.Script, error) {
+	args, err := b.Arguments()
+	if err != nil {
+		return nil, err
+	}
+	return flowkit.NewScript([]byte(SetupCode), args, SetupLocation), nil
+}
+
+// TransferTokensLocation is the location of the transaction the TransferTokens bindings are generated from.
+const TransferTokensLocation = "transfer_tokens.cdc"
+
+// TransferTokensCode is the code of the transaction transfer_tokens.cdc.
+const TransferTokensCode = "transaction(amount: UFix64, to: Address, memo: String?, tags: [String]) {\n\tprepare(signer: AuthAccount) {}\n}\n"
+
+// TransferTokens binds the parameters of the transaction transfer_tokens.cdc.
+type TransferTokens struct {
+	Amount string
+	To     flow.Address
+	Memo   *string
+	Tags   []string
+}
+
+// NewTransferTokens returns the transaction bindings with the required parameters, optional parameters can be set on the returned bindings.
+func NewTransferTokens(amount string, to flow.Address, tags []string) (*TransferTokens, error) {
+	b := &TransferTokens{
+		Amount: amount,
+		To:     to,
+		Tags:   tags,
+	}
+	if to == flow.EmptyAddress {
+		return nil, fmt.Errorf("parameter to is required")
+	}
+	if _, err := b.Arguments(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Arguments encodes the parameters as the Cadence arguments of the transaction.
+func (b *TransferTokens) Arguments() ([]cadence.Value, error) {
+	args := make([]cadence.Value, 4)
+	{
+		value0, err := cadence.NewUFix64(b.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parameter amount: %w", err)
+		}
+		args[0] = value0
+	}
+	args[1] = cadence.NewAddress(b.To)
+	if b.Memo == nil {
+		args[2] = cadence.NewOptional(nil)
+	} else {
+		var inner0 cadence.Value
+		{
+			value1, err := cadence.NewString(*b.Memo)
+			if err != nil {
+				return nil, fmt.Errorf("invalid parameter memo: %w", err)
+			}
+			inner0 = value1
+		}
+		args[2] = cadence.NewOptional(inner0)
+	}
+	{
+		values0 := make([]cadence.Value, len(b.Tags))
+		for i0, element0 := range b.Tags {
+			{
+				value1, err := cadence.NewString(element0)
+				if err != nil {
+					return nil, fmt.Errorf("invalid parameter tags: %w", err)
+				}
+				values0[i0] = value1
+			}
+		}
+		args[3] = cadence.NewArray(values0)
+	}
+	return args, nil
+}
+
+// Script returns the transaction with the encoded arguments.
+func (b *TransferTokens) Script() (*flowkit.Script, error) {
+	args, err := b.Arguments()
+	if err != nil {
+		return nil, err
+	}
+	return flowkit.NewScript([]byte(TransferTokensCode), args, TransferTokensLocation), nil
+}
@@ -0,0 +1,131 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bindingstest
+
+import (
+	"testing"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// roundTrip encodes the arguments as JSON-Cadence the same way they are sent to the network and decodes them back.
+func roundTrip(t *testing.T, args []cadence.Value) []cadence.Value {
+	decoded := make([]cadence.Value, len(args))
+	for i, arg := range args {
+		encoded, err := jsoncdc.Encode(arg)
+		require.NoError(t, err)
+		decoded[i], err = jsoncdc.Decode(nil, encoded)
+		require.NoError(t, err)
+	}
+	return decoded
+}
+
+func TestTransferTokens(t *testing.T) {
+	to := flow.HexToAddress("0x01cf0e2f2f715450")
+
+	t.Run("Script", func(t *testing.T) {
+		tx, err := NewTransferTokens("10.5", to, []string{"gift", "birthday"})
+		require.NoError(t, err)
+		memo := "happy birthday"
+		tx.Memo = &memo
+
+		script, err := tx.Script()
+		require.NoError(t, err)
+		assert.Equal(t, TransferTokensCode, string(script.Code()))
+		assert.Equal(t, TransferTokensLocation, script.Location())
+
+		amount, _ := cadence.NewUFix64("10.5")
+		expected := []cadence.Value{
+			amount,
+			cadence.NewAddress(to),
+			cadence.NewOptional(cadence.String("happy birthday")),
+			cadence.NewArray([]cadence.Value{cadence.String("gift"), cadence.String("birthday")}),
+		}
+		assert.Equal(t, expected, script.Args)
+		assert.Equal(t, expected, roundTrip(t, script.Args))
+	})
+
+	t.Run("Optional Not Set", func(t *testing.T) {
+		tx, err := NewTransferTokens("1.0", to, nil)
+		require.NoError(t, err)
+
+		args, err := tx.Arguments()
+		require.NoError(t, err)
+		assert.Equal(t, cadence.NewOptional(nil), args[2])
+		assert.Equal(t, cadence.NewArray([]cadence.Value{}), args[3])
+		assert.Equal(t, args, roundTrip(t, args))
+	})
+
+	t.Run("Invalid Parameters", func(t *testing.T) {
+		_, err := NewTransferTokens("ten", to, nil)
+		assert.ErrorContains(t, err, "invalid parameter amount")
+
+		_, err = NewTransferTokens("1.0", flow.EmptyAddress, nil)
+		assert.EqualError(t, err, "parameter to is required")
+	})
+}
+
+func TestGetBalances(t *testing.T) {
+	addresses := []flow.Address{flow.HexToAddress("0x01"), flow.HexToAddress("0x02")}
+	path := cadence.NewPath("public", "flowTokenBalance")
+
+	t.Run("Script", func(t *testing.T) {
+		script, err := NewGetBalances(addresses, true, 5, []*[]int{{1, 2}, nil}, path)
+		require.NoError(t, err)
+		minimum := "0.001"
+		script.Minimum = &minimum
+
+		args, err := script.Arguments()
+		require.NoError(t, err)
+
+		expectedMinimum, _ := cadence.NewUFix64("0.001")
+		expected := []cadence.Value{
+			cadence.NewArray([]cadence.Value{cadence.NewAddress(addresses[0]), cadence.NewAddress(addresses[1])}),
+			cadence.NewOptional(expectedMinimum),
+			cadence.NewBool(true),
+			cadence.NewInt(5),
+			cadence.NewArray([]cadence.Value{
+				cadence.NewOptional(cadence.NewArray([]cadence.Value{cadence.NewInt(1), cadence.NewInt(2)})),
+				cadence.NewOptional(nil),
+			}),
+			path,
+		}
+		assert.Equal(t, expected, args)
+		assert.Equal(t, expected, roundTrip(t, args))
+	})
+
+	t.Run("Unsupported Parameter Required", func(t *testing.T) {
+		_, err := NewGetBalances(addresses, false, 0, nil, nil)
+		assert.EqualError(t, err, "parameter path is required")
+	})
+}
+
+func TestSetup(t *testing.T) {
+	tx, err := NewSetup()
+	require.NoError(t, err)
+
+	script, err := tx.Script()
+	require.NoError(t, err)
+	assert.Len(t, script.Args, 0)
+	assert.Equal(t, SetupCode, string(script.Code()))
+}
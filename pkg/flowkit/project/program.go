@@ -19,12 +19,19 @@
 package project
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"regexp"
+	"strings"
+	"unicode"
 
 	"github.com/onflow/cadence/runtime/ast"
 	"github.com/onflow/cadence/runtime/common"
 	"github.com/onflow/cadence/runtime/parser"
+	"github.com/onflow/cadence/runtime/stdlib"
+	"github.com/onflow/flow-go-sdk"
 )
 
 type Program struct {
@@ -50,6 +57,35 @@ func NewProgram(script Scripter) (*Program, error) {
 	}, nil
 }
 
+// WithLocation returns a copy of the program at the location, so its relative imports resolve from there,
+// e.g. for a program loaded from a URL or generated in memory.
+//
+// The copy shares the parsed program with the original, replacing the imports of the copy doesn't change the original.
+func (p *Program) WithLocation(location string) *Program {
+	return &Program{
+		script:     &relocatedScript{code: p.Code(), location: location},
+		astProgram: p.astProgram,
+	}
+}
+
+// relocatedScript is the script of a program copied to another location.
+type relocatedScript struct {
+	code     []byte
+	location string
+}
+
+func (r *relocatedScript) Code() []byte {
+	return r.code
+}
+
+func (r *relocatedScript) SetCode(code []byte) {
+	r.code = code
+}
+
+func (r *relocatedScript) Location() string {
+	return r.location
+}
+
 // imports builds an array of all the import locations
 //
 // It currently supports getting import locations as identifiers or as strings. Strings locations
@@ -68,6 +104,42 @@ func (p *Program) imports() []string {
 	return imports
 }
 
+// importIdentifiers returns the names imported from the string location, e.g. X for
+// both import X from "./X.cdc" and import "X".
+func (p *Program) importIdentifiers(location string) []string {
+	names := make([]string, 0)
+	for _, importDeclaration := range p.astProgram.ImportDeclarations() {
+		if importDeclaration.Location.String() != location {
+			continue
+		}
+		if len(importDeclaration.Identifiers) == 0 {
+			names = append(names, location)
+		}
+		for _, identifier := range importDeclaration.Identifiers {
+			names = append(names, identifier.Identifier)
+		}
+	}
+
+	return names
+}
+
+// AddressImports returns the addresses of the contracts imported from an address by contract name,
+// e.g. Bar is imported from 0x01 by import Bar from 0x01.
+func (p *Program) AddressImports() map[string]flow.Address {
+	imports := make(map[string]flow.Address)
+	for _, importDeclaration := range p.astProgram.ImportDeclarations() {
+		location, ok := importDeclaration.Location.(common.AddressLocation)
+		if !ok {
+			continue
+		}
+		for _, identifier := range importDeclaration.Identifiers {
+			imports[identifier.Identifier] = flow.BytesToAddress(location.Address.Bytes())
+		}
+	}
+
+	return imports
+}
+
 func (p *Program) HasImports() bool {
 	return len(p.imports()) > 0
 }
@@ -95,6 +167,24 @@ func (p *Program) Code() []byte {
 	return p.script.Code()
 }
 
+// Checksum returns the hex encoded SHA-256 hash of the normalized program code.
+//
+// Line endings are normalized and trailing whitespace is removed, so the same code
+// has the same checksum no matter which platform it was loaded from.
+func (p *Program) Checksum() string {
+	code := strings.ReplaceAll(string(p.Code()), "\r\n", "\n")
+	code = strings.ReplaceAll(code, "\r", "\n")
+
+	lines := strings.Split(code, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRightFunc(line, unicode.IsSpace)
+	}
+	normalized := strings.TrimRight(strings.Join(lines, "\n"), "\n")
+
+	hash := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(hash[:])
+}
+
 func (p *Program) Name() (string, error) {
 	if len(p.astProgram.CompositeDeclarations()) > 1 || len(p.astProgram.InterfaceDeclarations()) > 1 ||
 		len(p.astProgram.CompositeDeclarations())+len(p.astProgram.InterfaceDeclarations()) > 1 {
@@ -116,6 +206,282 @@ func (p *Program) Name() (string, error) {
 	return "", fmt.Errorf("unable to determine contract name")
 }
 
+// EventDeclaration describes an event declared in the program.
+type EventDeclaration struct {
+	Name   string
+	Fields []EventField
+}
+
+// EventField is a single field of the declared event.
+type EventField struct {
+	Name string
+	Type string
+}
+
+// EventDeclarations returns all the events declared in the program, including events declared in contracts and contract interfaces.
+func (p *Program) EventDeclarations() []EventDeclaration {
+	return p.eventDeclarations(true)
+}
+
+// EmitableEvents returns the events declared in the program that can be emitted.
+//
+// Events declared in contract interfaces are requirements the implementing contracts declare again,
+// they are never emitted themselves and are left out.
+func (p *Program) EmitableEvents() []EventDeclaration {
+	return p.eventDeclarations(false)
+}
+
+func (p *Program) eventDeclarations(includeInterfaces bool) []EventDeclaration {
+	events := make([]EventDeclaration, 0)
+
+	var collect func(composites []*ast.CompositeDeclaration, interfaces []*ast.InterfaceDeclaration)
+	collect = func(composites []*ast.CompositeDeclaration, interfaces []*ast.InterfaceDeclaration) {
+		for _, composite := range composites {
+			if composite.CompositeKind == common.CompositeKindEvent {
+				events = append(events, newEventDeclaration(composite))
+				continue
+			}
+			collect(composite.Members.Composites(), composite.Members.Interfaces())
+		}
+		if !includeInterfaces {
+			return
+		}
+		for _, interfaceDeclaration := range interfaces {
+			collect(interfaceDeclaration.Members.Composites(), interfaceDeclaration.Members.Interfaces())
+		}
+	}
+
+	collect(p.astProgram.CompositeDeclarations(), p.astProgram.InterfaceDeclarations())
+
+	return events
+}
+
+// newEventDeclaration builds the event declaration from the event composite, whose fields are the parameters of its initializer.
+func newEventDeclaration(composite *ast.CompositeDeclaration) EventDeclaration {
+	event := EventDeclaration{
+		Name:   composite.Identifier.Identifier,
+		Fields: make([]EventField, 0),
+	}
+
+	for _, initializer := range composite.Members.Initializers() {
+		for _, param := range initializer.FunctionDeclaration.ParameterList.Parameters {
+			event.Fields = append(event.Fields, EventField{
+				Name: param.Identifier.Identifier,
+				Type: param.TypeAnnotation.String(),
+			})
+		}
+	}
+
+	return event
+}
+
+// TypeDeclaration describes a composite or interface type declared in the program.
+type TypeDeclaration struct {
+	// Name is qualified with the names of the enclosing declarations, e.g. "Market.Listing".
+	Name string
+	// Kind is the declaration keyword, e.g. "struct", "enum" or "resource interface".
+	Kind string
+	// Access is the access modifier keyword, e.g. "pub", empty if not specified.
+	Access string
+}
+
+// TypeDeclarations returns all the types declared in the program, including types nested in other declarations.
+//
+// Each declaration is followed by the types nested in it.
+func (p *Program) TypeDeclarations() []TypeDeclaration {
+	declarations := make([]TypeDeclaration, 0)
+
+	var collect func(prefix string, composites []*ast.CompositeDeclaration, interfaces []*ast.InterfaceDeclaration)
+	collect = func(prefix string, composites []*ast.CompositeDeclaration, interfaces []*ast.InterfaceDeclaration) {
+		for _, composite := range composites {
+			name := prefix + composite.Identifier.Identifier
+			declarations = append(declarations, TypeDeclaration{
+				Name:   name,
+				Kind:   composite.CompositeKind.Keyword(),
+				Access: composite.Access.Keyword(),
+			})
+			collect(name+".", composite.Members.Composites(), composite.Members.Interfaces())
+		}
+		for _, interfaceDeclaration := range interfaces {
+			name := prefix + interfaceDeclaration.Identifier.Identifier
+			declarations = append(declarations, TypeDeclaration{
+				Name:   name,
+				Kind:   fmt.Sprintf("%s interface", interfaceDeclaration.CompositeKind.Keyword()),
+				Access: interfaceDeclaration.Access.Keyword(),
+			})
+			collect(name+".", interfaceDeclaration.Members.Composites(), interfaceDeclaration.Members.Interfaces())
+		}
+	}
+
+	collect("", p.astProgram.CompositeDeclarations(), p.astProgram.InterfaceDeclarations())
+
+	return declarations
+}
+
+// StructDeclarations returns the names of all the structs declared in the program.
+func (p *Program) StructDeclarations() []string {
+	return p.typeDeclarationNames(common.CompositeKindStructure.Keyword())
+}
+
+// EnumDeclarations returns the names of all the enums declared in the program.
+func (p *Program) EnumDeclarations() []string {
+	return p.typeDeclarationNames(common.CompositeKindEnum.Keyword())
+}
+
+func (p *Program) typeDeclarationNames(kind string) []string {
+	names := make([]string, 0)
+	for _, declaration := range p.TypeDeclarations() {
+		if declaration.Kind == kind {
+			names = append(names, declaration.Name)
+		}
+	}
+	return names
+}
+
+// ConstantDeclaration describes a top-level constant declared in the program.
+type ConstantDeclaration struct {
+	Name string
+	// Type is the annotated type, or the type inferred from a literal value, empty if it can't be determined.
+	Type string
+	// Value is the Cadence expression of the value, e.g. string values are quoted.
+	Value string
+}
+
+// Constants returns the top-level let declarations of the program with an access modifier, in declaration order.
+func (p *Program) Constants() []ConstantDeclaration {
+	constants := make([]ConstantDeclaration, 0)
+
+	for _, declaration := range p.astProgram.VariableDeclarations() {
+		if !declaration.IsConstant || declaration.Access == ast.AccessNotSpecified {
+			continue
+		}
+
+		constant := ConstantDeclaration{
+			Name: declaration.Identifier.Identifier,
+			Type: literalType(declaration.Value),
+		}
+		if declaration.TypeAnnotation != nil {
+			constant.Type = declaration.TypeAnnotation.Type.String()
+		}
+		if declaration.Value != nil {
+			constant.Value = declaration.Value.String()
+		}
+
+		constants = append(constants, constant)
+	}
+
+	return constants
+}
+
+// literalType returns the type Cadence infers for a literal expression, empty for other expressions.
+func literalType(expression ast.Expression) string {
+	switch e := expression.(type) {
+	case *ast.IntegerExpression:
+		return "Int"
+	case *ast.FixedPointExpression:
+		if e.Negative {
+			return "Fix64"
+		}
+		return "UFix64"
+	case *ast.StringExpression:
+		return "String"
+	case *ast.BoolExpression:
+		return "Bool"
+	}
+	return ""
+}
+
+// Kinds of contract update compatibility violations.
+const (
+	ViolationContractNotFound      = "contract-not-found"
+	ViolationDeclarationKindChange = "declaration-kind-change"
+	ViolationMissingDeclaration    = "missing-declaration"
+	ViolationNewField              = "new-field"
+	ViolationFieldTypeChange       = "field-type-change"
+	ViolationConformanceChange     = "conformance-change"
+	ViolationEnumCaseChange        = "enum-case-change"
+	ViolationMissingEnumCases      = "missing-enum-cases"
+	ViolationOther                 = "other"
+)
+
+// CompatibilityViolation describes a change of a contract declaration the network doesn't allow in a contract update.
+type CompatibilityViolation struct {
+	Kind        string
+	Declaration string
+	Reason      string
+}
+
+func (c CompatibilityViolation) String() string {
+	if c.Declaration == "" {
+		return c.Reason
+	}
+	return fmt.Sprintf("%s: %s", c.Declaration, c.Reason)
+}
+
+// IsUpgradeable checks whether the program, as currently deployed, can be updated to the new source
+// following the Cadence contract updatability rules and returns the violations if it can't.
+//
+// The rules are described in https://developers.flow.com/cadence/language/contract-updatability,
+// e.g. fields can be removed but not added or changed, while functions can be changed freely.
+// An error is returned if the new source can't be parsed.
+func (p *Program) IsUpgradeable(newSource []byte) (bool, []CompatibilityViolation, error) {
+	newProgram, err := parser.ParseProgram(nil, newSource, parser.Config{})
+	if err != nil {
+		return false, nil, err
+	}
+
+	// the name is only used in the validation error, the program may declare no contract at all
+	name, _ := p.Name()
+	err = stdlib.NewContractUpdateValidator(
+		common.StringLocation(p.Location()),
+		name,
+		p.astProgram,
+		newProgram,
+	).Validate()
+	if err == nil {
+		return true, nil, nil
+	}
+
+	var updateErr *stdlib.ContractUpdateError
+	if !errors.As(err, &updateErr) {
+		return false, nil, err
+	}
+
+	violations := make([]CompatibilityViolation, len(updateErr.Errors))
+	for i, childErr := range updateErr.Errors {
+		violations[i] = newCompatibilityViolation(childErr)
+	}
+
+	return false, violations, nil
+}
+
+func newCompatibilityViolation(err error) CompatibilityViolation {
+	switch e := err.(type) {
+	case *stdlib.ContractNotFoundError:
+		return CompatibilityViolation{Kind: ViolationContractNotFound, Reason: e.Error()}
+	case *stdlib.InvalidDeclarationKindChangeError:
+		return CompatibilityViolation{Kind: ViolationDeclarationKindChange, Declaration: e.Name, Reason: e.Error()}
+	case *stdlib.MissingDeclarationError:
+		return CompatibilityViolation{Kind: ViolationMissingDeclaration, Declaration: e.Name, Reason: e.Error()}
+	case *stdlib.ExtraneousFieldError:
+		return CompatibilityViolation{Kind: ViolationNewField, Declaration: e.DeclName, Reason: e.Error()}
+	case *stdlib.FieldMismatchError:
+		return CompatibilityViolation{
+			Kind:        ViolationFieldTypeChange,
+			Declaration: e.DeclName,
+			Reason:      fmt.Sprintf("%s, %s", e.Error(), e.SecondaryError()),
+		}
+	case *stdlib.ConformanceMismatchError:
+		return CompatibilityViolation{Kind: ViolationConformanceChange, Declaration: e.DeclName, Reason: e.Error()}
+	case *stdlib.EnumCaseMismatchError:
+		return CompatibilityViolation{Kind: ViolationEnumCaseChange, Reason: e.Error()}
+	case *stdlib.MissingEnumCasesError:
+		return CompatibilityViolation{Kind: ViolationMissingEnumCases, Declaration: e.DeclName, Reason: e.Error()}
+	}
+
+	return CompatibilityViolation{Kind: ViolationOther, Reason: err.Error()}
+}
+
 func (p *Program) reload() {
 	astProgram, err := parser.ParseProgram(nil, p.script.Code(), parser.Config{})
 	if err != nil {
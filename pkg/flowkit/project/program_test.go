@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/onflow/flow-go-sdk"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -99,6 +100,50 @@ func TestProgram(t *testing.T) {
 		}
 	})
 
+	t.Run("Address Imports", func(t *testing.T) {
+		program, err := NewProgram(&testScript{code: []byte(`
+			import "Bar"
+			import Zoo from "./Zoo.cdc"
+			import Foo, Baz from 0x01
+			import Crypto
+
+			pub contract Qux {}
+		`)})
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]flow.Address{
+			"Foo": flow.HexToAddress("01"),
+			"Baz": flow.HexToAddress("01"),
+		}, program.AddressImports())
+	})
+
+	t.Run("With Location", func(t *testing.T) {
+		code := []byte(`
+			import Bar from "./Bar.cdc"
+			pub contract Foo {}
+		`)
+		program, err := NewProgram(&testScript{code: code})
+		require.NoError(t, err)
+
+		relocated := program.WithLocation("contracts/Foo.cdc")
+		assert.Equal(t, "contracts/Foo.cdc", relocated.Location())
+		assert.Equal(t, "", program.Location())
+
+		contracts := []*Contract{NewContract("Bar", "contracts/Bar.cdc", nil, flow.HexToAddress("0x1"), "", nil)}
+
+		// relative imports of the original can't be resolved without its location
+		_, err = NewImportReplacer(contracts, nil).Replace(program)
+		assert.EqualError(t, err, "import ./Bar.cdc could not be resolved from provided contracts")
+
+		replaced, err := NewImportReplacer(contracts, nil).Replace(relocated)
+		require.NoError(t, err)
+		assert.Contains(t, string(replaced.Code()), "import Bar from 0x0000000000000001")
+
+		// the original is not changed by replacing the imports of the copy
+		assert.Equal(t, code, program.Code())
+		assert.True(t, program.HasImports())
+	})
+
 	t.Run("Name", func(t *testing.T) {
 		tests := []struct {
 			code []byte
@@ -176,4 +221,343 @@ func TestProgram(t *testing.T) {
 		assert.Equal(t, string(replaced), string(program.Code()))
 	})
 
+	t.Run("Event Declarations", func(t *testing.T) {
+		code := []byte(`
+			pub contract Foo {
+				pub event Deposited(id: UInt64, to: Address?)
+				pub event Listed(prices: {String: UFix64}, tags: [String])
+				pub event Empty()
+
+				pub resource NFT {}
+			}
+		`)
+
+		program, err := NewProgram(&testScript{code: code})
+		require.NoError(t, err)
+
+		assert.Equal(t, []EventDeclaration{{
+			Name: "Deposited",
+			Fields: []EventField{
+				{Name: "id", Type: "UInt64"},
+				{Name: "to", Type: "Address?"},
+			},
+		}, {
+			Name: "Listed",
+			Fields: []EventField{
+				{Name: "prices", Type: "{String: UFix64}"},
+				{Name: "tags", Type: "[String]"},
+			},
+		}, {
+			Name:   "Empty",
+			Fields: []EventField{},
+		}}, program.EventDeclarations())
+
+		code = []byte(`
+			pub contract interface Bar {
+				pub event Withdrawn(amount: UFix64, from: Address?)
+			}
+		`)
+
+		program, err = NewProgram(&testScript{code: code})
+		require.NoError(t, err)
+
+		assert.Equal(t, []EventDeclaration{{
+			Name: "Withdrawn",
+			Fields: []EventField{
+				{Name: "amount", Type: "UFix64"},
+				{Name: "from", Type: "Address?"},
+			},
+		}}, program.EventDeclarations())
+
+		program, err = NewProgram(&testScript{code: []byte(`pub fun main() {}`)})
+		require.NoError(t, err)
+		assert.Empty(t, program.EventDeclarations())
+	})
+
+	t.Run("Emitable Events", func(t *testing.T) {
+		code := []byte(`
+			pub contract interface Token {
+				pub event Withdrawn(amount: UFix64, from: Address?)
+
+				pub resource interface Provider {}
+
+				pub resource Vault {
+					pub event Burned(amount: UFix64)
+				}
+			}
+
+			pub contract FlowToken: Token {
+				pub event Withdrawn(amount: UFix64, from: Address?)
+				pub event Minted(amount: UFix64)
+
+				pub resource interface Receiver {}
+
+				pub resource Vault {
+					pub event Burned(amount: UFix64)
+				}
+			}
+		`)
+
+		program, err := NewProgram(&testScript{code: code})
+		require.NoError(t, err)
+
+		names := func(events []EventDeclaration) []string {
+			eventNames := make([]string, len(events))
+			for i, event := range events {
+				eventNames[i] = event.Name
+			}
+			return eventNames
+		}
+
+		assert.Equal(t, []string{"Withdrawn", "Minted", "Burned"}, names(program.EmitableEvents()))
+		assert.Equal(t, []string{"Withdrawn", "Minted", "Burned", "Withdrawn", "Burned"}, names(program.EventDeclarations()))
+
+		program, err = NewProgram(&testScript{code: []byte(`
+			pub contract interface Bar {
+				pub event Withdrawn(amount: UFix64, from: Address?)
+			}
+		`)})
+		require.NoError(t, err)
+		assert.Empty(t, program.EmitableEvents())
+	})
+
+	t.Run("Checksum", func(t *testing.T) {
+		unix := "pub contract Foo {\n\tpub fun bar() {}\n}\n"
+		sources := []string{
+			unix,
+			"pub contract Foo {\r\n\tpub fun bar() {}\r\n}\r\n",
+			"pub contract Foo {\r\tpub fun bar() {}\r}",
+			"pub contract Foo {  \n\tpub fun bar() {}\t\n}\n\n",
+		}
+
+		program, err := NewProgram(&testScript{code: []byte(unix)})
+		require.NoError(t, err)
+		checksum := program.Checksum()
+		assert.Len(t, checksum, 64)
+
+		for _, source := range sources {
+			program, err := NewProgram(&testScript{code: []byte(source)})
+			require.NoError(t, err)
+			assert.Equal(t, checksum, program.Checksum(), fmt.Sprintf("%q", source))
+		}
+
+		program, err = NewProgram(&testScript{code: []byte("pub contract Foo {\n\tpub fun baz() {}\n}\n")})
+		require.NoError(t, err)
+		assert.NotEqual(t, checksum, program.Checksum())
+	})
+
+	t.Run("Constants", func(t *testing.T) {
+		program, err := NewProgram(&testScript{code: []byte(`
+			pub let maxSupply: UInt64 = 10_000
+			pub let count = 42
+			access(all) let fee = 0.5
+			pub let offset = -1.25
+			pub let name = "Kitty"
+			pub let paused = false
+			pub let treasury: Address = 0x01cf0e2f2f715450
+			pub let limits: [Int] = [1, 2]
+			let hidden = 1
+			pub var mutable = 1
+
+			pub contract Foo {
+				pub let bar: Int
+				init() { self.bar = 1 }
+			}
+		`)})
+		require.NoError(t, err)
+
+		assert.Equal(t, []ConstantDeclaration{
+			{Name: "maxSupply", Type: "UInt64", Value: "10_000"},
+			{Name: "count", Type: "Int", Value: "42"},
+			{Name: "fee", Type: "UFix64", Value: "0.5"},
+			{Name: "offset", Type: "Fix64", Value: "-1.25"},
+			{Name: "name", Type: "String", Value: `"Kitty"`},
+			{Name: "paused", Type: "Bool", Value: "false"},
+			{Name: "treasury", Type: "Address", Value: "0x01cf0e2f2f715450"},
+			{Name: "limits", Type: "[Int]", Value: "[1, 2]"},
+		}, program.Constants())
+
+		program, err = NewProgram(&testScript{code: []byte(`pub contract Foo {}`)})
+		require.NoError(t, err)
+		assert.Empty(t, program.Constants())
+	})
+
+	t.Run("Type Declarations", func(t *testing.T) {
+		program, err := NewProgram(&testScript{code: []byte(`
+			pub contract Market {
+				pub struct Listing {}
+				access(contract) struct Offer {}
+				pub enum Status: UInt8 {
+					pub case open
+					pub case closed
+				}
+				pub event Listed(id: UInt64)
+				pub resource Collection: CollectionPublic {
+					pub enum Sort: UInt8 {
+						pub case price
+					}
+				}
+				pub resource interface CollectionPublic {}
+			}
+		`)})
+		require.NoError(t, err)
+
+		assert.Equal(t, []TypeDeclaration{
+			{Name: "Market", Kind: "contract", Access: "pub"},
+			{Name: "Market.Listing", Kind: "struct", Access: "pub"},
+			{Name: "Market.Offer", Kind: "struct", Access: "access(contract)"},
+			{Name: "Market.Status", Kind: "enum", Access: "pub"},
+			{Name: "Market.Listed", Kind: "event", Access: "pub"},
+			{Name: "Market.Collection", Kind: "resource", Access: "pub"},
+			{Name: "Market.Collection.Sort", Kind: "enum", Access: "pub"},
+			{Name: "Market.CollectionPublic", Kind: "resource interface", Access: "pub"},
+		}, program.TypeDeclarations())
+		assert.Equal(t, []string{"Market.Listing", "Market.Offer"}, program.StructDeclarations())
+		assert.Equal(t, []string{"Market.Status", "Market.Collection.Sort"}, program.EnumDeclarations())
+
+		program, err = NewProgram(&testScript{code: []byte(`
+			struct interface Named {}
+			struct Point {}
+			pub fun main() {}
+		`)})
+		require.NoError(t, err)
+
+		assert.Equal(t, []TypeDeclaration{
+			{Name: "Point", Kind: "struct", Access: ""},
+			{Name: "Named", Kind: "struct interface", Access: ""},
+		}, program.TypeDeclarations())
+		assert.Empty(t, program.EnumDeclarations())
+	})
+
+}
+
+func TestProgram_IsUpgradeable(t *testing.T) {
+	deployed := []byte(`
+		pub contract Foo {
+			pub var count: Int
+			pub let owner: Address
+
+			pub enum Color: UInt8 {
+				pub case red
+				pub case green
+			}
+
+			pub resource NFT {
+				pub let id: UInt64
+				init(id: UInt64) { self.id = id }
+			}
+
+			pub fun increment(): Int {
+				self.count = self.count + 1
+				return self.count
+			}
+
+			init() {
+				self.count = 0
+				self.owner = self.account.address
+			}
+		}
+	`)
+
+	program, err := NewProgram(&testScript{code: deployed, location: "./Foo.cdc"})
+	require.NoError(t, err)
+
+	t.Run("Compatible", func(t *testing.T) {
+		upgradeable, violations, err := program.IsUpgradeable([]byte(`
+			pub contract Foo {
+				pub var count: Int
+
+				pub enum Color: UInt8 {
+					pub case red
+					pub case green
+					pub case blue
+				}
+
+				pub resource NFT {
+					pub let id: UInt64
+					init(id: UInt64) { self.id = id }
+				}
+
+				pub struct Info {
+					pub let name: String
+					init(name: String) { self.name = name }
+				}
+
+				pub fun increment(by: Int): Int {
+					self.count = self.count + by
+					return self.count
+				}
+
+				init() {
+					self.count = 0
+				}
+			}
+		`))
+		require.NoError(t, err)
+		assert.True(t, upgradeable)
+		assert.Len(t, violations, 0)
+	})
+
+	t.Run("Incompatible", func(t *testing.T) {
+		upgradeable, violations, err := program.IsUpgradeable([]byte(`
+			pub contract Foo {
+				pub var count: UInt64
+				pub let owner: Address
+				pub let name: String
+
+				pub enum Color: UInt8 {
+					pub case red
+				}
+
+				pub fun increment(): UInt64 {
+					self.count = self.count + 1
+					return self.count
+				}
+
+				init() {
+					self.count = 0
+					self.owner = self.account.address
+					self.name = "foo"
+				}
+			}
+		`))
+		require.NoError(t, err)
+		assert.False(t, upgradeable)
+
+		kinds := make(map[string]CompatibilityViolation)
+		for _, violation := range violations {
+			kinds[violation.Kind] = violation
+		}
+		require.Len(t, kinds, 4)
+		assert.Equal(t, CompatibilityViolation{
+			Kind:        ViolationFieldTypeChange,
+			Declaration: "Foo",
+			Reason:      "mismatching field `count` in `Foo`, incompatible type annotations. expected `Int`, found `UInt64`",
+		}, kinds[ViolationFieldTypeChange])
+		assert.Equal(t, "Foo: found new field `name` in `Foo`", kinds[ViolationNewField].String())
+		assert.Equal(t, "Color", kinds[ViolationMissingEnumCases].Declaration)
+		assert.Equal(t, "NFT", kinds[ViolationMissingDeclaration].Declaration)
+	})
+
+	t.Run("Declaration Kind Change", func(t *testing.T) {
+		upgradeable, violations, err := program.IsUpgradeable([]byte(`pub contract interface Foo {}`))
+		require.NoError(t, err)
+		assert.False(t, upgradeable)
+		require.Len(t, violations, 1)
+		assert.Equal(t, ViolationDeclarationKindChange, violations[0].Kind)
+		assert.Equal(t, "Foo", violations[0].Declaration)
+	})
+
+	t.Run("Contract Not Found", func(t *testing.T) {
+		upgradeable, violations, err := program.IsUpgradeable([]byte(`pub fun main() {}`))
+		require.NoError(t, err)
+		assert.False(t, upgradeable)
+		require.Len(t, violations, 1)
+		assert.Equal(t, ViolationContractNotFound, violations[0].Kind)
+	})
+
+	t.Run("Invalid Source", func(t *testing.T) {
+		_, _, err := program.IsUpgradeable([]byte(`pub contract Foo {`))
+		assert.Error(t, err)
+	})
 }
@@ -0,0 +1,67 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package flowkit
+
+import (
+	"fmt"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+)
+
+// RecoveredAccount is an on-chain account found to have a key matching a private key.
+type RecoveredAccount struct {
+	Network  string
+	Address  flow.Address
+	KeyIndex int
+	SigAlgo  crypto.SignatureAlgorithm
+	HashAlgo crypto.HashAlgorithm
+	Weight   int
+	// PrivateKey is the private key decoded on the curve of the matching key.
+	PrivateKey crypto.PrivateKey
+}
+
+// AddRecoveredAccounts adds the recovered accounts to the state and returns them.
+//
+// Accounts are named with the prefix and their network, e.g. alice-testnet, a number is appended to names
+// already taken by other accounts.
+func (p *State) AddRecoveredAccounts(namePrefix string, recovered []RecoveredAccount) ([]*Account, error) {
+	if namePrefix == "" {
+		return nil, fmt.Errorf("name prefix for recovered accounts is required")
+	}
+
+	added := make([]*Account, 0, len(recovered))
+	for _, r := range recovered {
+		name := fmt.Sprintf("%s-%s", namePrefix, r.Network)
+		for i := 2; ; i++ {
+			if _, err := p.Accounts().ByName(name); err != nil {
+				break
+			}
+			name = fmt.Sprintf("%s-%s-%d", namePrefix, r.Network, i)
+		}
+
+		account := NewAccount(name).
+			SetAddress(r.Address).
+			SetKey(NewHexAccountKeyFromPrivateKey(r.KeyIndex, r.HashAlgo, r.PrivateKey))
+		p.Accounts().AddOrUpdate(account)
+		added = append(added, account)
+	}
+
+	return added, nil
+}
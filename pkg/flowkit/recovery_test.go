@@ -0,0 +1,91 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package flowkit
+
+import (
+	"testing"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-cli/pkg/flowkit/config"
+)
+
+func TestAddRecoveredAccounts(t *testing.T) {
+	af := afero.Afero{Fs: afero.NewMemMapFs()}
+	state, err := Init(af, crypto.ECDSA_P256, crypto.SHA3_256)
+	require.NoError(t, err)
+
+	privateKey, err := crypto.DecodePrivateKeyHex(
+		crypto.ECDSA_secp256k1,
+		"ae2cc975dcd0af87ad7bba8e33cc38ce52dc4e0a6ecfa0d3e8b2c65a7a3fb5d1",
+	)
+	require.NoError(t, err)
+
+	state.Accounts().AddOrUpdate(NewAccount("alice-mainnet").
+		SetAddress(flow.HexToAddress("0x03")).
+		SetKey(NewHexAccountKeyFromPrivateKey(0, crypto.SHA3_256, privateKey)))
+
+	recovered := []RecoveredAccount{{
+		Network:    "testnet",
+		Address:    flow.HexToAddress("0x01"),
+		KeyIndex:   2,
+		SigAlgo:    crypto.ECDSA_secp256k1,
+		HashAlgo:   crypto.SHA2_256,
+		Weight:     1000,
+		PrivateKey: privateKey,
+	}, {
+		Network:    "mainnet",
+		Address:    flow.HexToAddress("0x02"),
+		SigAlgo:    crypto.ECDSA_secp256k1,
+		HashAlgo:   crypto.SHA3_256,
+		Weight:     1000,
+		PrivateKey: privateKey,
+	}}
+
+	added, err := state.AddRecoveredAccounts("alice", recovered)
+	require.NoError(t, err)
+	require.Len(t, added, 2)
+
+	testnet, err := state.Accounts().ByName("alice-testnet")
+	require.NoError(t, err)
+	assert.Equal(t, flow.HexToAddress("0x01"), testnet.Address())
+	assert.Equal(t, 2, testnet.Key().Index())
+	assert.Equal(t, crypto.ECDSA_secp256k1, testnet.Key().SigAlgo())
+	assert.Equal(t, crypto.SHA2_256, testnet.Key().HashAlgo())
+
+	// the name of the existing account isn't reused
+	mainnet, err := state.Accounts().ByName("alice-mainnet-2")
+	require.NoError(t, err)
+	assert.Equal(t, flow.HexToAddress("0x02"), mainnet.Address())
+	assert.Equal(t, added[1], mainnet)
+
+	// the recovered accounts are saved as valid configuration
+	require.NoError(t, state.SaveDefault())
+	reloaded, err := Load([]string{config.DefaultPath}, af)
+	require.NoError(t, err)
+	_, err = reloaded.Accounts().ByName("alice-mainnet-2")
+	assert.NoError(t, err)
+
+	_, err = state.AddRecoveredAccounts("", recovered)
+	assert.EqualError(t, err, "name prefix for recovered accounts is required")
+}
@@ -0,0 +1,109 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package flowkit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow-go-sdk"
+)
+
+// RedactedArgument replaces the value of a redacted argument when it's displayed.
+const RedactedArgument = "[redacted]"
+
+// ScriptHash returns the hex encoded SHA-256 hash of the script code, used to configure redaction rules.
+func ScriptHash(script []byte) string {
+	hash := sha256.Sum256(script)
+	return hex.EncodeToString(hash[:])
+}
+
+// RedactionRule selects transaction arguments whose values must not be displayed.
+//
+// If Match is set it decides which arguments are redacted, otherwise the argument
+// at ArgumentIndex of the script with ScriptHash is redacted.
+type RedactionRule struct {
+	ScriptHash    string
+	ArgumentIndex int
+	// Match is called with the script hash, the argument index and the decoded argument, the argument is nil if it can't be decoded.
+	Match func(scriptHash string, index int, argument cadence.Value) bool
+}
+
+func (r RedactionRule) matches(scriptHash string, index int, argument []byte) bool {
+	if r.Match == nil {
+		return r.ScriptHash == scriptHash && r.ArgumentIndex == index
+	}
+
+	value, err := jsoncdc.Decode(nil, argument)
+	if err != nil {
+		value = nil
+	}
+	return r.Match(scriptHash, index, value)
+}
+
+// Redactor replaces the transaction arguments matched by the redaction rules when they are displayed.
+//
+// Redaction only applies to displaying arguments, the transaction arguments are never changed.
+// A nil redactor doesn't redact any argument.
+type Redactor struct {
+	rules []RedactionRule
+}
+
+// NewRedactor returns a new redactor with the rules.
+func NewRedactor(rules ...RedactionRule) *Redactor {
+	return &Redactor{rules: rules}
+}
+
+// AddRules adds the rules to the redactor.
+func (r *Redactor) AddRules(rules ...RedactionRule) {
+	r.rules = append(r.rules, rules...)
+}
+
+// Redacted returns true if the argument at the index of the script must not be displayed.
+func (r *Redactor) Redacted(script []byte, index int, argument []byte) bool {
+	if r == nil || len(r.rules) == 0 {
+		return false
+	}
+
+	scriptHash := ScriptHash(script)
+	for _, rule := range r.rules {
+		if rule.matches(scriptHash, index, argument) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Arguments returns the JSON-Cadence encoded transaction arguments for display, with the redacted arguments
+// replaced by the RedactedArgument marker.
+func (r *Redactor) Arguments(tx *flow.Transaction) []string {
+	arguments := make([]string, len(tx.Arguments))
+	for i, argument := range tx.Arguments {
+		if r.Redacted(tx.Script, i, argument) {
+			arguments[i] = RedactedArgument
+			continue
+		}
+		arguments[i] = string(argument)
+	}
+
+	return arguments
+}
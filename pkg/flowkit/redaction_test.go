@@ -0,0 +1,99 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package flowkit_test
+
+import (
+	"testing"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-cli/pkg/flowkit"
+)
+
+func TestRedactor(t *testing.T) {
+	script := []byte(`transaction(apiKey: String, user: String, amount: UFix64) {}`)
+
+	newTransaction := func(t *testing.T) *flow.Transaction {
+		amount, err := cadence.NewUFix64("1.5")
+		require.NoError(t, err)
+
+		tx := flow.NewTransaction().SetScript(script)
+		for _, arg := range []cadence.Value{cadence.String("secret"), cadence.String("alice"), amount} {
+			require.NoError(t, tx.AddArgument(arg))
+		}
+		return tx
+	}
+
+	t.Run("Script Hash and Index", func(t *testing.T) {
+		tx := newTransaction(t)
+		encoded := make([][]byte, len(tx.Arguments))
+		copy(encoded, tx.Arguments)
+
+		redactor := flowkit.NewRedactor(flowkit.RedactionRule{
+			ScriptHash:    flowkit.ScriptHash(script),
+			ArgumentIndex: 0,
+		})
+
+		arguments := redactor.Arguments(tx)
+		assert.Equal(t, flowkit.RedactedArgument, arguments[0])
+		assert.Equal(t, string(tx.Arguments[1]), arguments[1])
+		assert.Equal(t, string(tx.Arguments[2]), arguments[2])
+		assert.Equal(t, encoded, tx.Arguments)
+	})
+
+	t.Run("Other Script", func(t *testing.T) {
+		tx := newTransaction(t)
+		redactor := flowkit.NewRedactor(flowkit.RedactionRule{
+			ScriptHash:    flowkit.ScriptHash([]byte(`transaction(apiKey: String) {}`)),
+			ArgumentIndex: 0,
+		})
+
+		assert.NotContains(t, redactor.Arguments(tx), flowkit.RedactedArgument)
+	})
+
+	t.Run("Predicate", func(t *testing.T) {
+		tx := newTransaction(t)
+		redactor := flowkit.NewRedactor()
+		redactor.AddRules(flowkit.RedactionRule{
+			Match: func(_ string, _ int, argument cadence.Value) bool {
+				_, isString := argument.(cadence.String)
+				return isString
+			},
+		})
+
+		arguments := redactor.Arguments(tx)
+		assert.Equal(t, []string{flowkit.RedactedArgument, flowkit.RedactedArgument, string(tx.Arguments[2])}, arguments)
+
+		value, err := jsoncdc.Decode(nil, tx.Arguments[0])
+		require.NoError(t, err)
+		assert.Equal(t, cadence.String("secret"), value)
+	})
+
+	t.Run("Nil Redactor", func(t *testing.T) {
+		tx := newTransaction(t)
+		var redactor *flowkit.Redactor
+
+		assert.False(t, redactor.Redacted(tx.Script, 0, tx.Arguments[0]))
+		assert.Equal(t, string(tx.Arguments[0]), redactor.Arguments(tx)[0])
+	})
+}
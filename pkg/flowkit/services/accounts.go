@@ -20,10 +20,16 @@ package services
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/parser"
 	tmpl "github.com/onflow/flow-core-contracts/lib/go/templates"
 	"github.com/onflow/flow-go-sdk"
 	"github.com/onflow/flow-go-sdk/crypto"
@@ -42,9 +48,27 @@ import (
 
 // Accounts is a service that handles all account-related interactions.
 type Accounts struct {
-	gateway gateway.Gateway
-	state   *flowkit.State
-	logger  output.Logger
+	gateway     gateway.Gateway
+	state       *flowkit.State
+	logger      output.Logger
+	tracing     *tracing
+	diagnostics *diagnostics
+	confirm     ConfirmationOptions
+	sequences   *SequenceManager
+	// forked accounts deploy to a local fork of the network, so the deployments aren't recorded in the lock file.
+	forked       bool
+	nameResolver NameResolver
+}
+
+// ErrOperationCancelled is returned when the user didn't confirm the operation.
+var ErrOperationCancelled = errors.New("operation cancelled")
+
+// ConfirmationOptions configure confirmation of operations with irreversible costs, such as creating accounts on mainnet.
+type ConfirmationOptions struct {
+	RequireConfirmation  bool
+	ConfirmationCallback func(network string) bool
+	// RemoveContractCallback is called with the impact of removing a contract, before it's removed.
+	RemoveContractCallback func(report *ImpactReport) bool
 }
 
 // NewAccounts returns a new accounts service.
@@ -54,14 +78,54 @@ func NewAccounts(
 	logger output.Logger,
 ) *Accounts {
 	return &Accounts{
-		gateway: gateway,
-		state:   state,
-		logger:  logger,
+		gateway:   gateway,
+		state:     state,
+		logger:    logger,
+		sequences: NewSequenceManager(gateway),
 	}
 }
 
+// newSharedAccounts returns an accounts service for the internal use of another service, sharing its tracing and
+// the sequences of its proposal keys, so the transactions sent by both services don't reuse a sequence number.
+func newSharedAccounts(
+	gateway gateway.Gateway,
+	state *flowkit.State,
+	logger output.Logger,
+	tracing *tracing,
+	sequences *SequenceManager,
+	forked bool,
+) *Accounts {
+	accounts := NewAccounts(gateway, state, logger)
+	accounts.tracing = tracing
+	accounts.sequences = sequences
+	accounts.forked = forked
+	return accounts
+}
+
+// SetConfirmation sets the confirmation options used before executing operations with irreversible costs.
+func (a *Accounts) SetConfirmation(options ConfirmationOptions) {
+	a.confirm = options
+}
+
+// SetNameResolver resolves the account selectors that are not account names with the resolver, nil disables it.
+//
+// Names resolved by the resolver never shadow accounts in the state with the same name.
+func (a *Accounts) SetNameResolver(resolver NameResolver) {
+	a.nameResolver = resolver
+}
+
 // Get returns an account by on address.
-func (a *Accounts) Get(address flow.Address) (*flow.Account, error) {
+func (a *Accounts) Get(address flow.Address) (account *flow.Account, err error) {
+	err = a.diagnostics.run(a.tracing, "Accounts.Get", []Attribute{
+		{Key: "address", Value: address.String()},
+	}, func(op *operation) error {
+		account, err = a.traced(op).get(address)
+		return err
+	})
+	return account, err
+}
+
+func (a *Accounts) get(address flow.Address) (*flow.Account, error) {
 	a.logger.StartProgress(fmt.Sprintf("Loading %s...", address))
 
 	account, err := a.gateway.GetAccount(address)
@@ -70,52 +134,341 @@ func (a *Accounts) Get(address flow.Address) (*flow.Account, error) {
 	return account, err
 }
 
+// GetMultiple returns the accounts selected by the selectors.
+//
+// A selector is an address, an account name or a tag selector like "tag:admin", which selects all
+// the accounts tagged with it and fails if no account is tagged with it. Names registered on-chain,
+// like alice.find, are selected if a name resolver is set. Each account is fetched once even if
+// more selectors select it.
+func (a *Accounts) GetMultiple(selectors []string) (accounts []*flow.Account, err error) {
+	err = a.diagnostics.run(a.tracing, "Accounts.GetMultiple", []Attribute{
+		{Key: "selectors", Value: strings.Join(selectors, ",")},
+	}, func(op *operation) error {
+		accounts, err = a.traced(op).getMultiple(selectors)
+		return err
+	})
+	return accounts, err
+}
+
+func (a *Accounts) getMultiple(selectors []string) ([]*flow.Account, error) {
+	addresses, err := a.resolveAddresses(selectors)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := make([]*flow.Account, 0, len(addresses))
+	for _, address := range addresses {
+		account, err := a.Get(address)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get account 0x%s: %w", address, err)
+		}
+		accounts = append(accounts, account)
+	}
+
+	return accounts, nil
+}
+
+// resolveAddresses expands the selectors to the unique addresses they select.
+func (a *Accounts) resolveAddresses(selectors []string) ([]flow.Address, error) {
+	addresses := make([]flow.Address, 0, len(selectors))
+	selected := make(map[flow.Address]bool)
+	add := func(address flow.Address) {
+		if !selected[address] {
+			selected[address] = true
+			addresses = append(addresses, address)
+		}
+	}
+
+	for _, selector := range selectors {
+		if flowkit.IsAccountTagSelector(selector) {
+			if a.state == nil {
+				return nil, config.ErrDoesNotExist
+			}
+			accounts, err := a.state.ResolveAccountSelectors([]string{selector})
+			if err != nil {
+				return nil, err
+			}
+			for _, account := range accounts {
+				add(account.Address())
+			}
+			continue
+		}
+
+		// account names take precedence over addresses and names registered on-chain
+		if a.state != nil {
+			if account, err := a.state.Accounts().ByName(selector); err == nil {
+				add(account.Address())
+				continue
+			}
+		}
+
+		if a.nameResolver != nil {
+			address, ok, err := a.nameResolver.ResolveName(selector)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				add(address)
+				continue
+			}
+		}
+
+		address, ok := util.ParseAddress(selector)
+		if !ok {
+			return nil, fmt.Errorf("account selector %s is not an account name, a tag selector or a valid address", selector)
+		}
+		add(address)
+	}
+
+	return addresses, nil
+}
+
+// PublicKeys returns the public keys of the account that are not revoked.
+//
+// flowkit.Account only holds the local configuration, so the keys are fetched
+// from the network by the account address.
+func (a *Accounts) PublicKeys(address flow.Address) (keys []flow.AccountKey, err error) {
+	err = a.diagnostics.run(a.tracing, "Accounts.PublicKeys", []Attribute{
+		{Key: "address", Value: address.String()},
+	}, func(op *operation) error {
+		keys, err = a.traced(op).publicKeys(address, false)
+		return err
+	})
+	return keys, err
+}
+
+// AllPublicKeys returns all the public keys of the account including the revoked ones.
+func (a *Accounts) AllPublicKeys(address flow.Address) (keys []flow.AccountKey, err error) {
+	err = a.diagnostics.run(a.tracing, "Accounts.AllPublicKeys", []Attribute{
+		{Key: "address", Value: address.String()},
+	}, func(op *operation) error {
+		keys, err = a.traced(op).publicKeys(address, true)
+		return err
+	})
+	return keys, err
+}
+
+func (a *Accounts) publicKeys(address flow.Address, includeRevoked bool) ([]flow.AccountKey, error) {
+	account, err := a.Get(address)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]flow.AccountKey, 0, len(account.Keys))
+	for _, key := range account.Keys {
+		if key.Revoked && !includeRevoked {
+			continue
+		}
+		keys = append(keys, *key)
+	}
+
+	return keys, nil
+}
+
+// ContractSummary contains the metadata of a contract deployed to an account.
+type ContractSummary struct {
+	Name      string
+	SizeBytes int
+	SHA256    string
+	// ParsedOK is true if the contract code could be parsed locally.
+	ParsedOK bool
+}
+
+// ListContracts returns the summaries of all the contracts deployed to the account, sorted by name.
+func (a *Accounts) ListContracts(address flow.Address) (summaries []ContractSummary, err error) {
+	err = a.diagnostics.run(a.tracing, "Accounts.ListContracts", []Attribute{
+		{Key: "address", Value: address.String()},
+	}, func(op *operation) error {
+		summaries, err = a.traced(op).listContracts(address)
+		return err
+	})
+	return summaries, err
+}
+
+func (a *Accounts) listContracts(address flow.Address) ([]ContractSummary, error) {
+	account, err := a.get(address)
+	if err != nil {
+		return nil, err
+	}
+
+	names := maps.Keys(account.Contracts)
+	slices.Sort(names)
+
+	summaries := make([]ContractSummary, len(names))
+	for i, name := range names {
+		code := account.Contracts[name]
+		hash := sha256.Sum256(code)
+		_, parseErr := parser.ParseProgram(nil, code, parser.Config{})
+
+		summaries[i] = ContractSummary{
+			Name:      name,
+			SizeBytes: len(code),
+			SHA256:    hex.EncodeToString(hash[:]),
+			ParsedOK:  parseErr == nil,
+		}
+	}
+
+	return summaries, nil
+}
+
+// StakingInfoWarning describes a part of the staking info that couldn't be fetched.
+type StakingInfoWarning struct {
+	// NodeID is the node the warning relates to, empty if it relates to all the account stakes or delegations.
+	NodeID string
+	Err    error
+}
+
+func (w StakingInfoWarning) String() string {
+	if w.NodeID == "" {
+		return w.Err.Error()
+	}
+	return fmt.Sprintf("node %s: %s", w.NodeID, w.Err.Error())
+}
+
+// StakingInfoResult contains the staking and delegation information for an account.
+//
+// If some of the information couldn't be fetched the result is partial and the
+// warnings describe what is missing.
+type StakingInfoResult struct {
+	Staking       []map[string]interface{}
+	Delegation    []map[string]interface{}
+	PartialResult bool
+	Warnings      []StakingInfoWarning
+}
+
+func (r *StakingInfoResult) warn(nodeID string, err error) {
+	r.PartialResult = true
+	r.Warnings = append(r.Warnings, StakingInfoWarning{NodeID: nodeID, Err: err})
+}
+
+// defaultStakingInfoPageSize is the number of nodes or delegators fetched by a single staking info script.
+const defaultStakingInfoPageSize = 50
+
+// stakingNodeInfoPageScript returns the info of a page of the nodes in the staking collection of the account.
+const stakingNodeInfoPageScript = `import FlowStakingCollection from 0x%s
+import FlowIDTableStaking from 0x%s
+
+pub fun main(address: Address, offset: Int, limit: Int): [FlowIDTableStaking.NodeInfo] {
+	let nodeIDs = FlowStakingCollection.getNodeIDs(address: address)
+	var infos: [FlowIDTableStaking.NodeInfo] = []
+	var i = offset
+	while i < nodeIDs.length && i < offset + limit {
+		infos.append(FlowIDTableStaking.NodeInfo(nodeID: nodeIDs[i]))
+		i = i + 1
+	}
+	return infos
+}
+`
+
+// stakingDelegatorInfoPageScript returns the info of a page of the delegators in the staking collection of the account.
+const stakingDelegatorInfoPageScript = `import FlowStakingCollection from 0x%s
+import FlowIDTableStaking from 0x%s
+
+pub fun main(address: Address, offset: Int, limit: Int): [FlowIDTableStaking.DelegatorInfo] {
+	let delegatorIDs = FlowStakingCollection.getDelegatorIDs(address: address)
+	var infos: [FlowIDTableStaking.DelegatorInfo] = []
+	var i = offset
+	while i < delegatorIDs.length && i < offset + limit {
+		let ids = delegatorIDs[i]
+		infos.append(FlowIDTableStaking.DelegatorInfo(nodeID: ids.delegatorNodeID, delegatorID: ids.delegatorID))
+		i = i + 1
+	}
+	return infos
+}
+`
+
+// StakingInfoOptions configures how the staking information is fetched.
+type StakingInfoOptions struct {
+	// PageSize is the number of nodes or delegators fetched by a single script, defaults to 50.
+	//
+	// Accounts staking or delegating to many nodes need smaller pages if the scripts hit the computation limit.
+	PageSize int
+}
+
 // StakingInfo returns the staking and delegation information for an account.
-func (a *Accounts) StakingInfo(address flow.Address) ([]map[string]interface{}, []map[string]interface{}, error) {
+//
+// Failing to fetch a part of the information doesn't fail the whole call, instead the
+// partial result is returned with warnings. An error is only returned if no information could be fetched.
+func (a *Accounts) StakingInfo(address flow.Address) (info *StakingInfoResult, err error) {
+	err = a.diagnostics.run(a.tracing, "Accounts.StakingInfo", []Attribute{
+		{Key: "address", Value: address.String()},
+	}, func(op *operation) error {
+		info, err = a.traced(op).stakingInfo(address, StakingInfoOptions{})
+		return err
+	})
+	return info, err
+}
+
+// StakingInfoWithOptions returns the staking and delegation information for an account same as StakingInfo,
+// fetching the nodes and delegators in pages of the configured size.
+func (a *Accounts) StakingInfoWithOptions(address flow.Address, options StakingInfoOptions) (info *StakingInfoResult, err error) {
+	err = a.diagnostics.run(a.tracing, "Accounts.StakingInfoWithOptions", []Attribute{
+		{Key: "address", Value: address.String()},
+	}, func(op *operation) error {
+		info, err = a.traced(op).stakingInfo(address, options)
+		return err
+	})
+	return info, err
+}
+
+func (a *Accounts) stakingInfo(address flow.Address, options StakingInfoOptions) (*StakingInfoResult, error) {
+	pageSize := options.PageSize
+	if pageSize < 0 {
+		return nil, fmt.Errorf("staking info page size must not be negative")
+	}
+	if pageSize == 0 {
+		pageSize = defaultStakingInfoPageSize
+	}
+
 	a.logger.StartProgress(fmt.Sprintf("Fetching info for %s...", address.String()))
 	defer a.logger.StopProgress()
 
-	cadenceAddress := []cadence.Value{cadence.NewAddress(address)}
+	cadenceAddress := cadence.NewAddress(address)
 
 	chain, err := util.GetAddressNetwork(address)
 	if err != nil {
-		return nil, nil, fmt.Errorf(
+		return nil, fmt.Errorf(
 			"failed to determine network from address, check the address and network",
 		)
 	}
 
 	if chain == flow.Emulator {
-		return nil, nil, fmt.Errorf("emulator chain not supported")
+		return nil, fmt.Errorf("emulator chain not supported")
 	}
 
 	env := util.EnvFromNetwork(chain)
 
-	stakingInfoScript := tmpl.GenerateCollectionGetAllNodeInfoScript(env)
-	delegationInfoScript := tmpl.GenerateCollectionGetAllDelegatorInfoScript(env)
+	stakingInfoScript := []byte(fmt.Sprintf(stakingNodeInfoPageScript, env.LockedTokensAddress, env.IDTableAddress))
+	delegationInfoScript := []byte(fmt.Sprintf(stakingDelegatorInfoPageScript, env.LockedTokensAddress, env.IDTableAddress))
 
-	stakingValue, err := a.gateway.ExecuteScript(stakingInfoScript, cadenceAddress)
-	if err != nil {
-		return nil, nil, fmt.Errorf("error getting staking info: %s", err.Error())
+	result := &StakingInfoResult{
+		Staking:    make([]map[string]interface{}, 0),
+		Delegation: make([]map[string]interface{}, 0),
 	}
 
-	delegationValue, err := a.gateway.ExecuteScript(delegationInfoScript, cadenceAddress)
-	if err != nil {
-		return nil, nil, fmt.Errorf("error getting delegation info: %s", err.Error())
+	stakingInfos, stakingErr := a.fetchStakingInfo(stakingInfoScript, cadenceAddress, pageSize)
+	if stakingErr != nil {
+		stakingErr = fmt.Errorf("error getting staking info: %w", stakingErr)
+		result.warn("", stakingErr)
+	} else {
+		result.Staking = stakingInfos
 	}
 
-	// get staking infos and delegation infos
-	stakingInfos, err := flowkit.NewStakingInfoFromValue(stakingValue)
-	if err != nil {
-		return nil, nil, fmt.Errorf("error parsing staking info: %s", err.Error())
+	delegationInfos, delegationErr := a.fetchStakingInfo(delegationInfoScript, cadenceAddress, pageSize)
+	if delegationErr != nil {
+		delegationErr = fmt.Errorf("error getting delegation info: %w", delegationErr)
+		result.warn("", delegationErr)
+	} else {
+		result.Delegation = delegationInfos
 	}
-	delegationInfos, err := flowkit.NewStakingInfoFromValue(delegationValue)
-	if err != nil {
-		return nil, nil, fmt.Errorf("error parsing delegation info: %s", err.Error())
+
+	if stakingErr != nil && delegationErr != nil {
+		return nil, fmt.Errorf("%s, %s", stakingErr.Error(), delegationErr.Error())
 	}
 
 	// get a set of node ids from all staking infos
 	nodeStakes := make(map[string]cadence.Value)
-	for _, stakingInfo := range stakingInfos {
+	for _, stakingInfo := range result.Staking {
 		nodeID, ok := stakingInfo["id"]
 		if ok {
 			nodeStakes[nodeIDToString(nodeID)] = nil
@@ -127,23 +480,57 @@ func (a *Accounts) StakingInfo(address flow.Address) ([]map[string]interface{},
 	for nodeID := range nodeStakes {
 		stake, err := a.gateway.ExecuteScript(totalCommitmentScript, []cadence.Value{cadence.String(nodeID)})
 		if err != nil {
-			return nil, nil, fmt.Errorf("error getting total stake for node: %s", err.Error())
+			result.warn(nodeID, fmt.Errorf("error getting total stake for node: %w", err))
+			continue
 		}
 
 		nodeStakes[nodeID] = stake
 	}
 
 	// foreach staking info, add the node total stake
-	for _, stakingInfo := range stakingInfos {
+	for _, stakingInfo := range result.Staking {
 		nodeID, ok := stakingInfo["id"]
-		if ok {
-			stakingInfo["nodeTotalStake"] = nodeStakes[nodeIDToString(nodeID)].(cadence.UFix64)
+		if !ok {
+			continue
+		}
+		if stake, ok := nodeStakes[nodeIDToString(nodeID)].(cadence.UFix64); ok {
+			stakingInfo["nodeTotalStake"] = stake
 		}
 	}
 
-	a.logger.StopProgress()
+	for _, warning := range result.Warnings {
+		output.Warn(a.logger, warning.String())
+	}
+
+	return result, nil
+}
 
-	return stakingInfos, delegationInfos, nil
+// fetchStakingInfo executes the paged staking info script until a page is shorter than the page size.
+func (a *Accounts) fetchStakingInfo(
+	script []byte,
+	address cadence.Address,
+	pageSize int,
+) ([]map[string]interface{}, error) {
+	infos := make([]map[string]interface{}, 0)
+	for offset := 0; ; offset += pageSize {
+		value, err := a.gateway.ExecuteScript(
+			script,
+			[]cadence.Value{address, cadence.NewInt(offset), cadence.NewInt(pageSize)},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		page, err := flowkit.NewStakingInfoFromValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing info: %w", err)
+		}
+
+		infos = append(infos, page...)
+		if len(page) < pageSize {
+			return infos, nil
+		}
+	}
 }
 
 func nodeIDToString(value interface{}) string {
@@ -151,7 +538,18 @@ func nodeIDToString(value interface{}) string {
 }
 
 // NodeTotalStake returns the total stake including delegations of a node.
-func (a *Accounts) NodeTotalStake(nodeId string, chain flow.ChainID) (*cadence.Value, error) {
+func (a *Accounts) NodeTotalStake(nodeId string, chain flow.ChainID) (stake *cadence.Value, err error) {
+	err = a.diagnostics.run(a.tracing, "Accounts.NodeTotalStake", []Attribute{
+		{Key: "nodeID", Value: nodeId},
+		{Key: "chain", Value: chain.String()},
+	}, func(op *operation) error {
+		stake, err = a.traced(op).nodeTotalStake(nodeId, chain)
+		return err
+	})
+	return stake, err
+}
+
+func (a *Accounts) nodeTotalStake(nodeId string, chain flow.ChainID) (*cadence.Value, error) {
 	a.logger.StartProgress(fmt.Sprintf("Fetching total stake for node id %s...", nodeId))
 	defer a.logger.StopProgress()
 
@@ -172,11 +570,65 @@ func (a *Accounts) NodeTotalStake(nodeId string, chain flow.ChainID) (*cadence.V
 	return &stakingValue, nil
 }
 
+// ErrUnexpectedAddress is returned when an account is created at a different address than expected.
+var ErrUnexpectedAddress = errors.New("account created at unexpected address")
+
+type createOptions struct {
+	expectedAddress *flow.Address
+	chainID         *flow.ChainID
+	waitPolicy      gateway.WaitPolicy
+}
+
+// CreateOption configures the account creation.
+type CreateOption func(*createOptions)
+
+// WithExpectedAddress checks that the new account is created at the address, otherwise ErrUnexpectedAddress is returned.
+//
+// This is useful with an emulator generating deterministic addresses, where fixtures can rely on the addresses.
+func WithExpectedAddress(address flow.Address) CreateOption {
+	return func(o *createOptions) {
+		o.expectedAddress = &address
+	}
+}
+
+// WithChainID creates the account on the chain, e.g. a private Flow instance with a custom chain ID.
+//
+// The created address is taken from the AccountCreated event as reported by the chain. On the standard
+// chains it's also checked to be a valid address of the chain, addresses of custom chains can't be checked
+// since they may not be derived the same way. The mainnet confirmation is only asked for on the mainnet chain.
+func WithChainID(chainID flow.ChainID) CreateOption {
+	return func(o *createOptions) {
+		o.chainID = &chainID
+	}
+}
+
+// WithWaitPolicy waits for the account creation transaction with the policy instead of the policy of the services.
+//
+// The address of the account is known once the transaction is executed, waiting for the seal only makes
+// sure the creation is final.
+func WithWaitPolicy(policy gateway.WaitPolicy) CreateOption {
+	return func(o *createOptions) {
+		o.waitPolicy = policy
+	}
+}
+
+// linearCodeChains are the chains with addresses derived by the linear code of the Flow SDK, which can be validated.
+var linearCodeChains = map[flow.ChainID]bool{
+	flow.Mainnet:    true,
+	flow.Testnet:    true,
+	flow.Sandboxnet: true,
+	flow.Emulator:   true,
+	flow.Localnet:   true,
+	flow.Benchnet:   true,
+	flow.BftTestnet: true,
+}
+
 // Create creates and returns a new account.
 //
 // The new account is created with the given public keys and contracts.
 //
-// The account creation transaction is signed by the specified signer.
+// The account creation transaction is signed by the specified signer. Contracts are parsed
+// before the transaction is built, use CreateForNetwork if they import other contracts.
 func (a *Accounts) Create(
 	signer *flowkit.Account,
 	pubKeys []crypto.PublicKey,
@@ -184,7 +636,63 @@ func (a *Accounts) Create(
 	sigAlgo []crypto.SignatureAlgorithm,
 	hashAlgo []crypto.HashAlgorithm,
 	contractArgs []string,
+	options ...CreateOption,
+) (account *flow.Account, err error) {
+	err = a.diagnostics.run(a.tracing, "Accounts.Create", []Attribute{
+		accountInput("signer", signer),
+		{Key: "keys", Value: fmt.Sprint(len(pubKeys))},
+	}, func(op *operation) error {
+		account, err = a.traced(op).create(signer, pubKeys, keyWeights, sigAlgo, hashAlgo, contractArgs, "", options)
+		return err
+	})
+	return account, err
+}
+
+// CreateForNetwork creates and returns a new account same as Create, resolving
+// the contract imports from the deployments on the network.
+//
+// A nil signer is replaced by the default signer configured for the network.
+func (a *Accounts) CreateForNetwork(
+	signer *flowkit.Account,
+	pubKeys []crypto.PublicKey,
+	keyWeights []int,
+	sigAlgo []crypto.SignatureAlgorithm,
+	hashAlgo []crypto.HashAlgorithm,
+	contractArgs []string,
+	network string,
+	options ...CreateOption,
+) (account *flow.Account, err error) {
+	err = a.diagnostics.run(a.tracing, "Accounts.CreateForNetwork", []Attribute{
+		accountInput("signer", signer),
+		{Key: "keys", Value: fmt.Sprint(len(pubKeys))},
+		{Key: "network", Value: network},
+	}, func(op *operation) error {
+		account, err = a.traced(op).create(signer, pubKeys, keyWeights, sigAlgo, hashAlgo, contractArgs, network, options)
+		return err
+	})
+	return account, err
+}
+
+func (a *Accounts) create(
+	signer *flowkit.Account,
+	pubKeys []crypto.PublicKey,
+	keyWeights []int,
+	sigAlgo []crypto.SignatureAlgorithm,
+	hashAlgo []crypto.HashAlgorithm,
+	contractArgs []string,
+	network string,
+	options []CreateOption,
 ) (*flow.Account, error) {
+	signer, err := resolveSigner(a.state, signer, network)
+	if err != nil {
+		return nil, err
+	}
+
+	createOpts := &createOptions{}
+	for _, option := range options {
+		option(createOpts)
+	}
+
 	if a.state == nil {
 		return nil, config.ErrDoesNotExist
 	}
@@ -221,29 +729,47 @@ func (a *Accounts) Create(
 	}
 
 	contracts := make([]templates.Contract, 0)
+	invalid := &ContractsValidationError{}
 	for _, contract := range contractArgs {
 		contractFlagContent := strings.SplitN(contract, ":", 2)
 		if len(contractFlagContent) != 2 {
 			return nil, fmt.Errorf("wrong format for contract. Correct format is name:path, but got: %s", contract)
 		}
 
-		contractSource, err := a.state.ReadFile(contractFlagContent[1])
+		name, location := contractFlagContent[0], contractFlagContent[1]
+		contractSource, err := a.state.ReadSource(location)
 		if err != nil {
 			return nil, err
 		}
 
+		code, err := a.prepareContract(name, flowkit.NewScript(contractSource, nil, location), network)
+		if err != nil {
+			invalid.add(name, err)
+			continue
+		}
+
 		contracts = append(contracts, templates.Contract{
-			Name:   contractFlagContent[0],
-			Source: string(contractSource),
+			Name:   name,
+			Source: string(code),
 		})
 	}
 
+	if len(invalid.Contracts) > 0 {
+		return nil, invalid
+	}
+
+	if createOpts.chainID == nil || *createOpts.chainID == flow.Mainnet {
+		if err := a.confirmMainnet(signer.Address()); err != nil {
+			return nil, err
+		}
+	}
+
 	tx, err := flowkit.NewCreateAccountTransaction(signer, accKeys, contracts)
 	if err != nil {
 		return nil, err
 	}
 
-	tx, err = a.prepareTransaction(tx, signer)
+	tx, err = a.prepareTransaction(tx, signer, network)
 	if err != nil {
 		return nil, err
 	}
@@ -252,20 +778,25 @@ func (a *Accounts) Create(
 	a.logger.StartProgress("Creating account...")
 	defer a.logger.StopProgress()
 
-	sentTx, err := a.gateway.SendSignedTransaction(tx)
+	sentTx, err := a.sequences.send(tx)
 	if err != nil {
 		return nil, errors.Wrap(err, "account creation transaction failed")
 	}
 
-	a.logger.StartProgress("Waiting for transaction to be sealed...")
+	policy := createOpts.waitPolicy
+	if policy == gateway.WaitDefault {
+		policy = a.sequences.WaitPolicy()
+	}
+	a.logger.StartProgress(fmt.Sprintf("Waiting for transaction to be %s...", policy))
 
-	result, err := a.gateway.GetTransactionResult(sentTx.ID(), true)
+	// the created address is only found in the events, they are retained even if results skip events by default
+	result, err := a.sequences.resultWithPolicy(tx, sentTx, policy, flow.EventAccountCreated)
 	if err != nil {
 		return nil, err
 	}
 
 	if result.Error != nil {
-		return nil, result.Error
+		return nil, flowkit.NewCadenceRuntimeError(result.Error)
 	}
 
 	events := flowkit.EventsFromTransaction(result)
@@ -273,134 +804,923 @@ func (a *Accounts) Create(
 	if len(newAccountAddress) == 0 {
 		return nil, fmt.Errorf("new account address couldn't be fetched")
 	}
+	if chainID := createOpts.chainID; chainID != nil && linearCodeChains[*chainID] && !newAccountAddress[0].IsValid(*chainID) {
+		return nil, fmt.Errorf("created address 0x%s is not a valid address on chain %s", newAccountAddress[0], *chainID)
+	}
+	if createOpts.expectedAddress != nil && *newAccountAddress[0] != *createOpts.expectedAddress {
+		return nil, fmt.Errorf(
+			"%w: expected 0x%s, created 0x%s",
+			ErrUnexpectedAddress,
+			createOpts.expectedAddress,
+			newAccountAddress[0],
+		)
+	}
 
 	a.logger.StopProgress()
 
-	return a.gateway.GetAccount(*newAccountAddress[0]) // we know it's the only and first event
+	account, err := a.gateway.GetAccount(*newAccountAddress[0]) // we know it's the only and first event
+	if err != nil {
+		return nil, err
+	}
+	if createOpts.chainID != nil {
+		// the address reported by the custom chain is authoritative, don't rely on how the gateway encodes it
+		account.Address = *newAccountAddress[0]
+	}
+
+	return account, nil
 }
 
-var errUpdateNoDiff = errors.New("contract already exists and is the same as the contract provided for update")
+// ContractsValidationError contains all the contracts that failed validation before being deployed.
+type ContractsValidationError struct {
+	// Contracts maps contract names to the validation errors.
+	Contracts map[string]error
+}
 
-// AddContract deploys a contract code to the account provided with possible update flag.
-func (a *Accounts) AddContract(
-	account *flowkit.Account,
-	contract *flowkit.Script,
-	network string,
-	updateExisting bool,
-) (flow.Identifier, bool, error) {
+func (c *ContractsValidationError) add(name string, err error) {
+	if c.Contracts == nil {
+		c.Contracts = make(map[string]error)
+	}
+	c.Contracts[name] = err
+}
+
+func (c *ContractsValidationError) Error() string {
+	names := maps.Keys(c.Contracts)
+	slices.Sort(names)
+
+	invalid := make([]string, len(names))
+	for i, name := range names {
+		invalid[i] = fmt.Sprintf("%s: %s", name, c.Contracts[name].Error())
+	}
+
+	return fmt.Sprintf("invalid contracts: %s", strings.Join(invalid, "; "))
+}
+
+// Unwrap returns the validation errors as a batch error of the invalid contracts sorted by name.
+func (c *ContractsValidationError) Unwrap() error {
+	names := maps.Keys(c.Contracts)
+	slices.Sort(names)
+
+	batch := flowkit.NewBatchError(names...)
+	for i, name := range names {
+		batch.Fail(i, c.Contracts[name])
+	}
+	return batch.Err()
+}
 
+// prepareContract parses the contract, replaces its imports for the network and checks
+// that the declared contract name matches the provided name.
+func (a *Accounts) prepareContract(name string, contract *flowkit.Script, network string) ([]byte, error) {
 	program, err := project.NewProgram(contract)
 	if err != nil {
-		return flow.EmptyID, false, err
+		return nil, parseErrorPositions(err)
 	}
 
 	if program.HasImports() {
 		contracts, err := a.state.DeploymentContractsByNetwork(network)
 		if err != nil {
-			return flow.EmptyID, false, err
+			return nil, err
 		}
 
-		importReplacer := project.NewImportReplacer(
-			contracts,
-			a.state.AliasesForNetwork(network),
-		)
+		importReplacer := newImportReplacer(a.state, contracts, network)
 
 		program, err = importReplacer.Replace(program)
 		if err != nil {
-			return flow.EmptyID, false, err
+			return nil, err
 		}
 	}
 
-	name, err := program.Name()
+	declared, err := program.Name()
 	if err != nil {
-		return flow.EmptyID, false, err
+		return nil, err
 	}
-
-	tx, err := flowkit.NewAddAccountContractTransaction(
-		account,
-		name,
-		program.Code(),
-		contract.Args,
-	)
-	if err != nil {
-		return flow.EmptyID, false, err
+	if declared != name {
+		return nil, fmt.Errorf("declared contract name %s doesn't match the provided name %s", declared, name)
 	}
 
-	a.logger.StartProgress(
-		fmt.Sprintf(
-			"%s contract '%s' on account '%s'...",
-			map[bool]string{true: "Updating", false: "Creating"}[updateExisting],
-			name,
-			account.Address(),
-		),
-	)
-	defer a.logger.StopProgress()
+	return program.Code(), nil
+}
 
-	// check if contract exists on account
-	flowAccount, err := a.gateway.GetAccount(account.Address())
-	if err != nil {
-		return flow.EmptyID, false, err
-	}
-	existingContract, exists := flowAccount.Contracts[name]
-	noDiffInContract := bytes.Equal(program.Code(), existingContract)
-	if exists && noDiffInContract {
-		return flow.EmptyID, false, errUpdateNoDiff
-	}
-	if exists && !updateExisting {
-		return flow.EmptyID, false, fmt.Errorf(
-			fmt.Sprintf("contract %s exists in account %s", name, account.Name()),
-		)
+// parseErrorPositions formats the parser errors with their positions on a single line.
+func parseErrorPositions(err error) error {
+	var parseErr parser.Error
+	if !errors.As(err, &parseErr) {
+		return err
 	}
 
-	// if we are updating contract
-	if exists && updateExisting {
-		tx, err = flowkit.NewUpdateAccountContractTransaction(
-			account,
-			name,
-			contract.Code(),
-		)
-		if err != nil {
-			return flow.EmptyID, false, err
+	messages := make([]string, len(parseErr.Errors))
+	for i, childErr := range parseErr.Errors {
+		if positioned, ok := childErr.(ast.HasPosition); ok {
+			pos := positioned.StartPosition()
+			messages[i] = fmt.Sprintf("%d:%d: %s", pos.Line, pos.Column, childErr.Error())
+			continue
 		}
+		messages[i] = childErr.Error()
 	}
 
-	tx, err = a.prepareTransaction(tx, account)
-	if err != nil {
-		return flow.EmptyID, false, err
-	}
+	return fmt.Errorf("parsing failed: %s", strings.Join(messages, ", "))
+}
+
+// confirmMainnet asks for confirmation if the address belongs to mainnet and confirmation is required,
+// otherwise it warns that the operation is executed on mainnet without confirmation.
+func (a *Accounts) confirmMainnet(address flow.Address) error {
+	chain, err := util.GetAddressNetwork(address)
+	if err != nil || chain != flow.Mainnet {
+		return nil
+	}
+
+	network := config.DefaultMainnetNetwork().Name
+	if !a.confirm.RequireConfirmation || a.confirm.ConfirmationCallback == nil {
+		output.Warn(a.logger, fmt.Sprintf("executing operation on %s without explicit confirmation", network))
+		return nil
+	}
+
+	if !a.confirm.ConfirmationCallback(network) {
+		return ErrOperationCancelled
+	}
+
+	return nil
+}
+
+var errUpdateNoDiff = errors.New("contract already exists and is the same as the contract provided for update")
+
+// AddContractOptions contains options for adding a contract to an account.
+type AddContractOptions struct {
+	// Metadata is recorded in the lock file together with the deployment.
+	Metadata map[string]string
+	// SkipIfIdentical skips the deployment without an error if the contract on the account has the same code.
+	//
+	// A skipped deployment returns an empty transaction ID and no update.
+	SkipIfIdentical bool
+	// StrictImports requires the contract imports to resolve to the addresses pinned for the network in the lock file,
+	// also enabled by the strictImports network configuration.
+	StrictImports bool
+	// AutoNormalize replaces the Windows line endings (CRLF) of the contract code with \n before deploying,
+	// otherwise contracts with Windows line endings are rejected with a CRLFError.
+	AutoNormalize bool
+	// IgnoreFormatting compares the code with the contract on the account after formatting both with
+	// project.FormatCadence, so a contract differing only in formatting is identical. The deployed code is never formatted.
+	IgnoreFormatting bool
+	// WaitPolicy is the status the deployment transaction is waited for, the policy of the services if WaitDefault.
+	// The deployment is only recorded in the lock file once the transaction is sealed.
+	WaitPolicy gateway.WaitPolicy
+}
+
+// ErrCRLFDetected is returned when a contract code has Windows line endings (CRLF),
+// which would deploy a code with a different checksum than the same contract checked out with \n line endings.
+var ErrCRLFDetected = errors.New("contract code has Windows line endings (CRLF)")
+
+// maxReportedCRLFLines limits the line numbers listed in the CRLFError message.
+const maxReportedCRLFLines = 10
+
+// CRLFError is returned when a contract code has Windows line endings, it wraps ErrCRLFDetected.
+type CRLFError struct {
+	Location string
+	// Lines are the 1-based numbers of the lines ending in CRLF.
+	Lines []int
+}
+
+func (c *CRLFError) Error() string {
+	reported := c.Lines
+	if len(reported) > maxReportedCRLFLines {
+		reported = reported[:maxReportedCRLFLines]
+	}
+
+	lines := make([]string, len(reported))
+	for i, line := range reported {
+		lines[i] = strconv.Itoa(line)
+	}
+	if len(c.Lines) > len(reported) {
+		lines = append(lines, fmt.Sprintf("and %d more", len(c.Lines)-len(reported)))
+	}
+
+	return fmt.Sprintf("%s at %s on lines %s", ErrCRLFDetected, c.Location, strings.Join(lines, ", "))
+}
+
+func (c *CRLFError) Unwrap() error {
+	return ErrCRLFDetected
+}
+
+// crlfLines returns the 1-based numbers of the lines of the code ending in CRLF.
+func crlfLines(code []byte) []int {
+	lines := make([]int, 0)
+	for i, line := range bytes.Split(code, []byte("\n")) {
+		if bytes.HasSuffix(line, []byte("\r")) {
+			lines = append(lines, i+1)
+		}
+	}
+	return lines
+}
+
+// checkLineEndings rejects the contract if its code has Windows line endings, unless normalize is set
+// in which case a copy of the contract with \n line endings is returned.
+func checkLineEndings(contract *flowkit.Script, normalize bool) (*flowkit.Script, error) {
+	lines := crlfLines(contract.Code())
+	if len(lines) == 0 {
+		return contract, nil
+	}
+	if !normalize {
+		return nil, &CRLFError{Location: contract.Location(), Lines: lines}
+	}
+
+	code := bytes.ReplaceAll(contract.Code(), []byte("\r\n"), []byte("\n"))
+	return flowkit.NewScript(code, contract.Args, contract.Location()), nil
+}
+
+// sanitizeContract checks the contract code with the source limits and removes a byte order mark from it.
+func sanitizeContract(contract *flowkit.Script, limits flowkit.SourceLimits) (*flowkit.Script, error) {
+	code, err := flowkit.SanitizeSource(contract.Location(), contract.Code(), limits)
+	if err != nil {
+		return nil, err
+	}
+	if len(code) == len(contract.Code()) {
+		return contract, nil
+	}
+
+	return flowkit.NewScript(code, contract.Args, contract.Location()), nil
+}
+
+// sourceLimits returns the source limits of the state, the default limits if there is no state.
+func (a *Accounts) sourceLimits() flowkit.SourceLimits {
+	if a.state == nil {
+		return flowkit.SourceLimits{}
+	}
+	return a.state.SourceLimits()
+}
+
+// AddContract deploys a contract code to the account provided with possible update flag.
+//
+// The deployment is recorded in the lock file. If the account is nil the contract is deployed
+// to the default signer configured for the network.
+func (a *Accounts) AddContract(
+	account *flowkit.Account,
+	contract *flowkit.Script,
+	network string,
+	updateExisting bool,
+) (ID flow.Identifier, updated bool, err error) {
+	err = a.diagnostics.run(a.tracing, "Accounts.AddContract", []Attribute{
+		accountInput("account", account),
+		scriptInput("contract", contract),
+		{Key: "network", Value: network},
+	}, func(op *operation) error {
+		ID, updated, err = a.traced(op).addContract(account, contract, network, updateExisting, AddContractOptions{})
+		return err
+	})
+	return ID, updated, err
+}
+
+// AddContractWithOptions deploys a contract code to the account same as AddContract,
+// recording the optional metadata provided by the caller together with the deployment.
+//
+// If the contract code is identical to the one on the account an error is returned,
+// unless the SkipIfIdentical option is set.
+func (a *Accounts) AddContractWithOptions(
+	account *flowkit.Account,
+	contract *flowkit.Script,
+	network string,
+	updateExisting bool,
+	options AddContractOptions,
+) (ID flow.Identifier, updated bool, err error) {
+	err = a.diagnostics.run(a.tracing, "Accounts.AddContractWithOptions", []Attribute{
+		accountInput("account", account),
+		scriptInput("contract", contract),
+		{Key: "network", Value: network},
+	}, func(op *operation) error {
+		ID, updated, err = a.traced(op).addContract(account, contract, network, updateExisting, options)
+		return err
+	})
+	return ID, updated, err
+}
+
+func (a *Accounts) addContract(
+	account *flowkit.Account,
+	contract *flowkit.Script,
+	network string,
+	updateExisting bool,
+	options AddContractOptions,
+) (flow.Identifier, bool, error) {
+	account, err := resolveSigner(a.state, account, network)
+	if err != nil {
+		return flow.EmptyID, false, err
+	}
+	if err := flowkit.ValidateDeploymentMetadata(options.Metadata); err != nil {
+		return flow.EmptyID, false, err
+	}
+
+	contract, err = sanitizeContract(contract, a.sourceLimits())
+	if err != nil {
+		return flow.EmptyID, false, err
+	}
+
+	contract, err = checkLineEndings(contract, options.AutoNormalize)
+	if err != nil {
+		return flow.EmptyID, false, err
+	}
+
+	program, name, err := a.resolveContract(contract, network, options.StrictImports)
+	if err != nil {
+		return flow.EmptyID, false, err
+	}
+
+	// the metadata is also registered on-chain if the network has a contract registry
+	var registryMetadata map[string]string
+	registry := flow.EmptyAddress
+	if a.state != nil {
+		if n, err := a.state.Networks().ByName(network); err == nil && n.ContractRegistry != "" {
+			registry = flow.HexToAddress(n.ContractRegistry)
+			registryMetadata = options.Metadata
+		}
+	}
+
+	tx, err := flowkit.NewAddAccountContractTransaction(
+		account,
+		name,
+		program.Code(),
+		contract.Args,
+		registryMetadata,
+		registry,
+	)
+	if err != nil {
+		return flow.EmptyID, false, err
+	}
+
+	a.logger.StartProgress(
+		fmt.Sprintf(
+			"%s contract '%s' on account '%s'...",
+			map[bool]string{true: "Updating", false: "Creating"}[updateExisting],
+			name,
+			account.Address(),
+		),
+	)
+	defer a.logger.StopProgress()
+
+	// check if contract exists on account
+	flowAccount, err := a.gateway.GetAccount(account.Address())
+	if err != nil {
+		return flow.EmptyID, false, err
+	}
+	existingContract, exists, err := a.checkExistingContract(
+		account,
+		flowAccount,
+		name,
+		program.Code(),
+		updateExisting,
+		options.IgnoreFormatting,
+	)
+	if errors.Is(err, errUpdateNoDiff) && options.SkipIfIdentical {
+		return flow.EmptyID, false, nil
+	}
+	if err != nil {
+		return flow.EmptyID, false, err
+	}
+
+	// if we are updating contract
+	if exists && updateExisting {
+		a.warnIncompatibleUpdate(name, existingContract, program.Code())
+
+		tx, err = flowkit.NewUpdateAccountContractTransactionWithSigner(
+			account,
+			flowAccount,
+			name,
+			program.Code(),
+		)
+		if err != nil {
+			return flow.EmptyID, false, err
+		}
+	}
+
+	tx, err = a.prepareTransaction(tx, account, network)
+	if err != nil {
+		return flow.EmptyID, false, err
+	}
 
 	a.logger.Info(fmt.Sprintf("Transaction ID: %s", tx.FlowTransaction().ID()))
 
 	// send transaction with contract
-	sentTx, err := a.gateway.SendSignedTransaction(tx)
+	sentTx, err := a.sequences.send(tx)
 	if err != nil {
 		return flow.EmptyID, false, fmt.Errorf("failed to send transaction to deploy a contract: %w", err)
 	}
 
-	// we wait for transaction to be sealed
-	trx, err := a.gateway.GetTransactionResult(sentTx.ID(), true)
+	trx, err := a.sequences.resultWithPolicy(tx, sentTx, options.WaitPolicy)
 	if err != nil {
 		return flow.EmptyID, false, err
 	}
 	if trx.Error != nil {
-		return flow.EmptyID, false, trx.Error
+		return flow.EmptyID, false, flowkit.NewCadenceRuntimeError(trx.Error)
+	}
+
+	// only final deployments are recorded in the lock file
+	if trx.Status != flow.TransactionStatusSealed && a.state != nil && !a.forked {
+		if _, err := a.sequences.EnsureSealed(sentTx.ID()); err != nil {
+			return flow.EmptyID, false, err
+		}
 	}
 
 	a.logger.StopProgress()
 	a.logger.Info(fmt.Sprintf(
-		"Contract '%s' %s on the account '%s'.",
+		"Contract '%s'%s %s on the account '%s'.",
 		name,
+		a.generatedBy(contract),
 		map[bool]string{true: "updated", false: "created"}[updateExisting],
 		account.Address(),
 	))
 
+	a.recordDeployment(network, name, account.Address(), program.Code(), sentTx.ID(), options.Metadata)
+
 	return sentTx.ID(), updateExisting, err
 }
 
+// generatedBy describes the code generator of the contract if it is configured as generated,
+// contracts are not generated without a state.
+func (a *Accounts) generatedBy(contract *flowkit.Script) string {
+	if a.state == nil {
+		return ""
+	}
+
+	configured, err := a.state.Contracts().ByLocation(contract.Location())
+	if err != nil || !configured.IsGenerated() {
+		return ""
+	}
+
+	return fmt.Sprintf(" (generated by %s)", configured.GeneratedBy)
+}
+
+// resolveContract parses the contract, replaces its imports for the network and returns the program with its declared name.
+//
+// In strict imports mode an ImportAllowlistError is returned if an import doesn't resolve to the pinned address.
+func (a *Accounts) resolveContract(
+	contract *flowkit.Script,
+	network string,
+	strict bool,
+) (*project.Program, string, error) {
+	program, err := project.NewProgram(contract)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if program.HasImports() {
+		if a.state == nil {
+			return nil, "", config.ErrDoesNotExist
+		}
+
+		contracts, err := a.state.DeploymentContractsByNetwork(network)
+		if err != nil {
+			return nil, "", err
+		}
+
+		importReplacer := newImportReplacer(a.state, contracts, network)
+
+		program, err = importReplacer.Replace(program)
+		if err != nil {
+			return nil, "", err
+		}
+
+		logImportWarnings(a.logger, importReplacer)
+
+		allowlist, strict, err := strictImportAllowlist(a.state, network, strict)
+		if err != nil {
+			return nil, "", err
+		}
+		if strict {
+			err := checkImportAllowlist(allowlist, network, importReplacer.Substitutions())
+			if err != nil {
+				return nil, "", err
+			}
+		}
+	}
+
+	name, err := program.Name()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return program, name, nil
+}
+
+// checkExistingContract returns the code of the contract on the account and whether it exists.
+//
+// An existing contract fails unless it's updated, errUpdateNoDiff is returned if the code is the same,
+// or only differs in formatting if formatting is ignored.
+func (a *Accounts) checkExistingContract(
+	account *flowkit.Account,
+	flowAccount *flow.Account,
+	name string,
+	code []byte,
+	updateExisting bool,
+	ignoreFormatting bool,
+) ([]byte, bool, error) {
+	existingContract, exists := flowAccount.Contracts[name]
+	compared, comparedCode := comparableCodes(a.logger, name, existingContract, code, ignoreFormatting)
+	if exists && bytes.Equal(comparedCode, compared) {
+		return existingContract, true, errUpdateNoDiff
+	}
+	if exists && !updateExisting {
+		return existingContract, true, fmt.Errorf("contract %s exists in account %s", name, account.Name())
+	}
+
+	return existingContract, exists, nil
+}
+
+// warnIncompatibleUpdate warns about the changes of the contract update the network is going to reject,
+// failing to check the compatibility only skips the warning.
+func (a *Accounts) warnIncompatibleUpdate(name string, existingCode []byte, newCode []byte) {
+	existing, err := project.NewProgram(flowkit.NewScript(existingCode, nil, ""))
+	if err != nil {
+		return
+	}
+
+	upgradeable, violations, err := existing.IsUpgradeable(newCode)
+	if err != nil || upgradeable {
+		return
+	}
+
+	for _, violation := range violations {
+		output.Warn(a.logger, fmt.Sprintf("contract %s update is not compatible, %s", name, violation))
+	}
+}
+
+// recordDeployment records the deployed contract in the lock file, failing to do so doesn't fail the deployment.
+func (a *Accounts) recordDeployment(
+	network string,
+	name string,
+	address flow.Address,
+	code []byte,
+	txID flow.Identifier,
+	metadata map[string]string,
+) {
+	if a.state == nil || a.forked {
+		return
+	}
+
+	hash := sha256.Sum256(code)
+	err := a.state.RecordDeployment(flowkit.DeploymentRecord{
+		Network:  network,
+		Contract: name,
+		Address:  address.String(),
+		CodeHash: hex.EncodeToString(hash[:]),
+		TxID:     txID.String(),
+		Metadata: metadata,
+	})
+	if err != nil {
+		a.logger.Error(fmt.Sprintf("Failed to record deployment of contract %s in the lock file: %s", name, err))
+	}
+}
+
+// ContractsDeploymentError is returned when a transaction deploying multiple contracts fails.
+type ContractsDeploymentError struct {
+	// Contract is the name of the contract whose code caused the failure, empty if it couldn't be determined.
+	Contract string
+	Err      error
+}
+
+func (c *ContractsDeploymentError) Error() string {
+	if c.Contract == "" {
+		return c.Err.Error()
+	}
+	return fmt.Sprintf("contract %s caused the deployment to fail: %s", c.Contract, c.Err.Error())
+}
+
+func (c *ContractsDeploymentError) Unwrap() error {
+	return c.Err
+}
+
+// AddContracts deploys multiple contracts to the account provided in a single transaction.
+//
+// Contracts are deployed in the order provided so any contract importing another contract being
+// deployed must come after it. Contracts that already exist on the account with the same code are skipped,
+// existing contracts are updated only if the update flag is set. The returned map contains the names of all
+// deployed contracts and whether they were updated. Each deployed contract is recorded in the lock file with the metadata.
+// The contracts are deployed to the default signer of the network if the account is nil.
+func (a *Accounts) AddContracts(
+	account *flowkit.Account,
+	contracts []*flowkit.Script,
+	network string,
+	updateExisting bool,
+	metadata map[string]string,
+) (ID flow.Identifier, updated map[string]bool, err error) {
+	err = a.diagnostics.run(a.tracing, "Accounts.AddContracts", []Attribute{
+		accountInput("account", account),
+		{Key: "contracts", Value: fmt.Sprint(len(contracts))},
+		{Key: "network", Value: network},
+	}, func(op *operation) error {
+		ID, updated, err = a.traced(op).addContracts(
+			account,
+			contracts,
+			network,
+			updateExisting,
+			AddContractOptions{Metadata: metadata},
+		)
+		return err
+	})
+	return ID, updated, err
+}
+
+// addContracts deploys the contracts, each contract is checked and resolved the same as by addContract.
+//
+// The metadata, strict imports, auto normalize and ignore formatting options apply to all the contracts.
+func (a *Accounts) addContracts(
+	account *flowkit.Account,
+	contracts []*flowkit.Script,
+	network string,
+	updateExisting bool,
+	options AddContractOptions,
+) (flow.Identifier, map[string]bool, error) {
+	account, err := resolveSigner(a.state, account, network)
+	if err != nil {
+		return flow.EmptyID, nil, err
+	}
+
+	if len(contracts) == 0 {
+		return flow.EmptyID, nil, fmt.Errorf("no contracts provided for deployment")
+	}
+	if err := flowkit.ValidateDeploymentMetadata(options.Metadata); err != nil {
+		return flow.EmptyID, nil, err
+	}
+
+	flowAccount, err := a.gateway.GetAccount(account.Address())
+	if err != nil {
+		return flow.EmptyID, nil, err
+	}
+
+	deployed := make(map[string]bool)
+	deployContracts := make([]flowkit.AccountContract, 0, len(contracts))
+	for _, contract := range contracts {
+		contract, err := sanitizeContract(contract, a.sourceLimits())
+		if err != nil {
+			return flow.EmptyID, nil, err
+		}
+
+		contract, err = checkLineEndings(contract, options.AutoNormalize)
+		if err != nil {
+			return flow.EmptyID, nil, err
+		}
+
+		program, name, err := a.resolveContract(contract, network, options.StrictImports)
+		if err != nil {
+			return flow.EmptyID, nil, err
+		}
+
+		existingContract, exists := flowAccount.Contracts[name]
+		compared, comparedCode := comparableCodes(a.logger, name, existingContract, program.Code(), options.IgnoreFormatting)
+		if exists && bytes.Equal(comparedCode, compared) {
+			continue
+		}
+		if exists && !updateExisting {
+			return flow.EmptyID, nil, fmt.Errorf("contract %s exists in account %s", name, account.Name())
+		}
+
+		deployContracts = append(deployContracts, flowkit.AccountContract{
+			Name:   name,
+			Source: program.Code(),
+			Args:   contract.Args,
+			Update: exists,
+		})
+		deployed[name] = exists
+	}
+
+	if len(deployContracts) == 0 {
+		return flow.EmptyID, nil, errUpdateNoDiff
+	}
+
+	tx, err := flowkit.NewDeployAccountContractsTransaction(account, deployContracts)
+	if err != nil {
+		return flow.EmptyID, nil, err
+	}
+
+	tx, err = a.prepareTransaction(tx, account, network)
+	if err != nil {
+		return flow.EmptyID, nil, err
+	}
+
+	a.logger.StartProgress(fmt.Sprintf(
+		"Deploying %d contracts on account '%s'...",
+		len(deployContracts),
+		account.Address(),
+	))
+	defer a.logger.StopProgress()
+
+	a.logger.Info(fmt.Sprintf("Transaction ID: %s", tx.FlowTransaction().ID()))
+
+	sentTx, err := a.sequences.send(tx)
+	if err != nil {
+		return flow.EmptyID, nil, fmt.Errorf("failed to send transaction to deploy contracts: %w", err)
+	}
+
+	result, err := a.sequences.result(tx, sentTx)
+	if err != nil {
+		return flow.EmptyID, nil, err
+	}
+	if result.Error != nil {
+		return flow.EmptyID, nil, &ContractsDeploymentError{
+			Contract: contractFromErrorLocation(result.Error, account.Address(), maps.Keys(deployed)),
+			Err:      flowkit.NewCadenceRuntimeError(result.Error),
+		}
+	}
+
+	for _, contract := range deployContracts {
+		a.recordDeployment(network, contract.Name, account.Address(), contract.Source, sentTx.ID(), options.Metadata)
+	}
+
+	return sentTx.ID(), deployed, nil
+}
+
+// contractFromErrorLocation finds the first contract of the provided names that is referenced as an
+// error location on the account (e.g. "--> f8d6e0586b0a20c7.Foo:3:4") in the Cadence error message.
+func contractFromErrorLocation(err error, address flow.Address, names []string) string {
+	locations := regexp.MustCompile(fmt.Sprintf(`(?:0x)?%s\.(\w+)`, address.Hex()))
+
+	for _, match := range locations.FindAllStringSubmatch(err.Error(), -1) {
+		if slices.Contains(names, match[1]) {
+			return match[1]
+		}
+	}
+
+	return ""
+}
+
+// SetCode updates multiple existing contracts on the account in a single transaction.
+//
+// Contracts are provided as a map of contract names to their source code, imports
+// are resolved for the provided network same as when adding a single contract.
+func (a *Accounts) SetCode(
+	account *flowkit.Account,
+	contracts map[string][]byte,
+	network string,
+) (ID flow.Identifier, err error) {
+	err = a.diagnostics.run(a.tracing, "Accounts.SetCode", []Attribute{
+		accountInput("account", account),
+		{Key: "contracts", Value: fmt.Sprint(len(contracts))},
+		{Key: "network", Value: network},
+	}, func(op *operation) error {
+		ID, err = a.traced(op).setCode(account, contracts, network)
+		return err
+	})
+	return ID, err
+}
+
+func (a *Accounts) setCode(
+	account *flowkit.Account,
+	contracts map[string][]byte,
+	network string,
+) (flow.Identifier, error) {
+	if len(contracts) == 0 {
+		return flow.EmptyID, fmt.Errorf("no contracts provided for update")
+	}
+
+	flowAccount, err := a.gateway.GetAccount(account.Address())
+	if err != nil {
+		return flow.EmptyID, err
+	}
+
+	codes := make(map[string][]byte, len(contracts))
+	for name, code := range contracts {
+		if _, exists := flowAccount.Contracts[name]; !exists {
+			return flow.EmptyID, fmt.Errorf(
+				"can not update a non-existing contract named '%s' on account %s",
+				name,
+				account.Address(),
+			)
+		}
+
+		location := ""
+		if a.state != nil {
+			if c, err := a.state.Contracts().ByNameAndNetwork(name, network); err == nil {
+				location = c.Location
+			}
+		}
+
+		program, err := project.NewProgram(flowkit.NewScript(code, nil, location))
+		if err != nil {
+			return flow.EmptyID, err
+		}
+
+		programName, err := program.Name()
+		if err != nil {
+			return flow.EmptyID, err
+		}
+		if programName != name {
+			return flow.EmptyID, fmt.Errorf("contract source for %s declares a different contract %s", name, programName)
+		}
+
+		if program.HasImports() {
+			if a.state == nil {
+				return flow.EmptyID, config.ErrDoesNotExist
+			}
+
+			deployContracts, err := a.state.DeploymentContractsByNetwork(network)
+			if err != nil {
+				return flow.EmptyID, err
+			}
+
+			importReplacer := newImportReplacer(a.state, deployContracts, network)
+
+			program, err = importReplacer.Replace(program)
+			if err != nil {
+				return flow.EmptyID, err
+			}
+
+			logImportWarnings(a.logger, importReplacer)
+		}
+
+		codes[name] = program.Code()
+	}
+
+	tx, err := flowkit.NewUpdateAccountContractsTransaction(account, codes)
+	if err != nil {
+		return flow.EmptyID, err
+	}
+
+	tx, err = a.prepareTransaction(tx, account, network)
+	if err != nil {
+		return flow.EmptyID, err
+	}
+
+	a.logger.Info(fmt.Sprintf("Transaction ID: %s", tx.FlowTransaction().ID()))
+	a.logger.StartProgress(
+		fmt.Sprintf("Updating %d contracts on account '%s'...", len(codes), account.Address()),
+	)
+	defer a.logger.StopProgress()
+
+	sentTx, err := a.sequences.send(tx)
+	if err != nil {
+		return flow.EmptyID, fmt.Errorf("failed to send transaction to update contracts: %w", err)
+	}
+
+	result, err := a.sequences.result(tx, sentTx, flow.EventAccountContractUpdated)
+	if err != nil {
+		return flow.EmptyID, err
+	}
+	if result.Error != nil {
+		return flow.EmptyID, flowkit.NewCadenceRuntimeError(result.Error)
+	}
+
+	events := flowkit.EventsFromTransaction(result)
+	updated := events.GetUpdatedContracts()
+	for name := range codes {
+		if !slices.Contains(updated, name) {
+			return flow.EmptyID, fmt.Errorf("contract %s was not updated on account %s", name, account.Address())
+		}
+	}
+
+	a.logger.StopProgress()
+	a.logger.Info(fmt.Sprintf(
+		"Contracts %s updated on the account '%s'.",
+		strings.Join(updated, ", "),
+		account.Address(),
+	))
+
+	return sentTx.ID(), nil
+}
+
+// RemoveContractOptions contains options for removing a contract from an account.
+type RemoveContractOptions struct {
+	// CleanupCapabilities unlinks capabilities of the account in the same transaction removing the contract,
+	// so they aren't left dangling.
+	CleanupCapabilities bool
+	// CapabilityPaths are the public and private paths unlinked when cleaning up capabilities, if empty
+	// the paths of the account linking types of the contract are found by scanning the account.
+	CapabilityPaths []cadence.Path
+	// Network is the network the contract is removed from, its configured compute limit caps the transaction.
+	// It's empty if the network isn't known.
+	Network string
+}
+
 // RemoveContract removes a contract from an account and returns the updated account.
 func (a *Accounts) RemoveContract(
 	account *flowkit.Account,
 	contractName string,
+) (ID flow.Identifier, err error) {
+	err = a.diagnostics.run(a.tracing, "Accounts.RemoveContract", []Attribute{
+		accountInput("account", account),
+		{Key: "contract", Value: contractName},
+	}, func(op *operation) error {
+		ID, err = a.traced(op).removeContract(account, contractName, RemoveContractOptions{})
+		return err
+	})
+	return ID, err
+}
+
+// RemoveContractWithOptions removes a contract from an account same as RemoveContract,
+// optionally unlinking capabilities in the same transaction.
+func (a *Accounts) RemoveContractWithOptions(
+	account *flowkit.Account,
+	contractName string,
+	options RemoveContractOptions,
+) (ID flow.Identifier, err error) {
+	err = a.diagnostics.run(a.tracing, "Accounts.RemoveContractWithOptions", []Attribute{
+		accountInput("account", account),
+		{Key: "contract", Value: contractName},
+	}, func(op *operation) error {
+		ID, err = a.traced(op).removeContract(account, contractName, options)
+		return err
+	})
+	return ID, err
+}
+
+func (a *Accounts) removeContract(
+	account *flowkit.Account,
+	contractName string,
+	options RemoveContractOptions,
 ) (flow.Identifier, error) {
 	// check if contracts exists on the account
 	flowAcc, err := a.gateway.GetAccount(account.Address())
@@ -417,12 +1737,35 @@ func (a *Accounts) RemoveContract(
 		)
 	}
 
-	tx, err := flowkit.NewRemoveAccountContractTransaction(account, contractName)
+	if a.confirm.RequireConfirmation && a.confirm.RemoveContractCallback != nil {
+		report, err := a.removeContractImpact(account.Address(), contractName, nil)
+		if err != nil {
+			return flow.EmptyID, err
+		}
+
+		if !a.confirm.RemoveContractCallback(report) {
+			return flow.EmptyID, ErrOperationCancelled
+		}
+	}
+
+	var tx *flowkit.Transaction
+	if options.CleanupCapabilities {
+		paths := options.CapabilityPaths
+		if len(paths) == 0 {
+			paths, err = a.linkedCapabilityPaths(account.Address(), contractName)
+			if err != nil {
+				return flow.EmptyID, err
+			}
+		}
+		tx, err = flowkit.NewRemoveAccountContractWithUnlinkTransaction(account, contractName, paths)
+	} else {
+		tx, err = flowkit.NewRemoveAccountContractTransaction(account, contractName)
+	}
 	if err != nil {
 		return flow.EmptyID, err
 	}
 
-	tx, err = a.prepareTransaction(tx, account)
+	tx, err = a.prepareTransaction(tx, account, options.Network)
 	if err != nil {
 		return flow.EmptyID, err
 	}
@@ -433,17 +1776,17 @@ func (a *Accounts) RemoveContract(
 	)
 	defer a.logger.StopProgress()
 
-	sentTx, err := a.gateway.SendSignedTransaction(tx)
+	sentTx, err := a.sequences.send(tx)
 	if err != nil {
 		return flow.EmptyID, err
 	}
 
-	txr, err := a.gateway.GetTransactionResult(sentTx.ID(), true)
+	txr, err := a.sequences.result(tx, sentTx)
 	if err != nil {
 		return flow.EmptyID, err
 	}
 	if txr != nil && txr.Error != nil {
-		return flow.EmptyID, txr.Error
+		return flow.EmptyID, flowkit.NewCadenceRuntimeError(txr.Error)
 	}
 
 	a.logger.StopProgress()
@@ -457,23 +1800,32 @@ func (a *Accounts) RemoveContract(
 }
 
 // prepareTransaction prepares transaction for sending with data from network
+//
+// The compute limit of the transaction is capped by the compute limit configured for the network, the network
+// is empty if it isn't known. If neither limits the transaction gateway.DefaultComputeLimit is used.
 func (a *Accounts) prepareTransaction(
 	tx *flowkit.Transaction,
 	account *flowkit.Account,
+	network string,
 ) (*flowkit.Transaction, error) {
-
 	block, err := a.gateway.GetLatestBlock()
 	if err != nil {
 		return nil, err
 	}
 
+	tx.SetGasLimit(a.computeLimit(tx.FlowTransaction().GasLimit, network))
+
 	proposer, err := a.gateway.GetAccount(account.Address())
 	if err != nil {
 		return nil, err
 	}
 
+	if err := account.CheckKeySigAlgo(proposer); err != nil {
+		return nil, err
+	}
+
 	tx.SetBlockReference(block)
-	if err = tx.SetProposer(proposer, account.Key().Index()); err != nil {
+	if err = a.sequences.setProposer(tx, proposer, account.Key().Index(), true); err != nil {
 		return nil, err
 	}
 
@@ -484,3 +1836,30 @@ func (a *Accounts) prepareTransaction(
 
 	return tx, nil
 }
+
+// computeLimit returns the lower of the transaction compute limit and the limit configured for the network, a zero
+// limit doesn't limit the transaction. If both are zero gateway.DefaultComputeLimit is returned and logged, the limit
+// of the network can't be queried since the Access API of the Flow SDK in use doesn't provide the node version info.
+func (a *Accounts) computeLimit(txLimit uint64, network string) uint64 {
+	var networkLimit uint64
+	if a.state != nil && network != "" {
+		if net, err := a.state.Networks().ByName(network); err == nil {
+			networkLimit = net.ComputeLimit
+		}
+	}
+
+	switch {
+	case txLimit == 0 && networkLimit == 0:
+		a.logger.Debug(fmt.Sprintf(
+			"no compute limit set for the transaction or configured for the network, using the default limit %d",
+			gateway.DefaultComputeLimit,
+		))
+		return gateway.DefaultComputeLimit
+	case txLimit == 0:
+		return networkLimit
+	case networkLimit == 0 || txLimit < networkLimit:
+		return txLimit
+	default:
+		return networkLimit
+	}
+}
@@ -19,6 +19,7 @@
 package services
 
 import (
+	"bytes"
 	"fmt"
 	"strings"
 	"testing"
@@ -30,8 +31,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slices"
 
 	"github.com/onflow/flow-cli/pkg/flowkit"
+	"github.com/onflow/flow-cli/pkg/flowkit/config"
 	"github.com/onflow/flow-cli/pkg/flowkit/gateway"
 	"github.com/onflow/flow-cli/pkg/flowkit/output"
 	"github.com/onflow/flow-cli/pkg/flowkit/tests"
@@ -69,6 +72,111 @@ func TestAccounts(t *testing.T) {
 		assert.Equal(t, serviceAddress, account.Address)
 	})
 
+	t.Run("Get Multiple", func(t *testing.T) {
+		state, s, gw := setup()
+		state.Accounts().AddOrUpdate(tests.Alice().SetTags([]string{"admin"}))
+		state.Accounts().AddOrUpdate(tests.Bob().SetTags([]string{"admin", "testnet-only"}))
+		state.Accounts().AddOrUpdate(tests.Charlie())
+
+		accounts, err := s.Accounts.GetMultiple([]string{"tag:admin", "Charlie", serviceAddress.String(), "Bob"})
+		require.NoError(t, err)
+
+		addresses := make([]flow.Address, len(accounts))
+		for i, account := range accounts {
+			addresses[i] = account.Address
+		}
+		assert.Equal(t, []flow.Address{
+			tests.Alice().Address(),
+			tests.Bob().Address(),
+			tests.Charlie().Address(),
+			serviceAddress,
+		}, addresses)
+		gw.Mock.AssertNumberOfCalls(t, tests.GetAccountFunc, 4)
+
+		_, err = s.Accounts.GetMultiple([]string{"tag:unknown"})
+		assert.EqualError(t, err, "account selector tag:unknown doesn't match any account")
+	})
+
+	t.Run("List Contracts", func(t *testing.T) {
+		_, s, gw := setup()
+
+		gw.GetAccount.Run(func(args mock.Arguments) {
+			account := tests.NewAccountWithAddress(args.Get(0).(flow.Address).String())
+			account.Contracts = map[string][]byte{
+				"Foo": []byte("pub contract Foo {}"),
+				"Bar": []byte("pub contract Bar {"),
+			}
+			gw.GetAccount.Return(account, nil)
+		})
+
+		contracts, err := s.Accounts.ListContracts(serviceAddress)
+		require.NoError(t, err)
+		assert.Equal(t, []ContractSummary{{
+			Name:      "Bar",
+			SizeBytes: 18,
+			SHA256:    "e9b3346d5a9c9767ada1ca0b19d5e7c1e47d596dead4229d5aa3f9142b9afd56",
+			ParsedOK:  false,
+		}, {
+			Name:      "Foo",
+			SizeBytes: 19,
+			SHA256:    "6ad8cdbb6d343a186acde030ce7857a0cf936ad89c4582cb93cc3db106b7cc70",
+			ParsedOK:  true,
+		}}, contracts)
+		gw.Mock.AssertCalled(t, tests.GetAccountFunc, serviceAddress)
+	})
+
+	t.Run("List Contracts No Contracts", func(t *testing.T) {
+		_, s, _ := setup()
+
+		contracts, err := s.Accounts.ListContracts(serviceAddress)
+		require.NoError(t, err)
+		assert.Len(t, contracts, 0)
+	})
+
+	t.Run("List Contracts Account Error", func(t *testing.T) {
+		_, s, gw := setup()
+
+		gw.GetAccount.Run(func(args mock.Arguments) {
+			gw.GetAccount.Return(nil, gateway.ErrAccountNotFound)
+		})
+
+		_, err := s.Accounts.ListContracts(serviceAddress)
+		assert.ErrorIs(t, err, gateway.ErrAccountNotFound)
+	})
+
+	t.Run("Public Keys", func(t *testing.T) {
+		_, s, gw := setup()
+
+		account := tests.NewAccountWithAddress(serviceAddress.String())
+		account.Keys[1].Revoked = true
+
+		gw.GetAccount.Run(func(args mock.Arguments) {
+			gw.GetAccount.Return(account, nil)
+		})
+
+		keys, err := s.Accounts.PublicKeys(serviceAddress)
+		require.NoError(t, err)
+		require.Len(t, keys, 1)
+		assert.Equal(t, *account.Keys[0], keys[0])
+
+		keys, err = s.Accounts.AllPublicKeys(serviceAddress)
+		require.NoError(t, err)
+		require.Len(t, keys, 2)
+		assert.Equal(t, *account.Keys[1], keys[1])
+		assert.True(t, keys[1].Revoked)
+	})
+
+	t.Run("Public Keys Account Error", func(t *testing.T) {
+		_, s, gw := setup()
+
+		gw.GetAccount.Run(func(args mock.Arguments) {
+			gw.GetAccount.Return(nil, gateway.ErrAccountNotFound)
+		})
+
+		_, err := s.Accounts.PublicKeys(serviceAddress)
+		assert.ErrorIs(t, err, gateway.ErrAccountNotFound)
+	})
+
 	t.Run("Create an Account", func(t *testing.T) {
 		_, s, gw := setup()
 		newAddress := flow.HexToAddress("192440c99cb17282")
@@ -149,6 +257,97 @@ func TestAccounts(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("Create an Account with Expected Address", func(t *testing.T) {
+		_, s, gw := setup()
+		newAddress := flow.HexToAddress("192440c99cb17282")
+
+		gw.GetTransactionResult.Return(tests.NewAccountCreateResult(newAddress), nil)
+
+		account, err := s.Accounts.Create(
+			serviceAcc,
+			[]crypto.PublicKey{pubKey},
+			[]int{1000},
+			[]crypto.SignatureAlgorithm{crypto.ECDSA_P256},
+			[]crypto.HashAlgorithm{crypto.SHA3_256},
+			nil,
+			WithExpectedAddress(newAddress),
+		)
+
+		require.NoError(t, err)
+		assert.NotNil(t, account)
+	})
+
+	t.Run("Create an Account with Unexpected Address", func(t *testing.T) {
+		_, s, gw := setup()
+
+		gw.GetTransactionResult.Return(
+			tests.NewAccountCreateResult(flow.HexToAddress("192440c99cb17282")), nil,
+		)
+
+		account, err := s.Accounts.Create(
+			serviceAcc,
+			[]crypto.PublicKey{pubKey},
+			[]int{1000},
+			[]crypto.SignatureAlgorithm{crypto.ECDSA_P256},
+			[]crypto.HashAlgorithm{crypto.SHA3_256},
+			nil,
+			WithExpectedAddress(flow.HexToAddress("01cf0e2f2f715450")),
+		)
+
+		assert.ErrorIs(t, err, ErrUnexpectedAddress)
+		assert.EqualError(t, err, "account created at unexpected address: expected 0x01cf0e2f2f715450, created 0x192440c99cb17282")
+		assert.Nil(t, account)
+	})
+
+	t.Run("Create an Account on Custom Chain", func(t *testing.T) {
+		_, s, gw := setup()
+
+		newAddress := flow.HexToAddress("0000000000001234")
+		gw.GetTransactionResult.Return(tests.NewAccountCreateResult(newAddress), nil)
+		gw.GetAccount.Run(func(args mock.Arguments) {
+			addr := args.Get(0).(flow.Address)
+			if addr == newAddress {
+				addr = flow.EmptyAddress // gateway of the custom chain reporting a differently encoded address
+			}
+			gw.GetAccount.Return(tests.NewAccountWithAddress(addr.String()), nil)
+		})
+
+		account, err := s.Accounts.Create(
+			serviceAcc,
+			[]crypto.PublicKey{pubKey},
+			[]int{1000},
+			[]crypto.SignatureAlgorithm{crypto.ECDSA_P256},
+			[]crypto.HashAlgorithm{crypto.SHA3_256},
+			nil,
+			WithChainID("flow-private"),
+		)
+
+		require.NoError(t, err)
+		assert.Equal(t, newAddress, account.Address)
+	})
+
+	t.Run("Create an Account with Address Invalid on Chain", func(t *testing.T) {
+		_, s, gw := setup()
+
+		gw.GetTransactionResult.Return(
+			tests.NewAccountCreateResult(flow.HexToAddress("0000000000001234")), nil,
+		)
+
+		account, err := s.Accounts.Create(
+			serviceAcc,
+			[]crypto.PublicKey{pubKey},
+			[]int{1000},
+			[]crypto.SignatureAlgorithm{crypto.ECDSA_P256},
+			[]crypto.HashAlgorithm{crypto.SHA3_256},
+			nil,
+			WithChainID(flow.Emulator),
+		)
+
+		assert.EqualError(t, err, "created address 0x0000000000001234 is not a valid address on chain flow-emulator")
+		assert.Nil(t, account)
+		gw.Mock.AssertNotCalled(t, tests.GetAccountFunc, flow.HexToAddress("0000000000001234"))
+	})
+
 	t.Run("Contract Add for Account", func(t *testing.T) {
 		_, s, gw := setup()
 		gw.SendSignedTransaction.Run(func(args mock.Arguments) {
@@ -218,10 +417,11 @@ func TestAccounts(t *testing.T) {
 			gw.ExecuteScript.Return(cadence.NewArray([]cadence.Value{}), nil)
 		})
 
-		val1, val2, err := s.Accounts.StakingInfo(flow.HexToAddress("df9c30eb2252f1fa"))
+		result, err := s.Accounts.StakingInfo(flow.HexToAddress("df9c30eb2252f1fa"))
 		assert.NoError(t, err)
-		assert.NotNil(t, val1)
-		assert.NotNil(t, val2)
+		assert.NotNil(t, result.Staking)
+		assert.NotNil(t, result.Delegation)
+		assert.False(t, result.PartialResult)
 		assert.Equal(t, 2, count)
 	})
 	t.Run("Staking Info for Account fetches node total", func(t *testing.T) {
@@ -253,12 +453,304 @@ func TestAccounts(t *testing.T) {
 			count++
 		})
 
-		val1, val2, err := s.Accounts.StakingInfo(flow.HexToAddress("df9c30eb2252f1fa"))
+		result, err := s.Accounts.StakingInfo(flow.HexToAddress("df9c30eb2252f1fa"))
 		assert.NoError(t, err)
-		assert.NotNil(t, val1)
-		assert.NotNil(t, val2)
+		assert.NotNil(t, result.Staking)
+		assert.NotNil(t, result.Delegation)
 		assert.Equal(t, 3, count)
 	})
+
+	nodeInfos := cadence.NewArray([]cadence.Value{
+		cadence.Struct{
+			StructType: &cadence.StructType{
+				Fields: []cadence.Field{{Identifier: "id"}},
+			},
+			Fields: []cadence.Value{cadence.String("node-1")},
+		},
+		cadence.Struct{
+			StructType: &cadence.StructType{
+				Fields: []cadence.Field{{Identifier: "id"}},
+			},
+			Fields: []cadence.Value{cadence.String("node-2")},
+		},
+	})
+
+	t.Run("Staking Info partial node stakes", func(t *testing.T) {
+		_, s, gw := setup()
+
+		gw.ExecuteScript.Run(func(args mock.Arguments) {
+			scriptArgs := args.Get(1).([]cadence.Value)
+			switch scriptArgs[0] {
+			case cadence.String("node-1"):
+				gw.ExecuteScript.Return(cadence.NewUFix64("1.0"))
+			case cadence.String("node-2"):
+				gw.ExecuteScript.Return(nil, fmt.Errorf("access node unavailable"))
+			default:
+				gw.ExecuteScript.Return(nodeInfos, nil)
+			}
+		})
+
+		result, err := s.Accounts.StakingInfo(flow.HexToAddress("df9c30eb2252f1fa"))
+		require.NoError(t, err)
+		assert.True(t, result.PartialResult)
+		require.Len(t, result.Warnings, 1)
+		assert.Equal(t, "node-2", result.Warnings[0].NodeID)
+		assert.ErrorContains(t, result.Warnings[0].Err, "access node unavailable")
+
+		require.Len(t, result.Staking, 2)
+		for _, info := range result.Staking {
+			_, hasStake := info["nodeTotalStake"]
+			assert.Equal(t, info["id"] == cadence.String("node-1"), hasStake)
+		}
+	})
+
+	t.Run("Staking Info partial delegation", func(t *testing.T) {
+		_, s, gw := setup()
+
+		count := 0
+		gw.ExecuteScript.Run(func(args mock.Arguments) {
+			count++
+			if count == 2 { // staking info is fetched first, delegation info second
+				gw.ExecuteScript.Return(nil, fmt.Errorf("delegation failed"))
+				return
+			}
+			gw.ExecuteScript.Return(cadence.NewArray([]cadence.Value{}), nil)
+		})
+
+		result, err := s.Accounts.StakingInfo(flow.HexToAddress("df9c30eb2252f1fa"))
+		require.NoError(t, err)
+		assert.True(t, result.PartialResult)
+		require.Len(t, result.Warnings, 1)
+		assert.Equal(t, "", result.Warnings[0].NodeID)
+		assert.ErrorContains(t, result.Warnings[0].Err, "error getting delegation info")
+		assert.Len(t, result.Delegation, 0)
+	})
+
+	t.Run("Staking Info all failed", func(t *testing.T) {
+		_, s, gw := setup()
+
+		gw.ExecuteScript.Run(func(args mock.Arguments) {
+			gw.ExecuteScript.Return(nil, fmt.Errorf("unavailable"))
+		})
+
+		result, err := s.Accounts.StakingInfo(flow.HexToAddress("df9c30eb2252f1fa"))
+		assert.Nil(t, result)
+		assert.ErrorContains(t, err, "error getting staking info")
+		assert.ErrorContains(t, err, "error getting delegation info")
+	})
+
+	t.Run("Staking Info paginated", func(t *testing.T) {
+		_, s, gw := setup()
+
+		nodes := make([]cadence.Value, 5)
+		for i := range nodes {
+			nodes[i] = cadence.Struct{
+				StructType: &cadence.StructType{
+					Fields: []cadence.Field{{Identifier: "id"}},
+				},
+				Fields: []cadence.Value{cadence.String(fmt.Sprintf("node-%d", i))},
+			}
+		}
+
+		offsets := make([]int, 0)
+		gw.ExecuteScript.Run(func(args mock.Arguments) {
+			code := string(args.Get(0).([]byte))
+			scriptArgs := args.Get(1).([]cadence.Value)
+			switch {
+			case strings.Contains(code, "getNodeIDs"):
+				offset := scriptArgs[1].(cadence.Int).Int()
+				limit := scriptArgs[2].(cadence.Int).Int()
+				assert.Equal(t, 2, limit)
+				offsets = append(offsets, offset)
+
+				end := offset + limit
+				if end > len(nodes) {
+					end = len(nodes)
+				}
+				gw.ExecuteScript.Return(cadence.NewArray(nodes[offset:end]), nil)
+			case strings.Contains(code, "getDelegatorIDs"):
+				gw.ExecuteScript.Return(cadence.NewArray([]cadence.Value{}), nil)
+			default:
+				gw.ExecuteScript.Return(cadence.NewUFix64("1.0"))
+			}
+		})
+
+		result, err := s.Accounts.StakingInfoWithOptions(
+			flow.HexToAddress("df9c30eb2252f1fa"),
+			StakingInfoOptions{PageSize: 2},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, []int{0, 2, 4}, offsets)
+		require.Len(t, result.Staking, 5)
+		for i, info := range result.Staking {
+			assert.Equal(t, cadence.String(fmt.Sprintf("node-%d", i)), info["id"])
+		}
+		assert.Len(t, result.Delegation, 0)
+	})
+
+	t.Run("Staking Info negative page size", func(t *testing.T) {
+		_, s, _ := setup()
+
+		_, err := s.Accounts.StakingInfoWithOptions(
+			flow.HexToAddress("df9c30eb2252f1fa"),
+			StakingInfoOptions{PageSize: -1},
+		)
+		assert.EqualError(t, err, "staking info page size must not be negative")
+	})
+
+	t.Run("Prepare Transaction with Network Compute Limit", func(t *testing.T) {
+		state, s, _ := setup()
+		state.Networks().AddOrUpdate("testnet", config.Network{
+			Name:         "testnet",
+			Host:         "127.0.0.1:3569",
+			ComputeLimit: 300,
+		})
+		srvAcc, err := state.EmulatorServiceAccount()
+		require.NoError(t, err)
+
+		tx, err := flowkit.NewAuthorizedTransaction(srvAcc, []byte("transaction {}"), nil)
+		require.NoError(t, err)
+		tx.SetGasLimit(9999)
+		tx, err = s.Accounts.prepareTransaction(tx, srvAcc, "testnet")
+		require.NoError(t, err)
+		assert.Equal(t, uint64(300), tx.FlowTransaction().GasLimit)
+	})
+
+	t.Run("Create an Account Waits Executed", func(t *testing.T) {
+		for _, perCall := range []bool{false, true} {
+			_, s, gw := setup()
+			s.Sequences.pollInterval = 0
+			newAddress := flow.HexToAddress("192440c99cb17282")
+
+			options := []CreateOption{WithWaitPolicy(gateway.WaitExecuted)}
+			if !perCall {
+				s.SetWaitPolicy(gateway.WaitExecuted)
+				options = nil
+			}
+
+			statuses := []flow.TransactionStatus{flow.TransactionStatusPending, flow.TransactionStatusExecuted}
+			gw.GetTransactionResult.Run(func(args mock.Arguments) {
+				assert.False(t, args.Get(1).(bool))
+				result := tests.NewAccountCreateResult(newAddress)
+				result.Status = statuses[0]
+				statuses = statuses[1:]
+				gw.GetTransactionResult.Return(result, nil)
+			})
+
+			account, err := s.Accounts.Create(
+				serviceAcc,
+				[]crypto.PublicKey{pubKey},
+				[]int{1000},
+				[]crypto.SignatureAlgorithm{crypto.ECDSA_P256},
+				[]crypto.HashAlgorithm{crypto.SHA3_256},
+				nil,
+				options...,
+			)
+			require.NoError(t, err)
+			assert.Equal(t, newAddress, account.Address)
+			gw.Mock.AssertNumberOfCalls(t, tests.GetTransactionResultFunc, 2)
+		}
+	})
+
+	t.Run("Contract Add Waits Sealed Before Recording", func(t *testing.T) {
+		_, s, gw := setup()
+		s.SetWaitPolicy(gateway.WaitExecuted)
+		s.Sequences.pollInterval = 0
+
+		statuses := []flow.TransactionStatus{
+			flow.TransactionStatusExecuted,
+			flow.TransactionStatusExecuted,
+			flow.TransactionStatusSealed,
+		}
+		waitSeals := make([]bool, 0)
+		gw.GetTransactionResult.Run(func(args mock.Arguments) {
+			waitSeals = append(waitSeals, args.Get(1).(bool))
+			result := tests.NewTransactionResult(nil)
+			result.Status = statuses[0]
+			statuses = statuses[1:]
+			gw.GetTransactionResult.Return(result, nil)
+		})
+
+		_, _, err := s.Accounts.AddContract(serviceAcc, resourceToContract(tests.ContractHelloString), "", false)
+		require.NoError(t, err)
+		assert.Equal(t, []bool{false, true, true}, waitSeals)
+	})
+}
+
+func TestAccountsCreateConfirmation(t *testing.T) {
+	pubKey := tests.PubKeys()[0]
+	signer := tests.Alice().SetAddress(flow.HexToAddress("e467b9dd11fa00df"))
+	newAddress := flow.HexToAddress("192440c99cb17282")
+
+	create := func(s *Services) (*flow.Account, error) {
+		return s.Accounts.Create(
+			signer,
+			[]crypto.PublicKey{pubKey},
+			[]int{1000},
+			[]crypto.SignatureAlgorithm{crypto.ECDSA_P256},
+			[]crypto.HashAlgorithm{crypto.SHA3_256},
+			nil,
+		)
+	}
+
+	t.Run("Confirmed", func(t *testing.T) {
+		_, s, gw := setup()
+		gw.GetTransactionResult.Return(tests.NewAccountCreateResult(newAddress), nil)
+
+		var confirmedNetwork string
+		s.Accounts.SetConfirmation(ConfirmationOptions{
+			RequireConfirmation: true,
+			ConfirmationCallback: func(network string) bool {
+				confirmedNetwork = network
+				return true
+			},
+		})
+
+		account, err := create(s)
+		require.NoError(t, err)
+		assert.Equal(t, newAddress, account.Address)
+		assert.Equal(t, "mainnet", confirmedNetwork)
+		gw.Mock.AssertNumberOfCalls(t, tests.SendSignedTransactionFunc, 1)
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		_, s, gw := setup()
+
+		s.Accounts.SetConfirmation(ConfirmationOptions{
+			RequireConfirmation: true,
+			ConfirmationCallback: func(network string) bool {
+				return false
+			},
+		})
+
+		_, err := create(s)
+		assert.ErrorIs(t, err, ErrOperationCancelled)
+		gw.Mock.AssertNotCalled(t, tests.SendSignedTransactionFunc, mock.Anything)
+	})
+
+	t.Run("Not Mainnet", func(t *testing.T) {
+		state, s, gw := setup()
+		serviceAcc, _ := state.EmulatorServiceAccount()
+		gw.GetTransactionResult.Return(tests.NewAccountCreateResult(newAddress), nil)
+
+		s.Accounts.SetConfirmation(ConfirmationOptions{
+			RequireConfirmation: true,
+			ConfirmationCallback: func(network string) bool {
+				t.Fatal("confirmation should not be requested for emulator")
+				return false
+			},
+		})
+
+		_, err := s.Accounts.Create(
+			serviceAcc,
+			[]crypto.PublicKey{pubKey},
+			[]int{1000},
+			[]crypto.SignatureAlgorithm{crypto.ECDSA_P256},
+			[]crypto.HashAlgorithm{crypto.SHA3_256},
+			nil,
+		)
+		assert.NoError(t, err)
+	})
 }
 
 func setupIntegration() (*flowkit.State, *Services) {
@@ -277,28 +769,97 @@ func setupIntegration() (*flowkit.State, *Services) {
 	return state, s
 }
 
-func TestAccountsCreate_Integration(t *testing.T) {
-	t.Parallel()
+func TestAccountsCreateInvalidContracts(t *testing.T) {
+	state, _, _ := setup()
+	serviceAcc, _ := state.EmulatorServiceAccount()
 
-	type accountsIn struct {
-		account  *flowkit.Account
-		pubKeys  []crypto.PublicKey
-		weights  []int
-		sigAlgo  []crypto.SignatureAlgorithm
-		hashAlgo []crypto.HashAlgorithm
-		args     []string
+	create := func(s *Services, contracts []string) error {
+		_, err := s.Accounts.CreateForNetwork(
+			serviceAcc,
+			[]crypto.PublicKey{tests.PubKeys()[0]},
+			[]int{1000},
+			[]crypto.SignatureAlgorithm{crypto.ECDSA_P256},
+			[]crypto.HashAlgorithm{crypto.SHA3_256},
+			contracts,
+			"emulator",
+		)
+		return err
 	}
 
-	type accountsOut struct {
-		address string
-		code    map[string][]byte
-		balance uint64
-		pubKeys []crypto.PublicKey
-		weights []int
-	}
+	t.Run("Syntax Error", func(t *testing.T) {
+		_, s, gw := setup()
 
-	t.Run("Create", func(t *testing.T) {
-		t.Parallel()
+		err := create(s, []string{"Invalid:contractInvalid.cdc"})
+
+		var validationErr *ContractsValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Contains(t, validationErr.Contracts["Invalid"].Error(), "parsing failed: 3:")
+		gw.Mock.AssertNotCalled(t, tests.SendSignedTransactionFunc, mock.Anything)
+	})
+
+	t.Run("Name Mismatch", func(t *testing.T) {
+		_, s, gw := setup()
+
+		err := create(s, []string{"Bar:contractHello.cdc"})
+
+		assert.EqualError(t, err, "invalid contracts: Bar: declared contract name Hello doesn't match the provided name Bar")
+		gw.Mock.AssertNotCalled(t, tests.SendSignedTransactionFunc, mock.Anything)
+	})
+
+	t.Run("Unresolved Import", func(t *testing.T) {
+		_, s, gw := setup()
+
+		err := create(s, []string{"ContractB:contractB.cdc"})
+
+		assert.EqualError(t, err, "invalid contracts: ContractB: import ./contractA.cdc could not be resolved from provided contracts")
+		gw.Mock.AssertNotCalled(t, tests.SendSignedTransactionFunc, mock.Anything)
+	})
+
+	t.Run("All Reported", func(t *testing.T) {
+		_, s, gw := setup()
+
+		err := create(s, []string{
+			"Invalid:contractInvalid.cdc",
+			"Hello:contractHello.cdc",
+			"Bar:contractSimple.cdc",
+			"ContractB:contractB.cdc",
+		})
+
+		var validationErr *ContractsValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Len(t, validationErr.Contracts, 3)
+		assert.NotContains(t, validationErr.Contracts, "Hello")
+
+		var batchErr *flowkit.BatchError
+		require.ErrorAs(t, err, &batchErr)
+		assert.Equal(t, 3, batchErr.Failed())
+		assert.Equal(t, []string{"Bar", "ContractB", "Invalid"}, []string{batchErr.Label(0), batchErr.Label(1), batchErr.Label(2)})
+		gw.Mock.AssertNotCalled(t, tests.SendSignedTransactionFunc, mock.Anything)
+	})
+}
+
+func TestAccountsCreate_Integration(t *testing.T) {
+	t.Parallel()
+
+	type accountsIn struct {
+		account  *flowkit.Account
+		pubKeys  []crypto.PublicKey
+		weights  []int
+		sigAlgo  []crypto.SignatureAlgorithm
+		hashAlgo []crypto.HashAlgorithm
+		args     []string
+	}
+
+	type accountsOut struct {
+		address string
+		code    map[string][]byte
+		balance uint64
+		pubKeys []crypto.PublicKey
+		weights []int
+	}
+
+	t.Run("Create", func(t *testing.T) {
+		t.Parallel()
 
 		state, s := setupIntegration()
 		srvAcc, _ := state.EmulatorServiceAccount()
@@ -488,6 +1049,24 @@ func TestAccountsCreate_Integration(t *testing.T) {
 		}
 	})
 
+	t.Run("Create With Events Skipped By Default", func(t *testing.T) {
+		t.Parallel()
+
+		state, s := setupIntegration()
+		s.Sequences.SetResultOptions(gateway.ResultOptions{SkipEvents: true})
+		srvAcc, _ := state.EmulatorServiceAccount()
+
+		account, err := s.Accounts.Create(
+			srvAcc,
+			[]crypto.PublicKey{tests.PubKeys()[0]},
+			[]int{flow.AccountKeyWeightThreshold},
+			[]crypto.SignatureAlgorithm{tests.SigAlgos()[0]},
+			[]crypto.HashAlgorithm{tests.HashAlgos()[0]},
+			nil,
+		)
+		require.NoError(t, err)
+		assert.Equal(t, "01cf0e2f2f715450", account.Address.String())
+	})
 }
 
 func TestAccountsAddContract_Integration(t *testing.T) {
@@ -526,6 +1105,177 @@ func TestAccountsAddContract_Integration(t *testing.T) {
 		assert.Equal(t, acc.Contracts["Simple"], tests.ContractSimpleUpdated.Source)
 	})
 
+	t.Run("Update Contract With Imports", func(t *testing.T) {
+		t.Parallel()
+
+		state, s := setupIntegration()
+		srvAcc, _ := state.EmulatorServiceAccount()
+
+		for _, c := range []tests.Resource{tests.ContractA, tests.ContractB} {
+			state.Contracts().AddOrUpdate(c.Name, config.Contract{Name: c.Name, Location: c.Filename})
+		}
+		state.Deployments().AddOrUpdate(config.Deployment{
+			Network: config.DefaultEmulatorNetwork().Name,
+			Account: srvAcc.Name(),
+			Contracts: []config.ContractDeployment{
+				{Name: tests.ContractA.Name},
+				{Name: tests.ContractB.Name},
+			},
+		})
+
+		for _, c := range []tests.Resource{tests.ContractA, tests.ContractB} {
+			_, _, err := s.Accounts.AddContract(srvAcc, resourceToContract(c), config.DefaultEmulatorNetwork().Name, false)
+			require.NoError(t, err)
+		}
+
+		updated := flowkit.NewScript(
+			append(append([]byte{}, tests.ContractB.Source...), []byte("// updated\n")...),
+			nil,
+			tests.ContractB.Filename,
+		)
+		_, _, err := s.Accounts.AddContract(srvAcc, updated, config.DefaultEmulatorNetwork().Name, true)
+		require.NoError(t, err)
+
+		acc, err := s.Accounts.Get(srvAcc.Address())
+		require.NoError(t, err)
+		code := acc.Contracts[tests.ContractB.Name]
+		assert.Contains(t, string(code), fmt.Sprintf("import ContractA from 0x%s", srvAcc.Address()))
+		assert.Contains(t, string(code), "// updated")
+
+		// the lock file records the code sent, once per contract
+		lock, err := state.LockFile()
+		require.NoError(t, err)
+		records := lock.ByContract(config.DefaultEmulatorNetwork().Name, tests.ContractB.Name)
+		require.Len(t, records, 1)
+		assert.Equal(t, codeHash(code), records[0].CodeHash)
+	})
+
+	t.Run("Reject CRLF", func(t *testing.T) {
+		t.Parallel()
+
+		state, s := setupIntegration()
+		srvAcc, _ := state.EmulatorServiceAccount()
+
+		code := "pub contract Simple {\r\n\tpub let id: Int\n\tinit() { self.id = 1 }\r\n}\n"
+		_, _, err := s.Accounts.AddContract(srvAcc, flowkit.NewScript([]byte(code), nil, "simple.cdc"), "", false)
+		assert.ErrorIs(t, err, ErrCRLFDetected)
+		var crlfErr *CRLFError
+		require.ErrorAs(t, err, &crlfErr)
+		assert.Equal(t, []int{1, 3}, crlfErr.Lines)
+		assert.EqualError(t, err, "contract code has Windows line endings (CRLF) at simple.cdc on lines 1, 3")
+
+		acc, err := s.Accounts.Get(srvAcc.Address())
+		require.NoError(t, err)
+		assert.NotContains(t, acc.Contracts, "Simple")
+
+		long := strings.Repeat("// comment\r\n", 12) + "pub contract Simple {}\r\n"
+		_, _, err = s.Accounts.AddContract(srvAcc, flowkit.NewScript([]byte(long), nil, "simple.cdc"), "", false)
+		assert.EqualError(
+			t,
+			err,
+			"contract code has Windows line endings (CRLF) at simple.cdc on lines 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, and 3 more",
+		)
+	})
+
+	t.Run("Normalize CRLF", func(t *testing.T) {
+		t.Parallel()
+
+		state, s := setupIntegration()
+		srvAcc, _ := state.EmulatorServiceAccount()
+
+		code := "pub contract Simple {\r\n\tpub let id: Int\n\tinit() { self.id = 1 }\r\n}\n"
+		script := flowkit.NewScript([]byte(code), nil, "simple.cdc")
+		_, _, err := s.Accounts.AddContractWithOptions(srvAcc, script, "", false, AddContractOptions{
+			AutoNormalize: true,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, code, string(script.Code()))
+
+		acc, err := s.Accounts.Get(srvAcc.Address())
+		require.NoError(t, err)
+		assert.Equal(t, "pub contract Simple {\n\tpub let id: Int\n\tinit() { self.id = 1 }\n}\n", string(acc.Contracts["Simple"]))
+	})
+
+	t.Run("Log Generated Contract", func(t *testing.T) {
+		t.Parallel()
+
+		state, s := setupIntegration()
+		logger := &recordingLogger{Logger: output.NewStdoutLogger(output.NoneLog)}
+		s.SetLogger(logger)
+		srvAcc, _ := state.EmulatorServiceAccount()
+
+		state.Contracts().AddOrUpdate("Simple", config.Contract{
+			Name:        "Simple",
+			Location:    tests.ContractSimple.Filename,
+			GeneratedBy: "cadence-gen v1.2.3",
+		})
+
+		_, _, err := s.Accounts.AddContract(srvAcc, resourceToContract(tests.ContractSimple), "", false)
+		require.NoError(t, err)
+		assert.Contains(
+			t,
+			logger.infos,
+			fmt.Sprintf("Contract 'Simple' (generated by cadence-gen v1.2.3) created on the account '%s'.", srvAcc.Address()),
+		)
+	})
+
+	t.Run("Update Contract Incompatible", func(t *testing.T) {
+		t.Parallel()
+
+		state, s := setupIntegration()
+		logger := &recordingLogger{Logger: output.NewStdoutLogger(output.NoneLog)}
+		s.SetLogger(logger)
+		srvAcc, _ := state.EmulatorServiceAccount()
+
+		_, _, err := s.Accounts.AddContract(
+			srvAcc,
+			flowkit.NewScript([]byte(`pub contract Counter { pub var count: Int; init() { self.count = 0 } }`), nil, "counter.cdc"),
+			"",
+			false,
+		)
+		require.NoError(t, err)
+		assert.Len(t, logger.warnings, 0)
+
+		_, _, err = s.Accounts.AddContract(
+			srvAcc,
+			flowkit.NewScript([]byte(`
+				pub contract Counter {
+					pub var count: UInt64
+					pub var total: Int
+					init() { self.count = 0; self.total = 0 }
+				}
+			`), nil, "counter.cdc"),
+			"",
+			true,
+		)
+		assert.Error(t, err) // the network rejects the update
+		assert.Equal(t, []string{
+			"contract Counter update is not compatible, Counter: mismatching field `count` in `Counter`, incompatible type annotations. expected `Int`, found `UInt64`",
+			"contract Counter update is not compatible, Counter: found new field `total` in `Counter`",
+		}, logger.warnings)
+	})
+
+	t.Run("Add Contract Init Panic", func(t *testing.T) {
+		t.Parallel()
+
+		state, s := setupIntegration()
+		srvAcc, _ := state.EmulatorServiceAccount()
+
+		_, _, err := s.Accounts.AddContract(
+			srvAcc,
+			flowkit.NewScript([]byte("pub contract Broken {\n\tinit() {\n\t\tpanic(\"init failed\")\n\t}\n}"), nil, "broken.cdc"),
+			"",
+			false,
+		)
+
+		var runtimeErr *flowkit.CadenceRuntimeError
+		require.ErrorAs(t, err, &runtimeErr)
+		assert.Equal(t, flowkit.CadenceErrorPanic, runtimeErr.Kind)
+		assert.Equal(t, "init failed", runtimeErr.Message)
+		require.NotNil(t, runtimeErr.Location())
+		assert.Equal(t, fmt.Sprintf("%s.Broken:3:2", srvAcc.Address()), runtimeErr.Location().String())
+	})
+
 	t.Run("Add Contract Invalid", func(t *testing.T) {
 		t.Parallel()
 
@@ -551,6 +1301,195 @@ func TestAccountsAddContract_Integration(t *testing.T) {
 		require.Error(t, err)
 		assert.Error(t, err, "cannot overwrite existing contract with name \"Simple\"")
 	})
+
+	t.Run("Skip If Identical", func(t *testing.T) {
+		t.Parallel()
+
+		state, s := setupIntegration()
+		srvAcc, _ := state.EmulatorServiceAccount()
+
+		_, _, err := s.Accounts.AddContract(
+			srvAcc,
+			resourceToContract(tests.ContractSimple),
+			"",
+			false,
+		)
+		require.NoError(t, err)
+
+		ID, updated, err := s.Accounts.AddContractWithOptions(
+			srvAcc,
+			resourceToContract(tests.ContractSimple),
+			"",
+			true,
+			AddContractOptions{SkipIfIdentical: true},
+		)
+		require.NoError(t, err)
+		assert.Equal(t, flow.EmptyID, ID)
+		assert.False(t, updated)
+
+		// changed code is still updated
+		ID, updated, err = s.Accounts.AddContractWithOptions(
+			srvAcc,
+			resourceToContract(tests.ContractSimpleUpdated),
+			"",
+			true,
+			AddContractOptions{SkipIfIdentical: true},
+		)
+		require.NoError(t, err)
+		assert.NotEqual(t, flow.EmptyID, ID)
+		assert.True(t, updated)
+	})
+
+	t.Run("Identical Without Skip", func(t *testing.T) {
+		t.Parallel()
+
+		state, s := setupIntegration()
+		srvAcc, _ := state.EmulatorServiceAccount()
+
+		_, _, err := s.Accounts.AddContract(
+			srvAcc,
+			resourceToContract(tests.ContractSimple),
+			"",
+			false,
+		)
+		require.NoError(t, err)
+
+		_, _, err = s.Accounts.AddContract(
+			srvAcc,
+			resourceToContract(tests.ContractSimple),
+			"",
+			true,
+		)
+		assert.ErrorIs(t, err, errUpdateNoDiff)
+	})
+
+	t.Run("Without State", func(t *testing.T) {
+		t.Parallel()
+
+		state, s := setupIntegration()
+		srvAcc, _ := state.EmulatorServiceAccount()
+		accounts := NewAccounts(s.Accounts.gateway, nil, output.NewStdoutLogger(output.NoneLog))
+
+		// imports can't be resolved without the contracts in the state
+		_, _, err := accounts.AddContract(srvAcc, resourceToContract(tests.ContractB), "", false)
+		assert.ErrorIs(t, err, config.ErrDoesNotExist)
+
+		// contracts without imports are not generated
+		ID, _, err := accounts.AddContract(srvAcc, resourceToContract(tests.ContractSimple), "", false)
+		require.NoError(t, err)
+		assert.NotEqual(t, flow.EmptyID, ID)
+	})
+}
+
+func TestAccountsSetCode_Integration(t *testing.T) {
+	t.Parallel()
+
+	state, s := setupIntegration()
+	srvAcc, _ := state.EmulatorServiceAccount()
+
+	names := []string{"Foo", "Bar", "Baz"}
+	for _, name := range names {
+		code := []byte(fmt.Sprintf("pub contract %s {}", name))
+		_, _, err := s.Accounts.AddContract(srvAcc, flowkit.NewScript(code, nil, ""), "", false)
+		require.NoError(t, err)
+	}
+
+	updated := make(map[string][]byte)
+	for _, name := range names {
+		updated[name] = []byte(fmt.Sprintf("pub contract %s { pub fun updated() {} }", name))
+	}
+
+	ID, err := s.Accounts.SetCode(srvAcc, updated, "")
+	require.NoError(t, err)
+	assert.NotEqual(t, flow.EmptyID, ID)
+
+	acc, err := s.Accounts.Get(srvAcc.Address())
+	require.NoError(t, err)
+	for name, code := range updated {
+		assert.Equal(t, code, acc.Contracts[name])
+	}
+
+	t.Run("Set Code Non-Existing", func(t *testing.T) {
+		_, err := s.Accounts.SetCode(srvAcc, map[string][]byte{
+			"Qux": []byte("pub contract Qux {}"),
+		}, "")
+		assert.EqualError(t, err, fmt.Sprintf(
+			"can not update a non-existing contract named 'Qux' on account %s",
+			srvAcc.Address(),
+		))
+	})
+
+	t.Run("Set Code Rejected", func(t *testing.T) {
+		// adding a field is not a valid contract update
+		_, err := s.Accounts.SetCode(srvAcc, map[string][]byte{
+			"Foo": []byte("pub contract Foo { pub let x: Int\n init() { self.x = 1 } }"),
+		}, "")
+
+		var runtimeErr *flowkit.CadenceRuntimeError
+		require.ErrorAs(t, err, &runtimeErr)
+		assert.Contains(t, runtimeErr.Error(), "found new field `x` in `Foo`")
+	})
+
+	t.Run("Set Code With Imports Without State", func(t *testing.T) {
+		accounts := NewAccounts(s.Accounts.gateway, nil, output.NewStdoutLogger(output.NoneLog))
+		_, err := accounts.SetCode(srvAcc, map[string][]byte{
+			"Foo": []byte("import Bar from \"./bar.cdc\"\npub contract Foo {}"),
+		}, "")
+		assert.ErrorIs(t, err, config.ErrDoesNotExist)
+	})
+}
+
+func TestAccountsAddContracts_Integration(t *testing.T) {
+	t.Parallel()
+
+	state, s := setupIntegration()
+	srvAcc, _ := state.EmulatorServiceAccount()
+
+	contracts := []*flowkit.Script{
+		flowkit.NewScript([]byte("pub contract Foo {}"), nil, ""),
+		flowkit.NewScript([]byte("pub contract Bar { pub let x: Int\n init(x: Int) { self.x = x } }"), []cadence.Value{cadence.NewInt(1)}, ""),
+	}
+
+	ID, deployed, err := s.Accounts.AddContracts(srvAcc, contracts, "", false, nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, flow.EmptyID, ID)
+	assert.Equal(t, map[string]bool{"Foo": false, "Bar": false}, deployed)
+
+	acc, err := s.Accounts.Get(srvAcc.Address())
+	require.NoError(t, err)
+	assert.Contains(t, acc.Contracts, "Foo")
+	assert.Contains(t, acc.Contracts, "Bar")
+
+	t.Run("Report Failing Contract", func(t *testing.T) {
+		_, _, err := s.Accounts.AddContracts(srvAcc, []*flowkit.Script{
+			flowkit.NewScript([]byte("pub contract Baz {}"), nil, ""),
+			flowkit.NewScript([]byte("pub contract Qux { pub let x: Int\n init() { self.x = \"invalid\" } }"), nil, ""),
+		}, "", false, nil)
+
+		var deployErr *ContractsDeploymentError
+		require.ErrorAs(t, err, &deployErr)
+		assert.Equal(t, "Qux", deployErr.Contract)
+	})
+
+	t.Run("CRLF Contract", func(t *testing.T) {
+		_, _, err := s.Accounts.AddContracts(srvAcc, []*flowkit.Script{
+			flowkit.NewScript([]byte("pub contract Baz {}"), nil, "baz.cdc"),
+			flowkit.NewScript([]byte("pub contract Quux {\r\n}\r\n"), nil, "quux.cdc"),
+		}, "", false, nil)
+
+		var crlfErr *CRLFError
+		require.ErrorAs(t, err, &crlfErr)
+		assert.Equal(t, "quux.cdc", crlfErr.Location)
+		assert.Equal(t, []int{1, 2}, crlfErr.Lines)
+	})
+
+	t.Run("Imports Without State", func(t *testing.T) {
+		accounts := NewAccounts(s.Accounts.gateway, nil, output.NewStdoutLogger(output.NoneLog))
+		_, _, err := accounts.AddContracts(srvAcc, []*flowkit.Script{
+			flowkit.NewScript([]byte("import Bar from \"./bar.cdc\"\npub contract Baz {}"), nil, ""),
+		}, "", false, nil)
+		assert.ErrorIs(t, err, config.ErrDoesNotExist)
+	})
 }
 
 func TestAccountsAddContractWithArgs(t *testing.T) {
@@ -579,24 +1518,105 @@ func TestAccountsAddContractWithArgs(t *testing.T) {
 	assert.Equal(t, acc.Contracts["Simple"], tests.ContractSimpleWithArgs.Source)
 }
 
-func TestAccountsRemoveContract_Integration(t *testing.T) {
+func TestAccountsUploadBigContract_Integration(t *testing.T) {
 	t.Parallel()
 
 	state, s := setupIntegration()
 	srvAcc, _ := state.EmulatorServiceAccount()
 
-	c := tests.ContractSimple
-	// prepare existing contract
-	_, _, err := s.Accounts.AddContract(
-		srvAcc,
-		flowkit.NewScript(c.Source, nil, c.Filename),
-		"",
-		false,
-	)
-	assert.NoError(t, err)
+	contract := flowkit.NewScript(tests.ContractBig.Source, []cadence.Value{cadence.NewUInt64(42)}, tests.ContractBig.Filename)
+	require.Greater(t, len(contract.Code()), 20*1024)
 
-	t.Run("Remove Contract", func(t *testing.T) {
-		t.Parallel()
+	// leftovers of an upload that didn't complete are replaced by the next upload
+	tx, err := flowkit.NewStageContractChunkTransaction(srvAcc, "Big", []byte("pub contract Leftover {"), true)
+	require.NoError(t, err)
+	_, err = s.Accounts.sendTransaction(tx, srvAcc, "")
+	require.NoError(t, err)
+
+	ID, updated, err := s.Accounts.UploadBigContract(srvAcc, contract, "", false, 0)
+	require.NoError(t, err)
+	assert.NotEqual(t, flow.EmptyID, ID)
+	assert.False(t, updated)
+
+	acc, err := s.Accounts.Get(srvAcc.Address())
+	require.NoError(t, err)
+	assert.Equal(t, tests.ContractBig.Source, acc.Contracts["Big"])
+
+	value, err := s.Scripts.Execute(flowkit.NewScript([]byte(fmt.Sprintf(`
+		import Big from 0x%[1]s
+
+		pub fun main(): [AnyStruct] {
+			return [Big.id, Big.value7(), getAuthAccount(0x%[1]s).copy<String>(from: /storage/flowkitStagedContractBig)]
+		}`, srvAcc.Address())), nil, ""), "")
+	require.NoError(t, err)
+	assert.Equal(t, `[42, "value of the big contract number 7", nil]`, value.String())
+
+	updatedCode := bytes.Replace(
+		tests.ContractBig.Source,
+		[]byte("pub fun value0()"),
+		[]byte("pub fun added(): Int { return 1 }\n\t\tpub fun value0()"),
+		1,
+	)
+
+	t.Run("Existing Contract", func(t *testing.T) {
+		_, _, err := s.Accounts.UploadBigContract(srvAcc, flowkit.NewScript(updatedCode, nil, ""), "", false, 0)
+		assert.EqualError(t, err, "contract Big exists in account emulator-account")
+
+		_, _, err = s.Accounts.UploadBigContract(srvAcc, contract, "", true, 0)
+		assert.ErrorIs(t, err, errUpdateNoDiff)
+	})
+
+	t.Run("Update Contract", func(t *testing.T) {
+		_, updated, err := s.Accounts.UploadBigContract(srvAcc, flowkit.NewScript(updatedCode, nil, ""), "", true, 5000)
+		require.NoError(t, err)
+		assert.True(t, updated)
+
+		acc, err := s.Accounts.Get(srvAcc.Address())
+		require.NoError(t, err)
+		assert.Equal(t, updatedCode, acc.Contracts["Big"])
+	})
+
+	t.Run("Invalid Chunk Size", func(t *testing.T) {
+		_, _, err := s.Accounts.UploadBigContract(srvAcc, contract, "", true, -1)
+		assert.EqualError(t, err, "invalid chunk size -1, must be positive")
+	})
+
+	t.Run("Source Limits", func(t *testing.T) {
+		state.SetSourceLimits(flowkit.SourceLimits{MaxSize: 1024})
+		defer state.SetSourceLimits(flowkit.SourceLimits{})
+
+		_, _, err := s.Accounts.AddContract(srvAcc, contract, "", true)
+		assert.ErrorIs(t, err, flowkit.ErrSourceTooLarge)
+
+		_, updated, err := s.Accounts.UploadBigContract(srvAcc, contract, "", true, 0)
+		require.NoError(t, err)
+		assert.True(t, updated)
+
+		binary := flowkit.NewScript(append(append([]byte{}, contract.Code()...), 0), nil, "big.bin")
+		_, _, err = s.Accounts.UploadBigContract(srvAcc, binary, "", true, 0)
+		assert.ErrorIs(t, err, flowkit.ErrBinarySource)
+		assert.ErrorContains(t, err, "big.bin")
+	})
+}
+
+func TestAccountsRemoveContract_Integration(t *testing.T) {
+	t.Parallel()
+
+	state, s := setupIntegration()
+	srvAcc, _ := state.EmulatorServiceAccount()
+
+	c := tests.ContractSimple
+	// prepare existing contract
+	_, _, err := s.Accounts.AddContract(
+		srvAcc,
+		flowkit.NewScript(c.Source, nil, c.Filename),
+		"",
+		false,
+	)
+	assert.NoError(t, err)
+
+	t.Run("Remove Contract", func(t *testing.T) {
+		t.Parallel()
 
 		_, err := s.Accounts.RemoveContract(srvAcc, tests.ContractSimple.Name)
 		require.NoError(t, err)
@@ -607,6 +1627,306 @@ func TestAccountsRemoveContract_Integration(t *testing.T) {
 	})
 }
 
+func TestAccountsRemoveContractImpact_Integration(t *testing.T) {
+	t.Parallel()
+
+	state, s := setupIntegration()
+	srvAcc, _ := state.EmulatorServiceAccount()
+
+	_, _, err := s.Accounts.AddContract(
+		srvAcc,
+		flowkit.NewScript([]byte(`
+			pub contract Kitty {
+				pub resource NFT {}
+				pub fun mint(): @NFT {
+					return <- create NFT()
+				}
+			}
+		`), nil, "kitty.cdc"),
+		"",
+		false,
+	)
+	require.NoError(t, err)
+
+	// create another account holding a kitty with a public capability to it
+	privateKey, err := srvAcc.Key().PrivateKey()
+	require.NoError(t, err)
+	created, err := s.Accounts.Create(
+		srvAcc,
+		[]crypto.PublicKey{(*privateKey).PublicKey()},
+		[]int{1000},
+		[]crypto.SignatureAlgorithm{crypto.ECDSA_P256},
+		[]crypto.HashAlgorithm{crypto.SHA3_256},
+		nil,
+	)
+	require.NoError(t, err)
+
+	owner := flowkit.NewAccount("owner").SetAddress(created.Address).SetKey(srvAcc.Key())
+	state.Accounts().AddOrUpdate(owner)
+
+	_, _, err = s.Transactions.Send(
+		NewSingleTransactionAccount(owner),
+		flowkit.NewScript([]byte(fmt.Sprintf(`
+			import Kitty from 0x%s
+			transaction {
+				prepare(signer: AuthAccount) {
+					signer.save(<- Kitty.mint(), to: /storage/kitty)
+					signer.link<&Kitty.NFT>(/public/kitty, target: /storage/kitty)
+				}
+			}
+		`, srvAcc.Address())), nil, ""),
+		flow.DefaultTransactionGasLimit,
+		"",
+	)
+	require.NoError(t, err)
+
+	t.Run("Report", func(t *testing.T) {
+		t.Parallel()
+
+		report, err := s.Accounts.RemoveContractImpact(srvAcc.Address(), "Kitty", nil)
+		require.NoError(t, err)
+
+		assert.True(t, report.HasReferences())
+		assert.False(t, report.Truncated())
+		require.Len(t, report.Accounts, 2)
+		assert.Equal(t, srvAcc.Address(), report.Accounts[0].Address)
+		assert.Len(t, report.Accounts[0].References, 0)
+
+		nftType := fmt.Sprintf("A.%s.Kitty.NFT", srvAcc.Address())
+		assert.Equal(t, created.Address, report.Accounts[1].Address)
+		assert.Equal(t, []StorageReference{
+			{Path: "/public/kitty", Type: fmt.Sprintf("Capability<&%s>", nftType)},
+			{Path: "/storage/kitty", Type: nftType},
+		}, report.Accounts[1].References)
+
+		// only the provided accounts are scanned besides the contract account
+		report, err = s.Accounts.RemoveContractImpact(srvAcc.Address(), "Kitty", []flow.Address{})
+		require.NoError(t, err)
+		require.Len(t, report.Accounts, 1)
+		assert.False(t, report.HasReferences())
+	})
+
+	t.Run("Without State", func(t *testing.T) {
+		t.Parallel()
+
+		accounts := NewAccounts(s.Accounts.gateway, nil, output.NewStdoutLogger(output.NoneLog))
+		_, err := accounts.RemoveContractImpact(srvAcc.Address(), "Kitty", nil)
+		assert.ErrorIs(t, err, config.ErrDoesNotExist)
+
+		report, err := accounts.RemoveContractImpact(srvAcc.Address(), "Kitty", []flow.Address{})
+		require.NoError(t, err)
+		require.Len(t, report.Accounts, 1)
+	})
+
+	t.Run("Confirmation", func(t *testing.T) {
+		t.Parallel()
+
+		var confirmed *ImpactReport
+		s.Accounts.SetConfirmation(ConfirmationOptions{
+			RequireConfirmation: true,
+			RemoveContractCallback: func(report *ImpactReport) bool {
+				confirmed = report
+				return false
+			},
+		})
+
+		_, err := s.Accounts.RemoveContract(srvAcc, "Kitty")
+		assert.ErrorIs(t, err, ErrOperationCancelled)
+		require.NotNil(t, confirmed)
+		assert.True(t, confirmed.HasReferences())
+
+		acc, err := s.Accounts.Get(srvAcc.Address())
+		require.NoError(t, err)
+		assert.Contains(t, acc.Contracts, "Kitty")
+	})
+}
+
+func TestAccountsRemoveContractWithOptions_Integration(t *testing.T) {
+	t.Parallel()
+
+	// the service account holds a kitty linked on public and private paths and an unrelated link
+	setupKitty := func(t *testing.T) (*flowkit.Account, *Services) {
+		state, s := setupIntegration()
+		srvAcc, _ := state.EmulatorServiceAccount()
+
+		_, _, err := s.Accounts.AddContract(
+			srvAcc,
+			flowkit.NewScript([]byte(`
+				pub contract Kitty {
+					pub resource NFT {}
+					pub fun mint(): @NFT {
+						return <- create NFT()
+					}
+				}
+			`), nil, "kitty.cdc"),
+			"",
+			false,
+		)
+		require.NoError(t, err)
+
+		_, _, err = s.Transactions.Send(
+			NewSingleTransactionAccount(srvAcc),
+			flowkit.NewScript([]byte(fmt.Sprintf(`
+				import Kitty from 0x%s
+				transaction {
+					prepare(signer: AuthAccount) {
+						signer.save(<- Kitty.mint(), to: /storage/kitty)
+						signer.link<&Kitty.NFT>(/public/kitty, target: /storage/kitty)
+						signer.link<&Kitty.NFT>(/private/kittyProvider, target: /storage/kitty)
+						signer.save("meow", to: /storage/greeting)
+						signer.link<&String>(/public/greeting, target: /storage/greeting)
+					}
+				}
+			`, srvAcc.Address())), nil, ""),
+			flow.DefaultTransactionGasLimit,
+			"",
+		)
+		require.NoError(t, err)
+
+		return srvAcc, s
+	}
+
+	links := func(t *testing.T, s *Services, address flow.Address) cadence.Value {
+		value, err := s.Scripts.Execute(flowkit.NewScript([]byte(fmt.Sprintf(`
+			pub fun main(): [Path?] {
+				let account = getAuthAccount(0x%s)
+				return [
+					account.getLinkTarget(/public/kitty),
+					account.getLinkTarget(/private/kittyProvider),
+					account.getLinkTarget(/public/greeting)
+				]
+			}`, address)), nil, ""), "")
+		require.NoError(t, err)
+		return value
+	}
+
+	t.Run("Scanned Capabilities", func(t *testing.T) {
+		t.Parallel()
+
+		srvAcc, s := setupKitty(t)
+
+		_, err := s.Accounts.RemoveContractWithOptions(srvAcc, "Kitty", RemoveContractOptions{CleanupCapabilities: true})
+		require.NoError(t, err)
+
+		acc, err := s.Accounts.Get(srvAcc.Address())
+		require.NoError(t, err)
+		assert.NotContains(t, acc.Contracts, "Kitty")
+
+		assert.Equal(t, `[nil, nil, /storage/greeting]`, links(t, s, srvAcc.Address()).String())
+	})
+
+	t.Run("Provided Capabilities", func(t *testing.T) {
+		t.Parallel()
+
+		srvAcc, s := setupKitty(t)
+
+		_, err := s.Accounts.RemoveContractWithOptions(srvAcc, "Kitty", RemoveContractOptions{
+			CleanupCapabilities: true,
+			CapabilityPaths:     []cadence.Path{{Domain: "public", Identifier: "kitty"}},
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, `[nil, /storage/kitty, /storage/greeting]`, links(t, s, srvAcc.Address()).String())
+	})
+
+	t.Run("Without Cleanup", func(t *testing.T) {
+		t.Parallel()
+
+		srvAcc, s := setupKitty(t)
+
+		_, err := s.Accounts.RemoveContractWithOptions(srvAcc, "Kitty", RemoveContractOptions{})
+		require.NoError(t, err)
+
+		assert.Equal(t, `[/storage/kitty, /storage/kitty, /storage/greeting]`, links(t, s, srvAcc.Address()).String())
+	})
+}
+
+func TestAccountsDefaultSigner_Integration(t *testing.T) {
+	t.Parallel()
+
+	// alice is the default signer on the emulator network, testnet has no default signer
+	setupDefaultSigner := func(t *testing.T) (*flowkit.State, *Services) {
+		state, s := setupIntegration()
+		srvAcc, _ := state.EmulatorServiceAccount()
+
+		privateKey, err := srvAcc.Key().PrivateKey()
+		require.NoError(t, err)
+		created, err := s.Accounts.Create(
+			srvAcc,
+			[]crypto.PublicKey{(*privateKey).PublicKey()},
+			[]int{flow.AccountKeyWeightThreshold},
+			[]crypto.SignatureAlgorithm{crypto.ECDSA_P256},
+			[]crypto.HashAlgorithm{crypto.SHA3_256},
+			nil,
+		)
+		require.NoError(t, err)
+		state.Accounts().AddOrUpdate(flowkit.NewAccount("alice").SetAddress(created.Address).SetKey(srvAcc.Key()))
+
+		emulatorNetwork := config.DefaultEmulatorNetwork()
+		emulatorNetwork.DefaultSigner = "alice"
+		state.Networks().AddOrUpdate(emulatorNetwork.Name, emulatorNetwork)
+		state.Networks().AddOrUpdate("testnet", config.DefaultTestnetNetwork())
+
+		return state, s
+	}
+
+	c := tests.ContractSimple
+
+	t.Run("Default Signer", func(t *testing.T) {
+		t.Parallel()
+
+		state, s := setupDefaultSigner(t)
+
+		_, _, err := s.Accounts.AddContract(nil, flowkit.NewScript(c.Source, nil, c.Filename), "emulator", false)
+		require.NoError(t, err)
+
+		alice, _ := state.Accounts().ByName("alice")
+		acc, err := s.Accounts.Get(alice.Address())
+		require.NoError(t, err)
+		assert.Contains(t, acc.Contracts, c.Name)
+	})
+
+	t.Run("Explicit Override", func(t *testing.T) {
+		t.Parallel()
+
+		state, s := setupDefaultSigner(t)
+		srvAcc, _ := state.EmulatorServiceAccount()
+
+		_, _, err := s.Accounts.AddContract(srvAcc, flowkit.NewScript(c.Source, nil, c.Filename), "emulator", false)
+		require.NoError(t, err)
+
+		acc, err := s.Accounts.Get(srvAcc.Address())
+		require.NoError(t, err)
+		assert.Contains(t, acc.Contracts, c.Name)
+
+		alice, _ := state.Accounts().ByName("alice")
+		acc, err = s.Accounts.Get(alice.Address())
+		require.NoError(t, err)
+		assert.NotContains(t, acc.Contracts, c.Name)
+	})
+
+	t.Run("Missing Signer", func(t *testing.T) {
+		t.Parallel()
+
+		_, s := setupDefaultSigner(t)
+
+		_, _, err := s.Accounts.AddContract(nil, flowkit.NewScript(c.Source, nil, c.Filename), "testnet", false)
+		var noSignerErr *flowkit.NoSignerError
+		require.ErrorAs(t, err, &noSignerErr)
+		assert.EqualError(t, err, "no signer provided and no defaultSigner configured for network 'testnet'")
+	})
+
+	t.Run("No Service Account Fallback", func(t *testing.T) {
+		t.Parallel()
+
+		state, s := setupIntegration()
+		state.Networks().AddOrUpdate("emulator", config.DefaultEmulatorNetwork())
+
+		_, _, err := s.Transactions.Send(nil, flowkit.NewScript(tests.TransactionSimple.Source, nil, ""), flow.DefaultTransactionGasLimit, "emulator")
+		assert.EqualError(t, err, "no signer provided and no defaultSigner configured for network 'emulator'")
+	})
+}
+
 func TestAccountsGet_Integration(t *testing.T) {
 	t.Parallel()
 
@@ -627,7 +1947,8 @@ func TestAccountsGet_Integration(t *testing.T) {
 
 		acc, err := s.Accounts.Get(flow.HexToAddress("0x1"))
 		assert.Nil(t, acc)
-		assert.Equal(t, err.Error(), "could not find account with address 0000000000000001")
+		assert.ErrorIs(t, err, gateway.ErrAccountNotFound)
+		assert.EqualError(t, err, "failed to get account with address 0000000000000001: account not found")
 	})
 }
 
@@ -637,7 +1958,590 @@ func TestAccountsStakingInfo_Integration(t *testing.T) {
 	srvAcc, _ := state.EmulatorServiceAccount()
 
 	t.Run("Get Staking Info", func(t *testing.T) {
-		_, _, err := s.Accounts.StakingInfo(srvAcc.Address()) // unfortunately can't do integration test
+		_, err := s.Accounts.StakingInfo(srvAcc.Address()) // unfortunately can't do integration test
 		assert.Equal(t, err.Error(), "emulator chain not supported")
 	})
 }
+
+func TestAccountsReadContractField_Integration(t *testing.T) {
+	t.Parallel()
+
+	state, s := setupIntegration()
+	srvAcc, _ := state.EmulatorServiceAccount()
+
+	_, _, err := s.Accounts.AddContract(
+		srvAcc,
+		flowkit.NewScript([]byte(`
+			pub contract Market {
+				pub struct Info {
+					pub let version: String
+					access(self) let secret: String
+
+					init() {
+						self.version = "1.2.0"
+						self.secret = "hidden"
+					}
+				}
+
+				pub let name: String
+				pub let fee: UFix64
+				pub let info: Info
+				access(contract) let owner: Address
+
+				init() {
+					self.name = "Market"
+					self.fee = 0.025
+					self.info = Info()
+					self.owner = self.account.address
+				}
+			}
+		`), nil, "market.cdc"),
+		"",
+		false,
+	)
+	require.NoError(t, err)
+
+	t.Run("Read Field", func(t *testing.T) {
+		t.Parallel()
+
+		value, err := s.Accounts.ReadContractField(srvAcc.Address(), "Market", "name")
+		require.NoError(t, err)
+		assert.Equal(t, cadence.String("Market"), value)
+
+		value, err = s.Accounts.ReadContractField(srvAcc.Address(), "Market", "fee")
+		require.NoError(t, err)
+		assert.Equal(t, "0.02500000", value.String())
+	})
+
+	t.Run("Read Nested Field", func(t *testing.T) {
+		t.Parallel()
+
+		value, err := s.Accounts.ReadContractField(srvAcc.Address(), "Market", "info.version")
+		require.NoError(t, err)
+		assert.Equal(t, cadence.String("1.2.0"), value)
+	})
+
+	t.Run("Read Multiple Fields", func(t *testing.T) {
+		t.Parallel()
+
+		values, err := s.Accounts.ReadContractFields(srvAcc.Address(), "Market", []string{"fee", "info.version", "name"})
+		require.NoError(t, err)
+		require.Len(t, values, 3)
+		assert.Equal(t, "0.02500000", values[0].String())
+		assert.Equal(t, cadence.String("1.2.0"), values[1])
+		assert.Equal(t, cadence.String("Market"), values[2])
+	})
+
+	t.Run("Missing Field", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := s.Accounts.ReadContractField(srvAcc.Address(), "Market", "foo")
+		assert.EqualError(t, err, "contract Market has no public field 'foo'")
+
+		_, err = s.Accounts.ReadContractFields(srvAcc.Address(), "Market", []string{"fee", "info.bar"})
+		assert.EqualError(t, err, "contract Market has no public field 'info.bar'")
+	})
+
+	t.Run("Private Field", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := s.Accounts.ReadContractField(srvAcc.Address(), "Market", "owner")
+		assert.EqualError(t, err, "contract Market has no public field 'owner'")
+
+		_, err = s.Accounts.ReadContractField(srvAcc.Address(), "Market", "info.secret")
+		assert.EqualError(t, err, "contract Market has no public field 'info.secret'")
+	})
+
+	t.Run("Invalid Field Path", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := s.Accounts.ReadContractField(srvAcc.Address(), "Market", "info.version.length")
+		assert.ErrorContains(t, err, "field path 'info.version.length' is too deep")
+
+		_, err = s.Accounts.ReadContractField(srvAcc.Address(), "Market", "fee }; panic(\"\")")
+		assert.ErrorContains(t, err, "invalid field path")
+
+		_, err = s.Accounts.ReadContractField(srvAcc.Address(), "Market from 0x1", "fee")
+		assert.ErrorContains(t, err, "invalid contract name")
+
+		_, err = s.Accounts.ReadContractFields(srvAcc.Address(), "Market", nil)
+		assert.ErrorContains(t, err, "at least one field path is required")
+	})
+}
+
+func TestAccountsGetNFTs(t *testing.T) {
+	collectionPath := cadence.NewPath("public", "exampleNFTCollection")
+
+	t.Run("Get NFTs", func(t *testing.T) {
+		_, s, gw := setup()
+		address := flow.HexToAddress("0x01cf0e2f2f715450")
+
+		gw.ExecuteScript.Run(func(args mock.Arguments) {
+			script := string(args.Get(0).([]byte))
+			assert.Contains(t, script, "import NonFungibleToken from 0xf8d6e0586b0a20c7")
+			assert.Contains(t, script, ".getCapability(path)")
+			assert.Equal(t, []cadence.Value{cadence.NewAddress(address), collectionPath}, args.Get(1))
+
+			gw.ExecuteScript.Return(cadence.NewArray([]cadence.Value{cadence.NewUInt64(1), cadence.NewUInt64(42)}), nil)
+		})
+
+		ids, err := s.Accounts.GetNFTs(address, collectionPath)
+		require.NoError(t, err)
+		assert.Equal(t, []cadence.Value{cadence.NewUInt64(1), cadence.NewUInt64(42)}, ids)
+	})
+
+	t.Run("Get NFTs Empty Collection", func(t *testing.T) {
+		_, s, gw := setup()
+
+		gw.ExecuteScript.Run(func(args mock.Arguments) {
+			gw.ExecuteScript.Return(cadence.NewArray([]cadence.Value{}), nil)
+		})
+
+		ids, err := s.Accounts.GetNFTs(flow.HexToAddress("0x01cf0e2f2f715450"), collectionPath)
+		require.NoError(t, err)
+		assert.Len(t, ids, 0)
+	})
+
+	t.Run("Get NFTs Network Contract", func(t *testing.T) {
+		_, s, gw := setup()
+
+		gw.ExecuteScript.Run(func(args mock.Arguments) {
+			assert.Contains(t, string(args.Get(0).([]byte)), "import NonFungibleToken from 0x631e88ae7f1d7c20")
+			gw.ExecuteScript.Return(cadence.NewArray([]cadence.Value{}), nil)
+		})
+
+		_, err := s.Accounts.GetNFTs(flow.HexToAddress("df9c30eb2252f1fa"), collectionPath)
+		require.NoError(t, err)
+	})
+
+	t.Run("Get NFTs Storage Path", func(t *testing.T) {
+		_, s, gw := setup()
+
+		gw.ExecuteScript.Run(func(args mock.Arguments) {
+			script := string(args.Get(0).([]byte))
+			assert.Contains(t, script, "path: StoragePath")
+			assert.Contains(t, script, "borrow<&AnyResource{NonFungibleToken.CollectionPublic}>(from: path)")
+			gw.ExecuteScript.Return(cadence.NewArray([]cadence.Value{cadence.NewUInt64(7)}), nil)
+		})
+
+		ids, err := s.Accounts.GetNFTs(
+			flow.HexToAddress("0x01cf0e2f2f715450"),
+			cadence.NewPath("storage", "exampleNFTCollection"),
+		)
+		require.NoError(t, err)
+		assert.Equal(t, []cadence.Value{cadence.NewUInt64(7)}, ids)
+	})
+
+	t.Run("Get NFTs Private Path", func(t *testing.T) {
+		_, s, gw := setup()
+
+		_, err := s.Accounts.GetNFTs(flow.HexToAddress("0x01cf0e2f2f715450"), cadence.NewPath("private", "collection"))
+		assert.EqualError(t, err, "collection path /private/collection must be a public or storage path")
+		gw.Mock.AssertNotCalled(t, "ExecuteScript", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Get NFTs Script Error", func(t *testing.T) {
+		_, s, gw := setup()
+
+		gw.ExecuteScript.Run(func(args mock.Arguments) {
+			gw.ExecuteScript.Return(nil, fmt.Errorf("could not borrow a NonFungibleToken collection at the path"))
+		})
+
+		_, err := s.Accounts.GetNFTs(flow.HexToAddress("0x01cf0e2f2f715450"), collectionPath)
+		assert.EqualError(t, err, "could not borrow a NonFungibleToken collection at the path")
+	})
+}
+
+func TestAccountsEnabledContracts(t *testing.T) {
+	address := flow.HexToAddress("0x01cf0e2f2f715450")
+
+	capabilityTypes := func(identifiers ...string) cadence.Value {
+		values := make([]cadence.Value, len(identifiers))
+		for i, identifier := range identifiers {
+			values[i] = cadence.String(identifier)
+		}
+		return cadence.NewArray(values)
+	}
+
+	t.Run("Enabled Contracts", func(t *testing.T) {
+		_, s, gw := setup()
+
+		gw.ExecuteScript.Run(func(args mock.Arguments) {
+			assert.Contains(t, string(args.Get(0).([]byte)), "forEachPublic")
+			assert.Equal(t, []cadence.Value{cadence.NewAddress(address)}, args.Get(1))
+
+			gw.ExecuteScript.Return(capabilityTypes(
+				"A.f8d6e0586b0a20c7.Market.Storefront",
+				"A.0ae53cb6e3f42a79.FlowToken.Vault",
+				"A.f8d6e0586b0a20c7.ExampleNFT.Collection",
+				"&A.0ae53cb6e3f42a79.FlowToken.Vault",
+				"A.f8d6e0586b0a20c7.ExampleNFT.Collection",
+			), nil)
+		})
+
+		contracts, err := s.Accounts.EnabledContracts(address)
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"A.0ae53cb6e3f42a79.FlowToken",
+			"A.f8d6e0586b0a20c7.ExampleNFT",
+			"A.f8d6e0586b0a20c7.Market",
+		}, contracts)
+	})
+
+	t.Run("Enabled Contracts Skip Built-in Types", func(t *testing.T) {
+		_, s, gw := setup()
+
+		gw.ExecuteScript.Run(func(args mock.Arguments) {
+			gw.ExecuteScript.Return(capabilityTypes("String", "{String: Int}", "A.1.Registry.Entry"), nil)
+		})
+
+		contracts, err := s.Accounts.EnabledContracts(address)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"A.0000000000000001.Registry"}, contracts)
+	})
+
+	t.Run("Enabled Contracts No Capabilities", func(t *testing.T) {
+		_, s, gw := setup()
+
+		gw.ExecuteScript.Run(func(args mock.Arguments) {
+			gw.ExecuteScript.Return(capabilityTypes(), nil)
+		})
+
+		contracts, err := s.Accounts.EnabledContracts(address)
+		require.NoError(t, err)
+		assert.Len(t, contracts, 0)
+	})
+
+	t.Run("Enabled Contracts Unexpected Result", func(t *testing.T) {
+		_, s, gw := setup()
+
+		gw.ExecuteScript.Run(func(args mock.Arguments) {
+			gw.ExecuteScript.Return(cadence.NewArray([]cadence.Value{cadence.NewInt(1)}), nil)
+		})
+
+		_, err := s.Accounts.EnabledContracts(address)
+		assert.EqualError(t, err, "unexpected capability type 1")
+	})
+
+	t.Run("Enabled Contracts Script Error", func(t *testing.T) {
+		_, s, gw := setup()
+
+		gw.ExecuteScript.Run(func(args mock.Arguments) {
+			gw.ExecuteScript.Return(nil, fmt.Errorf("failed to execute script"))
+		})
+
+		_, err := s.Accounts.EnabledContracts(address)
+		assert.EqualError(t, err, "failed to execute script")
+	})
+}
+
+func TestAccountsEnabledContracts_Integration(t *testing.T) {
+	t.Parallel()
+
+	state, s := setupIntegration()
+	srvAcc, _ := state.EmulatorServiceAccount()
+
+	_, _, err := s.Accounts.AddContract(
+		srvAcc,
+		flowkit.NewScript([]byte(`
+			pub contract Registry {
+				pub resource Entries {}
+				pub struct Info {}
+
+				init() {
+					self.account.save(<-create Entries(), to: /storage/registryEntries)
+					self.account.link<&Entries>(/public/registryEntries, target: /storage/registryEntries)
+					self.account.save(Info(), to: /storage/registryInfo)
+					self.account.link<&Info>(/public/registryInfo, target: /storage/registryInfo)
+					self.account.link<&Entries>(/public/broken, target: /storage/missing)
+				}
+			}
+		`), nil, "registry.cdc"),
+		"",
+		false,
+	)
+	require.NoError(t, err)
+
+	contracts, err := s.Accounts.EnabledContracts(srvAcc.Address())
+	require.NoError(t, err)
+	assert.Contains(t, contracts, fmt.Sprintf("A.%s.Registry", srvAcc.Address().Hex()))
+	assert.True(t, slices.IsSorted(contracts))
+}
+
+// fakeKeyIndexer returns the addresses of the public keys.
+type fakeKeyIndexer map[string][]flow.Address
+
+func (f fakeKeyIndexer) AccountsByPublicKey(publicKey crypto.PublicKey) ([]flow.Address, error) {
+	return f[publicKey.String()], nil
+}
+
+func TestAccountsRecover(t *testing.T) {
+	// the key is valid on both curves
+	privateKeyHex := "ae2cc975dcd0af87ad7bba8e33cc38ce52dc4e0a6ecfa0d3e8b2c65a7a3fb5d1"
+	p256, err := crypto.DecodePrivateKeyHex(crypto.ECDSA_P256, privateKeyHex)
+	require.NoError(t, err)
+	secp256k1, err := crypto.DecodePrivateKeyHex(crypto.ECDSA_secp256k1, privateKeyHex)
+	require.NoError(t, err)
+	other, err := crypto.GeneratePrivateKey(crypto.ECDSA_P256, make([]byte, crypto.MinSeedLength))
+	require.NoError(t, err)
+
+	key := func(index int, privateKey crypto.PrivateKey, weight int, revoked bool) *flow.AccountKey {
+		return &flow.AccountKey{
+			Index:     index,
+			PublicKey: privateKey.PublicKey(),
+			SigAlgo:   privateKey.Algorithm(),
+			HashAlgo:  crypto.SHA3_256,
+			Weight:    weight,
+			Revoked:   revoked,
+		}
+	}
+
+	// setupRecover returns services where the networks gateways have the accounts by network and address.
+	setupRecover := func(accounts map[string]map[flow.Address][]*flow.AccountKey) *Services {
+		_, s, _ := setup()
+
+		recoveryGateway = func(network config.Network) (gateway.Gateway, error) {
+			gw := tests.DefaultMockGateway()
+			gw.GetAccount.Run(nil).Return(
+				func(address flow.Address) *flow.Account {
+					return &flow.Account{Address: address, Keys: accounts[network.Name][address]}
+				},
+				func(address flow.Address) error {
+					if _, ok := accounts[network.Name][address]; !ok {
+						return gateway.ErrAccountNotFound
+					}
+					return nil
+				},
+			)
+			return gw.Mock, nil
+		}
+
+		return s
+	}
+	defaultGateway := recoveryGateway
+	t.Cleanup(func() { recoveryGateway = defaultGateway })
+
+	first, second := flow.HexToAddress("0x01"), flow.HexToAddress("0x02")
+
+	t.Run("Match On Second Candidate", func(t *testing.T) {
+		s := setupRecover(map[string]map[flow.Address][]*flow.AccountKey{
+			"testnet": {
+				first:  {key(0, other, 1000, false)},
+				second: {key(0, other, 1000, false), key(1, p256, 500, true), key(2, p256, 1000, false)},
+			},
+		})
+
+		recovered, err := s.Accounts.Recover(privateKeyHex, []flow.Address{first, second}, []string{"testnet"})
+		require.NoError(t, err)
+		require.Len(t, recovered, 1)
+		assert.Equal(t, "testnet", recovered[0].Network)
+		assert.Equal(t, second, recovered[0].Address)
+		assert.Equal(t, 2, recovered[0].KeyIndex)
+		assert.Equal(t, crypto.ECDSA_P256, recovered[0].SigAlgo)
+		assert.Equal(t, crypto.SHA3_256, recovered[0].HashAlgo)
+		assert.Equal(t, 1000, recovered[0].Weight)
+	})
+
+	t.Run("No Match", func(t *testing.T) {
+		s := setupRecover(map[string]map[flow.Address][]*flow.AccountKey{
+			"testnet": {
+				first:  {key(0, other, 1000, false)},
+				second: {key(0, p256, 1000, true)},
+			},
+		})
+
+		recovered, err := s.Accounts.Recover(privateKeyHex, []flow.Address{first, second}, []string{"testnet"})
+		require.NoError(t, err)
+		assert.Empty(t, recovered)
+	})
+
+	t.Run("Both Curves", func(t *testing.T) {
+		s := setupRecover(map[string]map[flow.Address][]*flow.AccountKey{
+			"testnet": {first: {key(0, secp256k1, 1000, false)}},
+			"mainnet": {first: {key(0, p256, 1000, false)}},
+		})
+
+		recovered, err := s.Accounts.Recover("0x"+privateKeyHex, []flow.Address{first}, []string{"testnet", "mainnet"})
+		require.NoError(t, err)
+		require.Len(t, recovered, 2)
+		assert.Equal(t, "testnet", recovered[0].Network)
+		assert.Equal(t, crypto.ECDSA_secp256k1, recovered[0].SigAlgo)
+		assert.True(t, recovered[0].PrivateKey.Equals(secp256k1))
+		assert.Equal(t, "mainnet", recovered[1].Network)
+		assert.Equal(t, crypto.ECDSA_P256, recovered[1].SigAlgo)
+		assert.True(t, recovered[1].PrivateKey.Equals(p256))
+	})
+
+	t.Run("Key Indexer", func(t *testing.T) {
+		s := setupRecover(map[string]map[flow.Address][]*flow.AccountKey{
+			"testnet": {second: {key(3, secp256k1, 1000, false)}},
+		})
+
+		indexer := keyIndexers["testnet"]
+		keyIndexers["testnet"] = fakeKeyIndexer{secp256k1.PublicKey().String(): {second}}
+		defer func() { keyIndexers["testnet"] = indexer }()
+
+		recovered, err := s.Accounts.Recover(privateKeyHex, nil, []string{"testnet"})
+		require.NoError(t, err)
+		require.Len(t, recovered, 1)
+		assert.Equal(t, second, recovered[0].Address)
+		assert.Equal(t, 3, recovered[0].KeyIndex)
+
+		_, err = s.Accounts.Recover(privateKeyHex, nil, []string{"emulator"})
+		assert.EqualError(t, err, "no candidate addresses provided and network emulator has no key indexer to look them up")
+	})
+
+	t.Run("Invalid Key", func(t *testing.T) {
+		s := setupRecover(nil)
+
+		_, err := s.Accounts.Recover("zz", []flow.Address{first}, []string{"testnet"})
+		assert.EqualError(t, err, "invalid private key, it is not a ECDSA_P256 or ECDSA_secp256k1 key")
+	})
+}
+
+func TestAccountsRecoverAccount_Integration(t *testing.T) {
+	t.Parallel()
+
+	// createWithKeys creates an account with the keys of the private keys and returns it
+	createWithKeys := func(t *testing.T, s *Services, signer *flowkit.Account, weights []int) *flow.Account {
+		privateKeys := tests.PrivKeys()[:len(weights)]
+		pubKeys := make([]crypto.PublicKey, len(weights))
+		sigAlgos := make([]crypto.SignatureAlgorithm, len(weights))
+		hashAlgos := make([]crypto.HashAlgorithm, len(weights))
+		for i, privateKey := range privateKeys {
+			pubKeys[i] = privateKey.PublicKey()
+			sigAlgos[i] = privateKey.Algorithm()
+			hashAlgos[i] = crypto.SHA3_256
+		}
+
+		account, err := s.Accounts.Create(signer, pubKeys, weights, sigAlgos, hashAlgos, nil)
+		require.NoError(t, err)
+		return account
+	}
+
+	recoverySigner := func(index int) *flowkit.Account {
+		return flowkit.NewAccount("recovery").SetKey(
+			flowkit.NewHexAccountKeyFromPrivateKey(0, crypto.SHA3_256, tests.PrivKeys()[index]),
+		)
+	}
+
+	t.Run("Revoke Lost Keys", func(t *testing.T) {
+		t.Parallel()
+		state, s := setupIntegration()
+		srvAcc, _ := state.EmulatorServiceAccount()
+
+		lost := createWithKeys(t, s, srvAcc, []int{1000, 1000, 500})
+
+		ID, err := s.Accounts.RecoverAccount(recoverySigner(1), lost.Address)
+		require.NoError(t, err)
+		assert.NotEqual(t, flow.EmptyID, ID)
+
+		recovered, err := s.Accounts.Get(lost.Address)
+		require.NoError(t, err)
+		require.Len(t, recovered.Keys, 3)
+		assert.True(t, recovered.Keys[0].Revoked)
+		assert.False(t, recovered.Keys[1].Revoked)
+		assert.True(t, recovered.Keys[2].Revoked)
+	})
+
+	t.Run("No Recovery Key", func(t *testing.T) {
+		t.Parallel()
+		state, s := setupIntegration()
+		srvAcc, _ := state.EmulatorServiceAccount()
+
+		lost := createWithKeys(t, s, srvAcc, []int{1000})
+
+		_, err := s.Accounts.RecoverAccount(recoverySigner(1), lost.Address)
+		assert.ErrorContains(t, err, "the recovery key must be added before the other keys are lost")
+	})
+
+	t.Run("Recovery Key Weight", func(t *testing.T) {
+		t.Parallel()
+		state, s := setupIntegration()
+		srvAcc, _ := state.EmulatorServiceAccount()
+
+		lost := createWithKeys(t, s, srvAcc, []int{1000, 500})
+
+		_, err := s.Accounts.RecoverAccount(recoverySigner(1), lost.Address)
+		assert.ErrorContains(t, err, "has weight 500, a weight of at least 1000 is required")
+	})
+}
+
+func TestAccountsComputeLimit(t *testing.T) {
+	t.Parallel()
+
+	setupNetwork := func(limit uint64) (*flowkit.State, *Services, *tests.TestGateway) {
+		state, s, gw := setup()
+		state.Networks().AddOrUpdate("testnet", config.Network{
+			Name:         "testnet",
+			Host:         "127.0.0.1:3569",
+			ComputeLimit: limit,
+		})
+		return state, s, gw
+	}
+
+	t.Run("Transaction Limit", func(t *testing.T) {
+		_, s, _ := setupNetwork(0)
+
+		limit := s.Accounts.computeLimit(500, "testnet")
+		assert.Equal(t, uint64(500), limit)
+	})
+
+	t.Run("Network Limit", func(t *testing.T) {
+		_, s, _ := setupNetwork(300)
+
+		limit := s.Accounts.computeLimit(0, "testnet")
+		assert.Equal(t, uint64(300), limit)
+	})
+
+	t.Run("Lower Limit", func(t *testing.T) {
+		_, s, _ := setupNetwork(300)
+
+		limit := s.Accounts.computeLimit(500, "testnet")
+		assert.Equal(t, uint64(300), limit)
+
+		limit = s.Accounts.computeLimit(200, "testnet")
+		assert.Equal(t, uint64(200), limit)
+	})
+
+	t.Run("Unknown Network", func(t *testing.T) {
+		_, s, _ := setupNetwork(300)
+
+		limit := s.Accounts.computeLimit(500, "")
+		assert.Equal(t, uint64(500), limit)
+	})
+
+	t.Run("Default Limit", func(t *testing.T) {
+		_, s, _ := setupNetwork(0)
+
+		logger := &recordingLogger{}
+		s.Accounts.logger = logger
+
+		limit := s.Accounts.computeLimit(0, "testnet")
+		assert.Equal(t, uint64(gateway.DefaultComputeLimit), limit)
+		assert.Equal(t, []string{
+			"no compute limit set for the transaction or configured for the network, using the default limit 9999",
+		}, logger.debugs)
+	})
+
+	t.Run("Remove Contract Network Limit", func(t *testing.T) {
+		_, s, gw := setupNetwork(300)
+		signer := tests.Alice()
+		gw.GetAccount.Run(func(args mock.Arguments) {
+			account := tests.NewAccountWithAddress(args.Get(0).(flow.Address).String())
+			account.Keys[0].Index = 0
+			account.Contracts = map[string][]byte{"Foo": []byte("pub contract Foo {}")}
+			gw.GetAccount.Return(account, nil)
+		})
+
+		var gasLimit uint64
+		gw.SendSignedTransaction.Run(func(args mock.Arguments) {
+			gasLimit = args.Get(0).(*flowkit.Transaction).FlowTransaction().GasLimit
+			gw.SendSignedTransaction.Return(tests.NewTransaction(), nil)
+		})
+
+		_, err := s.Accounts.RemoveContractWithOptions(signer, "Foo", RemoveContractOptions{Network: "testnet"})
+		require.NoError(t, err)
+		assert.Equal(t, uint64(300), gasLimit)
+	})
+}
@@ -0,0 +1,763 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/cadence/runtime/parser"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+
+	"github.com/onflow/flow-cli/pkg/flowkit"
+	"github.com/onflow/flow-cli/pkg/flowkit/gateway"
+	"github.com/onflow/flow-cli/pkg/flowkit/project"
+)
+
+// AccountStateBundleVersion is the version of the account state bundles written by ExportState.
+const AccountStateBundleVersion = 1
+
+// storageValuesScript returns the copies of the struct values stored on the account with their types by path,
+// resources can't be copied and are returned without a value. It stops after the limit is exceeded.
+const storageValuesScript = `
+pub struct StoredValue {
+	pub let path: String
+	pub let type: String
+	pub let isResource: Bool
+	pub let value: AnyStruct?
+
+	init(path: String, type: String, isResource: Bool, value: AnyStruct?) {
+		self.path = path
+		self.type = type
+		self.isResource = isResource
+		self.value = value
+	}
+}
+
+pub fun main(address: Address, limit: Int): [StoredValue] {
+	let account = getAuthAccount(address)
+	let values: [StoredValue] = []
+
+	account.forEachStored(fun (path: StoragePath, type: Type): Bool {
+		let isResource = type.isSubtype(of: Type<@AnyResource>())
+		values.append(StoredValue(
+			path: path.toString(),
+			type: type.identifier,
+			isResource: isResource,
+			value: isResource ? nil : account.copy<AnyStruct>(from: path)
+		))
+		return values.length <= limit
+	})
+
+	return values
+}
+`
+
+// AccountStateBundle is a versioned snapshot of accounts written by ExportState and recreated by ImportState.
+type AccountStateBundle struct {
+	Version  int                    `json:"version"`
+	Accounts []ExportedAccountState `json:"accounts"`
+	// Limitations lists the parts of the accounts that were not exported because they can't be recreated.
+	Limitations []AccountStateLimitation `json:"limitations"`
+}
+
+// ExportedAccountState is the state of a single account in the bundle.
+type ExportedAccountState struct {
+	Address   flow.Address           `json:"address"`
+	Keys      []ExportedAccountKey   `json:"keys"`
+	Contracts []ExportedContract     `json:"contracts"`
+	Storage   []ExportedStorageValue `json:"storage"`
+}
+
+// ExportedAccountKey is a non-revoked public key of an exported account.
+type ExportedAccountKey struct {
+	PublicKey string `json:"publicKey"`
+	SigAlgo   string `json:"sigAlgo"`
+	HashAlgo  string `json:"hashAlgo"`
+	Weight    int    `json:"weight"`
+}
+
+// ExportedContract is a contract deployed on an exported account.
+type ExportedContract struct {
+	Name string `json:"name"`
+	Code string `json:"code"`
+}
+
+// ExportedStorageValue is a value stored on an exported account.
+type ExportedStorageValue struct {
+	Path string `json:"path"`
+	Type string `json:"type"`
+	// Value is the JSON-Cadence encoded value.
+	Value json.RawMessage `json:"value"`
+}
+
+// AccountStateLimitation describes a part of an account that was left out of the bundle.
+type AccountStateLimitation struct {
+	Address  flow.Address `json:"address"`
+	Path     string       `json:"path,omitempty"`
+	Contract string       `json:"contract,omitempty"`
+	Reason   string       `json:"reason"`
+}
+
+// ExportState captures the keys, contracts and storage of the accounts into a bundle, meant for preparing
+// test fixtures on the emulator once and importing them into fresh emulator instances with ImportState.
+//
+// Struct values built from literals, such as strings, numbers, addresses, paths, arrays and dictionaries, are
+// exported. Resources, composite values, capability links, revoked keys and contracts whose initializer takes
+// arguments can't be recreated and are listed in the bundle limitations instead.
+func (a *Accounts) ExportState(addresses []flow.Address) (bundle *AccountStateBundle, err error) {
+	err = a.diagnostics.run(a.tracing, "Accounts.ExportState", []Attribute{
+		addressesInput("addresses", addresses),
+	}, func(op *operation) error {
+		bundle, err = a.traced(op).exportState(addresses)
+		return err
+	})
+	return bundle, err
+}
+
+func (a *Accounts) exportState(addresses []flow.Address) (*AccountStateBundle, error) {
+	bundle := &AccountStateBundle{
+		Version:     AccountStateBundleVersion,
+		Accounts:    make([]ExportedAccountState, 0, len(addresses)),
+		Limitations: make([]AccountStateLimitation, 0),
+	}
+
+	for _, address := range addresses {
+		account, err := a.gateway.GetAccount(address)
+		if err != nil {
+			return nil, err
+		}
+
+		exported := ExportedAccountState{
+			Address:   address,
+			Keys:      make([]ExportedAccountKey, 0, len(account.Keys)),
+			Contracts: make([]ExportedContract, 0, len(account.Contracts)),
+			Storage:   make([]ExportedStorageValue, 0),
+		}
+		limit := func(path string, contract string, reason string) {
+			bundle.Limitations = append(bundle.Limitations, AccountStateLimitation{
+				Address:  address,
+				Path:     path,
+				Contract: contract,
+				Reason:   reason,
+			})
+		}
+
+		for _, key := range account.Keys {
+			if key.Revoked {
+				limit("", "", fmt.Sprintf("revoked key %d is not exported, key indexes may differ after import", key.Index))
+				continue
+			}
+			exported.Keys = append(exported.Keys, ExportedAccountKey{
+				PublicKey: hex.EncodeToString(key.PublicKey.Encode()),
+				SigAlgo:   key.SigAlgo.String(),
+				HashAlgo:  key.HashAlgo.String(),
+				Weight:    key.Weight,
+			})
+		}
+
+		names := make([]string, 0, len(account.Contracts))
+		for name := range account.Contracts {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			code := account.Contracts[name]
+			if hasInitializerParameters(code) {
+				limit("", name, "the contract initializer takes arguments, which are not recorded on chain")
+				continue
+			}
+			exported.Contracts = append(exported.Contracts, ExportedContract{Name: name, Code: string(code)})
+		}
+
+		storage, err := a.exportStorage(address, limit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export storage of account 0x%s: %w", address, err)
+		}
+		exported.Storage = storage
+
+		bundle.Accounts = append(bundle.Accounts, exported)
+	}
+
+	return bundle, nil
+}
+
+// exportStorage returns the stored values of the account that can be recreated, reporting the rest as limitations.
+func (a *Accounts) exportStorage(
+	address flow.Address,
+	limit func(path string, contract string, reason string),
+) ([]ExportedStorageValue, error) {
+	args := []cadence.Value{cadence.NewAddress(address), cadence.NewInt(maxStorageScanEntries)}
+
+	value, err := a.gateway.ExecuteScript([]byte(storageValuesScript), args)
+	if err != nil {
+		return nil, err
+	}
+	stored, ok := value.(cadence.Array)
+	if !ok {
+		return nil, fmt.Errorf("unexpected storage values result %s", value)
+	}
+	if len(stored.Values) > maxStorageScanEntries {
+		limit("", "", fmt.Sprintf("only the first %d storage paths are exported", maxStorageScanEntries))
+		stored.Values = stored.Values[:maxStorageScanEntries]
+	}
+
+	values := make([]ExportedStorageValue, 0, len(stored.Values))
+	for _, s := range stored.Values {
+		var entry struct {
+			Path       string
+			Type       string
+			IsResource bool
+			Value      cadence.Value
+		}
+		fields, ok := s.(cadence.Struct)
+		if !ok || len(fields.Fields) != 4 {
+			return nil, fmt.Errorf("unexpected stored value %s", s)
+		}
+		entry.Path, _ = fields.Fields[0].ToGoValue().(string)
+		entry.Type, _ = fields.Fields[1].ToGoValue().(string)
+		entry.IsResource, _ = fields.Fields[2].ToGoValue().(bool)
+		if optional, ok := fields.Fields[3].(cadence.Optional); ok {
+			entry.Value = optional.Value
+		}
+
+		if entry.IsResource {
+			limit(entry.Path, "", fmt.Sprintf("resource %s can't be copied", entry.Type))
+			continue
+		}
+		if _, err := parser.ParseType(nil, []byte(entry.Type), parser.Config{}); err != nil {
+			limit(entry.Path, "", fmt.Sprintf("type %s can't be written in a transaction", entry.Type))
+			continue
+		}
+		if _, err := cadenceLiteral(entry.Value); err != nil {
+			limit(entry.Path, "", err.Error())
+			continue
+		}
+
+		encoded, err := jsoncdc.Encode(entry.Value)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, ExportedStorageValue{
+			Path:  entry.Path,
+			Type:  entry.Type,
+			Value: json.RawMessage(strings.TrimSpace(string(encoded))),
+		})
+	}
+
+	links, err := a.gateway.ExecuteScript([]byte(storageTypesScript), args)
+	if err != nil {
+		return nil, err
+	}
+	if types, ok := links.(cadence.Dictionary); ok {
+		paths := make([]string, 0)
+		for _, pair := range types.Pairs {
+			path, _ := pair.Key.ToGoValue().(string)
+			if !strings.HasPrefix(path, "/storage/") {
+				paths = append(paths, path)
+			}
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			limit(path, "", "capability links are not exported")
+		}
+	}
+
+	return values, nil
+}
+
+// ImportState recreates the accounts of the bundle on the emulator and returns the addresses of the imported
+// accounts by their exported addresses.
+//
+// Accounts are created by the emulator service account at new addresses, in an order that deploys imported
+// contracts before the contracts importing them. Address imports and address values in the stored values are
+// changed to the new addresses. Accounts are created with their exported public keys, unless a private key is
+// provided in the key overrides for the hex encoded exported address, then the account is created with only that key.
+// Without an override a temporary key is added to deploy the contracts and store the values, which is revoked
+// afterwards, so the imported account has one more key than exported.
+func (a *Accounts) ImportState(
+	bundle *AccountStateBundle,
+	keyOverrides map[string]crypto.PrivateKey,
+) (addresses map[flow.Address]flow.Address, err error) {
+	err = a.diagnostics.run(a.tracing, "Accounts.ImportState", nil, func(op *operation) error {
+		addresses, err = a.traced(op).importState(bundle, keyOverrides)
+		return err
+	})
+	return addresses, err
+}
+
+func (a *Accounts) importState(
+	bundle *AccountStateBundle,
+	keyOverrides map[string]crypto.PrivateKey,
+) (map[flow.Address]flow.Address, error) {
+	isolated, ok := a.gateway.(gateway.IsolatedGateway)
+	if !ok || !isolated.Isolated() {
+		return nil, &UnsupportedGatewayError{Operation: "account state import"}
+	}
+	if bundle.Version != AccountStateBundleVersion {
+		return nil, fmt.Errorf(
+			"unsupported account state bundle version %d, expected version %d",
+			bundle.Version,
+			AccountStateBundleVersion,
+		)
+	}
+
+	serviceAccount, err := a.state.EmulatorServiceAccount()
+	if err != nil {
+		return nil, err
+	}
+
+	overrides := make(map[flow.Address]crypto.PrivateKey, len(keyOverrides))
+	for address, key := range keyOverrides {
+		overrides[flow.HexToAddress(address)] = key
+	}
+
+	ordered, err := importOrder(bundle.Accounts)
+	if err != nil {
+		return nil, err
+	}
+
+	// all the accounts are created first, so address values can be replaced with the addresses of any account
+	addresses := make(map[flow.Address]flow.Address, len(bundle.Accounts))
+	signers := make([]*flowkit.Account, len(ordered))
+	for i, exported := range ordered {
+		signers[i], err = a.createImportedAccount(serviceAccount, exported, overrides[exported.Address])
+		if err != nil {
+			return nil, fmt.Errorf("failed to import account 0x%s: %w", exported.Address, err)
+		}
+		addresses[exported.Address] = signers[i].Address()
+	}
+
+	for i, exported := range ordered {
+		err := a.restoreImportedAccount(signers[i], exported, overrides[exported.Address] == nil, addresses)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import account 0x%s: %w", exported.Address, err)
+		}
+	}
+
+	return addresses, nil
+}
+
+// createImportedAccount creates the account with the exported keys and a temporary key, or with only the
+// override key if provided, and returns a signer using the temporary or override key.
+func (a *Accounts) createImportedAccount(
+	creator *flowkit.Account,
+	exported ExportedAccountState,
+	override crypto.PrivateKey,
+) (*flowkit.Account, error) {
+	pubKeys := make([]crypto.PublicKey, 0, len(exported.Keys)+1)
+	weights := make([]int, 0, len(exported.Keys)+1)
+	sigAlgos := make([]crypto.SignatureAlgorithm, 0, len(exported.Keys)+1)
+	hashAlgos := make([]crypto.HashAlgorithm, 0, len(exported.Keys)+1)
+
+	signingKey := override
+	if signingKey == nil {
+		for _, key := range exported.Keys {
+			sigAlgo := crypto.StringToSignatureAlgorithm(key.SigAlgo)
+			encoded, err := hex.DecodeString(key.PublicKey)
+			if err != nil {
+				return nil, fmt.Errorf("invalid public key: %w", err)
+			}
+			pubKey, err := crypto.DecodePublicKey(sigAlgo, encoded)
+			if err != nil {
+				return nil, fmt.Errorf("invalid public key: %w", err)
+			}
+
+			pubKeys = append(pubKeys, pubKey)
+			weights = append(weights, key.Weight)
+			sigAlgos = append(sigAlgos, sigAlgo)
+			hashAlgos = append(hashAlgos, crypto.StringToHashAlgorithm(key.HashAlgo))
+		}
+
+		seed := make([]byte, crypto.MinSeedLength)
+		if _, err := rand.Read(seed); err != nil {
+			return nil, err
+		}
+		temporaryKey, err := crypto.GeneratePrivateKey(crypto.ECDSA_P256, seed)
+		if err != nil {
+			return nil, err
+		}
+		signingKey = temporaryKey
+	}
+
+	signingIndex := len(pubKeys)
+	pubKeys = append(pubKeys, signingKey.PublicKey())
+	weights = append(weights, flow.AccountKeyWeightThreshold)
+	sigAlgos = append(sigAlgos, signingKey.Algorithm())
+	hashAlgos = append(hashAlgos, crypto.SHA3_256)
+
+	created, err := a.create(creator, pubKeys, weights, sigAlgos, hashAlgos, nil, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return flowkit.NewAccount(fmt.Sprintf("import-%s", exported.Address)).
+		SetAddress(created.Address).
+		SetKey(flowkit.NewHexAccountKeyFromPrivateKey(signingIndex, crypto.SHA3_256, signingKey)), nil
+}
+
+// restoreImportedAccount deploys the contracts and stores the values of the exported account on the created account,
+// revoking the temporary key of the signer afterwards.
+func (a *Accounts) restoreImportedAccount(
+	signer *flowkit.Account,
+	exported ExportedAccountState,
+	temporaryKey bool,
+	addresses map[flow.Address]flow.Address,
+) error {
+	if len(exported.Contracts) > 0 {
+		contracts, err := importedContracts(exported, addresses)
+		if err != nil {
+			return err
+		}
+		// contracts added in the same transaction can't import each other, so each is deployed on its own
+		for _, contract := range contracts {
+			tx, err := flowkit.NewDeployAccountContractsTransaction(signer, []flowkit.AccountContract{contract})
+			if err != nil {
+				return err
+			}
+			if _, err := a.sendTransaction(tx, signer, ""); err != nil {
+				return fmt.Errorf("failed to deploy contract %s: %w", contract.Name, err)
+			}
+		}
+	}
+
+	if len(exported.Storage) > 0 {
+		code, err := storeValuesTransaction(exported.Storage, addresses)
+		if err != nil {
+			return err
+		}
+		tx, err := flowkit.NewAuthorizedTransaction(signer, code, nil)
+		if err != nil {
+			return err
+		}
+		if _, err := a.sendTransaction(tx, signer, ""); err != nil {
+			return fmt.Errorf("failed to store values: %w", err)
+		}
+	}
+
+	if temporaryKey {
+		tx, err := flowkit.NewAuthorizedTransaction(
+			signer,
+			[]byte(revokeKeysTransaction),
+			[]cadence.Value{cadence.NewArray([]cadence.Value{cadence.NewInt(signer.Key().Index())})},
+		)
+		if err != nil {
+			return err
+		}
+		if _, err := a.sendTransaction(tx, signer, ""); err != nil {
+			return fmt.Errorf("failed to revoke the temporary import key: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// importOrder sorts the accounts so the accounts of imported contracts come before the accounts importing them.
+func importOrder(accounts []ExportedAccountState) ([]ExportedAccountState, error) {
+	exported := make(map[flow.Address]bool, len(accounts))
+	for _, account := range accounts {
+		exported[account.Address] = true
+	}
+
+	dependencies := make(map[flow.Address][]flow.Address, len(accounts))
+	for _, account := range accounts {
+		for _, contract := range account.Contracts {
+			imports, err := contractAddressImports(contract)
+			if err != nil {
+				return nil, err
+			}
+			for _, address := range imports {
+				if address != account.Address && exported[address] {
+					dependencies[account.Address] = append(dependencies[account.Address], address)
+				}
+			}
+		}
+	}
+
+	ordered := make([]ExportedAccountState, 0, len(accounts))
+	added := make(map[flow.Address]bool, len(accounts))
+	for len(ordered) < len(accounts) {
+		progress := false
+		for _, account := range accounts {
+			if added[account.Address] || !allAdded(dependencies[account.Address], added) {
+				continue
+			}
+			ordered = append(ordered, account)
+			added[account.Address] = true
+			progress = true
+		}
+		if !progress {
+			return nil, fmt.Errorf("contracts of the exported accounts import each other in a cycle")
+		}
+	}
+
+	return ordered, nil
+}
+
+// importedContracts returns the contracts of the account with the imports changed to the new addresses,
+// ordered so the contracts imported from the same account are deployed first.
+func importedContracts(
+	account ExportedAccountState,
+	addresses map[flow.Address]flow.Address,
+) ([]flowkit.AccountContract, error) {
+	names := make(map[string]bool, len(account.Contracts))
+	for _, contract := range account.Contracts {
+		names[contract.Name] = true
+	}
+
+	dependencies := make(map[string][]string, len(account.Contracts))
+	for _, contract := range account.Contracts {
+		program, err := project.NewProgram(flowkit.NewScript([]byte(contract.Code), nil, ""))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse contract %s: %w", contract.Name, err)
+		}
+		for name, address := range program.AddressImports() {
+			if address == account.Address && names[name] {
+				dependencies[contract.Name] = append(dependencies[contract.Name], name)
+			}
+		}
+	}
+
+	contracts := make([]flowkit.AccountContract, 0, len(account.Contracts))
+	added := make(map[string]bool, len(account.Contracts))
+	for len(contracts) < len(account.Contracts) {
+		progress := false
+		for _, contract := range account.Contracts {
+			if added[contract.Name] || !allAdded(dependencies[contract.Name], added) {
+				continue
+			}
+			contracts = append(contracts, flowkit.AccountContract{
+				Name:   contract.Name,
+				Source: replaceAddressImports([]byte(contract.Code), addresses),
+			})
+			added[contract.Name] = true
+			progress = true
+		}
+		if !progress {
+			return nil, fmt.Errorf("contracts import each other in a cycle")
+		}
+	}
+
+	return contracts, nil
+}
+
+func allAdded[T comparable](values []T, added map[T]bool) bool {
+	for _, value := range values {
+		if !added[value] {
+			return false
+		}
+	}
+	return true
+}
+
+// contractAddressImports returns the addresses the contract imports contracts from.
+func contractAddressImports(contract ExportedContract) ([]flow.Address, error) {
+	program, err := project.NewProgram(flowkit.NewScript([]byte(contract.Code), nil, ""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse contract %s: %w", contract.Name, err)
+	}
+
+	imports := make([]flow.Address, 0)
+	for _, address := range program.AddressImports() {
+		imports = append(imports, address)
+	}
+	return imports, nil
+}
+
+var addressImportRegex = regexp.MustCompile(`(\bimport\s+[\w\s,]+?\s+from\s+)0x([0-9a-fA-F]+)`)
+
+// replaceAddressImports changes the address imports of the code to the new addresses of the imported accounts.
+func replaceAddressImports(code []byte, addresses map[flow.Address]flow.Address) []byte {
+	return addressImportRegex.ReplaceAllFunc(code, func(match []byte) []byte {
+		parts := addressImportRegex.FindSubmatch(match)
+		replaced, ok := addresses[flow.HexToAddress(string(parts[2]))]
+		if !ok {
+			return match
+		}
+		return []byte(fmt.Sprintf("%s0x%s", parts[1], replaced))
+	})
+}
+
+// storeValuesTransaction builds a transaction storing the values on the signer, replacing any values
+// already stored on their paths, e.g. by a contract initializer.
+func storeValuesTransaction(values []ExportedStorageValue, addresses map[flow.Address]flow.Address) ([]byte, error) {
+	statements := make([]string, 0, len(values))
+	for i, stored := range values {
+		value, err := jsoncdc.Decode(nil, stored.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value stored on %s: %w", stored.Path, err)
+		}
+		literal, err := cadenceLiteral(replaceAddresses(value, addresses))
+		if err != nil {
+			return nil, fmt.Errorf("invalid value stored on %s: %w", stored.Path, err)
+		}
+
+		statements = append(statements,
+			fmt.Sprintf("signer.load<AnyStruct>(from: %s)", stored.Path),
+			fmt.Sprintf("let value%d: %s = %s", i, stored.Type, literal),
+			fmt.Sprintf("signer.save(value%d, to: %s)", i, stored.Path),
+		)
+	}
+
+	return []byte(fmt.Sprintf(`
+transaction {
+	prepare(signer: AuthAccount) {
+		%s
+	}
+}`, strings.Join(statements, "\n\t\t"))), nil
+}
+
+// replaceAddresses returns the value with the addresses changed to their new addresses.
+func replaceAddresses(value cadence.Value, addresses map[flow.Address]flow.Address) cadence.Value {
+	switch v := value.(type) {
+	case cadence.Address:
+		if replaced, ok := addresses[flow.BytesToAddress(v.Bytes())]; ok {
+			return cadence.NewAddress(replaced)
+		}
+		return v
+	case cadence.Optional:
+		if v.Value == nil {
+			return v
+		}
+		return cadence.NewOptional(replaceAddresses(v.Value, addresses))
+	case cadence.Array:
+		values := make([]cadence.Value, len(v.Values))
+		for i, element := range v.Values {
+			values[i] = replaceAddresses(element, addresses)
+		}
+		return cadence.Array{ArrayType: v.ArrayType, Values: values}
+	case cadence.Dictionary:
+		pairs := make([]cadence.KeyValuePair, len(v.Pairs))
+		for i, pair := range v.Pairs {
+			pairs[i] = cadence.KeyValuePair{
+				Key:   replaceAddresses(pair.Key, addresses),
+				Value: replaceAddresses(pair.Value, addresses),
+			}
+		}
+		return cadence.Dictionary{DictionaryType: v.DictionaryType, Pairs: pairs}
+	default:
+		return value
+	}
+}
+
+// cadenceLiteral writes the value as a Cadence expression, an error is returned for values without literals.
+func cadenceLiteral(value cadence.Value) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "nil", nil
+	case cadence.Optional:
+		return cadenceLiteral(v.Value)
+	case cadence.Bool:
+		return fmt.Sprint(bool(v)), nil
+	case cadence.String:
+		return cadenceStringLiteral(string(v)), nil
+	case cadence.Character:
+		return cadenceStringLiteral(string(v)), nil
+	case cadence.Address:
+		return fmt.Sprintf("0x%s", v.Hex()), nil
+	case cadence.Path:
+		return v.String(), nil
+	case cadence.NumberValue:
+		return v.String(), nil
+	case cadence.Array:
+		elements := make([]string, len(v.Values))
+		for i, element := range v.Values {
+			literal, err := cadenceLiteral(element)
+			if err != nil {
+				return "", err
+			}
+			elements[i] = literal
+		}
+		return fmt.Sprintf("[%s]", strings.Join(elements, ", ")), nil
+	case cadence.Dictionary:
+		pairs := make([]string, len(v.Pairs))
+		for i, pair := range v.Pairs {
+			key, err := cadenceLiteral(pair.Key)
+			if err != nil {
+				return "", err
+			}
+			element, err := cadenceLiteral(pair.Value)
+			if err != nil {
+				return "", err
+			}
+			pairs[i] = fmt.Sprintf("%s: %s", key, element)
+		}
+		return fmt.Sprintf("{%s}", strings.Join(pairs, ", ")), nil
+	default:
+		return "", fmt.Errorf("%s values can't be written as literals", value.Type().ID())
+	}
+}
+
+// cadenceStringLiteral quotes the string with the escape sequences supported by Cadence.
+func cadenceStringLiteral(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case '"':
+			b.WriteString(`\"`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case 0:
+			b.WriteString(`\0`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u{%x}`, r)
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// hasInitializerParameters checks whether the initializer of the contract declared in the code takes arguments.
+func hasInitializerParameters(code []byte) bool {
+	program, err := parser.ParseProgram(nil, code, parser.Config{})
+	if err != nil {
+		return false
+	}
+
+	contract := program.SoleContractDeclaration()
+	if contract == nil {
+		return false
+	}
+	for _, initializer := range contract.Members.Initializers() {
+		if initializer.FunctionDeclaration.ParameterList != nil &&
+			len(initializer.FunctionDeclaration.ParameterList.Parameters) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,265 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-cli/pkg/flowkit"
+	"github.com/onflow/flow-cli/pkg/flowkit/tests"
+)
+
+func TestAccountsExportImportState_Integration(t *testing.T) {
+	t.Parallel()
+
+	signerOf := func(address flow.Address, privateKey crypto.PrivateKey) *flowkit.Account {
+		return flowkit.NewAccount(address.String()).
+			SetAddress(address).
+			SetKey(flowkit.NewHexAccountKeyFromPrivateKey(0, crypto.SHA3_256, privateKey))
+	}
+
+	create := func(t *testing.T, s *Services, signer *flowkit.Account, privateKey crypto.PrivateKey) *flowkit.Account {
+		account, err := s.Accounts.Create(
+			signer,
+			[]crypto.PublicKey{privateKey.PublicKey()},
+			[]int{flow.AccountKeyWeightThreshold},
+			[]crypto.SignatureAlgorithm{privateKey.Algorithm()},
+			[]crypto.HashAlgorithm{crypto.SHA3_256},
+			nil,
+		)
+		require.NoError(t, err)
+		return signerOf(account.Address, privateKey)
+	}
+
+	send := func(t *testing.T, s *Services, signer *flowkit.Account, code string) {
+		tx, err := flowkit.NewAuthorizedTransaction(signer, []byte(code), nil)
+		require.NoError(t, err)
+		_, err = s.Accounts.sendTransaction(tx, signer, "")
+		require.NoError(t, err)
+	}
+
+	copyStored := func(t *testing.T, s *Services, address flow.Address, valueType string, path string) cadence.Value {
+		value, err := s.Scripts.Execute(flowkit.NewScript([]byte(fmt.Sprintf(
+			"pub fun main(address: Address): %[1]s { return getAuthAccount(address).copy<%[1]s>(from: %[2]s)! }",
+			valueType,
+			path,
+		)), []cadence.Value{cadence.NewAddress(address)}, ""), "")
+		require.NoError(t, err)
+		return value
+	}
+
+	// export prepares two accounts on a source emulator, Bob's contract imports Alice's contract
+	export := func(t *testing.T) (*AccountStateBundle, *flowkit.Account, *flowkit.Account) {
+		state, s := setupIntegration()
+		srvAcc, _ := state.EmulatorServiceAccount()
+
+		alice := create(t, s, srvAcc, tests.PrivKeys()[0])
+		bob := create(t, s, srvAcc, tests.PrivKeys()[1])
+
+		_, _, err := s.Accounts.AddContract(alice, flowkit.NewScript([]byte(`
+			pub contract Foo {
+				pub resource Vault {}
+				pub fun createVault(): @Vault { return <- create Vault() }
+			}
+		`), nil, "Foo.cdc"), "", false)
+		require.NoError(t, err)
+		_, _, err = s.Accounts.AddContract(alice, flowkit.NewScript([]byte(fmt.Sprintf(`
+			import Foo from 0x%s
+			pub contract FooUser {}
+		`, alice.Address())), nil, "FooUser.cdc"), "", false)
+		require.NoError(t, err)
+		_, _, err = s.Accounts.AddContract(bob, flowkit.NewScript([]byte(fmt.Sprintf(`
+			import Foo from 0x%s
+			pub contract Bar {
+				pub let owner: Address
+				init() { self.owner = 0x%s }
+			}
+		`, alice.Address(), alice.Address())), nil, "Bar.cdc"), "", false)
+		require.NoError(t, err)
+
+		send(t, s, alice, fmt.Sprintf(`
+			import Foo from 0x%s
+			transaction {
+				prepare(signer: AuthAccount) {
+					signer.save("Hello \"World\"\n", to: /storage/greeting)
+					signer.save(42 as UInt64, to: /storage/count)
+					signer.save(0x%s as Address, to: /storage/friend)
+					signer.save([1, -2, 3], to: /storage/numbers)
+					signer.save({"a": 1.5}, to: /storage/prices)
+					signer.save(<- Foo.createVault(), to: /storage/vault)
+					signer.link<&Foo.Vault>(/public/vault, target: /storage/vault)
+				}
+			}
+		`, alice.Address(), bob.Address()))
+
+		bundle, err := s.Accounts.ExportState([]flow.Address{bob.Address(), alice.Address()})
+		require.NoError(t, err)
+
+		// the bundle is meant to be written to a file between the emulator instances
+		encoded, err := json.Marshal(bundle)
+		require.NoError(t, err)
+		var decoded AccountStateBundle
+		require.NoError(t, json.Unmarshal(encoded, &decoded))
+
+		return &decoded, alice, bob
+	}
+
+	t.Run("Round Trip", func(t *testing.T) {
+		t.Parallel()
+		bundle, alice, bob := export(t)
+
+		assert.Equal(t, AccountStateBundleVersion, bundle.Version)
+		require.Len(t, bundle.Accounts, 2)
+		// every account also has a FLOW vault and its links, which are reported the same way
+		assert.Contains(t, bundle.Limitations, AccountStateLimitation{
+			Address: alice.Address(),
+			Path:    "/storage/vault",
+			Reason:  fmt.Sprintf("resource A.%s.Foo.Vault can't be copied", alice.Address()),
+		})
+		assert.Contains(t, bundle.Limitations, AccountStateLimitation{
+			Address: alice.Address(),
+			Path:    "/public/vault",
+			Reason:  "capability links are not exported",
+		})
+		assert.Contains(t, bundle.Limitations, AccountStateLimitation{
+			Address: bob.Address(),
+			Path:    "/storage/flowTokenVault",
+			Reason:  "resource A.0ae53cb6e3f42a79.FlowToken.Vault can't be copied",
+		})
+
+		state, s := setupIntegration()
+		srvAcc, _ := state.EmulatorServiceAccount()
+		// shift the addresses of the imported accounts
+		create(t, s, srvAcc, tests.PrivKeys()[2])
+
+		addresses, err := s.Accounts.ImportState(bundle, nil)
+		require.NoError(t, err)
+		require.Len(t, addresses, 2)
+		newAlice, newBob := addresses[alice.Address()], addresses[bob.Address()]
+		assert.NotEqual(t, alice.Address(), newAlice)
+		assert.NotEqual(t, bob.Address(), newBob)
+
+		imported, err := s.Accounts.Get(newAlice)
+		require.NoError(t, err)
+		assert.Len(t, imported.Contracts, 2)
+		assert.Contains(t, string(imported.Contracts["FooUser"]), fmt.Sprintf("import Foo from 0x%s", newAlice))
+		require.Len(t, imported.Keys, 2)
+		assert.Equal(t, tests.PrivKeys()[0].PublicKey().String(), imported.Keys[0].PublicKey.String())
+		assert.False(t, imported.Keys[0].Revoked)
+		assert.True(t, imported.Keys[1].Revoked)
+
+		imported, err = s.Accounts.Get(newBob)
+		require.NoError(t, err)
+		assert.Contains(t, string(imported.Contracts["Bar"]), fmt.Sprintf("import Foo from 0x%s", newAlice))
+
+		assert.Equal(t, cadence.String("Hello \"World\"\n"), copyStored(t, s, newAlice, "String", "/storage/greeting"))
+		assert.Equal(t, cadence.NewUInt64(42), copyStored(t, s, newAlice, "UInt64", "/storage/count"))
+		assert.Equal(t, cadence.NewAddress(newBob), copyStored(t, s, newAlice, "Address", "/storage/friend"))
+		assert.Equal(t, "[1, -2, 3]", copyStored(t, s, newAlice, "[Int]", "/storage/numbers").String())
+		assert.Equal(t, `{"a": 1.50000000}`, copyStored(t, s, newAlice, "{String: UFix64}", "/storage/prices").String())
+
+		// the exported key can sign for the imported account
+		send(t, s, signerOf(newAlice, tests.PrivKeys()[0]), `
+			transaction {
+				prepare(signer: AuthAccount) {
+					signer.save(true, to: /storage/signed)
+				}
+			}
+		`)
+	})
+
+	t.Run("Key Override", func(t *testing.T) {
+		t.Parallel()
+		bundle, alice, _ := export(t)
+
+		_, s := setupIntegration()
+		override := tests.PrivKeys()[3]
+		addresses, err := s.Accounts.ImportState(bundle, map[string]crypto.PrivateKey{
+			"0x" + alice.Address().String(): override,
+		})
+		require.NoError(t, err)
+
+		imported, err := s.Accounts.Get(addresses[alice.Address()])
+		require.NoError(t, err)
+		require.Len(t, imported.Keys, 1)
+		assert.Equal(t, override.PublicKey().String(), imported.Keys[0].PublicKey.String())
+		assert.False(t, imported.Keys[0].Revoked)
+	})
+
+	t.Run("Unsupported Version", func(t *testing.T) {
+		t.Parallel()
+		_, s := setupIntegration()
+
+		_, err := s.Accounts.ImportState(&AccountStateBundle{Version: 2}, nil)
+		assert.EqualError(t, err, "unsupported account state bundle version 2, expected version 1")
+	})
+}
+
+func TestAccountsImportState(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Unsupported Gateway", func(t *testing.T) {
+		_, s, _ := setup()
+
+		_, err := s.Accounts.ImportState(&AccountStateBundle{Version: AccountStateBundleVersion}, nil)
+
+		var unsupported *UnsupportedGatewayError
+		assert.ErrorAs(t, err, &unsupported)
+	})
+
+	t.Run("Store Values Transaction", func(t *testing.T) {
+		code, err := storeValuesTransaction([]ExportedStorageValue{{
+			Path:  "/storage/owner",
+			Type:  "{String: Address?}",
+			Value: json.RawMessage(`{"type":"Dictionary","value":[{"key":{"type":"String","value":"tab\t"},"value":{"type":"Optional","value":{"type":"Address","value":"0x0000000000000001"}}}]}`),
+		}}, map[flow.Address]flow.Address{flow.HexToAddress("01"): flow.HexToAddress("02")})
+		require.NoError(t, err)
+
+		assert.Contains(t, string(code), "signer.load<AnyStruct>(from: /storage/owner)")
+		assert.Contains(t, string(code), `let value0: {String: Address?} = {"tab\t": 0x0000000000000002}`)
+		assert.Contains(t, string(code), "signer.save(value0, to: /storage/owner)")
+	})
+
+	t.Run("Import Order", func(t *testing.T) {
+		first := ExportedAccountState{Address: flow.HexToAddress("01"), Contracts: []ExportedContract{{
+			Name: "A",
+			Code: "import B from 0x02\npub contract A {}",
+		}}}
+		second := ExportedAccountState{Address: flow.HexToAddress("02"), Contracts: []ExportedContract{{
+			Name: "B",
+			Code: "pub contract B {}",
+		}}}
+
+		ordered, err := importOrder([]ExportedAccountState{first, second})
+		require.NoError(t, err)
+		assert.Equal(t, []ExportedAccountState{second, first}, ordered)
+
+		second.Contracts[0].Code = "import A from 0x01\npub contract B {}"
+		_, err = importOrder([]ExportedAccountState{first, second})
+		assert.EqualError(t, err, "contracts of the exported accounts import each other in a cycle")
+	})
+}
@@ -0,0 +1,153 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/onflow/flow-cli/pkg/flowkit"
+	"github.com/onflow/flow-cli/pkg/flowkit/config"
+	"github.com/onflow/flow-cli/pkg/flowkit/project"
+)
+
+// ImportAllowlistError is returned in strict imports mode when imports resolve to addresses not allowed by the
+// import pins of the network.
+type ImportAllowlistError struct {
+	Network    string
+	Violations []project.ImportViolation
+}
+
+func (i *ImportAllowlistError) Error() string {
+	violations := make([]string, len(i.Violations))
+	for n, violation := range i.Violations {
+		violations[n] = violation.String()
+	}
+
+	return fmt.Sprintf(
+		"strict imports on %s rejected %d import(s): %s",
+		i.Network,
+		len(i.Violations),
+		strings.Join(violations, ", "),
+	)
+}
+
+// strictImportAllowlist returns the import pins of the network from the lock file if strict imports
+// are enabled, either by the network configuration or by the caller.
+func strictImportAllowlist(state *flowkit.State, network string, strict bool) (project.ImportAllowlist, bool, error) {
+	if n, err := state.Networks().ByName(network); err == nil && n.StrictImports {
+		strict = true
+	}
+	if !strict {
+		return nil, false, nil
+	}
+
+	lock, err := state.LockFile()
+	if err != nil {
+		return nil, false, err
+	}
+
+	return project.ImportAllowlist(lock.PinsForNetwork(network)), true, nil
+}
+
+// checkImportAllowlist checks the substitutions against the allowlist and returns an ImportAllowlistError
+// listing all the violations.
+func checkImportAllowlist(
+	allowlist project.ImportAllowlist,
+	network string,
+	substitutions []project.ImportSubstitution,
+) error {
+	violations := allowlist.Check(substitutions)
+	if len(violations) > 0 {
+		return &ImportAllowlistError{Network: network, Violations: violations}
+	}
+
+	return nil
+}
+
+// deploymentImportSubstitutions resolves the imports of all the contracts deployed on the network
+// and returns the performed substitutions.
+func (p *Project) deploymentImportSubstitutions(network string) ([]project.ImportSubstitution, error) {
+	contracts, err := p.state.DeploymentContractsByNetwork(network)
+	if err != nil {
+		return nil, err
+	}
+
+	replacer := newImportReplacer(p.state, contracts, network)
+	substitutions := make([]project.ImportSubstitution, 0)
+	for _, contract := range contracts {
+		program, err := project.NewProgram(contract)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := replacer.Replace(program); err != nil {
+			return nil, fmt.Errorf("failed to resolve imports of contract %s: %w", contract.Name, err)
+		}
+		substitutions = append(substitutions, replacer.Substitutions()...)
+	}
+
+	return substitutions, nil
+}
+
+// GenerateImportAllowlist pins the imports of the deployed contracts on the network to the addresses
+// they currently resolve to and saves the pins in the lock file.
+//
+// Strict imports can then be enabled for the network without listing the contracts by hand.
+func (p *Project) GenerateImportAllowlist(network string) (allowlist project.ImportAllowlist, err error) {
+	err = p.diagnostics.run(p.tracing, "Project.GenerateImportAllowlist", []Attribute{
+		{Key: "network", Value: network},
+	}, func(op *operation) error {
+		allowlist, err = p.traced(op).generateImportAllowlist(network)
+		return err
+	})
+	return allowlist, err
+}
+
+func (p *Project) generateImportAllowlist(network string) (project.ImportAllowlist, error) {
+	if p.state == nil {
+		return nil, config.ErrDoesNotExist
+	}
+
+	if _, err := p.state.Networks().ByName(network); err != nil {
+		return nil, err
+	}
+
+	substitutions, err := p.deploymentImportSubstitutions(network)
+	if err != nil {
+		return nil, err
+	}
+
+	allowlist, err := project.NewImportAllowlist(substitutions)
+	if err != nil {
+		return nil, err
+	}
+
+	lock, err := p.state.LockFile()
+	if err != nil {
+		return nil, err
+	}
+
+	lock.SetPins(network, allowlist)
+	if err := p.state.SaveLockFile(lock); err != nil {
+		return nil, err
+	}
+
+	return allowlist, nil
+}
@@ -0,0 +1,169 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package services
+
+import (
+	"fmt"
+
+	"github.com/onflow/flow-go-sdk"
+
+	"github.com/onflow/flow-cli/pkg/flowkit"
+)
+
+// DefaultContractChunkSize is the number of contract source bytes sent in each transaction by UploadBigContract,
+// the chunks are hex encoded so the transaction stays below the network transaction size limit.
+const DefaultContractChunkSize = 3000
+
+// UploadBigContract deploys a contract too big to fit in a single transaction to the account.
+//
+// The contract source is split into chunks of chunkSize bytes, DefaultContractChunkSize if zero,
+// and each chunk is sent in a separate transaction that stages it in the account storage.
+// A final transaction deploys the staged code and removes it from the storage. If the upload
+// fails the staged code is left in the storage and replaced by the next upload.
+// The contract source is not limited in size, the other project contracts read to resolve imports
+// are limited by the source limits of the state.
+// The default signer of the network is used if the account is nil.
+//
+// The deployment is recorded in the lock file.
+func (a *Accounts) UploadBigContract(
+	account *flowkit.Account,
+	contract *flowkit.Script,
+	network string,
+	updateExisting bool,
+	chunkSize int,
+) (ID flow.Identifier, updated bool, err error) {
+	err = a.diagnostics.run(a.tracing, "Accounts.UploadBigContract", []Attribute{
+		accountInput("account", account),
+		scriptInput("contract", contract),
+		{Key: "network", Value: network},
+	}, func(op *operation) error {
+		ID, updated, err = a.traced(op).uploadBigContract(account, contract, network, updateExisting, chunkSize)
+		return err
+	})
+	return ID, updated, err
+}
+
+func (a *Accounts) uploadBigContract(
+	account *flowkit.Account,
+	contract *flowkit.Script,
+	network string,
+	updateExisting bool,
+	chunkSize int,
+) (flow.Identifier, bool, error) {
+	account, err := resolveSigner(a.state, account, network)
+	if err != nil {
+		return flow.EmptyID, false, err
+	}
+	if chunkSize < 0 {
+		return flow.EmptyID, false, fmt.Errorf("invalid chunk size %d, must be positive", chunkSize)
+	}
+	if chunkSize == 0 {
+		chunkSize = DefaultContractChunkSize
+	}
+
+	// the contract is uploaded in chunks so its size is not limited, but it must still be text
+	contract, err = sanitizeContract(contract, flowkit.SourceLimits{MaxSize: -1})
+	if err != nil {
+		return flow.EmptyID, false, err
+	}
+
+	program, name, err := a.resolveContract(contract, network, false)
+	if err != nil {
+		return flow.EmptyID, false, err
+	}
+	code := program.Code()
+
+	flowAccount, err := a.gateway.GetAccount(account.Address())
+	if err != nil {
+		return flow.EmptyID, false, err
+	}
+	existingContract, exists, err := a.checkExistingContract(account, flowAccount, name, code, updateExisting, false)
+	if err != nil {
+		return flow.EmptyID, false, err
+	}
+	update := exists && updateExisting
+	if update {
+		a.warnIncompatibleUpdate(name, existingContract, code)
+	}
+
+	chunks := make([][]byte, 0, len(code)/chunkSize+1)
+	for start := 0; start < len(code); start += chunkSize {
+		end := start + chunkSize
+		if end > len(code) {
+			end = len(code)
+		}
+		chunks = append(chunks, code[start:end])
+	}
+
+	steps := a.logger.StartProgressSteps(
+		fmt.Sprintf("Uploading contract '%s' to account '%s'", name, account.Address()),
+		len(chunks)+1,
+	)
+	defer steps.Complete()
+
+	for i, chunk := range chunks {
+		tx, err := flowkit.NewStageContractChunkTransaction(account, name, chunk, i == 0)
+		if err != nil {
+			return flow.EmptyID, false, err
+		}
+
+		if _, err := a.sendTransaction(tx, account, network); err != nil {
+			return flow.EmptyID, false, fmt.Errorf("failed to stage chunk %d of %d of contract %s: %w", i+1, len(chunks), name, err)
+		}
+		steps.Increment(fmt.Sprintf("Staged chunk %d of %d", i+1, len(chunks)))
+	}
+
+	tx, err := flowkit.NewDeployStagedContractTransaction(account, name, code, contract.Args, update)
+	if err != nil {
+		return flow.EmptyID, false, err
+	}
+
+	ID, err := a.sendTransaction(tx, account, network)
+	if err != nil {
+		return flow.EmptyID, false, fmt.Errorf("failed to deploy staged contract %s: %w", name, err)
+	}
+	steps.Increment(fmt.Sprintf("Contract '%s' %s", name, map[bool]string{true: "updated", false: "created"}[update]))
+
+	a.recordDeployment(network, name, account.Address(), code, ID, nil)
+
+	return ID, update, nil
+}
+
+// sendTransaction prepares, sends and waits for the transaction signed by the account to be sealed.
+func (a *Accounts) sendTransaction(tx *flowkit.Transaction, account *flowkit.Account, network string) (flow.Identifier, error) {
+	tx, err := a.prepareTransaction(tx, account, network)
+	if err != nil {
+		return flow.EmptyID, err
+	}
+
+	sentTx, err := a.sequences.send(tx)
+	if err != nil {
+		return flow.EmptyID, err
+	}
+
+	result, err := a.sequences.result(tx, sentTx)
+	if err != nil {
+		return flow.EmptyID, err
+	}
+	if result.Error != nil {
+		return flow.EmptyID, flowkit.NewCadenceRuntimeError(result.Error)
+	}
+
+	return sentTx.ID(), nil
+}
@@ -0,0 +1,315 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+	flowGo "github.com/onflow/flow-go/model/flow"
+
+	"github.com/onflow/flow-cli/pkg/flowkit"
+	"github.com/onflow/flow-cli/pkg/flowkit/config"
+	"github.com/onflow/flow-cli/pkg/flowkit/gateway"
+	"github.com/onflow/flow-cli/pkg/flowkit/output"
+	"github.com/onflow/flow-cli/pkg/flowkit/project"
+)
+
+// fundAccountTransaction transfers FLOW from the emulator service account to the recipient.
+const fundAccountTransaction = `
+import FungibleToken from 0xee82856bf20e2aa6
+import FlowToken from 0x0ae53cb6e3f42a79
+
+transaction(amount: UFix64, recipient: Address) {
+	let sentVault: @FungibleToken.Vault
+
+	prepare(signer: AuthAccount) {
+		let vault = signer.borrow<&FlowToken.Vault>(from: /storage/flowTokenVault)
+			?? panic("could not borrow reference to the service account vault")
+		self.sentVault <- vault.withdraw(amount: amount)
+	}
+
+	execute {
+		let receiver = getAccount(recipient)
+			.getCapability(/public/flowTokenReceiver)
+			.borrow<&{FungibleToken.Receiver}>()
+			?? panic("could not borrow receiver reference to the recipient vault")
+		receiver.deposit(from: <-self.sentVault)
+	}
+}
+`
+
+// BootstrapOptions configures how the emulator state is bootstrapped.
+type BootstrapOptions struct {
+	// FundAmount is transferred from the service account to each created account, nothing is transferred if zero.
+	FundAmount cadence.UFix64
+	// RewriteAddresses changes the addresses of the accounts in the state to the addresses
+	// assigned by the emulator, if they differ from the configured addresses.
+	RewriteAddresses bool
+	// Deploy configures the deployment of the project contracts.
+	Deploy DeployOptions
+}
+
+// AddressMismatch describes an account the emulator created at a different address than configured.
+type AddressMismatch struct {
+	Account    string
+	Configured flow.Address
+	Created    flow.Address
+}
+
+// AddressMismatchError is returned when accounts are created at different addresses than configured
+// and the addresses are not rewritten.
+type AddressMismatchError struct {
+	Mismatches []AddressMismatch
+}
+
+func (a *AddressMismatchError) Error() string {
+	mismatches := make([]string, len(a.Mismatches))
+	for i, m := range a.Mismatches {
+		mismatches[i] = fmt.Sprintf("%s (configured 0x%s, created 0x%s)", m.Account, m.Configured, m.Created)
+	}
+
+	return fmt.Sprintf(
+		"accounts created at different addresses than configured: %s, update the configuration or rewrite the addresses",
+		strings.Join(mismatches, ", "),
+	)
+}
+
+// BootstrapResult describes the changes made by bootstrapping the emulator.
+type BootstrapResult struct {
+	// Created contains the names of the accounts created.
+	Created []string
+	// Existing contains the names of the accounts that already existed at their configured address.
+	Existing   []string
+	Mismatches []AddressMismatch
+	// Contracts are the project contracts for the network, unchanged contracts are included.
+	Contracts []*project.Contract
+}
+
+// Bootstrap creates the configured accounts on the emulator and deploys the project.
+//
+// Each account in the state except the service account is created with its configured key,
+// so the configured private key can sign for it right away. Accounts are created in the order of their
+// configured addresses on the emulator. Accounts that already exist at their configured address with the
+// configured key are skipped and so are contracts that are already deployed with the same code,
+// which makes bootstrapping an already bootstrapped emulator a no-op. Accounts configured with
+// addresses of other networks are ignored.
+func (p *Project) Bootstrap(network string, options BootstrapOptions) (result *BootstrapResult, err error) {
+	err = p.diagnostics.run(p.tracing, "Project.Bootstrap", []Attribute{
+		{Key: "network", Value: network},
+	}, func(op *operation) error {
+		result, err = p.traced(op).bootstrap(network, options)
+		return err
+	})
+	return result, err
+}
+
+func (p *Project) bootstrap(network string, options BootstrapOptions) (*BootstrapResult, error) {
+	if p.state == nil {
+		return nil, config.ErrDoesNotExist
+	}
+
+	if network != config.DefaultEmulatorNetwork().Name {
+		return nil, fmt.Errorf("bootstrap is only supported on the %s network", config.DefaultEmulatorNetwork().Name)
+	}
+
+	serviceAccount, err := p.state.EmulatorServiceAccount()
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := newSharedAccounts(p.gateway, p.state, output.NewStdoutLogger(output.NoneLog), p.tracing, p.sequences, p.forked)
+
+	result := &BootstrapResult{
+		Created:    make([]string, 0),
+		Existing:   make([]string, 0),
+		Mismatches: make([]AddressMismatch, 0),
+	}
+
+	for _, account := range emulatorAccounts(*p.state.Accounts(), serviceAccount) {
+		onChain, err := p.gateway.GetAccount(account.Address())
+		if err != nil && !errors.Is(err, gateway.ErrAccountNotFound) {
+			return nil, fmt.Errorf("failed to get account %s: %w", account.Name(), err)
+		}
+		if err == nil {
+			// the address can be taken by another account created by the emulator, which has other keys
+			if problem, _ := checkAccountKey(account, onChain); problem == nil {
+				result.Existing = append(result.Existing, account.Name())
+				continue
+			}
+			output.Warn(p.logger, fmt.Sprintf(
+				"account at the configured address 0x%s of %s does not have its configured key, creating it",
+				account.Address(),
+				account.Name(),
+			))
+		}
+
+		created, err := p.createAccount(accounts, serviceAccount, account, network)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create account %s: %w", account.Name(), err)
+		}
+		result.Created = append(result.Created, account.Name())
+		p.logger.Info(fmt.Sprintf("%s -> 0x%s [created]", output.Green(account.Name()), created))
+
+		if options.FundAmount > 0 {
+			if err := p.fundAccount(accounts, serviceAccount, created, options.FundAmount, network); err != nil {
+				return nil, fmt.Errorf("failed to fund account %s: %w", account.Name(), err)
+			}
+		}
+
+		if created != account.Address() {
+			result.Mismatches = append(result.Mismatches, AddressMismatch{
+				Account:    account.Name(),
+				Configured: account.Address(),
+				Created:    created,
+			})
+		}
+	}
+
+	if len(result.Mismatches) > 0 {
+		if !options.RewriteAddresses {
+			return result, &AddressMismatchError{Mismatches: result.Mismatches}
+		}
+
+		for _, mismatch := range result.Mismatches {
+			account, err := p.state.Accounts().ByName(mismatch.Account)
+			if err != nil {
+				return nil, err
+			}
+			account.SetAddress(mismatch.Created)
+			output.Warn(p.logger, fmt.Sprintf(
+				"address of account %s changed from 0x%s to 0x%s",
+				mismatch.Account,
+				mismatch.Configured,
+				mismatch.Created,
+			))
+		}
+	}
+
+	result.Contracts, err = p.DeployWithOptions(network, false, options.Deploy)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// emulatorAccounts returns the accounts configured with emulator addresses except the service account,
+// in the order the emulator creates their addresses, so they are created at their configured addresses
+// whatever their order in the configuration is.
+func emulatorAccounts(accounts flowkit.Accounts, serviceAccount *flowkit.Account) []*flowkit.Account {
+	type indexedAccount struct {
+		account *flowkit.Account
+		index   uint64
+	}
+
+	chain := flowGo.Emulator.Chain()
+	indexed := make([]indexedAccount, 0, len(accounts))
+	for i := range accounts {
+		account := &accounts[i]
+		if account.Name() == serviceAccount.Name() {
+			continue
+		}
+		index, err := chain.IndexFromAddress(flowGo.Address(account.Address()))
+		if err != nil {
+			continue
+		}
+		indexed = append(indexed, indexedAccount{account: account, index: index})
+	}
+
+	sort.SliceStable(indexed, func(i, j int) bool {
+		return indexed[i].index < indexed[j].index
+	})
+
+	emulator := make([]*flowkit.Account, len(indexed))
+	for i, a := range indexed {
+		emulator[i] = a.account
+	}
+	return emulator
+}
+
+// createAccount creates the account on the network with the configured key and returns the assigned address.
+func (p *Project) createAccount(
+	accounts *Accounts,
+	signer *flowkit.Account,
+	account *flowkit.Account,
+	network string,
+) (flow.Address, error) {
+	key := account.Key()
+	keySigner, err := key.Signer(context.Background())
+	if err != nil {
+		return flow.EmptyAddress, err
+	}
+
+	created, err := accounts.CreateForNetwork(
+		signer,
+		[]crypto.PublicKey{keySigner.PublicKey()},
+		[]int{flow.AccountKeyWeightThreshold},
+		[]crypto.SignatureAlgorithm{key.SigAlgo()},
+		[]crypto.HashAlgorithm{key.HashAlgo()},
+		nil,
+		network,
+	)
+	if err != nil {
+		return flow.EmptyAddress, err
+	}
+
+	return created.Address, nil
+}
+
+// fundAccount transfers the amount of FLOW from the signer to the address.
+func (p *Project) fundAccount(
+	accounts *Accounts,
+	signer *flowkit.Account,
+	address flow.Address,
+	amount cadence.UFix64,
+	network string,
+) error {
+	tx, err := flowkit.NewAuthorizedTransaction(
+		signer,
+		[]byte(fundAccountTransaction),
+		[]cadence.Value{amount, cadence.NewAddress(address)},
+	)
+	if err != nil {
+		return err
+	}
+
+	tx, err = accounts.prepareTransaction(tx, signer, network)
+	if err != nil {
+		return err
+	}
+
+	sentTx, err := p.sequences.sendWithTransfer(tx, amount)
+	if err != nil {
+		return err
+	}
+
+	result, err := p.sequences.result(tx, sentTx)
+	if err != nil {
+		return err
+	}
+
+	return result.Error
+}
@@ -0,0 +1,242 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+
+	"github.com/onflow/flow-cli/pkg/flowkit"
+)
+
+// feesDeductedEventTypes are the types of the event the FlowFees contract emits with the fee paid by a transaction,
+// on the emulator, testnet and mainnet.
+var feesDeductedEventTypes = []string{
+	"A.e5a8b7f23e8b548f.FlowFees.FeesDeducted",
+	"A.912d5440f7e3769e.FlowFees.FeesDeducted",
+	"A.f919ee77447b7497.FlowFees.FeesDeducted",
+}
+
+// ErrBudgetExceeded is returned when sending a transaction would exceed the configured budget.
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+// Budget limits the FLOW spent on transaction fees and funding transfers by the transactions sent by the services.
+type Budget struct {
+	// MaxTotal limits the FLOW spent by all the transactions, unlimited if zero.
+	MaxTotal cadence.UFix64
+	// MaxPerTransaction limits the FLOW spent by a single transaction, unlimited if zero.
+	MaxPerTransaction cadence.UFix64
+	// FeeEstimate is the fee expected for each transaction before its actual fee is known, 0.001 FLOW if zero.
+	FeeEstimate cadence.UFix64
+}
+
+// defaultFeeEstimate is the fee expected for each transaction if the budget doesn't set one, 0.001 FLOW.
+const defaultFeeEstimate = cadence.UFix64(100_000)
+
+func (b Budget) feeEstimate() cadence.UFix64 {
+	if b.FeeEstimate == 0 {
+		return defaultFeeEstimate
+	}
+	return b.FeeEstimate
+}
+
+// BudgetState is the FLOW spent within the budget so far.
+type BudgetState struct {
+	Budget Budget
+	// Spent is the FLOW spent by all the sent transactions, including the estimated fees of Pending transactions.
+	Spent cadence.UFix64
+	// Pending is the number of sent transactions whose fee is still estimated, because their result wasn't
+	// received yet or didn't contain a fee event.
+	Pending      int
+	Transactions int
+}
+
+// Remaining returns the FLOW that can still be spent by all the transactions, false if the total is unlimited.
+func (s BudgetState) Remaining() (cadence.UFix64, bool) {
+	if s.Budget.MaxTotal == 0 {
+		return 0, false
+	}
+	if s.Spent >= s.Budget.MaxTotal {
+		return 0, true
+	}
+	return s.Budget.MaxTotal - s.Spent, true
+}
+
+// BudgetExceededError describes the transaction that would have exceeded the budget.
+type BudgetExceededError struct {
+	// Operation describes the rejected transaction.
+	Operation string
+	// Cost is the FLOW the rejected transaction would have spent at most.
+	Cost cadence.UFix64
+	// Spent is the FLOW spent by the transactions sent before.
+	Spent cadence.UFix64
+	// Limit is the exceeded limit, PerTransaction tells whether it limits a single transaction or the total.
+	Limit          cadence.UFix64
+	PerTransaction bool
+}
+
+func (b *BudgetExceededError) Error() string {
+	limit := "total limit"
+	if b.PerTransaction {
+		limit = "limit per transaction"
+	}
+
+	return fmt.Sprintf(
+		"%s: %s would spend up to %s FLOW with %s FLOW spent so far, exceeding the %s of %s FLOW",
+		ErrBudgetExceeded,
+		b.Operation,
+		b.Cost,
+		b.Spent,
+		limit,
+		b.Limit,
+	)
+}
+
+func (b *BudgetExceededError) Unwrap() error {
+	return ErrBudgetExceeded
+}
+
+// budgetSpending is the FLOW a sent transaction is accounted for.
+type budgetSpending struct {
+	fee      cadence.UFix64
+	transfer cadence.UFix64
+}
+
+// budgetTracker keeps the FLOW spent by the sent transactions within the budget.
+type budgetTracker struct {
+	mu      sync.Mutex
+	budget  Budget
+	spent   cadence.UFix64
+	sent    int
+	pending map[flow.Identifier]budgetSpending
+}
+
+func newBudgetTracker(budget Budget) *budgetTracker {
+	return &budgetTracker{
+		budget:  budget,
+		pending: make(map[flow.Identifier]budgetSpending),
+	}
+}
+
+// reserve accounts for the estimated fee and the transfer of the transaction, or returns a BudgetExceededError
+// if the transaction would exceed the budget.
+func (b *budgetTracker) reserve(tx *flowkit.Transaction, transfer cadence.UFix64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	spending := budgetSpending{fee: b.budget.feeEstimate(), transfer: transfer}
+	cost := spending.fee + spending.transfer
+
+	exceeded := &BudgetExceededError{Operation: transactionOperation(tx), Cost: cost, Spent: b.spent}
+	if b.budget.MaxPerTransaction > 0 && cost > b.budget.MaxPerTransaction {
+		exceeded.Limit = b.budget.MaxPerTransaction
+		exceeded.PerTransaction = true
+		return exceeded
+	}
+	if b.budget.MaxTotal > 0 && b.spent+cost > b.budget.MaxTotal {
+		exceeded.Limit = b.budget.MaxTotal
+		return exceeded
+	}
+
+	b.spent += cost
+	b.sent++
+	b.pending[tx.FlowTransaction().ID()] = spending
+	return nil
+}
+
+// release removes the spending of a transaction that failed to be sent.
+func (b *budgetTracker) release(tx *flowkit.Transaction) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := tx.FlowTransaction().ID()
+	spending, ok := b.pending[id]
+	if !ok {
+		return
+	}
+	b.spent -= spending.fee + spending.transfer
+	b.sent--
+	delete(b.pending, id)
+}
+
+// settle replaces the estimated fee of the transaction with the fee deducted according to the result,
+// the estimate is kept if the result has no fee event.
+func (b *budgetTracker) settle(tx *flowkit.Transaction, result *flow.TransactionResult) {
+	fee, ok := deductedFee(result)
+	if !ok {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := tx.FlowTransaction().ID()
+	spending, ok := b.pending[id]
+	if !ok {
+		return
+	}
+	b.spent = b.spent - spending.fee + fee
+	delete(b.pending, id)
+}
+
+func (b *budgetTracker) state() BudgetState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return BudgetState{
+		Budget:       b.budget,
+		Spent:        b.spent,
+		Pending:      len(b.pending),
+		Transactions: b.sent,
+	}
+}
+
+// deductedFee returns the fee amount of the fee event of the result.
+func deductedFee(result *flow.TransactionResult) (cadence.UFix64, bool) {
+	if result == nil {
+		return 0, false
+	}
+
+	for _, event := range result.Events {
+		if !strings.HasSuffix(event.Type, ".FlowFees.FeesDeducted") || event.Value.EventType == nil {
+			continue
+		}
+		for i, field := range event.Value.EventType.Fields {
+			if i >= len(event.Value.Fields) || field.Identifier != "amount" {
+				continue
+			}
+			if amount, ok := event.Value.Fields[i].(cadence.UFix64); ok {
+				return amount, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// transactionOperation describes the transaction for budget errors.
+func transactionOperation(tx *flowkit.Transaction) string {
+	flowTx := tx.FlowTransaction()
+	return fmt.Sprintf("transaction proposed by 0x%s with key %d", flowTx.ProposalKey.Address, flowTx.ProposalKey.KeyIndex)
+}
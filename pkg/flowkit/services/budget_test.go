@@ -0,0 +1,208 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-cli/pkg/flowkit"
+	"github.com/onflow/flow-cli/pkg/flowkit/tests"
+)
+
+func TestBudget(t *testing.T) {
+	t.Parallel()
+
+	proposer := flow.HexToAddress("01")
+	newTx := func(n int) *flowkit.Transaction {
+		tx := flowkit.NewTransaction()
+		tx.FlowTransaction().
+			SetScript([]byte(fmt.Sprintf("transaction { execute { log(%d) } }", n))).
+			SetProposalKey(proposer, 0, uint64(n))
+		return tx
+	}
+
+	feeResult := func(amount cadence.UFix64) *flow.TransactionResult {
+		event := tests.NewEvent(
+			0,
+			"FlowFees.FeesDeducted",
+			[]cadence.Field{
+				{Identifier: "amount", Type: cadence.UFix64Type{}},
+				{Identifier: "inclusionEffort", Type: cadence.UFix64Type{}},
+				{Identifier: "executionEffort", Type: cadence.UFix64Type{}},
+			},
+			[]cadence.Value{amount, cadence.UFix64(100_000_000), cadence.UFix64(1_000)},
+		)
+		return tests.NewTransactionResult([]flow.Event{*event})
+	}
+
+	ufix := func(value string) cadence.UFix64 {
+		v, err := cadence.NewUFix64(value)
+		require.NoError(t, err)
+		return v
+	}
+
+	t.Run("No Budget", func(t *testing.T) {
+		_, s, gw := setup()
+
+		_, err := s.Sequences.sendWithTransfer(newTx(0), ufix("1000000.0"))
+		require.NoError(t, err)
+
+		_, ok := s.Sequences.BudgetState()
+		assert.False(t, ok)
+		gw.Mock.AssertNumberOfCalls(t, tests.SendSignedTransactionFunc, 1)
+	})
+
+	t.Run("Per Transaction Limit", func(t *testing.T) {
+		_, s, gw := setup()
+		s.SetBudget(&Budget{MaxPerTransaction: ufix("1.0")})
+
+		_, err := s.Sequences.send(newTx(0))
+		require.NoError(t, err)
+
+		_, err = s.Sequences.sendWithTransfer(newTx(1), ufix("1.0"))
+		require.True(t, errors.Is(err, ErrBudgetExceeded))
+
+		var exceeded *BudgetExceededError
+		require.ErrorAs(t, err, &exceeded)
+		assert.True(t, exceeded.PerTransaction)
+		assert.Equal(t, ufix("1.001"), exceeded.Cost)
+		assert.Equal(t, ufix("0.001"), exceeded.Spent)
+		assert.Equal(t, ufix("1.0"), exceeded.Limit)
+		assert.EqualError(t, err, fmt.Sprintf(
+			"budget exceeded: transaction proposed by 0x%s with key 0 would spend up to 1.00100000 FLOW "+
+				"with 0.00100000 FLOW spent so far, exceeding the limit per transaction of 1.00000000 FLOW",
+			proposer,
+		))
+
+		gw.Mock.AssertNumberOfCalls(t, tests.SendSignedTransactionFunc, 1)
+		state, ok := s.Sequences.BudgetState()
+		require.True(t, ok)
+		assert.Equal(t, ufix("0.001"), state.Spent)
+		assert.Equal(t, 1, state.Transactions)
+	})
+
+	t.Run("Total Limit", func(t *testing.T) {
+		_, s, gw := setup()
+		s.SetBudget(&Budget{MaxTotal: ufix("0.0025")})
+
+		for i := 0; i < 2; i++ {
+			_, err := s.Sequences.send(newTx(i))
+			require.NoError(t, err)
+		}
+
+		_, err := s.Sequences.send(newTx(2))
+		var exceeded *BudgetExceededError
+		require.ErrorAs(t, err, &exceeded)
+		assert.False(t, exceeded.PerTransaction)
+		assert.Equal(t, ufix("0.002"), exceeded.Spent)
+		assert.Contains(t, err.Error(), "exceeding the total limit of 0.00250000 FLOW")
+
+		gw.Mock.AssertNumberOfCalls(t, tests.SendSignedTransactionFunc, 2)
+		state, _ := s.Sequences.BudgetState()
+		remaining, limited := state.Remaining()
+		assert.True(t, limited)
+		assert.Equal(t, ufix("0.0005"), remaining)
+	})
+
+	t.Run("Failed Send Not Spent", func(t *testing.T) {
+		_, s, gw := setup()
+		s.SetBudget(&Budget{MaxTotal: ufix("0.001")})
+		gw.SendSignedTransaction.Run(nil).Return(nil, errors.New("unavailable")).Once()
+
+		_, err := s.Sequences.send(newTx(0))
+		assert.EqualError(t, err, "unavailable")
+
+		state, _ := s.Sequences.BudgetState()
+		assert.Equal(t, cadence.UFix64(0), state.Spent)
+		assert.Equal(t, 0, state.Transactions)
+	})
+
+	t.Run("Actual Fees Replace Estimates", func(t *testing.T) {
+		_, s, gw := setup()
+		s.SetBudget(&Budget{MaxTotal: ufix("0.0025")})
+
+		first := newTx(0)
+		sent, err := s.Sequences.send(first)
+		require.NoError(t, err)
+
+		state, _ := s.Sequences.BudgetState()
+		assert.Equal(t, ufix("0.001"), state.Spent)
+		assert.Equal(t, 1, state.Pending)
+
+		gw.GetTransactionResult.Return(feeResult(ufix("0.0001")), nil).Once()
+		_, err = s.Sequences.result(first, sent)
+		require.NoError(t, err)
+
+		state, _ = s.Sequences.BudgetState()
+		assert.Equal(t, ufix("0.0001"), state.Spent)
+		assert.Equal(t, 0, state.Pending)
+
+		// without the reconciliation the budget would only allow one more transaction
+		second, third := newTx(1), newTx(2)
+		sent, err = s.Sequences.send(second)
+		require.NoError(t, err)
+		_, err = s.Sequences.send(third)
+		require.NoError(t, err)
+
+		// a result without a fee event keeps the estimate
+		gw.GetTransactionResult.Return(tests.NewTransactionResult(nil), nil).Once()
+		_, err = s.Sequences.result(second, sent)
+		require.NoError(t, err)
+
+		state, _ = s.Sequences.BudgetState()
+		assert.Equal(t, ufix("0.0021"), state.Spent)
+		assert.Equal(t, 2, state.Pending)
+		assert.Equal(t, 3, state.Transactions)
+
+		_, err = s.Sequences.send(newTx(3))
+		assert.ErrorIs(t, err, ErrBudgetExceeded)
+	})
+
+	t.Run("Funding Transfer", func(t *testing.T) {
+		_, s, _ := setup()
+		s.SetBudget(&Budget{MaxTotal: ufix("10.0")})
+
+		_, err := s.Sequences.sendWithTransfer(newTx(0), ufix("9.0"))
+		require.NoError(t, err)
+
+		_, err = s.Sequences.sendWithTransfer(newTx(1), ufix("1.0"))
+		assert.ErrorIs(t, err, ErrBudgetExceeded)
+
+		state, _ := s.Sequences.BudgetState()
+		assert.Equal(t, ufix("9.001"), state.Spent)
+	})
+
+	t.Run("Remove Budget", func(t *testing.T) {
+		_, s, _ := setup()
+		s.SetBudget(&Budget{MaxPerTransaction: ufix("0.0001")})
+		s.SetBudget(nil)
+
+		_, err := s.Sequences.send(newTx(0))
+		require.NoError(t, err)
+		_, ok := s.Sequences.BudgetState()
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,103 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package services
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	"golang.org/x/exp/slices"
+)
+
+// capabilityTypesScript returns the type identifiers of the values the public capabilities of the account borrow.
+const capabilityTypesScript = `
+pub fun main(address: Address): [String] {
+	let account = getAccount(address)
+	let types: [String] = []
+
+	account.forEachPublic(fun (path: PublicPath, type: Type): Bool {
+		let capability = account.getCapability(path)
+		if let resource = capability.borrow<&AnyResource>() {
+			types.append(resource.getType().identifier)
+		} else if let value = capability.borrow<&AnyStruct>() {
+			types.append(value.getType().identifier)
+		}
+		return true
+	})
+
+	return types
+}
+`
+
+// contractTypePattern matches the contract in a type identifier of a type declared in a contract deployed to an address.
+var contractTypePattern = regexp.MustCompile(`A\.([0-9a-fA-F]{1,16})\.([A-Za-z_][A-Za-z0-9_]*)`)
+
+// EnabledContracts returns the contracts the account has capability access to, sorted and without duplicates.
+//
+// Each public capability of the account is borrowed and resolved to the contract declaring the type of the
+// borrowed value. Contracts are identified by their address and name as in Cadence type identifiers,
+// for example A.f8d6e0586b0a20c7.ExampleNFT. Capabilities that can't be borrowed or borrow a value of a type
+// not declared in a contract are skipped.
+func (a *Accounts) EnabledContracts(address flow.Address) (contracts []string, err error) {
+	err = a.diagnostics.run(a.tracing, "Accounts.EnabledContracts", []Attribute{
+		{Key: "address", Value: address.String()},
+	}, func(op *operation) error {
+		contracts, err = a.traced(op).enabledContracts(address)
+		return err
+	})
+	return contracts, err
+}
+
+func (a *Accounts) enabledContracts(address flow.Address) ([]string, error) {
+	value, err := a.gateway.ExecuteScript(
+		[]byte(capabilityTypesScript),
+		[]cadence.Value{cadence.NewAddress(address)},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	types, ok := value.(cadence.Array)
+	if !ok {
+		return nil, fmt.Errorf("unexpected capability types result %s", value)
+	}
+
+	contracts := make([]string, 0, len(types.Values))
+	for _, v := range types.Values {
+		identifier, ok := v.(cadence.String)
+		if !ok {
+			return nil, fmt.Errorf("unexpected capability type %s", v)
+		}
+
+		match := contractTypePattern.FindStringSubmatch(string(identifier))
+		if match == nil {
+			continue
+		}
+
+		contract := fmt.Sprintf("A.%s.%s", flow.HexToAddress(match[1]).Hex(), match[2])
+		if !slices.Contains(contracts, contract) {
+			contracts = append(contracts, contract)
+		}
+	}
+	slices.Sort(contracts)
+
+	return contracts, nil
+}
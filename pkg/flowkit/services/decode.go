@@ -0,0 +1,272 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/onflow/cadence"
+
+	"github.com/onflow/flow-cli/pkg/flowkit"
+)
+
+// ExecuteAndDecode executes the script on the network same as Scripts.Execute and decodes the result into T.
+//
+// Strings, characters and paths decode into strings, numbers into Go numbers or strings, addresses into
+// cadence.Address, flow.Address or strings, optionals into pointers, arrays into slices and dictionaries into maps.
+// Structs, resources, events and enums decode into Go structs, where a field is decoded from the Cadence field
+// named by its cadence tag, e.g. `cadence:"balance"`, or from the field with the same name ignoring case if untagged.
+func ExecuteAndDecode[T any](scripts *Scripts, script *flowkit.Script, network string) (T, error) {
+	var result T
+
+	value, err := scripts.Execute(script, network)
+	if err != nil {
+		return result, err
+	}
+
+	if err := decodeValue(value, &result); err != nil {
+		return result, fmt.Errorf("failed to decode script result: %w", err)
+	}
+
+	return result, nil
+}
+
+// decodeValue decodes the Cadence value into the Go value the target points to.
+//
+// The value is first mapped to a JSON compatible value shaped after the target type, which is then
+// round-tripped through encoding/json, so the Go side decoding follows the encoding/json rules.
+func decodeValue(value cadence.Value, target any) error {
+	targetType := reflect.TypeOf(target)
+	if targetType == nil || targetType.Kind() != reflect.Pointer {
+		return fmt.Errorf("decode target must be a pointer, got %T", target)
+	}
+
+	mapped, err := cadenceToJSON(value, targetType.Elem())
+	if err != nil {
+		return err
+	}
+
+	encoded, err := json.Marshal(mapped)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(encoded, target)
+}
+
+var (
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	addressType     = reflect.TypeOf(cadence.Address{})
+)
+
+// cadenceToJSON maps the Cadence value to a value encoding/json encodes in the form the target type decodes from.
+//
+// A nil target type is the empty interface, composites are then mapped to maps keyed by the Cadence field names.
+func cadenceToJSON(value cadence.Value, target reflect.Type) (any, error) {
+	for target != nil && target.Kind() == reflect.Pointer {
+		target = target.Elem()
+	}
+	if target != nil && target.Kind() == reflect.Interface {
+		target = nil
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case cadence.Optional:
+		if v.Value == nil {
+			return nil, nil
+		}
+		return cadenceToJSON(v.Value, target)
+	case cadence.Void:
+		return nil, nil
+	case cadence.Bool:
+		return bool(v), nil
+	case cadence.String:
+		return string(v), nil
+	case cadence.Character:
+		return string(v), nil
+	case cadence.Path:
+		return v.String(), nil
+	case cadence.Address:
+		if target == nil || target.Kind() == reflect.String || reflect.PointerTo(target).Implements(unmarshalerType) {
+			return v.String(), nil
+		}
+		if target != addressType {
+			return nil, fmt.Errorf("can't decode address %s into %s", v, target)
+		}
+		return v, nil
+	case cadence.NumberValue:
+		if target != nil && target.Kind() == reflect.String {
+			return v.String(), nil
+		}
+		return json.Number(v.String()), nil
+	case cadence.Array:
+		return arrayToJSON(v, target)
+	case cadence.Dictionary:
+		return dictionaryToJSON(v, target)
+	case cadence.Struct:
+		return compositeToJSON(v.StructType, v.Fields, target)
+	case cadence.Resource:
+		return compositeToJSON(v.ResourceType, v.Fields, target)
+	case cadence.Event:
+		return compositeToJSON(v.EventType, v.Fields, target)
+	case cadence.Enum:
+		return compositeToJSON(v.EnumType, v.Fields, target)
+	default:
+		return nil, fmt.Errorf("decoding %s values is not supported", value.Type().ID())
+	}
+}
+
+func arrayToJSON(array cadence.Array, target reflect.Type) (any, error) {
+	var elemType reflect.Type
+	if target != nil {
+		if target.Kind() != reflect.Slice && target.Kind() != reflect.Array {
+			return nil, fmt.Errorf("can't decode array into %s", target)
+		}
+		elemType = target.Elem()
+	}
+
+	elements := make([]any, len(array.Values))
+	for i, element := range array.Values {
+		mapped, err := cadenceToJSON(element, elemType)
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		elements[i] = mapped
+	}
+
+	return elements, nil
+}
+
+func dictionaryToJSON(dictionary cadence.Dictionary, target reflect.Type) (any, error) {
+	var elemType reflect.Type
+	if target != nil {
+		if target.Kind() != reflect.Map {
+			return nil, fmt.Errorf("can't decode dictionary into %s", target)
+		}
+		elemType = target.Elem()
+	}
+
+	pairs := make(map[string]any, len(dictionary.Pairs))
+	for _, pair := range dictionary.Pairs {
+		// JSON object keys are strings, encoding/json decodes them into string and integer map keys
+		key, err := cadenceToJSON(pair.Key, reflect.TypeOf(""))
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", pair.Key, err)
+		}
+		keyString, ok := key.(string)
+		if !ok {
+			return nil, fmt.Errorf("decoding %s dictionary keys is not supported", pair.Key.Type().ID())
+		}
+
+		mapped, err := cadenceToJSON(pair.Value, elemType)
+		if err != nil {
+			return nil, fmt.Errorf("value of key %s: %w", keyString, err)
+		}
+		pairs[keyString] = mapped
+	}
+
+	return pairs, nil
+}
+
+func compositeToJSON(compositeType cadence.CompositeType, fields []cadence.Value, target reflect.Type) (any, error) {
+	typeID := compositeType.CompositeTypeQualifiedIdentifier()
+	cadenceFields := compositeType.CompositeFields()
+	if len(cadenceFields) != len(fields) {
+		return nil, fmt.Errorf("fields of %s don't match its type", typeID)
+	}
+
+	if target == nil {
+		composite := make(map[string]any, len(fields))
+		for i, field := range cadenceFields {
+			mapped, err := cadenceToJSON(fields[i], nil)
+			if err != nil {
+				return nil, fmt.Errorf("field %s of %s: %w", field.Identifier, typeID, err)
+			}
+			composite[field.Identifier] = mapped
+		}
+		return composite, nil
+	}
+
+	if target.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("can't decode %s into %s", typeID, target)
+	}
+
+	composite := make(map[string]any)
+	for i := 0; i < target.NumField(); i++ {
+		goField := target.Field(i)
+		if !goField.IsExported() {
+			continue
+		}
+
+		jsonName, ok := jsonFieldName(goField)
+		if !ok {
+			continue
+		}
+
+		name, tagged := goField.Tag.Lookup("cadence")
+		if tagged {
+			name, _, _ = strings.Cut(name, ",")
+			if name == "-" {
+				continue
+			}
+		}
+		if name == "" {
+			name = goField.Name
+		}
+
+		index := -1
+		for j, field := range cadenceFields {
+			if field.Identifier == name || (!tagged && strings.EqualFold(field.Identifier, name)) {
+				index = j
+				break
+			}
+		}
+		if index < 0 {
+			if tagged {
+				return nil, fmt.Errorf("field %s not found in %s", name, typeID)
+			}
+			continue
+		}
+
+		mapped, err := cadenceToJSON(fields[index], goField.Type)
+		if err != nil {
+			return nil, fmt.Errorf("field %s of %s: %w", name, typeID, err)
+		}
+		composite[jsonName] = mapped
+	}
+
+	return composite, nil
+}
+
+// jsonFieldName returns the key encoding/json decodes the struct field from, false if the field is ignored.
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return "", false
+	}
+	if name == "" {
+		return field.Name, true
+	}
+	return name, true
+}
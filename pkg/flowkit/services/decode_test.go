@@ -0,0 +1,156 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package services
+
+import (
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-cli/pkg/flowkit"
+)
+
+func TestExecuteAndDecode_Integration(t *testing.T) {
+	t.Parallel()
+
+	_, s := setupIntegration()
+	script := func(code string) *flowkit.Script {
+		return flowkit.NewScript([]byte(code), nil, "")
+	}
+
+	t.Run("String", func(t *testing.T) {
+		result, err := ExecuteAndDecode[string](s.Scripts, script(`pub fun main(): String { return "Hello" }`), "")
+		require.NoError(t, err)
+		assert.Equal(t, "Hello", result)
+	})
+
+	t.Run("UInt64", func(t *testing.T) {
+		result, err := ExecuteAndDecode[uint64](
+			s.Scripts,
+			script(`pub fun main(): UInt64 { return 18446744073709551615 }`),
+			"",
+		)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(18446744073709551615), result)
+	})
+
+	t.Run("Bool", func(t *testing.T) {
+		result, err := ExecuteAndDecode[bool](s.Scripts, script(`pub fun main(): Bool { return true }`), "")
+		require.NoError(t, err)
+		assert.True(t, result)
+	})
+
+	t.Run("Address", func(t *testing.T) {
+		code := script(`pub fun main(): Address { return 0xf8d6e0586b0a20c7 }`)
+
+		address, err := ExecuteAndDecode[cadence.Address](s.Scripts, code, "")
+		require.NoError(t, err)
+		assert.Equal(t, cadence.BytesToAddress(flow.HexToAddress("f8d6e0586b0a20c7").Bytes()), address)
+
+		flowAddress, err := ExecuteAndDecode[flow.Address](s.Scripts, code, "")
+		require.NoError(t, err)
+		assert.Equal(t, flow.HexToAddress("f8d6e0586b0a20c7"), flowAddress)
+	})
+
+	t.Run("Struct", func(t *testing.T) {
+		type item struct {
+			Name  string `cadence:"name"`
+			Price string `cadence:"price"`
+		}
+		type listing struct {
+			Seller  cadence.Address   `cadence:"seller"`
+			Items   []item            `cadence:"items"`
+			Counts  map[string]uint64 `cadence:"counts"`
+			Note    *string           `cadence:"note"`
+			Missing *string           `cadence:"missing,omitempty"`
+			Active  bool
+		}
+
+		code := script(`
+			pub struct Item {
+				pub let name: String
+				pub let price: UFix64
+				init(name: String, price: UFix64) {
+					self.name = name
+					self.price = price
+				}
+			}
+
+			pub struct Listing {
+				pub let seller: Address
+				pub let items: [Item]
+				pub let counts: {String: UInt64}
+				pub let note: String?
+				pub let missing: String?
+				pub let active: Bool
+				init() {
+					self.seller = 0x01
+					self.items = [Item(name: "Kitty", price: 1.5)]
+					self.counts = {"Kitty": 2}
+					self.note = "on sale"
+					self.missing = nil
+					self.active = true
+				}
+			}
+
+			pub fun main(): Listing { return Listing() }
+		`)
+
+		result, err := ExecuteAndDecode[listing](s.Scripts, code, "")
+		require.NoError(t, err)
+
+		note := "on sale"
+		assert.Equal(t, listing{
+			Seller: cadence.BytesToAddress([]byte{1}),
+			Items:  []item{{Name: "Kitty", Price: "1.50000000"}},
+			Counts: map[string]uint64{"Kitty": 2},
+			Note:   &note,
+			Active: true,
+		}, result)
+	})
+
+	t.Run("Struct Missing Field", func(t *testing.T) {
+		type account struct {
+			Balance uint64 `cadence:"balance"`
+		}
+
+		code := script(`
+			pub struct Account {
+				pub let name: String
+				init() { self.name = "Alice" }
+			}
+
+			pub fun main(): Account { return Account() }
+		`)
+
+		_, err := ExecuteAndDecode[account](s.Scripts, code, "")
+		assert.ErrorContains(t, err, "field balance not found in Account")
+	})
+
+	t.Run("Type Mismatch", func(t *testing.T) {
+		_, err := ExecuteAndDecode[uint64](s.Scripts, script(`pub fun main(): String { return "Hello" }`), "")
+		assert.ErrorContains(t, err, "failed to decode script result")
+
+		_, err = ExecuteAndDecode[string](s.Scripts, script(`pub fun main(): [Int] { return [1] }`), "")
+		assert.ErrorContains(t, err, "can't decode array into string")
+	})
+}
@@ -0,0 +1,312 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/onflow/flow-go-sdk"
+
+	"github.com/onflow/flow-cli/pkg/flowkit"
+)
+
+// DiagnosticHistorySize is the number of the most recent service operations kept for the diagnostic bundle.
+const DiagnosticHistorySize = 50
+
+// InternalError is returned by a service operation that panicked, instead of the panic.
+//
+// It's a bug in flowkit or one of its dependencies, the error contains what is needed to report it.
+type InternalError struct {
+	// Operation is the name of the service operation, e.g. Accounts.Get.
+	Operation string
+	// Inputs are the sanitized inputs of the operation, they never contain keys or code.
+	Inputs []Attribute
+	// Value is the value the operation panicked with.
+	Value any
+	// Stack is the stack trace of the panic.
+	Stack string
+}
+
+func (i *InternalError) Error() string {
+	return fmt.Sprintf("internal error in %s: %v", i.Operation, i.Value)
+}
+
+// Unwrap returns the value the operation panicked with if it's an error.
+func (i *InternalError) Unwrap() error {
+	err, _ := i.Value.(error)
+	return err
+}
+
+// DiagnosticOperation is a service operation recorded in the diagnostic bundle.
+type DiagnosticOperation struct {
+	Name     string            `json:"name"`
+	Inputs   map[string]string `json:"inputs,omitempty"`
+	Started  time.Time         `json:"started"`
+	Duration string            `json:"duration"`
+	Error    string            `json:"error,omitempty"`
+	// Stack is only set if the operation panicked.
+	Stack string `json:"stack,omitempty"`
+}
+
+// DiagnosticBundle is written by Services.WriteDiagnosticBundle to be attached to bug reports.
+type DiagnosticBundle struct {
+	Created    time.Time             `json:"created"`
+	GoVersion  string                `json:"goVersion"`
+	Platform   string                `json:"platform"`
+	Operations []DiagnosticOperation `json:"operations"`
+	// Config is the effective project configuration with all the key material redacted.
+	Config json.RawMessage `json:"config,omitempty"`
+}
+
+// diagnostics records the recent service operations and recovers their panics.
+//
+// A nil diagnostics is valid and doesn't record nor recover anything.
+type diagnostics struct {
+	state      *flowkit.State
+	mu         sync.Mutex
+	recovery   bool
+	operations []DiagnosticOperation
+}
+
+func newDiagnostics(state *flowkit.State) *diagnostics {
+	return &diagnostics{
+		state:    state,
+		recovery: true,
+	}
+}
+
+func (d *diagnostics) setRecovery(enabled bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.recovery = enabled
+}
+
+// diagnosedOperation is a service operation in progress.
+type diagnosedOperation struct {
+	diagnostics *diagnostics
+	name        string
+	inputs      []Attribute
+	started     time.Time
+}
+
+// start a service operation with the sanitized inputs, the returned operation must be ended with a deferred call.
+func (d *diagnostics) start(name string, inputs ...Attribute) *diagnosedOperation {
+	if d == nil {
+		return nil
+	}
+
+	return &diagnosedOperation{
+		diagnostics: d,
+		name:        name,
+		inputs:      inputs,
+		started:     time.Now(),
+	}
+}
+
+// end records the operation with the error it returns.
+//
+// If recovery is enabled a panic of the operation is recovered and returned as an InternalError instead,
+// end has to be deferred directly by the operation for the recovery to work.
+func (o *diagnosedOperation) end(err *error) {
+	if o == nil {
+		return
+	}
+
+	var stack string
+	if o.diagnostics.recoveryEnabled() {
+		if value := recover(); value != nil {
+			stack = string(debug.Stack())
+			*err = &InternalError{
+				Operation: o.name,
+				Inputs:    o.inputs,
+				Value:     value,
+				Stack:     stack,
+			}
+		}
+	}
+
+	operation := DiagnosticOperation{
+		Name:     o.name,
+		Started:  o.started,
+		Duration: time.Since(o.started).String(),
+		Stack:    stack,
+	}
+	if len(o.inputs) > 0 {
+		operation.Inputs = make(map[string]string, len(o.inputs))
+		for _, input := range o.inputs {
+			operation.Inputs[input.Key] = input.Value
+		}
+	}
+	if *err != nil {
+		operation.Error = (*err).Error()
+	}
+
+	o.diagnostics.record(operation)
+}
+
+// run a service operation with the sanitized inputs, recording it in the diagnostics and tracing it in a span
+// with the inputs as attributes. The calls of the operation are traced with the provided operation.
+//
+// If recovery is enabled a panic of the operation is recovered and returned as an InternalError.
+func (d *diagnostics) run(t *tracing, name string, inputs []Attribute, operate func(op *operation) error) (err error) {
+	defer d.start(name, inputs...).end(&err)
+
+	op := t.start(name, inputs...)
+	err = operate(op)
+	op.end(err)
+	return err
+}
+
+func (d *diagnostics) recoveryEnabled() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.recovery
+}
+
+func (d *diagnostics) record(operation DiagnosticOperation) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.operations = append(d.operations, operation)
+	if len(d.operations) > DiagnosticHistorySize {
+		d.operations = append([]DiagnosticOperation(nil), d.operations[len(d.operations)-DiagnosticHistorySize:]...)
+	}
+}
+
+func (d *diagnostics) bundle() (*DiagnosticBundle, error) {
+	d.mu.Lock()
+	operations := append([]DiagnosticOperation{}, d.operations...)
+	d.mu.Unlock()
+
+	bundle := &DiagnosticBundle{
+		Created:    time.Now(),
+		GoVersion:  runtime.Version(),
+		Platform:   fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+		Operations: operations,
+	}
+
+	if d.state != nil {
+		conf, err := redactedConfig(d.state)
+		if err != nil {
+			return nil, fmt.Errorf("failed to redact the configuration: %w", err)
+		}
+		bundle.Config = conf
+	}
+
+	return bundle, nil
+}
+
+// redactKeyFields are the fields of the advanced account key format holding key material.
+var redactKeyFields = []string{"privateKey", "mnemonic"}
+
+// redactedConfig returns the configuration of the state in the JSON format, with the key material of all accounts redacted.
+func redactedConfig(state *flowkit.State) (json.RawMessage, error) {
+	var buf bytes.Buffer
+	if err := state.Serialize(&buf); err != nil {
+		return nil, err
+	}
+
+	var conf map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &conf); err != nil {
+		return nil, err
+	}
+
+	accounts, _ := conf["accounts"].(map[string]any)
+	for _, account := range accounts {
+		account, ok := account.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		switch key := account["key"].(type) {
+		case string:
+			account["key"] = flowkit.RedactedArgument
+		case map[string]any:
+			for _, field := range redactKeyFields {
+				if _, ok := key[field]; ok {
+					key[field] = flowkit.RedactedArgument
+				}
+			}
+		}
+	}
+
+	return json.Marshal(conf)
+}
+
+// SetPanicRecovery enables or disables recovering the panics of the service operations, it's enabled by default.
+//
+// With recovery a panic is returned as an InternalError by the operation, tests relying on the panic can disable it.
+func (s *Services) SetPanicRecovery(enabled bool) {
+	s.diagnostics.setRecovery(enabled)
+}
+
+// WriteDiagnosticBundle writes the JSON diagnostic bundle for a bug report to the writer.
+//
+// The bundle contains the last DiagnosticHistorySize service operations with their sanitized inputs, durations,
+// errors and stack traces of the panics, and the effective configuration with all the key material redacted.
+func (s *Services) WriteDiagnosticBundle(w io.Writer) error {
+	if s.diagnostics == nil {
+		return errors.New("diagnostics are not recorded by the services")
+	}
+
+	bundle, err := s.diagnostics.bundle()
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "\t")
+	return encoder.Encode(bundle)
+}
+
+// accountInput returns the sanitized input of the account, only its address.
+func accountInput(key string, account *flowkit.Account) Attribute {
+	if account == nil {
+		return Attribute{Key: key, Value: ""}
+	}
+	return Attribute{Key: key, Value: account.Address().String()}
+}
+
+// scriptInput returns the sanitized input of the script, only its location and never its code.
+func scriptInput(key string, script *flowkit.Script) Attribute {
+	if script == nil {
+		return Attribute{Key: key, Value: ""}
+	}
+	return Attribute{Key: key, Value: script.Location()}
+}
+
+// addressesInput returns the input of the addresses joined by commas.
+func addressesInput(key string, addresses []flow.Address) Attribute {
+	value := ""
+	for i, address := range addresses {
+		if i > 0 {
+			value += ","
+		}
+		value += address.String()
+	}
+	return Attribute{Key: key, Value: value}
+}
@@ -0,0 +1,153 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-cli/pkg/flowkit"
+	"github.com/onflow/flow-cli/pkg/flowkit/tests"
+)
+
+func TestDiagnostics(t *testing.T) {
+	address := flow.HexToAddress("01cf0e2f2f715450")
+
+	t.Run("Panic Recovered", func(t *testing.T) {
+		_, s, gw := setup()
+		gw.GetAccount.Run(func(args mock.Arguments) {
+			panic("gateway exploded")
+		})
+
+		account, err := s.Accounts.Get(address)
+
+		assert.Nil(t, account)
+		var internalErr *InternalError
+		require.ErrorAs(t, err, &internalErr)
+		assert.EqualError(t, err, "internal error in Accounts.Get: gateway exploded")
+		assert.Equal(t, "Accounts.Get", internalErr.Operation)
+		assert.Equal(t, []Attribute{{Key: "address", Value: address.String()}}, internalErr.Inputs)
+		assert.Contains(t, internalErr.Stack, "panic")
+		assert.Contains(t, internalErr.Stack, "TestDiagnostics")
+	})
+
+	t.Run("Panic Error Unwrapped", func(t *testing.T) {
+		_, s, gw := setup()
+		gw.GetAccount.Run(func(args mock.Arguments) {
+			panic(flowkit.ErrInvalidPassphrase)
+		})
+
+		_, err := s.Accounts.Get(address)
+		assert.ErrorIs(t, err, flowkit.ErrInvalidPassphrase)
+	})
+
+	t.Run("Recovery Disabled", func(t *testing.T) {
+		_, s, gw := setup()
+		s.SetPanicRecovery(false)
+		gw.GetAccount.Run(func(args mock.Arguments) {
+			panic("gateway exploded")
+		})
+
+		assert.PanicsWithValue(t, "gateway exploded", func() {
+			_, _ = s.Accounts.Get(address)
+		})
+	})
+
+	t.Run("Bundle", func(t *testing.T) {
+		state, s, gw := setup()
+
+		privateKey := tests.PrivKeys()[0]
+		state.Accounts().AddOrUpdate(
+			flowkit.NewAccount("secret").
+				SetAddress(address).
+				SetKey(flowkit.NewHexAccountKeyFromPrivateKey(0, crypto.SHA3_256, privateKey)),
+		)
+
+		_, err := s.Accounts.Get(flow.HexToAddress("f8d6e0586b0a20c7"))
+		require.NoError(t, err)
+
+		gw.GetAccount.Run(func(args mock.Arguments) {
+			panic("gateway exploded")
+		})
+		_, err = s.Accounts.Get(address)
+		require.Error(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, s.WriteDiagnosticBundle(&buf))
+		raw := buf.String()
+
+		var bundle DiagnosticBundle
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &bundle))
+
+		require.Len(t, bundle.Operations, 2)
+		assert.Equal(t, "Accounts.Get", bundle.Operations[0].Name)
+		assert.Equal(t, map[string]string{"address": "f8d6e0586b0a20c7"}, bundle.Operations[0].Inputs)
+		assert.Empty(t, bundle.Operations[0].Error)
+		assert.Empty(t, bundle.Operations[0].Stack)
+		assert.NotEmpty(t, bundle.Operations[0].Duration)
+		assert.Equal(t, "internal error in Accounts.Get: gateway exploded", bundle.Operations[1].Error)
+		assert.Contains(t, bundle.Operations[1].Stack, "panic")
+
+		assert.Contains(t, string(bundle.Config), `"secret"`)
+		assert.Contains(t, string(bundle.Config), flowkit.RedactedArgument)
+		assert.NotContains(t, raw, strings.TrimPrefix(privateKey.String(), "0x"))
+		serviceAccount, err := state.EmulatorServiceAccount()
+		require.NoError(t, err)
+		serviceKey, err := serviceAccount.Key().PrivateKey()
+		require.NoError(t, err)
+		assert.NotContains(t, raw, strings.TrimPrefix((*serviceKey).String(), "0x"))
+	})
+
+	t.Run("Variant Recorded Once", func(t *testing.T) {
+		_, s, _ := setup()
+
+		_, _ = s.Accounts.ReadContractField(address, "Foo", "bar")
+
+		var buf bytes.Buffer
+		require.NoError(t, s.WriteDiagnosticBundle(&buf))
+
+		var bundle DiagnosticBundle
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &bundle))
+		require.Len(t, bundle.Operations, 1)
+		assert.Equal(t, "Accounts.ReadContractField", bundle.Operations[0].Name)
+	})
+
+	t.Run("Bundle History Limit", func(t *testing.T) {
+		_, s, _ := setup()
+
+		for i := 0; i < DiagnosticHistorySize+5; i++ {
+			_, _ = s.Accounts.Get(address)
+		}
+
+		var buf bytes.Buffer
+		require.NoError(t, s.WriteDiagnosticBundle(&buf))
+
+		var bundle DiagnosticBundle
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &bundle))
+		assert.Len(t, bundle.Operations, DiagnosticHistorySize)
+	})
+}
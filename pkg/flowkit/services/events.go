@@ -19,8 +19,14 @@
 package services
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/onflow/flow-go-sdk"
 	"github.com/onflow/flow-go-sdk/access/grpc"
@@ -32,9 +38,10 @@ import (
 
 // Events is a service that handles all event-related interactions.
 type Events struct {
-	gateway gateway.Gateway
-	state   *flowkit.State
-	logger  output.Logger
+	gateway     gateway.Gateway
+	state       *flowkit.State
+	logger      output.Logger
+	diagnostics *diagnostics
 }
 
 // NewEvents returns a new events service.
@@ -71,7 +78,19 @@ func makeEventQueries(events []string, startHeight uint64, endHeight uint64, blo
 
 }
 
-func (e *Events) Get(events []string, startHeight uint64, endHeight uint64, blockCount uint64, workerCount int) ([]flow.BlockEvents, error) {
+func (e *Events) Get(events []string, startHeight uint64, endHeight uint64, blockCount uint64, workerCount int) (blockEvents []flow.BlockEvents, err error) {
+	err = e.diagnostics.run(nil, "Events.Get", []Attribute{
+		{Key: "events", Value: strings.Join(events, ",")},
+		{Key: "startHeight", Value: fmt.Sprint(startHeight)},
+		{Key: "endHeight", Value: fmt.Sprint(endHeight)},
+	}, func(*operation) error {
+		blockEvents, err = e.get(events, startHeight, endHeight, blockCount, workerCount)
+		return err
+	})
+	return blockEvents, err
+}
+
+func (e *Events) get(events []string, startHeight uint64, endHeight uint64, blockCount uint64, workerCount int) ([]flow.BlockEvents, error) {
 	if endHeight < startHeight {
 		return nil, fmt.Errorf("cannot have end height (%d) of block range less that start height (%d)", endHeight, startHeight)
 	}
@@ -108,15 +127,33 @@ func (e *Events) Get(events []string, startHeight uint64, endHeight uint64, bloc
 		}
 	}()
 
+	steps := e.logger.StartProgressSteps("Fetching events", len(queries))
+	defer steps.Complete()
+
+	labels := make([]string, len(queries))
+	indexes := make(map[grpc.EventRangeQuery]int, len(queries))
+	for i, query := range queries {
+		labels[i] = fmt.Sprintf("%s from %d to %d", query.Type, query.StartHeight, query.EndHeight)
+		indexes[query] = i
+	}
+	batchErr := flowkit.NewBatchError(labels...)
+
+	// all the results are consumed even after a failure, so the workers don't block and all failed queries are reported
 	var resultEvents []flow.BlockEvents
 	for eventResult := range results {
 		if eventResult.Error != nil {
-			return nil, eventResult.Error
+			batchErr.Fail(indexes[eventResult.Query], eventResult.Error)
+			continue
 		}
 
+		steps.Increment(fmt.Sprintf("Fetched events from %d blocks", len(eventResult.Events)))
 		resultEvents = append(resultEvents, eventResult.Events...)
 	}
 
+	if err := batchErr.Err(); err != nil {
+		return nil, err
+	}
+
 	return resultEvents, nil
 
 }
@@ -126,13 +163,251 @@ func (e *Events) eventWorker(jobChan <-chan grpc.EventRangeQuery, results chan<-
 		//e.logger.Debug(fmt.Sprintf("Fetching events %v", q))
 		blockEvents, err := e.gateway.GetEvents(q.Type, q.StartHeight, q.EndHeight)
 		if err != nil {
-			results <- EventWorkerResult{nil, err}
+			results <- EventWorkerResult{Error: err, Query: q}
+			continue
 		}
-		results <- EventWorkerResult{blockEvents, nil}
+		results <- EventWorkerResult{Events: blockEvents, Query: q}
 	}
 }
 
 type EventWorkerResult struct {
 	Events []flow.BlockEvents
 	Error  error
+	Query  grpc.EventRangeQuery
+}
+
+// BlockEvents contains the decoded events of a single block.
+type BlockEvents struct {
+	Height  uint64
+	BlockID flow.Identifier
+	Events  flowkit.Events
+}
+
+// TailCheckpoint persists the height of the last handled block, so tailing can be resumed after a restart.
+type TailCheckpoint interface {
+	// Load returns the last handled height and whether a checkpoint exists.
+	Load() (uint64, bool, error)
+	// Save stores the last handled height.
+	Save(height uint64) error
+}
+
+var _ TailCheckpoint = &FileTailCheckpoint{}
+
+// FileTailCheckpoint is a tail checkpoint stored in a file.
+type FileTailCheckpoint struct {
+	readerWriter flowkit.ReaderWriter
+	path         string
+}
+
+// NewFileTailCheckpoint returns a new checkpoint that persists the height to the file at path.
+func NewFileTailCheckpoint(readerWriter flowkit.ReaderWriter, path string) *FileTailCheckpoint {
+	return &FileTailCheckpoint{
+		readerWriter: readerWriter,
+		path:         path,
+	}
+}
+
+func (f *FileTailCheckpoint) Load() (uint64, bool, error) {
+	data, err := f.readerWriter.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	height, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid checkpoint in %s: %w", f.path, err)
+	}
+
+	return height, true, nil
+}
+
+func (f *FileTailCheckpoint) Save(height uint64) error {
+	return f.readerWriter.WriteFile(f.path, []byte(strconv.FormatUint(height, 10)), 0644)
+}
+
+const tailBlockCount = 250
+
+var (
+	tailPollInterval = time.Second
+	tailMinBackoff   = time.Second
+	tailMaxBackoff   = 30 * time.Second
+)
+
+// Tail follows the chain from the provided height and invokes the handler once for each new sealed block,
+// in order, with the decoded events of the provided types.
+//
+// The handler is also invoked for blocks without any matching events, so consumers can track progress.
+// Tailing runs until the context is cancelled or the handler returns an error.
+func (e *Events) Tail(
+	ctx context.Context,
+	types []string,
+	fromHeight uint64,
+	handler func(BlockEvents) error,
+) error {
+	return e.diagnostics.run(nil, "Events.Tail", []Attribute{
+		{Key: "events", Value: strings.Join(types, ",")},
+		{Key: "fromHeight", Value: fmt.Sprint(fromHeight)},
+	}, func(*operation) error {
+		return e.tailWithCheckpoint(ctx, types, fromHeight, nil, handler)
+	})
+}
+
+// TailWithCheckpoint is the same as Tail, but it persists the last handled height using the checkpoint.
+//
+// If the checkpoint contains a height, tailing resumes from the block after it.
+// Errors fetching data from the gateway are retried with backoff without skipping any heights.
+func (e *Events) TailWithCheckpoint(
+	ctx context.Context,
+	types []string,
+	fromHeight uint64,
+	checkpoint TailCheckpoint,
+	handler func(BlockEvents) error,
+) error {
+	return e.diagnostics.run(nil, "Events.TailWithCheckpoint", []Attribute{
+		{Key: "events", Value: strings.Join(types, ",")},
+		{Key: "fromHeight", Value: fmt.Sprint(fromHeight)},
+	}, func(*operation) error {
+		return e.tailWithCheckpoint(ctx, types, fromHeight, checkpoint, handler)
+	})
+}
+
+func (e *Events) tailWithCheckpoint(
+	ctx context.Context,
+	types []string,
+	fromHeight uint64,
+	checkpoint TailCheckpoint,
+	handler func(BlockEvents) error,
+) error {
+	next := fromHeight
+	if checkpoint != nil {
+		height, ok, err := checkpoint.Load()
+		if err != nil {
+			return err
+		}
+		if ok && height+1 > next {
+			next = height + 1
+		}
+	}
+
+	backoff := time.Duration(0)
+	retry := func(err error) error {
+		if backoff == 0 {
+			backoff = tailMinBackoff
+		} else if backoff*2 <= tailMaxBackoff {
+			backoff *= 2
+		}
+		e.logger.Debug(fmt.Sprintf("tailing events failed, retrying in %s: %s", backoff, err))
+		return sleepContext(ctx, backoff)
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		latest, err := e.gateway.GetLatestBlock()
+		if err != nil {
+			if err := retry(err); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if latest.Height < next {
+			if err := sleepContext(ctx, tailPollInterval); err != nil {
+				return err
+			}
+			continue
+		}
+
+		end := latest.Height
+		if end-next >= tailBlockCount {
+			end = next + tailBlockCount - 1
+		}
+
+		blocks, err := e.blockEvents(types, next, end)
+		if err != nil {
+			if err := retry(err); err != nil {
+				return err
+			}
+			continue
+		}
+		backoff = 0
+
+		for _, block := range blocks {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			if err := handler(block); err != nil {
+				return err
+			}
+
+			if checkpoint != nil {
+				if err := checkpoint.Save(block.Height); err != nil {
+					return err
+				}
+			}
+			next = block.Height + 1
+		}
+	}
+}
+
+// blockEvents returns the decoded events for each block in the height range, ordered by height.
+func (e *Events) blockEvents(types []string, startHeight uint64, endHeight uint64) ([]BlockEvents, error) {
+	blocks := make([]BlockEvents, endHeight-startHeight+1)
+	found := make([]bool, len(blocks))
+
+	for _, q := range makeEventQueries(types, startHeight, endHeight, tailBlockCount) {
+		blockEvents, err := e.gateway.GetEvents(q.Type, q.StartHeight, q.EndHeight)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, b := range blockEvents {
+			if b.Height < startHeight || b.Height > endHeight {
+				continue
+			}
+			i := b.Height - startHeight
+			blocks[i].Height = b.Height
+			blocks[i].BlockID = b.BlockID
+			blocks[i].Events = append(blocks[i].Events, flowkit.NewEvents(b.Events)...)
+			found[i] = true
+		}
+	}
+
+	// blocks without any events of the requested types still need to be reported
+	for i := range blocks {
+		if found[i] {
+			continue
+		}
+
+		height := startHeight + uint64(i)
+		block, err := e.gateway.GetBlockByHeight(height)
+		if err != nil {
+			return nil, err
+		}
+		blocks[i] = BlockEvents{
+			Height:  height,
+			BlockID: block.ID,
+			Events:  flowkit.Events{},
+		}
+	}
+
+	return blocks, nil
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
 }
@@ -19,14 +19,18 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/onflow/flow-go-sdk"
 	"github.com/onflow/flow-go-sdk/access/grpc"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/onflow/flow-cli/pkg/flowkit"
 	"github.com/onflow/flow-cli/pkg/flowkit/tests"
 )
 
@@ -73,9 +77,156 @@ func TestEvents(t *testing.T) {
 
 		_, err := s.Events.Get([]string{"flow.CreateAccount"}, 0, 1, 250, 1)
 
-		assert.EqualError(t, err, "failed getting event")
+		assert.EqualError(t, err, "1 of 1 items failed:\n  flow.CreateAccount from 0 to 1: failed getting event")
 	})
 
+	t.Run("Should report all failed queries", func(t *testing.T) {
+		t.Parallel()
+
+		_, s, gw := setup()
+
+		errNotIndexed := errors.New("blocks not indexed")
+		gw.GetEvents.Return(
+			func(eventType string, start uint64, end uint64) []flow.BlockEvents {
+				return []flow.BlockEvents{{Height: start}}
+			},
+			func(eventType string, start uint64, end uint64) error {
+				if eventType == "flow.AccountKeyAdded" {
+					return fmt.Errorf("failed getting %s: %w", eventType, errNotIndexed)
+				}
+				return nil
+			},
+		)
+
+		events, err := s.Events.Get([]string{"flow.CreateAccount", "flow.AccountKeyAdded"}, 0, 400, 250, 3)
+		assert.Nil(t, events)
+		assert.ErrorIs(t, err, errNotIndexed)
+
+		var batchErr *flowkit.BatchError
+		require.ErrorAs(t, err, &batchErr)
+		assert.Equal(t, 2, batchErr.Failed())
+		assert.Equal(t, 2, batchErr.Succeeded())
+		assert.NoError(t, batchErr.ErrorAt(0))
+		assert.EqualError(t, batchErr.ErrorAt(1), "failed getting flow.AccountKeyAdded: blocks not indexed")
+		assert.Equal(t, "flow.AccountKeyAdded from 250 to 400", batchErr.Label(3))
+	})
+
+}
+
+func TestEventsTail(t *testing.T) {
+	tailPollInterval = time.Millisecond
+	tailMinBackoff = time.Millisecond
+	tailMaxBackoff = 5 * time.Millisecond
+
+	// setupTail returns services with a gateway where the latest sealed height advances by one on each request.
+	setupTail := func() (*Services, *tests.TestGateway) {
+		_, s, gw := setup()
+
+		height := uint64(0)
+		gw.GetLatestBlock.Return(func() *flow.Block {
+			block := tests.NewBlock()
+			block.Height = height
+			height++
+			return block
+		}, nil)
+
+		gw.GetEvents.Return(func(eventType string, start uint64, end uint64) []flow.BlockEvents {
+			events := make([]flow.BlockEvents, 0)
+			for h := start; h <= end; h++ {
+				events = append(events, flow.BlockEvents{
+					BlockID: flow.Identifier{byte(h)},
+					Height:  h,
+				})
+			}
+			return events
+		}, nil)
+
+		return s, gw
+	}
+
+	t.Run("Tail until handler error", func(t *testing.T) {
+		s, _ := setupTail()
+
+		handlerErr := errors.New("stop")
+		heights := make([]uint64, 0)
+		err := s.Events.Tail(context.Background(), []string{"flow.AccountCreated"}, 2, func(b BlockEvents) error {
+			heights = append(heights, b.Height)
+			assert.Equal(t, flow.Identifier{byte(b.Height)}, b.BlockID)
+			if b.Height == 6 {
+				return handlerErr
+			}
+			return nil
+		})
+
+		assert.ErrorIs(t, err, handlerErr)
+		assert.Equal(t, []uint64{2, 3, 4, 5, 6}, heights)
+	})
+
+	t.Run("Tail retries gateway errors", func(t *testing.T) {
+		s, gw := setupTail()
+
+		fails := 2
+		gw.GetEvents.Return(func(eventType string, start uint64, end uint64) []flow.BlockEvents {
+			events := make([]flow.BlockEvents, 0)
+			for h := start; h <= end; h++ {
+				events = append(events, flow.BlockEvents{Height: h})
+			}
+			return events
+		}, func(eventType string, start uint64, end uint64) error {
+			if fails > 0 {
+				fails--
+				return errors.New("unavailable")
+			}
+			return nil
+		})
+
+		heights := make([]uint64, 0)
+		ctx, cancel := context.WithCancel(context.Background())
+		err := s.Events.Tail(ctx, []string{"flow.AccountCreated"}, 0, func(b BlockEvents) error {
+			heights = append(heights, b.Height)
+			if b.Height == 3 {
+				cancel()
+			}
+			return nil
+		})
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 0, fails)
+		assert.Equal(t, []uint64{0, 1, 2, 3}, heights)
+	})
+
+	t.Run("Tail resumes from checkpoint", func(t *testing.T) {
+		s, _ := setupTail()
+		rw, _ := tests.ReaderWriter()
+		checkpoint := NewFileTailCheckpoint(rw, "tail.checkpoint")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		err := s.Events.TailWithCheckpoint(ctx, []string{"flow.AccountCreated"}, 0, checkpoint, func(b BlockEvents) error {
+			if b.Height == 4 {
+				cancel()
+			}
+			return nil
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+
+		height, ok, err := checkpoint.Load()
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, uint64(4), height)
+
+		s, _ = setupTail()
+		heights := make([]uint64, 0)
+		ctx, cancel = context.WithCancel(context.Background())
+		err = s.Events.TailWithCheckpoint(ctx, []string{"flow.AccountCreated"}, 0, checkpoint, func(b BlockEvents) error {
+			heights = append(heights, b.Height)
+			if b.Height == 7 {
+				cancel()
+			}
+			return nil
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, []uint64{5, 6, 7}, heights)
+	})
 }
 
 func TestEvents_Integration(t *testing.T) {
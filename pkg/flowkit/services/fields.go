@@ -0,0 +1,150 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+)
+
+// maxFieldPathDepth limits field paths to a contract field and one field of a nested struct.
+const maxFieldPathDepth = 2
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ContractFieldError is returned when a contract has no public field matching the field path.
+type ContractFieldError struct {
+	Contract string
+	Field    string
+}
+
+func (c *ContractFieldError) Error() string {
+	return fmt.Sprintf("contract %s has no public field '%s'", c.Contract, c.Field)
+}
+
+// ReadContractField returns the value of a public field of the contract deployed to the address.
+//
+// The field path is a contract field name, optionally followed by a field of the nested public struct
+// separated by a dot, e.g. "fee" or "info.version".
+func (a *Accounts) ReadContractField(address flow.Address, contractName string, fieldPath string) (value cadence.Value, err error) {
+	err = a.diagnostics.run(a.tracing, "Accounts.ReadContractField", []Attribute{
+		{Key: "address", Value: address.String()},
+		{Key: "contract", Value: contractName},
+		{Key: "field", Value: fieldPath},
+	}, func(op *operation) error {
+		value, err = a.traced(op).readContractField(address, contractName, fieldPath)
+		return err
+	})
+	return value, err
+}
+
+func (a *Accounts) readContractField(address flow.Address, contractName string, fieldPath string) (cadence.Value, error) {
+	values, err := a.readContractFields(address, contractName, []string{fieldPath})
+	if err != nil {
+		return nil, err
+	}
+
+	return values[0], nil
+}
+
+// ReadContractFields returns the values of multiple public fields of the contract deployed to the address,
+// in the same order as the field paths. All the fields are read by a single script.
+func (a *Accounts) ReadContractFields(address flow.Address, contractName string, fieldPaths []string) (values []cadence.Value, err error) {
+	err = a.diagnostics.run(a.tracing, "Accounts.ReadContractFields", []Attribute{
+		{Key: "address", Value: address.String()},
+		{Key: "contract", Value: contractName},
+		{Key: "fields", Value: strings.Join(fieldPaths, ",")},
+	}, func(op *operation) error {
+		values, err = a.traced(op).readContractFields(address, contractName, fieldPaths)
+		return err
+	})
+	return values, err
+}
+
+func (a *Accounts) readContractFields(address flow.Address, contractName string, fieldPaths []string) ([]cadence.Value, error) {
+	if len(fieldPaths) == 0 {
+		return nil, fmt.Errorf("at least one field path is required")
+	}
+
+	script, err := contractFieldsScript(address, contractName, fieldPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := a.gateway.ExecuteScript(script, nil)
+	if err != nil {
+		return nil, contractFieldError(err, contractName, fieldPaths)
+	}
+
+	fields, ok := value.(cadence.Array)
+	if !ok || len(fields.Values) != len(fieldPaths) {
+		return nil, fmt.Errorf("unexpected contract fields result %s", value)
+	}
+
+	return fields.Values, nil
+}
+
+// contractFieldsScript generates a script importing the contract from the address and returning the fields in an array.
+func contractFieldsScript(address flow.Address, contractName string, fieldPaths []string) ([]byte, error) {
+	if !identifierPattern.MatchString(contractName) {
+		return nil, fmt.Errorf("invalid contract name '%s'", contractName)
+	}
+
+	fields := make([]string, len(fieldPaths))
+	for i, fieldPath := range fieldPaths {
+		segments := strings.Split(fieldPath, ".")
+		if len(segments) > maxFieldPathDepth {
+			return nil, fmt.Errorf("field path '%s' is too deep, only fields of nested structs one level deep are supported", fieldPath)
+		}
+		for _, segment := range segments {
+			if !identifierPattern.MatchString(segment) {
+				return nil, fmt.Errorf("invalid field path '%s'", fieldPath)
+			}
+		}
+
+		fields[i] = fmt.Sprintf("%s.%s", contractName, fieldPath)
+	}
+
+	return []byte(fmt.Sprintf(
+		"import %s from 0x%s\n\npub fun main(): [AnyStruct] {\n\treturn [%s]\n}\n",
+		contractName,
+		address,
+		strings.Join(fields, ", "),
+	)), nil
+}
+
+// contractFieldError maps the checker error about a missing or inaccessible member to the field path it belongs to,
+// other errors are returned unchanged.
+func contractFieldError(err error, contractName string, fieldPaths []string) error {
+	message := err.Error()
+	for _, fieldPath := range fieldPaths {
+		for _, segment := range strings.Split(fieldPath, ".") {
+			if strings.Contains(message, fmt.Sprintf("has no member `%s`", segment)) ||
+				strings.Contains(message, fmt.Sprintf("cannot access `%s`", segment)) {
+				return &ContractFieldError{Contract: contractName, Field: fieldPath}
+			}
+		}
+	}
+
+	return err
+}
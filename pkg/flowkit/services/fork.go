@@ -0,0 +1,133 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	emulator "github.com/onflow/flow-emulator"
+	"github.com/onflow/flow-go-sdk"
+	flowGo "github.com/onflow/flow-go/model/flow"
+
+	"github.com/onflow/flow-cli/pkg/flowkit"
+	"github.com/onflow/flow-cli/pkg/flowkit/config"
+	"github.com/onflow/flow-cli/pkg/flowkit/gateway"
+)
+
+// ForkOptions configures the deployment of the project against a fork of a network.
+type ForkOptions struct {
+	// Height is the block height the state of the network is forked at, the latest sealed block is used if zero.
+	Height uint64
+	// Update updates the contracts that already exist on the network.
+	Update bool
+	// Deploy configures the deployment of the project contracts, accounts can't be created on a fork.
+	Deploy DeployOptions
+}
+
+// ForkDeployResult describes the deployment of the project against a fork of a network.
+type ForkDeployResult struct {
+	Network string
+	// Height is the block height the state of the network was forked at.
+	Height uint64
+	// Contracts contains the results of the contracts in deployment order.
+	Contracts []ContractResult
+	// CadenceErrors contains the Cadence errors of the failed contracts by contract name.
+	CadenceErrors map[string]*flowkit.CadenceRuntimeError
+}
+
+// DeployAgainstFork rehearses the deployment of the project to the network on a local fork of its state.
+//
+// The project gateway must be connected to the network, its accounts are read at the pinned height
+// the first time the deployment accesses them while the changes stay on an in-process emulator,
+// see gateway.NewForkedEmulatorGateway for what is forked. The deployment isn't recorded in the
+// lock file and the result is returned together with the deployment error if contracts failed.
+func (p *Project) DeployAgainstFork(sourceNetwork string, opts ForkOptions) (result *ForkDeployResult, err error) {
+	err = p.diagnostics.run(p.tracing, "Project.DeployAgainstFork", []Attribute{
+		{Key: "network", Value: sourceNetwork},
+	}, func(op *operation) error {
+		result, err = p.traced(op).deployAgainstFork(sourceNetwork, opts)
+		return err
+	})
+	return result, err
+}
+
+func (p *Project) deployAgainstFork(sourceNetwork string, opts ForkOptions) (*ForkDeployResult, error) {
+	if p.state == nil {
+		return nil, config.ErrDoesNotExist
+	}
+
+	if opts.Deploy.CreateMissing {
+		return nil, fmt.Errorf("accounts can't be created on a fork of %s", sourceNetwork)
+	}
+
+	height := opts.Height
+	if height == 0 {
+		block, err := p.gateway.GetLatestBlock()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the latest block of %s: %w", sourceNetwork, err)
+		}
+		height = block.Height
+	}
+
+	chain, ok := networkChains[sourceNetwork]
+	if !ok {
+		chain = flow.Emulator
+	}
+
+	// the fork runs its own emulator, the configured service account is used if there is one
+	serviceAccount, _ := p.state.EmulatorServiceAccount()
+	forkGateway := gateway.NewForkedEmulatorGateway(
+		serviceAccount,
+		p.gateway,
+		height,
+		gateway.WithEmulatorOptions(emulator.WithChainID(flowGo.ChainID(chain))),
+	)
+	defer forkGateway.Close()
+
+	forked := NewProject(forkGateway, p.state, p.logger)
+	forked.tracing = p.tracing
+	forked.forked = true
+
+	result := &ForkDeployResult{
+		Network:       sourceNetwork,
+		Height:        height,
+		Contracts:     make([]ContractResult, 0),
+		CadenceErrors: make(map[string]*flowkit.CadenceRuntimeError),
+	}
+
+	options := opts.Deploy
+	afterContract := options.AfterContract
+	options.AfterContract = func(contract ContractResult) {
+		result.Contracts = append(result.Contracts, contract)
+
+		var runtimeErr *flowkit.CadenceRuntimeError
+		if errors.As(contract.Err, &runtimeErr) {
+			result.CadenceErrors[contract.Name] = runtimeErr
+		}
+
+		if afterContract != nil {
+			afterContract(contract)
+		}
+	}
+
+	p.logger.Info(fmt.Sprintf("Forking %s at height %d", sourceNetwork, height))
+	_, err := forked.DeployWithOptions(sourceNetwork, opts.Update, options)
+	return result, err
+}
@@ -0,0 +1,280 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/flow-go-sdk"
+
+	"github.com/onflow/flow-cli/pkg/flowkit/config"
+	"github.com/onflow/flow-cli/pkg/flowkit/util"
+)
+
+// maxStorageScanEntries limits the number of storage and capability paths scanned on a single account,
+// accounts with more paths are reported as truncated.
+const maxStorageScanEntries = 1000
+
+// storageTypesScript returns the type identifiers of the values stored and the capabilities linked on the account,
+// by path, stopping after the limit is exceeded.
+const storageTypesScript = `
+pub fun main(address: Address, limit: Int): {String: String} {
+	let account = getAuthAccount(address)
+	let types: {String: String} = {}
+
+	account.forEachStored(fun (path: StoragePath, type: Type): Bool {
+		types[path.toString()] = type.identifier
+		return types.length <= limit
+	})
+	if types.length > limit {
+		return types
+	}
+
+	account.forEachPublic(fun (path: PublicPath, type: Type): Bool {
+		types[path.toString()] = type.identifier
+		return types.length <= limit
+	})
+	if types.length > limit {
+		return types
+	}
+
+	account.forEachPrivate(fun (path: PrivatePath, type: Type): Bool {
+		types[path.toString()] = type.identifier
+		return types.length <= limit
+	})
+
+	return types
+}
+`
+
+// StorageReference is a value stored or a capability linked on a path, whose type references a contract.
+type StorageReference struct {
+	Path string
+	Type string
+}
+
+// AccountImpact contains the storage references to a contract found on an account.
+type AccountImpact struct {
+	Address    flow.Address
+	References []StorageReference
+	// Truncated is true if the account has more paths than scanned, so references may be missing.
+	Truncated bool
+}
+
+// ImpactReport lists the stored values and capabilities that reference the types of a contract,
+// which would be left orphaned if the contract was removed.
+type ImpactReport struct {
+	Contract string
+	Address  flow.Address
+	Accounts []AccountImpact
+}
+
+// HasReferences returns true if any of the scanned accounts references the contract.
+func (r *ImpactReport) HasReferences() bool {
+	for _, account := range r.Accounts {
+		if len(account.References) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Truncated returns true if any of the accounts couldn't be fully scanned.
+func (r *ImpactReport) Truncated() bool {
+	for _, account := range r.Accounts {
+		if account.Truncated {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *ImpactReport) String() string {
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "Values referencing contract %s on account %s:\n", r.Contract, r.Address)
+
+	for _, account := range r.Accounts {
+		if len(account.References) == 0 && !account.Truncated {
+			continue
+		}
+
+		_, _ = fmt.Fprintf(&b, "  %s\n", account.Address)
+		for _, reference := range account.References {
+			_, _ = fmt.Fprintf(&b, "    %s\t%s\n", reference.Path, reference.Type)
+		}
+		if account.Truncated {
+			_, _ = fmt.Fprintf(&b, "    only the first %d paths were scanned\n", maxStorageScanEntries)
+		}
+	}
+
+	return b.String()
+}
+
+// RemoveContractImpact reports which values stored and capabilities linked reference the types of the contract
+// named on the address, before removing the contract.
+//
+// The account of the contract is always scanned, together with the scan accounts provided. If no scan accounts are
+// provided all the accounts in the state on the same network as the address are scanned.
+func (a *Accounts) RemoveContractImpact(
+	address flow.Address,
+	name string,
+	scanAccounts []flow.Address,
+) (report *ImpactReport, err error) {
+	err = a.diagnostics.run(a.tracing, "Accounts.RemoveContractImpact", []Attribute{
+		{Key: "address", Value: address.String()},
+		{Key: "contract", Value: name},
+	}, func(op *operation) error {
+		report, err = a.traced(op).removeContractImpact(address, name, scanAccounts)
+		return err
+	})
+	return report, err
+}
+
+func (a *Accounts) removeContractImpact(
+	address flow.Address,
+	name string,
+	scanAccounts []flow.Address,
+) (*ImpactReport, error) {
+	if scanAccounts == nil {
+		var err error
+		scanAccounts, err = a.networkAccounts(address)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	addresses := []flow.Address{address}
+	for _, scanAddress := range scanAccounts {
+		if !containsAddress(addresses, scanAddress) {
+			addresses = append(addresses, scanAddress)
+		}
+	}
+
+	a.logger.StartProgress(fmt.Sprintf("Scanning %d accounts for references to %s...", len(addresses), name))
+	defer a.logger.StopProgress()
+
+	// type identifiers of the contract types are prefixed with the contract location,
+	// e.g. A.f8d6e0586b0a20c7.Kibble.Vault, and can be nested in other types like references or capabilities
+	typePattern := regexp.MustCompile(fmt.Sprintf(`A\.%s\.%s\b`, address.Hex(), regexp.QuoteMeta(name)))
+
+	report := &ImpactReport{
+		Contract: name,
+		Address:  address,
+		Accounts: make([]AccountImpact, 0, len(addresses)),
+	}
+	for _, scanAddress := range addresses {
+		impact, err := a.accountImpact(scanAddress, typePattern)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan storage of account %s: %w", scanAddress, err)
+		}
+		report.Accounts = append(report.Accounts, *impact)
+	}
+
+	return report, nil
+}
+
+// linkedCapabilityPaths returns the public and private paths of the account linking types of the contract.
+func (a *Accounts) linkedCapabilityPaths(address flow.Address, name string) ([]cadence.Path, error) {
+	report, err := a.RemoveContractImpact(address, name, []flow.Address{})
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]cadence.Path, 0)
+	for _, reference := range report.Accounts[0].References {
+		// paths are formatted as /domain/identifier
+		parts := strings.SplitN(strings.TrimPrefix(reference.Path, "/"), "/", 2)
+		if len(parts) != 2 || parts[0] == common.PathDomainStorage.Identifier() {
+			continue
+		}
+		paths = append(paths, cadence.Path{Domain: parts[0], Identifier: parts[1]})
+	}
+
+	return paths, nil
+}
+
+// accountImpact scans the storage of the account for paths with types matching the pattern.
+func (a *Accounts) accountImpact(address flow.Address, typePattern *regexp.Regexp) (*AccountImpact, error) {
+	value, err := a.gateway.ExecuteScript(
+		[]byte(storageTypesScript),
+		[]cadence.Value{cadence.NewAddress(address), cadence.NewInt(maxStorageScanEntries)},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	types, ok := value.(cadence.Dictionary)
+	if !ok {
+		return nil, fmt.Errorf("unexpected storage scan result %s", value)
+	}
+
+	impact := &AccountImpact{
+		Address:    address,
+		References: make([]StorageReference, 0),
+		Truncated:  len(types.Pairs) > maxStorageScanEntries,
+	}
+	for _, pair := range types.Pairs {
+		path, _ := pair.Key.ToGoValue().(string)
+		typeID, _ := pair.Value.ToGoValue().(string)
+		if typePattern.MatchString(typeID) {
+			impact.References = append(impact.References, StorageReference{Path: path, Type: typeID})
+		}
+	}
+
+	sort.Slice(impact.References, func(i, j int) bool {
+		return impact.References[i].Path < impact.References[j].Path
+	})
+
+	return impact, nil
+}
+
+// networkAccounts returns the addresses of all the accounts in the state on the same network as the address.
+func (a *Accounts) networkAccounts(address flow.Address) ([]flow.Address, error) {
+	if a.state == nil {
+		return nil, config.ErrDoesNotExist
+	}
+
+	chain, err := util.GetAddressNetwork(address)
+	if err != nil {
+		return nil, nil
+	}
+
+	addresses := make([]flow.Address, 0)
+	for _, account := range *a.state.Accounts() {
+		if accountChain, err := util.GetAddressNetwork(account.Address()); err == nil && accountChain == chain {
+			addresses = append(addresses, account.Address())
+		}
+	}
+
+	return addresses, nil
+}
+
+func containsAddress(addresses []flow.Address, address flow.Address) bool {
+	for _, a := range addresses {
+		if a == address {
+			return true
+		}
+	}
+	return false
+}
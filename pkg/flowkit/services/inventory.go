@@ -0,0 +1,191 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/onflow/flow-go-sdk"
+
+	"github.com/onflow/flow-cli/pkg/flowkit"
+	"github.com/onflow/flow-cli/pkg/flowkit/config"
+	"github.com/onflow/flow-cli/pkg/flowkit/gateway"
+	"github.com/onflow/flow-cli/pkg/flowkit/util"
+)
+
+// networkChains maps the default network names to their chain, used to find the accounts of a network.
+var networkChains = map[string]flow.ChainID{
+	config.DefaultEmulatorNetwork().Name: flow.Emulator,
+	config.DefaultTestnetNetwork().Name:  flow.Testnet,
+	config.DefaultSandboxNetwork().Name:  flow.Sandboxnet,
+	config.DefaultMainnetNetwork().Name:  flow.Mainnet,
+}
+
+// ContractInstance is a contract deployed to an account.
+type ContractInstance struct {
+	Account   string       `json:"account"`
+	Address   flow.Address `json:"address"`
+	SHA256    string       `json:"sha256"`
+	SizeBytes int          `json:"sizeBytes"`
+}
+
+// ContractInventory lists the contracts deployed to the accounts of a network.
+type ContractInventory struct {
+	Network string `json:"network"`
+	// Contracts contains the instances of each contract name, sorted by account name.
+	Contracts map[string][]ContractInstance `json:"contracts"`
+	// Collisions contains the sorted names of the contracts deployed with different code to multiple accounts.
+	Collisions []string `json:"collisions"`
+	// MissingAccounts contains the sorted names of the accounts that don't exist on the network.
+	MissingAccounts []string `json:"missingAccounts"`
+}
+
+// ContractInventory returns the contracts deployed to all the accounts in the state for the network.
+//
+// The accounts of a network are the accounts with an address of the network chain and the accounts
+// used by the network deployments. Accounts are fetched concurrently, accounts that don't exist are
+// reported in the inventory instead of failing the call.
+func (p *Project) ContractInventory(network string) (inventory *ContractInventory, err error) {
+	err = p.diagnostics.run(p.tracing, "Project.ContractInventory", []Attribute{
+		{Key: "network", Value: network},
+	}, func(op *operation) error {
+		inventory, err = p.traced(op).contractInventory(network)
+		return err
+	})
+	return inventory, err
+}
+
+func (p *Project) contractInventory(network string) (*ContractInventory, error) {
+	if p.state == nil {
+		return nil, config.ErrDoesNotExist
+	}
+
+	if _, err := p.state.Networks().ByName(network); err != nil {
+		return nil, err
+	}
+
+	accounts, err := p.networkAccounts(network)
+	if err != nil {
+		return nil, err
+	}
+
+	p.logger.StartProgress(fmt.Sprintf("Fetching contracts of %d accounts...", len(accounts)))
+	defer p.logger.StopProgress()
+
+	// each goroutine only writes its own index
+	onChainAccounts := make([]*flow.Account, len(accounts))
+	errs := make([]error, len(accounts))
+	var wg sync.WaitGroup
+	for i, account := range accounts {
+		wg.Add(1)
+		go func(i int, address flow.Address) {
+			defer wg.Done()
+			onChainAccounts[i], errs[i] = p.gateway.GetAccount(address)
+		}(i, account.Address())
+	}
+	wg.Wait()
+
+	inventory := &ContractInventory{
+		Network:         network,
+		Contracts:       make(map[string][]ContractInstance),
+		Collisions:      make([]string, 0),
+		MissingAccounts: make([]string, 0),
+	}
+
+	labels := make([]string, len(accounts))
+	for i, account := range accounts {
+		labels[i] = account.Name()
+	}
+	batchErr := flowkit.NewBatchError(labels...)
+
+	for i, account := range accounts {
+		if errors.Is(errs[i], gateway.ErrAccountNotFound) {
+			inventory.MissingAccounts = append(inventory.MissingAccounts, account.Name())
+			continue
+		}
+		if errs[i] != nil {
+			batchErr.Fail(i, errs[i])
+			continue
+		}
+
+		for name, code := range onChainAccounts[i].Contracts {
+			hash := sha256.Sum256(code)
+			inventory.Contracts[name] = append(inventory.Contracts[name], ContractInstance{
+				Account:   account.Name(),
+				Address:   account.Address(),
+				SHA256:    hex.EncodeToString(hash[:]),
+				SizeBytes: len(code),
+			})
+		}
+	}
+
+	if err := batchErr.Err(); err != nil {
+		return nil, err
+	}
+
+	for name, instances := range inventory.Contracts {
+		for _, instance := range instances[1:] {
+			if instance.SHA256 != instances[0].SHA256 {
+				inventory.Collisions = append(inventory.Collisions, name)
+				break
+			}
+		}
+	}
+	sort.Strings(inventory.Collisions)
+
+	return inventory, nil
+}
+
+// networkAccounts returns the accounts in the state with an address of the network chain or used
+// by the network deployments, sorted by name.
+func (p *Project) networkAccounts(network string) ([]*flowkit.Account, error) {
+	names := make(map[string]bool)
+
+	if chain, ok := networkChains[network]; ok {
+		for _, account := range *p.state.Accounts() {
+			if accountChain, err := util.GetAddressNetwork(account.Address()); err == nil && accountChain == chain {
+				names[account.Name()] = true
+			}
+		}
+	}
+
+	for _, deployment := range p.state.Deployments().ByNetwork(network) {
+		names[deployment.Account] = true
+	}
+
+	accounts := make([]*flowkit.Account, 0, len(names))
+	for name := range names {
+		account, err := p.state.Accounts().ByName(name)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].Name() < accounts[j].Name()
+	})
+
+	return accounts, nil
+}
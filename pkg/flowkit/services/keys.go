@@ -19,8 +19,15 @@
 package services
 
 import (
+	"crypto/elliptic"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
 	"encoding/hex"
+	"encoding/pem"
 	"fmt"
+	"math/big"
+	"strings"
 
 	goeth "github.com/ethereum/go-ethereum/accounts"
 	slip10 "github.com/lmars/go-slip10"
@@ -177,3 +184,203 @@ func (k *Keys) DecodePEM(key string, sigAlgo crypto.SignatureAlgorithm) (*flow.A
 		Weight:    -1,
 	}, nil
 }
+
+// ParsePublicKey parses a public key in any of the supported encodings.
+//
+// Supported encodings are raw hex (uncompressed or compressed point), RLP encoded account key in hex,
+// PEM, and DER encoded as base64 or hex. Raw points are decoded on the curve of the provided signature algorithm,
+// while the other encodings contain the signature algorithm, which is returned instead of the provided one.
+// The hash algorithm is only returned if it is embedded in the encoding (RLP account key),
+// otherwise crypto.UnknownHashAlgorithm is returned.
+func (k *Keys) ParsePublicKey(
+	input string,
+	sigAlgo crypto.SignatureAlgorithm,
+) (crypto.PublicKey, crypto.SignatureAlgorithm, crypto.HashAlgorithm, error) {
+	input = strings.TrimSpace(input)
+
+	if strings.HasPrefix(input, "-----BEGIN") {
+		block, rest := pem.Decode([]byte(input))
+		if block == nil || len(strings.TrimSpace(string(rest))) > 0 {
+			return nil, crypto.UnknownSignatureAlgorithm, crypto.UnknownHashAlgorithm,
+				fmt.Errorf("failed to decode public key detected as PEM: invalid PEM block")
+		}
+		return parseDERPublicKey(block.Bytes, "PEM")
+	}
+
+	if b, err := hex.DecodeString(strings.TrimPrefix(input, "0x")); err == nil {
+		return parseBinaryPublicKey(b, sigAlgo)
+	}
+
+	if b, err := base64.StdEncoding.DecodeString(input); err == nil {
+		return parseDERPublicKey(b, "base64 DER")
+	}
+
+	return nil, crypto.UnknownSignatureAlgorithm, crypto.UnknownHashAlgorithm, fmt.Errorf(
+		"failed to decode public key: unrecognized encoding, supported encodings are hex, RLP account key, PEM and base64 DER",
+	)
+}
+
+// parseBinaryPublicKey detects the encoding of the decoded hex public key by its length and structure,
+// raw points are decoded on the curve of the signature algorithm.
+func parseBinaryPublicKey(
+	b []byte,
+	sigAlgo crypto.SignatureAlgorithm,
+) (crypto.PublicKey, crypto.SignatureAlgorithm, crypto.HashAlgorithm, error) {
+	switch {
+	case len(b) == 64:
+		return decodeECDSAPublicKey(b, sigAlgo, "hex")
+	case len(b) == 65 && b[0] == 0x04:
+		return decodeECDSAPublicKey(b[1:], sigAlgo, "hex")
+	case len(b) == 33 && (b[0] == 0x02 || b[0] == 0x03):
+		return decodeCompressedPublicKey(b, sigAlgo, "compressed hex")
+	case len(b) > 0 && b[0] == 0x30:
+		return parseDERPublicKey(b, "hex DER")
+	case len(b) > 0 && b[0] >= 0xc0: // RLP list prefix
+		accountKey, err := flow.DecodeAccountKey(b)
+		if err != nil {
+			return nil, crypto.UnknownSignatureAlgorithm, crypto.UnknownHashAlgorithm,
+				fmt.Errorf("failed to decode public key detected as RLP account key: %w", err)
+		}
+		return accountKey.PublicKey, accountKey.SigAlgo, accountKey.HashAlgo, nil
+	}
+
+	return nil, crypto.UnknownSignatureAlgorithm, crypto.UnknownHashAlgorithm,
+		fmt.Errorf("failed to decode public key: unsupported hex encoded key of length %d bytes", len(b))
+}
+
+var (
+	oidPublicKeyECDSA      = asn1.ObjectIdentifier{1, 2, 840, 10045, 2, 1}
+	oidNamedCurveP256      = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+	oidNamedCurveSECP256K1 = asn1.ObjectIdentifier{1, 3, 132, 0, 10}
+)
+
+type publicKeyInfo struct {
+	Raw       asn1.RawContent
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+// parseDERPublicKey parses an ECDSA public key in PKIX, ASN.1 DER form.
+func parseDERPublicKey(der []byte, format string) (crypto.PublicKey, crypto.SignatureAlgorithm, crypto.HashAlgorithm, error) {
+	fail := func(err error) (crypto.PublicKey, crypto.SignatureAlgorithm, crypto.HashAlgorithm, error) {
+		return nil, crypto.UnknownSignatureAlgorithm, crypto.UnknownHashAlgorithm,
+			fmt.Errorf("failed to decode public key detected as %s: %w", format, err)
+	}
+
+	var info publicKeyInfo
+	rest, err := asn1.Unmarshal(der, &info)
+	if err != nil {
+		return fail(err)
+	}
+	if len(rest) != 0 {
+		return fail(fmt.Errorf("trailing data after public key"))
+	}
+	if !info.Algorithm.Algorithm.Equal(oidPublicKeyECDSA) {
+		return fail(fmt.Errorf("only ECDSA public keys are supported"))
+	}
+
+	var curve asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(info.Algorithm.Parameters.FullBytes, &curve); err != nil {
+		return fail(fmt.Errorf("invalid curve parameters: %w", err))
+	}
+
+	var sigAlgo crypto.SignatureAlgorithm
+	switch {
+	case curve.Equal(oidNamedCurveP256):
+		sigAlgo = crypto.ECDSA_P256
+	case curve.Equal(oidNamedCurveSECP256K1):
+		sigAlgo = crypto.ECDSA_secp256k1
+	default:
+		return fail(fmt.Errorf("unsupported curve %s", curve))
+	}
+
+	point := info.PublicKey.RightAlign()
+	switch {
+	case len(point) == 65 && point[0] == 0x04:
+		key, err := crypto.DecodePublicKey(sigAlgo, point[1:])
+		if err != nil {
+			return fail(err)
+		}
+		return key, sigAlgo, crypto.UnknownHashAlgorithm, nil
+	case len(point) == 33 && (point[0] == 0x02 || point[0] == 0x03):
+		return decodeCompressedPublicKey(point, sigAlgo, format)
+	}
+
+	return fail(fmt.Errorf("invalid public key point length %d", len(point)))
+}
+
+// decodeECDSAPublicKey decodes a raw uncompressed public key on the curve of the signature algorithm.
+func decodeECDSAPublicKey(
+	b []byte,
+	sigAlgo crypto.SignatureAlgorithm,
+	format string,
+) (crypto.PublicKey, crypto.SignatureAlgorithm, crypto.HashAlgorithm, error) {
+	key, err := crypto.DecodePublicKey(sigAlgo, b)
+	if err != nil {
+		return nil, crypto.UnknownSignatureAlgorithm, crypto.UnknownHashAlgorithm,
+			fmt.Errorf("failed to decode public key detected as %s with signature algorithm %s: %w", format, sigAlgo, err)
+	}
+
+	return key, sigAlgo, crypto.UnknownHashAlgorithm, nil
+}
+
+// decodeCompressedPublicKey decompresses the point on the curve of the signature algorithm and decodes the public key.
+//
+// The curve can't be detected, a compressed point often decodes on both P-256 and secp256k1.
+func decodeCompressedPublicKey(
+	b []byte,
+	sigAlgo crypto.SignatureAlgorithm,
+	format string,
+) (crypto.PublicKey, crypto.SignatureAlgorithm, crypto.HashAlgorithm, error) {
+	raw, ok := decompressPoint(sigAlgo, b)
+	if !ok {
+		return nil, crypto.UnknownSignatureAlgorithm, crypto.UnknownHashAlgorithm, fmt.Errorf(
+			"failed to decode public key detected as %s: point is not on the curve of signature algorithm %s",
+			format,
+			sigAlgo,
+		)
+	}
+
+	return decodeECDSAPublicKey(raw, sigAlgo, format)
+}
+
+var secp256k1P, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f", 16)
+
+// decompressPoint returns the uncompressed encoding (X || Y) of a compressed curve point.
+func decompressPoint(sigAlgo crypto.SignatureAlgorithm, b []byte) ([]byte, bool) {
+	var x, y *big.Int
+
+	switch sigAlgo {
+	case crypto.ECDSA_P256:
+		x, y = elliptic.UnmarshalCompressed(elliptic.P256(), b)
+		if x == nil {
+			return nil, false
+		}
+	case crypto.ECDSA_secp256k1:
+		// y^2 = x^3 + 7, since p = 3 mod 4 the square root is (y^2)^((p+1)/4)
+		p := secp256k1P
+		x = new(big.Int).SetBytes(b[1:])
+		if x.Cmp(p) >= 0 {
+			return nil, false
+		}
+		y2 := new(big.Int).Exp(x, big.NewInt(3), p)
+		y2.Add(y2, big.NewInt(7)).Mod(y2, p)
+
+		exp := new(big.Int).Add(p, big.NewInt(1))
+		exp.Rsh(exp, 2)
+		y = new(big.Int).Exp(y2, exp, p)
+		if new(big.Int).Exp(y, big.NewInt(2), p).Cmp(y2) != 0 {
+			return nil, false
+		}
+		if y.Bit(0) != uint(b[0]&1) {
+			y.Sub(p, y)
+		}
+	default:
+		return nil, false
+	}
+
+	raw := make([]byte, 64)
+	x.FillBytes(raw[:32])
+	y.FillBytes(raw[32:])
+	return raw, true
+}
@@ -190,6 +190,136 @@ func TestKeys(t *testing.T) {
 		assert.Equal(t, dkey.SigAlgo.String(), "ECDSA_P256")
 	})
 
+	t.Run("Parse Public Key", func(t *testing.T) {
+		t.Parallel()
+
+		_, s, _ := setup()
+
+		const p256Key = "d479b3cdc9edbddb195cb12b35161ade826b032a64bdd4062cc87fb3ba7e71c9cf646ff23990bb4532ca45c445c7e908cef278b2c4615360039a6660a366a95f"
+		const secp256k1Key = "d7482bbaff7827035d5b238df318b10604673dc613808723efbd23fbc4b9fad34a415828d924ec7b83ac0eddf22ef115b7c203ee39fb080572d7e51775ee54be"
+		const p256DER = "MFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAE1HmzzcntvdsZXLErNRYa3oJrAypkvdQGLMh/s7p+ccnPZG/yOZC7RTLKRcRFx+kIzvJ4ssRhU2ADmmZgo2apXw=="
+
+		// the signature algorithm of the flag is used for raw points, ECDSA_P256 if not set
+		cases := []struct {
+			name     string
+			input    string
+			flag     crypto.SignatureAlgorithm
+			key      string
+			sigAlgo  crypto.SignatureAlgorithm
+			hashAlgo crypto.HashAlgorithm
+			err      string
+		}{{
+			name:     "hex",
+			input:    p256Key,
+			key:      p256Key,
+			sigAlgo:  crypto.ECDSA_P256,
+			hashAlgo: crypto.UnknownHashAlgorithm,
+		}, {
+			name:     "hex with prefix",
+			input:    "0x" + p256Key,
+			key:      p256Key,
+			sigAlgo:  crypto.ECDSA_P256,
+			hashAlgo: crypto.UnknownHashAlgorithm,
+		}, {
+			name:     "hex uncompressed point",
+			input:    "04" + secp256k1Key,
+			flag:     crypto.ECDSA_secp256k1,
+			key:      secp256k1Key,
+			sigAlgo:  crypto.ECDSA_secp256k1,
+			hashAlgo: crypto.UnknownHashAlgorithm,
+		}, {
+			name:     "hex secp256k1",
+			input:    secp256k1Key,
+			flag:     crypto.ECDSA_secp256k1,
+			key:      secp256k1Key,
+			sigAlgo:  crypto.ECDSA_secp256k1,
+			hashAlgo: crypto.UnknownHashAlgorithm,
+		}, {
+			name:     "compressed secp256k1",
+			input:    "02d7482bbaff7827035d5b238df318b10604673dc613808723efbd23fbc4b9fad3",
+			flag:     crypto.ECDSA_secp256k1,
+			key:      secp256k1Key,
+			sigAlgo:  crypto.ECDSA_secp256k1,
+			hashAlgo: crypto.UnknownHashAlgorithm,
+		}, {
+			name:     "compressed P-256",
+			input:    "03d479b3cdc9edbddb195cb12b35161ade826b032a64bdd4062cc87fb3ba7e71c9",
+			key:      p256Key,
+			sigAlgo:  crypto.ECDSA_P256,
+			hashAlgo: crypto.UnknownHashAlgorithm,
+		}, {
+			name:     "RLP account key",
+			input:    "f847b84084d716c14b051ad6b001624f738f5d302636e6b07cc75e4530af7776a4368a2b586dbefc0564ee28384c2696f178cbed52e62811bcc9ecb59568c996d342db2402038203e8",
+			key:      "84d716c14b051ad6b001624f738f5d302636e6b07cc75e4530af7776a4368a2b586dbefc0564ee28384c2696f178cbed52e62811bcc9ecb59568c996d342db24",
+			sigAlgo:  crypto.ECDSA_P256,
+			hashAlgo: crypto.SHA3_256,
+		}, {
+			name:     "PEM",
+			input:    "-----BEGIN PUBLIC KEY-----\nMFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAE1HmzzcntvdsZXLErNRYa3oJrAypk\nvdQGLMh/s7p+ccnPZG/yOZC7RTLKRcRFx+kIzvJ4ssRhU2ADmmZgo2apXw==\n-----END PUBLIC KEY-----",
+			flag:     crypto.ECDSA_secp256k1,
+			key:      p256Key,
+			sigAlgo:  crypto.ECDSA_P256,
+			hashAlgo: crypto.UnknownHashAlgorithm,
+		}, {
+			name:     "base64 DER",
+			input:    p256DER,
+			key:      p256Key,
+			sigAlgo:  crypto.ECDSA_P256,
+			hashAlgo: crypto.UnknownHashAlgorithm,
+		}, {
+			name:  "invalid hex point",
+			input: "ff" + p256Key[2:],
+			err:   "failed to decode public key detected as hex with signature algorithm ECDSA_P256:",
+		}, {
+			name:  "hex on other curve",
+			input: secp256k1Key,
+			err:   "failed to decode public key detected as hex with signature algorithm ECDSA_P256:",
+		}, {
+			name:  "compressed on other curve",
+			input: "03d479b3cdc9edbddb195cb12b35161ade826b032a64bdd4062cc87fb3ba7e71c9",
+			flag:  crypto.ECDSA_secp256k1,
+			err:   "failed to decode public key detected as compressed hex",
+		}, {
+			name:  "invalid hex length",
+			input: "aabbcc",
+			err:   "failed to decode public key: unsupported hex encoded key of length 3 bytes",
+		}, {
+			name:  "invalid RLP account key",
+			input: "f847b84084d716",
+			err:   "failed to decode public key detected as RLP account key:",
+		}, {
+			name:  "invalid PEM",
+			input: "-----BEGIN PUBLIC KEY-----\nnope\n-----END PUBLIC KEY-----",
+			err:   "failed to decode public key detected as PEM:",
+		}, {
+			name:  "invalid base64 DER",
+			input: p256DER[:40],
+			err:   "failed to decode public key detected as base64 DER:",
+		}, {
+			name:  "unknown encoding",
+			input: "not a key!",
+			err:   "failed to decode public key: unrecognized encoding",
+		}}
+
+		for _, test := range cases {
+			flag := test.flag
+			if flag == crypto.UnknownSignatureAlgorithm {
+				flag = crypto.ECDSA_P256
+			}
+
+			key, sigAlgo, hashAlgo, err := s.Keys.ParsePublicKey(test.input, flag)
+			if test.err != "" {
+				assert.ErrorContains(t, err, test.err, test.name)
+				continue
+			}
+
+			assert.NoError(t, err, test.name)
+			assert.Equal(t, "0x"+test.key, key.String(), test.name)
+			assert.Equal(t, test.sigAlgo, sigAlgo, test.name)
+			assert.Equal(t, test.hashAlgo, hashAlgo, test.name)
+		}
+	})
+
 	t.Run("Decode PEM Key Invalid", func(t *testing.T) {
 		t.Parallel()
 
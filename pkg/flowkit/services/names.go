@@ -0,0 +1,246 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+
+	"github.com/onflow/flow-cli/pkg/flowkit/config"
+	"github.com/onflow/flow-cli/pkg/flowkit/gateway"
+	"github.com/onflow/flow-cli/pkg/flowkit/util"
+)
+
+var (
+	// ErrNameNotRegistered is returned when a name isn't registered in its name registry.
+	ErrNameNotRegistered = errors.New("name is not registered")
+	// ErrNameWithoutAddress is returned when a name is registered but no address is set for it.
+	ErrNameWithoutAddress = errors.New("name has no address set")
+	// ErrNameRegistryDisabled is returned when the network has no registry configured for the name.
+	ErrNameRegistryDisabled = errors.New("name registry is not configured for the network")
+)
+
+// NameResolver resolves human-friendly account names to addresses.
+type NameResolver interface {
+	// ResolveName returns the address of the name, ok is false if the resolver doesn't handle the name.
+	ResolveName(name string) (address flow.Address, ok bool, err error)
+}
+
+// NameResolutionError describes a name that couldn't be resolved with its registry.
+type NameResolutionError struct {
+	Name     string
+	Registry string
+	Network  string
+	Err      error
+}
+
+func (n *NameResolutionError) Error() string {
+	return fmt.Sprintf("failed to resolve %s with the %s registry on %s: %s", n.Name, n.Registry, n.Network, n.Err)
+}
+
+func (n *NameResolutionError) Unwrap() error {
+	return n.Err
+}
+
+// findLookupScript returns the owner of a .find name, nil if the name is free.
+const findLookupScript = `
+import FIND from 0x%s
+
+pub fun main(name: String): Address?? {
+	let status = FIND.status(name)
+	if status.status == FIND.LeaseStatus.FREE {
+		return nil
+	}
+	return status.owner
+}
+`
+
+// flownsLookupScript returns the address a .fn domain points to, nil if the domain isn't registered.
+const flownsLookupScript = `
+import Domains from 0x%s
+
+pub fun main(name: String): Address?? {
+	let rootHash = String.encodeHex(HashAlgorithm.SHA3_256.hash("fn".utf8))
+	let labelHash = String.encodeHex(HashAlgorithm.SHA3_256.hash(name.utf8))
+	let nameHash = "0x".concat(String.encodeHex(HashAlgorithm.SHA3_256.hash(rootHash.concat(labelHash).utf8)))
+	if Domains.getExpiredTime(nameHash) == nil {
+		return nil
+	}
+	return Domains.getRecords(nameHash)
+}
+`
+
+// nameRegistry is an on-chain registry resolving the names with its suffix.
+type nameRegistry struct {
+	name   string
+	suffix string
+	script string
+	// defaults are the built-in registry addresses by network name.
+	defaults map[string]string
+	// configured returns the registry address configured for the network.
+	configured func(network config.Network) string
+}
+
+var nameRegistries = []nameRegistry{{
+	name:   "find",
+	suffix: ".find",
+	script: findLookupScript,
+	defaults: map[string]string{
+		config.DefaultMainnetNetwork().Name: "097bafa4e0b48eef",
+		config.DefaultTestnetNetwork().Name: "35717efbbce11c74",
+	},
+	configured: func(network config.Network) string { return network.FindRegistry },
+}, {
+	name:   "flowns",
+	suffix: ".fn",
+	script: flownsLookupScript,
+	defaults: map[string]string{
+		config.DefaultMainnetNetwork().Name: "233eb012d34b0070",
+		config.DefaultTestnetNetwork().Name: "b05b2abb42335e88",
+	},
+	configured: func(network config.Network) string { return network.FlownsRegistry },
+}}
+
+// IsRegistryName reports whether the name ends in the suffix of a name registry, like alice.find or alice.fn.
+func IsRegistryName(name string) bool {
+	name = strings.ToLower(name)
+	for _, registry := range nameRegistries {
+		if label := strings.TrimSuffix(name, registry.suffix); label != name && label != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// RegistryNameResolver resolves .find and .fn names with the name registries of a network.
+//
+// The registries are looked up at the addresses configured for the network, the built-in addresses
+// for mainnet and testnet otherwise. Resolved names are cached for the lifetime of the resolver.
+type RegistryNameResolver struct {
+	gateway gateway.Gateway
+	network config.Network
+
+	mu    sync.Mutex
+	cache map[string]flow.Address
+}
+
+var _ NameResolver = &RegistryNameResolver{}
+
+// NewRegistryNameResolver returns a resolver looking up names in the registries of the network using the gateway.
+func NewRegistryNameResolver(gateway gateway.Gateway, network config.Network) *RegistryNameResolver {
+	return &RegistryNameResolver{
+		gateway: gateway,
+		network: network,
+		cache:   make(map[string]flow.Address),
+	}
+}
+
+// ResolveName resolves names ending in .find or .fn, other names are not handled.
+func (r *RegistryNameResolver) ResolveName(name string) (flow.Address, bool, error) {
+	name = strings.ToLower(name)
+
+	for _, registry := range nameRegistries {
+		label := strings.TrimSuffix(name, registry.suffix)
+		if label == name || label == "" {
+			continue
+		}
+
+		address, err := r.resolve(registry, name, label)
+		if err != nil {
+			return flow.EmptyAddress, true, &NameResolutionError{
+				Name:     name,
+				Registry: registry.name,
+				Network:  r.network.Name,
+				Err:      err,
+			}
+		}
+		return address, true, nil
+	}
+
+	return flow.EmptyAddress, false, nil
+}
+
+func (r *RegistryNameResolver) resolve(registry nameRegistry, name string, label string) (flow.Address, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if address, ok := r.cache[name]; ok {
+		return address, nil
+	}
+
+	registryAddress := registry.configured(r.network)
+	if registryAddress == "" {
+		registryAddress = registry.defaults[r.network.Name]
+	}
+	if registryAddress == "" {
+		return flow.EmptyAddress, ErrNameRegistryDisabled
+	}
+	contractAddress, ok := util.ParseAddress(registryAddress)
+	if !ok {
+		return flow.EmptyAddress, fmt.Errorf("invalid registry address %s", registryAddress)
+	}
+
+	value, err := r.gateway.ExecuteScript(
+		[]byte(fmt.Sprintf(registry.script, contractAddress.Hex())),
+		[]cadence.Value{cadence.String(label)},
+	)
+	if err != nil {
+		return flow.EmptyAddress, err
+	}
+
+	address, err := decodeRegistryAddress(value)
+	if err != nil {
+		return flow.EmptyAddress, err
+	}
+
+	r.cache[name] = address
+	return address, nil
+}
+
+// decodeRegistryAddress decodes the Address?? returned by the lookup scripts, nil if the name isn't registered
+// and a nil address if the name is registered without an address.
+func decodeRegistryAddress(value cadence.Value) (flow.Address, error) {
+	registered, ok := value.(cadence.Optional)
+	if !ok {
+		return flow.EmptyAddress, fmt.Errorf("unexpected registry lookup result %s", value)
+	}
+	if registered.Value == nil {
+		return flow.EmptyAddress, ErrNameNotRegistered
+	}
+
+	// a registered address may not be wrapped in an optional if the script returned it unboxed
+	if optional, ok := registered.Value.(cadence.Optional); ok {
+		if optional.Value == nil {
+			return flow.EmptyAddress, ErrNameWithoutAddress
+		}
+		registered = optional
+	}
+
+	address, ok := registered.Value.(cadence.Address)
+	if !ok {
+		return flow.EmptyAddress, fmt.Errorf("unexpected registry lookup result %s", value)
+	}
+
+	return flow.Address(address), nil
+}
@@ -0,0 +1,217 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-cli/pkg/flowkit/config"
+	"github.com/onflow/flow-cli/pkg/flowkit/tests"
+)
+
+func TestNameResolver(t *testing.T) {
+	t.Parallel()
+
+	aliceAddress := flow.HexToAddress("0x01cf0e2f2f715450")
+
+	registered := func(address *flow.Address) cadence.Value {
+		if address == nil {
+			return cadence.NewOptional(cadence.NewOptional(nil))
+		}
+		return cadence.NewOptional(cadence.NewOptional(cadence.NewAddress(*address)))
+	}
+
+	t.Run("Resolve Find Name", func(t *testing.T) {
+		_, _, gw := setup()
+		resolver := NewRegistryNameResolver(gw.Mock, config.DefaultMainnetNetwork())
+
+		gw.ExecuteScript.Run(func(args mock.Arguments) {
+			assert.Contains(t, string(args.Get(0).([]byte)), "import FIND from 0x097bafa4e0b48eef")
+			assert.Equal(t, []cadence.Value{cadence.String("alice")}, args.Get(1))
+			gw.ExecuteScript.Return(registered(&aliceAddress), nil)
+		})
+
+		address, ok, err := resolver.ResolveName("Alice.find")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, aliceAddress, address)
+	})
+
+	t.Run("Resolve Flowns Name", func(t *testing.T) {
+		_, _, gw := setup()
+		resolver := NewRegistryNameResolver(gw.Mock, config.DefaultTestnetNetwork())
+
+		gw.ExecuteScript.Run(func(args mock.Arguments) {
+			assert.Contains(t, string(args.Get(0).([]byte)), "import Domains from 0xb05b2abb42335e88")
+			assert.Equal(t, []cadence.Value{cadence.String("alice")}, args.Get(1))
+			gw.ExecuteScript.Return(registered(&aliceAddress), nil)
+		})
+
+		address, ok, err := resolver.ResolveName("alice.fn")
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, aliceAddress, address)
+	})
+
+	t.Run("Resolve Cached", func(t *testing.T) {
+		_, _, gw := setup()
+		resolver := NewRegistryNameResolver(gw.Mock, config.DefaultMainnetNetwork())
+
+		gw.ExecuteScript.Run(func(args mock.Arguments) {
+			gw.ExecuteScript.Return(registered(&aliceAddress), nil)
+		})
+
+		for i := 0; i < 2; i++ {
+			address, _, err := resolver.ResolveName("alice.find")
+			require.NoError(t, err)
+			assert.Equal(t, aliceAddress, address)
+		}
+		gw.Mock.AssertNumberOfCalls(t, "ExecuteScript", 1)
+	})
+
+	t.Run("Resolve Configured Registry", func(t *testing.T) {
+		_, _, gw := setup()
+		network := config.DefaultTestnetNetwork()
+		network.FindRegistry = "0x7e60df042a9c0868"
+		resolver := NewRegistryNameResolver(gw.Mock, network)
+
+		gw.ExecuteScript.Run(func(args mock.Arguments) {
+			assert.Contains(t, string(args.Get(0).([]byte)), "import FIND from 0x7e60df042a9c0868")
+			gw.ExecuteScript.Return(registered(&aliceAddress), nil)
+		})
+
+		_, _, err := resolver.ResolveName("alice.find")
+		require.NoError(t, err)
+	})
+
+	t.Run("Unregistered Name", func(t *testing.T) {
+		_, _, gw := setup()
+		resolver := NewRegistryNameResolver(gw.Mock, config.DefaultMainnetNetwork())
+
+		gw.ExecuteScript.Run(func(args mock.Arguments) {
+			gw.ExecuteScript.Return(cadence.NewOptional(nil), nil)
+		})
+
+		_, ok, err := resolver.ResolveName("nobody.find")
+		assert.True(t, ok)
+		assert.True(t, errors.Is(err, ErrNameNotRegistered))
+		assert.EqualError(t, err, "failed to resolve nobody.find with the find registry on mainnet: name is not registered")
+
+		var resolutionErr *NameResolutionError
+		require.ErrorAs(t, err, &resolutionErr)
+		assert.Equal(t, "find", resolutionErr.Registry)
+
+		// failed resolutions are not cached
+		gw.ExecuteScript.Run(func(args mock.Arguments) {
+			gw.ExecuteScript.Return(registered(&aliceAddress), nil)
+		})
+		address, _, err := resolver.ResolveName("nobody.find")
+		require.NoError(t, err)
+		assert.Equal(t, aliceAddress, address)
+	})
+
+	t.Run("Name Without Address", func(t *testing.T) {
+		_, _, gw := setup()
+		resolver := NewRegistryNameResolver(gw.Mock, config.DefaultMainnetNetwork())
+
+		gw.ExecuteScript.Run(func(args mock.Arguments) {
+			gw.ExecuteScript.Return(registered(nil), nil)
+		})
+
+		_, _, err := resolver.ResolveName("alice.fn")
+		assert.True(t, errors.Is(err, ErrNameWithoutAddress))
+		assert.False(t, errors.Is(err, ErrNameNotRegistered))
+	})
+
+	t.Run("Registry Error", func(t *testing.T) {
+		_, _, gw := setup()
+		resolver := NewRegistryNameResolver(gw.Mock, config.DefaultMainnetNetwork())
+
+		gw.ExecuteScript.Run(func(args mock.Arguments) {
+			gw.ExecuteScript.Return(nil, fmt.Errorf("access node unavailable"))
+		})
+
+		_, _, err := resolver.ResolveName("alice.find")
+		assert.EqualError(t, err, "failed to resolve alice.find with the find registry on mainnet: access node unavailable")
+	})
+
+	t.Run("Disabled Network", func(t *testing.T) {
+		_, _, gw := setup()
+		resolver := NewRegistryNameResolver(gw.Mock, config.DefaultEmulatorNetwork())
+
+		_, ok, err := resolver.ResolveName("alice.find")
+		assert.True(t, ok)
+		assert.True(t, errors.Is(err, ErrNameRegistryDisabled))
+		gw.Mock.AssertNotCalled(t, "ExecuteScript", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Unhandled Names", func(t *testing.T) {
+		_, _, gw := setup()
+		resolver := NewRegistryNameResolver(gw.Mock, config.DefaultMainnetNetwork())
+
+		for _, name := range []string{"alice", ".find", "alice.flow", "0x01cf0e2f2f715450"} {
+			_, ok, err := resolver.ResolveName(name)
+			require.NoError(t, err)
+			assert.False(t, ok, name)
+		}
+		gw.Mock.AssertNotCalled(t, "ExecuteScript", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Get Multiple", func(t *testing.T) {
+		state, s, gw := setup()
+		s.Accounts.SetNameResolver(NewRegistryNameResolver(gw.Mock, config.DefaultMainnetNetwork()))
+		// an account in the state is never shadowed by a registered name
+		state.Accounts().AddOrUpdate(tests.Bob().SetName("bob.find"))
+
+		gw.ExecuteScript.Run(func(args mock.Arguments) {
+			assert.Equal(t, []cadence.Value{cadence.String("alice")}, args.Get(1))
+			gw.ExecuteScript.Return(registered(&aliceAddress), nil)
+		})
+
+		accounts, err := s.Accounts.GetMultiple([]string{"alice.find", "bob.find"})
+		require.NoError(t, err)
+		require.Len(t, accounts, 2)
+		assert.Equal(t, aliceAddress, accounts[0].Address)
+		assert.Equal(t, tests.Bob().Address(), accounts[1].Address)
+		gw.Mock.AssertNumberOfCalls(t, "ExecuteScript", 1)
+	})
+
+	t.Run("Get Multiple Without Resolver", func(t *testing.T) {
+		_, s, _ := setup()
+
+		_, err := s.Accounts.GetMultiple([]string{"alice.find"})
+		assert.EqualError(t, err, "account selector alice.find is not an account name, a tag selector or a valid address")
+	})
+}
+
+func TestIsRegistryName(t *testing.T) {
+	assert.True(t, IsRegistryName("alice.find"))
+	assert.True(t, IsRegistryName("Alice.FN"))
+	assert.False(t, IsRegistryName(".find"))
+	assert.False(t, IsRegistryName("alice"))
+	assert.False(t, IsRegistryName("f8d6e0586b0a20c7"))
+}
@@ -0,0 +1,101 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package services
+
+import (
+	"fmt"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+
+	"github.com/onflow/flow-cli/pkg/flowkit/util"
+)
+
+// nftIDsScript borrows the NonFungibleToken collection linked at a public path and returns its NFT IDs.
+const nftIDsScript = `
+import NonFungibleToken from 0x%s
+
+pub fun main(address: Address, path: PublicPath): [UInt64] {
+	let collection = getAccount(address)
+		.getCapability(path)
+		.borrow<&AnyResource{NonFungibleToken.CollectionPublic}>()
+		?? panic("could not borrow a NonFungibleToken collection at the path")
+
+	return collection.getIDs()
+}
+`
+
+// nftStorageIDsScript borrows the NonFungibleToken collection stored at a storage path and returns its NFT IDs.
+const nftStorageIDsScript = `
+import NonFungibleToken from 0x%s
+
+pub fun main(address: Address, path: StoragePath): [UInt64] {
+	let collection = getAuthAccount(address)
+		.borrow<&AnyResource{NonFungibleToken.CollectionPublic}>(from: path)
+		?? panic("could not borrow a NonFungibleToken collection at the path")
+
+	return collection.getIDs()
+}
+`
+
+// GetNFTs returns the IDs of the NFTs in the NonFungibleToken collection of the account at the collection path.
+//
+// The collection path is either the public path the collection is linked at or the storage path it's stored at.
+func (a *Accounts) GetNFTs(address flow.Address, collectionPath cadence.Path) (values []cadence.Value, err error) {
+	err = a.diagnostics.run(a.tracing, "Accounts.GetNFTs", []Attribute{
+		{Key: "address", Value: address.String()},
+		{Key: "collectionPath", Value: collectionPath.String()},
+	}, func(op *operation) error {
+		values, err = a.traced(op).getNFTs(address, collectionPath)
+		return err
+	})
+	return values, err
+}
+
+func (a *Accounts) getNFTs(address flow.Address, collectionPath cadence.Path) ([]cadence.Value, error) {
+	chain, err := util.GetAddressNetwork(address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine network from address, check the address and network")
+	}
+
+	var script string
+	switch collectionPath.Domain {
+	case "public":
+		script = nftIDsScript
+	case "storage":
+		script = nftStorageIDsScript
+	default:
+		return nil, fmt.Errorf("collection path %s must be a public or storage path", collectionPath)
+	}
+
+	value, err := a.gateway.ExecuteScript(
+		[]byte(fmt.Sprintf(script, util.NonFungibleTokenAddress(chain).Hex())),
+		[]cadence.Value{cadence.NewAddress(address), collectionPath},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, ok := value.(cadence.Array)
+	if !ok {
+		return nil, fmt.Errorf("unexpected collection IDs result %s", value)
+	}
+
+	return ids.Values, nil
+}
@@ -0,0 +1,584 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package services
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/pmezard/go-difflib/difflib"
+
+	"github.com/onflow/flow-cli/pkg/flowkit"
+	"github.com/onflow/flow-cli/pkg/flowkit/config"
+	"github.com/onflow/flow-cli/pkg/flowkit/gateway"
+	"github.com/onflow/flow-cli/pkg/flowkit/output"
+	"github.com/onflow/flow-cli/pkg/flowkit/project"
+	"github.com/onflow/flow-cli/pkg/flowkit/util"
+)
+
+// ContractAction is the change a deployment plan makes to a contract.
+type ContractAction string
+
+const (
+	ContractCreate ContractAction = "create"
+	ContractUpdate ContractAction = "update"
+	ContractSkip   ContractAction = "skip"
+)
+
+// transactionFeeScript computes the fee of a transaction from its inclusion and execution effort
+// with the fee parameters of the network.
+const transactionFeeScript = `
+import FlowFees from 0x%s
+
+pub fun main(inclusionEffort: UFix64, executionEffort: UFix64): UFix64 {
+	return FlowFees.computeFees(inclusionEffort: inclusionEffort, executionEffort: executionEffort)
+}`
+
+// transactionInclusionEffort is the inclusion effort of every transaction, 1.0.
+const transactionInclusionEffort = cadence.UFix64(100_000_000)
+
+// PlannedContract is a contract of a deployment plan.
+type PlannedContract struct {
+	Name        string         `json:"name"`
+	AccountName string         `json:"account"`
+	Address     flow.Address   `json:"address"`
+	Location    string         `json:"location"`
+	Action      ContractAction `json:"action"`
+	// Code is the contract code with resolved imports, which is deployed when the plan is applied.
+	Code string `json:"code"`
+	// CodeHash is the hex encoded SHA256 hash of the code.
+	CodeHash string `json:"codeHash"`
+	// OnChainHash is the hex encoded SHA256 hash of the code on the account when the plan was created,
+	// empty if the contract didn't exist.
+	OnChainHash string `json:"onChainHash,omitempty"`
+	// Diff is the unified diff from the on-chain code to the code, only set for updates.
+	Diff string `json:"diff,omitempty"`
+	// Args are the JSON-Cadence encoded initializer arguments.
+	Args []json.RawMessage `json:"args,omitempty"`
+}
+
+// DeployPlan describes the changes deploying the project makes to a network.
+//
+// A plan is created by Plan without sending anything and executed by Apply. It can be serialized
+// to JSON and applied later, e.g. in a different CI job after the plan was approved.
+type DeployPlan struct {
+	Network string `json:"network"`
+	// Contracts are sorted in deployment order.
+	Contracts []PlannedContract `json:"contracts"`
+	// Metadata is recorded in the lock file for each deployed contract.
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// MaxFee is the estimated fee in FLOW of all the deployment transactions if each uses its whole compute limit,
+	// computed with the fee parameters of the network. The fees paid are usually lower, as they depend on the
+	// computation the transactions use. It's empty if the fee parameters of the network can't be read.
+	MaxFee string `json:"maxFee,omitempty"`
+}
+
+func (d *DeployPlan) String() string {
+	var b strings.Builder
+	symbols := map[ContractAction]string{ContractCreate: "+", ContractUpdate: "~", ContractSkip: "="}
+	deployed := 0
+
+	_, _ = fmt.Fprintf(&b, "Deployment plan for %s:\n", d.Network)
+	for _, contract := range d.Contracts {
+		_, _ = fmt.Fprintf(
+			&b,
+			"  %s %s -> 0x%s (%s) [%s]\n",
+			symbols[contract.Action],
+			contract.Name,
+			contract.Address,
+			contract.AccountName,
+			contract.Action,
+		)
+		if contract.Action != ContractSkip {
+			deployed++
+		}
+		if contract.Diff != "" {
+			for _, line := range strings.Split(strings.TrimRight(contract.Diff, "\n"), "\n") {
+				_, _ = fmt.Fprintf(&b, "      %s\n", line)
+			}
+		}
+	}
+	fee := "maximum fee unknown"
+	if d.MaxFee != "" {
+		fee = fmt.Sprintf("maximum fee %s FLOW", d.MaxFee)
+	}
+	_, _ = fmt.Fprintf(&b, "%d to deploy, %d unchanged, %s", deployed, len(d.Contracts)-deployed, fee)
+
+	return b.String()
+}
+
+// PlanOptions configures how the deployment is planned.
+type PlanOptions struct {
+	// Update plans the update of contracts that exist on the account with different code, otherwise planning fails.
+	Update bool
+	// Metadata is recorded in the lock file for each deployed contract when the plan is applied.
+	Metadata map[string]string
+	// CascadeUpdates plans the update of the contracts still importing dependencies from their previous
+	// addresses even without Update, see StaleDependents.
+	CascadeUpdates bool
+	// IgnoreFormatting compares the on-chain and the local code formatted with project.FormatCadence,
+	// so contracts differing only in formatting are skipped and diffs don't include formatting changes.
+	// The planned code is never formatted.
+	IgnoreFormatting bool
+}
+
+// ApplyOptions configures how a deployment plan is applied.
+type ApplyOptions struct {
+	// Force applies the plan even if the on-chain code changed since the plan was created.
+	Force bool
+}
+
+// ContractDrift is a planned contract whose on-chain code changed since the plan was created.
+type ContractDrift struct {
+	Name        string
+	AccountName string
+	Address     flow.Address
+	// PlannedHash is the hash of the on-chain code recorded in the plan, empty if the contract didn't exist.
+	PlannedHash string
+	// OnChainHash is the hash of the current on-chain code, empty if the contract doesn't exist.
+	OnChainHash string
+}
+
+// PlanDriftError is returned when the on-chain code of planned contracts changed since the plan was created.
+type PlanDriftError struct {
+	Network   string
+	Contracts []ContractDrift
+}
+
+func (d *PlanDriftError) Error() string {
+	hash := func(h string) string {
+		if h == "" {
+			return "absent"
+		}
+		if len(h) > 12 {
+			return h[:12]
+		}
+		return h
+	}
+
+	contracts := make([]string, len(d.Contracts))
+	for i, c := range d.Contracts {
+		contracts[i] = fmt.Sprintf(
+			"%s on 0x%s (planned %s, found %s)",
+			c.Name,
+			c.Address,
+			hash(c.PlannedHash),
+			hash(c.OnChainHash),
+		)
+	}
+
+	return fmt.Sprintf(
+		"on-chain contracts on %s changed since the plan was created: %s, create a new plan or force the apply",
+		d.Network,
+		strings.Join(contracts, ", "),
+	)
+}
+
+// Plan resolves the deployment of the project for the network without sending any transaction.
+//
+// The contracts are sorted by their dependencies and their imports are resolved the same as in Deploy,
+// then each contract is compared with the code on its account to decide whether it's created, updated
+// or skipped. Deployment accounts must exist on the network.
+func (p *Project) Plan(network string, options PlanOptions) (plan *DeployPlan, err error) {
+	err = p.diagnostics.run(p.tracing, "Project.Plan", []Attribute{
+		{Key: "network", Value: network},
+	}, func(op *operation) error {
+		plan, err = p.traced(op).plan(network, options)
+		return err
+	})
+	return plan, err
+}
+
+func (p *Project) plan(network string, options PlanOptions) (*DeployPlan, error) {
+	if p.state == nil {
+		return nil, config.ErrDoesNotExist
+	}
+
+	if err := p.state.Validate(network); err != nil {
+		return nil, err
+	}
+
+	if err := flowkit.ValidateDeploymentMetadata(options.Metadata); err != nil {
+		return nil, err
+	}
+
+	// the accounts service is only used to create missing accounts, which planning never does
+	if err := p.checkDeploymentAccounts(nil, network, DeployOptions{}); err != nil {
+		return nil, err
+	}
+
+	contracts, err := p.state.DeploymentContractsByNetwork(network)
+	if err != nil {
+		return nil, err
+	}
+
+	deployment, err := newDeployment(p.state, contracts)
+	if err != nil {
+		return nil, err
+	}
+
+	sorted, err := deployment.Sort()
+	if err != nil {
+		return nil, err
+	}
+
+	cascaded := make(map[string]bool)
+	if options.CascadeUpdates {
+		cascaded, err = p.cascadedContracts(network)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	p.logger.StartProgress(fmt.Sprintf("Planning the deployment of %d contracts...", len(sorted)))
+	defer p.logger.StopProgress()
+
+	plan := &DeployPlan{
+		Network:   network,
+		Contracts: make([]PlannedContract, 0, len(sorted)),
+		Metadata:  options.Metadata,
+	}
+
+	deployed := 0
+	onChainAccounts := make(map[flow.Address]*flow.Account)
+	for _, contract := range sorted {
+		program, err := p.resolveImports(contract, network)
+		if err != nil {
+			return nil, err
+		}
+
+		account, ok := onChainAccounts[contract.AccountAddress]
+		if !ok {
+			account, err = p.gateway.GetAccount(contract.AccountAddress)
+			if err != nil {
+				return nil, err
+			}
+			onChainAccounts[contract.AccountAddress] = account
+		}
+
+		planned := PlannedContract{
+			Name:        contract.Name,
+			AccountName: contract.AccountName,
+			Address:     contract.AccountAddress,
+			Location:    contract.Location(),
+			Code:        string(program.Code()),
+			CodeHash:    codeHash(program.Code()),
+		}
+
+		for _, arg := range contract.Args {
+			encoded, err := jsoncdc.Encode(arg)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode argument of contract %s: %w", contract.Name, err)
+			}
+			planned.Args = append(planned.Args, bytes.TrimSpace(encoded))
+		}
+
+		existing, exists := account.Contracts[contract.Name]
+		comparedExisting, comparedCode := comparableCodes(
+			p.logger,
+			contract.Name,
+			existing,
+			program.Code(),
+			options.IgnoreFormatting,
+		)
+		switch {
+		case !exists:
+			planned.Action = ContractCreate
+		case bytes.Equal(comparedExisting, comparedCode):
+			planned.Action = ContractSkip
+			planned.OnChainHash = codeHash(existing)
+		case !options.Update && !cascaded[contract.Name]:
+			return nil, fmt.Errorf(
+				"contract %s already exists on account %s with different code, plan with update to update it",
+				contract.Name,
+				contract.AccountName,
+			)
+		default:
+			planned.Action = ContractUpdate
+			planned.OnChainHash = codeHash(existing)
+			planned.Diff, err = contractDiff(contract, comparedExisting, comparedCode)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if planned.Action != ContractSkip {
+			deployed++
+		}
+		plan.Contracts = append(plan.Contracts, planned)
+	}
+
+	fee, err := p.deploymentFee(network)
+	if err != nil {
+		output.Warn(p.logger, fmt.Sprintf("failed to estimate the fee of the deployment: %s", err))
+	} else {
+		plan.MaxFee = (cadence.UFix64(deployed) * fee).String()
+	}
+
+	return plan, nil
+}
+
+// deploymentFee returns the fee of a contract deployment transaction using its whole compute limit, read from
+// the FlowFees contract of the network.
+func (p *Project) deploymentFee(network string) (cadence.UFix64, error) {
+	chain, ok := networkChains[network]
+	fees := util.FlowFeesAddress(chain)
+	if !ok || fees == flow.EmptyAddress {
+		return 0, fmt.Errorf("the FlowFees contract of network %s is not known", network)
+	}
+
+	// the execution effort of a transaction is its computation, deployment transactions use the default limit
+	accounts := newSharedAccounts(p.gateway, p.state, p.logger, p.tracing, p.sequences, p.forked)
+	limit := accounts.computeLimit(gateway.DefaultComputeLimit, network)
+
+	value, err := p.gateway.ExecuteScript(
+		[]byte(fmt.Sprintf(transactionFeeScript, fees.Hex())),
+		[]cadence.Value{transactionInclusionEffort, cadence.UFix64(limit)},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	fee, ok := value.(cadence.UFix64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected fee %s", value)
+	}
+	return fee, nil
+}
+
+// Apply executes the deployment plan, deploying exactly the planned code.
+//
+// Before anything is sent the code on the accounts is compared with the plan and a PlanDriftError
+// is returned if any planned contract changed on-chain since the plan was created. The results
+// of the contracts are returned in deployment order, also when the deployment fails.
+func (p *Project) Apply(plan *DeployPlan) (results []ContractResult, err error) {
+	err = p.diagnostics.run(p.tracing, "Project.Apply", []Attribute{
+		{Key: "network", Value: plan.Network},
+	}, func(op *operation) error {
+		results, err = p.traced(op).apply(plan, ApplyOptions{})
+		return err
+	})
+	return results, err
+}
+
+// ApplyWithOptions executes the deployment plan same as Apply, with the possibility of ignoring on-chain changes.
+func (p *Project) ApplyWithOptions(plan *DeployPlan, options ApplyOptions) (results []ContractResult, err error) {
+	err = p.diagnostics.run(p.tracing, "Project.ApplyWithOptions", []Attribute{
+		{Key: "network", Value: plan.Network},
+	}, func(op *operation) error {
+		results, err = p.traced(op).apply(plan, options)
+		return err
+	})
+	return results, err
+}
+
+func (p *Project) apply(plan *DeployPlan, options ApplyOptions) ([]ContractResult, error) {
+	if p.state == nil {
+		return nil, config.ErrDoesNotExist
+	}
+
+	if err := flowkit.ValidateDeploymentMetadata(plan.Metadata); err != nil {
+		return nil, err
+	}
+
+	contracts := make([]*project.Contract, len(plan.Contracts))
+	for i, planned := range plan.Contracts {
+		account, err := p.state.Accounts().ByName(planned.AccountName)
+		if err != nil {
+			return nil, err
+		}
+		if account.Address() != planned.Address {
+			return nil, fmt.Errorf(
+				"address of account %s changed from planned 0x%s to 0x%s",
+				planned.AccountName,
+				planned.Address,
+				account.Address(),
+			)
+		}
+
+		if hash := codeHash([]byte(planned.Code)); hash != planned.CodeHash {
+			return nil, fmt.Errorf(
+				"code of contract %s doesn't match the planned code hash %s, the plan was modified",
+				planned.Name,
+				planned.CodeHash,
+			)
+		}
+
+		args := make([]cadence.Value, len(planned.Args))
+		for j, arg := range planned.Args {
+			args[j], err = jsoncdc.Decode(nil, arg)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode argument of contract %s: %w", planned.Name, err)
+			}
+		}
+
+		contracts[i] = project.NewContract(
+			planned.Name,
+			planned.Location,
+			[]byte(planned.Code),
+			planned.Address,
+			planned.AccountName,
+			args,
+		)
+	}
+
+	if !options.Force {
+		if err := p.checkPlanDrift(plan); err != nil {
+			return nil, err
+		}
+	}
+
+	accounts := newSharedAccounts(p.gateway, p.state, output.NewStdoutLogger(output.NoneLog), p.tracing, p.sequences, p.forked)
+
+	results := make([]ContractResult, len(plan.Contracts))
+	deployErr := newProjectDeploymentError(contracts)
+	for i, planned := range plan.Contracts {
+		contract := contracts[i]
+		results[i] = ContractResult{
+			ContractPlan: ContractPlan{
+				Name:        planned.Name,
+				AccountName: planned.AccountName,
+				Address:     planned.Address,
+				CodeHash:    planned.CodeHash,
+				Update:      planned.Action == ContractUpdate,
+				Args:        contract.Args,
+			},
+			Status: ContractUnchanged,
+		}
+
+		if planned.Action == ContractSkip {
+			continue
+		}
+
+		results[i].TxID, results[i].Status, results[i].Err = p.deployContract(
+			accounts,
+			contract,
+			plan.Network,
+			planned.Action == ContractUpdate,
+			AddContractOptions{Metadata: plan.Metadata},
+			deployErr,
+		)
+	}
+
+	if len(deployErr.contracts) > 0 {
+		return results, deployErr
+	}
+
+	return results, nil
+}
+
+// checkPlanDrift compares the code on the accounts with the on-chain code recorded in the plan.
+func (p *Project) checkPlanDrift(plan *DeployPlan) error {
+	driftErr := &PlanDriftError{Network: plan.Network}
+	onChainAccounts := make(map[flow.Address]*flow.Account)
+
+	for _, planned := range plan.Contracts {
+		account, ok := onChainAccounts[planned.Address]
+		if !ok {
+			var err error
+			account, err = p.gateway.GetAccount(planned.Address)
+			if err != nil {
+				return err
+			}
+			onChainAccounts[planned.Address] = account
+		}
+
+		onChainHash := ""
+		if code, exists := account.Contracts[planned.Name]; exists {
+			onChainHash = codeHash(code)
+		}
+
+		if onChainHash != planned.OnChainHash {
+			driftErr.Contracts = append(driftErr.Contracts, ContractDrift{
+				Name:        planned.Name,
+				AccountName: planned.AccountName,
+				Address:     planned.Address,
+				PlannedHash: planned.OnChainHash,
+				OnChainHash: onChainHash,
+			})
+		}
+	}
+
+	if len(driftErr.Contracts) > 0 {
+		return driftErr
+	}
+
+	return nil
+}
+
+// contractDiff returns the unified diff from the on-chain code of the contract to the local code.
+func contractDiff(contract *project.Contract, onChain []byte, local []byte) (string, error) {
+	lines := func(code []byte) []string {
+		if len(code) == 0 {
+			return nil // a missing contract has no lines, instead of a single empty one
+		}
+		return difflib.SplitLines(string(code))
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        lines(onChain),
+		B:        lines(local),
+		FromFile: fmt.Sprintf("0x%s.%s", contract.AccountAddress, contract.Name),
+		ToFile:   contract.Location(),
+		Context:  3,
+	})
+}
+
+// comparableCodes returns the codes formatted with project.FormatCadence if formatting is ignored, so they can be
+// compared and diffed without formatting differences.
+//
+// If either code fails to format the codes are returned unchanged and a warning is logged.
+// Missing codes are returned as they are since there is nothing to compare them with.
+func comparableCodes(
+	logger output.Logger,
+	name string,
+	onChain []byte,
+	local []byte,
+	ignoreFormatting bool,
+) ([]byte, []byte) {
+	if !ignoreFormatting || len(onChain) == 0 || len(local) == 0 {
+		return onChain, local
+	}
+
+	formattedOnChain, err := project.FormatCadence(onChain)
+	if err != nil {
+		output.Warn(logger, fmt.Sprintf("failed to format the on-chain code of contract %s, comparing unformatted: %s", name, err))
+		return onChain, local
+	}
+	formattedLocal, err := project.FormatCadence(local)
+	if err != nil {
+		output.Warn(logger, fmt.Sprintf("failed to format the code of contract %s, comparing unformatted: %s", name, err))
+		return onChain, local
+	}
+
+	return formattedOnChain, formattedLocal
+}
+
+// codeHash returns the hex encoded SHA256 hash of the code.
+func codeHash(code []byte) string {
+	hash := sha256.Sum256(code)
+	return hex.EncodeToString(hash[:])
+}
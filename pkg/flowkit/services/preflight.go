@@ -0,0 +1,276 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/onflow/flow-go-sdk"
+
+	"github.com/onflow/flow-cli/pkg/flowkit"
+	"github.com/onflow/flow-cli/pkg/flowkit/config"
+	"github.com/onflow/flow-cli/pkg/flowkit/gateway"
+)
+
+// Kinds of problems found by the deployment preflight.
+const (
+	PreflightAccountMissing = "account-missing"
+	PreflightKeyMissing     = "key-missing"
+	PreflightKeyRevoked     = "key-revoked"
+	PreflightKeyMismatch    = "key-mismatch"
+)
+
+// PreflightProblem describes why a deployment account can't sign the deployment with its configured key.
+type PreflightProblem struct {
+	Kind     string
+	Account  string
+	Address  flow.Address
+	KeyIndex int
+	Reason   string
+}
+
+func (p PreflightProblem) String() string {
+	return fmt.Sprintf("account '%s' (0x%s): %s", p.Account, p.Address, p.Reason)
+}
+
+// PreflightReport lists the problems of the deployment accounts of a network found before deploying.
+type PreflightReport struct {
+	Network  string
+	Problems []PreflightProblem
+	// Notes describe the checks that were skipped, e.g. for keys whose public key can't be derived locally.
+	Notes []string
+}
+
+// PreflightError is returned when the deployment preflight finds problems with the deployment accounts.
+type PreflightError struct {
+	Report *PreflightReport
+}
+
+func (p *PreflightError) Error() string {
+	lines := []string{fmt.Sprintf(
+		"deployment preflight found %d problems on %s:",
+		len(p.Report.Problems),
+		p.Report.Network,
+	)}
+	for _, problem := range p.Report.Problems {
+		lines = append(lines, fmt.Sprintf("  - %s", problem))
+	}
+	for _, note := range p.Report.Notes {
+		lines = append(lines, fmt.Sprintf("  note: %s", note))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Unwrap returns a MissingAccountsError if any of the deployment accounts doesn't exist.
+func (p *PreflightError) Unwrap() error {
+	missingErr := &MissingAccountsError{Network: p.Report.Network}
+	for _, problem := range p.Report.Problems {
+		if problem.Kind == PreflightAccountMissing {
+			missingErr.Accounts = append(missingErr.Accounts, MissingAccount{Name: problem.Account, Address: problem.Address})
+		}
+	}
+
+	if len(missingErr.Accounts) == 0 {
+		return nil
+	}
+	return missingErr
+}
+
+// Preflight checks that every deployment account of the network can sign the deployment.
+//
+// For each account it checks that the account exists, that the configured key index exists on the account
+// and is not revoked and that the configured private key matches the public key on the account. Keys
+// whose public key can't be derived locally, like KMS keys, are only checked for existence and a note
+// is added to the report. All the problems are collected in the report instead of failing on the first one.
+func (p *Project) Preflight(network string) (report *PreflightReport, err error) {
+	err = p.diagnostics.run(p.tracing, "Project.Preflight", []Attribute{
+		{Key: "network", Value: network},
+	}, func(op *operation) error {
+		report, err = p.traced(op).preflight(network, false)
+		return err
+	})
+	return report, err
+}
+
+// PreflightAccounts checks that the accounts selected by the selectors can sign on the network, the same way
+// Preflight checks the deployment accounts.
+//
+// A selector is an account name or a tag selector like "tag:admin", which selects all the accounts tagged
+// with it and fails if no account is tagged with it.
+func (p *Project) PreflightAccounts(network string, selectors []string) (report *PreflightReport, err error) {
+	err = p.diagnostics.run(p.tracing, "Project.PreflightAccounts", []Attribute{
+		{Key: "network", Value: network},
+		{Key: "selectors", Value: strings.Join(selectors, ",")},
+	}, func(op *operation) error {
+		report, err = p.traced(op).preflightAccounts(network, selectors)
+		return err
+	})
+	return report, err
+}
+
+func (p *Project) preflightAccounts(network string, selectors []string) (*PreflightReport, error) {
+	if p.state == nil {
+		return nil, config.ErrDoesNotExist
+	}
+
+	accounts, err := p.state.ResolveAccountSelectors(selectors)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.checkAccounts(network, accounts, false)
+}
+
+// preflight checks the deployment accounts, missing accounts are not reported if they are going to be created.
+func (p *Project) preflight(network string, createMissing bool) (*PreflightReport, error) {
+	if p.state == nil {
+		return nil, config.ErrDoesNotExist
+	}
+
+	accounts := make([]*flowkit.Account, 0)
+	checked := make(map[string]bool)
+	for _, deployment := range p.state.Deployments().ByNetwork(network) {
+		if checked[deployment.Account] {
+			continue
+		}
+		checked[deployment.Account] = true
+
+		account, err := p.state.Accounts().ByName(deployment.Account)
+		if err != nil {
+			continue // reported when the contracts are resolved
+		}
+		accounts = append(accounts, account)
+	}
+
+	return p.checkAccounts(network, accounts, createMissing)
+}
+
+// checkAccounts reports the problems of the accounts on the network.
+func (p *Project) checkAccounts(
+	network string,
+	accounts []*flowkit.Account,
+	createMissing bool,
+) (*PreflightReport, error) {
+	report := &PreflightReport{
+		Network:  network,
+		Problems: make([]PreflightProblem, 0),
+		Notes:    make([]string, 0),
+	}
+
+	for _, account := range accounts {
+		onChain, err := p.gateway.GetAccount(account.Address())
+		if errors.Is(err, gateway.ErrAccountNotFound) {
+			if !createMissing {
+				report.Problems = append(report.Problems, PreflightProblem{
+					Kind:     PreflightAccountMissing,
+					Account:  account.Name(),
+					Address:  account.Address(),
+					KeyIndex: account.Key().Index(),
+					Reason:   fmt.Sprintf("account does not exist on %s", network),
+				})
+			}
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get account %s: %w", account.Name(), err)
+		}
+
+		problem, note := checkAccountKey(account, onChain)
+		if problem != nil {
+			report.Problems = append(report.Problems, *problem)
+		}
+		if note != "" {
+			report.Notes = append(report.Notes, note)
+		}
+	}
+
+	return report, nil
+}
+
+// checkAccountKey compares the configured key of the account with the key at the same index on the network.
+func checkAccountKey(account *flowkit.Account, onChain *flow.Account) (*PreflightProblem, string) {
+	key := account.Key()
+	problem := &PreflightProblem{
+		Account:  account.Name(),
+		Address:  account.Address(),
+		KeyIndex: key.Index(),
+	}
+
+	var onChainKey *flow.AccountKey
+	for _, k := range onChain.Keys {
+		if k.Index == key.Index() {
+			onChainKey = k
+			break
+		}
+	}
+
+	if onChainKey == nil {
+		problem.Kind = PreflightKeyMissing
+		problem.Reason = fmt.Sprintf("key index %d does not exist, the account has %d keys", key.Index(), len(onChain.Keys))
+		return problem, ""
+	}
+
+	if onChainKey.Revoked {
+		problem.Kind = PreflightKeyRevoked
+		problem.Reason = fmt.Sprintf("key %d is revoked", key.Index())
+		return problem, ""
+	}
+
+	privateKey, err := key.PrivateKey()
+	if err != nil {
+		return nil, fmt.Sprintf(
+			"account '%s' uses a %s key, its public key can't be derived locally and was not checked",
+			account.Name(),
+			key.Type(),
+		)
+	}
+
+	if !(*privateKey).PublicKey().Equals(onChainKey.PublicKey) {
+		problem.Kind = PreflightKeyMismatch
+		problem.Reason = fmt.Sprintf("the configured private key does not match the public key %d on the account", key.Index())
+		return problem, ""
+	}
+
+	return nil, ""
+}
+
+// preflightDeploy runs the preflight before deploying.
+//
+// If the only problems are missing accounts nil is returned, so they are reported or created when the accounts are checked.
+func (p *Project) preflightDeploy(network string, createMissing bool) error {
+	report, err := p.preflight(network, createMissing)
+	if err != nil {
+		return err
+	}
+
+	for _, note := range report.Notes {
+		p.logger.Info(note)
+	}
+
+	for _, problem := range report.Problems {
+		if problem.Kind != PreflightAccountMissing {
+			return &PreflightError{Report: report}
+		}
+	}
+
+	return nil
+}
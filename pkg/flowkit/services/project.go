@@ -21,7 +21,10 @@ package services
 import (
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/onflow/cadence"
 	"github.com/onflow/flow-go-sdk"
 	"github.com/onflow/flow-go-sdk/crypto"
 
@@ -34,9 +37,14 @@ import (
 
 // Project is a service that handles all interactions for a state.
 type Project struct {
-	gateway gateway.Gateway
-	state   *flowkit.State
-	logger  output.Logger
+	gateway     gateway.Gateway
+	state       *flowkit.State
+	logger      output.Logger
+	tracing     *tracing
+	diagnostics *diagnostics
+	sequences   *SequenceManager
+	// forked is set when the project is deployed to a local fork of the network.
+	forked bool
 }
 
 // NewProject returns a new state service.
@@ -46,9 +54,10 @@ func NewProject(
 	logger output.Logger,
 ) *Project {
 	return &Project{
-		gateway: gateway,
-		state:   state,
-		logger:  logger,
+		gateway:   gateway,
+		state:     state,
+		logger:    logger,
+		sequences: NewSequenceManager(gateway),
 	}
 }
 
@@ -60,6 +69,24 @@ func (p *Project) Init(
 	sigAlgo crypto.SignatureAlgorithm,
 	hashAlgo crypto.HashAlgorithm,
 	serviceKey crypto.PrivateKey,
+) (state *flowkit.State, err error) {
+	err = p.diagnostics.run(p.tracing, "Project.Init", []Attribute{
+		{Key: "reset", Value: fmt.Sprint(reset)},
+		{Key: "global", Value: fmt.Sprint(global)},
+	}, func(op *operation) error {
+		state, err = p.traced(op).init(readerWriter, reset, global, sigAlgo, hashAlgo, serviceKey)
+		return err
+	})
+	return state, err
+}
+
+func (p *Project) init(
+	readerWriter flowkit.ReaderWriter,
+	reset bool,
+	global bool,
+	sigAlgo crypto.SignatureAlgorithm,
+	hashAlgo crypto.HashAlgorithm,
+	serviceKey crypto.PrivateKey,
 ) (*flowkit.State, error) {
 	path := config.DefaultPath
 	if global {
@@ -98,6 +125,14 @@ type StandardContract struct {
 }
 
 func (p *Project) ReplaceStandardContractReferenceToAlias(standardContract StandardContract) error {
+	return p.diagnostics.run(p.tracing, "Project.ReplaceStandardContractReferenceToAlias", []Attribute{
+		{Key: "contract", Value: standardContract.Name},
+	}, func(op *operation) error {
+		return p.traced(op).replaceStandardContractReferenceToAlias(standardContract)
+	})
+}
+
+func (p *Project) replaceStandardContractReferenceToAlias(standardContract StandardContract) error {
 	//replace contract with alias
 	c, err := p.state.Config().Contracts.ByNameAndNetwork(standardContract.Name, config.DefaultMainnetNetwork().Name)
 	if err != nil {
@@ -121,7 +156,12 @@ func (p *Project) ReplaceStandardContractReferenceToAlias(standardContract Stand
 }
 
 func (p *Project) CheckForStandardContractUsageOnMainnet() error {
+	return p.diagnostics.run(p.tracing, "Project.CheckForStandardContractUsageOnMainnet", nil, func(op *operation) error {
+		return p.traced(op).checkForStandardContractUsageOnMainnet()
+	})
+}
 
+func (p *Project) checkForStandardContractUsageOnMainnet() error {
 	mainnetContracts := map[string]StandardContract{
 		"FungibleToken": {
 			Name:     "FungibleToken",
@@ -196,7 +236,7 @@ func (p *Project) CheckForStandardContractUsageOnMainnet() error {
 		p.logger.Info(fmt.Sprintf("You can read more about it here: %s \n", standardContract.InfoLink))
 
 		if output.WantToUseMainnetVersionPrompt() {
-			err := p.ReplaceStandardContractReferenceToAlias(standardContract)
+			err := p.replaceStandardContractReferenceToAlias(standardContract)
 			if err != nil {
 				return err
 			}
@@ -205,31 +245,367 @@ func (p *Project) CheckForStandardContractUsageOnMainnet() error {
 	return nil
 }
 
+// DeployOptions configures how the project contracts are deployed.
+type DeployOptions struct {
+	// Batch deploys contracts targeting the same account in a single transaction where dependencies allow it.
+	Batch bool
+	// FallbackToIndividual deploys contracts of a failed batch one by one.
+	FallbackToIndividual bool
+	// BeforeContract is called before each contract is deployed, returning an error vetoes the
+	// deployment of the contract and all the contracts depending on it.
+	BeforeContract func(ContractPlan) error
+	// AfterContract is called after each contract is deployed, failed or was skipped.
+	AfterContract func(ContractResult)
+	// Metadata is recorded in the lock file for each deployed contract, e.g. the commit the contracts were built from.
+	Metadata map[string]string
+	// CreateMissing creates the deployment accounts that don't exist on the network with their configured key
+	// before deploying, otherwise a MissingAccountsError is returned.
+	CreateMissing bool
+	// Creator pays for creating the missing accounts, on the emulator the service account is used if not set.
+	Creator *flowkit.Account
+	// SkipPreflight skips checking the keys of the deployment accounts before deploying.
+	SkipPreflight bool
+	// StrictImports requires the imports of all the contracts to resolve to the addresses pinned for the
+	// network in the lock file, also enabled by the strictImports network configuration.
+	StrictImports bool
+	// CascadeUpdates updates the contracts still importing dependencies from their previous addresses,
+	// even if updating is not requested and their source is unchanged, see StaleDependents.
+	CascadeUpdates bool
+	// IgnoreFormatting skips contracts whose code only differs in formatting from the deployed code.
+	IgnoreFormatting bool
+}
+
+// MissingAccount is a deployment account that doesn't exist on the network.
+type MissingAccount struct {
+	Name    string
+	Address flow.Address
+}
+
+// MissingAccountsError is returned when deployment accounts don't exist on the network.
+type MissingAccountsError struct {
+	Network  string
+	Accounts []MissingAccount
+}
+
+func (m *MissingAccountsError) Error() string {
+	accounts := make([]string, len(m.Accounts))
+	for i, account := range m.Accounts {
+		accounts[i] = fmt.Sprintf("account '%s' (0x%s)", account.Name, account.Address)
+	}
+
+	verb, pronoun := "does", "it"
+	if len(m.Accounts) > 1 {
+		verb, pronoun = "do", "them"
+	}
+
+	return fmt.Sprintf(
+		"%s %s not exist on %s — create %s or run with --create-missing",
+		strings.Join(accounts, ", "),
+		verb,
+		m.Network,
+		pronoun,
+	)
+}
+
+// ContractPlan describes a contract about to be deployed.
+type ContractPlan struct {
+	Name        string
+	AccountName string
+	Address     flow.Address
+	// CodeHash is the hex encoded SHA256 hash of the contract code with resolved imports.
+	CodeHash string
+	// Update is true if the contract already exists on the account.
+	Update bool
+	Args   []cadence.Value
+}
+
+// ContractDeployStatus is the outcome of a contract deployment.
+type ContractDeployStatus string
+
+const (
+	ContractDeployed  ContractDeployStatus = "deployed"
+	ContractUnchanged ContractDeployStatus = "unchanged"
+	ContractFailed    ContractDeployStatus = "failed"
+	ContractSkipped   ContractDeployStatus = "skipped"
+)
+
+// ContractResult describes a contract after the deployment.
+type ContractResult struct {
+	ContractPlan
+	TxID   flow.Identifier
+	Status ContractDeployStatus
+	Err    error
+}
+
+// DeployHookError is an error returned or a panic raised by a deployment hook.
+type DeployHookError struct {
+	Hook     string
+	Contract string
+	Err      error
+}
+
+func (h *DeployHookError) Error() string {
+	return fmt.Sprintf("%s hook failed for contract %s: %s", h.Hook, h.Contract, h.Err.Error())
+}
+
+func (h *DeployHookError) Unwrap() error {
+	return h.Err
+}
+
+// deployHooks runs the deployment hooks and keeps track of the vetoed contracts.
+type deployHooks struct {
+	project    *Project
+	options    DeployOptions
+	deployment *project.Deployment
+	network    string
+	accounts   map[flow.Address]*flow.Account
+	plans      map[string]ContractPlan
+	// vetoed contains the names of contracts that are not deployed because of a hook, including their dependents.
+	vetoed map[string]bool
+}
+
+func (h *deployHooks) enabled() bool {
+	return h.options.BeforeContract != nil || h.options.AfterContract != nil
+}
+
+// before runs the BeforeContract hook and returns an error if the contract or any of its dependencies is vetoed.
+func (h *deployHooks) before(contract *project.Contract) error {
+	if !h.enabled() {
+		return nil
+	}
+
+	for _, dep := range h.deployment.Dependencies(contract) {
+		if h.vetoed[dep.Name] {
+			h.vetoed[contract.Name] = true
+			return fmt.Errorf("contract %s not deployed, depends on vetoed contract %s", contract.Name, dep.Name)
+		}
+	}
+
+	plan, err := h.plan(contract)
+	if err != nil {
+		return err
+	}
+	h.plans[contract.Name] = plan
+
+	if h.options.BeforeContract == nil {
+		return nil
+	}
+
+	err = recoverHook(func() error {
+		return h.options.BeforeContract(plan)
+	})
+	if err != nil {
+		h.vetoed[contract.Name] = true
+		return &DeployHookError{Hook: "BeforeContract", Contract: contract.Name, Err: err}
+	}
+
+	return nil
+}
+
+// after runs the AfterContract hook, a panic in the hook is logged and doesn't affect the deployment.
+func (h *deployHooks) after(
+	contract *project.Contract,
+	txID flow.Identifier,
+	status ContractDeployStatus,
+	deployErr error,
+) {
+	if h.options.AfterContract == nil {
+		return
+	}
+
+	plan, ok := h.plans[contract.Name]
+	if !ok {
+		plan = ContractPlan{
+			Name:        contract.Name,
+			AccountName: contract.AccountName,
+			Address:     contract.AccountAddress,
+			Args:        contract.Args,
+		}
+	}
+
+	err := recoverHook(func() error {
+		h.options.AfterContract(ContractResult{
+			ContractPlan: plan,
+			TxID:         txID,
+			Status:       status,
+			Err:          deployErr,
+		})
+		return nil
+	})
+	if err != nil {
+		h.project.logger.Error((&DeployHookError{Hook: "AfterContract", Contract: contract.Name, Err: err}).Error())
+	}
+}
+
+// plan resolves the contract imports and checks if the contract already exists on the account.
+func (h *deployHooks) plan(contract *project.Contract) (ContractPlan, error) {
+	program, err := h.project.resolveImports(contract, h.network)
+	if err != nil {
+		return ContractPlan{}, err
+	}
+
+	account, ok := h.accounts[contract.AccountAddress]
+	if !ok {
+		account, err = h.project.gateway.GetAccount(contract.AccountAddress)
+		if err != nil {
+			return ContractPlan{}, err
+		}
+		h.accounts[contract.AccountAddress] = account
+	}
+	_, exists := account.Contracts[contract.Name]
+
+	return ContractPlan{
+		Name:        contract.Name,
+		AccountName: contract.AccountName,
+		Address:     contract.AccountAddress,
+		CodeHash:    codeHash(program.Code()),
+		Update:      exists,
+		Args:        contract.Args,
+	}, nil
+}
+
+// resolveImports parses the contract and replaces its imports with the addresses of the contracts on the network.
+func (p *Project) resolveImports(contract *project.Contract, network string) (*project.Program, error) {
+	program, err := project.NewProgram(contract)
+	if err != nil {
+		return nil, err
+	}
+
+	if !program.HasImports() {
+		return program, nil
+	}
+
+	contracts, err := p.state.DeploymentContractsByNetwork(network)
+	if err != nil {
+		return nil, err
+	}
+
+	return newImportReplacer(p.state, contracts, network).Replace(program)
+}
+
+// recoverHook calls the hook and converts a panic in it into an error.
+func recoverHook(hook func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return hook()
+}
+
 // Deploy the project for the provided network.
 //
 // Retrieve all the contracts for specified network, sort them for deployment
 // deploy one by one and replace the imports in the contract source so it corresponds
-// to the account name the contract was deployed to.
-func (p *Project) Deploy(network string, update bool) ([]*project.Contract, error) {
+// to the account name the contract was deployed to. Deployed contracts are recorded in
+// the lock file, DeployWithOptions also records metadata with them. Before any transaction is built the
+// keys of the deployment accounts are checked by the preflight, see Preflight.
+func (p *Project) Deploy(network string, update bool) (contracts []*project.Contract, err error) {
+	err = p.diagnostics.run(p.tracing, "Project.Deploy", []Attribute{
+		{Key: "network", Value: network},
+		{Key: "update", Value: fmt.Sprint(update)},
+	}, func(op *operation) error {
+		contracts, err = p.traced(op).deployWithOptions(network, update, DeployOptions{})
+		return err
+	})
+	return contracts, err
+}
+
+// DeployWithOptions deploys the project for the provided network same as Deploy, with the
+// possibility of batching contracts for the same account into a single transaction.
+func (p *Project) DeployWithOptions(network string, update bool, options DeployOptions) (contracts []*project.Contract, err error) {
+	err = p.diagnostics.run(p.tracing, "Project.DeployWithOptions", []Attribute{
+		{Key: "network", Value: network},
+		{Key: "update", Value: fmt.Sprint(update)},
+	}, func(op *operation) error {
+		contracts, err = p.traced(op).deployWithOptions(network, update, options)
+		return err
+	})
+	return contracts, err
+}
+
+func (p *Project) deployWithOptions(network string, update bool, options DeployOptions) ([]*project.Contract, error) {
 	if p.state == nil {
 		return nil, config.ErrDoesNotExist
 	}
 
-	contracts, err := p.state.DeploymentContractsByNetwork(network)
+	if err := p.state.Validate(network); err != nil {
+		return nil, err
+	}
+
+	if err := flowkit.ValidateDeploymentMetadata(options.Metadata); err != nil {
+		return nil, err
+	}
+
+	accounts := newSharedAccounts(p.gateway, p.state, output.NewStdoutLogger(output.NoneLog), p.tracing, p.sequences, p.forked)
+
+	// every violation of the allowlist is reported before anything is sent
+	allowlist, strict, err := strictImportAllowlist(p.state, network, options.StrictImports)
 	if err != nil {
 		return nil, err
 	}
+	if strict {
+		substitutions, err := p.deploymentImportSubstitutions(network)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkImportAllowlist(allowlist, network, substitutions); err != nil {
+			return nil, err
+		}
+	}
+
+	// all the account and key problems are reported together before anything is sent
+	if !options.SkipPreflight {
+		if err := p.preflightDeploy(network, options.CreateMissing); err != nil {
+			return nil, err
+		}
+	}
+
+	// accounts must exist before the contracts are resolved, since created accounts can change addresses
+	if err := p.checkDeploymentAccounts(accounts, network, options); err != nil {
+		return nil, err
+	}
 
-	deployment, err := project.NewDeployment(contracts)
+	cascaded := make(map[string]bool)
+	if options.CascadeUpdates {
+		cascaded, err = p.cascadedContracts(network)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	contracts, err := p.state.DeploymentContractsByNetwork(network)
 	if err != nil {
 		return nil, err
 	}
 
-	sorted, err := deployment.Sort()
+	deployment, err := newDeployment(p.state, contracts)
 	if err != nil {
 		return nil, err
 	}
 
+	var batches [][]*project.Contract
+	if options.Batch {
+		batches, err = deployment.Batches()
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		sorted, err := deployment.Sort()
+		if err != nil {
+			return nil, err
+		}
+		for _, contract := range sorted {
+			batches = append(batches, []*project.Contract{contract})
+		}
+	}
+
+	sorted := make([]*project.Contract, 0, len(contracts))
+	for _, batch := range batches {
+		sorted = append(sorted, batch...)
+	}
+
 	p.logger.Info(fmt.Sprintf(
 		"\nDeploying %d contracts for accounts: %s\n",
 		len(sorted),
@@ -237,73 +613,508 @@ func (p *Project) Deploy(network string, update bool) ([]*project.Contract, erro
 	))
 	defer p.logger.StopProgress()
 
-	// todo refactor service layer so it can be shared
-	accounts := NewAccounts(p.gateway, p.state, output.NewStdoutLogger(output.NoneLog))
+	steps := p.logger.StartProgressSteps("Deploying contracts", len(batches))
+	defer steps.Complete()
+
+	hooks := &deployHooks{
+		project:    p,
+		options:    options,
+		deployment: deployment,
+		network:    network,
+		accounts:   make(map[flow.Address]*flow.Account),
+		plans:      make(map[string]ContractPlan),
+		vetoed:     make(map[string]bool),
+	}
+
+	deployStart := time.Now()
+
+	deployErr := newProjectDeploymentError(sorted)
+	addOptions := AddContractOptions{Metadata: options.Metadata, IgnoreFormatting: options.IgnoreFormatting}
+	for _, batch := range batches {
+		steps.Increment(fmt.Sprintf("Deploying %s", contractNames(batch)))
+
+		allowed := make([]*project.Contract, 0, len(batch))
+		for _, contract := range batch {
+			if err := hooks.before(contract); err != nil {
+				err = deployErr.add(contract, err, fmt.Sprintf("contract %s skipped", contract.Name))
+				hooks.after(contract, flow.EmptyID, ContractSkipped, err)
+				continue
+			}
+			allowed = append(allowed, contract)
+		}
+
+		// cascaded contracts are updated on their own, so the rest of the batch is not updated with them
+		if !update && len(allowed) > 1 {
+			var stale []*project.Contract
+			allowed, stale = splitCascaded(allowed, cascaded)
+			for _, contract := range stale {
+				txID, status, err := p.deployContract(accounts, contract, network, true, addOptions, deployErr)
+				hooks.after(contract, txID, status, err)
+			}
+		}
+
+		if len(allowed) == 0 {
+			continue
+		}
+
+		if len(allowed) == 1 {
+			contractUpdate := update || cascaded[allowed[0].Name]
+			txID, status, err := p.deployContract(accounts, allowed[0], network, contractUpdate, addOptions, deployErr)
+			hooks.after(allowed[0], txID, status, err)
+			continue
+		}
+
+		txID, deployed, err := p.deployBatch(accounts, allowed, network, update, addOptions)
+		if err == nil {
+			for _, contract := range allowed {
+				if _, ok := deployed[contract.Name]; ok {
+					hooks.after(contract, txID, ContractDeployed, nil)
+				} else {
+					hooks.after(contract, flow.EmptyID, ContractUnchanged, nil)
+				}
+			}
+			continue
+		}
+
+		if options.FallbackToIndividual {
+			p.logger.Info(fmt.Sprintf(
+				"%s -> 0x%s [batch failed, deploying contracts individually]",
+				output.Italic(contractNames(allowed)),
+				allowed[0].AccountAddress,
+			))
+			for _, contract := range allowed {
+				txID, status, err := p.deployContract(accounts, contract, network, update, addOptions, deployErr)
+				hooks.after(contract, txID, status, err)
+			}
+			continue
+		}
+
+		failed := ""
+		var batchErr *ContractsDeploymentError
+		if errors.As(err, &batchErr) {
+			failed = batchErr.Contract
+		}
+
+		for _, contract := range allowed {
+			var contractErr error
+			switch {
+			case failed == "":
+				contractErr = deployErr.add(contract, err, fmt.Sprintf("failed to deploy contract %s in batch", contract.Name))
+			case failed == contract.Name:
+				contractErr = deployErr.add(contract, err, fmt.Sprintf("failed to deploy contract %s", contract.Name))
+			default:
+				contractErr = deployErr.add(contract, err, fmt.Sprintf(
+					"contract %s not deployed, batch failed because of contract %s",
+					contract.Name,
+					failed,
+				))
+			}
+			hooks.after(contract, flow.EmptyID, ContractFailed, contractErr)
+		}
+	}
+
+	if len(deployErr.contracts) > 0 {
+		return nil, deployErr
+	}
+
+	p.logger.Info(fmt.Sprintf(
+		"\n%s All contracts deployed successfully in %s",
+		output.SuccessEmoji(),
+		time.Since(deployStart).Round(time.Millisecond),
+	))
+	return sorted, nil
+}
 
-	deployErr := &ProjectDeploymentError{}
-	for _, contract := range sorted {
-		targetAccount, err := p.state.Accounts().ByName(contract.AccountName)
+// deployContract deploys a single contract in its own transaction and records any failure to the deployment error.
+//
+// Contracts already deployed with the same code are skipped.
+func (p *Project) deployContract(
+	accounts *Accounts,
+	contract *project.Contract,
+	network string,
+	update bool,
+	options AddContractOptions,
+	deployErr *ProjectDeploymentError,
+) (flow.Identifier, ContractDeployStatus, error) {
+	targetAccount, err := p.state.Accounts().ByName(contract.AccountName)
+	if err != nil {
+		err = deployErr.add(contract, err, "target account for deploying contract not found in configuration")
+		return flow.EmptyID, ContractFailed, err
+	}
+
+	// special case for emulator updates, where we remove and add a contract because it allows us to have more freedom in changes.
+	// Updating contracts is limited as described in https://developers.flow.com/cadence/language/contract-updatability
+	if update && network == config.DefaultEmulatorNetwork().Name {
+		// ignore failure as it's meant to be best-effort
+		_, _ = accounts.RemoveContractWithOptions(targetAccount, contract.Name, RemoveContractOptions{Network: network})
+	}
+
+	options.SkipIfIdentical = true
+	contractStart := time.Now()
+	txID, updated, err := accounts.AddContractWithOptions(
+		targetAccount,
+		flowkit.NewScript(contract.Code(), contract.Args, contract.Location()),
+		network,
+		update,
+		options,
+	)
+	if err != nil {
+		err = deployErr.add(contract, err, fmt.Sprintf("failed to deploy contract %s", contract.Name))
+		return flow.EmptyID, ContractFailed, err
+	}
+	if txID == flow.EmptyID {
+		p.logger.Info(fmt.Sprintf(
+			"%s -> 0x%s [skipping, no changes found]",
+			output.Italic(contract.Name),
+			contract.AccountAddress.String(),
+		))
+		return flow.EmptyID, ContractUnchanged, nil
+	}
+
+	contract.DeploymentDuration = time.Since(contractStart)
+
+	p.logger.Info(fmt.Sprintf(
+		"%s -> 0x%s (%s) %s [%s]",
+		output.Green(contract.Name),
+		contract.AccountAddress,
+		txID.String(),
+		map[bool]string{true: "[updated]", false: ""}[updated],
+		contract.DeploymentDuration.Round(time.Millisecond),
+	))
+
+	return txID, ContractDeployed, nil
+}
+
+// checkDeploymentAccounts makes sure the accounts targeted by the deployments on the network exist.
+//
+// Missing accounts are created if requested by the options, otherwise a MissingAccountsError listing
+// all the missing accounts is returned. The address of a created account is changed in the state if
+// the network assigned a different address than configured.
+func (p *Project) checkDeploymentAccounts(accounts *Accounts, network string, options DeployOptions) error {
+	missing := make([]*flowkit.Account, 0)
+	for _, deployment := range p.state.Deployments().ByNetwork(network) {
+		account, err := p.state.Accounts().ByName(deployment.Account)
 		if err != nil {
-			return nil, fmt.Errorf("target account for deploying contract not found in configuration")
+			continue // reported when the contracts are resolved
+		}
+
+		_, err = p.gateway.GetAccount(account.Address())
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, gateway.ErrAccountNotFound) {
+			return fmt.Errorf("failed to get deployment account %s: %w", account.Name(), err)
 		}
+		missing = append(missing, account)
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	missingErr := &MissingAccountsError{Network: network}
+	for _, account := range missing {
+		missingErr.Accounts = append(missingErr.Accounts, MissingAccount{Name: account.Name(), Address: account.Address()})
+	}
+
+	if !options.CreateMissing {
+		return missingErr
+	}
 
-		// special case for emulator updates, where we remove and add a contract because it allows us to have more freedom in changes.
-		// Updating contracts is limited as described in https://developers.flow.com/cadence/language/contract-updatability
+	creator := options.Creator
+	if creator == nil && network == config.DefaultEmulatorNetwork().Name {
+		serviceAccount, err := p.state.EmulatorServiceAccount()
+		if err != nil {
+			return err
+		}
+		creator = serviceAccount
+	}
+	if creator == nil {
+		return fmt.Errorf("a creator account paying for the missing accounts is required: %w", missingErr)
+	}
+
+	for _, account := range missing {
+		created, err := p.createAccount(accounts, creator, account, network)
+		if err != nil {
+			return fmt.Errorf("failed to create account %s: %w", account.Name(), err)
+		}
+		p.logger.Info(fmt.Sprintf("%s -> 0x%s [created]", output.Green(account.Name()), created))
+
+		if created != account.Address() {
+			output.Warn(p.logger, fmt.Sprintf(
+				"address of account %s changed from 0x%s to 0x%s",
+				account.Name(),
+				account.Address(),
+				created,
+			))
+			account.SetAddress(created)
+		}
+	}
+
+	return nil
+}
+
+// deployBatch deploys all the contracts in the batch to their account in a single transaction.
+//
+// It returns the transaction ID and the deployed contracts, contracts without changes are not deployed.
+func (p *Project) deployBatch(
+	accounts *Accounts,
+	batch []*project.Contract,
+	network string,
+	update bool,
+	options AddContractOptions,
+) (flow.Identifier, map[string]bool, error) {
+	targetAccount, err := p.state.Accounts().ByName(batch[0].AccountName)
+	if err != nil {
+		return flow.EmptyID, nil, fmt.Errorf("target account for deploying contracts not found in configuration")
+	}
+
+	scripts := make([]*flowkit.Script, len(batch))
+	for i, contract := range batch {
+		// same as with single contract deployment remove the contract on emulator so it can be freely changed
 		if update && network == config.DefaultEmulatorNetwork().Name {
-			_, _ = accounts.RemoveContract(targetAccount, contract.Name) // ignore failure as it's meant to be best-effort
+			// ignore failure as it's meant to be best-effort
+			_, _ = accounts.RemoveContractWithOptions(targetAccount, contract.Name, RemoveContractOptions{Network: network})
 		}
 
-		txID, updated, err := accounts.AddContract(
-			targetAccount,
-			flowkit.NewScript(contract.Code(), contract.Args, contract.Location()),
-			network,
-			update,
-		)
-		if err != nil && errors.Is(err, errUpdateNoDiff) {
+		scripts[i] = flowkit.NewScript(contract.Code(), contract.Args, contract.Location())
+	}
+
+	batchStart := time.Now()
+	txID, deployed, err := accounts.addContracts(targetAccount, scripts, network, update, options)
+	if err != nil && !errors.Is(err, errUpdateNoDiff) {
+		return flow.EmptyID, nil, err
+	}
+
+	duration := time.Since(batchStart)
+	for _, contract := range batch {
+		updated, ok := deployed[contract.Name]
+		if !ok {
 			p.logger.Info(fmt.Sprintf(
 				"%s -> 0x%s [skipping, no changes found]",
 				output.Italic(contract.Name),
 				contract.AccountAddress.String(),
 			))
 			continue
-		} else if err != nil {
-			deployErr.add(contract, err, fmt.Sprintf("failed to deploy contract %s", contract.Name))
-			continue
 		}
 
+		contract.DeploymentDuration = duration
+
 		p.logger.Info(fmt.Sprintf(
-			"%s -> 0x%s (%s) %s",
+			"%s -> 0x%s (%s) %s [%s, batched]",
 			output.Green(contract.Name),
 			contract.AccountAddress,
 			txID.String(),
 			map[bool]string{true: "[updated]", false: ""}[updated],
+			contract.DeploymentDuration.Round(time.Millisecond),
 		))
 	}
 
-	if len(deployErr.contracts) > 0 {
-		return nil, deployErr
+	return txID, deployed, nil
+}
+
+// ErrDeploymentDrift is returned when the contract code on-chain doesn't match any deployment recorded in the lock file.
+var ErrDeploymentDrift = errors.New("on-chain code does not match any recorded deployment")
+
+// DeploymentInfo returns the recorded deployment of the contract matching the code currently on-chain.
+//
+// The contract code is fetched from the account the contract is deployed to on the network and its hash is
+// compared with the deployments recorded in the lock file, so the metadata of the deployment that produced
+// the on-chain code can be found. If no recorded deployment matches ErrDeploymentDrift is returned.
+func (p *Project) DeploymentInfo(network string, contractName string) (record *flowkit.DeploymentRecord, err error) {
+	err = p.diagnostics.run(p.tracing, "Project.DeploymentInfo", []Attribute{
+		{Key: "network", Value: network},
+		{Key: "contract", Value: contractName},
+	}, func(op *operation) error {
+		record, err = p.traced(op).deploymentInfo(network, contractName)
+		return err
+	})
+	return record, err
+}
+
+func (p *Project) deploymentInfo(network string, contractName string) (*flowkit.DeploymentRecord, error) {
+	if p.state == nil {
+		return nil, config.ErrDoesNotExist
 	}
 
-	p.logger.Info(fmt.Sprintf("\n%s All contracts deployed successfully", output.SuccessEmoji()))
-	return sorted, nil
+	contracts, err := p.state.DeploymentContractsByNetwork(network)
+	if err != nil {
+		return nil, err
+	}
+
+	var address flow.Address
+	found := false
+	for _, contract := range contracts {
+		if contract.Name == contractName {
+			address = contract.AccountAddress
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("contract %s is not deployed on network %s", contractName, network)
+	}
+
+	account, err := p.gateway.GetAccount(address)
+	if err != nil {
+		return nil, err
+	}
+
+	code, exists := account.Contracts[contractName]
+	if !exists {
+		return nil, fmt.Errorf("contract %s not found on account 0x%s", contractName, address)
+	}
+	hash := codeHash(code)
+
+	lock, err := p.state.LockFile()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, record := range lock.ByContract(network, contractName) {
+		if record.CodeHash == hash && record.Address == address.String() {
+			return &record, nil
+		}
+	}
+
+	return nil, ErrDeploymentDrift
+}
+
+// Reset removes the project contracts deployed on the network and the deployments on the network recorded in the lock file.
+//
+// Contracts are removed in reverse deployment order, so contracts are removed before the contracts they import.
+// Contracts that are not deployed are skipped.
+func (p *Project) Reset(network string) error {
+	return p.diagnostics.run(p.tracing, "Project.Reset", []Attribute{
+		{Key: "network", Value: network},
+	}, func(op *operation) error {
+		return p.traced(op).reset(network)
+	})
+}
+
+func (p *Project) reset(network string) error {
+	if p.state == nil {
+		return config.ErrDoesNotExist
+	}
+
+	contracts, err := p.state.DeploymentContractsByNetwork(network)
+	if err != nil {
+		return err
+	}
+
+	deployment, err := newDeployment(p.state, contracts)
+	if err != nil {
+		return err
+	}
+
+	sorted, err := deployment.Sort()
+	if err != nil {
+		return err
+	}
+
+	accounts := newSharedAccounts(p.gateway, p.state, output.NewStdoutLogger(output.NoneLog), p.tracing, p.sequences, p.forked)
+
+	deployed := make(map[flow.Address]*flow.Account)
+	for i := len(sorted) - 1; i >= 0; i-- {
+		contract := sorted[i]
+
+		flowAccount, ok := deployed[contract.AccountAddress]
+		if !ok {
+			flowAccount, err = p.gateway.GetAccount(contract.AccountAddress)
+			if err != nil {
+				return fmt.Errorf("failed to get account 0x%s: %w", contract.AccountAddress, err)
+			}
+			deployed[contract.AccountAddress] = flowAccount
+		}
+
+		if _, exists := flowAccount.Contracts[contract.Name]; !exists {
+			continue
+		}
+
+		account, err := p.state.Accounts().ByName(contract.AccountName)
+		if err != nil {
+			return err
+		}
+
+		_, err = accounts.RemoveContractWithOptions(account, contract.Name, RemoveContractOptions{Network: network})
+		if err != nil {
+			return fmt.Errorf("failed to remove contract %s: %w", contract.Name, err)
+		}
+
+		p.logger.Info(fmt.Sprintf("%s -> 0x%s [removed]", output.Red(contract.Name), contract.AccountAddress))
+	}
+
+	lock, err := p.state.LockFile()
+	if err != nil {
+		return err
+	}
+	recorded := len(lock.Deployments)
+	lock.RemoveNetwork(network)
+	if len(lock.Deployments) == recorded {
+		return nil
+	}
+
+	return p.state.SaveLockFile(lock)
+}
+
+func contractNames(contracts []*project.Contract) string {
+	names := make([]string, len(contracts))
+	for i, contract := range contracts {
+		names[i] = contract.Name
+	}
+	return strings.Join(names, ", ")
 }
 
 type ProjectDeploymentError struct {
 	contracts map[string]error
+	batch     *flowkit.BatchError
+	indexes   map[string]int
+}
+
+// newProjectDeploymentError returns a deployment error aggregating the failures of the contracts in deployment order.
+func newProjectDeploymentError(contracts []*project.Contract) *ProjectDeploymentError {
+	names := make([]string, len(contracts))
+	indexes := make(map[string]int, len(contracts))
+	for i, contract := range contracts {
+		names[i] = contract.Name
+		indexes[contract.Name] = i
+	}
+
+	return &ProjectDeploymentError{
+		contracts: make(map[string]error),
+		batch:     flowkit.NewBatchError(names...),
+		indexes:   indexes,
+	}
 }
 
-func (d *ProjectDeploymentError) add(contract *project.Contract, err error, msg string) {
+func (d *ProjectDeploymentError) add(contract *project.Contract, err error, msg string) error {
 	if d.contracts == nil {
 		d.contracts = make(map[string]error)
 	}
 	d.contracts[contract.Name] = fmt.Errorf("%s: %w", msg, err)
+	if i, ok := d.indexes[contract.Name]; ok {
+		d.batch.Fail(i, d.contracts[contract.Name])
+	}
+	return d.contracts[contract.Name]
 }
 
 func (d *ProjectDeploymentError) Contracts() map[string]error {
 	return d.contracts
 }
 
+// Unwrap returns the batch error with the failures of the contracts in deployment order.
+func (d *ProjectDeploymentError) Unwrap() error {
+	if d.batch == nil {
+		return nil
+	}
+	return d.batch
+}
+
 func (d *ProjectDeploymentError) Error() string {
+	if d.batch != nil {
+		return d.batch.Error()
+	}
+
 	err := ""
 	for c, e := range d.contracts {
 		err = fmt.Sprintf("%s %s: %s,", err, c, e.Error())
@@ -19,17 +19,28 @@
 package services
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/flow-go-sdk"
 	"github.com/onflow/flow-go-sdk/crypto"
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"github.com/onflow/flow-cli/pkg/flowkit"
 	"github.com/onflow/flow-cli/pkg/flowkit/config"
+	"github.com/onflow/flow-cli/pkg/flowkit/gateway"
+	"github.com/onflow/flow-cli/pkg/flowkit/output"
 	"github.com/onflow/flow-cli/pkg/flowkit/project"
 	"github.com/onflow/flow-cli/pkg/flowkit/tests"
 )
@@ -84,6 +95,7 @@ func TestProject(t *testing.T) {
 
 		a := tests.Alice()
 		state.Accounts().AddOrUpdate(a)
+		gw.GetAccount.Run(nil).Return(onChainAccount(a), nil)
 
 		d := config.Deployment{
 			Network: n.Name,
@@ -107,11 +119,40 @@ func TestProject(t *testing.T) {
 
 		assert.NoError(t, err)
 		assert.Equal(t, len(contracts), 1)
+		assert.Greater(t, contracts[0].DeploymentDuration, time.Duration(0))
 		gw.Mock.AssertCalled(t, tests.GetLatestBlockFunc)
 		gw.Mock.AssertCalled(t, tests.GetAccountFunc, a.Address())
 		gw.Mock.AssertNumberOfCalls(t, tests.GetTransactionResultFunc, 1)
 	})
 
+	t.Run("Deploy Project Missing Contract File", func(t *testing.T) {
+		t.Parallel()
+
+		state, s, gw := setup()
+
+		state.Contracts().AddOrUpdate("Missing", config.Contract{
+			Name:     "Missing",
+			Location: "missing.cdc",
+			Network:  "emulator",
+		})
+
+		a := tests.Alice()
+		state.Accounts().AddOrUpdate(a)
+		state.Deployments().AddOrUpdate(config.Deployment{
+			Network:   "emulator",
+			Account:   a.Name(),
+			Contracts: []config.ContractDeployment{{Name: "Missing"}},
+		})
+
+		_, err := s.Project.Deploy("emulator", false)
+
+		var validationErr *flowkit.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, map[string]string{"Missing": "missing.cdc"}, validationErr.MissingContracts)
+		gw.Mock.AssertNotCalled(t, tests.GetLatestBlockFunc)
+		gw.Mock.AssertNotCalled(t, tests.GetAccountFunc, a.Address())
+	})
+
 	t.Run("Deploy Project Duplicate Address", func(t *testing.T) {
 		t.Parallel()
 
@@ -135,6 +176,7 @@ func TestProject(t *testing.T) {
 
 		acct2 := tests.Donald()
 		state.Accounts().AddOrUpdate(acct2)
+		gw.GetAccount.Run(nil).Return(onChainAccount(acct2), nil)
 
 		d := config.Deployment{
 			Network: n.Name,
@@ -161,6 +203,77 @@ func TestProject(t *testing.T) {
 		assert.Equal(t, contracts[0].AccountAddress, acct2.Address())
 	})
 
+	t.Run("Deploy Project Complex Args", func(t *testing.T) {
+		t.Parallel()
+
+		state, s, gw := setup()
+
+		c := config.Contract{
+			Name:     tests.ContractRates.Name,
+			Location: tests.ContractRates.Filename,
+			Network:  "emulator",
+		}
+		state.Contracts().AddOrUpdate(c.Name, c)
+
+		a := tests.Alice()
+		state.Accounts().AddOrUpdate(a)
+		gw.GetAccount.Run(nil).Return(onChainAccount(a), nil)
+
+		args := []cadence.Value{
+			cadence.NewDictionary([]cadence.KeyValuePair{{
+				Key:   cadence.String("USD"),
+				Value: cadence.UFix64(100000000),
+			}}),
+			cadence.NewOptional(cadence.UFix64(50000000)),
+			cadence.NewArray([]cadence.Value{cadence.String("USD")}),
+		}
+		state.Deployments().AddOrUpdate(config.Deployment{
+			Network: "emulator",
+			Account: a.Name(),
+			Contracts: []config.ContractDeployment{{
+				Name: c.Name,
+				Args: args,
+			}},
+		})
+
+		gw.SendSignedTransaction.Run(func(mockArgs mock.Arguments) {
+			tx := mockArgs.Get(0).(*flowkit.Transaction).FlowTransaction()
+
+			// the name and code arguments are followed by the contract arguments
+			require.Len(t, tx.Arguments, 5)
+			for i, arg := range args {
+				assert.JSONEq(t, string(jsoncdc.MustEncode(arg)), string(tx.Arguments[i+2]))
+			}
+			assert.Contains(t, string(tx.Script), "arg0:{String: UFix64},arg1:UFix64?,arg2:[String]")
+
+			gw.SendSignedTransaction.Return(tests.NewTransaction(), nil)
+		})
+
+		_, err := s.Project.Deploy("emulator", false)
+		assert.NoError(t, err)
+		gw.Mock.AssertNumberOfCalls(t, tests.SendSignedTransactionFunc, 1)
+	})
+
+}
+
+// onChainAccount returns the account as it exists on the network, with its configured key as the only key.
+func onChainAccount(account *flowkit.Account) *flow.Account {
+	privateKey, err := account.Key().PrivateKey()
+	if err != nil {
+		panic(err)
+	}
+
+	return &flow.Account{
+		Address: account.Address(),
+		Keys: []*flow.AccountKey{{
+			Index:     account.Key().Index(),
+			PublicKey: (*privateKey).PublicKey(),
+			SigAlgo:   account.Key().SigAlgo(),
+			HashAlgo:  account.Key().HashAlgo(),
+			Weight:    flow.AccountKeyWeightThreshold,
+		}},
+		Contracts: make(map[string][]byte),
+	}
 }
 
 // used for integration tests
@@ -207,6 +320,36 @@ func TestProject_Integration(t *testing.T) {
 		assert.Equal(t, string(contracts[0].Code()), string(tests.ContractHelloString.Source))
 	})
 
+	t.Run("Deploy Runtime Error", func(t *testing.T) {
+		t.Parallel()
+
+		state, s := setupIntegration()
+		srvAcc, _ := state.EmulatorServiceAccount()
+		err := state.ReaderWriter().WriteFile("assert.cdc", []byte(`pub contract Assert {
+			init() {
+				assert(1 == 2, message: "one is not two")
+			}
+		}`), 0644)
+		require.NoError(t, err)
+
+		state.Contracts().AddOrUpdate("Assert", config.Contract{Name: "Assert", Location: "assert.cdc", Network: "emulator"})
+		state.Networks().AddOrUpdate("emulator", config.DefaultEmulatorNetwork())
+		state.Deployments().AddOrUpdate(config.Deployment{
+			Network:   "emulator",
+			Account:   srvAcc.Name(),
+			Contracts: []config.ContractDeployment{{Name: "Assert"}},
+		})
+
+		_, err = s.Project.Deploy("emulator", false)
+		var runtimeErr *flowkit.CadenceRuntimeError
+		require.ErrorAs(t, err, &runtimeErr)
+		assert.Equal(t, flowkit.CadenceErrorAssertion, runtimeErr.Kind)
+		assert.Equal(t, "one is not two", runtimeErr.Message)
+		_, name, ok := runtimeErr.Location().Contract()
+		assert.True(t, ok)
+		assert.Equal(t, "Assert", name)
+	})
+
 	t.Run("Deploy Complex Project", func(t *testing.T) {
 		t.Parallel()
 
@@ -258,6 +401,9 @@ func TestProject_Integration(t *testing.T) {
 		contracts, err := s.Project.Deploy(n.Name, false)
 		assert.NoError(t, err)
 		assert.Len(t, contracts, 3)
+		for _, c := range contracts {
+			assert.Greater(t, c.DeploymentDuration, time.Duration(0))
+		}
 
 		account, err := s.Accounts.Get(srvAcc.Address())
 
@@ -268,6 +414,98 @@ func TestProject_Integration(t *testing.T) {
 		}
 	})
 
+	t.Run("Deploy Project Batched", func(t *testing.T) {
+		t.Parallel()
+
+		state, s := setupIntegration()
+		srvAcc, _ := state.EmulatorServiceAccount()
+
+		n := config.DefaultEmulatorNetwork()
+		state.Networks().AddOrUpdate(n.Name, n)
+
+		contractFixtures := []tests.Resource{
+			tests.ContractHelloString, tests.ContractSimple, tests.ContractA,
+		}
+
+		deployments := make([]config.ContractDeployment, len(contractFixtures))
+		for i, c := range contractFixtures {
+			state.Contracts().AddOrUpdate(c.Name, config.Contract{
+				Name:     c.Name,
+				Location: c.Filename,
+				Network:  n.Name,
+			})
+			deployments[i] = config.ContractDeployment{Name: c.Name}
+		}
+
+		state.Deployments().AddOrUpdate(config.Deployment{
+			Network:   n.Name,
+			Account:   srvAcc.Name(),
+			Contracts: deployments,
+		})
+
+		contracts, err := s.Project.DeployWithOptions(n.Name, false, DeployOptions{Batch: true})
+		assert.NoError(t, err)
+		assert.Len(t, contracts, 3)
+		for _, c := range contracts {
+			// all contracts share the duration of the single batch transaction
+			assert.Equal(t, contracts[0].DeploymentDuration, c.DeploymentDuration)
+		}
+
+		account, err := s.Accounts.Get(srvAcc.Address())
+		assert.NoError(t, err)
+		for _, c := range contractFixtures {
+			code, exists := account.Contracts[c.Name]
+			assert.True(t, exists)
+			assert.Equal(t, string(c.Source), string(code))
+		}
+	})
+
+	t.Run("Deploy Project Progress", func(t *testing.T) {
+		t.Parallel()
+
+		state, s := setupIntegration()
+		logger := &recordingLogger{Logger: output.NewStdoutLogger(output.NoneLog)}
+		s.SetLogger(logger)
+		srvAcc, _ := state.EmulatorServiceAccount()
+
+		n := config.DefaultEmulatorNetwork()
+		state.Networks().AddOrUpdate(n.Name, n)
+
+		contractFixtures := []tests.Resource{
+			tests.ContractHelloString, tests.ContractSimple, tests.ContractA,
+		}
+
+		deployments := make([]config.ContractDeployment, len(contractFixtures))
+		for i, c := range contractFixtures {
+			state.Contracts().AddOrUpdate(c.Name, config.Contract{
+				Name:     c.Name,
+				Location: c.Filename,
+				Network:  n.Name,
+			})
+			deployments[i] = config.ContractDeployment{Name: c.Name}
+		}
+
+		state.Deployments().AddOrUpdate(config.Deployment{
+			Network:   n.Name,
+			Account:   srvAcc.Name(),
+			Contracts: deployments,
+		})
+
+		contracts, err := s.Project.Deploy(n.Name, false)
+		assert.NoError(t, err)
+		assert.Len(t, contracts, 3)
+
+		require.Len(t, logger.progress, 1)
+		progress := logger.progress[0]
+		assert.Equal(t, "Deploying contracts", progress.label)
+		assert.Equal(t, 3, progress.total)
+		assert.True(t, progress.completed)
+		require.Len(t, progress.steps, 3)
+		for i, c := range contracts {
+			assert.Equal(t, fmt.Sprintf("Deploying %s", c.Name), progress.steps[i])
+		}
+	})
+
 	t.Run("Deploy Project Update", func(t *testing.T) {
 		t.Parallel()
 
@@ -280,4 +518,1861 @@ func TestProject_Integration(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	// hooksDeploy configures contracts A, B and C, where B imports A and C imports A and B, and the independent Hello contract
+	hooksDeploy := func(state *flowkit.State) (*flowkit.Account, string) {
+		srvAcc, _ := state.EmulatorServiceAccount()
+
+		n := config.DefaultEmulatorNetwork()
+		state.Networks().AddOrUpdate(n.Name, n)
+
+		contractFixtures := []tests.Resource{
+			tests.ContractA, tests.ContractB, tests.ContractC, tests.ContractHelloString,
+		}
+
+		deployments := make([]config.ContractDeployment, len(contractFixtures))
+		for i, c := range contractFixtures {
+			state.Contracts().AddOrUpdate(c.Name, config.Contract{
+				Name:     c.Name,
+				Location: c.Filename,
+				Network:  n.Name,
+			})
+			deployments[i] = config.ContractDeployment{Name: c.Name}
+		}
+		deployments[2].Args = []cadence.Value{cadence.String("foo")}
+
+		state.Deployments().AddOrUpdate(config.Deployment{
+			Network:   n.Name,
+			Account:   srvAcc.Name(),
+			Contracts: deployments,
+		})
+
+		return srvAcc, n.Name
+	}
+
+	t.Run("Deploy Project Hooks", func(t *testing.T) {
+		t.Parallel()
+
+		state, s := setupIntegration()
+		srvAcc, network := hooksDeploy(state)
+
+		var plans []ContractPlan
+		results := make(map[string]ContractResult)
+		options := DeployOptions{
+			BeforeContract: func(plan ContractPlan) error {
+				plans = append(plans, plan)
+				return nil
+			},
+			AfterContract: func(result ContractResult) {
+				results[result.Name] = result
+			},
+		}
+
+		_, err := s.Project.DeployWithOptions(network, false, options)
+		require.NoError(t, err)
+
+		require.Len(t, plans, 4)
+		order := make(map[string]int)
+		for i, plan := range plans {
+			order[plan.Name] = i
+			assert.Equal(t, srvAcc.Address(), plan.Address)
+			assert.Equal(t, srvAcc.Name(), plan.AccountName)
+			assert.False(t, plan.Update)
+		}
+		assert.Less(t, order["ContractA"], order["ContractB"])
+		assert.Less(t, order["ContractB"], order["ContractC"])
+		assert.Equal(t, []cadence.Value{cadence.String("foo")}, plans[order["ContractC"]].Args)
+
+		account, err := s.Accounts.Get(srvAcc.Address())
+		require.NoError(t, err)
+
+		require.Len(t, results, 4)
+		for name, result := range results {
+			hash := sha256.Sum256(account.Contracts[name])
+			assert.Equal(t, hex.EncodeToString(hash[:]), result.CodeHash)
+			assert.Equal(t, ContractDeployed, result.Status)
+			assert.NotEqual(t, flow.EmptyID, result.TxID)
+			assert.NoError(t, result.Err)
+		}
+
+		plans = nil
+		_, err = s.Project.DeployWithOptions(network, true, options)
+		require.NoError(t, err)
+		require.Len(t, plans, 4)
+		for _, plan := range plans {
+			assert.True(t, plan.Update)
+		}
+	})
+
+	t.Run("Deploy Project Hook Veto", func(t *testing.T) {
+		t.Parallel()
+
+		state, s := setupIntegration()
+		srvAcc, network := hooksDeploy(state)
+
+		var planned []string
+		results := make(map[string]ContractResult)
+		_, err := s.Project.DeployWithOptions(network, false, DeployOptions{
+			BeforeContract: func(plan ContractPlan) error {
+				planned = append(planned, plan.Name)
+				if plan.Name == "ContractB" {
+					return fmt.Errorf("not on the allowlist")
+				}
+				return nil
+			},
+			AfterContract: func(result ContractResult) {
+				results[result.Name] = result
+			},
+		})
+
+		var deployErr *ProjectDeploymentError
+		require.ErrorAs(t, err, &deployErr)
+		require.Len(t, deployErr.Contracts(), 2)
+
+		var hookErr *DeployHookError
+		require.ErrorAs(t, deployErr.Contracts()["ContractB"], &hookErr)
+		assert.Equal(t, "BeforeContract", hookErr.Hook)
+		assert.Equal(t, "ContractB", hookErr.Contract)
+		assert.EqualError(t, hookErr.Err, "not on the allowlist")
+		assert.Contains(t, deployErr.Contracts()["ContractC"].Error(), "depends on vetoed contract ContractB")
+
+		// failures are also reported as a batch in deployment order
+		var batchErr *flowkit.BatchError
+		require.ErrorAs(t, err, &batchErr)
+		assert.Equal(t, 2, batchErr.Failed())
+		assert.Equal(t, 2, batchErr.Succeeded())
+		assert.True(t, strings.HasPrefix(err.Error(), "2 of 4 items failed:\n  ContractB: contract ContractB skipped"))
+
+		// the hook isn't called for dependents of a vetoed contract
+		assert.ElementsMatch(t, []string{"ContractA", "ContractB", "Hello"}, planned)
+
+		assert.Equal(t, ContractDeployed, results["ContractA"].Status)
+		assert.Equal(t, ContractDeployed, results["Hello"].Status)
+		assert.Equal(t, ContractSkipped, results["ContractB"].Status)
+		assert.Equal(t, ContractSkipped, results["ContractC"].Status)
+		assert.Error(t, results["ContractC"].Err)
+
+		account, err := s.Accounts.Get(srvAcc.Address())
+		require.NoError(t, err)
+		assert.Contains(t, account.Contracts, "ContractA")
+		assert.Contains(t, account.Contracts, "Hello")
+		assert.NotContains(t, account.Contracts, "ContractB")
+		assert.NotContains(t, account.Contracts, "ContractC")
+	})
+
+	t.Run("Deploy Project Hook Panic", func(t *testing.T) {
+		t.Parallel()
+
+		state, s := setupIntegration()
+		srvAcc, network := hooksDeploy(state)
+
+		_, err := s.Project.DeployWithOptions(network, false, DeployOptions{
+			BeforeContract: func(plan ContractPlan) error {
+				if plan.Name == "Hello" {
+					panic("hook bug")
+				}
+				return nil
+			},
+			AfterContract: func(result ContractResult) {
+				panic("hook bug")
+			},
+		})
+
+		var deployErr *ProjectDeploymentError
+		require.ErrorAs(t, err, &deployErr)
+		require.Len(t, deployErr.Contracts(), 1)
+
+		var hookErr *DeployHookError
+		require.ErrorAs(t, deployErr.Contracts()["Hello"], &hookErr)
+		assert.EqualError(t, hookErr.Err, "panic: hook bug")
+
+		account, err := s.Accounts.Get(srvAcc.Address())
+		require.NoError(t, err)
+		assert.Contains(t, account.Contracts, "ContractA")
+		assert.Contains(t, account.Contracts, "ContractB")
+		assert.Contains(t, account.Contracts, "ContractC")
+		assert.NotContains(t, account.Contracts, "Hello")
+	})
+
+	t.Run("Deployment Info", func(t *testing.T) {
+		t.Parallel()
+
+		state, s := setupIntegration()
+		srvAcc, network := hooksDeploy(state)
+
+		metadata := map[string]string{"commit": "3f2a1b", "ci.run-url": "https://ci.example.com/runs/1"}
+		_, err := s.Project.DeployWithOptions(network, false, DeployOptions{Metadata: metadata})
+		require.NoError(t, err)
+
+		lock, err := state.LockFile()
+		require.NoError(t, err)
+		require.Len(t, lock.Deployments, 4)
+		for _, record := range lock.Deployments {
+			assert.Equal(t, network, record.Network)
+			assert.Equal(t, srvAcc.Address().String(), record.Address)
+			assert.Equal(t, metadata, record.Metadata)
+		}
+
+		info, err := s.Project.DeploymentInfo(network, "ContractB")
+		require.NoError(t, err)
+		assert.Equal(t, "ContractB", info.Contract)
+		assert.Equal(t, metadata, info.Metadata)
+
+		account, err := s.Accounts.Get(srvAcc.Address())
+		require.NoError(t, err)
+		hash := sha256.Sum256(account.Contracts["ContractB"])
+		assert.Equal(t, hex.EncodeToString(hash[:]), info.CodeHash)
+
+		// updating the code without recording the deployment causes a drift
+		_, err = s.Accounts.SetCode(srvAcc, map[string][]byte{
+			"ContractA": []byte(`
+				pub contract ContractA {
+					pub fun foo() {}
+				}
+			`),
+		}, network)
+		require.NoError(t, err)
+
+		_, err = s.Project.DeploymentInfo(network, "ContractA")
+		assert.ErrorIs(t, err, ErrDeploymentDrift)
+
+		_, err = s.Project.DeploymentInfo(network, "Foo")
+		assert.EqualError(t, err, "contract Foo is not deployed on network emulator")
+	})
+
+	t.Run("Reset", func(t *testing.T) {
+		t.Parallel()
+
+		state, s := setupIntegration()
+		srvAcc, network := hooksDeploy(state)
+
+		_, err := s.Project.Deploy(network, false)
+		require.NoError(t, err)
+
+		// a recorded deployment on another network is kept
+		require.NoError(t, state.RecordDeployment(flowkit.DeploymentRecord{Network: "testnet", Contract: "ContractA"}))
+
+		err = s.Project.Reset(network)
+		require.NoError(t, err)
+
+		account, err := s.Accounts.Get(srvAcc.Address())
+		require.NoError(t, err)
+		for _, name := range []string{"ContractA", "ContractB", "ContractC", tests.ContractHelloString.Name} {
+			assert.NotContains(t, account.Contracts, name)
+		}
+
+		lock, err := state.LockFile()
+		require.NoError(t, err)
+		require.Len(t, lock.Deployments, 1)
+		assert.Equal(t, "testnet", lock.Deployments[0].Network)
+
+		// resetting again is a no-op and the project can be deployed again
+		require.NoError(t, s.Project.Reset(network))
+
+		contracts, err := s.Project.Deploy(network, false)
+		require.NoError(t, err)
+		assert.Len(t, contracts, 4)
+	})
+
+	t.Run("Reset Partially Deployed", func(t *testing.T) {
+		t.Parallel()
+
+		state, s := setupIntegration()
+		srvAcc, network := hooksDeploy(state)
+
+		_, _, err := s.Accounts.AddContract(
+			srvAcc,
+			flowkit.NewScript(tests.ContractHelloString.Source, nil, tests.ContractHelloString.Filename),
+			network,
+			false,
+		)
+		require.NoError(t, err)
+
+		err = s.Project.Reset(network)
+		require.NoError(t, err)
+
+		account, err := s.Accounts.Get(srvAcc.Address())
+		require.NoError(t, err)
+		assert.NotContains(t, account.Contracts, tests.ContractHelloString.Name)
+	})
+
+	t.Run("Deploy Project Complex Args", func(t *testing.T) {
+		t.Parallel()
+
+		state, s := setupIntegration()
+		srvAcc, _ := state.EmulatorServiceAccount()
+
+		n := config.DefaultEmulatorNetwork()
+		state.Networks().AddOrUpdate(n.Name, n)
+		state.Contracts().AddOrUpdate(tests.ContractRates.Name, config.Contract{
+			Name:     tests.ContractRates.Name,
+			Location: tests.ContractRates.Filename,
+			Network:  n.Name,
+		})
+		state.Deployments().AddOrUpdate(config.Deployment{
+			Network: n.Name,
+			Account: srvAcc.Name(),
+			Contracts: []config.ContractDeployment{{
+				Name: tests.ContractRates.Name,
+				Args: []cadence.Value{
+					cadence.NewDictionary([]cadence.KeyValuePair{{
+						Key:   cadence.String("USD"),
+						Value: cadence.UFix64(100000000),
+					}}),
+					cadence.NewOptional(nil),
+					cadence.NewArray([]cadence.Value{cadence.String("USD"), cadence.String("EUR")}),
+				},
+			}},
+		})
+
+		_, err := s.Project.Deploy(n.Name, false)
+		require.NoError(t, err)
+
+		script := fmt.Sprintf(`
+			import Rates from 0x%s
+			pub fun main(): UFix64 {
+				return Rates.rates["USD"]!
+			}
+		`, srvAcc.Address())
+		value, err := s.Scripts.Execute(flowkit.NewScript([]byte(script), nil, ""), n.Name)
+		require.NoError(t, err)
+		assert.Equal(t, cadence.UFix64(100000000), value)
+	})
+
+	t.Run("Deploy Project Invalid Metadata", func(t *testing.T) {
+		t.Parallel()
+
+		state, s := setupIntegration()
+		_, network := hooksDeploy(state)
+
+		_, err := s.Project.DeployWithOptions(network, false, DeployOptions{Metadata: map[string]string{"commit sha": "3f2a1b"}})
+		assert.ErrorContains(t, err, `invalid deployment metadata key "commit sha"`)
+
+		lock, err := state.LockFile()
+		require.NoError(t, err)
+		assert.Len(t, lock.Deployments, 0)
+	})
+
+}
+
+// recordingLogger records all the progress steps, debugs, infos and warnings reported to it.
+type recordingLogger struct {
+	output.Logger
+	progress []*recordingProgressSteps
+	debugs   []string
+	infos    []string
+	warnings []string
+}
+
+func (r *recordingLogger) Debug(msg string) {
+	r.debugs = append(r.debugs, msg)
+}
+
+func (r *recordingLogger) Info(msg string) {
+	r.infos = append(r.infos, msg)
+}
+
+func (r *recordingLogger) Warn(msg string) {
+	r.warnings = append(r.warnings, msg)
+}
+
+func (r *recordingLogger) StartProgressSteps(label string, total int) output.ProgressSteps {
+	steps := &recordingProgressSteps{label: label, total: total}
+	r.progress = append(r.progress, steps)
+	return steps
+}
+
+type recordingProgressSteps struct {
+	label     string
+	total     int
+	steps     []string
+	completed bool
+}
+
+func (r *recordingProgressSteps) Increment(stepLabel string) {
+	r.steps = append(r.steps, stepLabel)
+}
+
+func (r *recordingProgressSteps) Complete() {
+	r.completed = true
+}
+
+func TestProjectBootstrap_Integration(t *testing.T) {
+	t.Parallel()
+
+	// alice is configured at the address of the first account the emulator creates, bob isn't
+	aliceAddress := flow.HexToAddress("01cf0e2f2f715450")
+	bobAddress := flow.HexToAddress("e03daebed8ca0615")
+
+	setupBootstrap := func(t *testing.T) (*flowkit.State, *Services) {
+		state, s := setupIntegration()
+
+		for name, address := range map[string]flow.Address{"alice": aliceAddress, "bob": bobAddress} {
+			key, err := s.Keys.Generate("", crypto.ECDSA_P256)
+			require.NoError(t, err)
+			state.Accounts().AddOrUpdate(flowkit.NewAccount(name).
+				SetAddress(address).
+				SetKey(flowkit.NewHexAccountKeyFromPrivateKey(0, crypto.SHA3_256, key)),
+			)
+		}
+
+		// accounts of other networks are ignored
+		state.Accounts().AddOrUpdate(flowkit.NewAccount("testnet-account").
+			SetAddress(flow.HexToAddress("0x72ddb3d2cec14114")),
+		)
+
+		c := config.Contract{
+			Name:     tests.ContractHelloString.Name,
+			Location: tests.ContractHelloString.Filename,
+			Network:  "emulator",
+		}
+		state.Contracts().AddOrUpdate(c.Name, c)
+		state.Networks().AddOrUpdate("emulator", config.DefaultEmulatorNetwork())
+		state.Deployments().AddOrUpdate(config.Deployment{
+			Network:   "emulator",
+			Account:   "alice",
+			Contracts: []config.ContractDeployment{{Name: c.Name}},
+		})
+
+		return state, s
+	}
+
+	t.Run("Address Mismatch", func(t *testing.T) {
+		t.Parallel()
+
+		state, s := setupBootstrap(t)
+
+		result, err := s.Project.Bootstrap("emulator", BootstrapOptions{})
+
+		var mismatchErr *AddressMismatchError
+		require.ErrorAs(t, err, &mismatchErr)
+		require.Len(t, mismatchErr.Mismatches, 1)
+		assert.Equal(t, "bob", mismatchErr.Mismatches[0].Account)
+		assert.Equal(t, bobAddress, mismatchErr.Mismatches[0].Configured)
+		assert.ElementsMatch(t, []string{"alice", "bob"}, result.Created)
+
+		bob, _ := state.Accounts().ByName("bob")
+		assert.Equal(t, bobAddress, bob.Address())
+	})
+
+	t.Run("Configured Address Taken", func(t *testing.T) {
+		t.Parallel()
+
+		state, s := setupBootstrap(t)
+		serviceAccount, _ := state.EmulatorServiceAccount()
+
+		// another account with other keys is created at the configured address of alice
+		created, err := s.Accounts.CreateForNetwork(
+			serviceAccount,
+			[]crypto.PublicKey{tests.PubKeys()[0]},
+			[]int{flow.AccountKeyWeightThreshold},
+			[]crypto.SignatureAlgorithm{crypto.ECDSA_P256},
+			[]crypto.HashAlgorithm{crypto.SHA3_256},
+			nil,
+			"emulator",
+		)
+		require.NoError(t, err)
+		require.Equal(t, aliceAddress, created.Address)
+
+		result, err := s.Project.Bootstrap("emulator", BootstrapOptions{})
+
+		var mismatchErr *AddressMismatchError
+		require.ErrorAs(t, err, &mismatchErr)
+		assert.Empty(t, result.Existing)
+		assert.ElementsMatch(t, []string{"alice", "bob"}, result.Created)
+		require.Len(t, mismatchErr.Mismatches, 2)
+		assert.Equal(t, "alice", mismatchErr.Mismatches[0].Account)
+		assert.Equal(t, aliceAddress, mismatchErr.Mismatches[0].Configured)
+		assert.NotEqual(t, aliceAddress, mismatchErr.Mismatches[0].Created)
+	})
+
+	t.Run("Not Emulator", func(t *testing.T) {
+		t.Parallel()
+
+		_, s := setupBootstrap(t)
+
+		_, err := s.Project.Bootstrap("testnet", BootstrapOptions{})
+		assert.EqualError(t, err, "bootstrap is only supported on the emulator network")
+	})
+
+	t.Run("Bootstrap Twice", func(t *testing.T) {
+		t.Parallel()
+
+		state, s := setupBootstrap(t)
+		options := BootstrapOptions{
+			FundAmount:       cadence.UFix64(10_00000000),
+			RewriteAddresses: true,
+		}
+
+		result, err := s.Project.Bootstrap("emulator", options)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"alice", "bob"}, result.Created)
+		require.Len(t, result.Mismatches, 1)
+
+		bob, _ := state.Accounts().ByName("bob")
+		assert.Equal(t, result.Mismatches[0].Created, bob.Address())
+
+		alice, err := s.Accounts.Get(aliceAddress)
+		require.NoError(t, err)
+		assert.Contains(t, alice.Contracts, tests.ContractHelloString.Name)
+		assert.GreaterOrEqual(t, alice.Balance, uint64(10_00000000))
+
+		height, err := s.Blocks.GetLatestBlockHeight()
+		require.NoError(t, err)
+
+		statuses := make([]ContractDeployStatus, 0)
+		options.Deploy.AfterContract = func(result ContractResult) {
+			statuses = append(statuses, result.Status)
+		}
+
+		result, err = s.Project.Bootstrap("emulator", options)
+		require.NoError(t, err)
+		assert.Empty(t, result.Created)
+		assert.Empty(t, result.Mismatches)
+		assert.ElementsMatch(t, []string{"alice", "bob"}, result.Existing)
+		assert.Equal(t, []ContractDeployStatus{ContractUnchanged}, statuses)
+
+		// no transactions were sent
+		afterHeight, err := s.Blocks.GetLatestBlockHeight()
+		require.NoError(t, err)
+		assert.Equal(t, height, afterHeight)
+	})
+}
+
+func TestProjectDeployMissingAccounts_Integration(t *testing.T) {
+	t.Parallel()
+
+	// addresses of the first two accounts the emulator creates
+	aliceAddress := flow.HexToAddress("01cf0e2f2f715450")
+	bobAddress := flow.HexToAddress("179b6b1cb6755e31")
+
+	setupMissing := func(t *testing.T, network string) (*flowkit.State, *Services) {
+		state, s := setupIntegration()
+		state.Networks().AddOrUpdate(network, config.Network{Name: network, Host: config.DefaultEmulatorNetwork().Host})
+
+		// missing accounts are created in deployment order, so alice must be added first
+		names := []string{"alice", "bob"}
+		fixtures := []tests.Resource{tests.ContractHelloString, tests.ContractSimple}
+		for i, address := range []flow.Address{aliceAddress, bobAddress} {
+			name := names[i]
+			key, err := s.Keys.Generate("", crypto.ECDSA_P256)
+			require.NoError(t, err)
+			state.Accounts().AddOrUpdate(flowkit.NewAccount(name).
+				SetAddress(address).
+				SetKey(flowkit.NewHexAccountKeyFromPrivateKey(0, crypto.SHA3_256, key)),
+			)
+
+			c := fixtures[i]
+			state.Contracts().AddOrUpdate(c.Name, config.Contract{Name: c.Name, Location: c.Filename, Network: network})
+			state.Deployments().AddOrUpdate(config.Deployment{
+				Network:   network,
+				Account:   name,
+				Contracts: []config.ContractDeployment{{Name: c.Name}},
+			})
+		}
+
+		return state, s
+	}
+
+	t.Run("Missing Accounts Error", func(t *testing.T) {
+		t.Parallel()
+		_, s := setupMissing(t, "emulator")
+
+		_, err := s.Project.Deploy("emulator", false)
+
+		var missingErr *MissingAccountsError
+		require.ErrorAs(t, err, &missingErr)
+		assert.ElementsMatch(t, []MissingAccount{
+			{Name: "alice", Address: aliceAddress},
+			{Name: "bob", Address: bobAddress},
+		}, missingErr.Accounts)
+		assert.Contains(t, err.Error(), "account 'alice' (0x01cf0e2f2f715450)")
+		assert.Contains(t, err.Error(), "do not exist on emulator — create them or run with --create-missing")
+
+		_, err = s.Accounts.Get(aliceAddress)
+		assert.Error(t, err)
+	})
+
+	t.Run("Create Missing", func(t *testing.T) {
+		t.Parallel()
+		_, s := setupMissing(t, "emulator")
+
+		contracts, err := s.Project.DeployWithOptions("emulator", false, DeployOptions{CreateMissing: true})
+		require.NoError(t, err)
+		assert.Len(t, contracts, 2)
+
+		alice, err := s.Accounts.Get(aliceAddress)
+		require.NoError(t, err)
+		assert.Contains(t, alice.Contracts, tests.ContractHelloString.Name)
+
+		bob, err := s.Accounts.Get(bobAddress)
+		require.NoError(t, err)
+		assert.Contains(t, bob.Contracts, tests.ContractSimple.Name)
+	})
+
+	t.Run("One Account Exists", func(t *testing.T) {
+		t.Parallel()
+		state, s := setupMissing(t, "emulator")
+
+		alice, err := state.Accounts().ByName("alice")
+		require.NoError(t, err)
+		srvAcc, _ := state.EmulatorServiceAccount()
+		created, err := s.Project.createAccount(s.Accounts, srvAcc, alice, "emulator")
+		require.NoError(t, err)
+		require.Equal(t, aliceAddress, created)
+
+		_, err = s.Project.Deploy("emulator", false)
+		assert.EqualError(t, err, "account 'bob' (0x179b6b1cb6755e31) does not exist on emulator — create it or run with --create-missing")
+
+		contracts, err := s.Project.DeployWithOptions("emulator", false, DeployOptions{CreateMissing: true})
+		require.NoError(t, err)
+		assert.Len(t, contracts, 2)
+
+		bob, err := s.Accounts.Get(bobAddress)
+		require.NoError(t, err)
+		assert.Contains(t, bob.Contracts, tests.ContractSimple.Name)
+	})
+
+	t.Run("Creator Required", func(t *testing.T) {
+		t.Parallel()
+		_, s := setupMissing(t, "testnet")
+
+		_, err := s.Project.DeployWithOptions("testnet", false, DeployOptions{CreateMissing: true})
+
+		var missingErr *MissingAccountsError
+		assert.ErrorAs(t, err, &missingErr)
+		assert.ErrorContains(t, err, "a creator account paying for the missing accounts is required")
+	})
+}
+
+func TestProjectContractInventory(t *testing.T) {
+	tokenCode := []byte(`pub contract Token {}`)
+	marketCode := []byte(`pub contract Market {}`)
+	marketV2Code := []byte(`pub contract Market { pub let fee: UFix64 init() { self.fee = 0.1 } }`)
+
+	hash := func(code []byte) string {
+		h := sha256.Sum256(code)
+		return hex.EncodeToString(h[:])
+	}
+
+	setupInventory := func() (*flowkit.State, *Services, *tests.TestGateway) {
+		state, s, gw := setup()
+		state.Accounts().AddOrUpdate(tests.Alice().SetAddress(flow.HexToAddress("01cf0e2f2f715450")))
+		state.Accounts().AddOrUpdate(tests.Bob().SetAddress(flow.HexToAddress("179b6b1cb6755e31")))
+		state.Accounts().AddOrUpdate(tests.Charlie().SetAddress(flow.HexToAddress("f3fcd2c1a78f5eee")))
+		// testnet account is not part of the emulator inventory
+		state.Accounts().AddOrUpdate(tests.Donald().SetAddress(flow.HexToAddress("631e88ae7f1d7c20")))
+		return state, s, gw
+	}
+
+	onChain := map[flow.Address]map[string][]byte{
+		flow.HexToAddress("f8d6e0586b0a20c7"): {},
+		flow.HexToAddress("01cf0e2f2f715450"): {"Token": tokenCode, "Market": marketCode},
+		flow.HexToAddress("179b6b1cb6755e31"): {"Token": tokenCode, "Market": marketV2Code},
+	}
+
+	t.Run("Inventory", func(t *testing.T) {
+		_, s, gw := setupInventory()
+
+		// accounts are fetched concurrently so the results are returned by functions instead of a Run handler
+		gw.GetAccount.Run(nil).Return(
+			func(address flow.Address) *flow.Account {
+				if contracts, ok := onChain[address]; ok {
+					return &flow.Account{Address: address, Contracts: contracts}
+				}
+				return nil
+			},
+			func(address flow.Address) error {
+				if _, ok := onChain[address]; ok {
+					return nil
+				}
+				return fmt.Errorf("failed to get account with address %s: %w", address, gateway.ErrAccountNotFound)
+			},
+		)
+
+		inventory, err := s.Project.ContractInventory("emulator")
+		require.NoError(t, err)
+
+		gw.Mock.AssertNumberOfCalls(t, "GetAccount", 4)
+		gw.Mock.AssertNotCalled(t, "GetAccount", flow.HexToAddress("631e88ae7f1d7c20"))
+
+		assert.Equal(t, "emulator", inventory.Network)
+		assert.Equal(t, []ContractInstance{{
+			Account:   "Alice",
+			Address:   flow.HexToAddress("01cf0e2f2f715450"),
+			SHA256:    hash(tokenCode),
+			SizeBytes: len(tokenCode),
+		}, {
+			Account:   "Bob",
+			Address:   flow.HexToAddress("179b6b1cb6755e31"),
+			SHA256:    hash(tokenCode),
+			SizeBytes: len(tokenCode),
+		}}, inventory.Contracts["Token"])
+
+		require.Len(t, inventory.Contracts["Market"], 2)
+		assert.Equal(t, hash(marketCode), inventory.Contracts["Market"][0].SHA256)
+		assert.Equal(t, hash(marketV2Code), inventory.Contracts["Market"][1].SHA256)
+		assert.Len(t, inventory.Contracts, 2)
+
+		assert.Equal(t, []string{"Market"}, inventory.Collisions)
+		assert.Equal(t, []string{"Charlie"}, inventory.MissingAccounts)
+
+		out, err := json.Marshal(inventory)
+		require.NoError(t, err)
+		assert.Contains(t, string(out), `"collisions":["Market"]`)
+		assert.Contains(t, string(out), `"missingAccounts":["Charlie"]`)
+		assert.Contains(t, string(out), fmt.Sprintf(`{"account":"Alice","address":"01cf0e2f2f715450","sha256":"%s","sizeBytes":%d}`, hash(tokenCode), len(tokenCode)))
+	})
+
+	t.Run("Account Error", func(t *testing.T) {
+		_, s, gw := setupInventory()
+
+		gw.GetAccount.Run(nil).Return(
+			func(address flow.Address) *flow.Account {
+				return &flow.Account{Address: address, Contracts: onChain[address]}
+			},
+			func(address flow.Address) error {
+				if address == flow.HexToAddress("179b6b1cb6755e31") {
+					return fmt.Errorf("access node unavailable")
+				}
+				return nil
+			},
+		)
+
+		_, err := s.Project.ContractInventory("emulator")
+		var batchErr *flowkit.BatchError
+		require.ErrorAs(t, err, &batchErr)
+		assert.EqualError(t, err, "1 of 4 items failed:\n  Bob: access node unavailable")
+	})
+
+	t.Run("Unknown Network", func(t *testing.T) {
+		_, s, _ := setupInventory()
+
+		_, err := s.Project.ContractInventory("previewnet")
+		assert.Error(t, err)
+	})
+}
+
+func TestProjectDeployAgainstFork_Integration(t *testing.T) {
+	t.Parallel()
+
+	counter := `pub contract Counter {
+		pub let count: %s
+		init() { self.count = %s }
+	}`
+
+	// the project gateway connects to the emulator acting as the remote network, where Counter is deployed to alice
+	setupFork := func(t *testing.T) (*flowkit.State, *Services, uint64) {
+		state, s := setupIntegration()
+		srvAcc, _ := state.EmulatorServiceAccount()
+
+		key, err := s.Keys.Generate("", crypto.ECDSA_P256)
+		require.NoError(t, err)
+		alice, err := s.Accounts.Create(
+			srvAcc,
+			[]crypto.PublicKey{key.PublicKey()},
+			[]int{flow.AccountKeyWeightThreshold},
+			[]crypto.SignatureAlgorithm{crypto.ECDSA_P256},
+			[]crypto.HashAlgorithm{crypto.SHA3_256},
+			nil,
+		)
+		require.NoError(t, err)
+		state.Accounts().AddOrUpdate(flowkit.NewAccount("alice").
+			SetAddress(alice.Address).
+			SetKey(flowkit.NewHexAccountKeyFromPrivateKey(0, crypto.SHA3_256, key)),
+		)
+
+		height, err := s.Blocks.GetLatestBlockHeight()
+		require.NoError(t, err)
+
+		err = state.ReaderWriter().WriteFile("counter.cdc", []byte(fmt.Sprintf(counter, "Int", "0")), 0644)
+		require.NoError(t, err)
+		state.Contracts().AddOrUpdate("Counter", config.Contract{Name: "Counter", Location: "counter.cdc", Network: "remote"})
+		state.Networks().AddOrUpdate("remote", config.Network{Name: "remote", Host: "127.0.0.1:3570"})
+		state.Deployments().AddOrUpdate(config.Deployment{
+			Network:   "remote",
+			Account:   "alice",
+			Contracts: []config.ContractDeployment{{Name: "Counter"}},
+		})
+
+		_, err = s.Project.Deploy("remote", false)
+		require.NoError(t, err)
+
+		return state, s, height
+	}
+
+	remoteCode := func(t *testing.T, state *flowkit.State, s *Services) string {
+		alice, _ := state.Accounts().ByName("alice")
+		account, err := s.Accounts.Get(alice.Address())
+		require.NoError(t, err)
+		return string(account.Contracts["Counter"])
+	}
+
+	t.Run("Update Fails On Remote State", func(t *testing.T) {
+		t.Parallel()
+
+		state, s, _ := setupFork(t)
+		deployed := remoteCode(t, state, s)
+
+		// changing the type of a field is not a valid update of the remote contract
+		err := state.ReaderWriter().WriteFile("counter.cdc", []byte(fmt.Sprintf(counter, "String", `""`)), 0644)
+		require.NoError(t, err)
+
+		result, err := s.Project.DeployAgainstFork("remote", ForkOptions{Update: true})
+		var deployErr *ProjectDeploymentError
+		require.ErrorAs(t, err, &deployErr)
+		require.Len(t, result.Contracts, 1)
+		assert.Equal(t, ContractFailed, result.Contracts[0].Status)
+		assert.True(t, result.Contracts[0].Update)
+		require.Contains(t, result.CadenceErrors, "Counter")
+		assert.Contains(t, result.CadenceErrors["Counter"].Error(), "mismatching field `count`")
+
+		assert.Equal(t, deployed, remoteCode(t, state, s))
+	})
+
+	t.Run("Update Succeeds On Fork", func(t *testing.T) {
+		t.Parallel()
+
+		state, s, _ := setupFork(t)
+		deployed := remoteCode(t, state, s)
+		lock, err := state.LockFile()
+		require.NoError(t, err)
+		records := len(lock.ByContract("remote", "Counter"))
+
+		err = state.ReaderWriter().WriteFile("counter.cdc", []byte(`pub contract Counter {
+			pub let count: Int
+			init() { self.count = 0 }
+			pub fun next(): Int { return self.count + 1 }
+		}`), 0644)
+		require.NoError(t, err)
+
+		result, err := s.Project.DeployAgainstFork("remote", ForkOptions{Update: true})
+		require.NoError(t, err)
+		require.Len(t, result.Contracts, 1)
+		assert.Equal(t, ContractDeployed, result.Contracts[0].Status)
+		assert.Empty(t, result.CadenceErrors)
+
+		// the remote network and the lock file are unchanged
+		assert.Equal(t, deployed, remoteCode(t, state, s))
+		lock, err = state.LockFile()
+		require.NoError(t, err)
+		assert.Len(t, lock.ByContract("remote", "Counter"), records)
+	})
+
+	t.Run("Pinned Height", func(t *testing.T) {
+		t.Parallel()
+
+		state, s, height := setupFork(t)
+
+		err := state.ReaderWriter().WriteFile("counter.cdc", []byte(fmt.Sprintf(counter, "String", `""`)), 0644)
+		require.NoError(t, err)
+
+		// Counter wasn't deployed yet at the height, so it's deployed as a new contract
+		result, err := s.Project.DeployAgainstFork("remote", ForkOptions{Height: height, Update: true})
+		require.NoError(t, err)
+		assert.Equal(t, height, result.Height)
+		require.Len(t, result.Contracts, 1)
+		assert.Equal(t, ContractDeployed, result.Contracts[0].Status)
+		assert.False(t, result.Contracts[0].Update)
+	})
+
+	t.Run("Forked Storage Read", func(t *testing.T) {
+		t.Parallel()
+
+		state, s, height := setupFork(t)
+
+		// the storage of alice isn't forked, so reading it fails instead of finding it empty
+		err := state.ReaderWriter().WriteFile("reader.cdc", []byte(`pub contract Reader {
+			pub let found: Bool
+			init() { self.found = self.account.type(at: /storage/flowTokenVault) != nil }
+		}`), 0644)
+		require.NoError(t, err)
+		state.Contracts().AddOrUpdate("Reader", config.Contract{Name: "Reader", Location: "reader.cdc", Network: "remote"})
+		state.Deployments().AddOrUpdate(config.Deployment{
+			Network:   "remote",
+			Account:   "alice",
+			Contracts: []config.ContractDeployment{{Name: "Reader"}},
+		})
+
+		result, err := s.Project.DeployAgainstFork("remote", ForkOptions{Height: height})
+		require.Error(t, err)
+		assert.ErrorContains(t, err, gateway.ErrForkedStorage.Error())
+		require.Len(t, result.Contracts, 1)
+		assert.Equal(t, ContractFailed, result.Contracts[0].Status)
+	})
+
+	t.Run("Create Missing", func(t *testing.T) {
+		t.Parallel()
+
+		_, s, _ := setupFork(t)
+
+		_, err := s.Project.DeployAgainstFork("remote", ForkOptions{Deploy: DeployOptions{CreateMissing: true}})
+		assert.EqualError(t, err, "accounts can't be created on a fork of remote")
+	})
+}
+
+func TestProjectPlan(t *testing.T) {
+	contractBV1 := []byte(`pub contract ContractB {}`)
+
+	setupPlan := func() (*flowkit.State, *Services, *tests.TestGateway, map[string][]byte) {
+		state, s, gw := setup()
+		state.Networks().AddOrUpdate("testnet", config.DefaultTestnetNetwork())
+
+		alice := tests.Alice()
+		state.Accounts().AddOrUpdate(alice)
+
+		deployment := config.Deployment{Network: "testnet", Account: alice.Name()}
+		for _, c := range []tests.Resource{tests.ContractA, tests.ContractB, tests.ContractC} {
+			state.Contracts().AddOrUpdate(c.Name, config.Contract{Name: c.Name, Location: c.Filename, Network: "testnet"})
+			deployment.Contracts = append(deployment.Contracts, config.ContractDeployment{Name: c.Name})
+		}
+		deployment.Contracts[2].Args = []cadence.Value{cadence.String("hello")}
+		state.Deployments().AddOrUpdate(deployment)
+
+		// ContractA is unchanged, ContractB has different code and ContractC doesn't exist
+		onChain := map[string][]byte{
+			tests.ContractA.Name: tests.ContractA.Source,
+			tests.ContractB.Name: contractBV1,
+		}
+		gw.GetAccount.Run(nil).Return(
+			func(address flow.Address) *flow.Account {
+				account := onChainAccount(alice)
+				for name, code := range onChain {
+					account.Contracts[name] = code
+				}
+				return account
+			},
+			nil,
+		)
+
+		return state, s, gw, onChain
+	}
+
+	t.Run("Plan", func(t *testing.T) {
+		_, s, gw, _ := setupPlan()
+		gw.ExecuteScript.Run(func(args mock.Arguments) {
+			assert.Contains(t, string(args.Get(0).([]byte)), "import FlowFees from 0x912d5440f7e3769e")
+			// the inclusion effort is 1.0 and the execution effort is the compute limit
+			assert.Equal(t, []cadence.Value{cadence.UFix64(100_000_000), cadence.UFix64(9999)}, args.Get(1))
+			gw.ExecuteScript.Return(cadence.UFix64(150_000), nil)
+		})
+
+		plan, err := s.Project.Plan("testnet", PlanOptions{Update: true, Metadata: map[string]string{"commit": "abc123"}})
+		require.NoError(t, err)
+		gw.Mock.AssertNotCalled(t, tests.SendSignedTransactionFunc, mock.Anything)
+
+		require.Len(t, plan.Contracts, 3)
+		a, b, c := plan.Contracts[0], plan.Contracts[1], plan.Contracts[2]
+
+		assert.Equal(t, "ContractA", a.Name)
+		assert.Equal(t, ContractSkip, a.Action)
+		assert.Equal(t, a.CodeHash, a.OnChainHash)
+
+		assert.Equal(t, "ContractB", b.Name)
+		assert.Equal(t, ContractUpdate, b.Action)
+		assert.Contains(t, b.Code, "import ContractA from 0x0000000000000001")
+		h := sha256.Sum256(contractBV1)
+		assert.Equal(t, hex.EncodeToString(h[:]), b.OnChainHash)
+		assert.Contains(t, b.Diff, "+		import ContractA from 0x0000000000000001")
+		assert.Contains(t, b.Diff, "-pub contract ContractB {}")
+
+		assert.Equal(t, "ContractC", c.Name)
+		assert.Equal(t, ContractCreate, c.Action)
+		assert.Empty(t, c.OnChainHash)
+		assert.Equal(t, []json.RawMessage{json.RawMessage(`{"value":"hello","type":"String"}`)}, c.Args)
+
+		assert.Equal(t, "0.00300000", plan.MaxFee)
+		summary := plan.String()
+		assert.True(t, strings.HasPrefix(summary, "Deployment plan for testnet:\n"+
+			"  = ContractA -> 0x0000000000000001 (Alice) [skip]\n"+
+			"  ~ ContractB -> 0x0000000000000001 (Alice) [update]\n"+
+			"      --- 0x0000000000000001.ContractB\n",
+		))
+		assert.True(t, strings.HasSuffix(summary, "  + ContractC -> 0x0000000000000001 (Alice) [create]\n"+
+			"2 to deploy, 1 unchanged, maximum fee 0.00300000 FLOW",
+		))
+
+		out, err := json.Marshal(plan)
+		require.NoError(t, err)
+
+		var decoded DeployPlan
+		require.NoError(t, json.Unmarshal(out, &decoded))
+		assert.Equal(t, *plan, decoded)
+	})
+
+	t.Run("Plan Fee Unknown", func(t *testing.T) {
+		_, s, gw, _ := setupPlan()
+		gw.ExecuteScript.Return(nil, fmt.Errorf("FlowFees not deployed"))
+
+		plan, err := s.Project.Plan("testnet", PlanOptions{Update: true})
+		require.NoError(t, err)
+		assert.Empty(t, plan.MaxFee)
+		assert.True(t, strings.HasSuffix(plan.String(), "2 to deploy, 1 unchanged, maximum fee unknown"))
+	})
+
+	t.Run("Plan Without Update", func(t *testing.T) {
+		_, s, _, _ := setupPlan()
+
+		_, err := s.Project.Plan("testnet", PlanOptions{})
+		assert.EqualError(t, err, "contract ContractB already exists on account Alice with different code, plan with update to update it")
+	})
+
+	t.Run("Drift", func(t *testing.T) {
+		_, s, gw, onChain := setupPlan()
+
+		plan, err := s.Project.Plan("testnet", PlanOptions{Update: true})
+		require.NoError(t, err)
+
+		onChain[tests.ContractC.Name] = []byte(`pub contract ContractC {}`)
+		delete(onChain, tests.ContractB.Name)
+
+		_, err = s.Project.Apply(plan)
+		var driftErr *PlanDriftError
+		require.ErrorAs(t, err, &driftErr)
+		require.Len(t, driftErr.Contracts, 2)
+		assert.Equal(t, "ContractB", driftErr.Contracts[0].Name)
+		assert.Equal(t, plan.Contracts[1].OnChainHash, driftErr.Contracts[0].PlannedHash)
+		assert.Empty(t, driftErr.Contracts[0].OnChainHash)
+		assert.Equal(t, "ContractC", driftErr.Contracts[1].Name)
+		assert.Empty(t, driftErr.Contracts[1].PlannedHash)
+		assert.Contains(t, err.Error(), "ContractB on 0x0000000000000001 (planned "+plan.Contracts[1].OnChainHash[:12]+", found absent)")
+		gw.Mock.AssertNotCalled(t, tests.SendSignedTransactionFunc, mock.Anything)
+	})
+
+	t.Run("Apply", func(t *testing.T) {
+		_, s, gw, _ := setupPlan()
+
+		plan, err := s.Project.Plan("testnet", PlanOptions{Update: true})
+		require.NoError(t, err)
+
+		// the plan is applied as serialized, e.g. in a later CI job
+		out, err := json.Marshal(plan)
+		require.NoError(t, err)
+		var stored DeployPlan
+		require.NoError(t, json.Unmarshal(out, &stored))
+
+		scripts := make([]string, 0)
+		gw.SendSignedTransaction.Run(func(args mock.Arguments) {
+			tx := args.Get(0).(*flowkit.Transaction)
+			scripts = append(scripts, string(tx.FlowTransaction().Script))
+			gw.SendSignedTransaction.Return(tests.NewTransaction(), nil)
+		})
+
+		results, err := s.Project.Apply(&stored)
+		require.NoError(t, err)
+
+		require.Len(t, results, 3)
+		assert.Equal(t, ContractUnchanged, results[0].Status)
+		assert.Equal(t, ContractDeployed, results[1].Status)
+		assert.True(t, results[1].Update)
+		assert.Equal(t, ContractDeployed, results[2].Status)
+		assert.Equal(t, []cadence.Value{cadence.String("hello")}, results[2].Args)
+
+		require.Len(t, scripts, 2)
+		assert.Contains(t, scripts[0], "signer.contracts.update__experimental")
+		assert.Contains(t, scripts[1], "signer.contracts.add")
+	})
+
+	t.Run("Apply Modified Code", func(t *testing.T) {
+		_, s, gw, _ := setupPlan()
+
+		plan, err := s.Project.Plan("testnet", PlanOptions{Update: true})
+		require.NoError(t, err)
+
+		plan.Contracts[2].Code = strings.Replace(plan.Contracts[2].Code, "self.x = x", "self.x = \"changed\"", 1)
+
+		_, err = s.Project.Apply(plan)
+		assert.EqualError(t, err, fmt.Sprintf(
+			"code of contract ContractC doesn't match the planned code hash %s, the plan was modified",
+			plan.Contracts[2].CodeHash,
+		))
+		gw.Mock.AssertNotCalled(t, tests.SendSignedTransactionFunc, mock.Anything)
+	})
+
+	t.Run("Apply Forced", func(t *testing.T) {
+		_, s, gw, onChain := setupPlan()
+
+		plan, err := s.Project.Plan("testnet", PlanOptions{Update: true})
+		require.NoError(t, err)
+
+		onChain[tests.ContractA.Name] = []byte(`pub contract ContractA { pub let x: Int init() { self.x = 1 } }`)
+
+		results, err := s.Project.ApplyWithOptions(plan, ApplyOptions{Force: true})
+		require.NoError(t, err)
+
+		// the plan is executed as planned, the changed contract is still skipped
+		assert.Equal(t, ContractUnchanged, results[0].Status)
+		gw.Mock.AssertNumberOfCalls(t, tests.SendSignedTransactionFunc, 2)
+	})
+}
+
+func TestProjectStaleDependents(t *testing.T) {
+	// ContractB was deployed to Bob importing ContractA from Alice, ContractA was then moved to Charlie
+	contractBDeployed := []byte(`
+		import ContractA from 0x0000000000000001
+		pub contract ContractB {}
+	`)
+
+	setupMoved := func() (*Services, *tests.TestGateway) {
+		state, s, gw := setup()
+		state.Networks().AddOrUpdate("testnet", config.DefaultTestnetNetwork())
+
+		alice, bob, charlie := tests.Alice(), tests.Bob(), tests.Charlie()
+		onChain := make(map[flow.Address]*flow.Account)
+		for _, account := range []*flowkit.Account{alice, bob, charlie} {
+			state.Accounts().AddOrUpdate(account)
+			onChain[account.Address()] = onChainAccount(account)
+		}
+		onChain[alice.Address()].Contracts[tests.ContractA.Name] = tests.ContractA.Source
+		onChain[charlie.Address()].Contracts[tests.ContractA.Name] = tests.ContractA.Source
+		onChain[bob.Address()].Contracts[tests.ContractB.Name] = contractBDeployed
+
+		for _, c := range []tests.Resource{tests.ContractA, tests.ContractB, tests.ContractC} {
+			state.Contracts().AddOrUpdate(c.Name, config.Contract{Name: c.Name, Location: c.Filename, Network: "testnet"})
+		}
+		state.Deployments().AddOrUpdate(config.Deployment{
+			Network:   "testnet",
+			Account:   charlie.Name(),
+			Contracts: []config.ContractDeployment{{Name: tests.ContractA.Name}},
+		})
+		state.Deployments().AddOrUpdate(config.Deployment{
+			Network: "testnet",
+			Account: bob.Name(),
+			Contracts: []config.ContractDeployment{
+				{Name: tests.ContractB.Name},
+				{Name: tests.ContractC.Name, Args: []cadence.Value{cadence.String("hello")}},
+			},
+		})
+
+		gw.GetAccount.Run(nil).Return(
+			func(address flow.Address) *flow.Account {
+				return onChain[address]
+			},
+			nil,
+		)
+
+		return s, gw
+	}
+
+	t.Run("Detect", func(t *testing.T) {
+		s, gw := setupMoved()
+
+		stale, err := s.Project.StaleDependents("testnet")
+		require.NoError(t, err)
+		assert.Equal(t, []StaleContract{{
+			Name:        tests.ContractB.Name,
+			AccountName: "Bob",
+			Address:     flow.HexToAddress("02"),
+			Imports: []DriftedImport{{
+				Contract: tests.ContractA.Name,
+				Embedded: flow.HexToAddress("01"),
+				Current:  flow.HexToAddress("03"),
+			}},
+		}}, stale)
+		assert.Equal(
+			t,
+			"ContractB on 0x0000000000000002 imports ContractA (deployed 0x0000000000000001, now 0x0000000000000003)",
+			stale[0].String(),
+		)
+		gw.Mock.AssertNotCalled(t, tests.SendSignedTransactionFunc, mock.Anything)
+	})
+
+	t.Run("Deploy Without Cascade", func(t *testing.T) {
+		s, _ := setupMoved()
+
+		_, err := s.Project.Deploy("testnet", false)
+		assert.ErrorContains(t, err, "contract ContractB exists in account Bob")
+	})
+
+	for _, batch := range []bool{false, true} {
+		t.Run(fmt.Sprintf("Deploy Cascade Batch %t", batch), func(t *testing.T) {
+			s, gw := setupMoved()
+
+			scripts := make([]string, 0)
+			gw.SendSignedTransaction.Run(func(args mock.Arguments) {
+				tx := args.Get(0).(*flowkit.Transaction)
+				scripts = append(scripts, string(tx.FlowTransaction().Script))
+				gw.SendSignedTransaction.Return(tests.NewTransaction(), nil)
+			})
+
+			results := make(map[string]ContractResult)
+			_, err := s.Project.DeployWithOptions("testnet", false, DeployOptions{
+				Batch:          batch,
+				CascadeUpdates: true,
+				AfterContract: func(result ContractResult) {
+					results[result.Name] = result
+				},
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, ContractUnchanged, results[tests.ContractA.Name].Status)
+			assert.Equal(t, ContractDeployed, results[tests.ContractB.Name].Status)
+			assert.True(t, results[tests.ContractB.Name].Update)
+			assert.Equal(t, ContractDeployed, results[tests.ContractC.Name].Status)
+
+			// the stale contract is updated on its own also when batching
+			require.Len(t, scripts, 2)
+			assert.Contains(t, scripts[0], "signer.contracts.update__experimental")
+			assert.Contains(t, scripts[1], "signer.contracts.add")
+		})
+	}
+
+	t.Run("Plan Cascade", func(t *testing.T) {
+		s, _ := setupMoved()
+
+		_, err := s.Project.Plan("testnet", PlanOptions{})
+		assert.EqualError(t, err, "contract ContractB already exists on account Bob with different code, plan with update to update it")
+
+		plan, err := s.Project.Plan("testnet", PlanOptions{CascadeUpdates: true})
+		require.NoError(t, err)
+		require.Len(t, plan.Contracts, 3)
+		assert.Equal(t, ContractSkip, plan.Contracts[0].Action)
+		assert.Equal(t, ContractUpdate, plan.Contracts[1].Action)
+		assert.Contains(t, plan.Contracts[1].Code, "import ContractA from 0x0000000000000003")
+		assert.Equal(t, ContractCreate, plan.Contracts[2].Action)
+	})
+}
+
+func TestProjectStrictImports(t *testing.T) {
+	setupStrict := func() (*flowkit.State, *Services, *tests.TestGateway) {
+		state, s, gw := setup()
+		state.Networks().AddOrUpdate("testnet", config.DefaultTestnetNetwork())
+
+		alice := tests.Alice()
+		state.Accounts().AddOrUpdate(alice)
+
+		deployment := config.Deployment{Network: "testnet", Account: alice.Name()}
+		for _, c := range []tests.Resource{tests.ContractA, tests.ContractB, tests.ContractC} {
+			state.Contracts().AddOrUpdate(c.Name, config.Contract{Name: c.Name, Location: c.Filename, Network: "testnet"})
+			deployment.Contracts = append(deployment.Contracts, config.ContractDeployment{Name: c.Name})
+		}
+		deployment.Contracts[2].Args = []cadence.Value{cadence.String("hello")}
+		state.Deployments().AddOrUpdate(deployment)
+
+		gw.GetAccount.Run(nil).Return(onChainAccount(alice), nil)
+		gw.SendSignedTransaction.Run(func(args mock.Arguments) {
+			gw.SendSignedTransaction.Return(tests.NewTransaction(), nil)
+		})
+
+		return state, s, gw
+	}
+
+	setPins := func(state *flowkit.State, strict bool, pins map[string]string) {
+		network, err := state.Networks().ByName("testnet")
+		require.NoError(t, err)
+		network.StrictImports = strict
+		state.Networks().AddOrUpdate(network.Name, *network)
+
+		lock, err := state.LockFile()
+		require.NoError(t, err)
+		lock.SetPins("testnet", pins)
+		require.NoError(t, state.SaveLockFile(lock))
+	}
+
+	t.Run("Generated pins", func(t *testing.T) {
+		state, s, gw := setupStrict()
+
+		allowlist, err := s.Project.GenerateImportAllowlist("testnet")
+		require.NoError(t, err)
+		assert.Equal(t, project.ImportAllowlist{
+			"ContractA": "0000000000000001",
+			"ContractB": "0000000000000001",
+		}, allowlist)
+
+		lock, err := state.LockFile()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string(allowlist), lock.PinsForNetwork("testnet"))
+
+		contracts, err := s.Project.DeployWithOptions("testnet", false, DeployOptions{StrictImports: true})
+		require.NoError(t, err)
+		assert.Len(t, contracts, 3)
+		gw.Mock.AssertNumberOfCalls(t, tests.SendSignedTransactionFunc, 3)
+	})
+
+	t.Run("Missing pin", func(t *testing.T) {
+		state, s, gw := setupStrict()
+		setPins(state, true, map[string]string{"ContractB": "0x01"})
+
+		_, err := s.Project.Deploy("testnet", false)
+
+		var allowlistErr *ImportAllowlistError
+		require.ErrorAs(t, err, &allowlistErr)
+		assert.Equal(t, "testnet", allowlistErr.Network)
+		require.Len(t, allowlistErr.Violations, 2)
+		for _, violation := range allowlistErr.Violations {
+			assert.Equal(t, "ContractA", violation.Contract)
+			assert.Empty(t, violation.Expected)
+		}
+		assert.ErrorContains(t, err, "strict imports on testnet rejected 2 import(s)")
+		gw.Mock.AssertNotCalled(t, tests.SendSignedTransactionFunc, mock.Anything)
+	})
+
+	t.Run("Mismatched address", func(t *testing.T) {
+		state, s, gw := setupStrict()
+		setPins(state, false, map[string]string{"ContractA": "0x01", "ContractB": "0x02"})
+
+		// strict imports are off for the network, so only the option enables them
+		_, err := s.Project.Deploy("testnet", false)
+		require.NoError(t, err)
+		gw.Mock.AssertNumberOfCalls(t, tests.SendSignedTransactionFunc, 3)
+
+		_, err = s.Project.DeployWithOptions("testnet", false, DeployOptions{StrictImports: true})
+		var allowlistErr *ImportAllowlistError
+		require.ErrorAs(t, err, &allowlistErr)
+		require.Len(t, allowlistErr.Violations, 1)
+		violation := allowlistErr.Violations[0]
+		assert.Equal(t, "ContractB", violation.Contract)
+		assert.Equal(t, "contractC.cdc", violation.Program)
+		assert.Equal(t, "0000000000000001", violation.Address)
+		assert.Equal(t, "0000000000000002", violation.Expected)
+		gw.Mock.AssertNumberOfCalls(t, tests.SendSignedTransactionFunc, 3)
+	})
+
+	t.Run("Add contract with mismatched address", func(t *testing.T) {
+		state, s, gw := setupStrict()
+		setPins(state, true, map[string]string{"ContractA": "0x02"})
+
+		_, _, err := s.Accounts.AddContract(
+			tests.Alice(),
+			flowkit.NewScript(tests.ContractB.Source, nil, tests.ContractB.Filename),
+			"testnet",
+			false,
+		)
+		var allowlistErr *ImportAllowlistError
+		require.ErrorAs(t, err, &allowlistErr)
+		require.Len(t, allowlistErr.Violations, 1)
+		assert.Equal(t, "ContractA", allowlistErr.Violations[0].Contract)
+		gw.Mock.AssertNotCalled(t, tests.SendSignedTransactionFunc, mock.Anything)
+	})
+}
+
+func TestProjectVerify_Integration(t *testing.T) {
+	state, s := setupIntegration()
+	srvAcc, _ := state.EmulatorServiceAccount()
+	state.Networks().AddOrUpdate("emulator", config.DefaultEmulatorNetwork())
+
+	deployment := config.Deployment{Network: "emulator", Account: srvAcc.Name()}
+	for _, c := range []tests.Resource{tests.ContractA, tests.ContractB, tests.ContractSimple} {
+		state.Contracts().AddOrUpdate(c.Name, config.Contract{Name: c.Name, Location: c.Filename, Network: "emulator"})
+		deployment.Contracts = append(deployment.Contracts, config.ContractDeployment{Name: c.Name})
+	}
+	state.Deployments().AddOrUpdate(deployment)
+
+	_, err := s.Project.Deploy("emulator", false)
+	require.NoError(t, err)
+
+	t.Run("Match", func(t *testing.T) {
+		results, err := s.Project.Verify("emulator")
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+		for _, result := range results {
+			assert.True(t, result.Match, result.ContractName)
+			assert.Empty(t, result.Diff)
+			assert.Equal(t, srvAcc.Address(), result.Address)
+		}
+	})
+
+	t.Run("Mismatch", func(t *testing.T) {
+		err := state.ReaderWriter().WriteFile(tests.ContractSimple.Filename, tests.ContractSimpleUpdated.Source, 0644)
+		require.NoError(t, err)
+		defer func() {
+			_ = state.ReaderWriter().WriteFile(tests.ContractSimple.Filename, tests.ContractSimple.Source, 0644)
+		}()
+
+		// a contract and an account that are not deployed are reported instead of failing
+		state.Contracts().AddOrUpdate("Hello", config.Contract{
+			Name:     "Hello",
+			Location: tests.ContractHelloString.Filename,
+			Network:  "emulator",
+		})
+		state.Contracts().AddOrUpdate(tests.ContractRates.Name, config.Contract{
+			Name:     tests.ContractRates.Name,
+			Location: tests.ContractRates.Filename,
+			Network:  "emulator",
+		})
+		state.Accounts().AddOrUpdate(tests.Alice().SetAddress(flow.HexToAddress("01cf0e2f2f715450")))
+		state.Deployments().AddOrUpdate(config.Deployment{
+			Network:   "emulator",
+			Account:   srvAcc.Name(),
+			Contracts: append(deployment.Contracts, config.ContractDeployment{Name: "Hello"}),
+		})
+		state.Deployments().AddOrUpdate(config.Deployment{
+			Network:   "emulator",
+			Account:   "Alice",
+			Contracts: []config.ContractDeployment{{Name: tests.ContractRates.Name}},
+		})
+
+		results, err := s.Project.Verify("emulator")
+		require.NoError(t, err)
+		require.Len(t, results, 5)
+
+		byName := make(map[string]*VerificationResult)
+		for _, result := range results {
+			byName[result.ContractName] = result
+		}
+
+		assert.True(t, byName[tests.ContractA.Name].Match)
+		assert.True(t, byName[tests.ContractB.Name].Match)
+
+		simple := byName[tests.ContractSimple.Name]
+		assert.False(t, simple.Match)
+		assert.Contains(t, simple.Diff, fmt.Sprintf("--- 0x%s.Simple", srvAcc.Address()))
+		assert.Contains(t, simple.Diff, "+++ "+tests.ContractSimple.Filename)
+
+		hello := byName["Hello"]
+		assert.False(t, hello.Match)
+		assert.Contains(t, hello.Diff, "@@ -0,0 +1,")
+
+		rates := byName[tests.ContractRates.Name]
+		assert.False(t, rates.Match)
+		assert.Equal(t, "Alice", rates.AccountName)
+	})
+}
+
+func TestProjectIgnoreFormatting_Integration(t *testing.T) {
+	state, s := setupIntegration()
+	logger := &recordingLogger{Logger: output.NewStdoutLogger(output.NoneLog)}
+	s.SetLogger(logger)
+	srvAcc, _ := state.EmulatorServiceAccount()
+	state.Networks().AddOrUpdate("emulator", config.DefaultEmulatorNetwork())
+
+	state.Contracts().AddOrUpdate(tests.ContractSimple.Name, config.Contract{
+		Name:     tests.ContractSimple.Name,
+		Location: tests.ContractSimple.Filename,
+		Network:  "emulator",
+	})
+	state.Deployments().AddOrUpdate(config.Deployment{
+		Network:   "emulator",
+		Account:   srvAcc.Name(),
+		Contracts: []config.ContractDeployment{{Name: tests.ContractSimple.Name}},
+	})
+
+	_, err := s.Project.Deploy("emulator", false)
+	require.NoError(t, err)
+
+	reformatted := []byte("pub contract Simple {\n}\n")
+	err = state.ReaderWriter().WriteFile(tests.ContractSimple.Filename, reformatted, 0644)
+	require.NoError(t, err)
+
+	t.Run("Verify", func(t *testing.T) {
+		results, err := s.Project.Verify("emulator")
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.False(t, results[0].Match)
+
+		results, err = s.Project.VerifyWithOptions("emulator", VerifyOptions{IgnoreFormatting: true})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.True(t, results[0].Match)
+		assert.Empty(t, results[0].Diff)
+	})
+
+	t.Run("Plan", func(t *testing.T) {
+		plan, err := s.Project.Plan("emulator", PlanOptions{IgnoreFormatting: true})
+		require.NoError(t, err)
+		require.Len(t, plan.Contracts, 1)
+		assert.Equal(t, ContractSkip, plan.Contracts[0].Action)
+		assert.Equal(t, string(reformatted), plan.Contracts[0].Code)
+	})
+
+	t.Run("Deploy Unchanged", func(t *testing.T) {
+		var statuses []ContractDeployStatus
+		_, err := s.Project.DeployWithOptions("emulator", false, DeployOptions{
+			IgnoreFormatting: true,
+			AfterContract: func(result ContractResult) {
+				statuses = append(statuses, result.Status)
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []ContractDeployStatus{ContractUnchanged}, statuses)
+
+		acc, err := s.Accounts.Get(srvAcc.Address())
+		require.NoError(t, err)
+		assert.Equal(t, tests.ContractSimple.Source, acc.Contracts[tests.ContractSimple.Name])
+	})
+
+	t.Run("Deploy Original Code", func(t *testing.T) {
+		updated := []byte("pub contract Simple {\n\n  pub fun newFunc( ) { }\n}\n")
+		err := state.ReaderWriter().WriteFile(tests.ContractSimple.Filename, updated, 0644)
+		require.NoError(t, err)
+
+		_, err = s.Project.DeployWithOptions("emulator", true, DeployOptions{IgnoreFormatting: true})
+		require.NoError(t, err)
+
+		acc, err := s.Accounts.Get(srvAcc.Address())
+		require.NoError(t, err)
+		assert.Equal(t, string(updated), string(acc.Contracts[tests.ContractSimple.Name]))
+	})
+
+	t.Run("Format Failure", func(t *testing.T) {
+		onChain, local := comparableCodes(logger, "Invalid", []byte("pub contract Invalid {"), []byte("pub contract Invalid {}"), true)
+		assert.Equal(t, "pub contract Invalid {", string(onChain))
+		assert.Equal(t, "pub contract Invalid {}", string(local))
+		require.NotEmpty(t, logger.warnings)
+		assert.Contains(t, logger.warnings[len(logger.warnings)-1], "failed to format the on-chain code of contract Invalid")
+	})
+}
+
+func TestProjectPreflight(t *testing.T) {
+	kmsKey, err := flowkit.NewAccountKey(config.AccountKey{
+		Type:       config.KeyTypeGoogleKMS,
+		SigAlgo:    crypto.ECDSA_P256,
+		HashAlgo:   crypto.SHA3_256,
+		ResourceID: "projects/flow/locations/global/keyRings/flow/cryptoKeys/deployer/cryptoKeyVersions/1",
+	})
+	require.NoError(t, err)
+
+	// each account has a different problem, the kms account key can't be checked
+	missing := tests.Alice()
+	unknownIndex := tests.Bob()
+	unknownIndexKey, _ := unknownIndex.Key().PrivateKey()
+	unknownIndex.SetKey(flowkit.NewHexAccountKeyFromPrivateKey(2, crypto.SHA3_256, *unknownIndexKey))
+	revoked := tests.Charlie()
+	// Donald has the same address as Charlie
+	mismatched := tests.Donald().SetAddress(flow.HexToAddress("0x4"))
+	kms := flowkit.NewAccount("Kms").SetAddress(flow.HexToAddress("0x5")).SetKey(kmsKey)
+
+	revokedAccount := onChainAccount(revoked)
+	revokedAccount.Keys[0].Revoked = true
+	mismatchedAccount := onChainAccount(mismatched)
+	mismatchedAccount.Keys[0].PublicKey = onChainAccount(tests.Alice()).Keys[0].PublicKey
+
+	onChain := map[flow.Address]*flow.Account{
+		unknownIndex.Address(): onChainAccount(tests.Bob()),
+		revoked.Address():      revokedAccount,
+		mismatched.Address():   mismatchedAccount,
+		kms.Address():          {Address: kms.Address(), Keys: []*flow.AccountKey{{Index: 0, Weight: 1000}}},
+	}
+
+	setupPreflight := func(accounts ...*flowkit.Account) (*Services, *tests.TestGateway) {
+		state, s, gw := setup()
+		state.Networks().AddOrUpdate("testnet", config.DefaultTestnetNetwork())
+		state.Contracts().AddOrUpdate(tests.ContractSimple.Name, config.Contract{
+			Name:     tests.ContractSimple.Name,
+			Location: tests.ContractSimple.Filename,
+			Network:  "testnet",
+		})
+
+		for _, account := range accounts {
+			state.Accounts().AddOrUpdate(account)
+			state.Deployments().AddOrUpdate(config.Deployment{
+				Network:   "testnet",
+				Account:   account.Name(),
+				Contracts: []config.ContractDeployment{{Name: tests.ContractSimple.Name}},
+			})
+		}
+
+		gw.GetAccount.Run(nil).Return(
+			func(address flow.Address) *flow.Account {
+				return onChain[address]
+			},
+			func(address flow.Address) error {
+				if _, ok := onChain[address]; ok {
+					return nil
+				}
+				return fmt.Errorf("failed to get account with address %s: %w", address, gateway.ErrAccountNotFound)
+			},
+		)
+
+		return s, gw
+	}
+
+	problem := func(t *testing.T, account *flowkit.Account) PreflightProblem {
+		s, _ := setupPreflight(account)
+		report, err := s.Project.Preflight("testnet")
+		require.NoError(t, err)
+		require.Len(t, report.Problems, 1)
+		assert.Empty(t, report.Notes)
+		return report.Problems[0]
+	}
+
+	t.Run("Account Missing", func(t *testing.T) {
+		p := problem(t, missing)
+		assert.Equal(t, PreflightAccountMissing, p.Kind)
+		assert.Equal(t, "account 'Alice' (0x0000000000000001): account does not exist on testnet", p.String())
+
+		// missing accounts alone are reported the same as without the preflight
+		s, _ := setupPreflight(missing)
+		_, err := s.Project.Deploy("testnet", false)
+		assert.EqualError(t, err, "account 'Alice' (0x0000000000000001) does not exist on testnet — create it or run with --create-missing")
+	})
+
+	t.Run("Key Missing", func(t *testing.T) {
+		p := problem(t, unknownIndex)
+		assert.Equal(t, PreflightKeyMissing, p.Kind)
+		assert.Equal(t, 2, p.KeyIndex)
+		assert.Equal(t, "key index 2 does not exist, the account has 1 keys", p.Reason)
+	})
+
+	t.Run("Key Revoked", func(t *testing.T) {
+		p := problem(t, revoked)
+		assert.Equal(t, PreflightKeyRevoked, p.Kind)
+		assert.Equal(t, "key 0 is revoked", p.Reason)
+	})
+
+	t.Run("Key Mismatch", func(t *testing.T) {
+		p := problem(t, mismatched)
+		assert.Equal(t, PreflightKeyMismatch, p.Kind)
+		assert.Equal(t, "the configured private key does not match the public key 0 on the account", p.Reason)
+	})
+
+	t.Run("KMS Key Not Checked", func(t *testing.T) {
+		s, _ := setupPreflight(kms)
+		report, err := s.Project.Preflight("testnet")
+		require.NoError(t, err)
+		assert.Empty(t, report.Problems)
+		assert.Equal(t, []string{
+			"account 'Kms' uses a google-kms key, its public key can't be derived locally and was not checked",
+		}, report.Notes)
+	})
+
+	t.Run("Aggregate Report", func(t *testing.T) {
+		s, gw := setupPreflight(missing, unknownIndex, revoked, mismatched, kms)
+
+		_, err := s.Project.Deploy("testnet", false)
+
+		var preflightErr *PreflightError
+		require.ErrorAs(t, err, &preflightErr)
+		kinds := make([]string, 0)
+		for _, p := range preflightErr.Report.Problems {
+			kinds = append(kinds, p.Kind)
+		}
+		assert.ElementsMatch(t, []string{
+			PreflightAccountMissing,
+			PreflightKeyMissing,
+			PreflightKeyRevoked,
+			PreflightKeyMismatch,
+		}, kinds)
+
+		var missingErr *MissingAccountsError
+		require.ErrorAs(t, err, &missingErr)
+		assert.Equal(t, []MissingAccount{{Name: "Alice", Address: missing.Address()}}, missingErr.Accounts)
+
+		assert.Contains(t, err.Error(), "deployment preflight found 4 problems on testnet:\n")
+		assert.Contains(t, err.Error(), "\n  - account 'Charlie' (0x0000000000000003): key 0 is revoked")
+		assert.Contains(t, err.Error(), "\n  note: account 'Kms' uses a google-kms key")
+		gw.Mock.AssertNotCalled(t, tests.SendSignedTransactionFunc, mock.Anything)
+	})
+
+	t.Run("Tag Selectors", func(t *testing.T) {
+		taggedRevoked := tests.Charlie().SetTags([]string{"ops"})
+		taggedKms := flowkit.NewAccount("Kms").SetAddress(kms.Address()).SetKey(kmsKey).SetTags([]string{"ops"})
+		s, _ := setupPreflight(missing, taggedRevoked, taggedKms)
+
+		// Charlie is selected by the tag and by the name but checked once
+		report, err := s.Project.PreflightAccounts("testnet", []string{"tag:ops", "Charlie"})
+		require.NoError(t, err)
+		require.Len(t, report.Problems, 1)
+		assert.Equal(t, PreflightKeyRevoked, report.Problems[0].Kind)
+		assert.Len(t, report.Notes, 1)
+
+		_, err = s.Project.PreflightAccounts("testnet", []string{"tag:admin"})
+		assert.EqualError(t, err, "account selector tag:admin doesn't match any account")
+	})
+
+	t.Run("Skip Preflight", func(t *testing.T) {
+		s, gw := setupPreflight(mismatched)
+
+		_, err := s.Project.DeployWithOptions("testnet", false, DeployOptions{SkipPreflight: true})
+		require.NoError(t, err)
+		gw.Mock.AssertNumberOfCalls(t, tests.SendSignedTransactionFunc, 1)
+	})
+}
+
+func TestProjectWatchContracts(t *testing.T) {
+	watchMinBackoff = time.Millisecond
+	watchMaxBackoff = 5 * time.Millisecond
+
+	tokenV1 := []byte(`pub contract Token {}`)
+	tokenV2 := []byte(`pub contract Token { pub let supply: UFix64 }`)
+	market := []byte(`pub contract Market {}`)
+
+	hash := func(code []byte) string {
+		h := sha256.Sum256(code)
+		return hex.EncodeToString(h[:])
+	}
+
+	// setupWatch returns services with a gateway responding with the scripted contracts in order,
+	// a nil entry in the script is a failed request and the last entry is repeated once the script ends.
+	// The mock gateway builds the account before the error, so the response is picked when building the account.
+	setupWatch := func(script []map[string][]byte) (*flowkit.State, *Services, *flowkit.Account) {
+		state, s, gw := setup()
+		state.Networks().AddOrUpdate("testnet", config.DefaultTestnetNetwork())
+
+		alice := tests.Alice()
+		state.Accounts().AddOrUpdate(alice)
+		state.Deployments().AddOrUpdate(config.Deployment{
+			Network:   "testnet",
+			Account:   alice.Name(),
+			Contracts: []config.ContractDeployment{{Name: "Token"}, {Name: "Market"}},
+		})
+
+		call := 0
+		next := func() map[string][]byte {
+			i := call
+			if i >= len(script) {
+				i = len(script) - 1
+			}
+			call++
+			return script[i]
+		}
+
+		var contracts map[string][]byte
+		gw.GetAccount.Run(nil).Return(
+			func(address flow.Address) *flow.Account {
+				contracts = next()
+				account := onChainAccount(alice)
+				for name, code := range contracts {
+					account.Contracts[name] = code
+				}
+				return account
+			},
+			func(address flow.Address) error {
+				if contracts == nil {
+					return fmt.Errorf("connection refused")
+				}
+				return nil
+			},
+		)
+
+		return state, s, alice
+	}
+
+	t.Run("Change, removal and transient errors", func(t *testing.T) {
+		_, s, alice := setupWatch([]map[string][]byte{
+			{"Token": tokenV1, "Market": market},
+			nil,
+			nil,
+			{"Token": tokenV2, "Market": market},
+			{"Token": tokenV2},
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		changes := make([]ContractChange, 0)
+		err := s.Project.WatchContracts(ctx, "testnet", time.Millisecond, func(change ContractChange) {
+			changes = append(changes, change)
+			if change.Kind == ContractRemoved {
+				cancel()
+			}
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+
+		require.Len(t, changes, 2)
+		assert.Equal(t, ContractModified, changes[0].Kind)
+		assert.Equal(t, "Token", changes[0].Name)
+		assert.Equal(t, alice.Address(), changes[0].Address)
+		assert.Equal(t, hash(tokenV1), changes[0].OldHash)
+		assert.Equal(t, hash(tokenV2), changes[0].NewHash)
+		assert.False(t, changes[0].DetectedAt.IsZero())
+
+		assert.Equal(t, ContractRemoved, changes[1].Kind)
+		assert.Equal(t, "Market", changes[1].Name)
+		assert.Equal(t, hash(market), changes[1].OldHash)
+		assert.Empty(t, changes[1].NewHash)
+	})
+
+	t.Run("Seeded from lock file", func(t *testing.T) {
+		state, s, alice := setupWatch([]map[string][]byte{
+			{"Token": tokenV2, "Market": market},
+		})
+		require.NoError(t, state.RecordDeployment(flowkit.DeploymentRecord{
+			Network:  "testnet",
+			Contract: "Token",
+			Address:  alice.Address().String(),
+			CodeHash: hash(tokenV1),
+		}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		changes := make([]ContractChange, 0)
+		err := s.Project.WatchContracts(ctx, "testnet", time.Millisecond, func(change ContractChange) {
+			changes = append(changes, change)
+			cancel()
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+
+		require.Len(t, changes, 1)
+		assert.Equal(t, ContractModified, changes[0].Kind)
+		assert.Equal(t, "Token", changes[0].Name)
+		assert.Equal(t, hash(tokenV1), changes[0].OldHash)
+		assert.Equal(t, hash(tokenV2), changes[0].NewHash)
+	})
+
+	t.Run("Invalid interval", func(t *testing.T) {
+		_, s, _ := setupWatch([]map[string][]byte{{}})
+
+		err := s.Project.WatchContracts(context.Background(), "testnet", 0, func(ContractChange) {})
+		assert.EqualError(t, err, "watch interval must be positive")
+	})
+}
+
+func TestProjectRootImports(t *testing.T) {
+	t.Parallel()
+
+	setupProject := func(t *testing.T, fooImport string) (*Services, *tests.TestGateway) {
+		af := afero.Afero{Fs: afero.NewMemMapFs()}
+		require.NoError(t, af.WriteFile("project/flow.json", []byte(`{
+			"contracts": {
+				"Secret": "secret.cdc",
+				"Foo": "project/contracts/Foo.cdc"
+			},
+			"networks": {"emulator": "127.0.0.1:3569"},
+			"accounts": {
+				"emulator-account": {
+					"address": "f8d6e0586b0a20c7",
+					"key": "dd72967fd2bd75234ae9037dd4694c1f00baad63a10c35172bf65fbb8ad74b47"
+				}
+			},
+			"deployments": {
+				"emulator": {"emulator-account": ["Secret", "Foo"]}
+			}
+		}`), 0644))
+		require.NoError(t, af.WriteFile("secret.cdc", []byte("pub contract Secret {}"), 0644))
+		require.NoError(t, af.WriteFile(
+			"project/contracts/Foo.cdc",
+			[]byte(fmt.Sprintf("import %q\npub contract Foo {}", fooImport)),
+			0644,
+		))
+
+		state, err := flowkit.Load([]string{"project/flow.json"}, af)
+		require.NoError(t, err)
+		assert.Equal(t, "project", state.Root())
+
+		gw := tests.DefaultMockGateway()
+		return NewServices(gw.Mock, state, output.NewStdoutLogger(output.NoneLog)), gw
+	}
+
+	t.Run("Script Import Outside Root", func(t *testing.T) {
+		s, _ := setupProject(t, "./Bar.cdc")
+
+		_, err := s.Scripts.Execute(
+			flowkit.NewScript([]byte("import \"../../secret.cdc\"\npub fun main() {}"), nil, "project/scripts/main.cdc"),
+			"emulator",
+		)
+		assert.ErrorContains(t, err, "resolves to secret.cdc which is outside of the project root project")
+	})
+
+	t.Run("Script Import Inside Root", func(t *testing.T) {
+		s, gw := setupProject(t, "./Bar.cdc")
+
+		gw.ExecuteScript.Run(func(args mock.Arguments) {
+			assert.Contains(t, string(args.Get(0).([]byte)), "from 0xf8d6e0586b0a20c7")
+			gw.ExecuteScript.Return(cadence.NewInt(1), nil)
+		})
+
+		_, err := s.Scripts.Execute(
+			flowkit.NewScript([]byte("import \"../contracts/Foo.cdc\"\npub fun main(): Int { return 1 }"), nil, "project/scripts/main.cdc"),
+			"emulator",
+		)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Plan Contract Import Outside Root", func(t *testing.T) {
+		s, _ := setupProject(t, "../../secret.cdc")
+
+		_, err := s.Project.Plan("emulator", PlanOptions{})
+		assert.ErrorContains(t, err, "resolves to secret.cdc which is outside of the project root project")
+	})
 }
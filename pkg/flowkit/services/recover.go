@@ -0,0 +1,342 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+
+	"github.com/onflow/flow-cli/pkg/flowkit"
+	"github.com/onflow/flow-cli/pkg/flowkit/config"
+	"github.com/onflow/flow-cli/pkg/flowkit/gateway"
+)
+
+// recoveryGateway connects to the network accounts are recovered on.
+var recoveryGateway = func(network config.Network) (gateway.Gateway, error) {
+	if network.Key != "" {
+		return gateway.NewSecureGrpcGateway(network.Host, network.Key)
+	}
+	return gateway.NewGrpcGateway(network.Host)
+}
+
+// keyIndexers are the key indexer APIs of the networks, used to recover accounts without candidate addresses.
+var keyIndexers = map[string]gateway.KeyIndexer{
+	config.DefaultMainnetNetwork().Name: gateway.NewHTTPKeyIndexer("https://key-indexer.production.flow.com"),
+	config.DefaultTestnetNetwork().Name: gateway.NewHTTPKeyIndexer("https://key-indexer.staging.flow.com"),
+}
+
+// recoverySigAlgos are the curves a private key is probed on, the same hex key is valid on both.
+var recoverySigAlgos = []crypto.SignatureAlgorithm{crypto.ECDSA_P256, crypto.ECDSA_secp256k1}
+
+// Recover finds the accounts having a key that matches the private key, so their configuration can be rebuilt.
+//
+// Each candidate address is fetched on each network and its non-revoked keys are compared with the public key
+// of the private key on the curve of the key, the key with the highest weight is returned for each matching
+// account. Without candidate addresses the accounts are looked up by public key on the networks that have a
+// key indexer. Use State.AddRecoveredAccounts to add the recovered accounts to the configuration.
+func (a *Accounts) Recover(
+	privateKeyHex string,
+	candidateAddresses []flow.Address,
+	networks []string,
+) (recovered []flowkit.RecoveredAccount, err error) {
+	err = a.diagnostics.run(a.tracing, "Accounts.Recover", []Attribute{
+		addressesInput("candidates", candidateAddresses),
+		{Key: "networks", Value: strings.Join(networks, ",")},
+	}, func(op *operation) error {
+		recovered, err = a.traced(op).recover(privateKeyHex, candidateAddresses, networks)
+		return err
+	})
+	return recovered, err
+}
+
+func (a *Accounts) recover(
+	privateKeyHex string,
+	candidateAddresses []flow.Address,
+	networks []string,
+) ([]flowkit.RecoveredAccount, error) {
+	if len(networks) == 0 {
+		return nil, fmt.Errorf("at least one network is required to recover accounts")
+	}
+
+	privateKeys := make(map[crypto.SignatureAlgorithm]crypto.PrivateKey)
+	for _, sigAlgo := range recoverySigAlgos {
+		privateKey, err := crypto.DecodePrivateKeyHex(sigAlgo, strings.TrimPrefix(privateKeyHex, "0x"))
+		if err == nil {
+			privateKeys[sigAlgo] = privateKey
+		}
+	}
+	if len(privateKeys) == 0 {
+		return nil, fmt.Errorf("invalid private key, it is not a %s or %s key", crypto.ECDSA_P256, crypto.ECDSA_secp256k1)
+	}
+
+	recovered := make([]flowkit.RecoveredAccount, 0)
+	for _, name := range networks {
+		network, err := a.recoveryNetwork(name)
+		if err != nil {
+			return nil, err
+		}
+
+		addresses := candidateAddresses
+		if len(addresses) == 0 {
+			addresses, err = lookUpKeyAddresses(name, privateKeys)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		matches, err := a.recoverOnNetwork(*network, addresses, privateKeys)
+		if err != nil {
+			return nil, err
+		}
+		recovered = append(recovered, matches...)
+	}
+
+	return recovered, nil
+}
+
+// recoverOnNetwork probes the addresses on the network for keys matching the private keys.
+func (a *Accounts) recoverOnNetwork(
+	network config.Network,
+	addresses []flow.Address,
+	privateKeys map[crypto.SignatureAlgorithm]crypto.PrivateKey,
+) ([]flowkit.RecoveredAccount, error) {
+	gw, err := recoveryGateway(network)
+	if err != nil {
+		return nil, err
+	}
+	defer gw.Close()
+
+	a.logger.StartProgress(fmt.Sprintf("Probing %d accounts on %s...", len(addresses), network.Name))
+	defer a.logger.StopProgress()
+
+	recovered := make([]flowkit.RecoveredAccount, 0)
+	for _, address := range addresses {
+		account, err := gw.GetAccount(address)
+		if errors.Is(err, gateway.ErrAccountNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get account 0x%s on %s: %w", address, network.Name, err)
+		}
+
+		match, found := matchAccountKey(account, privateKeys)
+		if found {
+			match.Network = network.Name
+			recovered = append(recovered, match)
+		}
+	}
+
+	return recovered, nil
+}
+
+// recoveryNetwork returns the configured network, or the default network with the name if there is no configuration.
+func (a *Accounts) recoveryNetwork(name string) (*config.Network, error) {
+	if a.state != nil {
+		if network, err := a.state.Networks().ByName(name); err == nil {
+			return network, nil
+		}
+	}
+
+	defaults := config.DefaultNetworks()
+	return defaults.ByName(name)
+}
+
+// lookUpKeyAddresses returns the addresses having the public key of any of the private keys on the network.
+func lookUpKeyAddresses(network string, privateKeys map[crypto.SignatureAlgorithm]crypto.PrivateKey) ([]flow.Address, error) {
+	indexer, ok := keyIndexers[network]
+	if !ok {
+		return nil, fmt.Errorf("no candidate addresses provided and network %s has no key indexer to look them up", network)
+	}
+
+	addresses := make([]flow.Address, 0)
+	seen := make(map[flow.Address]bool)
+	for _, sigAlgo := range recoverySigAlgos {
+		privateKey, ok := privateKeys[sigAlgo]
+		if !ok {
+			continue
+		}
+
+		found, err := indexer.AccountsByPublicKey(privateKey.PublicKey())
+		if err != nil {
+			return nil, err
+		}
+		for _, address := range found {
+			if !seen[address] {
+				seen[address] = true
+				addresses = append(addresses, address)
+			}
+		}
+	}
+
+	return addresses, nil
+}
+
+// matchAccountKey returns the non-revoked key of the account with the highest weight that matches a private key.
+func matchAccountKey(
+	account *flow.Account,
+	privateKeys map[crypto.SignatureAlgorithm]crypto.PrivateKey,
+) (flowkit.RecoveredAccount, bool) {
+	var match *flow.AccountKey
+	for _, key := range account.Keys {
+		privateKey, ok := privateKeys[key.SigAlgo]
+		if key.Revoked || !ok || !privateKey.PublicKey().Equals(key.PublicKey) {
+			continue
+		}
+		if match == nil || key.Weight > match.Weight {
+			match = key
+		}
+	}
+
+	if match == nil {
+		return flowkit.RecoveredAccount{}, false
+	}
+
+	return flowkit.RecoveredAccount{
+		Address:    account.Address,
+		KeyIndex:   match.Index,
+		SigAlgo:    match.SigAlgo,
+		HashAlgo:   match.HashAlgo,
+		Weight:     match.Weight,
+		PrivateKey: privateKeys[match.SigAlgo],
+	}, true
+}
+
+// revokeKeysTransaction revokes the keys at the indexes on the signer account.
+const revokeKeysTransaction = `
+transaction(keyIndexes: [Int]) {
+	prepare(signer: AuthAccount) {
+		for keyIndex in keyIndexes {
+			signer.keys.revoke(keyIndex: keyIndex)
+		}
+	}
+}
+`
+
+// RecoverAccount regains control of an account whose other keys are lost, using a recovery key added beforehand.
+//
+// Flow has no protocol level recovery through the payer of an account, a transaction authorized by an account
+// always needs signatures of the account keys. Recovery is therefore only possible when the account has a
+// non-revoked recovery key with full weight, added while the lost keys were still available. The key of the
+// signing account must be that recovery key, its configured address and index are ignored, the key is matched
+// by public key on the lost account.
+//
+// The recovery transaction is proposed, paid and authorized by the lost account with the recovery key and revokes
+// every other non-revoked key of the account. The returned transaction ID is of the sealed recovery transaction.
+func (a *Accounts) RecoverAccount(signingAccount *flowkit.Account, lostAccount flow.Address) (ID flow.Identifier, err error) {
+	err = a.diagnostics.run(a.tracing, "Accounts.RecoverAccount", []Attribute{
+		accountInput("signer", signingAccount),
+		{Key: "address", Value: lostAccount.String()},
+	}, func(op *operation) error {
+		ID, err = a.traced(op).recoverAccount(signingAccount, lostAccount)
+		return err
+	})
+	return ID, err
+}
+
+func (a *Accounts) recoverAccount(signingAccount *flowkit.Account, lostAccount flow.Address) (flow.Identifier, error) {
+	account, err := a.gateway.GetAccount(lostAccount)
+	if err != nil {
+		return flow.EmptyID, fmt.Errorf("failed to get account 0x%s: %w", lostAccount, err)
+	}
+
+	signer, err := signingAccount.Key().Signer(context.Background())
+	if err != nil {
+		return flow.EmptyID, err
+	}
+
+	var recoveryKey *flow.AccountKey
+	for _, key := range account.Keys {
+		if !key.Revoked && key.PublicKey.Equals(signer.PublicKey()) {
+			recoveryKey = key
+			break
+		}
+	}
+	if recoveryKey == nil {
+		return flow.EmptyID, fmt.Errorf(
+			"account 0x%s has no non-revoked key matching the key of the signing account %s, the recovery key must be added before the other keys are lost",
+			lostAccount,
+			signingAccount.Name(),
+		)
+	}
+	if recoveryKey.Weight < flow.AccountKeyWeightThreshold {
+		return flow.EmptyID, fmt.Errorf(
+			"recovery key %d of account 0x%s has weight %d, a weight of at least %d is required to sign alone",
+			recoveryKey.Index,
+			lostAccount,
+			recoveryKey.Weight,
+			flow.AccountKeyWeightThreshold,
+		)
+	}
+
+	revoked := make([]cadence.Value, 0)
+	for _, key := range account.Keys {
+		if !key.Revoked && key.Index != recoveryKey.Index {
+			revoked = append(revoked, cadence.NewInt(key.Index))
+		}
+	}
+	if len(revoked) == 0 {
+		return flow.EmptyID, fmt.Errorf("account 0x%s has no keys to revoke besides the recovery key", lostAccount)
+	}
+
+	keyConfig := signingAccount.Key().ToConfig()
+	keyConfig.Index = recoveryKey.Index
+	key, err := flowkit.NewAccountKey(keyConfig)
+	if err != nil {
+		return flow.EmptyID, err
+	}
+	recoverySigner := flowkit.NewAccount(signingAccount.Name()).SetAddress(lostAccount).SetKey(key)
+
+	tx, err := flowkit.NewAuthorizedTransaction(
+		recoverySigner,
+		[]byte(revokeKeysTransaction),
+		[]cadence.Value{cadence.NewArray(revoked)},
+	)
+	if err != nil {
+		return flow.EmptyID, err
+	}
+
+	tx, err = a.prepareTransaction(tx, recoverySigner, "")
+	if err != nil {
+		return flow.EmptyID, err
+	}
+
+	a.logger.StartProgress(fmt.Sprintf("Recovering account 0x%s...", lostAccount))
+	defer a.logger.StopProgress()
+
+	sentTx, err := a.sequences.send(tx)
+	if err != nil {
+		return flow.EmptyID, err
+	}
+
+	result, err := a.sequences.result(tx, sentTx)
+	if err != nil {
+		return flow.EmptyID, err
+	}
+	if result.Error != nil {
+		return flow.EmptyID, result.Error
+	}
+
+	return sentTx.ID(), nil
+}
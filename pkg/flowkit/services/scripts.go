@@ -19,9 +19,15 @@
 package services
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
+	"github.com/onflow/cadence/runtime/parser"
+	"github.com/onflow/cadence/runtime/sema"
 
 	"github.com/onflow/flow-cli/pkg/flowkit"
 	"github.com/onflow/flow-cli/pkg/flowkit/config"
@@ -32,9 +38,11 @@ import (
 
 // Scripts is a service that handles all script-related interactions.
 type Scripts struct {
-	gateway gateway.Gateway
-	state   *flowkit.State
-	logger  output.Logger
+	gateway     gateway.Gateway
+	state       *flowkit.State
+	logger      output.Logger
+	tracing     *tracing
+	diagnostics *diagnostics
 }
 
 // NewScripts returns a new scripts service.
@@ -51,7 +59,223 @@ func NewScripts(
 }
 
 // Execute script code with passed arguments on the selected network.
-func (s *Scripts) Execute(script *flowkit.Script, network string) (cadence.Value, error) {
+func (s *Scripts) Execute(script *flowkit.Script, network string) (value cadence.Value, err error) {
+	err = s.diagnostics.run(s.tracing, "Scripts.Execute", []Attribute{
+		scriptInput("script", script),
+		{Key: "network", Value: network},
+	}, func(op *operation) error {
+		value, err = s.traced(op).execute(script, network)
+		return err
+	})
+	return value, err
+}
+
+func (s *Scripts) execute(script *flowkit.Script, network string) (cadence.Value, error) {
+	code, err := s.resolveImports(script, network)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.gateway.ExecuteScript(code, script.Args)
+}
+
+// Run executes the script registered in the configuration under the name with the named arguments on the network.
+//
+// The argument values are converted to the types declared for the script in the configuration, scripts registered
+// without declared arguments take the arguments named and typed by the parameters of the script.
+// Arguments of optional types can be omitted and are passed as nil.
+func (s *Scripts) Run(name string, args map[string]string, network string) (value cadence.Value, err error) {
+	err = s.diagnostics.run(s.tracing, "Scripts.Run", []Attribute{
+		{Key: "script", Value: name},
+		{Key: "network", Value: network},
+	}, func(op *operation) error {
+		value, err = s.traced(op).run(name, args, network)
+		return err
+	})
+	return value, err
+}
+
+func (s *Scripts) run(name string, args map[string]string, network string) (cadence.Value, error) {
+	if s.state == nil {
+		return nil, config.ErrDoesNotExist
+	}
+
+	registered, err := s.state.Scripts().ByName(name)
+	if err != nil {
+		names := s.state.Scripts().Names()
+		if len(names) == 0 {
+			return nil, fmt.Errorf("script %s is not registered, no scripts are registered in the configuration", name)
+		}
+		return nil, fmt.Errorf("script %s is not registered, registered scripts: %s", name, strings.Join(names, ", "))
+	}
+
+	code, err := s.state.ReadSource(registered.Location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script %s from %s: %w", name, registered.Location, err)
+	}
+
+	declared := registered.Arguments
+	if len(declared) == 0 {
+		declared, err = scriptParameters(code)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse script %s: %w", name, err)
+		}
+	}
+
+	values, err := namedArgumentValues(name, declared, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.execute(flowkit.NewScript(code, values, registered.Location), network)
+}
+
+// scriptParameters returns the parameters of the script main function as argument declarations.
+func scriptParameters(code []byte) ([]config.ScriptArgument, error) {
+	program, err := parser.ParseProgram(nil, code, parser.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	declaration := sema.FunctionEntryPointDeclaration(program)
+	if declaration == nil || declaration.ParameterList == nil {
+		return nil, nil
+	}
+
+	parameters := make([]config.ScriptArgument, len(declaration.ParameterList.Parameters))
+	for i, parameter := range declaration.ParameterList.Parameters {
+		parameters[i] = config.ScriptArgument{
+			Name: parameter.Identifier.Identifier,
+			Type: parameter.TypeAnnotation.Type.String(),
+		}
+	}
+
+	return parameters, nil
+}
+
+// namedArgumentValues converts the named argument values to the declared types in the declaration order.
+func namedArgumentValues(
+	script string,
+	declared []config.ScriptArgument,
+	args map[string]string,
+) ([]cadence.Value, error) {
+	names := make([]string, len(declared))
+	declaredNames := make(map[string]bool)
+	for i, arg := range declared {
+		names[i] = arg.Name
+		declaredNames[arg.Name] = true
+	}
+
+	unknown := make([]string, 0)
+	for name := range args {
+		if !declaredNames[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf(
+			"unknown arguments of script %s: %s, the script takes: %s",
+			script,
+			strings.Join(unknown, ", "),
+			strings.Join(names, ", "),
+		)
+	}
+
+	missing := make([]string, 0)
+	for _, arg := range declared {
+		if _, ok := args[arg.Name]; !ok && !arg.Optional() {
+			missing = append(missing, arg.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("missing required arguments of script %s: %s", script, strings.Join(missing, ", "))
+	}
+
+	values := make([]cadence.Value, len(declared))
+	for i, arg := range declared {
+		value, ok := args[arg.Name]
+		if !ok {
+			values[i] = cadence.NewOptional(nil)
+			continue
+		}
+
+		converted, err := flowkit.ParseArgumentWithType(arg.Name, arg.Type, value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid arguments of script %s: %w", script, err)
+		}
+		values[i] = converted
+	}
+
+	return values, nil
+}
+
+// UnsupportedGatewayError is returned when an operation is not supported by the gateway in use.
+type UnsupportedGatewayError struct {
+	Operation string
+}
+
+func (u *UnsupportedGatewayError) Error() string {
+	return fmt.Sprintf("%s is only supported on the emulator", u.Operation)
+}
+
+// ScriptProfile describes the computation used by a script execution.
+type ScriptProfile struct {
+	Value cadence.Value `json:"-"`
+	// Result is the JSON-Cadence encoded script result.
+	Result          json.RawMessage `json:"result"`
+	ComputationUsed uint64          `json:"computationUsed"`
+	// Breakdown is the metered intensity by operation kind, such as Loop, FunctionInvocation or GetValue for storage reads.
+	Breakdown      map[string]uint `json:"breakdown"`
+	MemoryEstimate uint64          `json:"memoryEstimate"`
+}
+
+// Profile executes the script with the arguments on the emulator and reports where the computation is used.
+//
+// Imports are resolved for the emulator network. Profiling is only supported by the emulator gateway,
+// other gateways return an UnsupportedGatewayError.
+func (s *Scripts) Profile(script *flowkit.Script, args []cadence.Value) (profile *ScriptProfile, err error) {
+	err = s.diagnostics.run(s.tracing, "Scripts.Profile", []Attribute{
+		scriptInput("script", script),
+	}, func(op *operation) error {
+		profile, err = s.traced(op).profile(script, args)
+		return err
+	})
+	return profile, err
+}
+
+func (s *Scripts) profile(script *flowkit.Script, args []cadence.Value) (*ScriptProfile, error) {
+	profiler, ok := s.gateway.(gateway.ScriptProfiler)
+	if !ok {
+		return nil, &UnsupportedGatewayError{Operation: "script profiling"}
+	}
+
+	code, err := s.resolveImports(script, config.DefaultEmulatorNetwork().Name)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := profiler.ProfileScript(code, args)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := jsoncdc.Encode(profile.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ScriptProfile{
+		Value:           profile.Value,
+		Result:          result,
+		ComputationUsed: profile.ComputationUsed,
+		Breakdown:       profile.ComputationIntensities,
+		MemoryEstimate:  profile.MemoryEstimate,
+	}, nil
+}
+
+// resolveImports returns the script code with imports replaced by the addresses of the contracts on the network.
+func (s *Scripts) resolveImports(script *flowkit.Script, network string) ([]byte, error) {
 	program, err := project.NewProgram(script)
 	if err != nil {
 		return nil, err
@@ -63,10 +287,7 @@ func (s *Scripts) Execute(script *flowkit.Script, network string) (cadence.Value
 			return nil, err
 		}
 
-		importReplacer := project.NewImportReplacer(
-			contracts,
-			s.state.AliasesForNetwork(network),
-		)
+		importReplacer := newImportReplacer(s.state, contracts, network)
 
 		if s.state == nil {
 			return nil, config.ErrDoesNotExist
@@ -82,7 +303,9 @@ func (s *Scripts) Execute(script *flowkit.Script, network string) (cadence.Value
 		if err != nil {
 			return nil, err
 		}
+
+		logImportWarnings(s.logger, importReplacer)
 	}
 
-	return s.gateway.ExecuteScript(program.Code(), script.Args)
+	return program.Code(), nil
 }
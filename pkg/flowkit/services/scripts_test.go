@@ -19,11 +19,13 @@
 package services
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/onflow/cadence"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"github.com/onflow/flow-cli/pkg/flowkit"
 	"github.com/onflow/flow-cli/pkg/flowkit/config"
@@ -51,6 +53,14 @@ func TestScripts(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("Profile Unsupported Gateway", func(t *testing.T) {
+		_, s, _ := setup()
+
+		_, err := s.Scripts.Profile(flowkit.NewScript(tests.ScriptLoop.Source, nil, ""), nil)
+
+		var unsupported *UnsupportedGatewayError
+		assert.ErrorAs(t, err, &unsupported)
+	})
 }
 
 func TestScripts_Integration(t *testing.T) {
@@ -128,6 +138,78 @@ func TestScripts_Integration(t *testing.T) {
 		assert.Equal(t, res.String(), "\"Hello Hello, World!\"")
 	})
 
+	t.Run("Execute Path And Type Arguments", func(t *testing.T) {
+		t.Parallel()
+		_, s := setupIntegration()
+
+		code := []byte(`
+			pub fun main(path: StoragePath, amount: Type, name: Type): String {
+				return path.toString().concat(" ").concat(amount.identifier).concat(" ").concat(name.identifier)
+			}`)
+
+		args, err := flowkit.ParseArgumentsWithoutType("", code, []string{"/storage/flowTokenVault", "UFix64", "String?"})
+		require.NoError(t, err)
+
+		res, err := s.Scripts.Execute(flowkit.NewScript(code, args, ""), "")
+		require.NoError(t, err)
+		assert.Equal(t, `"/storage/flowTokenVault UFix64 String?"`, res.String())
+	})
+
+	t.Run("Run Registered", func(t *testing.T) {
+		t.Parallel()
+		state, s := setupIntegration()
+
+		state.Scripts().AddOrUpdate("greet", config.Script{
+			Name:     "greet",
+			Location: tests.ScriptNamedArgs.Filename,
+			Arguments: []config.ScriptArgument{
+				{Name: "name", Type: "String"},
+				{Name: "count", Type: "Int"},
+				{Name: "suffix", Type: "String?"},
+			},
+		})
+		// without declared arguments the script parameters are used
+		state.Scripts().AddOrUpdate("greet-undeclared", config.Script{
+			Name:     "greet-undeclared",
+			Location: tests.ScriptNamedArgs.Filename,
+		})
+
+		res, err := s.Scripts.Run("greet", map[string]string{"count": "3", "name": "Foo", "suffix": "!"}, "")
+		require.NoError(t, err)
+		assert.Equal(t, `"Hello Foo x3!"`, res.String())
+
+		res, err = s.Scripts.Run("greet-undeclared", map[string]string{"count": "2", "name": "Bar"}, "")
+		require.NoError(t, err)
+		assert.Equal(t, `"Hello Bar x2"`, res.String())
+
+		_, err = s.Scripts.Run("greeting", nil, "")
+		assert.EqualError(t, err, "script greeting is not registered, registered scripts: greet, greet-undeclared")
+	})
+
+	t.Run("Run Registered Invalid Arguments", func(t *testing.T) {
+		t.Parallel()
+		state, s := setupIntegration()
+
+		state.Scripts().AddOrUpdate("greet", config.Script{
+			Name:     "greet",
+			Location: tests.ScriptNamedArgs.Filename,
+			Arguments: []config.ScriptArgument{
+				{Name: "name", Type: "String"},
+				{Name: "count", Type: "Int"},
+				{Name: "suffix", Type: "String?"},
+			},
+		})
+
+		_, err := s.Scripts.Run("greet", map[string]string{"name": "Foo"}, "")
+		assert.EqualError(t, err, "missing required arguments of script greet: count")
+
+		_, err = s.Scripts.Run("greet", map[string]string{"name": "Foo", "count": "three"}, "")
+		assert.EqualError(t, err, "invalid arguments of script greet: argument `count` is not expected type `Int`")
+
+		_, err = s.Scripts.Run("greet", map[string]string{"name": "Foo", "count": "3", "prefix": "Mr."}, "")
+		assert.EqualError(t, err, "unknown arguments of script greet: prefix, the script takes: name, count, suffix")
+	})
+
 	t.Run("Execute Script Invalid", func(t *testing.T) {
 		t.Parallel()
 		_, s := setupIntegration()
@@ -153,4 +235,53 @@ func TestScripts_Integration(t *testing.T) {
 		}
 
 	})
+	t.Run("Profile", func(t *testing.T) {
+		t.Parallel()
+		_, s := setupIntegration()
+
+		args := []cadence.Value{cadence.NewInt(100)}
+		script := flowkit.NewScript(tests.ScriptLoop.Source, args, tests.ScriptLoop.Filename)
+
+		profile, err := s.Scripts.Profile(script, args)
+		require.NoError(t, err)
+
+		value, err := s.Scripts.Execute(script, "")
+		require.NoError(t, err)
+		assert.Equal(t, value, profile.Value)
+		assert.Equal(t, cadence.NewInt(328350), profile.Value)
+
+		assert.NotEmpty(t, profile.Breakdown)
+		assert.GreaterOrEqual(t, profile.Breakdown["Loop"], uint(100))
+		assert.GreaterOrEqual(t, profile.Breakdown["FunctionInvocation"], uint(100))
+		assert.Greater(t, profile.ComputationUsed, uint64(0))
+		assert.Greater(t, profile.MemoryEstimate, uint64(0))
+
+		// profiling is deterministic and the computation grows with the iterations
+		again, err := s.Scripts.Profile(script, args)
+		require.NoError(t, err)
+		assert.Equal(t, profile.ComputationUsed, again.ComputationUsed)
+		assert.Equal(t, profile.Breakdown, again.Breakdown)
+
+		longer, err := s.Scripts.Profile(script, []cadence.Value{cadence.NewInt(200)})
+		require.NoError(t, err)
+		assert.Greater(t, longer.ComputationUsed, profile.ComputationUsed)
+		assert.Greater(t, longer.Breakdown["Loop"], profile.Breakdown["Loop"])
+
+		encoded, err := json.Marshal(profile)
+		require.NoError(t, err)
+
+		var decoded map[string]any
+		require.NoError(t, json.Unmarshal(encoded, &decoded))
+		assert.Equal(t, float64(profile.ComputationUsed), decoded["computationUsed"])
+		assert.Contains(t, decoded, "breakdown")
+		assert.Contains(t, decoded, "result")
+	})
+
+	t.Run("Profile Script Error", func(t *testing.T) {
+		t.Parallel()
+		_, s := setupIntegration()
+
+		_, err := s.Scripts.Profile(flowkit.NewScript(tests.ScriptLoop.Source, nil, ""), nil)
+		assert.Error(t, err)
+	})
 }
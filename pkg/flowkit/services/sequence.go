@@ -0,0 +1,373 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package services
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+
+	"github.com/onflow/flow-cli/pkg/flowkit"
+	"github.com/onflow/flow-cli/pkg/flowkit/gateway"
+)
+
+// SequenceManager hands out proposal key sequence numbers for transactions sent from this process.
+//
+// The sequence number of each account key is fetched from the chain on first use and then
+// incremented locally for each prepared transaction, so multiple transactions proposed with the
+// same key can be sent without waiting for each other. The sequence number is reconciled with
+// the chain when sending a transaction fails or its result reports an invalid sequence number.
+type SequenceManager struct {
+	gateway gateway.Gateway
+	*sequenceState
+}
+
+// sequenceState is the state of a sequence manager, shared with its views using other gateways.
+type sequenceState struct {
+	mu   sync.Mutex
+	keys map[sequenceKey]*keySequence
+	// resultOptions are the default options of the transaction results waited for.
+	resultOptions gateway.ResultOptions
+	// budget limits the FLOW spent by the sent transactions, nil if there is no budget.
+	budget *budgetTracker
+	// waitPolicy is the policy of the results waited for by the services honoring it,
+	// WaitDefault uses the default policy of the gateway.
+	waitPolicy gateway.WaitPolicy
+	// pollInterval is the time between polls of a result that hasn't reached the waited for status yet.
+	pollInterval time.Duration
+}
+
+type sequenceKey struct {
+	address  flow.Address
+	keyIndex int
+}
+
+type keySequence struct {
+	mu     sync.Mutex
+	next   uint64
+	synced bool
+}
+
+// NewSequenceManager returns a new sequence manager sending transactions using the gateway.
+func NewSequenceManager(gateway gateway.Gateway) *SequenceManager {
+	return &SequenceManager{
+		gateway: gateway,
+		sequenceState: &sequenceState{
+			keys:         make(map[sequenceKey]*keySequence),
+			pollInterval: defaultResultPollInterval,
+		},
+	}
+}
+
+// withGateway returns a view of the sequence manager sending transactions using the gateway,
+// sharing the sequence numbers, budget and options with the manager.
+func (m *SequenceManager) withGateway(gw gateway.Gateway) *SequenceManager {
+	if m == nil {
+		return m
+	}
+
+	return &SequenceManager{
+		gateway:       gw,
+		sequenceState: m.sequenceState,
+	}
+}
+
+// defaultResultPollInterval is the time between polls of a transaction result not yet executed or sealed.
+const defaultResultPollInterval = 200 * time.Millisecond
+
+// SetWaitPolicy sets the status the services honoring the policy wait for, like Accounts.Create and AddContract.
+//
+// WaitDefault, the initial policy, waits for results to be executed on the emulator and sealed on other networks.
+func (m *SequenceManager) SetWaitPolicy(policy gateway.WaitPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.waitPolicy = policy
+}
+
+// WaitPolicy returns the status the services honoring the policy wait for, never WaitDefault.
+func (m *SequenceManager) WaitPolicy() gateway.WaitPolicy {
+	m.mu.Lock()
+	policy := m.waitPolicy
+	m.mu.Unlock()
+
+	if policy == gateway.WaitDefault {
+		return gateway.DefaultWaitPolicy(m.gateway)
+	}
+	return policy
+}
+
+// SetResultOptions sets the options of the transaction results the services wait for, e.g. to skip
+// the events of large transactions.
+//
+// Services needing some events of a result to complete, like the address of a created account,
+// still retain those events.
+func (m *SequenceManager) SetResultOptions(options gateway.ResultOptions) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.resultOptions = options
+}
+
+// ResultOptions returns the default options of the transaction results the services wait for.
+func (m *SequenceManager) ResultOptions() gateway.ResultOptions {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.resultOptions
+}
+
+// SetBudget limits the FLOW the transactions sent from now on can spend, nil removes the budget.
+//
+// Each transaction is accounted for the estimated fee and any FLOW it transfers before it's sent, a transaction
+// exceeding the budget is not sent and a BudgetExceededError is returned. The estimated fee is replaced with
+// the actual fee once the result is received, if it contains the fee event.
+func (m *SequenceManager) SetBudget(budget *Budget) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if budget == nil {
+		m.budget = nil
+		return
+	}
+	m.budget = newBudgetTracker(*budget)
+}
+
+// BudgetState returns the FLOW spent within the budget so far, false if there is no budget.
+func (m *SequenceManager) BudgetState() (BudgetState, bool) {
+	budget := m.currentBudget()
+	if budget == nil {
+		return BudgetState{}, false
+	}
+	return budget.state(), true
+}
+
+func (m *SequenceManager) currentBudget() *budgetTracker {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.budget
+}
+
+func (m *SequenceManager) key(address flow.Address, keyIndex int) *keySequence {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	k := sequenceKey{address: address, keyIndex: keyIndex}
+	if _, ok := m.keys[k]; !ok {
+		m.keys[k] = &keySequence{}
+	}
+	return m.keys[k]
+}
+
+// Reserve reserves the next sequence number of the proposer key at the key index.
+//
+// The proposer account should be freshly fetched from the chain, its sequence number is used
+// on first use and when the chain is ahead of the local sequence.
+func (m *SequenceManager) Reserve(proposer *flow.Account, keyIndex int) (uint64, error) {
+	return m.sequenceNumber(proposer, keyIndex, true)
+}
+
+// Next returns the sequence number the next reservation of the proposer key at the key index gets,
+// without reserving it.
+func (m *SequenceManager) Next(proposer *flow.Account, keyIndex int) (uint64, error) {
+	return m.sequenceNumber(proposer, keyIndex, false)
+}
+
+func (m *SequenceManager) sequenceNumber(proposer *flow.Account, keyIndex int, reserve bool) (uint64, error) {
+	if len(proposer.Keys) <= keyIndex {
+		return 0, fmt.Errorf("failed to retrieve proposer key at index %d", keyIndex)
+	}
+	chainKey := proposer.Keys[keyIndex]
+
+	seq := m.key(proposer.Address, chainKey.Index)
+	seq.mu.Lock()
+	defer seq.mu.Unlock()
+
+	if !seq.synced || chainKey.SequenceNumber > seq.next {
+		seq.next = chainKey.SequenceNumber
+		seq.synced = true
+	}
+
+	next := seq.next
+	if reserve {
+		seq.next++
+	}
+
+	return next, nil
+}
+
+// markSent advances the local sequence of the proposal key past the sequence number used by a sent transaction,
+// which was not reserved if the transaction was built to be sent later.
+func (m *SequenceManager) markSent(key flow.ProposalKey) {
+	seq := m.key(key.Address, key.KeyIndex)
+	seq.mu.Lock()
+	defer seq.mu.Unlock()
+
+	if seq.synced && key.SequenceNumber >= seq.next {
+		seq.next = key.SequenceNumber + 1
+	}
+}
+
+// Reconcile discards the local sequence number of the key, so the next reservation uses the chain value.
+func (m *SequenceManager) Reconcile(address flow.Address, keyIndex int) {
+	seq := m.key(address, keyIndex)
+	seq.mu.Lock()
+	defer seq.mu.Unlock()
+
+	seq.synced = false
+}
+
+// setProposer sets the proposer of the transaction using the next sequence number, which is reserved
+// if the transaction is sent right away. Transactions built to be sent later don't reserve it, so a skipped
+// sequence number doesn't fail the following transactions if they are never sent.
+func (m *SequenceManager) setProposer(tx *flowkit.Transaction, proposer *flow.Account, keyIndex int, reserve bool) error {
+	sequenceNumber, err := m.sequenceNumber(proposer, keyIndex, reserve)
+	if err != nil {
+		return err
+	}
+
+	// copy the account so the fetched account isn't changed
+	reserved := *proposer
+	reserved.Keys = append([]*flow.AccountKey(nil), proposer.Keys...)
+	key := *proposer.Keys[keyIndex]
+	key.SequenceNumber = sequenceNumber
+	reserved.Keys[keyIndex] = &key
+
+	return tx.SetProposer(&reserved, keyIndex)
+}
+
+// send sends the signed transaction and reconciles the proposal key if sending fails.
+func (m *SequenceManager) send(tx *flowkit.Transaction) (*flow.Transaction, error) {
+	return m.sendWithTransfer(tx, 0)
+}
+
+// sendWithTransfer sends the signed transaction same as send, accounting the FLOW the transaction transfers
+// in the budget in addition to its fee.
+func (m *SequenceManager) sendWithTransfer(tx *flowkit.Transaction, transfer cadence.UFix64) (*flow.Transaction, error) {
+	budget := m.currentBudget()
+	if budget != nil {
+		if err := budget.reserve(tx, transfer); err != nil {
+			// the reserved sequence number is not used
+			m.reconcileProposalKey(tx.FlowTransaction())
+			return nil, err
+		}
+	}
+
+	sentTx, err := m.gateway.SendSignedTransaction(tx)
+	if err != nil {
+		if budget != nil {
+			budget.release(tx)
+		}
+		m.reconcileProposalKey(tx.FlowTransaction())
+		return nil, err
+	}
+	m.markSent(tx.FlowTransaction().ProposalKey)
+
+	return sentTx, nil
+}
+
+// result waits for the sent transaction to be sealed and reconciles the proposal key if the result
+// reports an invalid sequence number.
+//
+// The result contains the events retained by the default result options and the required event types.
+func (m *SequenceManager) result(
+	tx *flowkit.Transaction,
+	sentTx *flow.Transaction,
+	requiredEvents ...string,
+) (*flow.TransactionResult, error) {
+	return m.resultWithPolicy(tx, sentTx, gateway.WaitSealed, requiredEvents...)
+}
+
+// resultWithPolicy waits for the sent transaction result same as result, until the status of the policy is
+// reached. WaitDefault waits with the policy set on the manager.
+func (m *SequenceManager) resultWithPolicy(
+	tx *flowkit.Transaction,
+	sentTx *flow.Transaction,
+	policy gateway.WaitPolicy,
+	requiredEvents ...string,
+) (*flow.TransactionResult, error) {
+	budget := m.currentBudget()
+	if budget != nil {
+		requiredEvents = append(requiredEvents, feesDeductedEventTypes...)
+	}
+
+	if policy == gateway.WaitDefault {
+		policy = m.WaitPolicy()
+	}
+
+	options := m.ResultOptions().Require(requiredEvents...)
+	result, err := m.waitResult(sentTx.ID(), policy, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if budget != nil {
+		budget.settle(tx, result)
+	}
+
+	if result.Error != nil && strings.Contains(result.Error.Error(), "sequence number") {
+		m.reconcileProposalKey(tx.FlowTransaction())
+	}
+
+	return result, nil
+}
+
+// EnsureSealed waits for the transaction to be sealed and returns its final result.
+//
+// Results waited for with the WaitExecuted policy have the flow.TransactionStatusExecuted status,
+// callers requiring finality wait for the seal of the transaction before relying on it.
+func (m *SequenceManager) EnsureSealed(txID flow.Identifier) (*flow.TransactionResult, error) {
+	return m.waitResult(txID, gateway.WaitSealed, m.ResultOptions())
+}
+
+// waitResult polls the transaction result until the status waited for by the policy is reached.
+//
+// Gateways waiting for the seal themselves return once, others are polled, like the emulator that
+// returns the current status of the result. Expired transactions are returned as they are.
+func (m *SequenceManager) waitResult(
+	txID flow.Identifier,
+	policy gateway.WaitPolicy,
+	options gateway.ResultOptions,
+) (*flow.TransactionResult, error) {
+	status := flow.TransactionStatusSealed
+	if policy == gateway.WaitExecuted {
+		status = flow.TransactionStatusExecuted
+	}
+
+	for {
+		result, err := gateway.GetTransactionResultWithOptions(m.gateway, txID, policy != gateway.WaitExecuted, options)
+		if err != nil {
+			return nil, err
+		}
+		if result.Status >= status {
+			return result, nil
+		}
+
+		time.Sleep(m.pollInterval)
+	}
+}
+
+func (m *SequenceManager) reconcileProposalKey(tx *flow.Transaction) {
+	m.Reconcile(tx.ProposalKey.Address, tx.ProposalKey.KeyIndex)
+}
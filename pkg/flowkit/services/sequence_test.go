@@ -0,0 +1,327 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package services
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-cli/pkg/flowkit"
+	"github.com/onflow/flow-cli/pkg/flowkit/gateway"
+	"github.com/onflow/flow-cli/pkg/flowkit/output"
+	"github.com/onflow/flow-cli/pkg/flowkit/tests"
+)
+
+// setupSequences returns services with a mock gateway reporting the chain sequence number
+// and records the sequence numbers of the sent transactions.
+func setupSequences(chainSequence *uint64, mu *sync.Mutex) (*Services, *tests.TestGateway, *[]uint64) {
+	_, s, gw := setup()
+	sent := make([]uint64, 0)
+
+	gw.GetAccount.Run(func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		account := tests.NewAccountWithAddress(args.Get(0).(flow.Address).String())
+		account.Keys[0].Index = 0
+		account.Keys[0].SequenceNumber = *chainSequence
+		account.Contracts = map[string][]byte{"Foo": []byte("pub contract Foo {}")}
+		gw.GetAccount.Return(account, nil)
+	})
+
+	gw.SendSignedTransaction.Run(func(args mock.Arguments) {
+		mu.Lock()
+		defer mu.Unlock()
+		tx := args.Get(0).(*flowkit.Transaction)
+		sent = append(sent, tx.FlowTransaction().ProposalKey.SequenceNumber)
+		gw.SendSignedTransaction.Return(tests.NewTransaction(), nil)
+	})
+
+	return s, gw, &sent
+}
+
+func sendSimple(s *Services, account *flowkit.Account) (*flow.TransactionResult, error) {
+	_, result, err := s.Transactions.Send(
+		NewSingleTransactionAccount(account),
+		flowkit.NewScript(tests.TransactionSingleAuth.Source, nil, ""),
+		gasLimit,
+		"",
+	)
+	return result, err
+}
+
+func TestSequenceManager(t *testing.T) {
+	signer := tests.Alice()
+
+	t.Run("Concurrent Transactions", func(t *testing.T) {
+		var mu sync.Mutex
+		chainSequence := uint64(5)
+		s, gw, sent := setupSequences(&chainSequence, &mu)
+
+		const count = 20
+		var wg sync.WaitGroup
+		for i := 0; i < count; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := sendSimple(s, signer)
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		gw.Mock.AssertNumberOfCalls(t, tests.SendSignedTransactionFunc, count)
+		require.Len(t, *sent, count)
+
+		sequences := append([]uint64(nil), *sent...)
+		sort.Slice(sequences, func(i, j int) bool { return sequences[i] < sequences[j] })
+		for i, seq := range sequences {
+			assert.Equal(t, chainSequence+uint64(i), seq)
+		}
+	})
+
+	t.Run("Sequence shared between services", func(t *testing.T) {
+		var mu sync.Mutex
+		chainSequence := uint64(0)
+		s, _, sent := setupSequences(&chainSequence, &mu)
+
+		_, err := sendSimple(s, signer)
+		require.NoError(t, err)
+
+		_, err = s.Accounts.RemoveContract(signer, "Foo")
+		require.NoError(t, err)
+
+		assert.Equal(t, []uint64{0, 1}, *sent)
+	})
+
+	t.Run("Reconcile after failed submission", func(t *testing.T) {
+		var mu sync.Mutex
+		chainSequence := uint64(5)
+		s, gw, sent := setupSequences(&chainSequence, &mu)
+
+		_, err := sendSimple(s, signer)
+		require.NoError(t, err)
+		chainSequence = 6
+
+		// reserved sequence number 6 is never used on chain as the submission fails
+		gw.SendSignedTransaction.Run(func(args mock.Arguments) {
+			gw.SendSignedTransaction.Return(nil, fmt.Errorf("failed to submit transaction"))
+		})
+		_, err = sendSimple(s, signer)
+		require.Error(t, err)
+
+		gw.SendSignedTransaction.Run(func(args mock.Arguments) {
+			tx := args.Get(0).(*flowkit.Transaction)
+			*sent = append(*sent, tx.FlowTransaction().ProposalKey.SequenceNumber)
+			gw.SendSignedTransaction.Return(tests.NewTransaction(), nil)
+		})
+		_, err = sendSimple(s, signer)
+		require.NoError(t, err)
+
+		assert.Equal(t, []uint64{5, 6}, *sent)
+	})
+
+	t.Run("Reconcile after sequence mismatch result", func(t *testing.T) {
+		var mu sync.Mutex
+		chainSequence := uint64(5)
+		s, gw, sent := setupSequences(&chainSequence, &mu)
+
+		_, err := sendSimple(s, signer)
+		require.NoError(t, err)
+
+		// the transaction was not executed because of invalid sequence number, so chain stays at 5
+		mismatch := tests.NewTransactionResult(nil)
+		mismatch.Error = fmt.Errorf("invalid proposal key: public key 0 does not have a valid sequence number")
+		gw.GetTransactionResult.Return(mismatch, nil)
+		_, err = sendSimple(s, signer)
+		require.NoError(t, err)
+
+		gw.GetTransactionResult.Return(tests.NewTransactionResult(nil), nil)
+		_, err = sendSimple(s, signer)
+		require.NoError(t, err)
+
+		assert.Equal(t, []uint64{5, 6, 5}, *sent)
+	})
+
+	t.Run("Chain ahead of local sequence", func(t *testing.T) {
+		var mu sync.Mutex
+		chainSequence := uint64(5)
+		s, _, sent := setupSequences(&chainSequence, &mu)
+
+		_, err := sendSimple(s, signer)
+		require.NoError(t, err)
+
+		chainSequence = 10 // key used by another process
+		_, err = sendSimple(s, signer)
+		require.NoError(t, err)
+
+		assert.Equal(t, []uint64{5, 10}, *sent)
+	})
+
+	t.Run("Built transactions don't reserve", func(t *testing.T) {
+		var mu sync.Mutex
+		chainSequence := uint64(5)
+		s, _, sent := setupSequences(&chainSequence, &mu)
+
+		built, err := s.Transactions.Build(
+			NewSingleTransactionAccount(signer).toAddresses(),
+			signer.Key().Index(),
+			flowkit.NewScript(tests.TransactionSingleAuth.Source, nil, ""),
+			gasLimit,
+			"",
+		)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(5), built.FlowTransaction().ProposalKey.SequenceNumber)
+
+		// the built transaction is never sent
+		_, err = sendSimple(s, signer)
+		require.NoError(t, err)
+
+		// a transaction built while another one is in flight uses the next sequence number
+		built, err = s.Transactions.Build(
+			NewSingleTransactionAccount(signer).toAddresses(),
+			signer.Key().Index(),
+			flowkit.NewScript(tests.TransactionSingleAuth.Source, nil, ""),
+			gasLimit,
+			"",
+		)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(6), built.FlowTransaction().ProposalKey.SequenceNumber)
+
+		require.NoError(t, built.SetSigner(signer))
+		built, err = built.Sign()
+		require.NoError(t, err)
+		_, _, err = s.Transactions.SendSigned(built)
+		require.NoError(t, err)
+
+		_, err = sendSimple(s, signer)
+		require.NoError(t, err)
+
+		assert.Equal(t, []uint64{5, 6, 7}, *sent)
+	})
+}
+
+// scriptResultStatuses makes the mock gateway return results with the statuses in sequence, the last status is
+// repeated, and records whether each call waited for the seal.
+func scriptResultStatuses(gw *tests.TestGateway, statuses ...flow.TransactionStatus) *[]bool {
+	waitSeals := make([]bool, 0)
+	gw.GetTransactionResult.Run(func(args mock.Arguments) {
+		waitSeals = append(waitSeals, args.Get(1).(bool))
+		result := tests.NewTransactionResult(nil)
+		result.Status = statuses[0]
+		if len(statuses) > 1 {
+			statuses = statuses[1:]
+		}
+		gw.GetTransactionResult.Return(result, nil)
+	})
+
+	return &waitSeals
+}
+
+func TestWaitPolicy(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Default Policy", func(t *testing.T) {
+		_, s, _ := setup()
+		assert.Equal(t, gateway.WaitSealed, s.Sequences.WaitPolicy())
+
+		s.SetWaitPolicy(gateway.WaitExecuted)
+		assert.Equal(t, gateway.WaitExecuted, s.Sequences.WaitPolicy())
+	})
+
+	t.Run("Emulator Default Policy", func(t *testing.T) {
+		_, s := setupIntegration()
+		assert.Equal(t, gateway.WaitExecuted, s.Sequences.WaitPolicy())
+
+		traced := NewServicesWithTracer(s.Accounts.gateway, nil, output.NewStdoutLogger(output.NoneLog), nil)
+		assert.Equal(t, gateway.WaitExecuted, traced.Sequences.WaitPolicy())
+
+		s.SetWaitPolicy(gateway.WaitSealed)
+		assert.Equal(t, gateway.WaitSealed, s.Sequences.WaitPolicy())
+	})
+
+	t.Run("Wait Executed", func(t *testing.T) {
+		_, s, gw := setup()
+		s.SetWaitPolicy(gateway.WaitExecuted)
+		s.Sequences.pollInterval = 0
+		waitSeals := scriptResultStatuses(
+			gw,
+			flow.TransactionStatusPending,
+			flow.TransactionStatusFinalized,
+			flow.TransactionStatusExecuted,
+			flow.TransactionStatusSealed,
+		)
+
+		result, err := s.Sequences.resultWithPolicy(flowkit.NewTransaction(), tests.NewTransaction(), gateway.WaitDefault)
+		require.NoError(t, err)
+		assert.Equal(t, flow.TransactionStatusExecuted, result.Status)
+		assert.Equal(t, []bool{false, false, false}, *waitSeals)
+	})
+
+	t.Run("Wait Sealed", func(t *testing.T) {
+		_, s, gw := setup()
+		s.SetWaitPolicy(gateway.WaitExecuted)
+		s.Sequences.pollInterval = 0
+		waitSeals := scriptResultStatuses(gw, flow.TransactionStatusExecuted, flow.TransactionStatusSealed)
+
+		// results waited for without a policy are always sealed
+		result, err := s.Sequences.result(flowkit.NewTransaction(), tests.NewTransaction())
+		require.NoError(t, err)
+		assert.Equal(t, flow.TransactionStatusSealed, result.Status)
+		assert.Equal(t, []bool{true, true}, *waitSeals)
+	})
+
+	t.Run("Ensure Sealed", func(t *testing.T) {
+		_, s, gw := setup()
+		s.SetWaitPolicy(gateway.WaitExecuted)
+		s.Sequences.pollInterval = 0
+		scriptResultStatuses(
+			gw,
+			flow.TransactionStatusExecuted,
+			flow.TransactionStatusExecuted,
+			flow.TransactionStatusSealed,
+		)
+		sentTx := tests.NewTransaction()
+
+		result, err := s.Sequences.resultWithPolicy(flowkit.NewTransaction(), sentTx, gateway.WaitDefault)
+		require.NoError(t, err)
+		assert.Equal(t, flow.TransactionStatusExecuted, result.Status)
+
+		result, err = s.Sequences.EnsureSealed(sentTx.ID())
+		require.NoError(t, err)
+		assert.Equal(t, flow.TransactionStatusSealed, result.Status)
+		gw.Mock.AssertNumberOfCalls(t, tests.GetTransactionResultFunc, 3)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		_, s, gw := setup()
+		s.Sequences.pollInterval = 0
+		scriptResultStatuses(gw, flow.TransactionStatusPending, flow.TransactionStatusExpired)
+
+		result, err := s.Sequences.EnsureSealed(tests.NewTransaction().ID())
+		require.NoError(t, err)
+		assert.Equal(t, flow.TransactionStatusExpired, result.Status)
+	})
+}
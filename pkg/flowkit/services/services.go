@@ -22,6 +22,7 @@ import (
 	"github.com/onflow/flow-cli/pkg/flowkit"
 	"github.com/onflow/flow-cli/pkg/flowkit/gateway"
 	"github.com/onflow/flow-cli/pkg/flowkit/output"
+	"github.com/onflow/flow-cli/pkg/flowkit/project"
 )
 
 // Services is a collection of services that provide domain-specific functionality
@@ -38,6 +39,12 @@ type Services struct {
 	Status       *Status
 	Snapshot     *Snapshot
 	Tests        *Tests
+	Staking      *Staking
+	Sequences    *SequenceManager
+	// Redactor hides sensitive transaction arguments wherever they are displayed.
+	Redactor *flowkit.Redactor
+
+	diagnostics *diagnostics
 }
 
 // NewServices returns a new services collection for a state,
@@ -47,7 +54,7 @@ func NewServices(
 	state *flowkit.State,
 	logger output.Logger,
 ) *Services {
-	return &Services{
+	s := &Services{
 		Accounts:     NewAccounts(gateway, state, logger),
 		Scripts:      NewScripts(gateway, state, logger),
 		Transactions: NewTransactions(gateway, state, logger),
@@ -59,7 +66,70 @@ func NewServices(
 		Status:       NewStatus(gateway, state, logger),
 		Snapshot:     NewSnapshot(gateway, state, logger),
 		Tests:        NewTests(state, logger),
+		Staking:      NewStaking(gateway, state, logger),
+		Sequences:    NewSequenceManager(gateway),
+		Redactor:     flowkit.NewRedactor(),
+		diagnostics:  newDiagnostics(state),
 	}
+
+	// share sequence numbers between all services sending transactions
+	s.Accounts.sequences = s.Sequences
+	s.Transactions.sequences = s.Sequences
+	s.Project.sequences = s.Sequences
+	s.Staking.sequences = s.Sequences
+
+	// record the operations of the public services for the diagnostic bundle
+	s.Accounts.diagnostics = s.diagnostics
+	s.Scripts.diagnostics = s.diagnostics
+	s.Transactions.diagnostics = s.diagnostics
+	s.Events.diagnostics = s.diagnostics
+	s.Project.diagnostics = s.diagnostics
+
+	return s
+}
+
+// NewServicesWithTracer returns a new services collection same as NewServices,
+// with service operations and gateway calls traced using the provided tracer.
+func NewServicesWithTracer(
+	gateway gateway.Gateway,
+	state *flowkit.State,
+	logger output.Logger,
+	tracer Tracer,
+) *Services {
+	t := newTracing(tracer)
+	s := NewServices(newTracingGateway(gateway, t), state, logger)
+	s.Accounts.tracing = t
+	s.Scripts.tracing = t
+	s.Project.tracing = t
+
+	return s
+}
+
+// SetBudget limits the FLOW spent on fees and funding by the transactions the services send, nil removes the budget.
+//
+// The FLOW spent so far is returned by Sequences.BudgetState.
+func (s *Services) SetBudget(budget *Budget) {
+	s.Sequences.SetBudget(budget)
+}
+
+// SetWaitPolicy sets the status the services wait for transactions to reach, where they honor the policy.
+//
+// Accounts.Create and AddContract return as soon as their transaction is executed with WaitExecuted,
+// the default on the emulator. Sequences.EnsureSealed waits for the seal of a transaction afterwards.
+func (s *Services) SetWaitPolicy(policy gateway.WaitPolicy) {
+	s.Sequences.SetWaitPolicy(policy)
+}
+
+// resolveSigner returns the signer if provided, otherwise the default signer configured for the network.
+func resolveSigner(state *flowkit.State, signer *flowkit.Account, network string) (*flowkit.Account, error) {
+	if signer != nil {
+		return signer, nil
+	}
+	if state == nil {
+		return nil, &flowkit.NoSignerError{Network: network}
+	}
+
+	return state.DefaultSignerForNetwork(network)
 }
 
 func (s *Services) SetLogger(logger output.Logger) {
@@ -74,4 +144,35 @@ func (s *Services) SetLogger(logger output.Logger) {
 	s.Status.logger = logger
 	s.Snapshot.logger = logger
 	s.Tests.logger = logger
+	s.Staking.logger = logger
+}
+
+// newImportReplacer returns an import replacer for the contracts and the aliases of the network,
+// bounding path imports to the project root.
+func newImportReplacer(
+	state *flowkit.State,
+	contracts []*project.Contract,
+	network string,
+	options ...project.ImportReplacerOption,
+) *project.ImportReplacer {
+	replacer := project.NewImportReplacer(contracts, state.AliasesForNetwork(network), options...)
+	replacer.SetRoot(state.Root())
+	return replacer
+}
+
+// newDeployment returns the deployment of the contracts, bounding path imports to the project root.
+func newDeployment(state *flowkit.State, contracts []*project.Contract) (*project.Deployment, error) {
+	deployment, err := project.NewDeployment(contracts)
+	if err != nil {
+		return nil, err
+	}
+	deployment.SetRoot(state.Root())
+	return deployment, nil
+}
+
+// logImportWarnings logs any warnings the import replacer reported while resolving imports.
+func logImportWarnings(logger output.Logger, replacer *project.ImportReplacer) {
+	for _, warning := range replacer.Warnings() {
+		output.Warn(logger, warning.String())
+	}
 }
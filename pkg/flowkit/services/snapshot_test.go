@@ -0,0 +1,54 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package services
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshot(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Get Latest Protocol State Snapshot", func(t *testing.T) {
+		_, s, gw := setup()
+		snapshot := []byte{0x0a, 0x02, 0x08, 0x01}
+
+		gw.Mock.On("SecureConnection").Return(true)
+		gw.Mock.On("GetLatestProtocolStateSnapshot").Return(snapshot, nil)
+
+		b, err := s.Snapshot.GetLatestProtocolStateSnapshot()
+
+		assert.NoError(t, err)
+		assert.Equal(t, snapshot, b)
+		gw.Mock.AssertCalled(t, "GetLatestProtocolStateSnapshot")
+	})
+
+	t.Run("Get Latest Protocol State Snapshot Fails", func(t *testing.T) {
+		_, s, gw := setup()
+
+		gw.Mock.On("SecureConnection").Return(false)
+		gw.Mock.On("GetLatestProtocolStateSnapshot").Return(nil, fmt.Errorf("unavailable"))
+
+		_, err := s.Snapshot.GetLatestProtocolStateSnapshot()
+
+		assert.EqualError(t, err, "failed to get latest finalized protocol snapshot from gateway: unavailable")
+	})
+}
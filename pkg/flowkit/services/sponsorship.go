@@ -0,0 +1,340 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+
+	"github.com/onflow/flow-cli/pkg/flowkit"
+)
+
+// ErrInvalidPayerSignature is returned when the envelope signature of a remote payer doesn't verify
+// against the payer key on the network.
+var ErrInvalidPayerSignature = errors.New("invalid payer signature")
+
+// TxSummary describes the transaction a remote payer is asked to sign, so the payer can decide whether to sponsor it.
+type TxSummary struct {
+	Proposer         flow.Address
+	Payer            flow.Address
+	Authorizers      []flow.Address
+	ScriptHash       string
+	Arguments        int
+	GasLimit         uint64
+	ReferenceBlockID flow.Identifier
+}
+
+func newTxSummary(tx *flow.Transaction) TxSummary {
+	return TxSummary{
+		Proposer:         tx.ProposalKey.Address,
+		Payer:            tx.Payer,
+		Authorizers:      tx.Authorizers,
+		ScriptHash:       flowkit.ScriptHash(tx.Script),
+		Arguments:        len(tx.Arguments),
+		GasLimit:         tx.GasLimit,
+		ReferenceBlockID: tx.ReferenceBlockID,
+	}
+}
+
+// PayerProvider signs the transaction envelope on behalf of a payer whose keys are not available locally.
+//
+// The canonical envelope is the exact message to sign, including the transaction domain tag.
+// The returned key index is the index of the payer key that produced the signature.
+type PayerProvider interface {
+	SignEnvelope(ctx context.Context, canonicalEnvelope []byte, txSummary TxSummary) (signature []byte, keyIndex int, err error)
+}
+
+// LocalPayerProvider signs the envelope with the key of an account in the state.
+type LocalPayerProvider struct {
+	account *flowkit.Account
+}
+
+var _ PayerProvider = &LocalPayerProvider{}
+
+// NewLocalPayerProvider returns a payer provider signing with the account key.
+func NewLocalPayerProvider(account *flowkit.Account) *LocalPayerProvider {
+	return &LocalPayerProvider{account: account}
+}
+
+func (l *LocalPayerProvider) SignEnvelope(ctx context.Context, canonicalEnvelope []byte, _ TxSummary) ([]byte, int, error) {
+	signer, err := l.account.Key().Signer(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	signature, err := signer.Sign(canonicalEnvelope)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return signature, l.account.Key().Index(), nil
+}
+
+const (
+	// DefaultSponsorTimeout is the default timeout of a single request to a sponsorship service.
+	DefaultSponsorTimeout = 10 * time.Second
+	// SponsorTimestampHeader contains the unix time at which the request was signed.
+	SponsorTimestampHeader = "X-Flow-Sponsor-Timestamp"
+	// SponsorSignatureHeader contains the hex encoded HMAC-SHA256 of the timestamp and the request body.
+	SponsorSignatureHeader = "X-Flow-Sponsor-Signature"
+)
+
+// HTTPPayerOptions configures the requests to a sponsorship service.
+type HTTPPayerOptions struct {
+	// Secret is the shared key used to sign the requests, requests are not signed if empty.
+	Secret []byte
+	// Timeout of a single request, defaults to DefaultSponsorTimeout.
+	Timeout time.Duration
+	// Retries is the number of times a failed request is repeated.
+	Retries int
+	// RetryDelay is the time to wait before repeating a failed request.
+	RetryDelay time.Duration
+	// Client used to send the requests, defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// HTTPPayerProvider requests the envelope signature from a sponsorship service over HTTP.
+//
+// The service receives a POST request with a JSON body containing the hex encoded envelope
+// and the transaction summary, and responds with the hex encoded signature and the key index.
+// If a secret is configured the request is signed with HMAC-SHA256 over the timestamp and the body,
+// joined with a dot, so the service can authenticate the request.
+type HTTPPayerProvider struct {
+	endpoint string
+	options  HTTPPayerOptions
+}
+
+var _ PayerProvider = &HTTPPayerProvider{}
+
+// NewHTTPPayerProvider returns a payer provider requesting signatures from the endpoint.
+func NewHTTPPayerProvider(endpoint string, options HTTPPayerOptions) *HTTPPayerProvider {
+	if options.Timeout == 0 {
+		options.Timeout = DefaultSponsorTimeout
+	}
+	if options.Client == nil {
+		options.Client = http.DefaultClient
+	}
+
+	return &HTTPPayerProvider{
+		endpoint: endpoint,
+		options:  options,
+	}
+}
+
+// SponsorRequest is the body of the request sent to a sponsorship service.
+type SponsorRequest struct {
+	Envelope         string   `json:"envelope"`
+	Proposer         string   `json:"proposer"`
+	Payer            string   `json:"payer"`
+	Authorizers      []string `json:"authorizers"`
+	ScriptHash       string   `json:"scriptHash"`
+	Arguments        int      `json:"arguments"`
+	GasLimit         uint64   `json:"gasLimit"`
+	ReferenceBlockID string   `json:"referenceBlockId"`
+}
+
+// SponsorResponse is the body of the response returned by a sponsorship service.
+type SponsorResponse struct {
+	Signature string `json:"signature"`
+	KeyIndex  int    `json:"keyIndex"`
+}
+
+// SignSponsorRequest returns the HMAC-SHA256 signature of the request body at the timestamp.
+func SignSponsorRequest(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (h *HTTPPayerProvider) SignEnvelope(ctx context.Context, canonicalEnvelope []byte, txSummary TxSummary) ([]byte, int, error) {
+	authorizers := make([]string, len(txSummary.Authorizers))
+	for i, a := range txSummary.Authorizers {
+		authorizers[i] = a.Hex()
+	}
+
+	body, err := json.Marshal(SponsorRequest{
+		Envelope:         hex.EncodeToString(canonicalEnvelope),
+		Proposer:         txSummary.Proposer.Hex(),
+		Payer:            txSummary.Payer.Hex(),
+		Authorizers:      authorizers,
+		ScriptHash:       txSummary.ScriptHash,
+		Arguments:        txSummary.Arguments,
+		GasLimit:         txSummary.GasLimit,
+		ReferenceBlockID: txSummary.ReferenceBlockID.String(),
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var response *SponsorResponse
+	for attempt := 0; ; attempt++ {
+		var retry bool
+		response, retry, err = h.request(ctx, body)
+		if err == nil || !retry || attempt >= h.options.Retries {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, 0, ctx.Err()
+		case <-time.After(h.options.RetryDelay):
+		}
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get payer signature from %s: %w", h.endpoint, err)
+	}
+
+	signature, err := hex.DecodeString(response.Signature)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to decode payer signature: %w", err)
+	}
+
+	return signature, response.KeyIndex, nil
+}
+
+// request sends a single request and returns whether it can be retried if it fails.
+func (h *HTTPPayerProvider) request(ctx context.Context, body []byte) (*SponsorResponse, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.options.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if len(h.options.Secret) > 0 {
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(SponsorTimestampHeader, timestamp)
+		req.Header.Set(SponsorSignatureHeader, SignSponsorRequest(h.options.Secret, timestamp, body))
+	}
+
+	res, err := h.options.Client.Do(req)
+	if err != nil {
+		return nil, true, err
+	}
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, true, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, res.StatusCode >= http.StatusInternalServerError, fmt.Errorf(
+			"sponsorship service responded with status %d: %s",
+			res.StatusCode,
+			bytes.TrimSpace(resBody),
+		)
+	}
+
+	var response SponsorResponse
+	if err := json.Unmarshal(resBody, &response); err != nil {
+		return nil, false, fmt.Errorf("failed to decode sponsorship service response: %w", err)
+	}
+
+	return &response, false, nil
+}
+
+// SetPayerProvider marks the payer as remote, the envelope of transactions paid by the payer is
+// signed by the provider instead of a local key. A nil provider marks the payer as local again.
+func (t *Transactions) SetPayerProvider(payer flow.Address, provider PayerProvider) {
+	if provider == nil {
+		delete(t.payers, payer)
+		return
+	}
+	t.payers[payer] = provider
+}
+
+// signEnvelopeRemotely requests the envelope signature from the provider and adds it to the transaction
+// after verifying it against the payer key on the network.
+func (t *Transactions) signEnvelopeRemotely(tx *flowkit.Transaction, provider PayerProvider) error {
+	flowTx := tx.FlowTransaction()
+	envelope := append(flow.TransactionDomainTag[:], flowTx.EnvelopeMessage()...)
+
+	signature, keyIndex, err := provider.SignEnvelope(context.Background(), envelope, newTxSummary(flowTx))
+	if err != nil {
+		return err
+	}
+
+	payer, err := t.gateway.GetAccount(flowTx.Payer)
+	if err != nil {
+		return fmt.Errorf("failed to get payer account: %w", err)
+	}
+
+	if err := verifyPayerSignature(payer, keyIndex, envelope, signature); err != nil {
+		return err
+	}
+
+	flowTx.AddEnvelopeSignature(flowTx.Payer, keyIndex, signature)
+	return nil
+}
+
+func verifyPayerSignature(payer *flow.Account, keyIndex int, message []byte, signature []byte) error {
+	if keyIndex < 0 || keyIndex >= len(payer.Keys) {
+		return fmt.Errorf("%w: account 0x%s has no key at index %d", ErrInvalidPayerSignature, payer.Address, keyIndex)
+	}
+
+	key := payer.Keys[keyIndex]
+	if key.Revoked {
+		return fmt.Errorf("%w: key %d of account 0x%s is revoked", ErrInvalidPayerSignature, keyIndex, payer.Address)
+	}
+
+	if key.Weight < flow.AccountKeyWeightThreshold {
+		return fmt.Errorf(
+			"%w: key %d of account 0x%s has weight %d, which is not enough to pay for the transaction",
+			ErrInvalidPayerSignature,
+			keyIndex,
+			payer.Address,
+			key.Weight,
+		)
+	}
+
+	hasher, err := crypto.NewHasher(key.HashAlgo)
+	if err != nil {
+		return err
+	}
+
+	valid, err := key.PublicKey.Verify(signature, message, hasher)
+	if err != nil || !valid {
+		return fmt.Errorf(
+			"%w: signature doesn't match key %d of account 0x%s",
+			ErrInvalidPayerSignature,
+			keyIndex,
+			payer.Address,
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,237 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package services
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/onflow/flow-go-sdk"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-cli/pkg/flowkit"
+	"github.com/onflow/flow-cli/pkg/flowkit/tests"
+)
+
+var sponsorSecret = []byte("sponsor-secret")
+
+// newSponsorServer returns a stub sponsorship service signing the envelope with the payer account.
+func newSponsorServer(t *testing.T, payer *flowkit.Account, sign func([]byte) ([]byte, error)) *httptest.Server {
+	provider := NewLocalPayerProvider(payer)
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		signature := SignSponsorRequest(sponsorSecret, r.Header.Get(SponsorTimestampHeader), body)
+		if r.Header.Get(SponsorSignatureHeader) != signature {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var req SponsorRequest
+		require.NoError(t, json.Unmarshal(body, &req))
+		assert.Equal(t, payer.Address().Hex(), req.Payer)
+
+		envelope, err := hex.DecodeString(req.Envelope)
+		require.NoError(t, err)
+
+		var sig []byte
+		if sign != nil {
+			sig, err = sign(envelope)
+		} else {
+			sig, _, err = provider.SignEnvelope(r.Context(), envelope, TxSummary{})
+		}
+		require.NoError(t, err)
+
+		_ = json.NewEncoder(w).Encode(SponsorResponse{
+			Signature: hex.EncodeToString(sig),
+			KeyIndex:  payer.Key().Index(),
+		})
+	}))
+}
+
+func TestSponsoredTransactions_Integration(t *testing.T) {
+	t.Parallel()
+
+	send := func(s *Services, proposer *flowkit.Account, payer *flowkit.Account) (*flow.Transaction, *flow.TransactionResult, error) {
+		roles, err := NewTransactionAccountRoles(proposer, payer, []*flowkit.Account{proposer})
+		require.NoError(t, err)
+
+		return s.Transactions.Send(
+			roles,
+			flowkit.NewScript(tests.TransactionSingleAuth.Source, nil, tests.TransactionSingleAuth.Filename),
+			flow.DefaultTransactionGasLimit,
+			"",
+		)
+	}
+
+	t.Run("Send With HTTP Sponsor", func(t *testing.T) {
+		t.Parallel()
+		state, s := setupIntegration()
+		setupAccounts(state, s)
+
+		a, _ := state.Accounts().ByName("Alice")
+		b, _ := state.Accounts().ByName("Bob")
+
+		server := newSponsorServer(t, b, nil)
+		defer server.Close()
+
+		s.Transactions.SetPayerProvider(b.Address(), NewHTTPPayerProvider(server.URL, HTTPPayerOptions{
+			Secret: sponsorSecret,
+		}))
+
+		tx, txr, err := send(s, a, b)
+		require.NoError(t, err)
+		assert.Equal(t, b.Address(), tx.Payer)
+		require.Len(t, tx.EnvelopeSignatures, 1)
+		assert.Equal(t, b.Address(), tx.EnvelopeSignatures[0].Address)
+		assert.Nil(t, txr.Error)
+		assert.Equal(t, flow.TransactionStatusSealed, txr.Status)
+	})
+
+	t.Run("Send With Local Sponsor", func(t *testing.T) {
+		t.Parallel()
+		state, s := setupIntegration()
+		setupAccounts(state, s)
+
+		a, _ := state.Accounts().ByName("Alice")
+		b, _ := state.Accounts().ByName("Bob")
+		s.Transactions.SetPayerProvider(b.Address(), NewLocalPayerProvider(b))
+
+		_, txr, err := send(s, a, b)
+		require.NoError(t, err)
+		assert.Nil(t, txr.Error)
+	})
+
+	t.Run("Fail Invalid Signature", func(t *testing.T) {
+		t.Parallel()
+		state, s := setupIntegration()
+		setupAccounts(state, s)
+
+		a, _ := state.Accounts().ByName("Alice")
+		b, _ := state.Accounts().ByName("Bob")
+		c, _ := state.Accounts().ByName("Charlie")
+
+		// the sponsor is misconfigured and signs with a different key than the payer key
+		wrongSigner := NewLocalPayerProvider(c)
+		server := newSponsorServer(t, b, func(envelope []byte) ([]byte, error) {
+			sig, _, err := wrongSigner.SignEnvelope(context.Background(), envelope, TxSummary{})
+			return sig, err
+		})
+		defer server.Close()
+
+		s.Transactions.SetPayerProvider(b.Address(), NewHTTPPayerProvider(server.URL, HTTPPayerOptions{
+			Secret: sponsorSecret,
+		}))
+
+		_, _, err := send(s, a, b)
+		assert.ErrorIs(t, err, ErrInvalidPayerSignature)
+	})
+
+	t.Run("Fail Unauthenticated Request", func(t *testing.T) {
+		t.Parallel()
+		state, s := setupIntegration()
+		setupAccounts(state, s)
+
+		a, _ := state.Accounts().ByName("Alice")
+		b, _ := state.Accounts().ByName("Bob")
+
+		server := newSponsorServer(t, b, nil)
+		defer server.Close()
+
+		s.Transactions.SetPayerProvider(b.Address(), NewHTTPPayerProvider(server.URL, HTTPPayerOptions{
+			Secret: []byte("wrong-secret"),
+		}))
+
+		_, _, err := send(s, a, b)
+		assert.ErrorContains(t, err, "status 401")
+	})
+}
+
+func TestHTTPPayerProvider(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Timeout With Retries", func(t *testing.T) {
+		t.Parallel()
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+		}))
+		defer server.Close()
+
+		provider := NewHTTPPayerProvider(server.URL, HTTPPayerOptions{
+			Timeout: 50 * time.Millisecond,
+			Retries: 2,
+		})
+
+		_, _, err := provider.SignEnvelope(context.Background(), []byte("envelope"), TxSummary{})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&requests))
+	})
+
+	t.Run("Retry Server Error", func(t *testing.T) {
+		t.Parallel()
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&requests, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(SponsorResponse{Signature: "abcd", KeyIndex: 2})
+		}))
+		defer server.Close()
+
+		provider := NewHTTPPayerProvider(server.URL, HTTPPayerOptions{Retries: 1})
+
+		sig, keyIndex, err := provider.SignEnvelope(context.Background(), []byte("envelope"), TxSummary{})
+		require.NoError(t, err)
+		assert.Equal(t, []byte{0xab, 0xcd}, sig)
+		assert.Equal(t, 2, keyIndex)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	})
+
+	t.Run("No Retry Client Error", func(t *testing.T) {
+		t.Parallel()
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		provider := NewHTTPPayerProvider(server.URL, HTTPPayerOptions{Retries: 3})
+
+		_, _, err := provider.SignEnvelope(context.Background(), []byte("envelope"), TxSummary{})
+		assert.ErrorContains(t, err, "status 403")
+		assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	})
+}
@@ -0,0 +1,352 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/onflow/cadence"
+	tmpl "github.com/onflow/flow-core-contracts/lib/go/templates"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+	"github.com/onflow/flow-go-sdk/templates"
+
+	"github.com/onflow/flow-cli/pkg/flowkit"
+	"github.com/onflow/flow-cli/pkg/flowkit/gateway"
+	"github.com/onflow/flow-cli/pkg/flowkit/output"
+	"github.com/onflow/flow-cli/pkg/flowkit/util"
+)
+
+// Node roles as defined by the FlowIDTableStaking contract.
+const (
+	NodeRoleCollection   uint8 = 1
+	NodeRoleConsensus    uint8 = 2
+	NodeRoleExecution    uint8 = 3
+	NodeRoleVerification uint8 = 4
+	NodeRoleAccess       uint8 = 5
+)
+
+// machineAccountMinimumBalance is the recommended minimum balance of machine accounts by the node role,
+// machine accounts pay for the QC voting and DKG participation transactions.
+var machineAccountMinimumBalance = map[uint8]string{
+	NodeRoleCollection: "0.005",
+	NodeRoleConsensus:  "0.25",
+}
+
+const machineAccountCreatedEvent = "FlowStakingCollection.MachineAccountCreated"
+
+const getMachineAccountsInfoScript = `
+import FlowStakingCollection from 0xSTAKINGCOLLECTIONADDRESS
+
+pub fun main(address: Address): [{String: AnyStruct}] {
+	let infos: [{String: AnyStruct}] = []
+	let machineAccounts = FlowStakingCollection.getMachineAccounts(address: address)
+
+	for nodeID in machineAccounts.keys {
+		let info = machineAccounts[nodeID]!
+		let machineAddress = info.getAddress()
+
+		infos.append({
+			"nodeID": nodeID,
+			"role": info.role,
+			"address": machineAddress,
+			"balance": getAccount(machineAddress).balance
+		})
+	}
+
+	return infos
+}`
+
+// Staking is a service that handles staking collection interactions.
+type Staking struct {
+	gateway   gateway.Gateway
+	state     *flowkit.State
+	logger    output.Logger
+	sequences *SequenceManager
+}
+
+// NewStaking returns a new staking service.
+func NewStaking(
+	gateway gateway.Gateway,
+	state *flowkit.State,
+	logger output.Logger,
+) *Staking {
+	return &Staking{
+		gateway:   gateway,
+		state:     state,
+		logger:    logger,
+		sequences: NewSequenceManager(gateway),
+	}
+}
+
+// NodeInfo contains the information needed to register a new node in the staking collection.
+type NodeInfo struct {
+	ID                string
+	Role              uint8
+	NetworkingAddress string
+	NetworkingKey     string
+	StakingKey        string
+	Amount            cadence.UFix64
+	// MachineAccountKey is required for collection and consensus nodes, which get a machine account created.
+	MachineAccountKey      crypto.PublicKey
+	MachineAccountHashAlgo crypto.HashAlgorithm
+}
+
+// StakingResult contains the ID and the events of a staking transaction.
+type StakingResult struct {
+	ID     flow.Identifier
+	Events flowkit.Events
+	// MachineAccount is the address of the machine account if one was created by the transaction.
+	MachineAccount *flow.Address
+}
+
+// MachineAccountInfo contains the information about a machine account of a staked node.
+type MachineAccountInfo struct {
+	NodeID  string
+	Role    uint8
+	Address flow.Address
+	Balance cadence.UFix64
+}
+
+// SetupCollection sets up a staking collection on the account, moving any existing staking objects into it.
+func (s *Staking) SetupCollection(account *flowkit.Account) (*StakingResult, error) {
+	env, err := stakingEnv(account.Address())
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := s.gateway.ExecuteScript(
+		tmpl.GenerateCollectionDoesAccountHaveStakingCollection(env),
+		[]cadence.Value{cadence.NewAddress(account.Address())},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for existing staking collection: %w", err)
+	}
+	if exists == cadence.NewBool(true) {
+		return nil, fmt.Errorf("account %s already has a staking collection", account.Address())
+	}
+
+	return s.sendTransaction(account, tmpl.GenerateCollectionSetup(env), nil)
+}
+
+// RegisterNodeWithMachineAccount registers a new node in the account staking collection.
+//
+// Collection and consensus nodes get a machine account created, which is returned in the result
+// with the machine account key provided in the node info added to it.
+func (s *Staking) RegisterNodeWithMachineAccount(account *flowkit.Account, node NodeInfo) (*StakingResult, error) {
+	env, err := stakingEnv(account.Address())
+	if err != nil {
+		return nil, err
+	}
+
+	_, needsMachineAccount := machineAccountMinimumBalance[node.Role]
+	if needsMachineAccount && node.MachineAccountKey == nil {
+		return nil, fmt.Errorf("machine account key is required for node role %d", node.Role)
+	}
+
+	var machineKeys cadence.Value = cadence.NewOptional(nil)
+	if needsMachineAccount {
+		key, err := templates.AccountKeyToCadenceCryptoKey(&flow.AccountKey{
+			PublicKey: node.MachineAccountKey,
+			SigAlgo:   node.MachineAccountKey.Algorithm(),
+			HashAlgo:  node.MachineAccountHashAlgo,
+			Weight:    flow.AccountKeyWeightThreshold,
+		})
+		if err != nil {
+			return nil, err
+		}
+		machineKeys = cadence.NewOptional(cadence.NewArray([]cadence.Value{key}))
+	}
+
+	// the staking collection is deployed to the locked tokens account
+	machineAccountEvent := fmt.Sprintf("A.%s.%s", env.LockedTokensAddress, machineAccountCreatedEvent)
+	result, err := s.sendTransaction(account, tmpl.GenerateCollectionRegisterNode(env), []cadence.Value{
+		cadence.String(node.ID),
+		cadence.NewUInt8(node.Role),
+		cadence.String(node.NetworkingAddress),
+		cadence.String(node.NetworkingKey),
+		cadence.String(node.StakingKey),
+		node.Amount,
+		machineKeys,
+	}, machineAccountEvent)
+	if err != nil {
+		return nil, err
+	}
+
+	result.MachineAccount = machineAccountFromEvents(result.Events)
+	if needsMachineAccount && result.MachineAccount == nil {
+		return nil, fmt.Errorf("machine account address couldn't be fetched from transaction %s", result.ID)
+	}
+
+	return result, nil
+}
+
+// MachineAccountInfo returns information about all the machine accounts in the staking collection of the address.
+//
+// A warning is logged for any machine account with a balance below the recommended minimum for the node role.
+func (s *Staking) MachineAccountInfo(address flow.Address) ([]MachineAccountInfo, error) {
+	env, err := stakingEnv(address)
+	if err != nil {
+		return nil, err
+	}
+
+	script := strings.ReplaceAll(
+		getMachineAccountsInfoScript,
+		"0xSTAKINGCOLLECTIONADDRESS",
+		fmt.Sprintf("0x%s", env.LockedTokensAddress),
+	)
+
+	value, err := s.gateway.ExecuteScript([]byte(script), []cadence.Value{cadence.NewAddress(address)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get machine accounts: %w", err)
+	}
+
+	infos, err := machineAccountsFromValue(value)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, info := range infos {
+		minimum, ok := machineAccountMinimumBalance[info.Role]
+		if !ok {
+			continue
+		}
+		minimumBalance, err := cadence.NewUFix64(minimum)
+		if err != nil {
+			return nil, err
+		}
+		if info.Balance >= minimumBalance {
+			continue
+		}
+
+		output.Warn(s.logger, fmt.Sprintf(
+			"machine account 0x%s for node %s has balance %s FLOW, which is below the recommended minimum of %s FLOW",
+			info.Address,
+			info.NodeID,
+			info.Balance,
+			minimum,
+		))
+	}
+
+	return infos, nil
+}
+
+// sendTransaction sends the staking transaction signed by the account and waits for it to be sealed.
+//
+// The result retains the events of the required types, even if the results skip events by default.
+func (s *Staking) sendTransaction(
+	account *flowkit.Account,
+	script []byte,
+	args []cadence.Value,
+	requiredEvents ...string,
+) (*StakingResult, error) {
+	tx, err := flowkit.NewAuthorizedTransaction(account, script, args)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := newSharedAccounts(s.gateway, s.state, s.logger, nil, s.sequences, false)
+	tx, err = accounts.prepareTransaction(tx, account, "")
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info(fmt.Sprintf("Transaction ID: %s", tx.FlowTransaction().ID()))
+	s.logger.StartProgress("Sending staking transaction...")
+	defer s.logger.StopProgress()
+
+	sentTx, err := s.sequences.send(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.sequences.result(tx, sentTx, requiredEvents...)
+	if err != nil {
+		return nil, err
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return &StakingResult{
+		ID:     sentTx.ID(),
+		Events: flowkit.EventsFromTransaction(result),
+	}, nil
+}
+
+// stakingEnv returns the core contracts environment for the network of the address, rejecting the emulator.
+func stakingEnv(address flow.Address) (tmpl.Environment, error) {
+	chain, err := util.GetAddressNetwork(address)
+	if err != nil {
+		return tmpl.Environment{}, fmt.Errorf(
+			"failed to determine network from address, check the address and network",
+		)
+	}
+
+	if chain == flow.Emulator {
+		return tmpl.Environment{}, fmt.Errorf("emulator chain not supported")
+	}
+
+	return util.EnvFromNetwork(chain), nil
+}
+
+// machineAccountFromEvents returns the address of the machine account created in the events if found.
+func machineAccountFromEvents(events flowkit.Events) *flow.Address {
+	for _, event := range events {
+		if strings.HasSuffix(event.Type, machineAccountCreatedEvent) {
+			return event.GetAddress()
+		}
+	}
+
+	return nil
+}
+
+func machineAccountsFromValue(value cadence.Value) ([]MachineAccountInfo, error) {
+	array, ok := value.(cadence.Array)
+	if !ok {
+		return nil, fmt.Errorf("invalid machine accounts value: %s", value)
+	}
+
+	infos := make([]MachineAccountInfo, 0, len(array.Values))
+	for _, v := range array.Values {
+		dict, ok := v.(cadence.Dictionary)
+		if !ok {
+			return nil, fmt.Errorf("invalid machine account value: %s", v)
+		}
+
+		var info MachineAccountInfo
+		for _, pair := range dict.Pairs {
+			switch pair.Key {
+			case cadence.String("nodeID"):
+				info.NodeID, _ = pair.Value.ToGoValue().(string)
+			case cadence.String("role"):
+				info.Role, _ = pair.Value.ToGoValue().(uint8)
+			case cadence.String("address"):
+				if address, ok := pair.Value.(cadence.Address); ok {
+					info.Address = flow.Address(address)
+				}
+			case cadence.String("balance"):
+				info.Balance, _ = pair.Value.(cadence.UFix64)
+			}
+		}
+		infos = append(infos, info)
+	}
+
+	return infos, nil
+}
@@ -0,0 +1,169 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-cli/pkg/flowkit"
+	"github.com/onflow/flow-cli/pkg/flowkit/tests"
+)
+
+func testnetStaker() *flowkit.Account {
+	return tests.Alice().SetAddress(flow.HexToAddress("8c5303eaa26202d6"))
+}
+
+func TestStaking(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Setup Collection", func(t *testing.T) {
+		t.Parallel()
+
+		_, s, gw := setup()
+		gw.ExecuteScript.Run(func(args mock.Arguments) {
+			gw.ExecuteScript.Return(cadence.NewBool(false), nil)
+		})
+		gw.SendSignedTransaction.Run(func(args mock.Arguments) {
+			tx := args.Get(0).(*flowkit.Transaction)
+			assert.True(t, strings.Contains(string(tx.FlowTransaction().Script), "createStakingCollection"))
+			gw.SendSignedTransaction.Return(tests.NewTransaction(), nil)
+		})
+
+		result, err := s.Staking.SetupCollection(testnetStaker())
+		require.NoError(t, err)
+		assert.NotEqual(t, flow.EmptyID, result.ID)
+		gw.Mock.AssertNumberOfCalls(t, tests.SendSignedTransactionFunc, 1)
+	})
+
+	t.Run("Setup Existing Collection", func(t *testing.T) {
+		t.Parallel()
+
+		_, s, gw := setup()
+		gw.ExecuteScript.Run(func(args mock.Arguments) {
+			gw.ExecuteScript.Return(cadence.NewBool(true), nil)
+		})
+
+		_, err := s.Staking.SetupCollection(testnetStaker())
+		assert.EqualError(t, err, "account 8c5303eaa26202d6 already has a staking collection")
+		gw.Mock.AssertNotCalled(t, tests.SendSignedTransactionFunc, mock.Anything)
+	})
+
+	t.Run("Setup Collection Emulator", func(t *testing.T) {
+		t.Parallel()
+
+		_, s, _ := setup()
+		account := tests.Alice().SetAddress(flow.ServiceAddress(flow.Emulator))
+
+		_, err := s.Staking.SetupCollection(account)
+		assert.EqualError(t, err, "emulator chain not supported")
+	})
+
+	t.Run("Register Node With Machine Account", func(t *testing.T) {
+		t.Parallel()
+
+		_, s, gw := setup()
+		machineAddress := flow.HexToAddress("0x0b2a3299cc857e29")
+
+		gw.GetTransactionResult.Return(tests.NewTransactionResult([]flow.Event{{
+			Type: "A.95e019a17d0e23d7.FlowStakingCollection.MachineAccountCreated",
+			Value: cadence.Event{
+				EventType: cadence.NewEventType(
+					common.NewStringLocation(nil, "A.95e019a17d0e23d7.FlowStakingCollection"),
+					"MachineAccountCreated",
+					[]cadence.Field{
+						{Identifier: "nodeID", Type: cadence.StringType{}},
+						{Identifier: "role", Type: cadence.UInt8Type{}},
+						{Identifier: "address", Type: cadence.AddressType{}},
+					},
+					nil,
+				),
+				Fields: []cadence.Value{
+					cadence.String("node"),
+					cadence.NewUInt8(NodeRoleCollection),
+					cadence.NewAddress(machineAddress),
+				},
+			},
+		}}), nil)
+
+		amount, _ := cadence.NewUFix64("250000.0")
+		result, err := s.Staking.RegisterNodeWithMachineAccount(testnetStaker(), NodeInfo{
+			ID:                     "node",
+			Role:                   NodeRoleCollection,
+			NetworkingAddress:      "collection.flow.com:3569",
+			NetworkingKey:          "networking",
+			StakingKey:             "staking",
+			Amount:                 amount,
+			MachineAccountKey:      tests.PubKeys()[0],
+			MachineAccountHashAlgo: crypto.SHA3_256,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, result.MachineAccount)
+		assert.Equal(t, machineAddress, *result.MachineAccount)
+		assert.Len(t, result.Events, 1)
+	})
+
+	t.Run("Register Node Missing Machine Account Key", func(t *testing.T) {
+		t.Parallel()
+
+		_, s, _ := setup()
+
+		_, err := s.Staking.RegisterNodeWithMachineAccount(testnetStaker(), NodeInfo{
+			ID:   "node",
+			Role: NodeRoleConsensus,
+		})
+		assert.EqualError(t, err, "machine account key is required for node role 2")
+	})
+
+	t.Run("Machine Account Info", func(t *testing.T) {
+		t.Parallel()
+
+		_, s, gw := setup()
+		machineAddress := flow.HexToAddress("0x0b2a3299cc857e29")
+		balance, _ := cadence.NewUFix64("0.001")
+
+		gw.ExecuteScript.Run(func(args mock.Arguments) {
+			gw.ExecuteScript.Return(cadence.NewArray([]cadence.Value{
+				cadence.NewDictionary([]cadence.KeyValuePair{
+					{Key: cadence.String("nodeID"), Value: cadence.String("node")},
+					{Key: cadence.String("role"), Value: cadence.NewUInt8(NodeRoleCollection)},
+					{Key: cadence.String("address"), Value: cadence.NewAddress(machineAddress)},
+					{Key: cadence.String("balance"), Value: balance},
+				}),
+			}), nil)
+		})
+
+		infos, err := s.Staking.MachineAccountInfo(flow.HexToAddress("8c5303eaa26202d6"))
+		require.NoError(t, err)
+		require.Len(t, infos, 1)
+		assert.Equal(t, MachineAccountInfo{
+			NodeID:  "node",
+			Role:    NodeRoleCollection,
+			Address: machineAddress,
+			Balance: balance,
+		}, infos[0])
+	})
+}
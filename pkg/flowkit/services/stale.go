@@ -0,0 +1,175 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/onflow/flow-go-sdk"
+
+	"github.com/onflow/flow-cli/pkg/flowkit"
+	"github.com/onflow/flow-cli/pkg/flowkit/config"
+	"github.com/onflow/flow-cli/pkg/flowkit/project"
+)
+
+// DriftedImport is an import embedded in deployed code that no longer points to where the imported contract resolves.
+type DriftedImport struct {
+	Contract string
+	// Embedded is the address the deployed code imports the contract from.
+	Embedded flow.Address
+	// Current is the address the contract resolves to in the project configuration.
+	Current flow.Address
+}
+
+func (d DriftedImport) String() string {
+	return fmt.Sprintf("%s (deployed 0x%s, now 0x%s)", d.Contract, d.Embedded, d.Current)
+}
+
+// StaleContract is a deployed contract importing contracts from addresses they are no longer configured at.
+type StaleContract struct {
+	Name        string
+	AccountName string
+	Address     flow.Address
+	// Imports are the drifted imports, sorted by contract name.
+	Imports []DriftedImport
+}
+
+func (s StaleContract) String() string {
+	imports := make([]string, len(s.Imports))
+	for i, imp := range s.Imports {
+		imports[i] = imp.String()
+	}
+	return fmt.Sprintf("%s on 0x%s imports %s", s.Name, s.Address, strings.Join(imports, ", "))
+}
+
+// StaleDependents finds the deployed contracts that still import contracts from their previous addresses,
+// e.g. after a dependency was moved to another account.
+//
+// The code of each deployment contract is fetched from its account and the address imports embedded in it
+// are compared with the addresses the imported contracts resolve to on the network, an alias takes precedence
+// over a deployment the same as when imports are replaced. Contracts that are not deployed and imports of
+// contracts unknown to the project are ignored. Stale contracts are returned in the order of the deployments.
+func (p *Project) StaleDependents(network string) (stale []StaleContract, err error) {
+	err = p.diagnostics.run(p.tracing, "Project.StaleDependents", []Attribute{
+		{Key: "network", Value: network},
+	}, func(op *operation) error {
+		stale, err = p.traced(op).staleDependents(network)
+		return err
+	})
+	return stale, err
+}
+
+func (p *Project) staleDependents(network string) ([]StaleContract, error) {
+	if p.state == nil {
+		return nil, config.ErrDoesNotExist
+	}
+
+	contracts, err := p.state.DeploymentContractsByNetwork(network)
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[string]flow.Address)
+	for _, contract := range contracts {
+		current[contract.Name] = contract.AccountAddress
+	}
+	for _, contract := range p.state.Contracts().ByNetwork(network) {
+		if contract.IsAlias() {
+			current[contract.Name] = flow.HexToAddress(contract.Alias)
+		}
+	}
+
+	stale := make([]StaleContract, 0)
+	accounts := make(map[flow.Address]*flow.Account)
+	for _, contract := range contracts {
+		account, ok := accounts[contract.AccountAddress]
+		if !ok {
+			account, err = p.gateway.GetAccount(contract.AccountAddress)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get account %s: %w", contract.AccountName, err)
+			}
+			accounts[contract.AccountAddress] = account
+		}
+
+		code, deployed := account.Contracts[contract.Name]
+		if !deployed {
+			continue
+		}
+
+		program, err := project.NewProgram(flowkit.NewScript(code, nil, ""))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse deployed contract %s: %w", contract.Name, err)
+		}
+
+		drifted := make([]DriftedImport, 0)
+		for name, embedded := range program.AddressImports() {
+			address, known := current[name]
+			if known && address != embedded {
+				drifted = append(drifted, DriftedImport{Contract: name, Embedded: embedded, Current: address})
+			}
+		}
+		if len(drifted) == 0 {
+			continue
+		}
+
+		sort.Slice(drifted, func(i, j int) bool {
+			return drifted[i].Contract < drifted[j].Contract
+		})
+		stale = append(stale, StaleContract{
+			Name:        contract.Name,
+			AccountName: contract.AccountName,
+			Address:     contract.AccountAddress,
+			Imports:     drifted,
+		})
+	}
+
+	return stale, nil
+}
+
+// cascadedContracts returns the names of the stale dependents on the network, which are updated even if
+// updating is not requested, since only their imports changed.
+func (p *Project) cascadedContracts(network string) (map[string]bool, error) {
+	stale, err := p.staleDependents(network)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]bool, len(stale))
+	for _, contract := range stale {
+		names[contract.Name] = true
+		p.logger.Info(fmt.Sprintf("%s has stale imports, it will be updated", contract))
+	}
+	return names, nil
+}
+
+// splitCascaded separates the cascaded contracts from the rest of the batch.
+func splitCascaded(batch []*project.Contract, cascaded map[string]bool) ([]*project.Contract, []*project.Contract) {
+	rest := make([]*project.Contract, 0, len(batch))
+	stale := make([]*project.Contract, 0)
+	for _, contract := range batch {
+		if cascaded[contract.Name] {
+			stale = append(stale, contract)
+		} else {
+			rest = append(rest, contract)
+		}
+	}
+	return rest, stale
+}
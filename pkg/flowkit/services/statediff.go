@@ -0,0 +1,243 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package services
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+
+	"github.com/onflow/flow-cli/pkg/flowkit"
+	"github.com/onflow/flow-cli/pkg/flowkit/gateway"
+)
+
+// AccountState is a snapshot of the parts of an account state compared by SendWithStateDiff.
+type AccountState struct {
+	Address flow.Address
+	Balance uint64
+	// Contracts are the hex encoded SHA256 hashes of the contract codes by contract name.
+	Contracts map[string]string
+	// Paths are the type identifiers of the values stored and the capabilities linked on the account by path.
+	Paths map[string]string
+	// Truncated is true if the account has more paths than scanned.
+	Truncated bool
+}
+
+// AccountDiff describes the changes a transaction made to an account.
+type AccountDiff struct {
+	Address flow.Address
+	// BalanceDelta is the change of the FLOW balance in the smallest unit, negative if the balance decreased.
+	BalanceDelta     int64
+	ContractsAdded   []string
+	ContractsUpdated []string
+	ContractsRemoved []string
+	PathsAdded       []string
+	PathsRemoved     []string
+	// Truncated is true if the account has more paths than scanned before or after, so path changes may be missing.
+	Truncated bool
+}
+
+// Changed returns true if the account balance, contracts or paths changed.
+func (d *AccountDiff) Changed() bool {
+	return d.BalanceDelta != 0 ||
+		len(d.ContractsAdded) > 0 ||
+		len(d.ContractsUpdated) > 0 ||
+		len(d.ContractsRemoved) > 0 ||
+		len(d.PathsAdded) > 0 ||
+		len(d.PathsRemoved) > 0
+}
+
+// StateDiff describes the changes a transaction made to the watched accounts, in the order the accounts were watched.
+type StateDiff struct {
+	Accounts []AccountDiff
+}
+
+// ByAddress returns the diff of the watched account with the address, nil if the account wasn't watched.
+func (s *StateDiff) ByAddress(address flow.Address) *AccountDiff {
+	for i := range s.Accounts {
+		if s.Accounts[i].Address == address {
+			return &s.Accounts[i]
+		}
+	}
+	return nil
+}
+
+// Changed returns true if any of the watched accounts changed.
+func (s *StateDiff) Changed() bool {
+	for i := range s.Accounts {
+		if s.Accounts[i].Changed() {
+			return true
+		}
+	}
+	return false
+}
+
+// SendWithStateDiff sends the transaction same as Send and reports the changes it made to the watched accounts.
+//
+// Each watched account is snapshotted before the transaction is sent and again after it is sealed, the balance,
+// the contracts and the stored and linked paths of the snapshots are compared. The snapshots are only consistent
+// if no other transactions are executed in between, so state diffing is only supported by isolated gateways like
+// the emulator gateway, other gateways return an UnsupportedGatewayError.
+//
+// The state diff is returned together with the result of failed transactions, which can still change balances
+// by paying fees.
+func (t *Transactions) SendWithStateDiff(
+	accounts *transactionAccountRoles,
+	script *flowkit.Script,
+	gasLimit uint64,
+	network string,
+	watchAddresses []flow.Address,
+) (result *flow.TransactionResult, diff *StateDiff, err error) {
+	err = t.diagnostics.run(nil, "Transactions.SendWithStateDiff", []Attribute{
+		scriptInput("script", script),
+		{Key: "network", Value: network},
+		addressesInput("watch", watchAddresses),
+	}, func(*operation) error {
+		result, diff, err = t.sendWithStateDiff(accounts, script, gasLimit, network, watchAddresses)
+		return err
+	})
+	return result, diff, err
+}
+
+func (t *Transactions) sendWithStateDiff(
+	accounts *transactionAccountRoles,
+	script *flowkit.Script,
+	gasLimit uint64,
+	network string,
+	watchAddresses []flow.Address,
+) (*flow.TransactionResult, *StateDiff, error) {
+	isolated, ok := t.gateway.(gateway.IsolatedGateway)
+	if !ok || !isolated.Isolated() {
+		return nil, nil, &UnsupportedGatewayError{Operation: "transaction state diffing"}
+	}
+
+	before := make([]*AccountState, len(watchAddresses))
+	for i, address := range watchAddresses {
+		state, err := t.accountState(address)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to snapshot account %s before the transaction: %w", address, err)
+		}
+		before[i] = state
+	}
+
+	_, result, err := t.send(accounts, script, gasLimit, network)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	diff := &StateDiff{Accounts: make([]AccountDiff, len(watchAddresses))}
+	for i, address := range watchAddresses {
+		after, err := t.accountState(address)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to snapshot account %s after the transaction: %w", address, err)
+		}
+		diff.Accounts[i] = diffAccountState(before[i], after)
+	}
+
+	return result, diff, nil
+}
+
+// accountState snapshots the balance, the contracts and the paths of the account.
+func (t *Transactions) accountState(address flow.Address) (*AccountState, error) {
+	account, err := t.gateway.GetAccount(address)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &AccountState{
+		Address:   address,
+		Balance:   account.Balance,
+		Contracts: make(map[string]string, len(account.Contracts)),
+		Paths:     make(map[string]string),
+	}
+	for name, code := range account.Contracts {
+		state.Contracts[name] = codeHash(code)
+	}
+
+	value, err := t.gateway.ExecuteScript(
+		[]byte(storageTypesScript),
+		[]cadence.Value{cadence.NewAddress(address), cadence.NewInt(maxStorageScanEntries)},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	types, ok := value.(cadence.Dictionary)
+	if !ok {
+		return nil, fmt.Errorf("unexpected storage scan result %s", value)
+	}
+
+	state.Truncated = len(types.Pairs) > maxStorageScanEntries
+	for _, pair := range types.Pairs {
+		path, _ := pair.Key.ToGoValue().(string)
+		typeID, _ := pair.Value.ToGoValue().(string)
+		state.Paths[path] = typeID
+	}
+
+	return state, nil
+}
+
+// diffAccountState compares the snapshots of an account taken before and after a transaction.
+func diffAccountState(before *AccountState, after *AccountState) AccountDiff {
+	diff := AccountDiff{
+		Address:          after.Address,
+		BalanceDelta:     int64(after.Balance) - int64(before.Balance),
+		ContractsAdded:   make([]string, 0),
+		ContractsUpdated: make([]string, 0),
+		ContractsRemoved: make([]string, 0),
+		PathsAdded:       make([]string, 0),
+		PathsRemoved:     make([]string, 0),
+		Truncated:        before.Truncated || after.Truncated,
+	}
+
+	for name, hash := range after.Contracts {
+		previous, exists := before.Contracts[name]
+		if !exists {
+			diff.ContractsAdded = append(diff.ContractsAdded, name)
+		} else if previous != hash {
+			diff.ContractsUpdated = append(diff.ContractsUpdated, name)
+		}
+	}
+	for name := range before.Contracts {
+		if _, exists := after.Contracts[name]; !exists {
+			diff.ContractsRemoved = append(diff.ContractsRemoved, name)
+		}
+	}
+
+	for path := range after.Paths {
+		if _, exists := before.Paths[path]; !exists {
+			diff.PathsAdded = append(diff.PathsAdded, path)
+		}
+	}
+	for path := range before.Paths {
+		if _, exists := after.Paths[path]; !exists {
+			diff.PathsRemoved = append(diff.PathsRemoved, path)
+		}
+	}
+
+	sort.Strings(diff.ContractsAdded)
+	sort.Strings(diff.ContractsUpdated)
+	sort.Strings(diff.ContractsRemoved)
+	sort.Strings(diff.PathsAdded)
+	sort.Strings(diff.PathsRemoved)
+
+	return diff
+}
@@ -0,0 +1,298 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package services
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	cdcTests "github.com/onflow/cadence-tools/test"
+	"github.com/onflow/cadence/runtime/common"
+
+	"github.com/onflow/flow-cli/pkg/flowkit/config"
+	"github.com/onflow/flow-cli/pkg/flowkit/util"
+)
+
+// TestFileSuffix is the suffix of the Cadence test files discovered by Project.Test.
+const TestFileSuffix = "_test.cdc"
+
+// TestResult is the result of a test function of a Cadence test file.
+type TestResult struct {
+	File string
+	Name string
+	// Error is the reason the test failed, nil if it passed.
+	Error error
+}
+
+// Passed returns true if the test passed.
+func (t TestResult) Passed() bool {
+	return t.Error == nil
+}
+
+// TestFileResult contains the results of the test functions of a test file.
+type TestFileResult struct {
+	File  string
+	Tests []TestResult
+	// Error is set if running the test file failed, e.g. because an import couldn't be resolved
+	// or the file doesn't type check, in which case Tests contains the tests that ran before the failure if any.
+	Error error
+}
+
+// TestResults contains the results of all the test files run, sorted by file.
+type TestResults struct {
+	Files []TestFileResult
+}
+
+// TestSummary counts the results of a test run.
+type TestSummary struct {
+	Files  int
+	Passed int
+	Failed int
+	// Errored is the number of test files that failed to run.
+	Errored int
+}
+
+// Succeeded returns true if all the tests passed and all the test files ran.
+func (s TestSummary) Succeeded() bool {
+	return s.Failed == 0 && s.Errored == 0
+}
+
+func (s TestSummary) String() string {
+	return fmt.Sprintf(
+		"%d passed, %d failed, %d of %d test files failed to run",
+		s.Passed,
+		s.Failed,
+		s.Errored,
+		s.Files,
+	)
+}
+
+// Summary counts the passed and failed tests of all the test files.
+func (t *TestResults) Summary() TestSummary {
+	summary := TestSummary{Files: len(t.Files)}
+	for _, file := range t.Files {
+		if file.Error != nil {
+			summary.Errored++
+		}
+		for _, test := range file.Tests {
+			if test.Passed() {
+				summary.Passed++
+			} else {
+				summary.Failed++
+			}
+		}
+	}
+
+	return summary
+}
+
+// Err returns an error describing the summary if a test failed or a test file failed to run,
+// so the run can be reported with a non-zero exit code.
+func (t *TestResults) Err() error {
+	summary := t.Summary()
+	if summary.Succeeded() {
+		return nil
+	}
+
+	return fmt.Errorf("tests failed: %s", summary)
+}
+
+// TestImportError is the error of a test file with an import that doesn't match any configured contract.
+type TestImportError struct {
+	File   string
+	Import string
+	Err    error
+}
+
+func (t *TestImportError) Error() string {
+	return fmt.Sprintf("failed to resolve import %s of test file %s: %s", t.Import, t.File, t.Err)
+}
+
+func (t *TestImportError) Unwrap() error {
+	return t.Err
+}
+
+// fileWalker is implemented by reader writers that can walk a directory tree, like afero.Afero.
+type fileWalker interface {
+	Walk(root string, walkFn filepath.WalkFunc) error
+}
+
+// Test runs the Cadence test files of the project matching the patterns.
+//
+// Test files are the files ending in _test.cdc, all of them are run if no pattern is provided. A pattern
+// matches the files in a directory, e.g. "tests", or the files matching a glob, e.g. "tests/*_test.cdc".
+// Test files import contracts by their path relative to the test file or by their name, e.g. import "Hello",
+// using the contracts configured for the emulator. A test file failing to run doesn't stop running the other files.
+//
+// The Cadence test framework in use doesn't report logs or coverage, the results only tell which tests
+// passed and why the others failed.
+func (p *Project) Test(patterns []string) (results *TestResults, err error) {
+	err = p.diagnostics.run(p.tracing, "Project.Test", []Attribute{
+		{Key: "patterns", Value: strings.Join(patterns, ",")},
+	}, func(op *operation) error {
+		results, err = p.traced(op).test(patterns)
+		return err
+	})
+	return results, err
+}
+
+func (p *Project) test(patterns []string) (*TestResults, error) {
+	if p.state == nil {
+		return nil, config.ErrDoesNotExist
+	}
+
+	files, err := p.testFiles(patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	results := &TestResults{Files: make([]TestFileResult, 0, len(files))}
+	for _, file := range files {
+		p.logger.Info(fmt.Sprintf("Running tests in %s...", file))
+		results.Files = append(results.Files, p.runTestFile(file))
+	}
+
+	return results, nil
+}
+
+// testFiles walks the project directory for the test files matching the patterns.
+func (p *Project) testFiles(patterns []string) ([]string, error) {
+	walker, ok := p.state.ReaderWriter().(fileWalker)
+	if !ok {
+		return nil, fmt.Errorf("discovering test files is not supported by the project reader writer")
+	}
+
+	files := make([]string, 0)
+	err := walker.Walk(".", func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		name = path.Clean(filepath.ToSlash(name))
+		if info.IsDir() {
+			if name != "." && strings.HasPrefix(path.Base(name), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if strings.HasSuffix(name, TestFileSuffix) && matchesTestPatterns(name, patterns) {
+			files = append(files, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover test files: %w", err)
+	}
+
+	return files, nil
+}
+
+func matchesTestPatterns(name string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+
+	for _, pattern := range patterns {
+		pattern = path.Clean(filepath.ToSlash(pattern))
+		if pattern == "." || name == pattern || strings.HasPrefix(name, pattern+"/") {
+			return true
+		}
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// runTestFile runs the test functions of the file, failures to run the file are reported in the result.
+func (p *Project) runTestFile(file string) TestFileResult {
+	result := TestFileResult{File: file, Tests: make([]TestResult, 0)}
+
+	code, err := p.state.ReadSource(file)
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	contracts := p.state.Contracts().ByNetwork(config.DefaultEmulatorNetwork().Name)
+	index := contracts.Index()
+
+	var importErr error
+	importResolver := func(location common.Location) (string, error) {
+		code, err := p.resolveTestImport(file, location, index)
+		if err != nil {
+			importErr = &TestImportError{File: file, Import: location.String(), Err: err}
+			return "", importErr
+		}
+		return code, nil
+	}
+
+	tests := NewTests(p.state, p.logger)
+	runner := cdcTests.NewTestRunner().
+		WithImportResolver(importResolver).
+		WithFileResolver(tests.fileResolver(file, p.state.ReaderWriter()))
+
+	testResults, err := runner.RunTests(string(code))
+	if importErr != nil {
+		// the runner reports the import failure as a checking error of the whole file
+		result.Error = importErr
+		return result
+	}
+
+	for _, test := range testResults {
+		result.Tests = append(result.Tests, TestResult{File: file, Name: test.TestName, Error: test.Error})
+	}
+	if err != nil {
+		result.Error = fmt.Errorf("failed to run test file %s: %w", file, err)
+	}
+
+	return result
+}
+
+// resolveTestImport returns the code of the contract imported by the test file,
+// imported by its location relative to the test file or by its name.
+func (p *Project) resolveTestImport(
+	file string,
+	location common.Location,
+	contracts *config.ContractIndex,
+) (string, error) {
+	stringLocation, ok := location.(common.StringLocation)
+	if !ok {
+		return "", fmt.Errorf("only contracts imported by path or by name can be resolved")
+	}
+
+	contract, err := contracts.ByLocation(util.AbsolutePath(file, stringLocation.String()))
+	if err != nil {
+		contract, err = contracts.ByName(stringLocation.String())
+	}
+	if err != nil {
+		return "", fmt.Errorf("import doesn't match the location or the name of any contract in the configuration")
+	}
+
+	code, err := p.state.ReadSource(contract.Location)
+	if err != nil {
+		return "", fmt.Errorf("failed to read contract %s: %w", contract.Name, err)
+	}
+
+	return string(code), nil
+}
@@ -64,13 +64,18 @@ func (t *Tests) Execute(
 }
 
 func (t *Tests) importResolver(scriptPath string, readerWriter flowkit.ReaderWriter) cdcTests.ImportResolver {
+	contracts := config.NewContractIndex(nil)
+	if t.state != nil {
+		contracts = t.state.Contracts().Index()
+	}
+
 	return func(location common.Location) (string, error) {
 		stringLocation, isFileImport := location.(common.StringLocation)
 		if !isFileImport {
 			return "", fmt.Errorf("cannot import from %s", location)
 		}
 
-		importedContract, err := t.resolveContract(stringLocation)
+		importedContract, err := resolveContract(contracts, stringLocation)
 		if err != nil {
 			return "", err
 		}
@@ -86,16 +91,14 @@ func (t *Tests) importResolver(scriptPath string, readerWriter flowkit.ReaderWri
 	}
 }
 
-func (t *Tests) resolveContract(stringLocation common.StringLocation) (config.Contract, error) {
+func resolveContract(contracts *config.ContractIndex, stringLocation common.StringLocation) (*config.Contract, error) {
 	relativePath := stringLocation.String()
-	for _, contract := range *t.state.Contracts() {
-		if contract.Location == relativePath {
-			return contract, nil
-		}
+	contract, err := contracts.ByLocation(relativePath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot find contract with location '%s' in configuration", relativePath)
 	}
 
-	return config.Contract{},
-		fmt.Errorf("cannot find contract with location '%s' in configuration", relativePath)
+	return contract, nil
 }
 
 func (t *Tests) fileResolver(scriptPath string, readerWriter flowkit.ReaderWriter) cdcTests.FileResolver {
@@ -105,3 +105,97 @@ func TestExecutingTests(t *testing.T) {
 		assert.NoError(t, results[0].Error)
 	})
 }
+
+func TestProjectTest_Integration(t *testing.T) {
+	t.Parallel()
+
+	setupTestFiles := func(files ...tests.Resource) *Services {
+		st, s := setupIntegration()
+		st.Contracts().AddOrUpdate(tests.ContractHelloString.Name, config.Contract{
+			Name:     tests.ContractHelloString.Name,
+			Location: tests.ContractHelloString.Filename,
+			Network:  "emulator",
+		})
+		for _, file := range files {
+			require.NoError(t, st.ReaderWriter().WriteFile(file.Filename, file.Source, 0644))
+		}
+		return s
+	}
+
+	t.Run("Passing and Failing", func(t *testing.T) {
+		t.Parallel()
+		s := setupTestFiles(tests.TestFileHello, tests.TestFileHelloFailing)
+
+		results, err := s.Project.Test(nil)
+		require.NoError(t, err)
+		require.Len(t, results.Files, 2)
+
+		failing := results.Files[0]
+		assert.Equal(t, "tests/hello_failing_test.cdc", failing.File)
+		assert.NoError(t, failing.Error)
+		require.Len(t, failing.Tests, 2)
+		assert.True(t, failing.Tests[0].Passed())
+		assert.Equal(t, "testWrongGreeting", failing.Tests[1].Name)
+		assert.ErrorContains(t, failing.Tests[1].Error, "wrong greeting")
+
+		passing := results.Files[1]
+		assert.Equal(t, "tests/hello_test.cdc", passing.File)
+		assert.NoError(t, passing.Error)
+		require.Len(t, passing.Tests, 2)
+		assert.True(t, passing.Tests[0].Passed())
+		assert.True(t, passing.Tests[1].Passed())
+
+		assert.Equal(t, TestSummary{Files: 2, Passed: 3, Failed: 1}, results.Summary())
+		assert.EqualError(t, results.Err(), "tests failed: 3 passed, 1 failed, 0 of 2 test files failed to run")
+	})
+
+	t.Run("Patterns", func(t *testing.T) {
+		t.Parallel()
+		s := setupTestFiles(tests.TestFileHello, tests.TestFileHelloFailing)
+
+		results, err := s.Project.Test([]string{"tests/hello_test.cdc"})
+		require.NoError(t, err)
+		require.Len(t, results.Files, 1)
+		assert.NoError(t, results.Err())
+
+		results, err = s.Project.Test([]string{"tests/*_failing_test.cdc"})
+		require.NoError(t, err)
+		require.Len(t, results.Files, 1)
+		assert.Equal(t, "tests/hello_failing_test.cdc", results.Files[0].File)
+
+		results, err = s.Project.Test([]string{"tests"})
+		require.NoError(t, err)
+		assert.Len(t, results.Files, 2)
+
+		results, err = s.Project.Test([]string{"contracts"})
+		require.NoError(t, err)
+		assert.Len(t, results.Files, 0)
+		assert.True(t, results.Summary().Succeeded())
+	})
+
+	t.Run("Unresolved Import", func(t *testing.T) {
+		t.Parallel()
+		s := setupTestFiles(tests.TestFileHello, tests.TestFileMissingImport)
+
+		results, err := s.Project.Test(nil)
+		require.NoError(t, err)
+		require.Len(t, results.Files, 2)
+
+		passing := results.Files[0]
+		assert.NoError(t, passing.Error)
+		assert.Len(t, passing.Tests, 2)
+
+		missing := results.Files[1]
+		assert.Empty(t, missing.Tests)
+		var importErr *TestImportError
+		require.ErrorAs(t, missing.Error, &importErr)
+		assert.EqualError(
+			t,
+			missing.Error,
+			"failed to resolve import ../contractMissing.cdc of test file tests/missing_test.cdc: "+
+				"import doesn't match the location or the name of any contract in the configuration",
+		)
+		assert.Equal(t, TestSummary{Files: 2, Passed: 2, Errored: 1}, results.Summary())
+		assert.False(t, results.Summary().Succeeded())
+	})
+}
@@ -0,0 +1,350 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package services
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/flow-go-sdk"
+
+	"github.com/onflow/flow-cli/pkg/flowkit"
+	"github.com/onflow/flow-cli/pkg/flowkit/gateway"
+)
+
+// Attribute is a key value pair attached to a tracing span.
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// Span is a single traced operation.
+//
+// The interface is a subset of the OpenTelemetry span, so an OpenTelemetry tracer
+// can be used with a thin adapter without flowkit depending on it.
+type Span interface {
+	SetAttributes(attributes ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// Tracer starts new spans as children of the span found in the provided context.
+type Tracer interface {
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// tracing starts the spans of service operations and gateway calls as children of the span in its context.
+//
+// The context of a tracing never changes, each operation has its own tracing carrying the context of its span,
+// so overlapping operations don't share a parent. A nil tracing is valid and doesn't trace anything.
+type tracing struct {
+	tracer Tracer
+	ctx    context.Context
+}
+
+func newTracing(tracer Tracer) *tracing {
+	return &tracing{
+		tracer: tracer,
+		ctx:    context.Background(),
+	}
+}
+
+// operation is a span of a service operation that needs to be ended.
+type operation struct {
+	span Span
+	// tracing starts the spans of the calls made by the operation as children of its span.
+	tracing *tracing
+}
+
+// start a new service operation span, the calls made by the operation are traced with the tracing of the operation.
+func (t *tracing) start(name string, attributes ...Attribute) *operation {
+	if t == nil {
+		return nil
+	}
+
+	ctx, span := t.tracer.Start(t.ctx, name)
+	span.SetAttributes(attributes...)
+
+	return &operation{
+		span:    span,
+		tracing: &tracing{tracer: t.tracer, ctx: ctx},
+	}
+}
+
+// child starts a span as a child of the span in the context of the tracing.
+func (t *tracing) child(name string, attributes ...Attribute) Span {
+	_, span := t.tracer.Start(t.ctx, name)
+	span.SetAttributes(attributes...)
+
+	return span
+}
+
+// end the operation span and record the error if not nil.
+func (o *operation) end(err error) {
+	if o == nil {
+		return
+	}
+
+	endSpan(o.span, err)
+}
+
+// gateway returns the gateway tracing its calls as children of the operation, if it's a tracing gateway.
+func (o *operation) gateway(gw gateway.Gateway) gateway.Gateway {
+	if o == nil {
+		return gw
+	}
+	if traced, ok := gw.(*tracingGateway); ok {
+		return newTracingGateway(traced.gateway, o.tracing)
+	}
+	return gw
+}
+
+// traced returns a copy of the accounts service whose calls are traced as children of the operation.
+func (a *Accounts) traced(op *operation) *Accounts {
+	if op == nil {
+		return a
+	}
+
+	traced := *a
+	traced.tracing = op.tracing
+	traced.gateway = op.gateway(a.gateway)
+	traced.sequences = a.sequences.withGateway(traced.gateway)
+	return &traced
+}
+
+// traced returns a copy of the scripts service whose calls are traced as children of the operation.
+func (s *Scripts) traced(op *operation) *Scripts {
+	if op == nil {
+		return s
+	}
+
+	traced := *s
+	traced.tracing = op.tracing
+	traced.gateway = op.gateway(s.gateway)
+	return &traced
+}
+
+// traced returns a copy of the project service whose calls are traced as children of the operation.
+func (p *Project) traced(op *operation) *Project {
+	if op == nil {
+		return p
+	}
+
+	traced := *p
+	traced.tracing = op.tracing
+	traced.gateway = op.gateway(p.gateway)
+	traced.sequences = p.sequences.withGateway(traced.gateway)
+	return &traced
+}
+
+func endSpan(span Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+var _ gateway.Gateway = &tracingGateway{}
+var _ gateway.ScriptProfiler = &tracingGateway{}
+var _ gateway.TransactionResultReader = &tracingGateway{}
+var _ gateway.IsolatedGateway = &tracingGateway{}
+var _ gateway.WaitPolicyProvider = &tracingGateway{}
+
+// tracingGateway wraps a gateway and creates a span for every call.
+type tracingGateway struct {
+	gateway gateway.Gateway
+	tracing *tracing
+}
+
+func newTracingGateway(gw gateway.Gateway, tracing *tracing) *tracingGateway {
+	return &tracingGateway{
+		gateway: gw,
+		tracing: tracing,
+	}
+}
+
+func (g *tracingGateway) span(method string, attributes ...Attribute) Span {
+	return g.tracing.child(
+		"Gateway."+method,
+		append([]Attribute{{Key: "method", Value: method}}, attributes...)...,
+	)
+}
+
+func (g *tracingGateway) GetAccount(address flow.Address) (*flow.Account, error) {
+	span := g.span("GetAccount", Attribute{Key: "address", Value: address.String()})
+	account, err := g.gateway.GetAccount(address)
+	endSpan(span, err)
+	return account, err
+}
+
+func (g *tracingGateway) GetAccountAtBlockHeight(address flow.Address, height uint64) (*flow.Account, error) {
+	span := g.span(
+		"GetAccountAtBlockHeight",
+		Attribute{Key: "address", Value: address.String()},
+		Attribute{Key: "height", Value: strconv.FormatUint(height, 10)},
+	)
+	account, err := g.gateway.GetAccountAtBlockHeight(address, height)
+	endSpan(span, err)
+	return account, err
+}
+
+func (g *tracingGateway) SendSignedTransaction(tx *flowkit.Transaction) (*flow.Transaction, error) {
+	span := g.span("SendSignedTransaction", Attribute{Key: "txID", Value: tx.FlowTransaction().ID().String()})
+	sent, err := g.gateway.SendSignedTransaction(tx)
+	endSpan(span, err)
+	return sent, err
+}
+
+func (g *tracingGateway) GetTransaction(ID flow.Identifier) (*flow.Transaction, error) {
+	span := g.span("GetTransaction", Attribute{Key: "txID", Value: ID.String()})
+	tx, err := g.gateway.GetTransaction(ID)
+	endSpan(span, err)
+	return tx, err
+}
+
+func (g *tracingGateway) GetTransactionResultsByBlockID(blockID flow.Identifier) ([]*flow.TransactionResult, error) {
+	span := g.span("GetTransactionResultsByBlockID", Attribute{Key: "blockID", Value: blockID.String()})
+	results, err := g.gateway.GetTransactionResultsByBlockID(blockID)
+	endSpan(span, err)
+	return results, err
+}
+
+func (g *tracingGateway) GetTransactionResult(ID flow.Identifier, waitSeal bool) (*flow.TransactionResult, error) {
+	span := g.span("GetTransactionResult", Attribute{Key: "txID", Value: ID.String()})
+	result, err := g.gateway.GetTransactionResult(ID, waitSeal)
+	if result != nil {
+		span.SetAttributes(Attribute{Key: "status", Value: result.Status.String()})
+	}
+	endSpan(span, err)
+	return result, err
+}
+
+func (g *tracingGateway) GetTransactionResultWithOptions(
+	ID flow.Identifier,
+	waitSeal bool,
+	options gateway.ResultOptions,
+) (*flow.TransactionResult, error) {
+	span := g.span(
+		"GetTransactionResultWithOptions",
+		Attribute{Key: "txID", Value: ID.String()},
+		Attribute{Key: "skipEvents", Value: strconv.FormatBool(options.SkipEvents)},
+	)
+	result, err := gateway.GetTransactionResultWithOptions(g.gateway, ID, waitSeal, options)
+	if result != nil {
+		span.SetAttributes(Attribute{Key: "status", Value: result.Status.String()})
+	}
+	endSpan(span, err)
+	return result, err
+}
+
+func (g *tracingGateway) GetTransactionsByBlockID(blockID flow.Identifier) ([]*flow.Transaction, error) {
+	span := g.span("GetTransactionsByBlockID", Attribute{Key: "blockID", Value: blockID.String()})
+	txs, err := g.gateway.GetTransactionsByBlockID(blockID)
+	endSpan(span, err)
+	return txs, err
+}
+
+func (g *tracingGateway) ExecuteScript(script []byte, args []cadence.Value) (cadence.Value, error) {
+	span := g.span("ExecuteScript")
+	value, err := g.gateway.ExecuteScript(script, args)
+	endSpan(span, err)
+	return value, err
+}
+
+func (g *tracingGateway) ProfileScript(script []byte, args []cadence.Value) (*gateway.ScriptProfile, error) {
+	profiler, ok := g.gateway.(gateway.ScriptProfiler)
+	if !ok {
+		return nil, &UnsupportedGatewayError{Operation: "script profiling"}
+	}
+
+	span := g.span("ProfileScript")
+	profile, err := profiler.ProfileScript(script, args)
+	endSpan(span, err)
+	return profile, err
+}
+
+func (g *tracingGateway) Isolated() bool {
+	isolated, ok := g.gateway.(gateway.IsolatedGateway)
+	return ok && isolated.Isolated()
+}
+
+func (g *tracingGateway) DefaultWaitPolicy() gateway.WaitPolicy {
+	return gateway.DefaultWaitPolicy(g.gateway)
+}
+
+func (g *tracingGateway) GetLatestBlock() (*flow.Block, error) {
+	span := g.span("GetLatestBlock")
+	block, err := g.gateway.GetLatestBlock()
+	endSpan(span, err)
+	return block, err
+}
+
+func (g *tracingGateway) GetBlockByHeight(height uint64) (*flow.Block, error) {
+	span := g.span("GetBlockByHeight")
+	block, err := g.gateway.GetBlockByHeight(height)
+	endSpan(span, err)
+	return block, err
+}
+
+func (g *tracingGateway) GetBlockByID(ID flow.Identifier) (*flow.Block, error) {
+	span := g.span("GetBlockByID", Attribute{Key: "blockID", Value: ID.String()})
+	block, err := g.gateway.GetBlockByID(ID)
+	endSpan(span, err)
+	return block, err
+}
+
+func (g *tracingGateway) GetEvents(eventType string, startHeight uint64, endHeight uint64) ([]flow.BlockEvents, error) {
+	span := g.span("GetEvents", Attribute{Key: "eventType", Value: eventType})
+	events, err := g.gateway.GetEvents(eventType, startHeight, endHeight)
+	endSpan(span, err)
+	return events, err
+}
+
+func (g *tracingGateway) GetCollection(ID flow.Identifier) (*flow.Collection, error) {
+	span := g.span("GetCollection", Attribute{Key: "collectionID", Value: ID.String()})
+	collection, err := g.gateway.GetCollection(ID)
+	endSpan(span, err)
+	return collection, err
+}
+
+func (g *tracingGateway) GetLatestProtocolStateSnapshot() ([]byte, error) {
+	span := g.span("GetLatestProtocolStateSnapshot")
+	snapshot, err := g.gateway.GetLatestProtocolStateSnapshot()
+	endSpan(span, err)
+	return snapshot, err
+}
+
+func (g *tracingGateway) Ping() error {
+	span := g.span("Ping")
+	err := g.gateway.Ping()
+	endSpan(span, err)
+	return err
+}
+
+func (g *tracingGateway) HealthCheck() gateway.ConnectionState {
+	return g.gateway.HealthCheck()
+}
+
+func (g *tracingGateway) Close() error {
+	return g.gateway.Close()
+}
+
+func (g *tracingGateway) SecureConnection() bool {
+	return g.gateway.SecureConnection()
+}
@@ -0,0 +1,216 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/onflow/flow-go-sdk/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/onflow/flow-cli/pkg/flowkit"
+	"github.com/onflow/flow-cli/pkg/flowkit/config"
+	"github.com/onflow/flow-cli/pkg/flowkit/output"
+	"github.com/onflow/flow-cli/pkg/flowkit/tests"
+)
+
+type spanKey struct{}
+
+type recordedSpan struct {
+	name       string
+	parent     *recordedSpan
+	attributes map[string]string
+	err        error
+	ended      bool
+}
+
+func (r *recordedSpan) SetAttributes(attributes ...Attribute) {
+	for _, a := range attributes {
+		r.attributes[a.Key] = a.Value
+	}
+}
+
+func (r *recordedSpan) RecordError(err error) {
+	r.err = err
+}
+
+func (r *recordedSpan) End() {
+	r.ended = true
+}
+
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+func (r *recordingTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	parent, _ := ctx.Value(spanKey{}).(*recordedSpan)
+	span := &recordedSpan{
+		name:       name,
+		parent:     parent,
+		attributes: make(map[string]string),
+	}
+	r.spans = append(r.spans, span)
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func (r *recordingTracer) byName(name string) []*recordedSpan {
+	spans := make([]*recordedSpan, 0)
+	for _, s := range r.spans {
+		if s.name == name {
+			spans = append(spans, s)
+		}
+	}
+	return spans
+}
+
+func TestTracing(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Deploy Project", func(t *testing.T) {
+		t.Parallel()
+
+		readerWriter, _ := tests.ReaderWriter()
+		state, err := flowkit.Init(readerWriter, crypto.ECDSA_P256, crypto.SHA3_256)
+		require.NoError(t, err)
+
+		gw := tests.DefaultMockGateway()
+		tracer := &recordingTracer{}
+		s := NewServicesWithTracer(gw.Mock, state, output.NewStdoutLogger(output.NoneLog), tracer)
+
+		c := config.Contract{
+			Name:     "Hello",
+			Location: tests.ContractHelloString.Filename,
+			Network:  "emulator",
+		}
+		state.Contracts().AddOrUpdate(c.Name, c)
+		state.Networks().AddOrUpdate("emulator", config.DefaultEmulatorNetwork())
+
+		a := tests.Alice()
+		state.Accounts().AddOrUpdate(a)
+		gw.GetAccount.Run(nil).Return(onChainAccount(a), nil)
+		state.Deployments().AddOrUpdate(config.Deployment{
+			Network:   "emulator",
+			Account:   a.Name(),
+			Contracts: []config.ContractDeployment{{Name: c.Name}},
+		})
+
+		_, err = s.Project.Deploy("emulator", false)
+		require.NoError(t, err)
+
+		deploy := tracer.byName("Project.Deploy")
+		require.Len(t, deploy, 1)
+		assert.Nil(t, deploy[0].parent)
+		assert.Equal(t, "emulator", deploy[0].attributes["network"])
+
+		addContract := tracer.byName("Accounts.AddContractWithOptions")
+		require.Len(t, addContract, 1)
+		assert.Equal(t, deploy[0], addContract[0].parent)
+		assert.Equal(t, a.Address().String(), addContract[0].attributes["account"])
+
+		// the deployment accounts are fetched by the preflight of the deployment and again to add the contract
+		parents := make(map[*recordedSpan]int)
+		for _, span := range tracer.byName("Gateway.GetAccount") {
+			parents[span.parent]++
+			assert.Equal(t, "GetAccount", span.attributes["method"])
+		}
+		assert.Len(t, parents, 2)
+		assert.NotZero(t, parents[deploy[0]])
+		assert.NotZero(t, parents[addContract[0]])
+
+		send := tracer.byName("Gateway.SendSignedTransaction")
+		require.Len(t, send, 1)
+		assert.Equal(t, addContract[0], send[0].parent)
+		assert.NotEmpty(t, send[0].attributes["txID"])
+
+		result := tracer.byName("Gateway.GetTransactionResult")
+		require.Len(t, result, 1)
+		assert.Equal(t, addContract[0], result[0].parent)
+		assert.Equal(t, "SEALED", result[0].attributes["status"])
+
+		for _, span := range tracer.spans {
+			assert.True(t, span.ended, span.name)
+		}
+	})
+
+	t.Run("Execute Script Error", func(t *testing.T) {
+		t.Parallel()
+
+		_, _, gw := setup()
+		tracer := &recordingTracer{}
+		s := NewServicesWithTracer(gw.Mock, nil, output.NewStdoutLogger(output.NoneLog), tracer)
+
+		_, err := s.Scripts.Execute(flowkit.NewScript([]byte("invalid"), nil, ""), "")
+		assert.Error(t, err)
+
+		execute := tracer.byName("Scripts.Execute")
+		require.Len(t, execute, 1)
+		assert.Equal(t, err, execute[0].err)
+		assert.True(t, execute[0].ended)
+	})
+
+	t.Run("Concurrent Operations", func(t *testing.T) {
+		t.Parallel()
+
+		_, _, gw := setup()
+		tracer := &recordingTracer{}
+		s := NewServicesWithTracer(gw.Mock, nil, output.NewStdoutLogger(output.NoneLog), tracer)
+
+		const count = 10
+		var wg sync.WaitGroup
+		for i := 0; i < count; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := s.Scripts.Execute(flowkit.NewScript(tests.ScriptArgString.Source, nil, ""), "")
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		execute := tracer.byName("Scripts.Execute")
+		require.Len(t, execute, count)
+		for _, span := range execute {
+			assert.Nil(t, span.parent)
+		}
+
+		// each gateway call is a child of the operation making it, not of another operation in flight
+		children := make(map[*recordedSpan]int)
+		for _, span := range tracer.byName("Gateway.ExecuteScript") {
+			children[span.parent]++
+		}
+		assert.Len(t, children, count)
+		for _, span := range execute {
+			assert.Equal(t, 1, children[span], "children of operation")
+		}
+	})
+
+	t.Run("No Tracer", func(t *testing.T) {
+		var tr *tracing
+		op := tr.start("Accounts.Create")
+		assert.Nil(t, op)
+		op.end(nil)
+	})
+}
@@ -24,8 +24,11 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
 
 	"github.com/onflow/flow-cli/pkg/flowkit"
 	"github.com/onflow/flow-cli/pkg/flowkit/gateway"
@@ -35,9 +38,12 @@ import (
 
 // Transactions is a service that handles all transaction-related interactions.
 type Transactions struct {
-	gateway gateway.Gateway
-	state   *flowkit.State
-	logger  output.Logger
+	gateway     gateway.Gateway
+	state       *flowkit.State
+	logger      output.Logger
+	sequences   *SequenceManager
+	diagnostics *diagnostics
+	payers      map[flow.Address]PayerProvider
 }
 
 // NewTransactions returns a new transactions service.
@@ -47,13 +53,25 @@ func NewTransactions(
 	logger output.Logger,
 ) *Transactions {
 	return &Transactions{
-		gateway: gateway,
-		state:   state,
-		logger:  logger,
+		gateway:   gateway,
+		state:     state,
+		logger:    logger,
+		sequences: NewSequenceManager(gateway),
+		payers:    make(map[flow.Address]PayerProvider),
 	}
 }
 
-func (t *Transactions) GetTransactionsByBlockID(id flow.Identifier) ([]*flow.Transaction, error) {
+func (t *Transactions) GetTransactionsByBlockID(id flow.Identifier) (txs []*flow.Transaction, err error) {
+	err = t.diagnostics.run(nil, "Transactions.GetTransactionsByBlockID", []Attribute{
+		{Key: "blockID", Value: id.String()},
+	}, func(*operation) error {
+		txs, err = t.getTransactionsByBlockID(id)
+		return err
+	})
+	return txs, err
+}
+
+func (t *Transactions) getTransactionsByBlockID(id flow.Identifier) ([]*flow.Transaction, error) {
 	tx, err := t.gateway.GetTransactionsByBlockID(id)
 	if err != nil {
 		return nil, err
@@ -61,8 +79,17 @@ func (t *Transactions) GetTransactionsByBlockID(id flow.Identifier) ([]*flow.Tra
 	return tx, nil
 }
 
-func (t *Transactions) GetTransactionResultsByBlockID(id flow.Identifier) ([]*flow.TransactionResult, error) {
+func (t *Transactions) GetTransactionResultsByBlockID(id flow.Identifier) (results []*flow.TransactionResult, err error) {
+	err = t.diagnostics.run(nil, "Transactions.GetTransactionResultsByBlockID", []Attribute{
+		{Key: "blockID", Value: id.String()},
+	}, func(*operation) error {
+		results, err = t.getTransactionResultsByBlockID(id)
+		return err
+	})
+	return results, err
+}
 
+func (t *Transactions) getTransactionResultsByBlockID(id flow.Identifier) ([]*flow.TransactionResult, error) {
 	tx, err := t.gateway.GetTransactionResultsByBlockID(id)
 	if err != nil {
 		return nil, err
@@ -74,6 +101,35 @@ func (t *Transactions) GetTransactionResultsByBlockID(id flow.Identifier) ([]*fl
 func (t *Transactions) GetStatus(
 	id flow.Identifier,
 	waitSeal bool,
+) (tx *flow.Transaction, result *flow.TransactionResult, err error) {
+	err = t.diagnostics.run(nil, "Transactions.GetStatus", []Attribute{
+		{Key: "txID", Value: id.String()},
+	}, func(*operation) error {
+		tx, result, err = t.getStatus(id, waitSeal, gateway.ResultOptions{})
+		return err
+	})
+	return tx, result, err
+}
+
+// GetStatusWithOptions gets the transaction and its result, with the result events retained by the options.
+func (t *Transactions) GetStatusWithOptions(
+	id flow.Identifier,
+	waitSeal bool,
+	options gateway.ResultOptions,
+) (tx *flow.Transaction, result *flow.TransactionResult, err error) {
+	err = t.diagnostics.run(nil, "Transactions.GetStatusWithOptions", []Attribute{
+		{Key: "txID", Value: id.String()},
+	}, func(*operation) error {
+		tx, result, err = t.getStatus(id, waitSeal, options)
+		return err
+	})
+	return tx, result, err
+}
+
+func (t *Transactions) getStatus(
+	id flow.Identifier,
+	waitSeal bool,
+	options gateway.ResultOptions,
 ) (*flow.Transaction, *flow.TransactionResult, error) {
 	t.logger.StartProgress("Fetching Transaction...")
 
@@ -86,7 +142,7 @@ func (t *Transactions) GetStatus(
 		t.logger.StartProgress("Waiting for transaction to be sealed...")
 	}
 
-	result, err := t.gateway.GetTransactionResult(id, waitSeal)
+	result, err := gateway.GetTransactionResultWithOptions(t.gateway, id, waitSeal, options)
 	t.logger.StopProgress()
 
 	return tx, result, err
@@ -184,6 +240,27 @@ type transactionAddresses struct {
 	payer       flow.Address
 }
 
+// DefaultMinimumValidityBlocks is the default number of blocks a transaction built for offline signing
+// should remain valid for, before a warning is reported.
+const DefaultMinimumValidityBlocks = 300
+
+// BuildOptions contains options for building a transaction.
+type BuildOptions struct {
+	// OfflineSigning should be set when the transaction is meant to be signed later, possibly on another machine.
+	OfflineSigning bool
+	// MinimumValidityBlocks is the number of blocks the transaction is expected to remain valid for
+	// when signing offline, defaults to DefaultMinimumValidityBlocks.
+	MinimumValidityBlocks uint64
+}
+
+// BuildResult contains the built transaction with information about how long it remains valid.
+type BuildResult struct {
+	Transaction     *flowkit.Transaction
+	ExpiryHeight    uint64
+	EstimatedExpiry time.Time
+	Warnings        []string
+}
+
 // Build builds a transaction with specified payer, proposer and authorizer.
 func (t *Transactions) Build(
 	addresses *transactionAddresses,
@@ -191,6 +268,119 @@ func (t *Transactions) Build(
 	script *flowkit.Script,
 	gasLimit uint64,
 	network string,
+) (tx *flowkit.Transaction, err error) {
+	err = t.diagnostics.run(nil, "Transactions.Build", []Attribute{
+		scriptInput("script", script),
+		{Key: "network", Value: network},
+	}, func(*operation) error {
+		tx, err = t.build(addresses, proposerKeyIndex, script, gasLimit, network, false)
+		return err
+	})
+	return tx, err
+}
+
+// BuildWithOptions builds a transaction same as Build and reports the expiry of the transaction.
+//
+// The transaction always references the latest sealed block, which gives it the longest possible
+// validity. If built for offline signing with a validity window shorter than the required minimum a warning is reported.
+func (t *Transactions) BuildWithOptions(
+	addresses *transactionAddresses,
+	proposerKeyIndex int,
+	script *flowkit.Script,
+	gasLimit uint64,
+	network string,
+	options BuildOptions,
+) (result *BuildResult, err error) {
+	err = t.diagnostics.run(nil, "Transactions.BuildWithOptions", []Attribute{
+		scriptInput("script", script),
+		{Key: "network", Value: network},
+	}, func(*operation) error {
+		result, err = t.buildWithOptions(addresses, proposerKeyIndex, script, gasLimit, network, options)
+		return err
+	})
+	return result, err
+}
+
+func (t *Transactions) buildWithOptions(
+	addresses *transactionAddresses,
+	proposerKeyIndex int,
+	script *flowkit.Script,
+	gasLimit uint64,
+	network string,
+	options BuildOptions,
+) (*BuildResult, error) {
+	tx, err := t.build(addresses, proposerKeyIndex, script, gasLimit, network, false)
+	if err != nil {
+		return nil, err
+	}
+
+	window, err := tx.ValidityWindow(t.gateway)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BuildResult{
+		Transaction:     tx,
+		ExpiryHeight:    window.ExpiryHeight,
+		EstimatedExpiry: time.Now().Add(window.RemainingTime),
+	}
+
+	minimum := options.MinimumValidityBlocks
+	if minimum == 0 {
+		minimum = DefaultMinimumValidityBlocks
+	}
+
+	if options.OfflineSigning && window.RemainingBlocks < minimum {
+		warning := fmt.Sprintf(
+			"transaction expires in %d blocks (~%s) at height %d, which is less than the required %d blocks for offline signing",
+			window.RemainingBlocks,
+			window.RemainingTime,
+			window.ExpiryHeight,
+			minimum,
+		)
+		output.Warn(t.logger, warning)
+		result.Warnings = append(result.Warnings, warning)
+	}
+
+	return result, nil
+}
+
+// BuildUnsigned builds a transaction for multi-party signing without signing or sending it.
+//
+// The first signer is the proposer, the last signer is the payer and all the signers are authorizers.
+// Imports in the script can't be resolved since no network is specified.
+func (t *Transactions) BuildUnsigned(script *flowkit.Script, signers []*flowkit.Account) (tx *flowkit.Transaction, err error) {
+	err = t.diagnostics.run(nil, "Transactions.BuildUnsigned", []Attribute{
+		scriptInput("script", script),
+		{Key: "signers", Value: fmt.Sprint(len(signers))},
+	}, func(*operation) error {
+		tx, err = t.buildUnsigned(script, signers)
+		return err
+	})
+	return tx, err
+}
+
+func (t *Transactions) buildUnsigned(script *flowkit.Script, signers []*flowkit.Account) (*flowkit.Transaction, error) {
+	if len(signers) == 0 {
+		return nil, fmt.Errorf("must provide at least one signer")
+	}
+
+	proposer := signers[0]
+	roles, err := NewTransactionAccountRoles(proposer, signers[len(signers)-1], signers)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.build(roles.toAddresses(), proposer.Key().Index(), script, flow.DefaultTransactionGasLimit, "", false)
+}
+
+func (t *Transactions) build(
+	addresses *transactionAddresses,
+	proposerKeyIndex int,
+	script *flowkit.Script,
+	gasLimit uint64,
+	network string,
+	reserveSequence bool,
 ) (*flowkit.Transaction, error) {
 	if t.state == nil {
 		return nil, fmt.Errorf("missing configuration, initialize it: flow state init")
@@ -229,18 +419,17 @@ func (t *Transactions) Build(
 			return nil, err
 		}
 
-		importReplacer := project.NewImportReplacer(
-			contracts,
-			t.state.AliasesForNetwork(network),
-		)
+		importReplacer := newImportReplacer(t.state, contracts, network)
 
 		program, err = importReplacer.Replace(program)
 		if err != nil {
 			return nil, fmt.Errorf("error resolving imports: %w", err)
 		}
+
+		logImportWarnings(t.logger, importReplacer)
 	}
 
-	if err := tx.SetProposer(proposerAccount, proposerKeyIndex); err != nil {
+	if err := t.sequences.setProposer(tx, proposerAccount, proposerKeyIndex, reserveSequence); err != nil {
 		return nil, err
 	}
 
@@ -260,6 +449,19 @@ func (t *Transactions) Build(
 func (t *Transactions) Sign(
 	signer *flowkit.Account,
 	payload []byte,
+) (tx *flowkit.Transaction, err error) {
+	err = t.diagnostics.run(nil, "Transactions.Sign", []Attribute{
+		accountInput("signer", signer),
+	}, func(*operation) error {
+		tx, err = t.sign(signer, payload)
+		return err
+	})
+	return tx, err
+}
+
+func (t *Transactions) sign(
+	signer *flowkit.Account,
+	payload []byte,
 ) (*flowkit.Transaction, error) {
 	if t.state == nil {
 		return nil, fmt.Errorf("missing configuration, initialize it: flow state init")
@@ -279,46 +481,96 @@ func (t *Transactions) Sign(
 }
 
 // SendSigned sends the transaction that is already signed.
-func (t *Transactions) SendSigned(tx *flowkit.Transaction) (*flow.Transaction, *flow.TransactionResult, error) {
+func (t *Transactions) SendSigned(tx *flowkit.Transaction) (sent *flow.Transaction, result *flow.TransactionResult, err error) {
+	err = t.diagnostics.run(nil, "Transactions.SendSigned", []Attribute{
+		{Key: "txID", Value: tx.FlowTransaction().ID().String()},
+	}, func(*operation) error {
+		sent, result, err = t.sendSigned(tx)
+		return err
+	})
+	return sent, result, err
+}
+
+func (t *Transactions) sendSigned(tx *flowkit.Transaction) (*flow.Transaction, *flow.TransactionResult, error) {
 	t.logger.StartProgress(fmt.Sprintf("Sending transaction with ID: %s", tx.FlowTransaction().ID()))
 	defer t.logger.StopProgress()
 
-	sentTx, err := t.gateway.SendSignedTransaction(tx)
+	var localKey flowkit.AccountKey
+	if signer := tx.Signer(); signer != nil && signer.Address() == tx.FlowTransaction().ProposalKey.Address {
+		localKey = signer.Key()
+	}
+
+	sentTx, err := t.sequences.send(tx)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, t.explainProposalKeyError(tx, localKey, err)
 	}
 
-	res, err := t.gateway.GetTransactionResult(sentTx.ID(), true)
+	res, err := t.sequences.result(tx, sentTx)
 	if err != nil {
 		return nil, nil, err
 	}
+	if res.Error != nil {
+		res.Error = t.explainProposalKeyError(tx, localKey, res.Error)
+	}
 
 	return sentTx, res, nil
 }
 
 // Send a transaction code using the signer account and arguments for the specified network.
+//
+// If no accounts are provided the default signer of the network fulfills all the roles.
 func (t *Transactions) Send(
 	accounts *transactionAccountRoles,
 	script *flowkit.Script,
 	gasLimit uint64,
 	network string,
+) (tx *flow.Transaction, result *flow.TransactionResult, err error) {
+	err = t.diagnostics.run(nil, "Transactions.Send", []Attribute{
+		scriptInput("script", script),
+		{Key: "network", Value: network},
+	}, func(*operation) error {
+		tx, result, err = t.send(accounts, script, gasLimit, network)
+		return err
+	})
+	return tx, result, err
+}
+
+func (t *Transactions) send(
+	accounts *transactionAccountRoles,
+	script *flowkit.Script,
+	gasLimit uint64,
+	network string,
 ) (*flow.Transaction, *flow.TransactionResult, error) {
 	if t.state == nil {
 		return nil, nil, fmt.Errorf("missing configuration, initialize it: flow state init")
 	}
 
-	tx, err := t.Build(
+	if accounts == nil {
+		signer, err := resolveSigner(t.state, nil, network)
+		if err != nil {
+			return nil, nil, err
+		}
+		accounts = NewSingleTransactionAccount(signer)
+	}
+
+	tx, err := t.build(
 		accounts.toAddresses(),
 		accounts.proposer.Key().Index(),
 		script,
 		gasLimit,
 		network,
+		true,
 	)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	payerProvider, remotePayer := t.payers[accounts.payer.Address()]
 	for _, signer := range accounts.getSigners() {
+		if remotePayer && signer.Address() == accounts.payer.Address() {
+			continue // payer signs the envelope remotely
+		}
+
 		err = tx.SetSigner(signer)
 		if err != nil {
 			return nil, nil, err
@@ -330,25 +582,137 @@ func (t *Transactions) Send(
 		}
 	}
 
+	if remotePayer {
+		t.logger.StartProgress("Requesting payer signature...")
+		err = t.signEnvelopeRemotely(tx, payerProvider)
+		t.logger.StopProgress()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
 	t.logger.Info(fmt.Sprintf("Transaction ID: %s", tx.FlowTransaction().ID()))
 	t.logger.StartProgress("Sending transaction...")
 
-	sentTx, err := t.gateway.SendSignedTransaction(tx)
+	sentTx, err := t.sequences.send(tx)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, t.explainProposalKeyError(tx, accounts.proposer.Key(), err)
 	}
 
 	t.logger.StopProgress()
 	t.logger.StartProgress("Waiting for transaction to be sealed...")
 	defer t.logger.StopProgress()
 
-	res, err := t.gateway.GetTransactionResult(sentTx.ID(), true)
+	res, err := t.sequences.result(tx, sentTx)
+	if res != nil && res.Error != nil {
+		res.Error = t.explainProposalKeyError(tx, accounts.proposer.Key(), res.Error)
+	}
 
 	return sentTx, res, err
 }
 
-func (t *Transactions) GetRLP(rlpUrl string) ([]byte, error) {
+// ProposalKeyError is returned when a transaction fails because of an invalid proposal key or signature.
+//
+// It compares the proposal key used in the transaction with the key on the chain to help
+// troubleshooting, the original error is available with errors.Unwrap.
+type ProposalKeyError struct {
+	Err      error
+	Address  flow.Address
+	KeyIndex int
+	// SentSequenceNumber is the sequence number used by the transaction.
+	SentSequenceNumber uint64
+	// ChainSequenceNumber is the sequence number of the key on the chain when the error happened.
+	ChainSequenceNumber uint64
+	Revoked             bool
+	Weight              int
+	// SentSigAlgo and SentHashAlgo are unknown if the local proposer key isn't available.
+	SentSigAlgo   crypto.SignatureAlgorithm
+	SentHashAlgo  crypto.HashAlgorithm
+	ChainSigAlgo  crypto.SignatureAlgorithm
+	ChainHashAlgo crypto.HashAlgorithm
+}
+
+func (p *ProposalKeyError) Error() string {
+	var b strings.Builder
+	b.WriteString(p.Err.Error())
+	b.WriteString(fmt.Sprintf("\n\nProposal key %d of account %s:", p.KeyIndex, p.Address))
+	b.WriteString(fmt.Sprintf("\n  sequence number:      sent %d, on-chain %d", p.SentSequenceNumber, p.ChainSequenceNumber))
+	b.WriteString(fmt.Sprintf("\n  revoked:              %t", p.Revoked))
+	b.WriteString(fmt.Sprintf("\n  weight:               %d", p.Weight))
+	b.WriteString(fmt.Sprintf("\n  signature algorithm:  sent %s, on-chain %s", p.SentSigAlgo, p.ChainSigAlgo))
+	b.WriteString(fmt.Sprintf("\n  hash algorithm:       sent %s, on-chain %s", p.SentHashAlgo, p.ChainHashAlgo))
+	return b.String()
+}
+
+func (p *ProposalKeyError) Unwrap() error {
+	return p.Err
+}
+
+// isProposalKeyError checks if the error is caused by the proposal key or the transaction signatures.
+func isProposalKeyError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "proposal key") ||
+		strings.Contains(msg, "sequence number") ||
+		strings.Contains(msg, "signature")
+}
+
+// explainProposalKeyError enriches proposal key and signature errors with the state of the proposal key on the chain.
+//
+// The enrichment is best-effort, if the proposer account can't be fetched the original error is returned.
+func (t *Transactions) explainProposalKeyError(
+	tx *flowkit.Transaction,
+	localKey flowkit.AccountKey,
+	err error,
+) error {
+	if err == nil || !isProposalKeyError(err) {
+		return err
+	}
+
+	proposalKey := tx.FlowTransaction().ProposalKey
+	account, accErr := t.gateway.GetAccount(proposalKey.Address)
+	if accErr != nil || account == nil {
+		return err
+	}
+
+	var chainKey *flow.AccountKey
+	for _, key := range account.Keys {
+		if key.Index == proposalKey.KeyIndex {
+			chainKey = key
+			break
+		}
+	}
+	if chainKey == nil {
+		return err
+	}
+
+	keyErr := &ProposalKeyError{
+		Err:                 err,
+		Address:             proposalKey.Address,
+		KeyIndex:            proposalKey.KeyIndex,
+		SentSequenceNumber:  proposalKey.SequenceNumber,
+		ChainSequenceNumber: chainKey.SequenceNumber,
+		Revoked:             chainKey.Revoked,
+		Weight:              chainKey.Weight,
+		ChainSigAlgo:        chainKey.SigAlgo,
+		ChainHashAlgo:       chainKey.HashAlgo,
+	}
+	if localKey != nil {
+		keyErr.SentSigAlgo = localKey.SigAlgo()
+		keyErr.SentHashAlgo = localKey.HashAlgo()
+	}
+
+	return keyErr
+}
+
+func (t *Transactions) GetRLP(rlpUrl string) (rlp []byte, err error) {
+	err = t.diagnostics.run(nil, "Transactions.GetRLP", nil, func(*operation) error {
+		rlp, err = t.getRLP(rlpUrl)
+		return err
+	})
+	return rlp, err
+}
 
+func (t *Transactions) getRLP(rlpUrl string) ([]byte, error) {
 	client := http.Client{
 		CheckRedirect: func(r *http.Request, via []*http.Request) error {
 			r.URL.Opaque = r.URL.Path
@@ -369,6 +733,14 @@ func (t *Transactions) GetRLP(rlpUrl string) ([]byte, error) {
 }
 
 func (t *Transactions) PostRLP(rlpUrl string, tx *flow.Transaction) error {
+	return t.diagnostics.run(nil, "Transactions.PostRLP", []Attribute{
+		{Key: "txID", Value: tx.ID().String()},
+	}, func(*operation) error {
+		return t.postRLP(rlpUrl, tx)
+	})
+}
+
+func (t *Transactions) postRLP(rlpUrl string, tx *flow.Transaction) error {
 	signedRlp := hex.EncodeToString(tx.Encode())
 	resp, err := http.Post(rlpUrl, "application/text", bytes.NewBufferString(signedRlp))
 
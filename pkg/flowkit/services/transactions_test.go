@@ -19,11 +19,15 @@
 package services
 
 import (
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/onflow/cadence"
+	jsoncdc "github.com/onflow/cadence/encoding/json"
 	"github.com/onflow/flow-go-sdk"
 	"github.com/onflow/flow-go-sdk/crypto"
 	"github.com/stretchr/testify/assert"
@@ -32,6 +36,7 @@ import (
 
 	"github.com/onflow/flow-cli/pkg/flowkit"
 	"github.com/onflow/flow-cli/pkg/flowkit/config"
+	"github.com/onflow/flow-cli/pkg/flowkit/gateway"
 	"github.com/onflow/flow-cli/pkg/flowkit/tests"
 )
 
@@ -95,6 +100,251 @@ func TestTransactions(t *testing.T) {
 		gw.Mock.AssertNumberOfCalls(t, tests.GetTransactionResultFunc, 1)
 	})
 
+	t.Run("Build Unsigned Transaction", func(t *testing.T) {
+		t.Parallel()
+		_, s, _ := setup()
+
+		alice := tests.Alice()
+		bob := tests.Bob()
+
+		tx, err := s.Transactions.BuildUnsigned(
+			flowkit.NewScript(tests.TransactionTwoAuth.Source, nil, ""),
+			[]*flowkit.Account{alice, bob},
+		)
+		require.NoError(t, err)
+
+		ftx := tx.FlowTransaction()
+		assert.Equal(t, alice.Address(), ftx.ProposalKey.Address)
+		assert.Equal(t, bob.Address(), ftx.Payer)
+		assert.Equal(t, []flow.Address{alice.Address(), bob.Address()}, ftx.Authorizers)
+		assert.Equal(t, uint64(flow.DefaultTransactionGasLimit), ftx.GasLimit)
+		assert.Len(t, ftx.PayloadSignatures, 0)
+		assert.Len(t, ftx.EnvelopeSignatures, 0)
+	})
+
+	t.Run("Build Unsigned Transaction No Signers", func(t *testing.T) {
+		t.Parallel()
+		_, s, _ := setup()
+
+		_, err := s.Transactions.BuildUnsigned(
+			flowkit.NewScript(tests.TransactionSimple.Source, nil, ""),
+			nil,
+		)
+		assert.EqualError(t, err, "must provide at least one signer")
+	})
+
+	proposerAccount := func() *flow.Account {
+		account := tests.NewAccountWithAddress(serviceAddress.String())
+		account.Keys[0].Index = 0
+		account.Keys[0].SequenceNumber = 5
+		account.Keys[0].Weight = flow.AccountKeyWeightThreshold
+		account.Keys[0].SigAlgo = crypto.ECDSA_secp256k1
+		account.Keys[0].HashAlgo = crypto.SHA2_256
+		return account
+	}
+
+	sendSimple := func(s *Services) error {
+		_, _, err := s.Transactions.Send(
+			NewSingleTransactionAccount(serviceAcc),
+			flowkit.NewScript(tests.TransactionSingleAuth.Source, nil, ""),
+			gasLimit,
+			"",
+		)
+		return err
+	}
+
+	t.Run("Send Transaction Proposal Key Error", func(t *testing.T) {
+		t.Parallel()
+		_, s, gw := setup()
+
+		account := proposerAccount()
+		gw.GetAccount.Run(func(args mock.Arguments) {
+			gw.GetAccount.Return(account, nil)
+		})
+
+		sendErr := fmt.Errorf("[Error Code: 1007] invalid proposal key: public key 0 on account %s has sequence number 7, but given 5", serviceAddress)
+		gw.SendSignedTransaction.Run(func(args mock.Arguments) {
+			account.Keys[0].SequenceNumber = 7
+			gw.SendSignedTransaction.Return(nil, sendErr)
+		})
+
+		err := sendSimple(s)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, sendErr)
+		assert.Contains(t, err.Error(), sendErr.Error())
+		assert.Contains(t, err.Error(), "sent 5, on-chain 7")
+
+		var keyErr *ProposalKeyError
+		require.True(t, errors.As(err, &keyErr))
+		assert.Equal(t, serviceAddress, keyErr.Address)
+		assert.Equal(t, 0, keyErr.KeyIndex)
+		assert.Equal(t, uint64(5), keyErr.SentSequenceNumber)
+		assert.Equal(t, uint64(7), keyErr.ChainSequenceNumber)
+		assert.False(t, keyErr.Revoked)
+		assert.Equal(t, flow.AccountKeyWeightThreshold, keyErr.Weight)
+		assert.Equal(t, serviceAcc.Key().SigAlgo(), keyErr.SentSigAlgo)
+		assert.Equal(t, serviceAcc.Key().HashAlgo(), keyErr.SentHashAlgo)
+		assert.Equal(t, crypto.ECDSA_secp256k1, keyErr.ChainSigAlgo)
+		assert.Equal(t, crypto.SHA2_256, keyErr.ChainHashAlgo)
+	})
+
+	t.Run("Send Transaction Signature Error Result", func(t *testing.T) {
+		t.Parallel()
+		_, s, gw := setup()
+
+		account := proposerAccount()
+		account.Keys[0].Revoked = true
+		gw.GetAccount.Run(func(args mock.Arguments) {
+			gw.GetAccount.Return(account, nil)
+		})
+
+		resultErr := fmt.Errorf("[Error Code: 1006] invalid envelope key: public key 0 on account %s does not have a valid signature", serviceAddress)
+		gw.GetTransactionResult.Run(func(args mock.Arguments) {
+			result := tests.NewTransactionResult(nil)
+			result.Error = resultErr
+			gw.GetTransactionResult.Return(result, nil)
+		})
+
+		_, res, err := s.Transactions.Send(
+			NewSingleTransactionAccount(serviceAcc),
+			flowkit.NewScript(tests.TransactionSingleAuth.Source, nil, ""),
+			gasLimit,
+			"",
+		)
+		require.NoError(t, err)
+
+		var keyErr *ProposalKeyError
+		require.True(t, errors.As(res.Error, &keyErr))
+		assert.ErrorIs(t, res.Error, resultErr)
+		assert.True(t, keyErr.Revoked)
+		assert.Equal(t, uint64(5), keyErr.SentSequenceNumber)
+		assert.Equal(t, uint64(5), keyErr.ChainSequenceNumber)
+	})
+
+	t.Run("Send Transaction Proposal Key Error Degraded", func(t *testing.T) {
+		t.Parallel()
+		_, s, gw := setup()
+
+		account := proposerAccount()
+		sent := false
+		gw.GetAccount.Run(func(args mock.Arguments) {
+			if sent {
+				gw.GetAccount.Return(nil, fmt.Errorf("access node unavailable"))
+				return
+			}
+			gw.GetAccount.Return(account, nil)
+		})
+
+		sendErr := fmt.Errorf("invalid proposal key")
+		gw.SendSignedTransaction.Run(func(args mock.Arguments) {
+			sent = true
+			gw.SendSignedTransaction.Return(nil, sendErr)
+		})
+
+		err := sendSimple(s)
+		assert.Equal(t, sendErr, err)
+	})
+
+	t.Run("Send Transaction Other Error Not Enriched", func(t *testing.T) {
+		t.Parallel()
+		_, s, gw := setup()
+
+		sendErr := fmt.Errorf("execution failed")
+		gw.SendSignedTransaction.Run(func(args mock.Arguments) {
+			gw.SendSignedTransaction.Return(nil, sendErr)
+		})
+
+		err := sendSimple(s)
+		assert.Equal(t, sendErr, err)
+		gw.Mock.AssertNumberOfCalls(t, tests.GetAccountFunc, 1)
+	})
+
+	buildWithHeights := func(
+		t *testing.T,
+		referenceHeight uint64,
+		sealedHeight uint64,
+		options BuildOptions,
+	) *BuildResult {
+		_, s, gw := setup()
+
+		reference := tests.NewBlock()
+		reference.Height = referenceHeight
+		sealed := tests.NewBlock()
+		sealed.Height = sealedHeight
+		gw.GetLatestBlock.Return(sealed, nil)
+		gw.GetBlockByID.Return(reference, nil)
+
+		result, err := s.Transactions.BuildWithOptions(
+			NewTransactionAddresses(serviceAddress, serviceAddress, []flow.Address{serviceAddress}),
+			0,
+			flowkit.NewScript(tests.TransactionSingleAuth.Source, nil, ""),
+			gasLimit,
+			"",
+			options,
+		)
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("Send With State Diff Unsupported Gateway", func(t *testing.T) {
+		t.Parallel()
+		_, s, gw := setup()
+
+		_, _, err := s.Transactions.SendWithStateDiff(
+			NewSingleTransactionAccount(serviceAcc),
+			flowkit.NewScript(tests.TransactionSingleAuth.Source, nil, ""),
+			flow.DefaultTransactionGasLimit,
+			"",
+			[]flow.Address{serviceAddress},
+		)
+
+		var unsupported *UnsupportedGatewayError
+		assert.ErrorAs(t, err, &unsupported)
+		gw.Mock.AssertNotCalled(t, tests.GetAccountFunc)
+	})
+
+	t.Run("Build Transaction Validity", func(t *testing.T) {
+		t.Parallel()
+		result := buildWithHeights(t, 1000, 1000, BuildOptions{})
+
+		assert.Equal(t, uint64(1000+flowkit.TransactionExpiry), result.ExpiryHeight)
+		remaining := time.Until(result.EstimatedExpiry)
+		assert.InDelta(t, float64(flowkit.TransactionExpiry*flowkit.EstimatedBlockTime), float64(remaining), float64(time.Minute))
+		assert.Len(t, result.Warnings, 0)
+	})
+
+	t.Run("Build Transaction Offline Signing", func(t *testing.T) {
+		t.Parallel()
+
+		// reference block fell behind the sealed height, leaving only 100 blocks
+		result := buildWithHeights(t, 500, 1000, BuildOptions{OfflineSigning: true})
+		assert.Equal(t, uint64(1100), result.ExpiryHeight)
+		require.Len(t, result.Warnings, 1)
+		assert.Contains(t, result.Warnings[0], "expires in 100 blocks")
+
+		result = buildWithHeights(t, 1000, 1000, BuildOptions{OfflineSigning: true})
+		assert.Len(t, result.Warnings, 0)
+
+		result = buildWithHeights(t, 1000, 1000, BuildOptions{OfflineSigning: true, MinimumValidityBlocks: 900})
+		assert.Len(t, result.Warnings, 1)
+
+		result = buildWithHeights(t, 500, 1000, BuildOptions{})
+		assert.Len(t, result.Warnings, 0)
+	})
+
+	t.Run("Transaction Validity Window", func(t *testing.T) {
+		t.Parallel()
+
+		window := flowkit.NewValidityWindow(100, 400)
+		assert.Equal(t, uint64(700), window.ExpiryHeight)
+		assert.Equal(t, uint64(300), window.RemainingBlocks)
+		assert.Equal(t, 300*flowkit.EstimatedBlockTime, window.RemainingTime)
+		assert.False(t, window.Expired())
+
+		window = flowkit.NewValidityWindow(100, 800)
+		assert.Equal(t, uint64(0), window.RemainingBlocks)
+		assert.True(t, window.Expired())
+	})
 }
 
 func setupAccounts(state *flowkit.State, s *Services) {
@@ -412,6 +662,35 @@ func TestTransactions_Integration(t *testing.T) {
 
 	})
 
+	t.Run("Get Status With Filtered Events", func(t *testing.T) {
+		t.Parallel()
+		state, s := setupIntegration()
+		srvAcc, _ := state.EmulatorServiceAccount()
+
+		id, _, err := s.Accounts.AddContract(srvAcc, resourceToContract(tests.ContractEvents), "", false)
+		require.NoError(t, err)
+
+		eventA := fmt.Sprintf("A.%s.ContractEvents.EventA", srvAcc.Address())
+		eventB := fmt.Sprintf("A.%s.ContractEvents.EventB", srvAcc.Address())
+		_, result, err := s.Transactions.GetStatusWithOptions(id, true, gateway.ResultOptions{
+			EventTypePrefixes: []string{eventA, eventB},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, flow.TransactionStatusSealed, result.Status)
+		require.Len(t, result.Events, 2)
+		assert.Equal(t, eventA, result.Events[0].Type)
+		assert.Equal(t, eventB, result.Events[1].Type)
+
+		_, result, err = s.Transactions.GetStatusWithOptions(id, true, gateway.ResultOptions{SkipEvents: true})
+		require.NoError(t, err)
+		assert.Equal(t, flow.TransactionStatusSealed, result.Status)
+		assert.Empty(t, result.Events)
+
+		_, result, err = s.Transactions.GetStatus(id, true)
+		require.NoError(t, err)
+		assert.Greater(t, len(result.Events), 10)
+	})
+
 	t.Run("Fails signing transaction, wrong account", func(t *testing.T) {
 		t.Parallel()
 		state, s := setupIntegration()
@@ -485,6 +764,35 @@ func TestTransactions_Integration(t *testing.T) {
 		assert.Equal(t, txr.Status, flow.TransactionStatusSealed)
 	})
 
+	t.Run("Send Transaction Redacted Args", func(t *testing.T) {
+		t.Parallel()
+		state, s := setupIntegration()
+		setupAccounts(state, s)
+
+		a, _ := state.Accounts().ByName("Alice")
+		s.Redactor.AddRules(flowkit.RedactionRule{
+			ScriptHash:    flowkit.ScriptHash(tests.TransactionArgString.Source),
+			ArgumentIndex: 0,
+		})
+
+		args := []cadence.Value{cadence.String("secret")}
+		tx, txr, err := s.Transactions.Send(
+			NewSingleTransactionAccount(a),
+			flowkit.NewScript(tests.TransactionArgString.Source, args, tests.TransactionArgString.Filename),
+			flow.DefaultTransactionGasLimit,
+			"",
+		)
+		require.NoError(t, err)
+		assert.Nil(t, txr.Error)
+
+		sent, _, err := s.Transactions.GetStatus(tx.ID(), true)
+		require.NoError(t, err)
+		encoded, err := jsoncdc.Encode(args[0])
+		require.NoError(t, err)
+		assert.JSONEq(t, string(encoded), string(sent.Arguments[0]))
+		assert.Equal(t, []string{flowkit.RedactedArgument}, s.Redactor.Arguments(sent))
+	})
+
 	t.Run("Send Transaction With Auths", func(t *testing.T) {
 		t.Parallel()
 		state, s := setupIntegration()
@@ -607,3 +915,81 @@ func TestTransactions_Integration(t *testing.T) {
 		assert.Equal(t, txr.Status, flow.TransactionStatusSealed)
 	})
 }
+
+func TestTransactionsSendWithStateDiff_Integration(t *testing.T) {
+	t.Parallel()
+
+	t.Run("FLOW Transfer", func(t *testing.T) {
+		t.Parallel()
+		state, s := setupIntegration()
+		setupAccounts(state, s)
+
+		srvAcc, _ := state.EmulatorServiceAccount()
+		a, _ := state.Accounts().ByName("Alice")
+		b, _ := state.Accounts().ByName("Bob")
+
+		amount, err := cadence.NewUFix64("10.5")
+		require.NoError(t, err)
+
+		result, diff, err := s.Transactions.SendWithStateDiff(
+			NewSingleTransactionAccount(srvAcc),
+			flowkit.NewScript([]byte(fundAccountTransaction), []cadence.Value{amount, cadence.NewAddress(a.Address())}, ""),
+			flow.DefaultTransactionGasLimit,
+			"",
+			[]flow.Address{srvAcc.Address(), a.Address(), b.Address()},
+		)
+		require.NoError(t, err)
+		require.NoError(t, result.Error)
+		require.Len(t, diff.Accounts, 3)
+
+		empty := []string{}
+		assert.Equal(t, AccountDiff{
+			Address:          srvAcc.Address(),
+			BalanceDelta:     -1_050_000_000,
+			ContractsAdded:   empty,
+			ContractsUpdated: empty,
+			ContractsRemoved: empty,
+			PathsAdded:       empty,
+			PathsRemoved:     empty,
+		}, diff.Accounts[0])
+		assert.Equal(t, int64(1_050_000_000), diff.ByAddress(a.Address()).BalanceDelta)
+		assert.Empty(t, diff.ByAddress(a.Address()).PathsAdded)
+		assert.False(t, diff.ByAddress(b.Address()).Changed())
+		assert.True(t, diff.Changed())
+	})
+
+	t.Run("Contract Deployment", func(t *testing.T) {
+		t.Parallel()
+		state, s := setupIntegration()
+		setupAccounts(state, s)
+
+		a, _ := state.Accounts().ByName("Alice")
+
+		code := []byte(`
+			transaction(code: String) {
+				prepare(signer: AuthAccount) {
+					signer.contracts.add(name: "Simple", code: code.decodeHex())
+				}
+			}`)
+
+		result, diff, err := s.Transactions.SendWithStateDiff(
+			NewSingleTransactionAccount(a),
+			flowkit.NewScript(code, []cadence.Value{cadence.String(hex.EncodeToString(tests.ContractSimple.Source))}, ""),
+			flow.DefaultTransactionGasLimit,
+			"",
+			[]flow.Address{a.Address()},
+		)
+		require.NoError(t, err)
+		require.NoError(t, result.Error)
+
+		empty := []string{}
+		assert.Equal(t, []AccountDiff{{
+			Address:          a.Address(),
+			ContractsAdded:   []string{"Simple"},
+			ContractsUpdated: empty,
+			ContractsRemoved: empty,
+			PathsAdded:       empty,
+			PathsRemoved:     empty,
+		}}, diff.Accounts)
+	})
+}
@@ -0,0 +1,134 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/onflow/flow-go-sdk"
+
+	"github.com/onflow/flow-cli/pkg/flowkit/config"
+	"github.com/onflow/flow-cli/pkg/flowkit/gateway"
+)
+
+// VerificationResult is the outcome of comparing a local contract with the contract deployed on the network.
+type VerificationResult struct {
+	ContractName string
+	AccountName  string
+	Address      flow.Address
+	// Match is true if the on-chain code is the same as the local code with resolved imports.
+	Match bool
+	// Diff is the unified diff from the on-chain code to the local code, empty if the code matches.
+	// Contracts that are not deployed have the whole local code as added lines.
+	Diff string
+}
+
+// Verify compares the project contracts of the network with the contracts deployed on their accounts.
+//
+// The imports of the local contracts are resolved the same as when deploying, so a contract matches
+// only if deploying it again wouldn't change the on-chain code. A result is returned for each
+// contract in the deployments of the network, including contracts or accounts that don't exist.
+func (p *Project) Verify(network string) (results []*VerificationResult, err error) {
+	err = p.diagnostics.run(p.tracing, "Project.Verify", []Attribute{
+		{Key: "network", Value: network},
+	}, func(op *operation) error {
+		results, err = p.traced(op).verify(network, VerifyOptions{})
+		return err
+	})
+	return results, err
+}
+
+// VerifyOptions configures how the local contracts are compared with the deployed contracts.
+type VerifyOptions struct {
+	// IgnoreFormatting compares the codes formatted with project.FormatCadence, so contracts differing
+	// only in formatting match and diffs don't include formatting changes.
+	IgnoreFormatting bool
+}
+
+// VerifyWithOptions compares the project contracts with the deployed contracts same as Verify.
+func (p *Project) VerifyWithOptions(network string, options VerifyOptions) (results []*VerificationResult, err error) {
+	err = p.diagnostics.run(p.tracing, "Project.VerifyWithOptions", []Attribute{
+		{Key: "network", Value: network},
+	}, func(op *operation) error {
+		results, err = p.traced(op).verify(network, options)
+		return err
+	})
+	return results, err
+}
+
+func (p *Project) verify(network string, options VerifyOptions) ([]*VerificationResult, error) {
+	if p.state == nil {
+		return nil, config.ErrDoesNotExist
+	}
+
+	if err := p.state.Validate(network); err != nil {
+		return nil, err
+	}
+
+	contracts, err := p.state.DeploymentContractsByNetwork(network)
+	if err != nil {
+		return nil, err
+	}
+
+	p.logger.StartProgress(fmt.Sprintf("Verifying %d contracts...", len(contracts)))
+	defer p.logger.StopProgress()
+
+	onChainContracts := make(map[flow.Address]map[string][]byte)
+	results := make([]*VerificationResult, 0, len(contracts))
+	for _, contract := range contracts {
+		program, err := p.resolveImports(contract, network)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve contract %s: %w", contract.Name, err)
+		}
+
+		deployed, ok := onChainContracts[contract.AccountAddress]
+		if !ok {
+			account, err := p.gateway.GetAccount(contract.AccountAddress)
+			if err != nil && !errors.Is(err, gateway.ErrAccountNotFound) {
+				return nil, fmt.Errorf("failed to get account %s: %w", contract.AccountName, err)
+			}
+			if account != nil {
+				deployed = account.Contracts
+			}
+			onChainContracts[contract.AccountAddress] = deployed
+		}
+
+		result := &VerificationResult{
+			ContractName: contract.Name,
+			AccountName:  contract.AccountName,
+			Address:      contract.AccountAddress,
+		}
+
+		onChain, exists := deployed[contract.Name]
+		onChain, local := comparableCodes(p.logger, contract.Name, onChain, program.Code(), options.IgnoreFormatting)
+		result.Match = exists && bytes.Equal(onChain, local)
+		if !result.Match {
+			result.Diff, err = contractDiff(contract, onChain, local)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,201 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/onflow/flow-go-sdk"
+
+	"github.com/onflow/flow-cli/pkg/flowkit/config"
+	"github.com/onflow/flow-cli/pkg/flowkit/gateway"
+)
+
+var (
+	watchMinBackoff = time.Second
+	watchMaxBackoff = time.Minute
+)
+
+// ContractChangeKind is the kind of change of a watched contract.
+type ContractChangeKind string
+
+const (
+	// ContractModified is reported when the code of the contract changed.
+	ContractModified ContractChangeKind = "modified"
+	// ContractRemoved is reported when the contract no longer exists on the account.
+	ContractRemoved ContractChangeKind = "removed"
+	// ContractAdded is reported when a contract that didn't exist appears on the account.
+	ContractAdded ContractChangeKind = "added"
+)
+
+// ContractChange describes a change of a contract on the network.
+type ContractChange struct {
+	Kind    ContractChangeKind
+	Name    string
+	Address flow.Address
+	// OldHash is the hex encoded SHA256 hash of the previously observed code, empty if the contract didn't exist.
+	OldHash string
+	// NewHash is the hex encoded SHA256 hash of the current code, empty if the contract was removed.
+	NewHash    string
+	DetectedAt time.Time
+}
+
+// watchedContract is a deployed contract with its last observed code hash.
+type watchedContract struct {
+	name     string
+	hash     string
+	observed bool
+}
+
+// WatchContracts watches the contracts deployed on the network for changes until the context is cancelled.
+//
+// The code of every contract in the network deployments is fetched each interval and its hash is compared with
+// the previous observation, the first observation is compared with the latest deployment recorded in the lock
+// file if there is one. The callback is invoked for each change, a contract that is removed from its account is
+// reported as ContractRemoved. Errors fetching the accounts are retried with backoff and never reported as changes.
+// The context error is returned once the context is cancelled.
+func (p *Project) WatchContracts(
+	ctx context.Context,
+	network string,
+	interval time.Duration,
+	onChange func(ContractChange),
+) error {
+	return p.diagnostics.run(p.tracing, "Project.WatchContracts", []Attribute{
+		{Key: "network", Value: network},
+	}, func(op *operation) error {
+		return p.traced(op).watchContracts(ctx, network, interval, onChange)
+	})
+}
+
+func (p *Project) watchContracts(
+	ctx context.Context,
+	network string,
+	interval time.Duration,
+	onChange func(ContractChange),
+) error {
+	if p.state == nil {
+		return config.ErrDoesNotExist
+	}
+
+	if interval <= 0 {
+		return fmt.Errorf("watch interval must be positive")
+	}
+
+	lock, err := p.state.LockFile()
+	if err != nil {
+		return err
+	}
+
+	// contracts are grouped by account so each account is fetched once
+	watched := make(map[flow.Address][]*watchedContract)
+	addresses := make([]flow.Address, 0)
+	for _, deployment := range p.state.Deployments().ByNetwork(network) {
+		account, err := p.state.Accounts().ByName(deployment.Account)
+		if err != nil {
+			return err
+		}
+
+		address := account.Address()
+		if _, ok := watched[address]; !ok {
+			addresses = append(addresses, address)
+		}
+
+		for _, c := range deployment.Contracts {
+			contract := &watchedContract{name: c.Name}
+			for _, record := range lock.ByContract(network, c.Name) {
+				if record.Address == address.String() {
+					contract.hash = record.CodeHash
+					contract.observed = true
+					break
+				}
+			}
+			watched[address] = append(watched[address], contract)
+		}
+	}
+
+	for {
+		for _, address := range addresses {
+			contracts, err := p.fetchWatchedContracts(ctx, address)
+			if err != nil {
+				return err
+			}
+
+			for _, contract := range watched[address] {
+				hash := ""
+				if code, exists := contracts[contract.name]; exists {
+					hash = codeHash(code)
+				}
+
+				if contract.observed && hash != contract.hash {
+					change := ContractChange{
+						Kind:       ContractModified,
+						Name:       contract.name,
+						Address:    address,
+						OldHash:    contract.hash,
+						NewHash:    hash,
+						DetectedAt: time.Now(),
+					}
+					if hash == "" {
+						change.Kind = ContractRemoved
+					} else if contract.hash == "" {
+						change.Kind = ContractAdded
+					}
+					onChange(change)
+				}
+
+				contract.hash = hash
+				contract.observed = true
+			}
+		}
+
+		if err := sleepContext(ctx, interval); err != nil {
+			return err
+		}
+	}
+}
+
+// fetchWatchedContracts returns the contracts on the account, retrying with backoff until the account is fetched.
+//
+// An account that doesn't exist has no contracts.
+func (p *Project) fetchWatchedContracts(ctx context.Context, address flow.Address) (map[string][]byte, error) {
+	backoff := time.Duration(0)
+	for {
+		account, err := p.gateway.GetAccount(address)
+		if errors.Is(err, gateway.ErrAccountNotFound) {
+			return nil, nil
+		}
+		if err == nil {
+			return account.Contracts, nil
+		}
+
+		if backoff == 0 {
+			backoff = watchMinBackoff
+		} else if backoff*2 <= watchMaxBackoff {
+			backoff *= 2
+		}
+		p.logger.Debug(fmt.Sprintf("fetching account 0x%s failed, retrying in %s: %s", address, backoff, err))
+
+		if err := sleepContext(ctx, backoff); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,164 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package flowkit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"unicode/utf8"
+)
+
+// DefaultMaxSourceSize is the maximum size in bytes of contract, script and transaction sources read from files.
+const DefaultMaxSourceSize = 4 << 20
+
+// maxInvalidUTF8Ratio is the share of bytes in invalid UTF-8 sequences above which a source is considered binary,
+// a few invalid bytes are tolerated so sources saved in another encoding with non ASCII comments can still be read.
+const maxInvalidUTF8Ratio = 0.01
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+var (
+	ErrSourceTooLarge = errors.New("source exceeds the maximum size")
+	ErrBinarySource   = errors.New("source is not text")
+)
+
+// SourceError is returned when a source is refused, it wraps ErrSourceTooLarge or ErrBinarySource.
+type SourceError struct {
+	Location string
+	Err      error
+	Detail   string
+}
+
+func (s *SourceError) Error() string {
+	if s.Detail == "" {
+		return fmt.Sprintf("invalid source %s: %s", s.Location, s.Err)
+	}
+	return fmt.Sprintf("invalid source %s: %s, %s", s.Location, s.Err, s.Detail)
+}
+
+func (s *SourceError) Unwrap() error {
+	return s.Err
+}
+
+// SourceLimits configures the checks done on sources read from files.
+type SourceLimits struct {
+	// MaxSize is the maximum size in bytes, DefaultMaxSourceSize if zero and unlimited if negative.
+	MaxSize int64
+}
+
+func (s SourceLimits) maxSize() int64 {
+	if s.MaxSize == 0 {
+		return DefaultMaxSourceSize
+	}
+	return s.MaxSize
+}
+
+func (s SourceLimits) checkSize(location string, size int64) error {
+	if limit := s.maxSize(); limit > 0 && size > limit {
+		return &SourceError{
+			Location: location,
+			Err:      ErrSourceTooLarge,
+			Detail:   fmt.Sprintf("size is %d bytes and the limit is %d bytes", size, limit),
+		}
+	}
+	return nil
+}
+
+// SanitizeSource checks the source code read from the location and returns it without a UTF-8 byte order mark.
+//
+// Sources bigger than the maximum size of the limits and sources that are not text, because they contain
+// NUL bytes or too many invalid UTF-8 sequences, are refused with a SourceError.
+func SanitizeSource(location string, code []byte, limits SourceLimits) ([]byte, error) {
+	if err := limits.checkSize(location, int64(len(code))); err != nil {
+		return nil, err
+	}
+
+	if i := bytes.IndexByte(code, 0); i >= 0 {
+		return nil, &SourceError{
+			Location: location,
+			Err:      ErrBinarySource,
+			Detail:   fmt.Sprintf("found NUL byte at offset %d", i),
+		}
+	}
+
+	if invalid := invalidUTF8Bytes(code); float64(invalid) > float64(len(code))*maxInvalidUTF8Ratio {
+		return nil, &SourceError{
+			Location: location,
+			Err:      ErrBinarySource,
+			Detail:   fmt.Sprintf("found %d bytes of invalid UTF-8", invalid),
+		}
+	}
+
+	return bytes.TrimPrefix(code, utf8BOM), nil
+}
+
+// invalidUTF8Bytes returns the number of bytes in the code that are not part of valid UTF-8 sequences.
+func invalidUTF8Bytes(code []byte) int {
+	if utf8.Valid(code) {
+		return 0
+	}
+
+	invalid := 0
+	for len(code) > 0 {
+		r, size := utf8.DecodeRune(code)
+		if r == utf8.RuneError && size == 1 {
+			invalid++
+		}
+		code = code[size:]
+	}
+	return invalid
+}
+
+// fileStater is implemented by reader writers that can return the size of a file without reading it.
+type fileStater interface {
+	Stat(name string) (os.FileInfo, error)
+}
+
+// SetSourceLimits changes the limits of the sources read with ReadSource.
+func (p *State) SetSourceLimits(limits SourceLimits) {
+	p.sourceLimits = limits
+}
+
+// SourceLimits returns the limits of the sources read with ReadSource.
+func (p *State) SourceLimits() SourceLimits {
+	return p.sourceLimits
+}
+
+// ReadSource reads the contract, script or transaction source from the location and sanitizes it with the
+// source limits of the state.
+//
+// Files are refused before being read if their size is known to exceed the limit.
+func (p *State) ReadSource(location string) ([]byte, error) {
+	if stater, ok := p.readerWriter.(fileStater); ok {
+		if info, err := stater.Stat(location); err == nil && !info.IsDir() {
+			if err := p.sourceLimits.checkSize(location, info.Size()); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	code, err := p.readerWriter.ReadFile(location)
+	if err != nil {
+		return nil, err
+	}
+
+	return SanitizeSource(location, code, p.sourceLimits)
+}
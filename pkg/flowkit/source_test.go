@@ -0,0 +1,109 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package flowkit
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SanitizeSource(t *testing.T) {
+	contract := []byte("pub contract Hello {}\n")
+
+	t.Run("Valid", func(t *testing.T) {
+		code, err := SanitizeSource("hello.cdc", contract, SourceLimits{})
+		require.NoError(t, err)
+		assert.Equal(t, contract, code)
+	})
+
+	t.Run("Byte Order Mark", func(t *testing.T) {
+		code, err := SanitizeSource("hello.cdc", append([]byte("\xEF\xBB\xBF"), contract...), SourceLimits{})
+		require.NoError(t, err)
+		assert.Equal(t, contract, code)
+	})
+
+	t.Run("Too Large", func(t *testing.T) {
+		_, err := SanitizeSource("big.cdc", bytes.Repeat([]byte("a"), DefaultMaxSourceSize+1), SourceLimits{})
+		assert.ErrorIs(t, err, ErrSourceTooLarge)
+		assert.EqualError(t, err, "invalid source big.cdc: source exceeds the maximum size, size is 4194305 bytes and the limit is 4194304 bytes")
+
+		_, err = SanitizeSource("hello.cdc", contract, SourceLimits{MaxSize: 10})
+		assert.ErrorIs(t, err, ErrSourceTooLarge)
+
+		code, err := SanitizeSource("big.cdc", bytes.Repeat([]byte("a"), DefaultMaxSourceSize+1), SourceLimits{MaxSize: -1})
+		require.NoError(t, err)
+		assert.Len(t, code, DefaultMaxSourceSize+1)
+	})
+
+	t.Run("Binary", func(t *testing.T) {
+		_, err := SanitizeSource("hello.wasm", []byte("\x00asm\x01\x00\x00\x00"), SourceLimits{})
+		assert.ErrorIs(t, err, ErrBinarySource)
+		assert.EqualError(t, err, "invalid source hello.wasm: source is not text, found NUL byte at offset 0")
+
+		_, err = SanitizeSource("image.png", bytes.Repeat([]byte{0x89, 0xff, 0xfe, 'a'}, 100), SourceLimits{})
+		assert.ErrorIs(t, err, ErrBinarySource)
+		assert.EqualError(t, err, "invalid source image.png: source is not text, found 300 bytes of invalid UTF-8")
+	})
+
+	t.Run("Few Invalid UTF-8 Bytes", func(t *testing.T) {
+		// a Latin-1 encoded comment in an otherwise valid source
+		code := append([]byte("// caf\xe9\n"), bytes.Repeat(contract, 10)...)
+		sanitized, err := SanitizeSource("hello.cdc", code, SourceLimits{})
+		require.NoError(t, err)
+		assert.Equal(t, code, sanitized)
+	})
+}
+
+func Test_ReadSource(t *testing.T) {
+	af := afero.Afero{Fs: afero.NewMemMapFs()}
+	require.NoError(t, af.WriteFile("hello.cdc", []byte("\xEF\xBB\xBFpub contract Hello {}"), 0644))
+	require.NoError(t, af.WriteFile("big.cdc", bytes.Repeat([]byte("a"), 2048), 0644))
+	require.NoError(t, af.WriteFile("binary.cdc", []byte{'p', 'u', 'b', 0}, 0644))
+
+	state := &State{readerWriter: af}
+
+	code, err := state.ReadSource("hello.cdc")
+	require.NoError(t, err)
+	assert.Equal(t, "pub contract Hello {}", string(code))
+
+	_, err = state.ReadSource("binary.cdc")
+	assert.ErrorIs(t, err, ErrBinarySource)
+	assert.ErrorContains(t, err, "binary.cdc")
+
+	_, err = state.ReadSource("missing.cdc")
+	assert.Error(t, err)
+
+	code, err = state.ReadSource("big.cdc")
+	require.NoError(t, err)
+	assert.Len(t, code, 2048)
+
+	state.SetSourceLimits(SourceLimits{MaxSize: 1024})
+	_, err = state.ReadSource("big.cdc")
+	assert.ErrorIs(t, err, ErrSourceTooLarge)
+	assert.EqualError(t, err, "invalid source big.cdc: source exceeds the maximum size, size is 2048 bytes and the limit is 1024 bytes")
+
+	state.SetSourceLimits(SourceLimits{MaxSize: -1})
+	code, err = state.ReadSource("big.cdc")
+	require.NoError(t, err)
+	assert.Len(t, code, 2048)
+}
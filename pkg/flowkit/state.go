@@ -20,8 +20,11 @@ package flowkit
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path"
+	"sort"
+	"strings"
 
 	"github.com/onflow/flow-go-sdk/crypto"
 
@@ -43,6 +46,23 @@ type State struct {
 	confLoader   *config.Loader
 	readerWriter ReaderWriter
 	accounts     *Accounts
+	sourceLimits SourceLimits
+}
+
+// ConfigMigrations returns the changes made to each configuration file migrated while loading.
+func (p *State) ConfigMigrations() map[string][]config.MigrationChange {
+	return p.confLoader.Migrations()
+}
+
+// Root returns the project root, the directory containing the configuration.
+//
+// Path imports of the project contracts can't resolve outside of the root, the root is empty and
+// imports are not bounded if the state wasn't loaded from a project configuration.
+func (p *State) Root() string {
+	if p.confLoader == nil {
+		return ""
+	}
+	return p.confLoader.Root()
 }
 
 // ReaderWriter retrieve current file reader writer.
@@ -82,12 +102,18 @@ func (p *State) SaveEdited(paths []string) error {
 // Save saves the project configuration to the given path.
 func (p *State) Save(path string) error {
 	p.conf.Accounts = accountsToConfig(*p.accounts, p.confLoader.AccountsFromFile())
+	for i := range p.conf.Accounts {
+		p.conf.Accounts[i] = p.withKeyFromFile(p.conf.Accounts[i])
+	}
 	err := p.confLoader.Save(p.conf, path)
 
 	// if we have defined accounts to be saved to an external file, iterate over them and save them separately
 	for name, location := range p.confLoader.AccountsFromFile() {
 		acc, _ := p.accounts.ByName(name)
-		account := toConfig(*acc, nil)
+		if acc == nil {
+			continue // removed from the accounts collection directly
+		}
+		account := p.withKeyFromFile(toConfig(*acc, nil))
 		account.UseAdvanceFormat = true // in case where we save accounts to a separate file we use advance format even if default value
 
 		c := config.Empty()
@@ -105,6 +131,51 @@ func (p *State) Save(path string) error {
 	return nil
 }
 
+// Serialize writes the project configuration as JSON to the writer.
+//
+// The configuration is written one top-level section at a time, so large configurations aren't built in
+// memory before writing. All accounts are written inline, including the ones saved in separate files.
+func (p *State) Serialize(w io.Writer) error {
+	conf := *p.conf
+	conf.Accounts = accountsToConfig(*p.accounts, nil)
+
+	return json.NewParser().SerializeTo(w, &conf)
+}
+
+// Deserialize reads a project configuration written as JSON by Serialize from the reader.
+//
+// The configuration is read one top-level section at a time. Files referenced by the configuration
+// are read from the local filesystem.
+func Deserialize(r io.Reader) (*State, error) {
+	parser := json.NewParser()
+	conf, err := parser.DeserializeFrom(r)
+	if err != nil {
+		return nil, err
+	}
+	addDefaultEmulator(conf)
+
+	readerWriter := osReaderWriter{}
+	loader := config.NewLoader(readerWriter)
+	loader.AddConfigParser(parser)
+
+	proj, err := newProject(conf, loader, readerWriter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project configuration: %s", err)
+	}
+
+	return proj, nil
+}
+
+// withKeyFromFile sets the key file location of the account with the key loaded from a key file,
+// so the key is saved back to the key file instead of the configuration.
+func (p *State) withKeyFromFile(account config.Account) config.Account {
+	location, ok := p.confLoader.KeysFromFile()[account.Name]
+	if ok && account.Location == "" && account.Key.Type != "" {
+		account.Key.Location = location
+	}
+	return account
+}
+
 // Networks get network configuration.
 func (p *State) Networks() *config.Networks {
 	return &p.conf.Networks
@@ -120,6 +191,11 @@ func (p *State) Contracts() *config.Contracts {
 	return &p.conf.Contracts
 }
 
+// Scripts get registered scripts configuration.
+func (p *State) Scripts() *config.Scripts {
+	return &p.conf.Scripts
+}
+
 // Accounts get accounts.
 func (p *State) Accounts() *Accounts {
 	return p.accounts
@@ -135,6 +211,34 @@ func (p *State) SetAccountFileLocation(account Account, location string) {
 	p.confLoader.SetAccountFromFile(account.name, location)
 }
 
+// RemoveAccountOptions configures the removal of an account from the state.
+type RemoveAccountOptions struct {
+	// RemoveDeployments removes the deployments to the account on all networks.
+	RemoveDeployments bool
+}
+
+// RemoveAccount removes the account from the state, ErrAccountNotFound is returned if no account has the name.
+//
+// The account is no longer saved to the separate file it was loaded from, if any.
+func (p *State) RemoveAccount(name string, options RemoveAccountOptions) error {
+	if err := p.accounts.Remove(name); err != nil {
+		return err
+	}
+	p.confLoader.RemoveAccount(name)
+
+	if options.RemoveDeployments {
+		deployments := make(config.Deployments, 0, len(p.conf.Deployments))
+		for _, deployment := range p.conf.Deployments {
+			if deployment.Account != name {
+				deployments = append(deployments, deployment)
+			}
+		}
+		p.conf.Deployments = deployments
+	}
+
+	return nil
+}
+
 // EmulatorServiceAccount returns the service account for the default emulator profile.
 func (p *State) EmulatorServiceAccount() (*Account, error) {
 	emulator := p.conf.Emulators.Default()
@@ -145,6 +249,28 @@ func (p *State) EmulatorServiceAccount() (*Account, error) {
 	return p.accounts.ByName(emulator.ServiceAccount)
 }
 
+// NoSignerError is returned when no signer is provided and no default signer is configured for the network.
+type NoSignerError struct {
+	Network string
+}
+
+func (n *NoSignerError) Error() string {
+	return fmt.Sprintf("no signer provided and no defaultSigner configured for network '%s'", n.Network)
+}
+
+// DefaultSignerForNetwork returns the default signer account configured for the network.
+//
+// The emulator service account is never used as a fallback, a NoSignerError is returned if the network
+// doesn't configure a default signer.
+func (p *State) DefaultSignerForNetwork(network string) (*Account, error) {
+	n, err := p.conf.Networks.ByName(network)
+	if err != nil || n.DefaultSigner == "" {
+		return nil, &NoSignerError{Network: network}
+	}
+
+	return p.accounts.ByName(n.DefaultSigner)
+}
+
 // SetEmulatorKey sets the default emulator service account private key.
 func (p *State) SetEmulatorKey(privateKey crypto.PrivateKey) {
 	acc, _ := p.EmulatorServiceAccount()
@@ -178,7 +304,7 @@ func (p *State) DeploymentContractsByNetwork(network string) ([]*project.Contrac
 				return nil, err
 			}
 
-			code, err := p.readerWriter.ReadFile(c.Location)
+			code, err := p.ReadSource(c.Location)
 			if err != nil {
 				return nil, err
 			}
@@ -199,6 +325,68 @@ func (p *State) DeploymentContractsByNetwork(network string) ([]*project.Contrac
 	return contracts, nil
 }
 
+// ValidationError contains all the problems found when validating the state.
+type ValidationError struct {
+	// MissingContracts maps contract names to their locations which don't exist.
+	MissingContracts map[string]string
+}
+
+func (v *ValidationError) Error() string {
+	names := make([]string, 0, len(v.MissingContracts))
+	for name := range v.MissingContracts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	missing := make([]string, len(names))
+	for i, name := range names {
+		missing[i] = fmt.Sprintf("%s (%s)", name, v.MissingContracts[name])
+	}
+
+	return fmt.Sprintf("contract files not found: %s", strings.Join(missing, ", "))
+}
+
+// Validate checks that the files of the contracts deployed on the network exist.
+//
+// Aliased contracts are skipped as they are not deployed from the source, and so are the deployed contracts
+// missing from the configuration which fail the deployment anyway. All missing files are reported in a
+// single ValidationError.
+func (p *State) Validate(network string) error {
+	missing := make(map[string]string)
+
+	for _, deployment := range p.conf.Deployments.ByNetwork(network) {
+		for _, deployed := range deployment.Contracts {
+			contract, err := p.conf.Contracts.ByNameAndNetwork(deployed.Name, network)
+			if err != nil || contract.IsAlias() {
+				continue
+			}
+
+			if !p.fileExists(contract.Location) {
+				missing[contract.Name] = contract.Location
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		return &ValidationError{MissingContracts: missing}
+	}
+
+	return nil
+}
+
+// fileExists checks if the file exists using the reader writer, preferring a stat if it's supported.
+func (p *State) fileExists(location string) bool {
+	if statter, ok := p.readerWriter.(interface {
+		Stat(string) (os.FileInfo, error)
+	}); ok {
+		_, err := statter.Stat(location)
+		return err == nil
+	}
+
+	_, err := p.readerWriter.ReadFile(location)
+	return err == nil
+}
+
 // AccountsForNetwork returns all accounts used on a network defined by deployments.
 func (p *State) AccountsForNetwork(network string) Accounts {
 	exists := make(map[string]bool, 0)
@@ -214,6 +402,68 @@ func (p *State) AccountsForNetwork(network string) Accounts {
 	return accounts
 }
 
+// AccountTagSelectorPrefix prefixes an account selector that selects all accounts tagged with the tag, e.g. "tag:admin".
+const AccountTagSelectorPrefix = "tag:"
+
+// AccountsByTag returns all accounts tagged with the tag.
+func (p *State) AccountsByTag(tag string) []*Account {
+	accounts := make([]*Account, 0)
+	for i := range *p.accounts {
+		if (*p.accounts)[i].HasTag(tag) {
+			accounts = append(accounts, &(*p.accounts)[i])
+		}
+	}
+	return accounts
+}
+
+// ResolveAccountSelectors returns the accounts selected by the selectors.
+//
+// A selector is either an account name or a tag prefixed with "tag:" which selects all the accounts
+// tagged with it. An error is returned if the tag doesn't match any account. Accounts selected more
+// than once are returned only once, in the order they were first selected.
+func (p *State) ResolveAccountSelectors(selectors []string) ([]*Account, error) {
+	accounts := make([]*Account, 0, len(selectors))
+	selected := make(map[string]bool)
+
+	for _, selector := range selectors {
+		var matched []*Account
+		if tag, ok := cutTagSelector(selector); ok {
+			matched = p.AccountsByTag(tag)
+			if len(matched) == 0 {
+				return nil, fmt.Errorf("account selector %s doesn't match any account", selector)
+			}
+		} else {
+			account, err := p.accounts.ByName(selector)
+			if err != nil {
+				return nil, err
+			}
+			matched = []*Account{account}
+		}
+
+		for _, account := range matched {
+			if !selected[account.name] {
+				selected[account.name] = true
+				accounts = append(accounts, account)
+			}
+		}
+	}
+
+	return accounts, nil
+}
+
+// IsAccountTagSelector returns true if the selector selects accounts by a tag.
+func IsAccountTagSelector(selector string) bool {
+	_, ok := cutTagSelector(selector)
+	return ok
+}
+
+func cutTagSelector(selector string) (string, bool) {
+	if !strings.HasPrefix(selector, AccountTagSelectorPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(selector, AccountTagSelectorPrefix), true
+}
+
 // AliasesForNetwork returns all deployment aliases for a network.
 func (p *State) AliasesForNetwork(network string) project.Aliases {
 	aliases := make(project.Aliases)
@@ -228,9 +478,86 @@ func (p *State) AliasesForNetwork(network string) project.Aliases {
 	return aliases
 }
 
+// Clone returns a copy of the state, changing the configuration or the accounts of the copy doesn't change the original.
+//
+// The copy shares the reader writer and the configuration loader with the original.
+func (p *State) Clone() *State {
+	conf := &config.Config{
+		Emulators:   append(config.Emulators{}, p.conf.Emulators...),
+		Contracts:   append(config.Contracts{}, p.conf.Contracts...),
+		Networks:    append(config.Networks{}, p.conf.Networks...),
+		Accounts:    append(config.Accounts{}, p.conf.Accounts...),
+		Deployments: make(config.Deployments, len(p.conf.Deployments)),
+		Scripts:     make(config.Scripts, len(p.conf.Scripts)),
+	}
+	for i, deployment := range p.conf.Deployments {
+		deployment.Contracts = append([]config.ContractDeployment{}, deployment.Contracts...)
+		conf.Deployments[i] = deployment
+	}
+	for i, script := range p.conf.Scripts {
+		script.Arguments = append([]config.ScriptArgument(nil), script.Arguments...)
+		conf.Scripts[i] = script
+	}
+
+	accounts := append(Accounts{}, *p.accounts...)
+
+	return &State{
+		conf:         conf,
+		confLoader:   p.confLoader,
+		readerWriter: p.readerWriter,
+		accounts:     &accounts,
+	}
+}
+
+// WithNetwork returns a copy of the state scoped to the network.
+//
+// The copy contains only the configuration of the network, its deployments, the accounts targeted by
+// the deployments and the contracts available on the network. The emulators configuration is kept as is.
+// Saving the copy saves only the scoped configuration.
+func (p *State) WithNetwork(name string) *State {
+	scoped := p.Clone()
+
+	networks := make(config.Networks, 0)
+	if network, err := p.conf.Networks.ByName(name); err == nil {
+		networks = append(networks, *network)
+	}
+	scoped.conf.Networks = networks
+
+	scoped.conf.Deployments = append(config.Deployments{}, scoped.conf.Deployments.ByNetwork(name)...)
+	scoped.conf.Contracts = append(config.Contracts{}, scoped.conf.Contracts.ByNetwork(name)...)
+
+	accounts := scoped.AccountsForNetwork(name)
+	scoped.accounts = &accounts
+
+	confAccounts := make(config.Accounts, 0)
+	for _, account := range scoped.conf.Accounts {
+		if _, err := accounts.ByName(account.Name); err == nil {
+			confAccounts = append(confAccounts, account)
+		}
+	}
+	scoped.conf.Accounts = confAccounts
+
+	return scoped
+}
+
+// LoadOptions contains options for loading a project configuration.
+type LoadOptions struct {
+	// Migrate migrates configurations written in older schema versions in memory.
+	Migrate bool
+	// PersistMigration saves the migrated configurations, backing up the originals.
+	PersistMigration bool
+}
+
 // Load loads a project configuration and returns the resulting project.
 func Load(configFilePaths []string, readerWriter ReaderWriter) (*State, error) {
+	return LoadWithOptions(configFilePaths, readerWriter, LoadOptions{})
+}
+
+// LoadWithOptions loads a project configuration same as Load, with the possibility of
+// migrating configurations written in older schema versions.
+func LoadWithOptions(configFilePaths []string, readerWriter ReaderWriter, options LoadOptions) (*State, error) {
 	confLoader := config.NewLoader(readerWriter)
+	confLoader.SetMigration(options.Migrate, options.PersistMigration)
 
 	// here we add all available parsers (more to add yaml etc...)
 	confLoader.AddConfigParser(json.NewParser())
@@ -238,11 +565,7 @@ func Load(configFilePaths []string, readerWriter ReaderWriter) (*State, error) {
 	if err != nil {
 		return nil, err
 	}
-	// only add a default emulator in the config if the emulator account is present in accounts
-	_, err = conf.Accounts.ByName(config.DefaultEmulatorServiceAccountName)
-	if err == nil && len(conf.Emulators) == 0 {
-		conf.Emulators.AddOrUpdate("", config.DefaultEmulator())
-	}
+	addDefaultEmulator(conf)
 	proj, err := newProject(conf, confLoader, readerWriter)
 	if err != nil {
 		return nil, fmt.Errorf("invalid project configuration: %s", err)
@@ -251,6 +574,14 @@ func Load(configFilePaths []string, readerWriter ReaderWriter) (*State, error) {
 	return proj, nil
 }
 
+// addDefaultEmulator adds a default emulator to the config, only if the emulator account is present in accounts.
+func addDefaultEmulator(conf *config.Config) {
+	_, err := conf.Accounts.ByName(config.DefaultEmulatorServiceAccountName)
+	if err == nil && len(conf.Emulators) == 0 {
+		conf.Emulators.AddOrUpdate("", config.DefaultEmulator())
+	}
+}
+
 // Exists checks if a project configuration exists.
 func Exists(path string) bool {
 	return config.Exists(path)
@@ -19,9 +19,13 @@
 package flowkit
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"sort"
+	"strings"
 	"testing"
 
 	"github.com/onflow/flow-go-sdk"
@@ -511,6 +515,187 @@ func Test_GetAliasesComplex(t *testing.T) {
 	assert.Equal(t, cTestnet[1].Name, "FungibleToken")
 }
 
+func Test_WithNetwork(t *testing.T) {
+	p := generateAliasesComplexProject()
+
+	testnet := p.WithNetwork("testnet")
+
+	assert.Equal(t, config.Networks{{Name: "testnet", Host: "127.0.0.1.3569"}}, *testnet.Networks())
+
+	require.Len(t, *testnet.Deployments(), 1)
+	assert.Equal(t, "testnet-account", (*testnet.Deployments())[0].Account)
+
+	require.Len(t, *testnet.Accounts(), 1)
+	assert.Equal(t, "testnet-account", (*testnet.Accounts())[0].Name())
+	require.Len(t, testnet.Config().Accounts, 1)
+	assert.Equal(t, "testnet-account", testnet.Config().Accounts[0].Name)
+
+	contractNames := make([]string, 0)
+	for _, c := range *testnet.Contracts() {
+		contractNames = append(contractNames, fmt.Sprintf("%s@%s", c.Name, c.Network))
+	}
+	assert.Equal(t, []string{"NonFungibleToken@", "Kibble@testnet"}, contractNames)
+	assert.Equal(t, project.Aliases{
+		"../hungry-kitties/cadence/contracts/Kibble.cdc": "ee82856bf20e2aa6",
+	}, testnet.AliasesForNetwork("testnet"))
+
+	unknown := p.WithNetwork("mainnet")
+	assert.Empty(t, *unknown.Networks())
+	assert.Empty(t, *unknown.Deployments())
+	assert.Empty(t, *unknown.Accounts())
+
+	// the original state is not changed
+	assert.Len(t, *p.Networks(), 2)
+	assert.Len(t, *p.Deployments(), 2)
+	assert.Len(t, *p.Accounts(), 2)
+	assert.Len(t, *p.Contracts(), 4)
+}
+
+func Test_Clone(t *testing.T) {
+	p := generateAliasesComplexProject()
+	clone := p.Clone()
+
+	(*clone.Deployments())[1].Contracts[0].Name = "Changed"
+	clone.Networks().AddOrUpdate("mainnet", config.DefaultMainnetNetwork())
+	account, err := clone.Accounts().ByName("testnet-account")
+	require.NoError(t, err)
+	account.SetAddress(flow.HexToAddress("01"))
+	require.NoError(t, clone.Accounts().Remove("emulator-account"))
+
+	assert.Equal(t, "NonFungibleToken", (*p.Deployments())[1].Contracts[0].Name)
+	assert.Len(t, *p.Networks(), 2)
+	original, err := p.Accounts().ByName("testnet-account")
+	require.NoError(t, err)
+	assert.Equal(t, flow.HexToAddress("1e82856bf20e2aa6"), original.Address())
+	assert.Len(t, *p.Accounts(), 2)
+}
+
+func Test_SerializeState(t *testing.T) {
+	p := generateComplexProject()
+
+	var buf bytes.Buffer
+	require.NoError(t, p.Serialize(&buf))
+	serialized := buf.String()
+
+	deserialized, err := Deserialize(&buf)
+	require.NoError(t, err)
+
+	buffered, err := json.NewParser().Deserialize([]byte(serialized))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, buffered.Contracts, *deserialized.Contracts())
+	assert.ElementsMatch(t, buffered.Deployments, *deserialized.Deployments())
+	assert.ElementsMatch(t, *p.Networks(), *deserialized.Networks())
+	require.Len(t, *deserialized.Accounts(), len(*p.Accounts()))
+	for _, account := range *p.Accounts() {
+		acc, err := deserialized.Accounts().ByName(account.Name())
+		require.NoError(t, err)
+		assert.Equal(t, account.Address(), acc.Address())
+	}
+
+	var again bytes.Buffer
+	require.NoError(t, deserialized.Serialize(&again))
+	assert.Equal(t, serialized, again.String())
+
+	_, err = Deserialize(strings.NewReader(`{ "networks": `))
+	assert.ErrorContains(t, err, "configuration syntax error")
+}
+
+func benchmarkState(contracts int) *State {
+	conf := config.Config{
+		Networks: config.Networks{{Name: "emulator", Host: "127.0.0.1:3569"}},
+		Accounts: config.Accounts{{
+			Name:    "emulator-account",
+			Address: flow.ServiceAddress(flow.Emulator),
+			Key: config.AccountKey{
+				Type:       config.KeyTypeHex,
+				SigAlgo:    crypto.ECDSA_P256,
+				HashAlgo:   crypto.SHA3_256,
+				PrivateKey: keys()[0],
+			},
+		}},
+		Deployments: config.Deployments{{Network: "emulator", Account: "emulator-account"}},
+	}
+	for i := 0; i < contracts; i++ {
+		name := fmt.Sprintf("Contract%d", i)
+		conf.Contracts = append(conf.Contracts, config.Contract{
+			Name:     name,
+			Location: fmt.Sprintf("./cadence/contracts/%s.cdc", name),
+		})
+		conf.Deployments[0].Contracts = append(conf.Deployments[0].Contracts, config.ContractDeployment{Name: name})
+	}
+
+	state, _ := newProject(&conf, composer, af)
+	return state
+}
+
+// BenchmarkState_Serialize compares serializing the whole configuration in memory before writing it,
+// as Save does, with streaming it section by section.
+func BenchmarkState_Serialize(b *testing.B) {
+	state := benchmarkState(1000)
+
+	b.Run("Buffered/1000", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			data, _ := json.NewParser().Serialize(state.conf)
+			_, _ = io.Discard.Write(data)
+		}
+	})
+
+	b.Run("Streaming/1000", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = state.Serialize(io.Discard)
+		}
+	})
+}
+
+// BenchmarkState_Deserialize compares reading the whole configuration in memory before parsing it,
+// as Load does, with decoding it section by section.
+func BenchmarkState_Deserialize(b *testing.B) {
+	var buf bytes.Buffer
+	_ = benchmarkState(1000).Serialize(&buf)
+	data := buf.Bytes()
+
+	b.Run("Buffered/1000", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			raw, _ := io.ReadAll(bytes.NewReader(data))
+			conf, _ := json.NewParser().Deserialize(raw)
+			_, _ = newProject(conf, composer, af)
+		}
+	})
+
+	b.Run("Streaming/1000", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _ = Deserialize(bytes.NewReader(data))
+		}
+	})
+}
+
+func Test_AccountsByTag(t *testing.T) {
+	p := generateAliasesComplexProject()
+	emulatorAccount, err := p.Accounts().ByName("emulator-account")
+	require.NoError(t, err)
+	emulatorAccount.SetTags([]string{"admin", "emulator"})
+	testnetAccount, err := p.Accounts().ByName("testnet-account")
+	require.NoError(t, err)
+	testnetAccount.SetTags([]string{"admin"})
+
+	assert.Len(t, p.AccountsByTag("admin"), 2)
+	assert.Len(t, p.AccountsByTag("emulator"), 1)
+	assert.Empty(t, p.AccountsByTag("testnet-only"))
+
+	accounts, err := p.ResolveAccountSelectors([]string{"tag:emulator", "tag:admin"})
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	assert.Equal(t, "emulator-account", accounts[0].Name())
+	assert.Equal(t, "testnet-account", accounts[1].Name())
+
+	_, err = p.ResolveAccountSelectors([]string{"tag:testnet-only"})
+	assert.EqualError(t, err, "account selector tag:testnet-only doesn't match any account")
+}
+
 func Test_ChangingState(t *testing.T) {
 	p := generateSimpleProject()
 
@@ -590,6 +775,157 @@ func Test_LoadState(t *testing.T) {
 	assert.Equal(t, acc.Name(), "emulator-account")
 }
 
+func Test_SaveKeyFromFile(t *testing.T) {
+	b := []byte(`{
+		"accounts": {
+			"emulator-account": {
+				"address": "f8d6e0586b0a20c7",
+				"key": "21c5dfdeb0ff03a7a73ef39788563b62c89adea67bbb21ab95e5f710bd1d40b7"
+			},
+			"testnet-account": {
+				"key": { "fromFile": "keys/testnet.json" }
+			}
+		}
+	}`)
+
+	af := afero.Afero{Fs: afero.NewMemMapFs()}
+	require.NoError(t, af.WriteFile("flow.json", b, 0644))
+	require.NoError(t, af.WriteFile("keys/testnet.json", []byte(`{
+		"address": "f1d6e0586b0a20c7",
+		"key": "3335dfdeb0ff03a7a73ef39788563b62c89adea67bbb21ab95e5f710bd1d40b7"
+	}`), 0644))
+
+	state, err := Load([]string{"flow.json"}, af)
+	require.NoError(t, err)
+
+	acc, err := state.Accounts().ByName("testnet-account")
+	require.NoError(t, err)
+	assert.Equal(t, "f1d6e0586b0a20c7", acc.Address().String())
+	acc.SetKey(NewHexAccountKeyFromPrivateKey(0, crypto.SHA3_256, keys()[1]))
+
+	require.NoError(t, state.Save("flow.json"))
+
+	saved, err := af.ReadFile("flow.json")
+	require.NoError(t, err)
+	assert.Contains(t, string(saved), `"fromFile": "keys/testnet.json"`)
+	assert.NotContains(t, string(saved), "388e3fbdc654b765942610679bb3a66b74212149ab9482187067ee116d9a8118")
+
+	savedKey, err := af.ReadFile("keys/testnet.json")
+	require.NoError(t, err)
+	assert.Contains(t, string(savedKey), "388e3fbdc654b765942610679bb3a66b74212149ab9482187067ee116d9a8118")
+}
+
+func Test_RemoveAccount(t *testing.T) {
+	load := func(t *testing.T) (*State, afero.Afero) {
+		b := []byte(`{
+			"contracts": {
+				"Foo": "./Foo.cdc"
+			},
+			"networks": {
+				"emulator": "127.0.0.1:3569",
+				"testnet": "access.devnet.nodes.onflow.org:9000"
+			},
+			"accounts": {
+				"emulator-account": {
+					"address": "f8d6e0586b0a20c7",
+					"key": "21c5dfdeb0ff03a7a73ef39788563b62c89adea67bbb21ab95e5f710bd1d40b7"
+				},
+				"alice": { "fromFile": "private.json" },
+				"bob": {
+					"key": { "fromFile": "keys/bob.json" }
+				}
+			},
+			"deployments": {
+				"emulator": {
+					"emulator-account": ["Foo"],
+					"alice": ["Foo"]
+				},
+				"testnet": {
+					"alice": ["Foo"]
+				}
+			}
+		}`)
+
+		af := afero.Afero{Fs: afero.NewMemMapFs()}
+		require.NoError(t, af.WriteFile("flow.json", b, 0644))
+		require.NoError(t, af.WriteFile("private.json", []byte(`{
+			"accounts": {
+				"alice": {
+					"address": "179b6b1cb6755e31",
+					"key": "728d21a7622a29d976f4d264afe26379b8a63f5d1343773f813a37d4262b9f52"
+				}
+			}
+		}`), 0644))
+		require.NoError(t, af.WriteFile("keys/bob.json", []byte(`{
+			"address": "f3fcd2c1a78f5eee",
+			"key": "9463ceedf08627108ea0b394c96b18446d1370e7332c91ce332aba1594096ba0"
+		}`), 0644))
+
+		state, err := Load([]string{"flow.json"}, af)
+		require.NoError(t, err)
+		return state, af
+	}
+
+	t.Run("Not Found", func(t *testing.T) {
+		state, _ := load(t)
+
+		err := state.RemoveAccount("charlie", RemoveAccountOptions{})
+		assert.True(t, errors.Is(err, ErrAccountNotFound))
+		assert.EqualError(t, err, "account named charlie does not exist in configuration: account not found")
+
+		err = state.Accounts().Remove("charlie")
+		assert.True(t, errors.Is(err, ErrAccountNotFound))
+		assert.Len(t, *state.Accounts(), 3)
+	})
+
+	t.Run("Keep Deployments", func(t *testing.T) {
+		state, _ := load(t)
+
+		require.NoError(t, state.RemoveAccount("alice", RemoveAccountOptions{}))
+		_, err := state.Accounts().ByName("alice")
+		assert.Error(t, err)
+		assert.Len(t, state.Deployments().ForAccount("alice"), 2)
+	})
+
+	t.Run("Remove Deployments", func(t *testing.T) {
+		state, _ := load(t)
+
+		require.NoError(t, state.RemoveAccount("alice", RemoveAccountOptions{RemoveDeployments: true}))
+		assert.Len(t, state.Deployments().ForAccount("alice"), 0)
+		assert.Len(t, state.Deployments().ForAccount("emulator-account"), 1)
+	})
+
+	t.Run("Save", func(t *testing.T) {
+		state, af := load(t)
+
+		require.NoError(t, state.RemoveAccount("alice", RemoveAccountOptions{RemoveDeployments: true}))
+		require.NoError(t, state.RemoveAccount("bob", RemoveAccountOptions{}))
+		assert.NotContains(t, state.confLoader.AccountsFromFile(), "alice")
+		assert.NotContains(t, state.confLoader.KeysFromFile(), "bob")
+		require.NoError(t, state.Save("flow.json"))
+
+		saved, err := af.ReadFile("flow.json")
+		require.NoError(t, err)
+		assert.NotContains(t, string(saved), "alice")
+		assert.NotContains(t, string(saved), "bob")
+
+		reloaded, err := Load([]string{"flow.json"}, af)
+		require.NoError(t, err)
+		assert.Len(t, *reloaded.Accounts(), 1)
+	})
+
+	t.Run("Save Removed From Collection", func(t *testing.T) {
+		state, af := load(t)
+
+		require.NoError(t, state.Accounts().Remove("alice"))
+		require.NoError(t, state.Save("flow.json"))
+
+		saved, err := af.ReadFile("flow.json")
+		require.NoError(t, err)
+		assert.NotContains(t, string(saved), `"alice": {`)
+	})
+}
+
 func Test_LoadStateMultiple(t *testing.T) {
 	b := []byte(`{
 		"accounts": {
@@ -884,3 +1220,116 @@ func Test_CustomEmulatorValuesInConfig(t *testing.T) {
 	assert.Equal(t, state.conf, &config)
 	assert.NoError(t, err)
 }
+
+func Test_Validate(t *testing.T) {
+	rw := afero.Afero{Fs: afero.NewMemMapFs()}
+	_ = rw.WriteFile("contracts/Foo.cdc", []byte("pub contract Foo {}"), os.ModePerm)
+
+	state, err := Init(rw, crypto.ECDSA_P256, crypto.SHA3_256)
+	require.NoError(t, err)
+
+	state.Contracts().AddOrUpdate("Foo", config.Contract{
+		Name:     "Foo",
+		Location: "contracts/Foo.cdc",
+		Network:  "emulator",
+	})
+	state.Contracts().AddOrUpdate("FungibleToken", config.Contract{
+		Name:     "FungibleToken",
+		Location: "contracts/FungibleToken.cdc",
+		Network:  "testnet",
+		Alias:    "9a0766d93b6608b7",
+	})
+	state.Deployments().AddOrUpdate(config.Deployment{
+		Network:   "emulator",
+		Account:   "emulator-account",
+		Contracts: []config.ContractDeployment{{Name: "Foo"}},
+	})
+	state.Deployments().AddOrUpdate(config.Deployment{
+		Network:   "testnet",
+		Account:   "testnet-account",
+		Contracts: []config.ContractDeployment{{Name: "FungibleToken"}},
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		assert.NoError(t, state.Validate("emulator"))
+		assert.NoError(t, state.Validate("testnet"))
+	})
+
+	t.Run("Missing files", func(t *testing.T) {
+		state.Contracts().AddOrUpdate("Bar", config.Contract{
+			Name:     "Bar",
+			Location: "contracts/Bar.cdc",
+			Network:  "emulator",
+		})
+		state.Contracts().AddOrUpdate("Zoo", config.Contract{
+			Name:     "Zoo",
+			Location: "contracts/Zoo.cdc",
+			Network:  "emulator",
+		})
+		state.Deployments().AddOrUpdate(config.Deployment{
+			Network:   "emulator",
+			Account:   "emulator-account",
+			Contracts: []config.ContractDeployment{{Name: "Foo"}, {Name: "Bar"}, {Name: "Zoo"}},
+		})
+
+		err := state.Validate("emulator")
+		var validationErr *ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, map[string]string{
+			"Bar": "contracts/Bar.cdc",
+			"Zoo": "contracts/Zoo.cdc",
+		}, validationErr.MissingContracts)
+		assert.Equal(t, "contract files not found: Bar (contracts/Bar.cdc), Zoo (contracts/Zoo.cdc)", err.Error())
+	})
+
+	t.Run("Other Network", func(t *testing.T) {
+		state.Contracts().AddOrUpdate("Qux", config.Contract{
+			Name:     "Qux",
+			Location: "contracts/Qux.cdc",
+			Network:  "testnet",
+		})
+		state.Deployments().AddOrUpdate(config.Deployment{
+			Network:   "testnet",
+			Account:   "testnet-account",
+			Contracts: []config.ContractDeployment{{Name: "FungibleToken"}, {Name: "Qux"}},
+		})
+
+		err := state.Validate("testnet")
+		var validationErr *ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, map[string]string{"Qux": "contracts/Qux.cdc"}, validationErr.MissingContracts)
+	})
+}
+
+func Test_DefaultSignerForNetwork(t *testing.T) {
+	rw := afero.Afero{Fs: afero.NewMemMapFs()}
+	state, err := Init(rw, crypto.ECDSA_P256, crypto.SHA3_256)
+	require.NoError(t, err)
+
+	state.Accounts().AddOrUpdate(NewAccount("testnet-account").SetAddress(flow.HexToAddress("0x72ddb3d2cec14114")))
+	state.Networks().AddOrUpdate("testnet", config.Network{
+		Name:          "testnet",
+		Host:          config.DefaultTestnetNetwork().Host,
+		DefaultSigner: "testnet-account",
+	})
+	state.Networks().AddOrUpdate("mainnet", config.DefaultMainnetNetwork())
+
+	t.Run("Configured", func(t *testing.T) {
+		signer, err := state.DefaultSignerForNetwork("testnet")
+		require.NoError(t, err)
+		assert.Equal(t, "testnet-account", signer.Name())
+	})
+
+	t.Run("Not Configured", func(t *testing.T) {
+		_, err := state.DefaultSignerForNetwork("mainnet")
+		var noSignerErr *NoSignerError
+		require.ErrorAs(t, err, &noSignerErr)
+		assert.EqualError(t, err, "no signer provided and no defaultSigner configured for network 'mainnet'")
+	})
+
+	t.Run("No Emulator Fallback", func(t *testing.T) {
+		// the emulator service account exists but isn't used as the default signer
+		_, err := state.DefaultSignerForNetwork("emulator")
+		assert.EqualError(t, err, "no signer provided and no defaultSigner configured for network 'emulator'")
+	})
+}
@@ -27,32 +27,36 @@ import (
 )
 
 const (
-	GetAccountFunc            = "GetAccount"
-	SendSignedTransactionFunc = "SendSignedTransaction"
-	GetCollectionFunc         = "GetCollection"
-	GetTransactionResultFunc  = "GetTransactionResult"
-	GetEventsFunc             = "GetEvents"
-	GetLatestBlockFunc        = "GetLatestBlock"
-	GetBlockByHeightFunc      = "GetBlockByHeight"
-	GetBlockByIDFunc          = "GetBlockByID"
-	ExecuteScriptFunc         = "ExecuteScript"
-	GetTransactionFunc        = "GetTransaction"
+	GetAccountFunc              = "GetAccount"
+	GetAccountAtBlockHeightFunc = "GetAccountAtBlockHeight"
+	SendSignedTransactionFunc   = "SendSignedTransaction"
+	GetCollectionFunc           = "GetCollection"
+	GetTransactionResultFunc    = "GetTransactionResult"
+	GetEventsFunc               = "GetEvents"
+	GetLatestBlockFunc          = "GetLatestBlock"
+	GetBlockByHeightFunc        = "GetBlockByHeight"
+	GetBlockByIDFunc            = "GetBlockByID"
+	ExecuteScriptFunc           = "ExecuteScript"
+	GetTransactionFunc          = "GetTransaction"
+	CloseFunc                   = "Close"
 )
 
 // go:generate
 
 type TestGateway struct {
-	Mock                  *mocks.Gateway
-	SendSignedTransaction *mock.Call
-	GetAccount            *mock.Call
-	GetCollection         *mock.Call
-	GetTransactionResult  *mock.Call
-	GetEvents             *mock.Call
-	GetLatestBlock        *mock.Call
-	GetBlockByHeight      *mock.Call
-	GetBlockByID          *mock.Call
-	ExecuteScript         *mock.Call
-	GetTransaction        *mock.Call
+	Mock                    *mocks.Gateway
+	SendSignedTransaction   *mock.Call
+	GetAccount              *mock.Call
+	GetAccountAtBlockHeight *mock.Call
+	GetCollection           *mock.Call
+	GetTransactionResult    *mock.Call
+	GetEvents               *mock.Call
+	GetLatestBlock          *mock.Call
+	GetBlockByHeight        *mock.Call
+	GetBlockByID            *mock.Call
+	ExecuteScript           *mock.Call
+	GetTransaction          *mock.Call
+	Close                   *mock.Call
 }
 
 func DefaultMockGateway() *TestGateway {
@@ -67,6 +71,11 @@ func DefaultMockGateway() *TestGateway {
 			GetAccountFunc,
 			mock.AnythingOfType("flow.Address"),
 		),
+		GetAccountAtBlockHeight: m.On(
+			GetAccountAtBlockHeightFunc,
+			mock.AnythingOfType("flow.Address"),
+			mock.AnythingOfType("uint64"),
+		),
 		GetCollection: m.On(
 			GetCollectionFunc,
 			mock.AnythingOfType("flow.Identifier"),
@@ -94,6 +103,7 @@ func DefaultMockGateway() *TestGateway {
 		GetBlockByHeight: m.On(GetBlockByHeightFunc, mock.Anything),
 		GetBlockByID:     m.On(GetBlockByIDFunc, mock.Anything),
 		GetLatestBlock:   m.On(GetLatestBlockFunc),
+		Close:            m.On(CloseFunc),
 	}
 
 	// default return values
@@ -106,6 +116,11 @@ func DefaultMockGateway() *TestGateway {
 		t.GetAccount.Return(NewAccountWithAddress(addr.String()), nil)
 	})
 
+	t.GetAccountAtBlockHeight.Run(func(args mock.Arguments) {
+		addr := args.Get(0).(flow.Address)
+		t.GetAccountAtBlockHeight.Return(NewAccountWithAddress(addr.String()), nil)
+	})
+
 	t.ExecuteScript.Run(func(args mock.Arguments) {
 		t.ExecuteScript.Return(cadence.MustConvertValue(""), nil)
 	})
@@ -117,6 +132,7 @@ func DefaultMockGateway() *TestGateway {
 	t.GetLatestBlock.Return(NewBlock(), nil)
 	t.GetBlockByHeight.Return(NewBlock(), nil)
 	t.GetBlockByID.Return(NewBlock(), nil)
+	t.Close.Return(nil)
 
 	return t
 }
@@ -8,6 +8,8 @@ import (
 
 	flowkit "github.com/onflow/flow-cli/pkg/flowkit"
 
+	gateway "github.com/onflow/flow-cli/pkg/flowkit/gateway"
+
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -16,6 +18,20 @@ type Gateway struct {
 	mock.Mock
 }
 
+// Close provides a mock function with given fields:
+func (_m *Gateway) Close() error {
+	ret := _m.Called()
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // ExecuteScript provides a mock function with given fields: _a0, _a1
 func (_m *Gateway) ExecuteScript(_a0 []byte, _a1 []cadence.Value) (cadence.Value, error) {
 	ret := _m.Called(_a0, _a1)
@@ -62,6 +78,29 @@ func (_m *Gateway) GetAccount(_a0 flow.Address) (*flow.Account, error) {
 	return r0, r1
 }
 
+// GetAccountAtBlockHeight provides a mock function with given fields: _a0, _a1
+func (_m *Gateway) GetAccountAtBlockHeight(_a0 flow.Address, _a1 uint64) (*flow.Account, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *flow.Account
+	if rf, ok := ret.Get(0).(func(flow.Address, uint64) *flow.Account); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*flow.Account)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(flow.Address, uint64) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetBlockByHeight provides a mock function with given fields: _a0
 func (_m *Gateway) GetBlockByHeight(_a0 uint64) (*flow.Block, error) {
 	ret := _m.Called(_a0)
@@ -292,6 +331,20 @@ func (_m *Gateway) GetTransactionsByBlockID(blockID flow.Identifier) ([]*flow.Tr
 	return r0, r1
 }
 
+// HealthCheck provides a mock function with given fields:
+func (_m *Gateway) HealthCheck() gateway.ConnectionState {
+	ret := _m.Called()
+
+	var r0 gateway.ConnectionState
+	if rf, ok := ret.Get(0).(func() gateway.ConnectionState); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(gateway.ConnectionState)
+	}
+
+	return r0
+}
+
 // Ping provides a mock function with given fields:
 func (_m *Gateway) Ping() error {
 	ret := _m.Called()
@@ -20,6 +20,7 @@ package tests
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/onflow/flow-go-sdk"
 	"github.com/onflow/flow-go-sdk/crypto"
@@ -50,6 +51,16 @@ var ContractHelloString = Resource{
 	`),
 }
 
+var ContractInvalid = Resource{
+	Name:     "Invalid",
+	Filename: "contractInvalid.cdc",
+	Source: []byte(`
+		pub contract Invalid {
+			pub fun broken(: String {
+		}
+	`),
+}
+
 var ContractSimple = Resource{
 	Name:     "Simple",
 	Filename: "contractSimple.cdc",
@@ -81,6 +92,30 @@ var ContractSimpleWithArgs = Resource{
 	`),
 }
 
+// ContractBig is a 20 KB contract, too big to be deployed in a single transaction.
+var ContractBig = Resource{
+	Name:     "Big",
+	Filename: "contractBig.cdc",
+	Source:   bigContract(20 * 1024),
+}
+
+func bigContract(size int) []byte {
+	var b strings.Builder
+	b.WriteString(`
+		pub contract Big {
+			pub let id: UInt64
+			init(initId: UInt64) {
+				self.id = initId
+			}
+	`)
+	for i := 0; b.Len() < size; i++ {
+		fmt.Fprintf(&b, "\t\tpub fun value%d(): String { return \"value of the big contract number %d\" }\n", i, i)
+	}
+	b.WriteString("\t\t}\n")
+
+	return []byte(b.String())
+}
+
 var ContractEvents = Resource{
 	Name:     "ContractEvents",
 	Filename: "contractEvents.cdc",
@@ -154,6 +189,23 @@ var ContractC = Resource{
 	`),
 }
 
+var ContractRates = Resource{
+	Name:     "Rates",
+	Filename: "contractRates.cdc",
+	Source: []byte(`
+		pub contract Rates {
+			pub let rates: {String: UFix64}
+			pub let fallback: UFix64?
+			pub let currencies: [String]
+			init(rates: {String: UFix64}, fallback: UFix64?, currencies: [String]) {
+				self.rates = rates
+				self.fallback = fallback
+				self.currencies = currencies
+			}
+		}
+	`),
+}
+
 var TransactionArgString = Resource{
 	Filename: "transactionArg.cdc",
 	Source: []byte(`
@@ -239,6 +291,34 @@ var ScriptArgString = Resource{
 	`),
 }
 
+var ScriptNamedArgs = Resource{
+	Filename: "scriptNamedArgs.cdc",
+	Source: []byte(`
+		pub fun main(name: String, count: Int, suffix: String?): String {
+		  return "Hello ".concat(name).concat(" x").concat(count.toString()).concat(suffix ?? "")
+		}
+	`),
+}
+
+var ScriptLoop = Resource{
+	Filename: "scriptLoop.cdc",
+	Source: []byte(`
+		pub fun square(_ n: Int): Int {
+			return n * n
+		}
+
+		pub fun main(count: Int): Int {
+			var sum = 0
+			var i = 0
+			while i < count {
+				sum = sum + square(i)
+				i = i + 1
+			}
+			return sum
+		}
+	`),
+}
+
 var ScriptImport = Resource{
 	Filename: "scriptImport.cdc",
 	Source: []byte(`
@@ -292,6 +372,51 @@ var TestScriptWithFileRead = Resource{
     `),
 }
 
+var TestFileHello = Resource{
+	Filename: "tests/hello_test.cdc",
+	Source: []byte(`
+        import Hello from "../contractHello.cdc"
+
+        pub let hello = Hello()
+
+        pub fun testGreeting() {
+            assert(hello.greeting == "Hello, World!")
+        }
+
+        pub fun testHello() {
+            assert(hello.hello() == "Hello, World!")
+        }
+    `),
+}
+
+var TestFileHelloFailing = Resource{
+	Filename: "tests/hello_failing_test.cdc",
+	Source: []byte(`
+        import "Hello"
+
+        pub let hello = Hello()
+
+        pub fun testGreeting() {
+            assert(hello.greeting == "Hello, World!")
+        }
+
+        pub fun testWrongGreeting() {
+            assert(hello.greeting == "Goodbye!", message: "wrong greeting")
+        }
+    `),
+}
+
+var TestFileMissingImport = Resource{
+	Filename: "tests/missing_test.cdc",
+	Source: []byte(`
+        import Missing from "../contractMissing.cdc"
+
+        pub fun testMissing() {
+            assert(true)
+        }
+    `),
+}
+
 var SomeFile = Resource{
 	Filename: "someFile.cdc",
 	Source:   []byte(`This was read from a file!`),
@@ -301,6 +426,8 @@ var resources = []Resource{
 	ContractHelloString,
 	TransactionArgString,
 	ScriptArgString,
+	ScriptNamedArgs,
+	ScriptLoop,
 	ContractSimple,
 	ContractSimpleUpdated,
 	TransactionSimple,
@@ -308,6 +435,9 @@ var resources = []Resource{
 	ContractA,
 	ContractB,
 	ContractC,
+	ContractRates,
+	ContractInvalid,
+	ContractBig,
 }
 
 func ReaderWriter() (afero.Afero, afero.Fs) {
@@ -22,10 +22,14 @@ import (
 	"context"
 	"encoding/hex"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/onflow/cadence"
 	jsoncdc "github.com/onflow/cadence/encoding/json"
 	"github.com/onflow/cadence/runtime/ast"
+	"github.com/onflow/cadence/runtime/common"
 	"github.com/onflow/cadence/runtime/parser"
 	"github.com/onflow/flow-go-sdk"
 	"github.com/onflow/flow-go-sdk/templates"
@@ -59,6 +63,38 @@ func NewTransactionFromPayload(payload []byte) (*Transaction, error) {
 	return tx, nil
 }
 
+// ErrInsufficientAuthority is returned when the account has no key with enough weight to authorize a transaction alone.
+type ErrInsufficientAuthority struct {
+	Address flow.Address
+	// MaxKeyWeight is the highest weight of the account keys that are not revoked.
+	MaxKeyWeight int
+}
+
+func (e *ErrInsufficientAuthority) Error() string {
+	return fmt.Sprintf(
+		"account 0x%s has no key with weight of at least %d to authorize the transaction, highest key weight is %d",
+		e.Address,
+		flow.AccountKeyWeightThreshold,
+		e.MaxKeyWeight,
+	)
+}
+
+// validateAuthority checks the account has a key that isn't revoked with the weight to authorize a transaction alone.
+func validateAuthority(account *flow.Account) error {
+	maxWeight := 0
+	for _, key := range account.Keys {
+		if !key.Revoked && key.Weight > maxWeight {
+			maxWeight = key.Weight
+		}
+	}
+
+	if maxWeight < flow.AccountKeyWeightThreshold {
+		return &ErrInsufficientAuthority{Address: account.Address, MaxKeyWeight: maxWeight}
+	}
+
+	return nil
+}
+
 // NewUpdateAccountContractTransaction update account contract.
 func NewUpdateAccountContractTransaction(signer *Account, name string, source []byte) (*Transaction, error) {
 	contract := templates.Contract{
@@ -72,17 +108,281 @@ func NewUpdateAccountContractTransaction(signer *Account, name string, source []
 	)
 }
 
+// NewUpdateAccountContractTransactionWithSigner update account contract same as NewUpdateAccountContractTransaction,
+// checking the on-chain signer account has a key with the weight to authorize the update.
+//
+// ErrInsufficientAuthority is returned if the on-chain signer can't authorize the update alone.
+func NewUpdateAccountContractTransactionWithSigner(
+	signer *Account,
+	onChainSigner *flow.Account,
+	name string,
+	source []byte,
+) (*Transaction, error) {
+	if err := validateAuthority(onChainSigner); err != nil {
+		return nil, err
+	}
+
+	return NewUpdateAccountContractTransaction(signer, name, source)
+}
+
+// NewUpdateAccountContractsTransaction updates multiple account contracts in a single transaction.
+//
+// Contracts are provided as a map of contract names to their new source code.
+func NewUpdateAccountContractsTransaction(signer *Account, contracts map[string][]byte) (*Transaction, error) {
+	const updateAccountContractsTemplate = `
+	transaction(contracts: {String: String}) {
+		prepare(signer: AuthAccount) {
+			for name in contracts.keys {
+				signer.contracts.update__experimental(name: name, code: contracts[name]!.decodeHex())
+			}
+		}
+	}`
+
+	if len(contracts) == 0 {
+		return nil, fmt.Errorf("at least one contract must be provided for update")
+	}
+
+	names := make([]string, 0, len(contracts))
+	for name := range contracts {
+		names = append(names, name)
+	}
+	sort.Strings(names) // keep the transaction payload deterministic
+
+	pairs := make([]cadence.KeyValuePair, len(names))
+	for i, name := range names {
+		pairs[i] = cadence.KeyValuePair{
+			Key:   cadence.String(name),
+			Value: cadence.String(hex.EncodeToString(contracts[name])),
+		}
+	}
+
+	tx := flow.NewTransaction().
+		SetScript([]byte(updateAccountContractsTemplate)).
+		AddRawArgument(jsoncdc.MustEncode(cadence.NewDictionary(pairs))).
+		AddAuthorizer(signer.Address())
+
+	return newTransactionFromTemplate(tx, signer)
+}
+
+// contractRegistryName is the name of the registry contract updated when a contract is added with metadata.
+//
+// The registry contract must declare a `register(name: String, address: Address, metadata: {String: String})` function.
+const contractRegistryName = "ContractRegistry"
+
 // NewAddAccountContractTransaction add new contract to the account.
+//
+// If metadata is not nil the contract is also registered with the metadata in the contract registry
+// deployed on the registry address, in the same transaction.
 func NewAddAccountContractTransaction(
 	signer *Account,
 	name string,
 	source []byte,
 	args []cadence.Value,
+	metadata map[string]string,
+	registry flow.Address,
 ) (*Transaction, error) {
+	if metadata != nil && registry == flow.EmptyAddress {
+		return nil, fmt.Errorf("contract registry address is required to register the contract metadata")
+	}
+
 	return addAccountContractWithArgs(signer, templates.Contract{
 		Name:   name,
 		Source: string(source),
-	}, args)
+	}, args, metadata, registry)
+}
+
+// AccountContract is a contract to be added or updated on the account as part of a multi-contract deployment.
+type AccountContract struct {
+	Name   string
+	Source []byte
+	Args   []cadence.Value
+	Update bool
+}
+
+// NewDeployAccountContractsTransaction adds or updates multiple contracts on the account in a single transaction.
+//
+// Contracts are deployed in the order provided, so any contract importing another contract
+// from the same transaction must be provided after it.
+func NewDeployAccountContractsTransaction(signer *Account, contracts []AccountContract) (*Transaction, error) {
+	const deployAccountContractsTemplate = `%s
+	transaction(%s) {
+		prepare(signer: AuthAccount) {
+			%s
+		}
+	}`
+
+	if len(contracts) == 0 {
+		return nil, fmt.Errorf("at least one contract must be provided for deployment")
+	}
+
+	tx := flow.NewTransaction().AddAuthorizer(signer.Address())
+
+	txArgs := make([]string, 0)
+	imports := make(map[string]string)
+	statements := make([]string, len(contracts))
+	for i, contract := range contracts {
+		tx.AddRawArgument(jsoncdc.MustEncode(cadence.String(contract.Name)))
+		tx.AddRawArgument(jsoncdc.MustEncode(cadence.String(hex.EncodeToString(contract.Source))))
+		txArgs = append(txArgs, fmt.Sprintf("name%d: String", i), fmt.Sprintf("code%d: String", i))
+
+		if contract.Update {
+			statements[i] = fmt.Sprintf(
+				"signer.contracts.update__experimental(name: name%d, code: code%d.decodeHex())",
+				i, i,
+			)
+			continue
+		}
+
+		addArgs := ""
+		declared := initializerParameterTypes(contract.Source)
+		for j, arg := range contract.Args {
+			tx.AddRawArgument(jsoncdc.MustEncode(arg))
+			txArgs = append(txArgs, fmt.Sprintf("arg%d_%d: %s", i, j, parameterType(arg, declared, j, imports)))
+			addArgs += fmt.Sprintf(", arg%d_%d", i, j)
+		}
+
+		statements[i] = fmt.Sprintf("signer.contracts.add(name: name%d, code: code%d.decodeHex()%s)", i, i, addArgs)
+	}
+
+	tx.SetScript([]byte(fmt.Sprintf(
+		deployAccountContractsTemplate,
+		importDeclarations(imports),
+		strings.Join(txArgs, ", "),
+		strings.Join(statements, "\n\t\t\t"),
+	)))
+
+	return newTransactionFromTemplate(tx, signer)
+}
+
+// initializerParameterTypes returns the parameter types of the contract initializer as written in the code,
+// or nil if the code doesn't declare a single contract.
+func initializerParameterTypes(code []byte) []string {
+	program, err := parser.ParseProgram(nil, code, parser.Config{})
+	if err != nil {
+		return nil
+	}
+
+	contract := program.SoleContractDeclaration()
+	if contract == nil {
+		return nil
+	}
+
+	initializers := contract.Members.Initializers()
+	if len(initializers) == 0 || initializers[0].FunctionDeclaration.ParameterList == nil {
+		return nil
+	}
+
+	parameters := initializers[0].FunctionDeclaration.ParameterList.Parameters
+	types := make([]string, len(parameters))
+	for i, parameter := range parameters {
+		types[i] = parameter.TypeAnnotation.Type.String()
+	}
+
+	return types
+}
+
+// parameterType returns the transaction parameter type for the contract initializer argument at the index.
+//
+// The type declared by the initializer is used unless it refers to types of other contracts, which would
+// require imports, in which case the type is derived from the argument value.
+func parameterType(arg cadence.Value, declared []string, index int, imports map[string]string) string {
+	if index < len(declared) && !strings.Contains(declared[index], ".") {
+		return declared[index]
+	}
+
+	return argumentType(arg, imports)
+}
+
+// argumentType returns the Cadence type of the argument as declared in a transaction parameter list.
+//
+// Arrays, dictionaries and nil optionals decoded from JSON-Cadence don't carry their type, so it is inferred from
+// their elements, falling back to AnyStruct for empty or mixed collections. Contracts declaring
+// composite types used by the argument are added to the imports as contract name to address.
+func argumentType(arg cadence.Value, imports map[string]string) string {
+	switch v := arg.(type) {
+	case cadence.Optional:
+		if v.Value == nil {
+			return "AnyStruct?"
+		}
+		return fmt.Sprintf("%s?", argumentType(v.Value, imports))
+	case cadence.Array:
+		if v.ArrayType != nil {
+			return typeName(v.ArrayType, imports)
+		}
+		return fmt.Sprintf("[%s]", commonArgumentType(v.Values, imports))
+	case cadence.Dictionary:
+		if v.DictionaryType != nil {
+			return typeName(v.DictionaryType, imports)
+		}
+		if len(v.Pairs) == 0 {
+			return "{String: AnyStruct}"
+		}
+
+		keys := make([]cadence.Value, len(v.Pairs))
+		values := make([]cadence.Value, len(v.Pairs))
+		for i, pair := range v.Pairs {
+			keys[i] = pair.Key
+			values[i] = pair.Value
+		}
+		return fmt.Sprintf("{%s: %s}", commonArgumentType(keys, imports), commonArgumentType(values, imports))
+	default:
+		return typeName(arg.Type(), imports)
+	}
+}
+
+// commonArgumentType returns the type shared by all the values or AnyStruct if there is none.
+func commonArgumentType(values []cadence.Value, imports map[string]string) string {
+	shared := ""
+	for _, value := range values {
+		valueType := argumentType(value, imports)
+		if shared != "" && shared != valueType {
+			return "AnyStruct"
+		}
+		shared = valueType
+	}
+
+	if shared == "" {
+		return "AnyStruct"
+	}
+	return shared
+}
+
+// typeName returns the name of the type as written in Cadence code.
+func typeName(t cadence.Type, imports map[string]string) string {
+	switch v := t.(type) {
+	case nil:
+		return "AnyStruct"
+	case cadence.OptionalType:
+		return fmt.Sprintf("%s?", typeName(v.Type, imports))
+	case cadence.VariableSizedArrayType:
+		return fmt.Sprintf("[%s]", typeName(v.ElementType, imports))
+	case cadence.ConstantSizedArrayType:
+		return fmt.Sprintf("[%s; %d]", typeName(v.ElementType, imports), v.Size)
+	case cadence.DictionaryType:
+		return fmt.Sprintf("{%s: %s}", typeName(v.KeyType, imports), typeName(v.ElementType, imports))
+	case cadence.CompositeType:
+		if location, ok := v.CompositeTypeLocation().(common.AddressLocation); ok {
+			imports[location.Name] = location.Address.HexWithPrefix()
+		}
+		return v.CompositeTypeQualifiedIdentifier()
+	default:
+		return t.ID()
+	}
+}
+
+// importDeclarations returns the import declarations of the contracts, sorted by name.
+func importDeclarations(imports map[string]string) string {
+	names := make([]string, 0, len(imports))
+	for name := range imports {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	declarations := ""
+	for _, name := range names {
+		declarations += fmt.Sprintf("\n\timport %s from %s", name, imports[name])
+	}
+	return declarations
 }
 
 // NewRemoveAccountContractTransaction creates new transaction to remove contract.
@@ -93,15 +393,51 @@ func NewRemoveAccountContractTransaction(signer *Account, name string) (*Transac
 	)
 }
 
+// NewRemoveAccountContractWithUnlinkTransaction creates new transaction to remove contract,
+// unlinking the capabilities at the paths in the same transaction.
+func NewRemoveAccountContractWithUnlinkTransaction(
+	signer *Account,
+	name string,
+	paths []cadence.Path,
+) (*Transaction, error) {
+	const removeAccountContractWithUnlinkTemplate = `
+	transaction(name: String, paths: [CapabilityPath]) {
+		prepare(signer: AuthAccount) {
+			for path in paths {
+				signer.unlink(path)
+			}
+			signer.contracts.remove(name: name)
+		}
+	}`
+
+	values := make([]cadence.Value, len(paths))
+	for i, path := range paths {
+		if path.Domain != common.PathDomainPublic.Identifier() && path.Domain != common.PathDomainPrivate.Identifier() {
+			return nil, fmt.Errorf("capability path %s must be in the public or private domain", path)
+		}
+		values[i] = path
+	}
+
+	tx := flow.NewTransaction().
+		SetScript([]byte(removeAccountContractWithUnlinkTemplate)).
+		AddRawArgument(jsoncdc.MustEncode(cadence.String(name))).
+		AddRawArgument(jsoncdc.MustEncode(cadence.NewArray(values))).
+		AddAuthorizer(signer.Address())
+
+	return newTransactionFromTemplate(tx, signer)
+}
+
 func addAccountContractWithArgs(
 	signer *Account,
 	contract templates.Contract,
 	args []cadence.Value,
+	metadata map[string]string,
+	registry flow.Address,
 ) (*Transaction, error) {
-	const addAccountContractTemplate = `
+	const addAccountContractTemplate = `%s
 	transaction(name: String, code: String %s) {
 		prepare(signer: AuthAccount) {
-			signer.contracts.add(name: name, code: code.decodeHex() %s)
+			signer.contracts.add(name: name, code: code.decodeHex() %s)%s
 		}
 	}`
 
@@ -114,17 +450,40 @@ func addAccountContractWithArgs(
 		AddAuthorizer(signer.Address())
 
 	for _, arg := range args {
-		arg.Type().ID()
 		tx.AddRawArgument(jsoncdc.MustEncode(arg))
 	}
 
 	txArgs, addArgs := "", ""
+	imports := make(map[string]string)
+	declared := initializerParameterTypes([]byte(contract.Source))
 	for i, arg := range args {
-		txArgs += fmt.Sprintf(",arg%d:%s", i, arg.Type().ID())
+		txArgs += fmt.Sprintf(",arg%d:%s", i, parameterType(arg, declared, i, imports))
 		addArgs += fmt.Sprintf(",arg%d", i)
 	}
 
-	script := fmt.Sprintf(addAccountContractTemplate, txArgs, addArgs)
+	register := ""
+	if metadata != nil {
+		keys := make([]string, 0, len(metadata))
+		for key := range metadata {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys) // keep the transaction payload deterministic
+
+		pairs := make([]cadence.KeyValuePair, len(keys))
+		for i, key := range keys {
+			pairs[i] = cadence.KeyValuePair{Key: cadence.String(key), Value: cadence.String(metadata[key])}
+		}
+
+		tx.AddRawArgument(jsoncdc.MustEncode(cadence.NewDictionary(pairs)))
+		txArgs += ",metadata:{String: String}"
+		imports[contractRegistryName] = fmt.Sprintf("0x%s", registry)
+		register = fmt.Sprintf(
+			"\n\t\t\t%s.register(name: name, address: signer.address, metadata: metadata)",
+			contractRegistryName,
+		)
+	}
+
+	script := fmt.Sprintf(addAccountContractTemplate, importDeclarations(imports), txArgs, addArgs, register)
 	tx.SetScript([]byte(script))
 	tx.SetGasLimit(maxGasLimit)
 
@@ -138,6 +497,107 @@ func addAccountContractWithArgs(
 	return t, nil
 }
 
+// stagedContractPath returns the storage path where the code of the contract is staged by NewStageContractChunkTransaction.
+func stagedContractPath(name string) string {
+	return fmt.Sprintf("/storage/flowkitStagedContract%s", name)
+}
+
+// NewStageContractChunkTransaction appends a chunk of the contract source to the code staged in the signer storage.
+//
+// Contracts too big for a single transaction are sent in chunks and deployed with NewDeployStagedContractTransaction.
+// The first chunk replaces any code left in the storage by an upload that didn't complete.
+func NewStageContractChunkTransaction(signer *Account, name string, chunk []byte, first bool) (*Transaction, error) {
+	const stageContractChunkTemplate = `
+	transaction(chunk: String, first: Bool) {
+		prepare(signer: AuthAccount) {
+			var staged = signer.load<String>(from: %[1]s) ?? ""
+			if first {
+				staged = ""
+			}
+			signer.save(staged.concat(chunk), to: %[1]s)
+		}
+	}`
+
+	tx := flow.NewTransaction().
+		SetScript([]byte(fmt.Sprintf(stageContractChunkTemplate, stagedContractPath(name)))).
+		AddRawArgument(jsoncdc.MustEncode(cadence.String(hex.EncodeToString(chunk)))).
+		AddRawArgument(jsoncdc.MustEncode(cadence.NewBool(first))).
+		AddAuthorizer(signer.Address())
+
+	return newTransactionFromTemplate(tx, signer)
+}
+
+// NewDeployStagedContractTransaction adds or updates the contract with the code staged by NewStageContractChunkTransaction
+// and removes the staged code from the signer storage.
+//
+// The source is only used to resolve the types of the initializer arguments, which are ignored for updates.
+func NewDeployStagedContractTransaction(
+	signer *Account,
+	name string,
+	source []byte,
+	args []cadence.Value,
+	update bool,
+) (*Transaction, error) {
+	const deployStagedContractTemplate = `%s
+	transaction(name: String %s) {
+		prepare(signer: AuthAccount) {
+			let code = signer.load<String>(from: %s)
+				?? panic("no code staged for contract ".concat(name))
+			%s
+		}
+	}`
+
+	tx := flow.NewTransaction().
+		AddRawArgument(jsoncdc.MustEncode(cadence.String(name))).
+		AddAuthorizer(signer.Address())
+
+	if update {
+		tx.SetScript([]byte(fmt.Sprintf(
+			deployStagedContractTemplate,
+			"",
+			"",
+			stagedContractPath(name),
+			"signer.contracts.update__experimental(name: name, code: code.decodeHex())",
+		)))
+		return newTransactionFromTemplate(tx, signer)
+	}
+
+	txArgs, addArgs := "", ""
+	imports := make(map[string]string)
+	declared := initializerParameterTypes(source)
+	for i, arg := range args {
+		tx.AddRawArgument(jsoncdc.MustEncode(arg))
+		txArgs += fmt.Sprintf(",arg%d:%s", i, parameterType(arg, declared, i, imports))
+		addArgs += fmt.Sprintf(",arg%d", i)
+	}
+
+	tx.SetScript([]byte(fmt.Sprintf(
+		deployStagedContractTemplate,
+		importDeclarations(imports),
+		txArgs,
+		stagedContractPath(name),
+		fmt.Sprintf("signer.contracts.add(name: name, code: code.decodeHex() %s)", addArgs),
+	)))
+
+	return newTransactionFromTemplate(tx, signer)
+}
+
+// NewAuthorizedTransaction creates a transaction executing the script with provided arguments
+// where the signer is the only authorizer and payer.
+func NewAuthorizedTransaction(signer *Account, script []byte, args []cadence.Value) (*Transaction, error) {
+	tx := flow.NewTransaction().
+		SetScript(script).
+		AddAuthorizer(signer.Address())
+
+	for _, arg := range args {
+		if err := tx.AddArgument(arg); err != nil {
+			return nil, fmt.Errorf("failed to add argument %s: %w", arg, err)
+		}
+	}
+
+	return newTransactionFromTemplate(tx, signer)
+}
+
 // NewCreateAccountTransaction creates new transaction for account.
 func NewCreateAccountTransaction(
 	signer *Account,
@@ -260,6 +720,62 @@ func (t *Transaction) SetBlockReference(block *flow.Block) *Transaction {
 	return t
 }
 
+// TransactionExpiry is the number of blocks after the reference block in which a transaction can still be included.
+const TransactionExpiry = 600
+
+// EstimatedBlockTime is a rough estimate of the time between two blocks, used to estimate transaction expiry time.
+const EstimatedBlockTime = time.Second
+
+// BlockGetter gets blocks from the network, it is satisfied by the gateway.
+type BlockGetter interface {
+	GetLatestBlock() (*flow.Block, error)
+	GetBlockByID(flow.Identifier) (*flow.Block, error)
+}
+
+// ValidityWindow describes for how long a transaction can still be sent to the network.
+type ValidityWindow struct {
+	ReferenceHeight uint64
+	ExpiryHeight    uint64
+	RemainingBlocks uint64
+	RemainingTime   time.Duration
+}
+
+// Expired returns true if the transaction can no longer be included in a block.
+func (v ValidityWindow) Expired() bool {
+	return v.RemainingBlocks == 0
+}
+
+// NewValidityWindow calculates the validity window of a transaction referencing the block
+// at reference height, at the provided latest sealed height.
+func NewValidityWindow(referenceHeight uint64, sealedHeight uint64) ValidityWindow {
+	window := ValidityWindow{
+		ReferenceHeight: referenceHeight,
+		ExpiryHeight:    referenceHeight + TransactionExpiry,
+	}
+
+	if sealedHeight < window.ExpiryHeight {
+		window.RemainingBlocks = window.ExpiryHeight - sealedHeight
+		window.RemainingTime = time.Duration(window.RemainingBlocks) * EstimatedBlockTime
+	}
+
+	return window
+}
+
+// ValidityWindow computes the remaining validity of the transaction against the current sealed height.
+func (t *Transaction) ValidityWindow(blocks BlockGetter) (ValidityWindow, error) {
+	reference, err := blocks.GetBlockByID(t.tx.ReferenceBlockID)
+	if err != nil {
+		return ValidityWindow{}, fmt.Errorf("failed to get reference block: %w", err)
+	}
+
+	latest, err := blocks.GetLatestBlock()
+	if err != nil {
+		return ValidityWindow{}, fmt.Errorf("failed to get latest sealed block: %w", err)
+	}
+
+	return NewValidityWindow(reference.Height, latest.Height), nil
+}
+
 // SetGasLimit sets the gas limit for transaction.
 func (t *Transaction) SetGasLimit(gasLimit uint64) *Transaction {
 	t.tx.SetGasLimit(gasLimit)
@@ -0,0 +1,277 @@
+/*
+ * Flow CLI
+ *
+ * Copyright 2019 Dapper Labs, Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *   http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package flowkit
+
+import (
+	"testing"
+
+	"github.com/onflow/cadence"
+	"github.com/onflow/cadence/runtime/common"
+	"github.com/onflow/flow-go-sdk"
+	"github.com/onflow/flow-go-sdk/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContractArgumentTypes(t *testing.T) {
+
+	t.Run("Inferred Types", func(t *testing.T) {
+		rateType := cadence.NewStructType(
+			common.AddressLocation{Address: common.MustBytesToAddress([]byte{0x1}), Name: "Rates"},
+			"Rates.Rate",
+			[]cadence.Field{{Identifier: "value", Type: cadence.UFix64Type{}}},
+			nil,
+		)
+		rate := cadence.NewStruct([]cadence.Value{cadence.UFix64(100000000)}).WithType(rateType)
+
+		values := []struct {
+			value    cadence.Value
+			expected string
+		}{
+			{cadence.String("foo"), "String"},
+			{cadence.NewOptional(cadence.NewUInt64(1)), "UInt64?"},
+			{cadence.NewOptional(nil), "AnyStruct?"},
+			{cadence.NewArray([]cadence.Value{cadence.String("a"), cadence.String("b")}), "[String]"},
+			{cadence.NewArray([]cadence.Value{cadence.String("a"), cadence.NewInt(1)}), "[AnyStruct]"},
+			{cadence.NewArray(nil), "[AnyStruct]"},
+			{
+				cadence.NewArray(nil).WithType(cadence.NewVariableSizedArrayType(cadence.UFix64Type{})),
+				"[UFix64]",
+			},
+			{
+				cadence.NewDictionary([]cadence.KeyValuePair{{Key: cadence.String("USD"), Value: cadence.UFix64(1)}}),
+				"{String: UFix64}",
+			},
+			{cadence.NewDictionary(nil), "{String: AnyStruct}"},
+			{cadence.NewArray([]cadence.Value{rate}), "[Rates.Rate]"},
+		}
+
+		for _, v := range values {
+			imports := make(map[string]string)
+			assert.Equal(t, v.expected, argumentType(v.value, imports))
+		}
+
+		imports := make(map[string]string)
+		argumentType(rate, imports)
+		assert.Equal(t, map[string]string{"Rates": "0x0000000000000001"}, imports)
+		assert.Equal(t, "\n\timport Rates from 0x0000000000000001", importDeclarations(imports))
+	})
+
+	t.Run("Declared Types", func(t *testing.T) {
+		declared := initializerParameterTypes([]byte(`
+			import Rates from 0x01
+
+			pub contract Exchange {
+				init(rates: {String: UFix64}, fallback: UFix64?, rate: Rates.Rate) {}
+			}
+		`))
+		assert.Equal(t, []string{"{String: UFix64}", "UFix64?", "Rates.Rate"}, declared)
+
+		imports := make(map[string]string)
+		assert.Equal(t, "{String: UFix64}", parameterType(cadence.NewDictionary(nil), declared, 0, imports))
+		assert.Equal(t, "UFix64?", parameterType(cadence.NewOptional(nil), declared, 1, imports))
+		assert.Equal(t, "String", parameterType(cadence.String("foo"), declared, 3, imports))
+
+		assert.Nil(t, initializerParameterTypes([]byte(`pub fun main() {}`)))
+	})
+}
+
+func TestUpdateAccountContractTransactionWithSigner(t *testing.T) {
+	privateKey, err := crypto.GeneratePrivateKey(crypto.ECDSA_P256, make([]byte, crypto.MinSeedLength))
+	require.NoError(t, err)
+
+	address := flow.HexToAddress("01cf0e2f2f715450")
+	signer := NewAccount("alice").
+		SetAddress(address).
+		SetKey(NewHexAccountKeyFromPrivateKey(0, crypto.SHA3_256, privateKey))
+
+	onChain := func(keys ...*flow.AccountKey) *flow.Account {
+		return &flow.Account{Address: address, Keys: keys}
+	}
+
+	t.Run("Authorized", func(t *testing.T) {
+		tx, err := NewUpdateAccountContractTransactionWithSigner(
+			signer,
+			onChain(
+				&flow.AccountKey{Weight: 500},
+				&flow.AccountKey{Weight: flow.AccountKeyWeightThreshold},
+			),
+			"Hello",
+			[]byte(`pub contract Hello {}`),
+		)
+
+		require.NoError(t, err)
+		assert.Equal(t, []flow.Address{address}, tx.FlowTransaction().Authorizers)
+	})
+
+	t.Run("Insufficient Weight", func(t *testing.T) {
+		_, err := NewUpdateAccountContractTransactionWithSigner(
+			signer,
+			onChain(&flow.AccountKey{Weight: 500}, &flow.AccountKey{Weight: 999}),
+			"Hello",
+			[]byte(`pub contract Hello {}`),
+		)
+
+		var authorityErr *ErrInsufficientAuthority
+		require.ErrorAs(t, err, &authorityErr)
+		assert.Equal(t, address, authorityErr.Address)
+		assert.Equal(t, 999, authorityErr.MaxKeyWeight)
+	})
+
+	t.Run("Revoked Key", func(t *testing.T) {
+		_, err := NewUpdateAccountContractTransactionWithSigner(
+			signer,
+			onChain(
+				&flow.AccountKey{Weight: flow.AccountKeyWeightThreshold, Revoked: true},
+				&flow.AccountKey{Weight: 100},
+			),
+			"Hello",
+			[]byte(`pub contract Hello {}`),
+		)
+
+		assert.EqualError(
+			t,
+			err,
+			"account 0x01cf0e2f2f715450 has no key with weight of at least 1000 to authorize the transaction, highest key weight is 100",
+		)
+	})
+}
+
+func TestAddAccountContractTransaction(t *testing.T) {
+	privateKey, err := crypto.GeneratePrivateKey(crypto.ECDSA_P256, make([]byte, crypto.MinSeedLength))
+	require.NoError(t, err)
+
+	signer := NewAccount("alice").
+		SetAddress(flow.HexToAddress("01cf0e2f2f715450")).
+		SetKey(NewHexAccountKeyFromPrivateKey(0, crypto.SHA3_256, privateKey))
+	source := []byte(`pub contract Hello { init(greeting: String) {} }`)
+	args := []cadence.Value{cadence.String("hello")}
+	registry := flow.HexToAddress("0x02")
+
+	t.Run("Without Metadata", func(t *testing.T) {
+		tx, err := NewAddAccountContractTransaction(signer, "Hello", source, args, nil, registry)
+		require.NoError(t, err)
+
+		script := string(tx.FlowTransaction().Script)
+		assert.Contains(t, script, "signer.contracts.add(name: name, code: code.decodeHex() ,arg0)")
+		assert.NotContains(t, script, "ContractRegistry")
+		assert.Len(t, tx.FlowTransaction().Arguments, 3)
+	})
+
+	t.Run("With Metadata", func(t *testing.T) {
+		metadata := map[string]string{"version": "1.0.0", "commit": "abc123"}
+		tx, err := NewAddAccountContractTransaction(signer, "Hello", source, args, metadata, registry)
+		require.NoError(t, err)
+
+		script := string(tx.FlowTransaction().Script)
+		assert.Contains(t, script, "import ContractRegistry from 0x0000000000000002")
+		assert.Contains(t, script, "transaction(name: String, code: String ,arg0:String,metadata:{String: String})")
+		assert.Contains(
+			t,
+			script,
+			"signer.contracts.add(name: name, code: code.decodeHex() ,arg0)\n\t\t\tContractRegistry.register(name: name, address: signer.address, metadata: metadata)",
+		)
+
+		require.Len(t, tx.FlowTransaction().Arguments, 4)
+		arg, err := tx.FlowTransaction().Argument(3)
+		require.NoError(t, err)
+		assert.Equal(t, cadence.NewDictionary([]cadence.KeyValuePair{
+			{Key: cadence.String("commit"), Value: cadence.String("abc123")},
+			{Key: cadence.String("version"), Value: cadence.String("1.0.0")},
+		}), arg)
+	})
+
+	t.Run("Missing Registry", func(t *testing.T) {
+		_, err := NewAddAccountContractTransaction(signer, "Hello", source, args, map[string]string{}, flow.EmptyAddress)
+		assert.EqualError(t, err, "contract registry address is required to register the contract metadata")
+	})
+}
+
+func TestDeployStagedContractTransaction(t *testing.T) {
+	privateKey, err := crypto.GeneratePrivateKey(crypto.ECDSA_P256, make([]byte, crypto.MinSeedLength))
+	require.NoError(t, err)
+
+	signer := NewAccount("alice").
+		SetAddress(flow.HexToAddress("01cf0e2f2f715450")).
+		SetKey(NewHexAccountKeyFromPrivateKey(0, crypto.SHA3_256, privateKey))
+	source := []byte(`pub contract Big { init(fee: UFix64?) {} }`)
+	args := []cadence.Value{cadence.NewOptional(nil)}
+
+	t.Run("Add", func(t *testing.T) {
+		tx, err := NewDeployStagedContractTransaction(signer, "Big", source, args, false)
+		require.NoError(t, err)
+
+		script := string(tx.FlowTransaction().Script)
+		assert.Contains(t, script, "transaction(name: String ,arg0:UFix64?)")
+		assert.Contains(t, script, "signer.load<String>(from: /storage/flowkitStagedContractBig)")
+		assert.Contains(t, script, "signer.contracts.add(name: name, code: code.decodeHex() ,arg0)")
+		assert.Len(t, tx.FlowTransaction().Arguments, 2)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		tx, err := NewDeployStagedContractTransaction(signer, "Big", source, args, true)
+		require.NoError(t, err)
+
+		assert.Contains(t, string(tx.FlowTransaction().Script), "signer.contracts.update__experimental(name: name, code: code.decodeHex())")
+		assert.Len(t, tx.FlowTransaction().Arguments, 1)
+	})
+
+	t.Run("Stage Chunk", func(t *testing.T) {
+		tx, err := NewStageContractChunkTransaction(signer, "Big", []byte("pub"), true)
+		require.NoError(t, err)
+
+		assert.Contains(t, string(tx.FlowTransaction().Script), "signer.save(staged.concat(chunk), to: /storage/flowkitStagedContractBig)")
+		chunk, err := tx.FlowTransaction().Argument(0)
+		require.NoError(t, err)
+		assert.Equal(t, cadence.String("707562"), chunk)
+	})
+}
+
+func TestRemoveAccountContractWithUnlinkTransaction(t *testing.T) {
+	privateKey, err := crypto.GeneratePrivateKey(crypto.ECDSA_P256, make([]byte, crypto.MinSeedLength))
+	require.NoError(t, err)
+
+	signer := NewAccount("alice").
+		SetAddress(flow.HexToAddress("01cf0e2f2f715450")).
+		SetKey(NewHexAccountKeyFromPrivateKey(0, crypto.SHA3_256, privateKey))
+
+	t.Run("Capability Paths", func(t *testing.T) {
+		paths := []cadence.Path{
+			{Domain: "public", Identifier: "kitty"},
+			{Domain: "private", Identifier: "kittyProvider"},
+		}
+		tx, err := NewRemoveAccountContractWithUnlinkTransaction(signer, "Kitty", paths)
+		require.NoError(t, err)
+
+		assert.Contains(t, string(tx.FlowTransaction().Script), "signer.unlink(path)")
+		assert.Contains(t, string(tx.FlowTransaction().Script), "signer.contracts.remove(name: name)")
+		arg, err := tx.FlowTransaction().Argument(1)
+		require.NoError(t, err)
+		assert.Equal(t, cadence.NewArray([]cadence.Value{paths[0], paths[1]}), arg)
+	})
+
+	t.Run("Storage Path", func(t *testing.T) {
+		_, err := NewRemoveAccountContractWithUnlinkTransaction(
+			signer,
+			"Kitty",
+			[]cadence.Path{{Domain: "storage", Identifier: "kitty"}},
+		)
+		assert.EqualError(t, err, "capability path /storage/kitty must be in the public or private domain")
+	})
+}
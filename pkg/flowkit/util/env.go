@@ -46,3 +46,31 @@ func EnvFromNetwork(network flow.ChainID) templates.Environment {
 
 	return templates.Environment{}
 }
+
+// NonFungibleTokenAddress returns the address of the NonFungibleToken standard contract on the network.
+//
+// On the emulator the contract is deployed to the service account when the emulator is started with contracts.
+func NonFungibleTokenAddress(network flow.ChainID) flow.Address {
+	switch network {
+	case flow.Mainnet:
+		return flow.HexToAddress("1d7e57aa55817448")
+	case flow.Testnet:
+		return flow.HexToAddress("631e88ae7f1d7c20")
+	default:
+		return flow.HexToAddress("f8d6e0586b0a20c7")
+	}
+}
+
+// FlowFeesAddress returns the address of the FlowFees contract on the network, flow.EmptyAddress if it isn't known.
+func FlowFeesAddress(network flow.ChainID) flow.Address {
+	switch network {
+	case flow.Mainnet:
+		return flow.HexToAddress("f919ee77447b7497")
+	case flow.Testnet:
+		return flow.HexToAddress("912d5440f7e3769e")
+	case flow.Emulator:
+		return flow.HexToAddress("e5a8b7f23e8b548f")
+	default:
+		return flow.EmptyAddress
+	}
+}